@@ -2,86 +2,63 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
-	"net/http"
+	"errors"
+	"flag"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"golinks/internal/config"
-	"golinks/internal/database"
-	"golinks/internal/handlers"
-	"golinks/internal/repository"
-	"golinks/internal/service"
-
-	"github.com/gorilla/mux"
+	"golinks/pkg/golinks"
 )
 
+// closeTimeout bounds how long the server waits to write the queries and announce the
+// link changes still queued before exiting
+const closeTimeout = 30 * time.Second
+
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+	server, err := golinks.New(golinks.WithArgs(os.Args[1:]))
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
-
-	// Initialize database
-	db, err := database.NewSQLiteDB(cfg.DatabasePath)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		fatal("Failed to start", "err", err)
 	}
-	defer db.Close()
-
-	// Run migrations
-	if err := database.Migrate(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	slog.SetDefault(server.Logger())
+
+	// `golinks <command> ...` manages the instance from the shell instead of serving
+	if command := server.Command(); command != nil {
+		err := server.RunCommand(context.Background(), os.Stdin, os.Stdout)
+		closeServer(server)
+		if err != nil {
+			fatal("Command failed", "command", command[0], "err", err)
+		}
+		return
 	}
 
-	// Initialize repositories
-	shortcutRepo := repository.NewShortcutRepository(db)
-	queryRepo := repository.NewQueryRepository(db)
-
-	// Initialize services
-	linkService := service.NewLinkService(shortcutRepo, queryRepo)
-
-	// Initialize handlers
-	handler := handlers.NewHandler(linkService, cfg)
-
-	// Setup router
-	router := mux.NewRouter()
-	handler.RegisterRoutes(router)
-
-	// Setup server
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	// Serve until an interrupt signal, then shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	err = server.ListenAndServe(ctx)
+	closeServer(server)
+	if err != nil {
+		fatal("Server failed", "err", err)
 	}
+	slog.Info("Server exited")
+}
 
-	// Start server in a goroutine
-	go func() {
-		log.Printf("Starting server on port %d", cfg.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
-		}
-	}()
-
-	// Wait for interrupt signal to gracefully shutdown the server
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Shutting down server...")
-
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// closeServer releases server, logging what it failed to finish
+func closeServer(server *golinks.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
 	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if err := server.Close(ctx); err != nil {
+		slog.Error("Failed to shut down cleanly", "err", err)
 	}
+}
 
-	log.Println("Server exited")
+// fatal logs msg with its attributes as an error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+// googleUserInfoURL is Google's OpenID Connect userinfo endpoint
+const googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+
+// ErrDomainNotAllowed is returned when a Google account is outside the allowed domains
+var ErrDomainNotAllowed = errors.New("account is not in an allowed domain")
+
+// Google signs users in with their Google accounts over OAuth2
+type Google struct {
+	config         *oauth2.Config
+	allowedDomains map[string]bool
+	userInfoURL    string
+}
+
+// NewGoogle creates a Google sign-in provider that redirects back to redirectURL.
+// If allowedDomains is non-empty only accounts in those Google Workspace domains may sign in.
+func NewGoogle(clientID, clientSecret, redirectURL string, allowedDomains []string) *Google {
+	domains := map[string]bool{}
+	for _, domain := range allowedDomains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			domains[domain] = true
+		}
+	}
+
+	return &Google{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     endpoints.Google,
+			Scopes:       []string{"openid", "email"},
+		},
+		allowedDomains: domains,
+		userInfoURL:    googleUserInfoURL,
+	}
+}
+
+// AuthCodeURL returns the Google consent page URL for a login attempt identified by state
+func (g *Google) AuthCodeURL(state string) string {
+	opts := []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("prompt", "select_account")}
+	if len(g.allowedDomains) == 1 {
+		// Hint Google to only offer accounts from the one allowed domain
+		for domain := range g.allowedDomains {
+			opts = append(opts, oauth2.SetAuthURLParam("hd", domain))
+		}
+	}
+	return g.config.AuthCodeURL(state, opts...)
+}
+
+// Exchange trades an authorization code for the signed-in user's email address
+func (g *Google) Exchange(ctx context.Context, code string) (string, error) {
+	token, err := g.config.Exchange(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	resp, err := g.config.Client(ctx, token).Get(g.userInfoURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get user info: %s", resp.Status)
+	}
+
+	var info struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		HostedDomain  string `json:"hd"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("failed to decode user info: %w", err)
+	}
+	if info.Email == "" || !info.EmailVerified {
+		return "", errors.New("google account has no verified email address")
+	}
+
+	// hd names the Google Workspace that manages the account. The email domain alone is
+	// not enough, since consumer accounts can be registered with any address.
+	if len(g.allowedDomains) > 0 && !g.allowedDomains[strings.ToLower(info.HostedDomain)] {
+		return "", ErrDomainNotAllowed
+	}
+
+	return strings.ToLower(info.Email), nil
+}
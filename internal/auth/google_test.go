@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// fakeGoogle serves a token endpoint and a userinfo endpoint returning info
+func fakeGoogle(t *testing.T, info map[string]interface{}) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "good-code" {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "token_type": "Bearer", "expires_in": 3600})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(info)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGoogle_Exchange(t *testing.T) {
+	tests := []struct {
+		name      string
+		domains   []string
+		code      string
+		info      map[string]interface{}
+		wantEmail string
+		wantErr   error
+	}{
+		{
+			name:      "any account",
+			code:      "good-code",
+			info:      map[string]interface{}{"email": "Alice@Gmail.com", "email_verified": true},
+			wantEmail: "alice@gmail.com",
+		},
+		{
+			name:      "allowed workspace",
+			domains:   []string{"Example.com", "example.org"},
+			code:      "good-code",
+			info:      map[string]interface{}{"email": "alice@example.com", "email_verified": true, "hd": "example.com"},
+			wantEmail: "alice@example.com",
+		},
+		{
+			name:    "other workspace",
+			domains: []string{"example.com"},
+			code:    "good-code",
+			info:    map[string]interface{}{"email": "eve@evil.com", "email_verified": true, "hd": "evil.com"},
+			wantErr: ErrDomainNotAllowed,
+		},
+		{
+			name:    "consumer account with a workspace address",
+			domains: []string{"example.com"},
+			code:    "good-code",
+			info:    map[string]interface{}{"email": "eve@example.com", "email_verified": true},
+			wantErr: ErrDomainNotAllowed,
+		},
+		{
+			name: "unverified email",
+			code: "good-code",
+			info: map[string]interface{}{"email": "alice@gmail.com", "email_verified": false},
+		},
+		{
+			name: "bad code",
+			code: "bad-code",
+			info: map[string]interface{}{"email": "alice@gmail.com", "email_verified": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakeGoogle(t, tt.info)
+			google := NewGoogle("client", "secret", "https://go.example.com/auth/callback", tt.domains)
+			google.config.Endpoint.TokenURL = server.URL + "/token"
+			google.userInfoURL = server.URL + "/userinfo"
+
+			email, err := google.Exchange(context.Background(), tt.code)
+			if tt.wantEmail == "" {
+				if err == nil {
+					t.Fatalf("Google.Exchange() = %q, want an error", email)
+				}
+				if tt.wantErr != nil && err != tt.wantErr {
+					t.Errorf("Google.Exchange() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || email != tt.wantEmail {
+				t.Errorf("Google.Exchange() = %q, %v, want %q", email, err, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestGoogle_AuthCodeURL(t *testing.T) {
+	google := NewGoogle("client", "secret", "https://go.example.com/auth/callback", []string{"example.com"})
+
+	parsed, err := url.Parse(google.AuthCodeURL("state-123"))
+	if err != nil {
+		t.Fatalf("Google.AuthCodeURL() is not a URL: %v", err)
+	}
+	query := parsed.Query()
+	if parsed.Host != "accounts.google.com" || query.Get("state") != "state-123" || query.Get("client_id") != "client" ||
+		query.Get("redirect_uri") != "https://go.example.com/auth/callback" || query.Get("hd") != "example.com" {
+		t.Errorf("Google.AuthCodeURL() = %s", parsed)
+	}
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapTimeout bounds connecting to and each request against the directory
+const ldapTimeout = 10 * time.Second
+
+var (
+	// ErrInvalidCredentials is returned when a username and password do not match
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrGroupNotAllowed is returned when a directory user is in none of the allowed groups
+	ErrGroupNotAllowed = errors.New("account is not in an allowed group")
+)
+
+// LDAPConfig describes how to find and check users in an LDAP or Active Directory server
+type LDAPConfig struct {
+	// URL of the server, e.g. ldaps://ldap.example.com or ldap://dc1.example.com:389
+	URL string
+	// StartTLS upgrades a plain ldap:// connection before any credentials are sent
+	StartTLS bool
+
+	// BindDN and BindPassword are the service account used to look users up.
+	// An empty BindDN searches anonymously.
+	BindDN       string
+	BindPassword string
+
+	// BaseDN is where user searches start
+	BaseDN string
+	// UserFilter finds a user by login name, with %s replaced by the escaped name,
+	// e.g. (uid=%s) or (sAMAccountName=%s) for Active Directory
+	UserFilter string
+
+	// AllowedGroups, if set, limits sign-in to members of these groups, given by
+	// common name or full DN, as listed in the user's memberOf attribute
+	AllowedGroups []string
+}
+
+// ldapConn is the part of *ldap.Conn used to authenticate users
+type ldapConn interface {
+	StartTLS(config *tls.Config) error
+	Bind(username, password string) error
+	Search(request *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+// LDAP checks usernames and passwords against a directory server
+type LDAP struct {
+	config LDAPConfig
+	dial   func(url string) (ldapConn, error)
+}
+
+// NewLDAP creates an LDAP password checker
+func NewLDAP(config LDAPConfig) *LDAP {
+	if config.UserFilter == "" {
+		config.UserFilter = "(uid=%s)"
+	}
+
+	return &LDAP{
+		config: config,
+		dial: func(url string) (ldapConn, error) {
+			conn, err := ldap.DialURL(url, ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}))
+			if err != nil {
+				return nil, err
+			}
+			conn.SetTimeout(ldapTimeout)
+			return conn, nil
+		},
+	}
+}
+
+// Authenticate checks a username and password, returning the user's login name. The
+// user's memberOf groups are checked against AllowedGroups.
+func (l *LDAP) Authenticate(ctx context.Context, username, password string) (string, error) {
+	username = strings.TrimSpace(username)
+	// Most servers treat a bind with an empty password as anonymous and let it succeed
+	if username == "" || password == "" {
+		return "", ErrInvalidCredentials
+	}
+
+	conn, err := l.dial(l.config.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to ldap: %w", err)
+	}
+	defer conn.Close()
+
+	if l.config.StartTLS {
+		if err := conn.StartTLS(&tls.Config{ServerName: hostname(l.config.URL)}); err != nil {
+			return "", fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if l.config.BindDN != "" {
+		if err := conn.Bind(l.config.BindDN, l.config.BindPassword); err != nil {
+			return "", fmt.Errorf("failed to bind service account: %w", err)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		l.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(ldapTimeout.Seconds()), false,
+		fmt.Sprintf(l.config.UserFilter, ldap.EscapeFilter(username)),
+		[]string{"memberOf"}, nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return "", fmt.Errorf("failed to search for user: %w", err)
+	}
+	// Unknown and ambiguous names look the same as a wrong password
+	if result == nil || len(result.Entries) != 1 {
+		return "", ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return "", ErrInvalidCredentials
+		}
+		return "", fmt.Errorf("failed to bind user: %w", err)
+	}
+
+	if len(l.config.AllowedGroups) > 0 && !inAnyGroup(entry.GetAttributeValues("memberOf"), l.config.AllowedGroups) {
+		return "", ErrGroupNotAllowed
+	}
+
+	return strings.ToLower(username), nil
+}
+
+// inAnyGroup reports whether any of the group DNs matches an allowed group by DN or
+// common name
+func inAnyGroup(groups, allowed []string) bool {
+	for _, group := range groups {
+		for _, want := range allowed {
+			if strings.EqualFold(group, want) || strings.EqualFold(commonName(group), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commonName returns the value of a DN's leading CN, or "" if it has none
+func commonName(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 {
+		return ""
+	}
+	for _, attr := range parsed.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// hostname returns the host part of an LDAP URL for certificate verification
+func hostname(url string) string {
+	host := url
+	if _, rest, found := strings.Cut(url, "://"); found {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// fakeDirectory is an ldapConn over a fixed set of users
+type fakeDirectory struct {
+	users      map[string]*fakeUser // by DN
+	startedTLS bool
+	bound      string
+	filters    []string
+}
+
+type fakeUser struct {
+	uid      string
+	password string
+	groups   []string
+}
+
+func (d *fakeDirectory) StartTLS(config *tls.Config) error {
+	d.startedTLS = true
+	return nil
+}
+
+func (d *fakeDirectory) Bind(username, password string) error {
+	if username == "cn=svc,dc=example,dc=com" && password == "svc-pass" {
+		d.bound = username
+		return nil
+	}
+	if user, ok := d.users[username]; ok && user.password == password {
+		d.bound = username
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+}
+
+func (d *fakeDirectory) Search(request *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	d.filters = append(d.filters, request.Filter)
+	if d.bound == "" {
+		return nil, ldap.NewError(ldap.LDAPResultInsufficientAccessRights, errors.New("bind first"))
+	}
+
+	result := &ldap.SearchResult{}
+	for dn, user := range d.users {
+		if strings.EqualFold(request.Filter, "(uid="+user.uid+")") {
+			result.Entries = append(result.Entries, ldap.NewEntry(dn, map[string][]string{"memberOf": user.groups}))
+		}
+	}
+	return result, nil
+}
+
+func (d *fakeDirectory) Close() error {
+	return nil
+}
+
+func newFakeLDAP(config LDAPConfig) (*LDAP, *fakeDirectory) {
+	directory := &fakeDirectory{users: map[string]*fakeUser{
+		"uid=alice,ou=people,dc=example,dc=com": {
+			uid: "alice", password: "wonderland",
+			groups: []string{"cn=engineering,ou=groups,dc=example,dc=com"},
+		},
+		"uid=bob,ou=people,dc=example,dc=com": {uid: "bob", password: "builder"},
+		// Two entries with the same name are ambiguous
+		"uid=twin,ou=a,dc=example,dc=com": {uid: "twin", password: "pass"},
+		"uid=twin,ou=b,dc=example,dc=com": {uid: "twin", password: "pass"},
+	}}
+
+	config.URL = "ldap://ldap.example.com:389"
+	config.BindDN = "cn=svc,dc=example,dc=com"
+	config.BindPassword = "svc-pass"
+	config.BaseDN = "dc=example,dc=com"
+	l := NewLDAP(config)
+	l.dial = func(url string) (ldapConn, error) {
+		directory.bound = ""
+		return directory, nil
+	}
+	return l, directory
+}
+
+func TestLDAP_Authenticate(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   []string
+		username string
+		password string
+		wantUser string
+		wantErr  error
+	}{
+		{name: "valid", username: "alice", password: "wonderland", wantUser: "alice"},
+		{name: "name is case folded", username: " Alice ", password: "wonderland", wantUser: "alice"},
+		{name: "wrong password", username: "alice", password: "nope", wantErr: ErrInvalidCredentials},
+		{name: "empty password", username: "alice", password: "", wantErr: ErrInvalidCredentials},
+		{name: "unknown user", username: "carol", password: "wonderland", wantErr: ErrInvalidCredentials},
+		{name: "ambiguous user", username: "twin", password: "pass", wantErr: ErrInvalidCredentials},
+		{name: "filter injection", username: "*", password: "wonderland", wantErr: ErrInvalidCredentials},
+		{name: "allowed group by cn", groups: []string{"Engineering"}, username: "alice", password: "wonderland", wantUser: "alice"},
+		{name: "allowed group by dn", groups: []string{"cn=engineering,ou=groups,dc=example,dc=com"}, username: "alice", password: "wonderland", wantUser: "alice"},
+		{name: "not in allowed group", groups: []string{"engineering"}, username: "bob", password: "builder", wantErr: ErrGroupNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newFakeLDAP(LDAPConfig{AllowedGroups: tt.groups})
+
+			user, err := l.Authenticate(context.Background(), tt.username, tt.password)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("LDAP.Authenticate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || user != tt.wantUser {
+				t.Errorf("LDAP.Authenticate() = %q, %v, want %q", user, err, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestLDAP_Authenticate_Connection(t *testing.T) {
+	l, directory := newFakeLDAP(LDAPConfig{StartTLS: true})
+
+	if _, err := l.Authenticate(context.Background(), "al*ce)(uid=", "wonderland"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("LDAP.Authenticate() error = %v, want ErrInvalidCredentials", err)
+	}
+	if !directory.startedTLS {
+		t.Error("LDAP.Authenticate() did not start TLS")
+	}
+	if want := `(uid=al\2ace\29\28uid=)`; len(directory.filters) != 1 || directory.filters[0] != want {
+		t.Errorf("LDAP.Authenticate() filters = %v, want %s", directory.filters, want)
+	}
+
+	l.dial = func(url string) (ldapConn, error) { return nil, errors.New("connection refused") }
+	if _, err := l.Authenticate(context.Background(), "alice", "wonderland"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("LDAP.Authenticate() error = %v, want a connection error", err)
+	}
+}
+
+func TestHostname(t *testing.T) {
+	tests := map[string]string{
+		"ldap://ldap.example.com:389": "ldap.example.com",
+		"ldaps://ldap.example.com":    "ldap.example.com",
+		"ldap://[::1]:389/dc=example": "::1",
+		"ldap.example.com":            "ldap.example.com",
+	}
+
+	for url, want := range tests {
+		if got := hostname(url); got != want {
+			t.Errorf("hostname(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package auth signs users in with Google or LDAP and keeps them signed in with session cookies.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// SessionCookie holds the session token
+	SessionCookie = "golinks_session"
+
+	// SessionTTL is how long a login lasts before the user has to sign in again
+	SessionTTL = 7 * 24 * time.Hour
+)
+
+// SessionStore keeps sessions on the server, keyed by a hash of their token
+type SessionStore interface {
+	Create(ctx context.Context, tokenHash, user string, expiresAt time.Time) error
+	GetUser(ctx context.Context, tokenHash string, now time.Time) (string, error)
+	Delete(ctx context.Context, tokenHash string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Sessions issues and checks session cookies. A cookie carries only a random token;
+// the user it signs in is kept in the store, so sessions survive restarts and logging
+// out ends them for good.
+type Sessions struct {
+	store  SessionStore
+	secure bool
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewSessions creates a session manager backed by store, logging the store's failures to
+// logger. Secure cookies are only sent over HTTPS.
+func NewSessions(store SessionStore, secure bool, logger *slog.Logger) *Sessions {
+	return &Sessions{store: store, secure: secure, logger: logger, now: time.Now}
+}
+
+// Issue starts a session for user and sets its cookie
+func (s *Sessions) Issue(ctx context.Context, w http.ResponseWriter, user string) error {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := s.now()
+	expires := now.Add(SessionTTL)
+	if err := s.store.Create(ctx, hashToken(token), user, expires); err != nil {
+		return err
+	}
+
+	// Logins are rare enough to sweep out expired sessions on each one
+	if _, err := s.store.DeleteExpired(ctx, now); err != nil {
+		s.logger.Error("Failed to delete expired sessions", "err", err)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// User returns the user signed in by the request's session cookie, if its session
+// exists and has not expired
+func (s *Sessions) User(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+
+	user, err := s.store.GetUser(r.Context(), hashToken(cookie.Value), s.now())
+	if err != nil {
+		s.logger.Error("Failed to look up session", "err", err)
+		return "", false
+	}
+
+	return user, user != ""
+}
+
+// Clear ends the request's session and removes its cookie, signing the user out
+func (s *Sessions) Clear(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(SessionCookie); err == nil && cookie.Value != "" {
+		if err := s.store.Delete(r.Context(), hashToken(cookie.Value)); err != nil {
+			s.logger.Error("Failed to delete session", "err", err)
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Secure reports whether cookies are restricted to HTTPS
+func (s *Sessions) Secure() bool {
+	return s.secure
+}
+
+// hashToken returns the form a session token is stored in, so a leaked database does not
+// leak live sessions
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
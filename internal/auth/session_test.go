@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// memoryStore is an in-memory SessionStore
+type memoryStore struct {
+	users   map[string]string
+	expires map[string]time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{users: map[string]string{}, expires: map[string]time.Time{}}
+}
+
+func (m *memoryStore) Create(ctx context.Context, tokenHash, user string, expiresAt time.Time) error {
+	m.users[tokenHash] = user
+	m.expires[tokenHash] = expiresAt
+	return nil
+}
+
+func (m *memoryStore) GetUser(ctx context.Context, tokenHash string, now time.Time) (string, error) {
+	if !now.Before(m.expires[tokenHash]) {
+		return "", nil
+	}
+	return m.users[tokenHash], nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, tokenHash string) error {
+	delete(m.users, tokenHash)
+	delete(m.expires, tokenHash)
+	return nil
+}
+
+func (m *memoryStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for hash, expires := range m.expires {
+		if !now.Before(expires) {
+			m.Delete(ctx, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// sessionRequest returns a request carrying the session cookie set on w
+func sessionRequest(t *testing.T, w *httptest.ResponseRecorder) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestSessions(t *testing.T) {
+	store := newMemoryStore()
+	sessions := NewSessions(store, true, slog.Default())
+
+	w := httptest.NewRecorder()
+	if err := sessions.Issue(context.Background(), w, "alice@example.com"); err != nil {
+		t.Fatalf("Sessions.Issue() error = %v", err)
+	}
+
+	cookie := w.Result().Cookies()[0]
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+		t.Errorf("Sessions.Issue() cookie = %+v, want HttpOnly, Secure, SameSite=Lax on /", cookie)
+	}
+	if _, ok := store.users[cookie.Value]; ok {
+		t.Error("Sessions.Issue() stored the raw token instead of its hash")
+	}
+
+	if user, ok := sessions.User(sessionRequest(t, w)); !ok || user != "alice@example.com" {
+		t.Errorf("Sessions.User() = %q, %v, want alice@example.com", user, ok)
+	}
+
+	// Another manager over the same store, as after a restart, accepts the cookie
+	if user, ok := NewSessions(store, true, slog.Default()).User(sessionRequest(t, w)); !ok || user != "alice@example.com" {
+		t.Errorf("Sessions.User() after restart = %q, %v, want alice@example.com", user, ok)
+	}
+
+	// Sessions expire
+	sessions.now = func() time.Time { return time.Now().Add(SessionTTL + time.Minute) }
+	if _, ok := sessions.User(sessionRequest(t, w)); ok {
+		t.Error("Sessions.User() accepted an expired session")
+	}
+	sessions.now = time.Now
+
+	clear := httptest.NewRecorder()
+	sessions.Clear(clear, sessionRequest(t, w))
+	if cookie := clear.Result().Cookies()[0]; cookie.Name != SessionCookie || cookie.MaxAge >= 0 {
+		t.Errorf("Sessions.Clear() cookie = %+v, want it deleted", cookie)
+	}
+
+	// A cleared session stays ended even if the old cookie is replayed
+	if _, ok := sessions.User(sessionRequest(t, w)); ok {
+		t.Error("Sessions.User() accepted a session after Clear()")
+	}
+}
+
+func TestSessions_RejectsUnknownTokens(t *testing.T) {
+	sessions := NewSessions(newMemoryStore(), false, slog.Default())
+
+	w := httptest.NewRecorder()
+	sessions.Issue(context.Background(), w, "alice@example.com")
+	value := w.Result().Cookies()[0].Value
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"empty", ""},
+		{"truncated", value[:len(value)-1]},
+		{"garbage", "not-a-session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.AddCookie(&http.Cookie{Name: SessionCookie, Value: tt.value})
+			if user, ok := sessions.User(req); ok {
+				t.Errorf("Sessions.User() = %q for an unknown token", user)
+			}
+		})
+	}
+
+	if _, ok := sessions.User(httptest.NewRequest("GET", "/", nil)); ok {
+		t.Error("Sessions.User() accepted a request without a cookie")
+	}
+}
+
+func TestSessions_SweepsExpired(t *testing.T) {
+	store := newMemoryStore()
+	sessions := NewSessions(store, false, slog.Default())
+
+	sessions.now = func() time.Time { return time.Now().Add(-SessionTTL - time.Hour) }
+	sessions.Issue(context.Background(), httptest.NewRecorder(), "alice@example.com")
+
+	sessions.now = time.Now
+	sessions.Issue(context.Background(), httptest.NewRecorder(), "bob@example.com")
+
+	if len(store.users) != 1 {
+		t.Errorf("Sessions.Issue() left %d sessions, want the expired one swept", len(store.users))
+	}
+}
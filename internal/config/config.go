@@ -1,8 +1,11 @@
 package config
 
 import (
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,37 +16,425 @@ type Config struct {
 	DatabasePath string `json:"database_path"`
 	BaseURL      string `json:"base_url"`
 	Environment  string `json:"environment"`
+
+	// ListenAddr is where the server accepts connections in place of Port: a TCP address
+	// such as 127.0.0.1:8080, unix:/path/to/socket, or systemd for socket activation
+	ListenAddr string `json:"listen_addr"`
+
+	// TLSCertFile and TLSKeyFile serve HTTPS on Port with this certificate and key
+	TLSCertFile string `json:"tls_cert_file"`
+	TLSKeyFile  string `json:"tls_key_file"`
+
+	// ACMEHosts serves HTTPS on Port with certificates obtained from Let's Encrypt for
+	// these host names, and no others
+	ACMEHosts []string `json:"acme_hosts"`
+
+	// ACMECacheDir keeps the certificates and account key between restarts, and ACMEEmail
+	// is where Let's Encrypt sends notices about them
+	ACMECacheDir string `json:"acme_cache_dir"`
+	ACMEEmail    string `json:"acme_email"`
+
+	// ACMEHTTPPort answers HTTP challenges and redirects other requests to HTTPS; zero
+	// leaves certificates to the TLS challenge on Port
+	ACMEHTTPPort int `json:"acme_http_port"`
+
+	// StorageDriver names the registered storage driver: sqlite, postgres or memory
+	StorageDriver string `json:"storage_driver"`
+
+	// DatabaseURL is the data source for drivers other than sqlite, such as a postgres:// URL
+	DatabaseURL string `json:"-"`
+
+	// SQLiteJournalMode, SQLiteBusyTimeout (milliseconds) and SQLiteSynchronous tune
+	// the sqlite driver's connections
+	SQLiteJournalMode string `json:"sqlite_journal_mode"`
+	SQLiteBusyTimeout int    `json:"sqlite_busy_timeout"`
+	SQLiteSynchronous string `json:"sqlite_synchronous"`
+
+	// DBMaxOpenConns, DBMaxIdleConns and DBConnMaxLifetime size the database connection
+	// pool; zero leaves the database/sql default
+	DBMaxOpenConns    int           `json:"db_max_open_conns"`
+	DBMaxIdleConns    int           `json:"db_max_idle_conns"`
+	DBConnMaxLifetime time.Duration `json:"db_conn_max_lifetime"`
+
+	// DBConnectRetries is how many more times connecting to the database and migrating it
+	// are tried at startup before giving up, waiting DBConnectBackoff before the first retry
+	// and twice as long before each one after
+	DBConnectRetries int           `json:"db_connect_retries"`
+	DBConnectBackoff time.Duration `json:"db_connect_backoff"`
+
+	// BackupDir is where admin backups of the SQLite database are kept; empty disables them
+	BackupDir string `json:"backup_dir"`
+
+	// SnapshotURL is where every link is saved each SnapshotInterval, an object storage
+	// location like s3://bucket/prefix or gs://bucket/prefix; empty disables snapshots
+	SnapshotURL      string        `json:"snapshot_url"`
+	SnapshotInterval time.Duration `json:"snapshot_interval"`
+
+	// SnapshotKeep is how many snapshots are kept before the oldest are deleted; zero keeps
+	// them all
+	SnapshotKeep int `json:"snapshot_keep"`
+
+	// SnapshotDatabase saves a copy of the SQLite database with each snapshot
+	SnapshotDatabase bool `json:"snapshot_database"`
+
+	// SnapshotEndpoint and SnapshotRegion locate the object store, defaulting to Amazon S3
+	// or Google Cloud Storage by the scheme of SnapshotURL, and the access key signs requests
+	// to it
+	SnapshotEndpoint        string `json:"snapshot_endpoint"`
+	SnapshotRegion          string `json:"snapshot_region"`
+	SnapshotAccessKeyID     string `json:"snapshot_access_key_id"`
+	SnapshotSecretAccessKey string `json:"-"`
+
+	// SyncPrimaryURL makes this server a replica of the golinks server at that URL, pulling
+	// the links changed there each SyncInterval with SyncAPIKey, an admin's API key; empty
+	// disables syncing
+	SyncPrimaryURL string        `json:"sync_primary_url"`
+	SyncInterval   time.Duration `json:"sync_interval"`
+	SyncAPIKey     string        `json:"-"`
+
+	// NotifyWebhookURL is a channel's incoming webhook that is told about every change to
+	// a public link, in NotifyWebhookFormat: slack, teams or json; empty announces nothing
+	NotifyWebhookURL    string `json:"-"`
+	NotifyWebhookFormat string `json:"notify_webhook_format"`
+
+	// LinkCacheSize is how many resolved words are cached in memory, for LinkCacheTTL each;
+	// zero disables the cache
+	LinkCacheSize int           `json:"link_cache_size"`
+	LinkCacheTTL  time.Duration `json:"link_cache_ttl"`
+
+	// KeywordCacheTTL is how long the cached keyword lists are served before checking
+	// the database for changes made by other servers; zero disables the cache
+	KeywordCacheTTL time.Duration `json:"keyword_cache_ttl"`
+
+	// RedisURL shares the link cache between servers through Redis when set, such as
+	// redis://localhost:6379/0
+	RedisURL string `json:"-"`
+
+	// QueryLogBuffer is how many followed golinks may wait to be written to the query log
+	// before more are dropped; zero writes each one before redirecting
+	QueryLogBuffer int `json:"query_log_buffer"`
+
+	// QueryRetentionDays is how many days of the query log are kept before older queries
+	// are rolled up into daily counts; zero keeps them all
+	QueryRetentionDays int `json:"query_retention_days"`
+
+	// AutoMigrate applies pending schema migrations at startup; when off, the server
+	// refuses to start until they are applied with the migrate command
+	AutoMigrate bool `json:"auto_migrate"`
+
+	// UniqueWords keeps one row per word that edits update in place, with the history
+	// of each word in a separate versions table
+	UniqueWords bool `json:"unique_words"`
+
+	// LogLevel is the least severe level logged: debug, info, warn or error
+	LogLevel string `json:"log_level"`
+
+	// LogFormat is text for key=value log lines or json for one JSON object per line
+	LogFormat string `json:"log_format"`
+
+	// WebDir holds the templates and static files the web interface is served from
+	WebDir string `json:"web_dir"`
+
+	// ResponseTimeHeader enables the X-Response-Time header on responses
+	ResponseTimeHeader bool `json:"response_time_header"`
+
+	// LinkIcons enables storing and showing an icon or emoji per shortcut
+	LinkIcons bool `json:"link_icons"`
+
+	// AllowedSchemes lists non-HTTP URL schemes (e.g. slack, zoommtg) accepted as link targets
+	AllowedSchemes []string `json:"allowed_schemes"`
+
+	// RedirectStatus is the status golinks redirect to their targets with: 302 or 307 so
+	// edits take effect at once, or 301 or 308 so browsers cache the redirect
+	RedirectStatus int `json:"redirect_status"`
+
+	// TrustedDomains lists the domains, subdomains included, golinks redirect to straight
+	// away. When set, golinks to anywhere else show their destination and owner first.
+	TrustedDomains []string `json:"trusted_domains"`
+
+	// QueryPassthrough adds the query string of a golink request, like go/dash?env=prod, to
+	// the query string of its target
+	QueryPassthrough bool `json:"query_passthrough"`
+
+	// FallbackSearchURL is where queries no keyword matches are sent, with {*} replaced by
+	// the query, instead of to the homepage offering to create the link
+	FallbackSearchURL string `json:"fallback_search_url"`
+
+	// DeniedLinkDomains lists domains, subdomains included, new links may not point to,
+	// such as URL shorteners. Admins can change it while the server runs.
+	DeniedLinkDomains []string `json:"denied_link_domains"`
+
+	// AllowedLinkDomains, when set, lists the only domains, subdomains included, new links
+	// may point to. Admins can change it while the server runs.
+	AllowedLinkDomains []string `json:"allowed_link_domains"`
+
+	// LinkCheck requests the target of a link as it is saved to catch typos: "warn" logs
+	// targets that look broken, "reject" refuses them and "off" skips the check
+	LinkCheck string `json:"link_check"`
+
+	// LinkCheckTimeout is how long a link's target has to answer the check
+	LinkCheckTimeout time.Duration `json:"link_check_timeout"`
+
+	// WordMinLength and WordMaxLength bound the characters in new keywords; a maximum of 0
+	// sets no limit
+	WordMinLength int `json:"word_min_length"`
+	WordMaxLength int `json:"word_max_length"`
+
+	// WordSeparators, when set, are the only characters besides letters, digits and / new
+	// keywords may contain
+	WordSeparators string `json:"word_separators"`
+
+	// WordPattern, when set, is a regular expression new keywords must match in full, and
+	// WordPatternHint the message shown to people whose keyword doesn't
+	WordPattern     string `json:"word_pattern"`
+	WordPatternHint string `json:"word_pattern_hint"`
+
+	// DeadLinkCheckInterval is how often the target of every link is checked, with the
+	// broken ones listed for admins, or 0 not to check them
+	DeadLinkCheckInterval time.Duration `json:"dead_link_check_interval"`
+
+	// SMTPAddr is the host:port of the mail server owners are emailed about their broken
+	// links through, from SMTPFrom, or empty to email nobody. SMTPUsername and
+	// SMTPPassword sign in to it when set.
+	SMTPAddr     string `json:"smtp_addr"`
+	SMTPFrom     string `json:"smtp_from"`
+	SMTPUsername string `json:"smtp_username"`
+	SMTPPassword string `json:"-"`
+
+	// AdminUsers may update, transfer and delete golinks owned by other users
+	AdminUsers []string `json:"admin_users"`
+
+	// DefaultRole is the role of users who have not been assigned one: viewer, editor or admin
+	DefaultRole string `json:"default_role"`
+
+	// GRPCPort serves the gRPC API on a second port when non-zero
+	GRPCPort int `json:"grpc_port"`
+
+	// GoogleClientID and GoogleClientSecret enable Google sign-in when set
+	GoogleClientID     string `json:"google_client_id"`
+	GoogleClientSecret string `json:"-"`
+
+	// AllowedDomains restricts Google sign-in to these Google Workspace domains
+	AllowedDomains []string `json:"allowed_domains"`
+
+	// LDAPURL enables signing in with a directory username and password instead of Google
+	LDAPURL      string `json:"ldap_url"`
+	LDAPStartTLS bool   `json:"ldap_start_tls"`
+
+	// LDAPBindDN and LDAPBindPassword are the service account used to look users up
+	LDAPBindDN       string `json:"ldap_bind_dn"`
+	LDAPBindPassword string `json:"-"`
+
+	// LDAPBaseDN and LDAPUserFilter locate a user entry by login name
+	LDAPBaseDN     string `json:"ldap_base_dn"`
+	LDAPUserFilter string `json:"ldap_user_filter"`
+
+	// LDAPAllowedGroups restricts sign-in to members of these groups
+	LDAPAllowedGroups []string `json:"ldap_allowed_groups"`
 }
 
-// Load loads configuration from environment variables and .env file
-func Load() (*Config, error) {
+// Load loads configuration from the config file, environment variables and .env file,
+// and command-line flags, each overriding the ones before. It also returns the arguments
+// left after the flags.
+func Load(args []string) (*Config, []string, error) {
+	flags, configFile, rest, err := parseFlags(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Load .env file if it exists (ignore error if file doesn't exist)
 	_ = godotenv.Load()
 
+	// Settings from the config file only fill in what the environment leaves unset
+	var file map[string]string
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+	if configFile == "" {
+		if _, err := os.Stat(DefaultConfigFile); err == nil {
+			configFile = DefaultConfigFile
+		}
+	}
+	if configFile != "" {
+		if file, err = readConfigFile(configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	env := layerSettings(file, flags)
+
 	cfg := &Config{
-		Port:         getEnvAsInt("PORT", 8080),
-		DatabasePath: getEnv("DATABASE_PATH", "golinks.db"),
-		BaseURL:      getEnv("BASE_URL", "http://localhost:8080"),
-		Environment:  getEnv("ENVIRONMENT", "development"),
+		Port:         getEnvAsInt(env, "PORT", 8080),
+		DatabasePath: getEnv(env, "DATABASE_PATH", "golinks.db"),
+		BaseURL:      getEnv(env, "BASE_URL", "http://localhost:8080"),
+		Environment:  getEnv(env, "ENVIRONMENT", "development"),
+		ListenAddr:   getEnv(env, "LISTEN_ADDR", ""),
+
+		TLSCertFile:  getEnv(env, "TLS_CERT_FILE", ""),
+		TLSKeyFile:   getEnv(env, "TLS_KEY_FILE", ""),
+		ACMEHosts:    getEnvAsSlice(env, "ACME_HOSTS", nil),
+		ACMECacheDir: getEnv(env, "ACME_CACHE_DIR", "acme-cache"),
+		ACMEEmail:    getEnv(env, "ACME_EMAIL", ""),
+		ACMEHTTPPort: getEnvAsInt(env, "ACME_HTTP_PORT", 80),
+
+		StorageDriver: getEnv(env, "STORAGE_DRIVER", "sqlite"),
+		DatabaseURL:   getEnv(env, "DATABASE_URL", ""),
+		AutoMigrate:   getEnvAsBool(env, "AUTO_MIGRATE", true),
+		UniqueWords:   getEnvAsBool(env, "UNIQUE_WORDS", false),
+
+		SQLiteJournalMode: getEnv(env, "SQLITE_JOURNAL_MODE", "WAL"),
+		SQLiteBusyTimeout: getEnvAsInt(env, "SQLITE_BUSY_TIMEOUT", 5000),
+		SQLiteSynchronous: getEnv(env, "SQLITE_SYNCHRONOUS", "NORMAL"),
+
+		BackupDir:          getEnv(env, "BACKUP_DIR", ""),
+		QueryRetentionDays: getEnvAsInt(env, "QUERY_RETENTION_DAYS", 0),
+		QueryLogBuffer:     getEnvAsInt(env, "QUERY_LOG_BUFFER", 1000),
+
+		SnapshotURL:             getEnv(env, "SNAPSHOT_URL", ""),
+		SnapshotInterval:        getEnvAsDuration(env, "SNAPSHOT_INTERVAL", 24*time.Hour),
+		SnapshotKeep:            getEnvAsInt(env, "SNAPSHOT_KEEP", 7),
+		SnapshotDatabase:        getEnvAsBool(env, "SNAPSHOT_DATABASE", false),
+		SnapshotEndpoint:        getEnv(env, "SNAPSHOT_ENDPOINT", ""),
+		SnapshotRegion:          getEnv(env, "SNAPSHOT_REGION", ""),
+		SnapshotAccessKeyID:     getEnv(env, "SNAPSHOT_ACCESS_KEY_ID", ""),
+		SnapshotSecretAccessKey: getEnv(env, "SNAPSHOT_SECRET_ACCESS_KEY", ""),
+
+		SyncPrimaryURL: getEnv(env, "SYNC_PRIMARY_URL", ""),
+		SyncInterval:   getEnvAsDuration(env, "SYNC_INTERVAL", 30*time.Second),
+		SyncAPIKey:     getEnv(env, "SYNC_API_KEY", ""),
+
+		NotifyWebhookURL:    getEnv(env, "NOTIFY_WEBHOOK_URL", ""),
+		NotifyWebhookFormat: getEnv(env, "NOTIFY_WEBHOOK_FORMAT", "slack"),
+
+		LinkCacheSize: getEnvAsInt(env, "LINK_CACHE_SIZE", 0),
+		LinkCacheTTL:  getEnvAsDuration(env, "LINK_CACHE_TTL", time.Minute),
+		RedisURL:      getEnv(env, "REDIS_URL", ""),
+
+		KeywordCacheTTL: getEnvAsDuration(env, "KEYWORD_CACHE_TTL", 5*time.Second),
+
+		DBMaxOpenConns:    getEnvAsInt(env, "DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:    getEnvAsInt(env, "DB_MAX_IDLE_CONNS", 25),
+		DBConnMaxLifetime: getEnvAsDuration(env, "DB_CONN_MAX_LIFETIME", 5*time.Minute),
+		DBConnectRetries:  getEnvAsInt(env, "DB_CONNECT_RETRIES", 0),
+		DBConnectBackoff:  getEnvAsDuration(env, "DB_CONNECT_BACKOFF", time.Second),
+
+		LogLevel:           getEnv(env, "LOG_LEVEL", "info"),
+		LogFormat:          getEnv(env, "LOG_FORMAT", "text"),
+		WebDir:             getEnv(env, "WEB_DIR", "web"),
+		ResponseTimeHeader: getEnvAsBool(env, "RESPONSE_TIME_HEADER", false),
+		LinkIcons:          getEnvAsBool(env, "LINK_ICONS", false),
+		AllowedSchemes:     getEnvAsSlice(env, "ALLOWED_SCHEMES", nil),
+		RedirectStatus:     getEnvAsInt(env, "REDIRECT_STATUS", 302),
+		TrustedDomains:     getEnvAsSlice(env, "TRUSTED_DOMAINS", nil),
+		QueryPassthrough:   getEnvAsBool(env, "QUERY_PASSTHROUGH", false),
+		FallbackSearchURL:  getEnv(env, "FALLBACK_SEARCH_URL", ""),
+		DeniedLinkDomains:  getEnvAsSlice(env, "DENIED_LINK_DOMAINS", nil),
+		AllowedLinkDomains: getEnvAsSlice(env, "ALLOWED_LINK_DOMAINS", nil),
+		LinkCheck:          getEnv(env, "LINK_CHECK", "off"),
+		LinkCheckTimeout:   getEnvAsDuration(env, "LINK_CHECK_TIMEOUT", 5*time.Second),
+
+		WordMinLength:   getEnvAsInt(env, "WORD_MIN_LENGTH", 1),
+		WordMaxLength:   getEnvAsInt(env, "WORD_MAX_LENGTH", 0),
+		WordSeparators:  getEnv(env, "WORD_SEPARATORS", ""),
+		WordPattern:     getEnv(env, "WORD_PATTERN", ""),
+		WordPatternHint: getEnv(env, "WORD_PATTERN_HINT", ""),
+
+		DeadLinkCheckInterval: getEnvAsDuration(env, "DEAD_LINK_CHECK_INTERVAL", 0),
+		SMTPAddr:              getEnv(env, "SMTP_ADDR", ""),
+		SMTPFrom:              getEnv(env, "SMTP_FROM", ""),
+		SMTPUsername:          getEnv(env, "SMTP_USERNAME", ""),
+		SMTPPassword:          getEnv(env, "SMTP_PASSWORD", ""),
+
+		AdminUsers:         getEnvAsSlice(env, "ADMIN_USERS", nil),
+		DefaultRole:        getEnv(env, "DEFAULT_ROLE", "editor"),
+		GRPCPort:           getEnvAsInt(env, "GRPC_PORT", 0),
+		GoogleClientID:     getEnv(env, "GOOGLE_CLIENT_ID", ""),
+		GoogleClientSecret: getEnv(env, "GOOGLE_CLIENT_SECRET", ""),
+		AllowedDomains:     getEnvAsSlice(env, "ALLOWED_DOMAINS", nil),
+		LDAPURL:            getEnv(env, "LDAP_URL", ""),
+		LDAPStartTLS:       getEnvAsBool(env, "LDAP_START_TLS", false),
+		LDAPBindDN:         getEnv(env, "LDAP_BIND_DN", ""),
+		LDAPBindPassword:   getEnv(env, "LDAP_BIND_PASSWORD", ""),
+		LDAPBaseDN:         getEnv(env, "LDAP_BASE_DN", ""),
+		LDAPUserFilter:     getEnv(env, "LDAP_USER_FILTER", "(uid=%s)"),
+		LDAPAllowedGroups:  getEnvAsSlice(env, "LDAP_ALLOWED_GROUPS", nil),
 	}
 
-	return cfg, nil
+	return cfg, rest, nil
 }
 
-// getEnv gets an environment variable with a fallback value
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
+// StorageDSN returns the data source for the storage driver: DatabaseURL if set,
+// otherwise DatabasePath with the SQLite connection options
+func (c *Config) StorageDSN() string {
+	if c.DatabaseURL != "" {
+		return c.DatabaseURL
+	}
+
+	options := url.Values{}
+	if c.SQLiteJournalMode != "" {
+		options.Set("journal_mode", c.SQLiteJournalMode)
+	}
+	if c.SQLiteBusyTimeout > 0 {
+		options.Set("busy_timeout", strconv.Itoa(c.SQLiteBusyTimeout))
+	}
+	if c.SQLiteSynchronous != "" {
+		options.Set("synchronous", c.SQLiteSynchronous)
+	}
+	if len(options) == 0 {
+		return c.DatabasePath
+	}
+	return c.DatabasePath + "?" + options.Encode()
+}
+
+// getEnv gets a setting by environment variable name with a fallback value
+func getEnv(env map[string]string, key, fallback string) string {
+	if value := env[key]; value != "" {
 		return value
 	}
 	return fallback
 }
 
-// getEnvAsInt gets an environment variable as integer with a fallback value
-func getEnvAsInt(key string, fallback int) int {
-	if value := os.Getenv(key); value != "" {
+// getEnvAsInt gets a setting as integer with a fallback value
+func getEnvAsInt(env map[string]string, key string, fallback int) int {
+	if value := env[key]; value != "" {
 		if intVal, err := strconv.Atoi(value); err == nil {
 			return intVal
 		}
 	}
 	return fallback
 }
+
+// getEnvAsBool gets a setting as boolean with a fallback value
+func getEnvAsBool(env map[string]string, key string, fallback bool) bool {
+	if value := env[key]; value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return fallback
+}
+
+// getEnvAsDuration gets a setting as a duration such as 90s or 5m with a fallback value
+func getEnvAsDuration(env map[string]string, key string, fallback time.Duration) time.Duration {
+	if value := env[key]; value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return fallback
+}
+
+// getEnvAsSlice gets a comma-separated setting as a slice with a fallback value
+func getEnvAsSlice(env map[string]string, key string, fallback []string) []string {
+	value := env[key]
+	if value == "" {
+		return fallback
+	}
+
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
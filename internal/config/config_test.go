@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -94,7 +96,7 @@ func TestLoad(t *testing.T) {
 				os.Setenv(key, value)
 			}
 
-			cfg, err := Load()
+			cfg, _, err := Load(nil)
 			if err != nil {
 				t.Errorf("Load() error = %v", err)
 				return
@@ -159,7 +161,7 @@ func TestGetEnv(t *testing.T) {
 				os.Setenv(tt.key, tt.envValue)
 			}
 
-			result := getEnv(tt.key, tt.fallback)
+			result := getEnv(environment(), tt.key, tt.fallback)
 			if result != tt.expected {
 				t.Errorf("getEnv() = %v, want %v", result, tt.expected)
 			}
@@ -221,7 +223,7 @@ func TestGetEnvAsInt(t *testing.T) {
 				os.Setenv(tt.key, tt.envValue)
 			}
 
-			result := getEnvAsInt(tt.key, tt.fallback)
+			result := getEnvAsInt(environment(), tt.key, tt.fallback)
 			if result != tt.expected {
 				t.Errorf("getEnvAsInt() = %v, want %v", result, tt.expected)
 			}
@@ -231,7 +233,7 @@ func TestGetEnvAsInt(t *testing.T) {
 
 func TestConfigValidation(t *testing.T) {
 	// Test that Load() always returns a valid config
-	cfg, err := Load()
+	cfg, _, err := Load(nil)
 	if err != nil {
 		t.Errorf("Load() should not return error, got %v", err)
 	}
@@ -258,3 +260,177 @@ func TestConfigValidation(t *testing.T) {
 		t.Error("Environment should not be empty")
 	}
 }
+
+func TestGetEnvAsBool(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		fallback bool
+		envValue string
+		expected bool
+	}{
+		{
+			name:     "true value",
+			key:      "TEST_BOOL",
+			fallback: false,
+			envValue: "true",
+			expected: true,
+		},
+		{
+			name:     "numeric false",
+			key:      "TEST_BOOL",
+			fallback: true,
+			envValue: "0",
+			expected: false,
+		},
+		{
+			name:     "invalid value",
+			key:      "TEST_BOOL",
+			fallback: true,
+			envValue: "maybe",
+			expected: true,
+		},
+		{
+			name:     "empty value",
+			key:      "TEST_BOOL",
+			fallback: false,
+			envValue: "",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean up
+			defer os.Unsetenv(tt.key)
+
+			if tt.envValue != "" {
+				os.Setenv(tt.key, tt.envValue)
+			}
+
+			result := getEnvAsBool(environment(), tt.key, tt.fallback)
+			if result != tt.expected {
+				t.Errorf("getEnvAsBool() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetEnvAsDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		fallback time.Duration
+		envValue string
+		expected time.Duration
+	}{
+		{
+			name:     "minutes",
+			key:      "TEST_DURATION",
+			fallback: time.Second,
+			envValue: "5m",
+			expected: 5 * time.Minute,
+		},
+		{
+			name:     "invalid value",
+			key:      "TEST_DURATION",
+			fallback: time.Second,
+			envValue: "300",
+			expected: time.Second,
+		},
+		{
+			name:     "empty value",
+			key:      "TEST_DURATION",
+			fallback: time.Hour,
+			envValue: "",
+			expected: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean up
+			defer os.Unsetenv(tt.key)
+
+			if tt.envValue != "" {
+				os.Setenv(tt.key, tt.envValue)
+			}
+
+			result := getEnvAsDuration(environment(), tt.key, tt.fallback)
+			if result != tt.expected {
+				t.Errorf("getEnvAsDuration() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetEnvAsSlice(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		fallback []string
+		expected []string
+	}{
+		{
+			name:     "comma separated",
+			envValue: "slack, zoommtg ,vscode",
+			expected: []string{"slack", "zoommtg", "vscode"},
+		},
+		{
+			name:     "empty items skipped",
+			envValue: "slack,,",
+			expected: []string{"slack"},
+		},
+		{
+			name:     "empty value uses fallback",
+			envValue: "",
+			fallback: []string{"default"},
+			expected: []string{"default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean up
+			defer os.Unsetenv("TEST_SLICE")
+
+			if tt.envValue != "" {
+				os.Setenv("TEST_SLICE", tt.envValue)
+			}
+
+			result := getEnvAsSlice(environment(), "TEST_SLICE", tt.fallback)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("getEnvAsSlice() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_StorageDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"sqlite path", Config{DatabasePath: "golinks.db"}, "golinks.db"},
+		{"url wins", Config{DatabasePath: "golinks.db", DatabaseURL: "postgres://db/golinks"}, "postgres://db/golinks"},
+		{
+			"sqlite options",
+			Config{DatabasePath: "golinks.db", SQLiteJournalMode: "WAL", SQLiteBusyTimeout: 2500, SQLiteSynchronous: "FULL"},
+			"golinks.db?busy_timeout=2500&journal_mode=WAL&synchronous=FULL",
+		},
+		{
+			"sqlite options ignored with a url",
+			Config{DatabaseURL: "postgres://db/golinks", SQLiteJournalMode: "WAL"},
+			"postgres://db/golinks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.StorageDSN(); got != tt.want {
+				t.Errorf("Config.StorageDSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is read when it exists and no other config file is named
+const DefaultConfigFile = "golinks.yaml"
+
+// secretSettings are the settings left out of the JSON form of Config, which names the rest
+var secretSettings = []string{
+	"database_url", "redis_url", "google_client_secret", "ldap_bind_password", "snapshot_secret_access_key",
+	"sync_api_key", "notify_webhook_url",
+}
+
+// settings returns the name of every setting, the environment variable name in lower
+// case, and whether it is a boolean
+func settings() map[string]bool {
+	names := make(map[string]bool)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := field.Tag.Get("json"); name != "-" {
+			names[name] = field.Type.Kind() == reflect.Bool
+		}
+	}
+	for _, name := range secretSettings {
+		names[name] = false
+	}
+	return names
+}
+
+// settingFlag records a setting given on the command line
+type settingFlag struct {
+	name   string
+	isBool bool
+	values map[string]string
+}
+
+func (f *settingFlag) String() string { return "" }
+
+func (f *settingFlag) Set(value string) error {
+	f.values[f.name] = value
+	return nil
+}
+
+func (f *settingFlag) IsBoolFlag() bool { return f.isBool }
+
+// parseFlags reads settings from command-line flags such as -port 9090 or -link-icons,
+// returning them by setting name along with the config file named by -config and the
+// arguments left after the flags
+func parseFlags(args []string) (values map[string]string, configFile string, rest []string, err error) {
+	fs := flag.NewFlagSet("golinks", flag.ContinueOnError)
+	fs.StringVar(&configFile, "config", "", "YAML or TOML config file (default $CONFIG_FILE or "+DefaultConfigFile+")")
+
+	values = make(map[string]string)
+	for name, isBool := range settings() {
+		usage := "overrides $" + strings.ToUpper(name)
+		fs.Var(&settingFlag{name: name, isBool: isBool, values: values}, strings.ReplaceAll(name, "_", "-"), usage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, "", nil, err
+	}
+	return values, configFile, fs.Args(), nil
+}
+
+// readConfigFile reads settings from a YAML file, or a TOML file when the name ends in
+// .toml, by setting name. Lists are joined with commas the way environment variables
+// hold them.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	known := settings()
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("config file %s: unknown setting %q", path, name)
+		}
+		text, err := settingValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %s %w", path, name, err)
+		}
+		values[name] = text
+	}
+	return values, nil
+}
+
+// settingValue formats a value from a config file as an environment variable would hold it
+func settingValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			text, err := settingValue(item)
+			if err != nil {
+				return "", err
+			}
+			if strings.Contains(text, ",") {
+				return "", fmt.Errorf("list item %q must not contain a comma", text)
+			}
+			items = append(items, text)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", errors.New("must be a single value or a list, not a table")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// layerSettings merges settings from the config file, the environment and flags, each
+// overriding the ones before, keyed by environment variable name. It leaves the
+// environment itself alone, so secrets from the file stay out of child processes and a
+// later Load does not see this one's flags.
+func layerSettings(file, flags map[string]string) map[string]string {
+	values := make(map[string]string)
+	for name, value := range file {
+		values[strings.ToUpper(name)] = value
+	}
+	for key, value := range environment() {
+		if value != "" {
+			values[key] = value
+		}
+	}
+	for name, value := range flags {
+		values[strings.ToUpper(name)] = value
+	}
+	return values
+}
+
+// environment returns the environment variables by name
+func environment() map[string]string {
+	values := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// BackupSQLite writes a consistent snapshot of db to a new SQLite file at path using
+// SQLite's online backup API, so the database stays usable while it is copied. The
+// snapshot uses a rollback journal, so it is a single self-contained file.
+func BackupSQLite(ctx context.Context, db *sql.DB, path string) error {
+	dst, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer dst.Close()
+
+	if err := copySQLite(ctx, dst, db); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	if _, err := dst.ExecContext(ctx, "PRAGMA journal_mode=DELETE"); err != nil {
+		return fmt.Errorf("failed to finish backup file: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreSQLite replaces the contents of db with the SQLite snapshot at path. Open
+// connections see the restored data once it completes.
+func RestoreSQLite(ctx context.Context, db *sql.DB, path string) error {
+	src, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer src.Close()
+
+	// Reading the schema fails fast on files that aren't SQLite databases
+	var tables int
+	if err := src.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master").Scan(&tables); err != nil {
+		return fmt.Errorf("failed to read backup file: %w", err)
+	}
+
+	// The copy brings the snapshot's journal mode with it, so put back the database's own
+	var journalMode string
+	if err := db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		return fmt.Errorf("failed to read journal mode: %w", err)
+	}
+
+	if err := copySQLite(ctx, db, src); err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, "PRAGMA journal_mode="+journalMode); err != nil {
+		return fmt.Errorf("failed to restore journal mode: %w", err)
+	}
+
+	return nil
+}
+
+// copySQLite copies the whole of src's main database over dst's, retrying while either is locked
+func copySQLite(ctx context.Context, dst, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dstDriverConn interface{}) error {
+		return srcConn.Raw(func(srcDriverConn interface{}) error {
+			dstSQLite, ok := dstDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("destination is not a SQLite database")
+			}
+			srcSQLite, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("source is not a SQLite database")
+			}
+
+			backup, err := dstSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+
+			for {
+				done, err := backup.Step(-1)
+				if err != nil {
+					backup.Finish()
+					return err
+				}
+				if done {
+					break
+				}
+
+				// Step reports neither done nor an error while a database is locked
+				select {
+				case <-ctx.Done():
+					backup.Finish()
+					return ctx.Err()
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+
+			return backup.Finish()
+		})
+	})
+}
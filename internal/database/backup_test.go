@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupAndRestoreSQLite(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewSQLiteDB(filepath.Join(dir, "golinks.db"), Pool{MaxOpenConns: 4})
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := db.Exec("INSERT INTO linktable (word, link, user) VALUES ('docs', 'https://docs.example.com', 'alice')"); err != nil {
+		t.Fatalf("insert error = %v", err)
+	}
+
+	snapshot := filepath.Join(dir, "snapshot.db")
+	if err := BackupSQLite(ctx, db, snapshot); err != nil {
+		t.Fatalf("BackupSQLite() error = %v", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(snapshot + suffix); err == nil {
+			t.Errorf("BackupSQLite() left %s behind; snapshots should be a single file", snapshot+suffix)
+		}
+	}
+
+	if _, err := db.Exec("DELETE FROM linktable"); err != nil {
+		t.Fatalf("delete error = %v", err)
+	}
+
+	if err := RestoreSQLite(ctx, db, snapshot); err != nil {
+		t.Fatalf("RestoreSQLite() error = %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM linktable WHERE word = 'docs'").Scan(&count); err != nil {
+		t.Fatalf("count error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("after RestoreSQLite() found %d docs links, want 1", count)
+	}
+
+	var journalMode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		t.Fatalf("PRAGMA journal_mode error = %v", err)
+	}
+	if journalMode != "wal" {
+		t.Errorf("journal_mode after RestoreSQLite() = %q, want wal kept", journalMode)
+	}
+}
+
+func TestRestoreSQLite_NotADatabase(t *testing.T) {
+	db, err := NewSQLiteDB(":memory:", Pool{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	defer db.Close()
+
+	path := filepath.Join(t.TempDir(), "notes.db")
+	if err := os.WriteFile(path, []byte("these are not the pages you are looking for"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := RestoreSQLite(context.Background(), db, path); err == nil {
+		t.Error("RestoreSQLite() of a file that isn't a database should fail")
+	}
+}
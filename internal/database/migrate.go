@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Migration is one numbered change to the schema. Up applies it and Down reverts it;
+// each runs in a transaction together with its row in schema_migrations, so a failed
+// migration leaves the schema as it was.
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+
+	// UpFunc, when set, runs after Up for changes that SQL alone cannot express
+	UpFunc func(ctx context.Context, tx *sql.Tx) error
+}
+
+// MigrationStatus reports whether a migration has been applied and when
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies and reverts a dialect's migrations, recording the applied versions
+// in the schema_migrations table
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for db, which must use the dialect the migrations are written in
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	return &Migrator{db: db, migrations: sorted}
+}
+
+// Up applies every pending migration in order and returns the ones it applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		if err := m.apply(ctx, migration); err != nil {
+			return pending[:i], err
+		}
+	}
+
+	return pending, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns the ones it reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	var reverted []Migration
+	for _, version := range versions {
+		migration, ok := m.find(version)
+		if !ok {
+			return reverted, fmt.Errorf("migration %d is applied but unknown to this version of golinks", version)
+		}
+		if err := m.revert(ctx, migration); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, migration)
+	}
+
+	return reverted, nil
+}
+
+// Pending returns the migrations that have not been applied, in order
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Status reports every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+	}
+	return statuses, nil
+}
+
+// Version returns the newest applied migration, or 0 for an unmigrated database
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// applied returns when each applied migration was applied, creating schema_migrations if needed
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// apply runs a migration's Up statements and records it
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		for _, statement := range migration.Up {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+		if migration.UpFunc != nil {
+			if err := migration.UpFunc(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			migration.Version, migration.Name, time.Now().UTC().Truncate(time.Second),
+		)
+		return err
+	}, "apply", migration)
+}
+
+// revert runs a migration's Down statements and forgets it
+func (m *Migrator) revert(ctx context.Context, migration Migration) error {
+	if len(migration.Down) == 0 {
+		return fmt.Errorf("migration %d (%s) cannot be reverted", migration.Version, migration.Name)
+	}
+
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		for _, statement := range migration.Down {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, migration.Version)
+		return err
+	}, "revert", migration)
+}
+
+// inTx runs fn in a transaction, committing if it succeeds
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error, action string, migration Migration) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return fmt.Errorf("failed to %s migration %d (%s): %w", action, migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d (%s): %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+// find returns the migration with the given version
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to check table %s: %v", table, err)
+	}
+	return count == 1
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	db, err := NewSQLiteDB(":memory:", Pool{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	m := NewMigrator(db, SQLiteMigrations)
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != len(SQLiteMigrations) {
+		t.Errorf("Up() applied %d migrations, want %d", len(applied), len(SQLiteMigrations))
+	}
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 0 {
+		t.Errorf("second Up() = %d migrations, %v, want none", len(applied), err)
+	}
+
+	latest := len(SQLiteMigrations)
+	version, err := m.Version(ctx)
+	if err != nil || version != latest {
+		t.Errorf("Version() = %d, %v, want %d", version, err, latest)
+	}
+
+	// Revert down to sessions, the third migration
+	reverted, err := m.Down(ctx, latest-3)
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if len(reverted) != latest-3 || reverted[0].Version != latest || reverted[len(reverted)-1].Version != 4 {
+		t.Errorf("Down(%d) reverted %+v, want %d down to 4", latest-3, reverted, latest)
+	}
+	for table, want := range map[string]bool{"namespaces": false, "user_roles": false, "sessions": true} {
+		if got := tableExists(t, db, table); got != want {
+			t.Errorf("after Down(%d) table %s exists = %v, want %v", latest-3, table, got, want)
+		}
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	for _, status := range statuses {
+		if want := status.Version <= 3; status.Applied != want {
+			t.Errorf("Status() migration %d applied = %v, want %v", status.Version, status.Applied, want)
+		}
+		if status.Applied && status.AppliedAt.IsZero() {
+			t.Errorf("Status() migration %d has no applied time", status.Version)
+		}
+	}
+
+	pending, err := m.Pending(ctx)
+	if err != nil || len(pending) != latest-3 || pending[0].Version != 4 {
+		t.Errorf("Pending() = %+v, %v, want 4 onwards", pending, err)
+	}
+
+	if reverted, err := m.Down(ctx, 10); err != nil || len(reverted) != 3 {
+		t.Errorf("Down(10) reverted %d, %v, want the remaining 3", len(reverted), err)
+	}
+	if tableExists(t, db, "linktable") {
+		t.Error("Down() past the first migration left linktable behind")
+	}
+	if version, _ := m.Version(ctx); version != 0 {
+		t.Errorf("Version() after reverting everything = %d, want 0", version)
+	}
+}
+
+func TestMigrator_FailedMigrationRollsBack(t *testing.T) {
+	db, err := NewSQLiteDB(":memory:", Pool{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	migrations := []Migration{
+		{Version: 1, Name: "widgets", Up: []string{`CREATE TABLE widgets (id INTEGER)`}, Down: []string{`DROP TABLE widgets`}},
+		{Version: 2, Name: "broken", Up: []string{`CREATE TABLE gadgets (id INTEGER)`, `NOT SQL`}},
+	}
+
+	ctx := context.Background()
+	m := NewMigrator(db, migrations)
+	applied, err := m.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "migration 2 (broken)") {
+		t.Errorf("Up() error = %v, want migration 2 named", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Errorf("Up() applied %+v, want only migration 1", applied)
+	}
+	if tableExists(t, db, "gadgets") {
+		t.Error("failed migration left its table behind")
+	}
+	if version, _ := m.Version(ctx); version != 1 {
+		t.Errorf("Version() = %d, want 1", version)
+	}
+
+	// Migration 2 has no Down, but it was never applied, so reverting skips it
+	if reverted, err := m.Down(ctx, 1); err != nil || len(reverted) != 1 {
+		t.Errorf("Down(1) = %+v, %v, want migration 1 reverted", reverted, err)
+	}
+}
+
+func TestMigrator_Down_Irreversible(t *testing.T) {
+	db, err := NewSQLiteDB(":memory:", Pool{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	m := NewMigrator(db, []Migration{{Version: 1, Name: "one way", Up: []string{`CREATE TABLE widgets (id INTEGER)`}}})
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	if _, err := m.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "cannot be reverted") {
+		t.Errorf("Down() error = %v, want cannot be reverted", err)
+	}
+
+	unknown := NewMigrator(db, nil)
+	if _, err := unknown.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Down() of a migration this build doesn't know error = %v, want unknown", err)
+	}
+}
+
+func TestMigrations_Versions(t *testing.T) {
+	dialects := map[string][]Migration{"sqlite": SQLiteMigrations, "postgres": PostgresMigrations}
+	for name, migrations := range dialects {
+		for i, migration := range migrations {
+			if migration.Version != i+1 {
+				t.Errorf("%s migration %d has version %d, want versions numbered from 1 without gaps", name, i, migration.Version)
+			}
+			if migration.Name != SQLiteMigrations[i].Name {
+				t.Errorf("%s migration %d is %q, but sqlite's is %q", name, migration.Version, migration.Name, SQLiteMigrations[i].Name)
+			}
+			if len(migration.Down) == 0 {
+				t.Errorf("%s migration %d cannot be reverted", name, migration.Version)
+			}
+		}
+	}
+	if len(SQLiteMigrations) != len(PostgresMigrations) {
+		t.Errorf("sqlite has %d migrations and postgres %d, want the same", len(SQLiteMigrations), len(PostgresMigrations))
+	}
+}
+
+func TestMigrations_BackfillClickCounts(t *testing.T) {
+	db, err := NewSQLiteDB(":memory:", Pool{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	latest := len(SQLiteMigrations)
+	if _, err := NewMigrator(db, SQLiteMigrations[:latest-1]).Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	// Clicks logged before the counter existed, some of them already rolled up
+	seed := []string{
+		`INSERT INTO linktable (id, word, link, user) VALUES (1, 'docs', 'https://docs.example.com', 'alice')`,
+		`INSERT INTO linktable (id, word, link, user) VALUES (2, 'wiki', 'https://wiki.example.com', 'alice')`,
+		`INSERT INTO queries (word_id) VALUES (1)`,
+		`INSERT INTO queries (word_id) VALUES (1)`,
+		`INSERT INTO query_rollups (word_id, day, count) VALUES (1, '2024-01-01', 5)`,
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Failed to seed database: %v", err)
+		}
+	}
+
+	if _, err := NewMigrator(db, SQLiteMigrations).Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	for id, want := range map[int]int{1: 7, 2: 0} {
+		var got int
+		if err := db.QueryRow(`SELECT click_count FROM linktable WHERE id = ?`, id).Scan(&got); err != nil || got != want {
+			t.Errorf("click_count of link %d = %d, %v, want %d", id, got, err, want)
+		}
+	}
+}
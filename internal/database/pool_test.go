@@ -0,0 +1,31 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPool_Apply(t *testing.T) {
+	tests := []struct {
+		name        string
+		pool        Pool
+		wantMaxOpen int
+	}{
+		{name: "zero leaves defaults", pool: Pool{}, wantMaxOpen: 0},
+		{name: "limits", pool: Pool{MaxOpenConns: 3, MaxIdleConns: 2, ConnMaxLifetime: time.Minute}, wantMaxOpen: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := NewSQLiteDB(":memory:", tt.pool)
+			if err != nil {
+				t.Fatalf("NewSQLiteDB() error = %v", err)
+			}
+			defer db.Close()
+
+			if got := db.Stats().MaxOpenConnections; got != tt.wantMaxOpen {
+				t.Errorf("MaxOpenConnections = %d, want %d", got, tt.wantMaxOpen)
+			}
+		})
+	}
+}
@@ -0,0 +1,488 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// NewPostgresDB creates a new PostgreSQL database connection from a URL or key=value DSN.
+// Queries are written with SQLite's ? placeholders; the connection rewrites them to
+// PostgreSQL's numbered $1, $2, ... so repositories can share their SQL. The connection
+// pool is sized by pool.
+func NewPostgresDB(dsn string, pool Pool) (*sql.DB, error) {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db := sql.OpenDB(rebindConnector{connector})
+	pool.apply(db)
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+// PostgresMigrations is the PostgreSQL schema, numbered like SQLiteMigrations so both
+// dialects describe the same versions. It uses PostgreSQL types and adds a group_concat
+// aggregate like SQLite's.
+var PostgresMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "links, queries and tags",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS linktable (
+				id SERIAL PRIMARY KEY,
+				word TEXT NOT NULL,
+				link TEXT NOT NULL,
+				"user" TEXT NOT NULL,
+				icon TEXT NOT NULL DEFAULT '',
+				private BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS queries (
+				query_id SERIAL PRIMARY KEY,
+				word_id INTEGER NOT NULL REFERENCES linktable(id),
+				created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS tags (
+				id SERIAL PRIMARY KEY,
+				word_id INTEGER NOT NULL REFERENCES linktable(id),
+				tag TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_linktable_word ON linktable(word)`,
+			`CREATE INDEX IF NOT EXISTS idx_queries_word_id ON queries(word_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_queries_created_at ON queries(created_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_tags_word_id ON tags(word_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_tags_tag ON tags(tag)`,
+			// A strict state function skips NULLs and starts from the first value
+			`CREATE OR REPLACE FUNCTION golinks_concat(acc TEXT, value TEXT) RETURNS TEXT
+				AS $$ SELECT acc || ',' || value $$ LANGUAGE SQL IMMUTABLE STRICT`,
+			`CREATE OR REPLACE AGGREGATE group_concat(TEXT) (SFUNC = golinks_concat, STYPE = TEXT)`,
+		},
+		Down: []string{
+			`DROP AGGREGATE IF EXISTS group_concat(TEXT)`,
+			`DROP FUNCTION IF EXISTS golinks_concat(TEXT, TEXT)`,
+			`DROP TABLE IF EXISTS tags`,
+			`DROP TABLE IF EXISTS queries`,
+			`DROP TABLE IF EXISTS linktable`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "api keys",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS api_keys (
+				id SERIAL PRIMARY KEY,
+				name TEXT NOT NULL,
+				"user" TEXT NOT NULL,
+				prefix TEXT NOT NULL,
+				key_hash TEXT NOT NULL UNIQUE,
+				created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+				revoked_at TIMESTAMPTZ
+			)`,
+		},
+		Down: []string{`DROP TABLE IF EXISTS api_keys`},
+	},
+	{
+		Version: 3,
+		Name:    "sessions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS sessions (
+				token_hash TEXT PRIMARY KEY,
+				"user" TEXT NOT NULL,
+				created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+				expires_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at)`,
+		},
+		Down: []string{`DROP TABLE IF EXISTS sessions`},
+	},
+	{
+		Version: 4,
+		Name:    "user roles",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS user_roles (
+				"user" TEXT PRIMARY KEY,
+				role TEXT NOT NULL,
+				updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+			)`,
+		},
+		Down: []string{`DROP TABLE IF EXISTS user_roles`},
+	},
+	{
+		Version: 5,
+		Name:    "namespaces",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS namespaces (
+				name TEXT PRIMARY KEY,
+				created_by TEXT NOT NULL,
+				created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS namespace_members (
+				namespace TEXT NOT NULL REFERENCES namespaces(name) ON DELETE CASCADE,
+				"user" TEXT NOT NULL,
+				role TEXT NOT NULL,
+				added_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (namespace, "user")
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS namespace_members`,
+			`DROP TABLE IF EXISTS namespaces`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "link trash",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN deleted_at TIMESTAMPTZ`,
+			`CREATE INDEX IF NOT EXISTS idx_linktable_deleted_at ON linktable(deleted_at)`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_linktable_deleted_at`,
+			`DELETE FROM queries WHERE word_id IN (SELECT id FROM linktable WHERE deleted_at IS NOT NULL)`,
+			`DELETE FROM tags WHERE word_id IN (SELECT id FROM linktable WHERE deleted_at IS NOT NULL)`,
+			`DELETE FROM linktable WHERE deleted_at IS NOT NULL`,
+			`ALTER TABLE linktable DROP COLUMN deleted_at`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "link versions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS link_versions (
+				id SERIAL PRIMARY KEY,
+				word_id INTEGER NOT NULL REFERENCES linktable(id),
+				word TEXT NOT NULL,
+				link TEXT NOT NULL,
+				"user" TEXT NOT NULL,
+				icon TEXT NOT NULL DEFAULT '',
+				private BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_link_versions_word_id ON link_versions(word_id)`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_linktable_word_unique`,
+			`DROP TABLE IF EXISTS link_versions`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "query referrers",
+		Up: []string{
+			`ALTER TABLE queries ADD COLUMN referrer TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE queries DROP COLUMN referrer`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "query users",
+		Up: []string{
+			`ALTER TABLE queries ADD COLUMN "user" TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX IF NOT EXISTS idx_queries_user ON queries("user")`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_queries_user`,
+			`ALTER TABLE queries DROP COLUMN "user"`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "query clients",
+		Up: []string{
+			`ALTER TABLE queries ADD COLUMN client TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE queries DROP COLUMN client`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "query rollups",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS query_rollups (
+				word_id INTEGER NOT NULL REFERENCES linktable(id),
+				day TEXT NOT NULL,
+				count INTEGER NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_query_rollups_word_id ON query_rollups(word_id, day)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS query_rollups`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "sync state",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS sync_state (
+				source TEXT PRIMARY KEY,
+				last_version INTEGER NOT NULL,
+				synced_at TIMESTAMPTZ NOT NULL
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS sync_state`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "prefix links",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN prefix BOOLEAN NOT NULL DEFAULT FALSE`,
+			`ALTER TABLE link_versions ADD COLUMN prefix BOOLEAN NOT NULL DEFAULT FALSE`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN prefix`,
+			`ALTER TABLE linktable DROP COLUMN prefix`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "link health",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS link_health (
+				word TEXT PRIMARY KEY,
+				link TEXT NOT NULL,
+				owner TEXT NOT NULL,
+				problem TEXT NOT NULL DEFAULT '',
+				checked_at TIMESTAMPTZ NOT NULL,
+				broken_since TIMESTAMPTZ
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS link_health`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "link descriptions",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN description TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE link_versions ADD COLUMN description TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN description`,
+			`ALTER TABLE linktable DROP COLUMN description`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "link editors",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN updated_by TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE link_versions ADD COLUMN updated_by TEXT NOT NULL DEFAULT ''`,
+			// Versions stored before editors were recorded are credited to their owner
+			`UPDATE linktable SET updated_by = "user"`,
+			`UPDATE link_versions SET updated_by = "user"`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN updated_by`,
+			`ALTER TABLE linktable DROP COLUMN updated_by`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "favorites",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS favorites (
+				"user" TEXT NOT NULL,
+				word TEXT NOT NULL,
+				created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY ("user", word)
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS favorites`,
+		},
+	},
+	{
+		Version: 18,
+		Name:    "aliases",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS aliases (
+				id SERIAL PRIMARY KEY,
+				word TEXT NOT NULL UNIQUE,
+				shortcut_id INTEGER NOT NULL REFERENCES linktable(id),
+				created_by TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_aliases_shortcut_id ON aliases(shortcut_id)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS aliases`,
+		},
+	},
+	{
+		Version: 19,
+		Name:    "archived links",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE`,
+			`ALTER TABLE link_versions ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN archived`,
+			`ALTER TABLE linktable DROP COLUMN archived`,
+		},
+	},
+	{
+		Version: 20,
+		Name:    "link metadata",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN metadata TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE link_versions ADD COLUMN metadata TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN metadata`,
+			`ALTER TABLE linktable DROP COLUMN metadata`,
+		},
+	},
+	{
+		Version: 21,
+		Name:    "click counts",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN click_count INTEGER NOT NULL DEFAULT 0`,
+			`UPDATE linktable SET click_count =
+				(SELECT COUNT(*) FROM queries q WHERE q.word_id = linktable.id)
+				+ (SELECT COALESCE(SUM(r.count), 0) FROM query_rollups r WHERE r.word_id = linktable.id)`,
+		},
+		Down: []string{
+			`ALTER TABLE linktable DROP COLUMN click_count`,
+		},
+	},
+	{
+		Version: 22,
+		Name:    "word click counts",
+		Up: []string{
+			`UPDATE linktable SET click_count = (
+				(SELECT COUNT(*) FROM queries q JOIN linktable c ON c.id = q.word_id WHERE c.word = linktable.word)
+				+ (SELECT COALESCE(SUM(r.count), 0) FROM query_rollups r JOIN linktable c ON c.id = r.word_id WHERE c.word = linktable.word)
+			)
+			WHERE id IN (SELECT MAX(id) FROM linktable GROUP BY word)`,
+		},
+		Down: []string{
+			`UPDATE linktable SET click_count =
+				(SELECT COUNT(*) FROM queries q WHERE q.word_id = linktable.id)
+				+ (SELECT COALESCE(SUM(r.count), 0) FROM query_rollups r WHERE r.word_id = linktable.id)`,
+		},
+	},
+}
+
+// MigratePostgres applies every pending PostgreSQL migration
+func MigratePostgres(db *sql.DB) error {
+	_, err := NewMigrator(db, PostgresMigrations).Up(context.Background())
+	return err
+}
+
+// rebind rewrites ? placeholders to $1, $2, ... leaving quoted strings and identifiers alone
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// rebindConnector opens connections that rebind their queries
+type rebindConnector struct {
+	driver.Connector
+}
+
+func (c rebindConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rebindConn{conn}, nil
+}
+
+// rebindConn passes every query through rebind before handing it to the wrapped connection
+type rebindConn struct {
+	driver.Conn
+}
+
+func (c rebindConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebind(query))
+}
+
+func (c rebindConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, rebind(query))
+	}
+	return c.Prepare(query)
+}
+
+func (c rebindConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c rebindConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c rebindConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c rebindConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c rebindConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c rebindConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
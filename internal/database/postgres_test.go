@@ -0,0 +1,46 @@
+package database
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"no placeholders", "SELECT 1", "SELECT 1"},
+		{"placeholders", "SELECT id FROM linktable WHERE word = ? AND id > ?", "SELECT id FROM linktable WHERE word = $1 AND id > $2"},
+		{"quoted string", "SELECT '?' FROM t WHERE a = ?", "SELECT '?' FROM t WHERE a = $1"},
+		{"escaped quote", "SELECT 'it''s ?' FROM t WHERE a = ?", "SELECT 'it''s ?' FROM t WHERE a = $1"},
+		{"quoted identifier", `SELECT "a?" FROM t WHERE "user" = ?`, `SELECT "a?" FROM t WHERE "user" = $1`},
+		{"like escape", `l.word LIKE ? ESCAPE '\' OR l.link LIKE ? ESCAPE '\'`, `l.word LIKE $1 ESCAPE '\' OR l.link LIKE $2 ESCAPE '\'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rebind(tt.query); got != tt.want {
+				t.Errorf("rebind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPostgresDB_Unreachable(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+	}{
+		{"bad url", "postgres://%zz"},
+		{"no server", "postgres://golinks@127.0.0.1:1/golinks?sslmode=disable&connect_timeout=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := NewPostgresDB(tt.dsn, Pool{})
+			if err == nil {
+				db.Close()
+				t.Error("NewPostgresDB() should fail")
+			}
+		})
+	}
+}
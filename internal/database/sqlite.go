@@ -1,18 +1,45 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// NewSQLiteDB creates a new SQLite database connection
-func NewSQLiteDB(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+// SQLite connection defaults. WAL lets redirects read while links are written, and the
+// busy timeout makes a writer wait for the lock instead of failing with "database is locked".
+const (
+	DefaultJournalMode = "WAL"
+	DefaultBusyTimeout = 5000
+	DefaultSynchronous = "NORMAL"
+)
+
+var (
+	journalModes      = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	synchronousLevels = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
+// NewSQLiteDB creates a new SQLite database connection. The path may be followed by
+// connection options, as in golinks.db?journal_mode=WAL&busy_timeout=5000&synchronous=NORMAL;
+// busy_timeout is in milliseconds, and options left out take the defaults above. They are
+// applied to every connection in the pool, which is sized by pool.
+func NewSQLiteDB(dbPath string, pool Pool) (*sql.DB, error) {
+	dsn, err := sqliteDSN(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	pool.apply(db)
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
@@ -22,38 +49,453 @@ func NewSQLiteDB(dbPath string) (*sql.DB, error) {
 	return db, nil
 }
 
-// Migrate runs database migrations
-func Migrate(db *sql.DB) error {
-	migrations := []string{
-		`CREATE TABLE IF NOT EXISTS linktable (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			word TEXT NOT NULL,
-			link TEXT NOT NULL,
-			user TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS queries (
-			query_id INTEGER PRIMARY KEY AUTOINCREMENT,
-			word_id INTEGER NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (word_id) REFERENCES linktable(id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS tags (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			word_id INTEGER NOT NULL,
-			tag TEXT NOT NULL,
-			FOREIGN KEY (word_id) REFERENCES linktable(id)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_linktable_word ON linktable(word)`,
-		`CREATE INDEX IF NOT EXISTS idx_queries_word_id ON queries(word_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_queries_created_at ON queries(created_at)`,
-	}
-
-	for _, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil {
-			return fmt.Errorf("failed to run migration: %w", err)
+// sqliteDSN turns a path with golinks' connection options into a go-sqlite3 DSN
+func sqliteDSN(dbPath string) (string, error) {
+	path, rawOptions, _ := strings.Cut(dbPath, "?")
+	options, err := url.ParseQuery(rawOptions)
+	if err != nil {
+		return "", fmt.Errorf("invalid database options %q: %w", rawOptions, err)
+	}
+
+	journalMode := DefaultJournalMode
+	busyTimeout := DefaultBusyTimeout
+	synchronous := DefaultSynchronous
+	for name := range options {
+		value := options.Get(name)
+		switch name {
+		case "journal_mode":
+			journalMode = strings.ToUpper(value)
+			if !slices.Contains(journalModes, journalMode) {
+				return "", fmt.Errorf("journal_mode must be one of %s, not %q", strings.Join(journalModes, ", "), value)
+			}
+		case "busy_timeout":
+			busyTimeout, err = strconv.Atoi(value)
+			if err != nil || busyTimeout < 0 {
+				return "", fmt.Errorf("busy_timeout must be a number of milliseconds, not %q", value)
+			}
+		case "synchronous":
+			synchronous = strings.ToUpper(value)
+			if !slices.Contains(synchronousLevels, synchronous) {
+				return "", fmt.Errorf("synchronous must be one of %s, not %q", strings.Join(synchronousLevels, ", "), value)
+			}
+		default:
+			return "", fmt.Errorf("unknown database option %q", name)
 		}
 	}
 
+	return fmt.Sprintf("%s?_foreign_keys=on&_journal_mode=%s&_busy_timeout=%d&_synchronous=%s",
+		path, journalMode, busyTimeout, synchronous), nil
+}
+
+// SQLiteMigrations is the SQLite schema, oldest first. Databases created before
+// migrations were versioned already hold these tables, which is why they are created
+// only if missing; migrations added from here on should change the schema directly.
+var SQLiteMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "links, queries and tags",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS linktable (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				word TEXT NOT NULL,
+				link TEXT NOT NULL,
+				user TEXT NOT NULL,
+				icon TEXT NOT NULL DEFAULT '',
+				private INTEGER NOT NULL DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS queries (
+				query_id INTEGER PRIMARY KEY AUTOINCREMENT,
+				word_id INTEGER NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (word_id) REFERENCES linktable(id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS tags (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				word_id INTEGER NOT NULL,
+				tag TEXT NOT NULL,
+				FOREIGN KEY (word_id) REFERENCES linktable(id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_linktable_word ON linktable(word)`,
+			`CREATE INDEX IF NOT EXISTS idx_queries_word_id ON queries(word_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_queries_created_at ON queries(created_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_tags_word_id ON tags(word_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_tags_tag ON tags(tag)`,
+		},
+		// linktable from before icons and private links lacks their columns
+		UpFunc: func(ctx context.Context, tx *sql.Tx) error {
+			if err := addColumnIfNotExists(ctx, tx, "linktable", "icon", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+			return addColumnIfNotExists(ctx, tx, "linktable", "private", "INTEGER NOT NULL DEFAULT 0")
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS tags`,
+			`DROP TABLE IF EXISTS queries`,
+			`DROP TABLE IF EXISTS linktable`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "api keys",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS api_keys (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				user TEXT NOT NULL,
+				prefix TEXT NOT NULL,
+				key_hash TEXT NOT NULL UNIQUE,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				revoked_at DATETIME
+			)`,
+		},
+		Down: []string{`DROP TABLE IF EXISTS api_keys`},
+	},
+	{
+		Version: 3,
+		Name:    "sessions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS sessions (
+				token_hash TEXT PRIMARY KEY,
+				user TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				expires_at DATETIME NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at)`,
+		},
+		Down: []string{`DROP TABLE IF EXISTS sessions`},
+	},
+	{
+		Version: 4,
+		Name:    "user roles",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS user_roles (
+				user TEXT PRIMARY KEY,
+				role TEXT NOT NULL,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`,
+		},
+		Down: []string{`DROP TABLE IF EXISTS user_roles`},
+	},
+	{
+		Version: 5,
+		Name:    "namespaces",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS namespaces (
+				name TEXT PRIMARY KEY,
+				created_by TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS namespace_members (
+				namespace TEXT NOT NULL,
+				user TEXT NOT NULL,
+				role TEXT NOT NULL,
+				added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (namespace, user),
+				FOREIGN KEY (namespace) REFERENCES namespaces(name) ON DELETE CASCADE
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS namespace_members`,
+			`DROP TABLE IF EXISTS namespaces`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "link trash",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN deleted_at DATETIME`,
+			`CREATE INDEX IF NOT EXISTS idx_linktable_deleted_at ON linktable(deleted_at)`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_linktable_deleted_at`,
+			`DELETE FROM queries WHERE word_id IN (SELECT id FROM linktable WHERE deleted_at IS NOT NULL)`,
+			`DELETE FROM tags WHERE word_id IN (SELECT id FROM linktable WHERE deleted_at IS NOT NULL)`,
+			`DELETE FROM linktable WHERE deleted_at IS NOT NULL`,
+			`ALTER TABLE linktable DROP COLUMN deleted_at`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "link versions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS link_versions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				word_id INTEGER NOT NULL,
+				word TEXT NOT NULL,
+				link TEXT NOT NULL,
+				user TEXT NOT NULL,
+				icon TEXT NOT NULL DEFAULT '',
+				private INTEGER NOT NULL DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (word_id) REFERENCES linktable(id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_link_versions_word_id ON link_versions(word_id)`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_linktable_word_unique`,
+			`DROP TABLE IF EXISTS link_versions`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "query referrers",
+		Up: []string{
+			`ALTER TABLE queries ADD COLUMN referrer TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE queries DROP COLUMN referrer`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "query users",
+		Up: []string{
+			`ALTER TABLE queries ADD COLUMN user TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX IF NOT EXISTS idx_queries_user ON queries(user)`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_queries_user`,
+			`ALTER TABLE queries DROP COLUMN user`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "query clients",
+		Up: []string{
+			`ALTER TABLE queries ADD COLUMN client TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE queries DROP COLUMN client`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "query rollups",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS query_rollups (
+				word_id INTEGER NOT NULL,
+				day TEXT NOT NULL,
+				count INTEGER NOT NULL,
+				FOREIGN KEY (word_id) REFERENCES linktable(id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_query_rollups_word_id ON query_rollups(word_id, day)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS query_rollups`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "sync state",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS sync_state (
+				source TEXT PRIMARY KEY,
+				last_version INTEGER NOT NULL,
+				synced_at DATETIME NOT NULL
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS sync_state`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "prefix links",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN prefix INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE link_versions ADD COLUMN prefix INTEGER NOT NULL DEFAULT 0`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN prefix`,
+			`ALTER TABLE linktable DROP COLUMN prefix`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "link health",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS link_health (
+				word TEXT PRIMARY KEY,
+				link TEXT NOT NULL,
+				owner TEXT NOT NULL,
+				problem TEXT NOT NULL DEFAULT '',
+				checked_at DATETIME NOT NULL,
+				broken_since DATETIME
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS link_health`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "link descriptions",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN description TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE link_versions ADD COLUMN description TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN description`,
+			`ALTER TABLE linktable DROP COLUMN description`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "link editors",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN updated_by TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE link_versions ADD COLUMN updated_by TEXT NOT NULL DEFAULT ''`,
+			// Versions stored before editors were recorded are credited to their owner
+			`UPDATE linktable SET updated_by = "user"`,
+			`UPDATE link_versions SET updated_by = "user"`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN updated_by`,
+			`ALTER TABLE linktable DROP COLUMN updated_by`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "favorites",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS favorites (
+				user TEXT NOT NULL,
+				word TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user, word)
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS favorites`,
+		},
+	},
+	{
+		Version: 18,
+		Name:    "aliases",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS aliases (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				word TEXT NOT NULL UNIQUE,
+				shortcut_id INTEGER NOT NULL REFERENCES linktable(id),
+				created_by TEXT NOT NULL DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_aliases_shortcut_id ON aliases(shortcut_id)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS aliases`,
+		},
+	},
+	{
+		Version: 19,
+		Name:    "archived links",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN archived INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE link_versions ADD COLUMN archived INTEGER NOT NULL DEFAULT 0`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN archived`,
+			`ALTER TABLE linktable DROP COLUMN archived`,
+		},
+	},
+	{
+		Version: 20,
+		Name:    "link metadata",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN metadata TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE link_versions ADD COLUMN metadata TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE link_versions DROP COLUMN metadata`,
+			`ALTER TABLE linktable DROP COLUMN metadata`,
+		},
+	},
+	{
+		Version: 21,
+		Name:    "click counts",
+		Up: []string{
+			`ALTER TABLE linktable ADD COLUMN click_count INTEGER NOT NULL DEFAULT 0`,
+			`UPDATE linktable SET click_count =
+				(SELECT COUNT(*) FROM queries q WHERE q.word_id = linktable.id)
+				+ (SELECT COALESCE(SUM(r.count), 0) FROM query_rollups r WHERE r.word_id = linktable.id)`,
+		},
+		Down: []string{
+			`ALTER TABLE linktable DROP COLUMN click_count`,
+		},
+	},
+	{
+		Version: 22,
+		Name:    "word click counts",
+		Up: []string{
+			`UPDATE linktable SET click_count = (
+				(SELECT COUNT(*) FROM queries q JOIN linktable c ON c.id = q.word_id WHERE c.word = linktable.word)
+				+ (SELECT COALESCE(SUM(r.count), 0) FROM query_rollups r JOIN linktable c ON c.id = r.word_id WHERE c.word = linktable.word)
+			)
+			WHERE id IN (SELECT MAX(id) FROM linktable GROUP BY word)`,
+		},
+		Down: []string{
+			`UPDATE linktable SET click_count =
+				(SELECT COUNT(*) FROM queries q WHERE q.word_id = linktable.id)
+				+ (SELECT COALESCE(SUM(r.count), 0) FROM query_rollups r WHERE r.word_id = linktable.id)`,
+		},
+	},
+}
+
+// Migrate applies every pending SQLite migration
+func Migrate(db *sql.DB) error {
+	_, err := NewMigrator(db, SQLiteMigrations).Up(context.Background())
+	return err
+}
+
+// querier runs statements on a database or inside a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// addColumnIfNotExists adds a column to a table unless it is already present
+func addColumnIfNotExists(ctx context.Context, q querier, table, column, definition string) error {
+	exists, err := columnExists(ctx, q, table, column)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := q.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+
 	return nil
 }
+
+// columnExists reports whether a table has the given column
+func columnExists(ctx context.Context, q querier, table, column string) (bool, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to get table info for %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, dataType string
+		var defaultValue sql.NullString
+
+		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
+			return false, fmt.Errorf("failed to scan table info for %s: %w", table, err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("error iterating table info for %s: %w", table, err)
+	}
+
+	return false, nil
+}
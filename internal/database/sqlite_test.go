@@ -1,8 +1,11 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -39,7 +42,7 @@ func TestNewSQLiteDB(t *testing.T) {
 				defer os.Remove(tt.dbPath)
 			}
 
-			db, err := NewSQLiteDB(tt.dbPath)
+			db, err := NewSQLiteDB(tt.dbPath, Pool{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewSQLiteDB() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -72,6 +75,74 @@ func TestNewSQLiteDB(t *testing.T) {
 	}
 }
 
+func TestNewSQLiteDB_Options(t *testing.T) {
+	tests := []struct {
+		name            string
+		options         string
+		wantJournalMode string
+		wantBusyTimeout int
+		wantSynchronous int
+		wantErr         string
+	}{
+		{name: "defaults", wantJournalMode: "wal", wantBusyTimeout: 5000, wantSynchronous: 1},
+		{
+			name:            "custom options",
+			options:         "?journal_mode=delete&busy_timeout=250&synchronous=full",
+			wantJournalMode: "delete",
+			wantBusyTimeout: 250,
+			wantSynchronous: 2,
+		},
+		{name: "invalid journal mode", options: "?journal_mode=fast", wantErr: "journal_mode"},
+		{name: "invalid busy timeout", options: "?busy_timeout=soon", wantErr: "busy_timeout"},
+		{name: "invalid synchronous", options: "?synchronous=sometimes", wantErr: "synchronous"},
+		{name: "unknown option", options: "?cache=shared", wantErr: "unknown database option"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "golinks.db")+tt.options, Pool{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("NewSQLiteDB() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSQLiteDB() error = %v", err)
+			}
+			defer db.Close()
+
+			var journalMode string
+			var busyTimeout, synchronous, foreignKeys int
+			if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+				t.Fatalf("PRAGMA journal_mode error = %v", err)
+			}
+			if err := db.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+				t.Fatalf("PRAGMA busy_timeout error = %v", err)
+			}
+			if err := db.QueryRow("PRAGMA synchronous").Scan(&synchronous); err != nil {
+				t.Fatalf("PRAGMA synchronous error = %v", err)
+			}
+			if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+				t.Fatalf("PRAGMA foreign_keys error = %v", err)
+			}
+
+			if journalMode != tt.wantJournalMode {
+				t.Errorf("journal_mode = %q, want %q", journalMode, tt.wantJournalMode)
+			}
+			if busyTimeout != tt.wantBusyTimeout {
+				t.Errorf("busy_timeout = %d, want %d", busyTimeout, tt.wantBusyTimeout)
+			}
+			if synchronous != tt.wantSynchronous {
+				t.Errorf("synchronous = %d, want %d", synchronous, tt.wantSynchronous)
+			}
+			if foreignKeys != 1 {
+				t.Error("Foreign keys should be enabled alongside the options")
+			}
+		})
+	}
+}
+
 func TestMigrate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -96,7 +167,7 @@ func TestMigrate(t *testing.T) {
 				defer os.Remove(tt.dbPath)
 			}
 
-			db, err := NewSQLiteDB(tt.dbPath)
+			db, err := NewSQLiteDB(tt.dbPath, Pool{})
 			if err != nil {
 				t.Fatalf("Failed to create database: %v", err)
 			}
@@ -110,7 +181,7 @@ func TestMigrate(t *testing.T) {
 
 			if !tt.wantErr {
 				// Verify that tables were created
-				tables := []string{"linktable", "queries", "tags"}
+				tables := []string{"linktable", "queries", "tags", "api_keys", "sessions", "user_roles", "namespaces", "namespace_members", "favorites", "aliases"}
 				for _, table := range tables {
 					var count int
 					query := "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?"
@@ -124,7 +195,10 @@ func TestMigrate(t *testing.T) {
 				}
 
 				// Verify that indexes were created
-				indexes := []string{"idx_linktable_word", "idx_queries_word_id", "idx_queries_created_at"}
+				indexes := []string{
+					"idx_linktable_word", "idx_queries_word_id", "idx_queries_created_at",
+					"idx_tags_word_id", "idx_tags_tag",
+				}
 				for _, index := range indexes {
 					var count int
 					query := "SELECT COUNT(*) FROM sqlite_master WHERE type='index' AND name=?"
@@ -160,7 +234,7 @@ func TestMigrate(t *testing.T) {
 }
 
 func TestMigrate_Idempotent(t *testing.T) {
-	db, err := NewSQLiteDB(":memory:")
+	db, err := NewSQLiteDB(":memory:", Pool{})
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -191,7 +265,7 @@ func TestMigrate_Idempotent(t *testing.T) {
 }
 
 func TestMigrate_ClosedDatabase(t *testing.T) {
-	db, err := NewSQLiteDB(":memory:")
+	db, err := NewSQLiteDB(":memory:", Pool{})
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -207,7 +281,7 @@ func TestNewSQLiteDB_InvalidPath(t *testing.T) {
 	// Test with invalid path (directory that doesn't exist)
 	invalidPath := "/nonexistent/directory/test.db"
 
-	db, err := NewSQLiteDB(invalidPath)
+	db, err := NewSQLiteDB(invalidPath, Pool{})
 	if err == nil {
 		if db != nil {
 			db.Close()
@@ -219,7 +293,7 @@ func TestNewSQLiteDB_InvalidPath(t *testing.T) {
 }
 
 func TestDatabaseSchema(t *testing.T) {
-	db, err := NewSQLiteDB(":memory:")
+	db, err := NewSQLiteDB(":memory:", Pool{})
 	if err != nil {
 		t.Fatalf("Failed to create database: %v", err)
 	}
@@ -238,11 +312,19 @@ func TestDatabaseSchema(t *testing.T) {
 	defer rows.Close()
 
 	expectedColumns := map[string]bool{
-		"id":         false,
-		"word":       false,
-		"link":       false,
-		"user":       false,
-		"created_at": false,
+		"id":          false,
+		"word":        false,
+		"link":        false,
+		"user":        false,
+		"icon":        false,
+		"description": false,
+		"updated_by":  false,
+		"private":     false,
+		"prefix":      false,
+		"archived":    false,
+		"metadata":    false,
+		"click_count": false,
+		"created_at":  false,
 	}
 
 	for rows.Next() {
@@ -294,3 +376,35 @@ func TestDatabaseSchema(t *testing.T) {
 		t.Errorf("Expected 2 rows in linktable, got %d", count)
 	}
 }
+
+func TestMigrate_AddsColumnsToExistingTables(t *testing.T) {
+	db, err := NewSQLiteDB(":memory:", Pool{})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Simulate a database created before the icon column existed
+	_, err = db.Exec(`CREATE TABLE linktable (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		word TEXT NOT NULL,
+		link TEXT NOT NULL,
+		user TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	exists, err := columnExists(context.Background(), db, "linktable", "icon")
+	if err != nil {
+		t.Fatalf("columnExists() error = %v", err)
+	}
+	if !exists {
+		t.Error("Migrate() did not add icon column to existing linktable")
+	}
+}
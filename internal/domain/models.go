@@ -6,20 +6,57 @@ import (
 
 // Shortcut represents a golink shortcut
 type Shortcut struct {
-	ID        int       `json:"id" db:"id"`
-	Word      string    `json:"word" db:"word"`
-	Link      string    `json:"link" db:"link"`
-	User      string    `json:"user" db:"user"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID          int                    `json:"id" db:"id"`
+	Word        string                 `json:"word" db:"word"`
+	Link        string                 `json:"link" db:"link"`
+	User        string                 `json:"user" db:"user"`
+	Icon        string                 `json:"icon,omitempty" db:"icon"`
+	Description string                 `json:"description,omitempty" db:"description"`
+	UpdatedBy   string                 `json:"updated_by,omitempty" db:"updated_by"`
+	Private     bool                   `json:"private,omitempty" db:"private"`
+	Prefix      bool                   `json:"prefix,omitempty" db:"prefix"`
+	Archived    bool                   `json:"archived,omitempty" db:"archived"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+}
+
+// LinkDetail is the current version of a golink, with its aliases, when its word was
+// first created and when and by whom it was last changed
+type LinkDetail struct {
+	ID          int                    `json:"id"`
+	Word        string                 `json:"word"`
+	Link        string                 `json:"link"`
+	User        string                 `json:"user"`
+	Icon        string                 `json:"icon,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Private     bool                   `json:"private,omitempty"`
+	Prefix      bool                   `json:"prefix,omitempty"`
+	Archived    bool                   `json:"archived,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	AlsoKnownAs []string               `json:"also_known_as,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	UpdatedBy   string                 `json:"updated_by,omitempty"`
 }
 
 // Query represents a query log entry
 type Query struct {
 	ID        int       `json:"id" db:"query_id"`
 	WordID    int       `json:"word_id" db:"word_id"`
+	User      string    `json:"user,omitempty" db:"user"`
+	Referrer  string    `json:"referrer,omitempty" db:"referrer"`
+	Client    string    `json:"client,omitempty" db:"client"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// Kinds of client a query can come from
+const (
+	ClientBrowser   = "browser"
+	ClientExtension = "extension"
+	ClientCLI       = "cli"
+	ClientOther     = "other"
+)
+
 // Tag represents a tag associated with a shortcut
 type Tag struct {
 	ID     int    `json:"id" db:"id"`
@@ -27,10 +64,161 @@ type Tag struct {
 	Tag    string `json:"tag" db:"tag"`
 }
 
+// TagRequest represents a request to add tags to a link
+type TagRequest struct {
+	Tags []string `json:"tags" validate:"required"`
+}
+
 // LinkRequest represents a request to create or update a link
 type LinkRequest struct {
 	Word string `json:"word" validate:"required"`
 	Link string `json:"link" validate:"required"`
+	Icon string `json:"icon,omitempty"`
+
+	// Description says in a line what the link is for, shown in listings and suggestions
+	Description string `json:"description,omitempty"`
+
+	// Private links only resolve for, and are only listed to, their owner
+	Private bool `json:"private,omitempty"`
+
+	// Prefix links also resolve paths below their word, like repo/issues/123, by
+	// appending the rest of the path to their target
+	Prefix bool `json:"prefix,omitempty"`
+
+	// Archived links stay listed but no longer resolve, explaining that they were retired
+	Archived bool `json:"archived,omitempty"`
+
+	// Metadata is a JSON object other tools use to describe the link, like the team
+	// owning it or its service tier. It isn't interpreted here beyond being searchable.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Owner hands the link to another user; Force lets admins overwrite links they don't own
+	Owner string `json:"owner,omitempty"`
+	Force bool   `json:"force,omitempty"`
+}
+
+// RenameRequest moves a link to a new word, keeping the old one as an alias
+type RenameRequest struct {
+	Word string `json:"word" validate:"required"`
+}
+
+// TransferRequest hands a link over to another user
+type TransferRequest struct {
+	Owner string `json:"owner" validate:"required"`
+}
+
+// Bulk link result statuses
+const (
+	BulkStatusCreated = "created"
+	BulkStatusUpdated = "updated"
+	BulkStatusSkipped = "skipped"
+	BulkStatusFailed  = "failed"
+)
+
+// BulkLinkResult reports the outcome of one item in a bulk link request
+type BulkLinkResult struct {
+	Index  int    `json:"index"`
+	Word   string `json:"word"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// Line is the line of an imported file the item came from
+	Line int `json:"line,omitempty"`
+
+	// Link is where a dry run of an import would point the word
+	Link string `json:"link,omitempty"`
+}
+
+// Import conflict policies, choosing what an import does with words that already exist
+const (
+	ImportSkip      = "skip"
+	ImportOverwrite = "overwrite"
+)
+
+// ImportLink is one link to import, with the tags to give it
+type ImportLink struct {
+	LinkRequest
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ImportReport counts the outcomes of a link import, with the result of each link
+type ImportReport struct {
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Skipped int              `json:"skipped"`
+	Failed  int              `json:"failed"`
+	Results []BulkLinkResult `json:"results"`
+
+	// DryRun is set when nothing was written, the counts telling what the import would do
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// LegacyMigration counts what was copied from a legacy golinks database
+type LegacyMigration struct {
+	Links          int `json:"links"`
+	Queries        int `json:"queries"`
+	SkippedQueries int `json:"skipped_queries"`
+}
+
+// ExportRequest chooses what an export holds: every link, or only those owned by User,
+// tagged Tag or in Namespace, and with History every version of each
+type ExportRequest struct {
+	History   bool
+	User      string
+	Tag       string
+	Namespace string
+}
+
+// LinkExport is a complete dump of the links on a server, for backups or moving them to
+// another server
+type LinkExport struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	Links      []ExportedLink `json:"links"`
+}
+
+// ExportedLink is a link in an export with its latest target and owner, when it was first
+// created and when and by whom it was last changed, its tags and, if asked for, every version of it oldest first
+type ExportedLink struct {
+	Word        string                 `json:"word"`
+	Link        string                 `json:"link"`
+	Owner       string                 `json:"owner"`
+	Icon        string                 `json:"icon,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Private     bool                   `json:"private,omitempty"`
+	Prefix      bool                   `json:"prefix,omitempty"`
+	Archived    bool                   `json:"archived,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Tags        []string               `json:"tags"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	UpdatedBy   string                 `json:"updated_by,omitempty"`
+	History     []Shortcut             `json:"history,omitempty"`
+}
+
+// LinkChanges is a page of the link versions a primary stored after a cursor, oldest
+// first, for a replica to copy. The last page, which has More unset, also lists the
+// primary's live and trashed words, every link's tags and the word each alias stands for
+// so the replica can catch up on deletes, tag and alias changes, which store no new version.
+type LinkChanges struct {
+	Versions []Shortcut          `json:"versions"`
+	Cursor   int                 `json:"cursor"`
+	More     bool                `json:"more"`
+	Words    []string            `json:"words,omitempty"`
+	Trashed  []string            `json:"trashed,omitempty"`
+	Tags     map[string][]string `json:"tags,omitempty"`
+	Aliases  map[string]string   `json:"aliases,omitempty"`
+}
+
+// SyncResult counts what a replica changed to catch up with its primary, and the cursor
+// it reached
+type SyncResult struct {
+	Versions int `json:"versions"`
+	Deleted  int `json:"deleted"`
+	Restored int `json:"restored"`
+	Purged   int `json:"purged"`
+	Tags     int `json:"tags"`
+	Aliases  int `json:"aliases"`
+	Cursor   int `json:"cursor"`
 }
 
 // PopularQuery represents a popular query with count
@@ -40,10 +228,429 @@ type PopularQuery struct {
 	Link  string `json:"link"`
 }
 
-// KeywordInfo represents keyword information with aliases
-type KeywordInfo struct {
+// ClickCount is the number of times a golink was followed in the bucket starting at Start
+type ClickCount struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// ReferrerCount is the number of clicks on a golink that came from one referring host
+type ReferrerCount struct {
+	Referrer string `json:"referrer"`
+	Count    int    `json:"count"`
+}
+
+// Click stats intervals
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// LinkStats summarizes how often a golink was followed since Since
+type LinkStats struct {
+	Word      string          `json:"word"`
+	Interval  string          `json:"interval"`
+	Since     time.Time       `json:"since"`
+	Total     int             `json:"total"`
+	Clicks    []ClickCount    `json:"clicks"`
+	Referrers []ReferrerCount `json:"referrers"`
+}
+
+// StaleLink is a golink nobody has followed or changed for a while, with its owner so
+// they can be asked whether it is still needed
+type StaleLink struct {
 	Word      string    `json:"word"`
-	Aliases   string    `json:"aliases"`
 	Link      string    `json:"link"`
+	User      string    `json:"user"`
+	Private   bool      `json:"private,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// StaleLinkReport lists the golinks that have gone unused for Days days, since Since
+type StaleLinkReport struct {
+	Days  int         `json:"days"`
+	Since time.Time   `json:"since"`
+	Links []StaleLink `json:"links"`
+}
+
+// LinkHealth is what the last check of a golink's target found. Problem says why the
+// target looks broken, or is empty when it worked, and BrokenSince is when it was first
+// found broken.
+type LinkHealth struct {
+	Word        string     `json:"word"`
+	Link        string     `json:"link"`
+	Owner       string     `json:"owner"`
+	Problem     string     `json:"problem,omitempty"`
+	CheckedAt   time.Time  `json:"checked_at"`
+	BrokenSince *time.Time `json:"broken_since,omitempty"`
+}
+
+// BrokenLinkReport lists the golinks whose targets looked broken when last checked,
+// longest broken first
+type BrokenLinkReport struct {
+	Links []LinkHealth `json:"links"`
+}
+
+// LinkCheckRun reports a check of every golink's target: Checked targets were requested,
+// Broken of them look broken, NewlyBroken only since this check, and Emailed owners were
+// told about theirs while EmailsFailed could not be
+type LinkCheckRun struct {
+	Checked      int `json:"checked"`
+	Broken       int `json:"broken"`
+	NewlyBroken  int `json:"newly_broken"`
+	Emailed      int `json:"emailed"`
+	EmailsFailed int `json:"emails_failed"`
+}
+
+// QueryPrune reports a run of query log retention: Pruned queries logged before Before were
+// rolled up into daily counts and deleted
+type QueryPrune struct {
+	Before time.Time `json:"before"`
+	Pruned int64     `json:"pruned"`
+}
+
+// QueryLogStats reports the background query log writer: Queued queries are waiting to be
+// written, Dropped ones were discarded because the queue was full and Failed ones because
+// the database rejected them
+type QueryLogStats struct {
+	Queued  int   `json:"queued"`
+	Written int64 `json:"written"`
+	Dropped int64 `json:"dropped"`
+	Failed  int64 `json:"failed"`
+}
+
+// Changes reported by a LinkEvent
+const (
+	LinkCreated = "created"
+	LinkUpdated = "updated"
+	LinkDeleted = "deleted"
+)
+
+// LinkEvent is a change to a golink made by User, as announced to a channel webhook.
+// Before is nil for a created link and After for a deleted one.
+type LinkEvent struct {
+	Action string    `json:"action"`
+	Word   string    `json:"word"`
+	User   string    `json:"user"`
+	Before *Shortcut `json:"before,omitempty"`
+	After  *Shortcut `json:"after,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// LinkActivity is a golink that was created or deleted, as listed to automation services
+// such as Zapier that poll for new items and tell them apart by ID. URL is where a
+// created golink can be followed.
+type LinkActivity struct {
+	ID     string    `json:"id"`
+	Action string    `json:"action"`
+	Word   string    `json:"word"`
+	Link   string    `json:"link"`
+	Owner  string    `json:"owner"`
+	URL    string    `json:"url,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// NotifierStats reports the background change notifier: Sent changes were posted to the
+// webhook, Dropped ones were discarded because the queue was full and Failed ones because
+// the webhook rejected them
+type NotifierStats struct {
+	Queued  int   `json:"queued"`
+	Sent    int64 `json:"sent"`
+	Dropped int64 `json:"dropped"`
+	Failed  int64 `json:"failed"`
+}
+
+// ClickEvent is a followed golink as pushed to the live stats stream
+type ClickEvent struct {
+	Word     string    `json:"word"`
+	Referrer string    `json:"referrer,omitempty"`
+	Client   string    `json:"client,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// ClickCounters counts the golinks followed through this server over rolling windows, and
+// in Total since it started at Since
+type ClickCounters struct {
+	LastMinute int       `json:"last_minute"`
+	LastHour   int       `json:"last_hour"`
+	Total      int64     `json:"total"`
+	Since      time.Time `json:"since"`
+}
+
+// CacheStats reports how full an in-memory cache is and how often lookups hit it. When it
+// is Shared with other servers, SharedHits counts the misses the shared cache answered and
+// SharedErrors the calls to it that failed.
+type CacheStats struct {
+	Name         string `json:"name"`
+	Entries      int    `json:"entries"`
+	Capacity     int    `json:"capacity"`
+	Hits         int64  `json:"hits"`
+	Misses       int64  `json:"misses"`
+	Shared       bool   `json:"shared"`
+	SharedHits   int64  `json:"shared_hits"`
+	SharedErrors int64  `json:"shared_errors"`
+}
+
+// UserSummary is a user as admins see them: their role and how many golinks they own,
+// of which Private are private and Archived archived
+type UserSummary struct {
+	User     string `json:"user"`
+	Role     Role   `json:"role,omitempty"`
+	Links    int    `json:"links"`
+	Private  int    `json:"private"`
+	Archived int    `json:"archived"`
+}
+
+// TableStats counts the rows of a database table
+type TableStats struct {
+	Name string `json:"name"`
+	Rows int    `json:"rows"`
+}
+
+// StorageStats reports how much the database holds: the golinks in use and in the
+// trash, the rows of each table and, when the database can tell, its size in Bytes
+type StorageStats struct {
+	Links   int          `json:"links"`
+	Trashed int          `json:"trashed"`
+	Tables  []TableStats `json:"tables"`
+	Bytes   int64        `json:"bytes,omitempty"`
+}
+
+// ResponseCounts counts the responses a server sent, and how many of them were client
+// (4xx) and server (5xx) errors
+type ResponseCounts struct {
+	Requests     int64 `json:"requests"`
+	ClientErrors int64 `json:"client_errors"`
+	ServerErrors int64 `json:"server_errors"`
+}
+
+// ErrorRates counts the responses this server sent over rolling windows, and in Total
+// since it started at Since
+type ErrorRates struct {
+	LastMinute ResponseCounts `json:"last_minute"`
+	LastHour   ResponseCounts `json:"last_hour"`
+	Total      ResponseCounts `json:"total"`
+	Since      time.Time      `json:"since"`
+}
+
+// UserLinks is what a user follows and owns, for a personalized homepage
+type UserLinks struct {
+	User     string         `json:"user"`
+	MostUsed []PopularQuery `json:"most_used"`
+	Created  []KeywordInfo  `json:"created"`
+}
+
+// KeywordInfo represents keyword information with aliases, when the keyword was first
+// created and when and by whom it was last changed
+type KeywordInfo struct {
+	Word        string                 `json:"word"`
+	Aliases     string                 `json:"aliases"`
+	AlsoKnownAs []string               `json:"also_known_as,omitempty"`
+	Link        string                 `json:"link"`
+	Icon        string                 `json:"icon,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Private     bool                   `json:"private,omitempty"`
+	Prefix      bool                   `json:"prefix,omitempty"`
+	Archived    bool                   `json:"archived,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Tags        []string               `json:"tags"`
+	ClickCount  int                    `json:"click_count"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	UpdatedBy   string                 `json:"updated_by,omitempty"`
+}
+
+// KeywordPage is one page of the keyword list along with the total number of keywords
+type KeywordPage struct {
+	Keywords []KeywordInfo `json:"keywords"`
+	Query    string        `json:"query,omitempty"`
+	Sort     string        `json:"sort"`
+	Total    int           `json:"total"`
+	Limit    int           `json:"limit"`
+	Offset   int           `json:"offset"`
+}
+
+// Suggestions are the keywords offered for what has been typed so far, compact enough for
+// browser omnibox and extension autocomplete
+type Suggestions struct {
+	Query       string       `json:"query"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Suggestion is a keyword offered while typing, with a line describing where it goes
+type Suggestion struct {
+	Word        string `json:"word"`
+	Link        string `json:"link"`
+	Description string `json:"description"`
+}
+
+// Orders a keyword list can be sorted in
+const (
+	KeywordSortNewest       = "newest"
+	KeywordSortAlphabetical = "alphabetical"
+	KeywordSortMostUsed     = "most_used"
+)
+
+// Resolution describes how a query was resolved to its target URL
+type Resolution struct {
+	Query        string `json:"query"`
+	URL          string `json:"url"`
+	Word         string `json:"word"`
+	SearchTerm   string `json:"search_term"`
+	ResolvedWord string `json:"resolved_word"`
+	Substituted  bool   `json:"substituted"`
+	Hops         int    `json:"hops"`
+	Owner        string `json:"owner"`
+
+	// Alias is the alias the query named its golink by, if it did
+	Alias string `json:"alias,omitempty"`
+
+	// Path is what was appended to the target of a prefix link
+	Path string `json:"path,omitempty"`
+}
+
+// APIKey is a bearer token that lets programs act as a user. Only a hash of the key
+// itself is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	User      string     `json:"user"`
+	Prefix    string     `json:"prefix"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyRequest asks for a new API key acting as User, or as the requester if empty
+type APIKeyRequest struct {
+	Name string `json:"name"`
+	User string `json:"user"`
+}
+
+// CreatedAPIKey is a newly minted API key along with its secret, which is only
+// returned this once
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Role grants a user permissions; each role includes everything the roles below it can do
+type Role string
+
+// Roles from least to most privileged
+const (
+	// RoleViewer can follow and browse golinks
+	RoleViewer Role = "viewer"
+	// RoleEditor can also create, change and tag golinks
+	RoleEditor Role = "editor"
+	// RoleAdmin can also change anyone's golinks and manage API keys and roles
+	RoleAdmin Role = "admin"
+)
+
+// roleRanks orders roles by privilege
+var roleRanks = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	return roleRanks[r] > 0
+}
+
+// Includes reports whether r grants everything other does
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[other]
+}
+
+// UserRole is the role assigned to a user
+type UserRole struct {
+	User      string    `json:"user"`
+	Role      Role      `json:"role"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RoleRequest asks for a user to be given a role
+type RoleRequest struct {
+	Role Role `json:"role" validate:"required"`
+}
+
+// LogLevel is the least severe level the server logs, such as DEBUG or INFO. It is both
+// the body of a request to change the level and the report of the current one.
+type LogLevel struct {
+	Level string `json:"level" validate:"required"`
+}
+
+// DomainLists are the domains links may not point to, and when Allowed isn't empty the
+// only ones they may. Each covers its subdomains. It is both the body of a request to
+// replace the lists and the report of the current ones.
+type DomainLists struct {
+	Denied  []string `json:"denied"`
+	Allowed []string `json:"allowed"`
+}
+
+// Namespace groups a team's golinks under a shared prefix, like payments/runbook. Only
+// its members can add or change links in it.
+type Namespace struct {
+	Name      string            `json:"name"`
+	CreatedBy string            `json:"created_by"`
+	CreatedAt time.Time         `json:"created_at"`
+	Members   []NamespaceMember `json:"members,omitempty"`
+}
+
+// NamespaceRole is a member's standing within a namespace
+type NamespaceRole string
+
+// Namespace roles
+const (
+	// NamespaceMemberRole can add and change the namespace's links
+	NamespaceMemberRole NamespaceRole = "member"
+	// NamespaceOwnerRole can also manage members and delete the namespace
+	NamespaceOwnerRole NamespaceRole = "owner"
+)
+
+// Valid reports whether r is a known namespace role
+func (r NamespaceRole) Valid() bool {
+	return r == NamespaceMemberRole || r == NamespaceOwnerRole
+}
+
+// NamespaceMember is a user belonging to a namespace
+type NamespaceMember struct {
+	User    string        `json:"user"`
+	Role    NamespaceRole `json:"role"`
+	AddedAt time.Time     `json:"added_at"`
+}
+
+// NamespaceRequest asks for a new namespace
+type NamespaceRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// NamespaceMemberRequest adds a user to a namespace, as a member unless Role says otherwise
+type NamespaceMemberRequest struct {
+	Role NamespaceRole `json:"role,omitempty"`
+}
+
+// Backup is a snapshot of the database kept in backup storage
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Snapshot is a scheduled copy of every link, and optionally the database, saved off the
+// server, along with the older snapshots deleted to make room for it
+type Snapshot struct {
+	Saved   []Backup `json:"saved"`
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// RestoreRequest asks for the database to be replaced by the named backup
+type RestoreRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// TrashedLink is a deleted golink that can still be restored from the trash
+type TrashedLink struct {
+	Word      string    `json:"word"`
+	Link      string    `json:"link"`
+	User      string    `json:"user"`
+	Private   bool      `json:"private,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
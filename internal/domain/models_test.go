@@ -119,3 +119,26 @@ func TestLinkRequest_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestRole_Includes(t *testing.T) {
+	tests := []struct {
+		role  Role
+		other Role
+		want  bool
+	}{
+		{RoleAdmin, RoleEditor, true},
+		{RoleEditor, RoleEditor, true},
+		{RoleEditor, RoleAdmin, false},
+		{RoleViewer, RoleEditor, false},
+		{"owner", RoleViewer, false},
+		{"", RoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"/"+string(tt.other), func(t *testing.T) {
+			if got := tt.role.Includes(tt.other); got != tt.want {
+				t.Errorf("Role(%q).Includes(%q) = %v, want %v", tt.role, tt.other, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+// Package grpcapi exposes golink resolution and management over gRPC.
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"golinks/internal/domain"
+	"golinks/internal/pb/golinksv1"
+	"golinks/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultUser makes calls without an API key when sign-in isn't configured, as it
+// makes unauthenticated requests over HTTP
+const defaultUser = "DefaultUser"
+
+// methodRoles holds the role a caller needs for each method, matching the HTTP routes;
+// methods not listed need none
+var methodRoles = map[string]domain.Role{
+	golinksv1.GoLinks_UpdateLink_FullMethodName: domain.RoleEditor,
+}
+
+// LinkService interface for the link operations exposed over gRPC
+type LinkService interface {
+	ResolveDetail(ctx context.Context, query string, logQuery bool, userID string) (*domain.Resolution, error)
+	UpdateLink(ctx context.Context, req domain.LinkRequest, userID string) error
+	GetLinkDetail(ctx context.Context, word, userID string) (*domain.LinkDetail, error)
+	GetAllKeywords(ctx context.Context, userID string) ([]domain.KeywordInfo, error)
+	GetRecentQueries(ctx context.Context, days, limit int) ([]domain.PopularQuery, error)
+}
+
+// APIKeyService interface for authenticating callers by API key
+type APIKeyService interface {
+	Authenticate(ctx context.Context, token string) (*domain.APIKey, error)
+}
+
+// RoleService interface for the roles callers hold
+type RoleService interface {
+	RoleOf(ctx context.Context, user string) (domain.Role, error)
+}
+
+// Server implements the GoLinks gRPC service
+type Server struct {
+	golinksv1.UnimplementedGoLinksServer
+
+	linkService LinkService
+	apiKeys     APIKeyService
+	roles       RoleService
+
+	// requireLogin refuses calls without an API key rather than making them as
+	// defaultUser, as when sign-in is configured
+	requireLogin bool
+
+	logger *slog.Logger
+}
+
+// NewServer creates a new gRPC server, authenticating callers with apiKeys and checking
+// their roles with roles. With requireLogin every call needs an API key. It logs calls
+// and failures to logger.
+func NewServer(
+	linkService LinkService, apiKeys APIKeyService, roles RoleService, requireLogin bool, logger *slog.Logger,
+) *Server {
+	return &Server{linkService: linkService, apiKeys: apiKeys, roles: roles, requireLogin: requireLogin, logger: logger}
+}
+
+// userKey carries the user a call is made as
+type userKey struct{}
+
+// UnaryInterceptor authenticates calls by the API key in their "authorization: Bearer"
+// metadata, making them as its owner, and turns away callers without the role a method
+// needs. Install it with grpc.UnaryInterceptor.
+func (s *Server) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		userID, err := s.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if role, ok := methodRoles[info.FullMethod]; ok {
+			has, err := s.roles.RoleOf(ctx, userID)
+			if err != nil {
+				return nil, s.toStatus(err, "get role")
+			}
+			if !has.Includes(role) {
+				s.logger.Info("forbidden", "method", info.FullMethod, "user", userID, "role", has, "needs", role)
+				return nil, status.Error(codes.PermissionDenied, "This needs the "+string(role)+" role")
+			}
+		}
+
+		return handler(context.WithValue(ctx, userKey{}, userID), req)
+	}
+}
+
+// authenticate returns the user a call is made as: the owner of its API key, or
+// defaultUser when it has none and sign-in isn't required
+func (s *Server) authenticate(ctx context.Context) (string, error) {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			header = values[0]
+		}
+	}
+
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		if s.requireLogin {
+			return "", status.Error(codes.Unauthenticated, "Send an API key")
+		}
+		return defaultUser, nil
+	}
+
+	key, err := s.apiKeys.Authenticate(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return "", s.toStatus(err, "authenticate api key")
+	}
+	return key.User, nil
+}
+
+// callerOf returns the user the interceptor authenticated a call as
+func callerOf(ctx context.Context) string {
+	if userID, ok := ctx.Value(userKey{}).(string); ok {
+		return userID
+	}
+	return defaultUser
+}
+
+// Register registers the GoLinks service on a gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	golinksv1.RegisterGoLinksServer(registrar, s)
+}
+
+// GetLink resolves a query to its target URL
+func (s *Server) GetLink(ctx context.Context, req *golinksv1.GetLinkRequest) (*golinksv1.GetLinkResponse, error) {
+	if req.GetQuery() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing query")
+	}
+
+	resolution, err := s.linkService.ResolveDetail(ctx, req.GetQuery(), req.GetLogQuery(), callerOf(ctx))
+	if err != nil {
+		if _, ok := err.(service.InvalidQueryError); ok {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, s.toStatus(err, "resolve "+req.GetQuery())
+	}
+
+	return &golinksv1.GetLinkResponse{
+		Url:          resolution.URL,
+		Word:         resolution.Word,
+		ResolvedWord: resolution.ResolvedWord,
+		Owner:        resolution.Owner,
+		Hops:         int32(resolution.Hops),
+		Substituted:  resolution.Substituted,
+	}, nil
+}
+
+// UpdateLink creates a golink or adds a new version of one
+func (s *Server) UpdateLink(ctx context.Context, req *golinksv1.UpdateLinkRequest) (*golinksv1.Link, error) {
+	linkRequest := domain.LinkRequest{
+		Word:        req.GetWord(),
+		Link:        req.GetLink(),
+		Icon:        req.GetIcon(),
+		Description: req.GetDescription(),
+		Owner:       req.GetOwner(),
+		Force:       req.GetForce(),
+	}
+
+	userID := callerOf(ctx)
+	if err := s.linkService.UpdateLink(ctx, linkRequest, userID); err != nil {
+		return nil, s.toStatus(err, "update "+req.GetWord())
+	}
+
+	s.logger.Info("grpc update", "word", req.GetWord(), "user", userID, "link", req.GetLink())
+
+	detail, err := s.linkService.GetLinkDetail(ctx, req.GetWord(), userID)
+	if err != nil {
+		return nil, s.toStatus(err, "get "+req.GetWord())
+	}
+
+	return &golinksv1.Link{
+		Id:          int64(detail.ID),
+		Word:        detail.Word,
+		Link:        detail.Link,
+		User:        detail.User,
+		Icon:        detail.Icon,
+		Description: detail.Description,
+		CreatedAt:   timestamppb.New(detail.CreatedAt),
+		UpdatedAt:   timestamppb.New(detail.UpdatedAt),
+		UpdatedBy:   detail.UpdatedBy,
+	}, nil
+}
+
+// ListKeywords returns every golink
+func (s *Server) ListKeywords(ctx context.Context, _ *golinksv1.ListKeywordsRequest) (*golinksv1.ListKeywordsResponse, error) {
+	keywords, err := s.linkService.GetAllKeywords(ctx, callerOf(ctx))
+	if err != nil {
+		return nil, s.toStatus(err, "list keywords")
+	}
+
+	resp := &golinksv1.ListKeywordsResponse{Keywords: make([]*golinksv1.Keyword, 0, len(keywords))}
+	for _, keyword := range keywords {
+		resp.Keywords = append(resp.Keywords, &golinksv1.Keyword{
+			Word:        keyword.Word,
+			Aliases:     keyword.Aliases,
+			Link:        keyword.Link,
+			Icon:        keyword.Icon,
+			Description: keyword.Description,
+			Tags:        keyword.Tags,
+			CreatedAt:   timestamppb.New(keyword.CreatedAt),
+			UpdatedAt:   timestamppb.New(keyword.UpdatedAt),
+			UpdatedBy:   keyword.UpdatedBy,
+		})
+	}
+
+	return resp, nil
+}
+
+// PopularQueries returns the most used golinks
+func (s *Server) PopularQueries(ctx context.Context, _ *golinksv1.PopularQueriesRequest) (*golinksv1.PopularQueriesResponse, error) {
+	queries, err := s.linkService.GetRecentQueries(ctx, 0, 0)
+	if err != nil {
+		return nil, s.toStatus(err, "get popular queries")
+	}
+
+	resp := &golinksv1.PopularQueriesResponse{Queries: make([]*golinksv1.PopularQuery, 0, len(queries))}
+	for _, query := range queries {
+		resp.Queries = append(resp.Queries, &golinksv1.PopularQuery{
+			Count: int64(query.Count),
+			Word:  query.Word,
+			Link:  query.Link,
+		})
+	}
+
+	return resp, nil
+}
+
+// toStatus maps service errors onto gRPC status codes, logging unexpected ones
+func (s *Server) toStatus(err error, action string) error {
+	switch err.(type) {
+	case service.InvalidQueryError:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case service.NotFoundError:
+		return status.Error(codes.NotFound, err.Error())
+	case service.UnauthorizedError:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case service.ForbiddenError:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case service.AliasLoopError, service.ArchivedError:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		s.logger.Error("Failed to "+action, "err", err)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+}
@@ -0,0 +1,256 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+	"golinks/internal/pb/golinksv1"
+	"golinks/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// mockLinkService for testing
+type mockLinkService struct {
+	links        map[string]string
+	descriptions map[string]string
+	updateError  error
+}
+
+func (m *mockLinkService) ResolveDetail(
+	ctx context.Context, query string, logQuery bool, userID string,
+) (*domain.Resolution, error) {
+	if link, exists := m.links[query]; exists {
+		return &domain.Resolution{Query: query, URL: link, Word: query, ResolvedWord: query, Owner: "alice"}, nil
+	}
+	return nil, service.InvalidQueryError{Message: "not found"}
+}
+
+func (m *mockLinkService) UpdateLink(ctx context.Context, req domain.LinkRequest, userID string) error {
+	if m.updateError != nil {
+		return m.updateError
+	}
+	m.links[req.Word] = req.Link
+	if req.Description != "" {
+		if m.descriptions == nil {
+			m.descriptions = map[string]string{}
+		}
+		m.descriptions[req.Word] = req.Description
+	}
+	return nil
+}
+
+func (m *mockLinkService) GetLinkDetail(ctx context.Context, word, userID string) (*domain.LinkDetail, error) {
+	link, exists := m.links[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	return &domain.LinkDetail{
+		ID: 7, Word: word, Link: link, User: defaultUser, Description: m.descriptions[word],
+		CreatedAt: time.Unix(1700000000, 0), UpdatedAt: time.Unix(1700003600, 0), UpdatedBy: userID,
+	}, nil
+}
+
+func (m *mockLinkService) GetAllKeywords(ctx context.Context, userID string) ([]domain.KeywordInfo, error) {
+	return []domain.KeywordInfo{{Word: "docs", Link: m.links["docs"], Tags: []string{"engineering"}}}, nil
+}
+
+func (m *mockLinkService) GetRecentQueries(ctx context.Context, days, limit int) ([]domain.PopularQuery, error) {
+	return []domain.PopularQuery{{Count: 5, Word: "docs", Link: m.links["docs"]}}, nil
+}
+
+// mockAPIKeyService knows the API key gl_<user> of each of its users
+type mockAPIKeyService struct {
+	users []string
+}
+
+func (m *mockAPIKeyService) Authenticate(ctx context.Context, token string) (*domain.APIKey, error) {
+	for _, user := range m.users {
+		if token == "gl_"+user {
+			return &domain.APIKey{User: user}, nil
+		}
+	}
+	return nil, service.UnauthorizedError{Message: "Invalid API key"}
+}
+
+// mockRoleService gives users their role in roles, and everyone else editor
+type mockRoleService struct {
+	roles map[string]domain.Role
+}
+
+func (m *mockRoleService) RoleOf(ctx context.Context, user string) (domain.Role, error) {
+	if role, ok := m.roles[user]; ok {
+		return role, nil
+	}
+	return domain.RoleEditor, nil
+}
+
+func setupTestClient(t *testing.T, linkService LinkService) golinksv1.GoLinksClient {
+	t.Helper()
+	return setupServerClient(t, NewServer(linkService, &mockAPIKeyService{}, &mockRoleService{}, false, slog.Default()))
+}
+
+func setupServerClient(t *testing.T, api *Server) golinksv1.GoLinksClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(api.UnaryInterceptor()))
+	api.Register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return golinksv1.NewGoLinksClient(conn)
+}
+
+func TestServer_GetLink(t *testing.T) {
+	client := setupTestClient(t, &mockLinkService{links: map[string]string{"docs": "https://docs.example.com"}})
+
+	tests := []struct {
+		name     string
+		query    string
+		wantURL  string
+		wantCode codes.Code
+	}{
+		{name: "existing link", query: "docs", wantURL: "https://docs.example.com", wantCode: codes.OK},
+		{name: "missing link", query: "missing", wantCode: codes.NotFound},
+		{name: "empty query", query: "", wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetLink(context.Background(), &golinksv1.GetLinkRequest{Query: tt.query})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("GetLink() code = %v, want %v (err %v)", code, tt.wantCode, err)
+			}
+			if err == nil && (resp.GetUrl() != tt.wantURL || resp.GetOwner() != "alice") {
+				t.Errorf("GetLink() = %+v, want url %s", resp, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestServer_UpdateLink(t *testing.T) {
+	tests := []struct {
+		name        string
+		updateError error
+		wantCode    codes.Code
+	}{
+		{name: "success", wantCode: codes.OK},
+		{name: "invalid link", updateError: service.InvalidQueryError{Message: "invalid"}, wantCode: codes.InvalidArgument},
+		{name: "not owner", updateError: service.ForbiddenError{Message: "forbidden"}, wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linkService := &mockLinkService{links: map[string]string{}, updateError: tt.updateError}
+			client := setupTestClient(t, linkService)
+
+			resp, err := client.UpdateLink(context.Background(), &golinksv1.UpdateLinkRequest{
+				Word: "wiki", Link: "https://wiki.example.com", Description: "Team handbook",
+			})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("UpdateLink() code = %v, want %v (err %v)", code, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.GetId() != 7 || resp.GetLink() != "https://wiki.example.com" || resp.GetDescription() != "Team handbook" ||
+				resp.GetCreatedAt().AsTime().Unix() != 1700000000 || resp.GetUpdatedAt().AsTime().Unix() != 1700003600 ||
+				resp.GetUpdatedBy() != defaultUser {
+				t.Errorf("UpdateLink() = %+v", resp)
+			}
+			if linkService.links["wiki"] != "https://wiki.example.com" {
+				t.Errorf("UpdateLink() did not store the link")
+			}
+		})
+	}
+}
+
+func TestServer_ListKeywordsAndPopularQueries(t *testing.T) {
+	client := setupTestClient(t, &mockLinkService{links: map[string]string{"docs": "https://docs.example.com"}})
+
+	keywords, err := client.ListKeywords(context.Background(), &golinksv1.ListKeywordsRequest{})
+	if err != nil {
+		t.Fatalf("ListKeywords() error = %v", err)
+	}
+	if len(keywords.GetKeywords()) != 1 || keywords.GetKeywords()[0].GetTags()[0] != "engineering" {
+		t.Errorf("ListKeywords() = %+v", keywords)
+	}
+
+	queries, err := client.PopularQueries(context.Background(), &golinksv1.PopularQueriesRequest{})
+	if err != nil {
+		t.Fatalf("PopularQueries() error = %v", err)
+	}
+	if len(queries.GetQueries()) != 1 || queries.GetQueries()[0].GetCount() != 5 {
+		t.Errorf("PopularQueries() = %+v", queries)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	apiKeys := &mockAPIKeyService{users: []string{"alice", "victor"}}
+	roles := &mockRoleService{roles: map[string]domain.Role{"victor": domain.RoleViewer}}
+
+	tests := []struct {
+		name         string
+		requireLogin bool
+		key          string
+		method       string
+		wantCode     codes.Code
+		wantUser     string
+	}{
+		{name: "no key without sign-in", method: "update", wantCode: codes.OK, wantUser: defaultUser},
+		{name: "no key with sign-in", requireLogin: true, method: "update", wantCode: codes.Unauthenticated},
+		{name: "no key with sign-in, reading", requireLogin: true, method: "list", wantCode: codes.Unauthenticated},
+		{name: "key with sign-in", requireLogin: true, key: "gl_alice", method: "update", wantCode: codes.OK, wantUser: "alice"},
+		{name: "unknown key", key: "gl_mallory", method: "list", wantCode: codes.Unauthenticated},
+		{name: "viewer updating", requireLogin: true, key: "gl_victor", method: "update", wantCode: codes.PermissionDenied},
+		{name: "viewer reading", requireLogin: true, key: "gl_victor", method: "list", wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linkService := &mockLinkService{links: map[string]string{"docs": "https://docs.example.com"}}
+			client := setupServerClient(t, NewServer(linkService, apiKeys, roles, tt.requireLogin, slog.Default()))
+
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.key)
+			}
+
+			var err error
+			switch tt.method {
+			case "update":
+				var resp *golinksv1.Link
+				resp, err = client.UpdateLink(ctx, &golinksv1.UpdateLinkRequest{Word: "wiki", Link: "https://wiki.example.com"})
+				if err == nil && resp.GetUpdatedBy() != tt.wantUser {
+					t.Errorf("UpdateLink() updated by %q, want %q", resp.GetUpdatedBy(), tt.wantUser)
+				}
+			case "list":
+				_, err = client.ListKeywords(ctx, &golinksv1.ListKeywordsRequest{})
+			}
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("%s code = %v, want %v (err %v)", tt.method, code, tt.wantCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+)
+
+// AdminService interface for the admin dashboard's reports
+type AdminService interface {
+	ListUsers(ctx context.Context, requester string) ([]domain.UserSummary, error)
+	RecentChanges(ctx context.Context, limit int) ([]domain.Shortcut, error)
+	StorageStats(ctx context.Context) (*domain.StorageStats, error)
+}
+
+// AdminUsersHandler lists every user who owns golinks or holds a role, with their role and
+// link counts
+func (h *Handler) AdminUsersHandler(w http.ResponseWriter, r *http.Request) {
+	users, err := h.adminService.ListUsers(r.Context(), h.getUserID(r))
+	if err != nil {
+		h.writeAPIError(w, err, "list users")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, users)
+}
+
+// AdminChangesHandler lists the golink versions stored most recently, up to the limit
+// parameter of them, newest first
+func (h *Handler) AdminChangesHandler(w http.ResponseWriter, r *http.Request) {
+	limit, ok := intQueryParam(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	changes, err := h.adminService.RecentChanges(r.Context(), limit)
+	if err != nil {
+		h.writeAPIError(w, err, "list recent changes")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, changes)
+}
+
+// StorageStatsHandler reports how many golinks and rows the database holds and its size
+func (h *Handler) StorageStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.adminService.StorageStats(r.Context())
+	if err != nil {
+		h.writeAPIError(w, err, "get storage stats")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// ErrorRatesHandler reports how many responses this server sent over the last minute,
+// the last hour and since it started, and how many of them were errors
+func (h *Handler) ErrorRatesHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.responses.rates())
+}
+
+// AdminPageHandler renders the admin dashboard: users, recent changes, storage, error
+// rates, clicks and caches
+func (h *Handler) AdminPageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	users, err := h.adminService.ListUsers(ctx, h.getUserID(r))
+	if err != nil {
+		h.adminPageError(w, err)
+		return
+	}
+	changes, err := h.adminService.RecentChanges(ctx, 0)
+	if err != nil {
+		h.adminPageError(w, err)
+		return
+	}
+	// Backends that can't tell leave the storage section out
+	storage, err := h.adminService.StorageStats(ctx)
+	if _, ok := err.(service.NotFoundError); err != nil && !ok {
+		h.adminPageError(w, err)
+		return
+	}
+
+	data := struct {
+		BaseURL string
+		Users   []domain.UserSummary
+		Changes []domain.Shortcut
+		Storage *domain.StorageStats
+		Errors  domain.ErrorRates
+		Clicks  domain.ClickCounters
+		Caches  []domain.CacheStats
+	}{
+		BaseURL: h.config.BaseURL,
+		Users:   users,
+		Changes: changes,
+		Storage: storage,
+		Errors:  h.responses.rates(),
+		Clicks:  h.linkService.ClickCounters(),
+		Caches:  h.linkService.CacheStats(),
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := h.templates.ExecuteTemplate(w, "admin.html", data); err != nil {
+		h.logger.Error("Failed to execute template", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// adminPageError answers the admin dashboard with an error as plain text
+func (h *Handler) adminPageError(w http.ResponseWriter, err error) {
+	switch err.(type) {
+	case service.InvalidQueryError:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case service.ForbiddenError:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		h.logger.Error("Failed to build the admin dashboard", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// mockAdminService reports canned users, changes and storage, or no storage when it is nil
+type mockAdminService struct {
+	users   []domain.UserSummary
+	changes []domain.Shortcut
+	storage *domain.StorageStats
+}
+
+func newMockAdminService() *mockAdminService {
+	return &mockAdminService{
+		users: []domain.UserSummary{
+			{User: "alice", Role: domain.RoleEditor, Links: 2, Private: 1},
+			{User: "bob", Role: domain.RoleViewer},
+		},
+		changes: []domain.Shortcut{
+			{ID: 2, Word: "docs", Link: "https://docs.example.com/v2", User: "alice", UpdatedBy: "bob"},
+			{ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		},
+		storage: &domain.StorageStats{Links: 1, Tables: []domain.TableStats{{Name: "linktable", Rows: 2}}},
+	}
+}
+
+func (m *mockAdminService) ListUsers(ctx context.Context, requester string) ([]domain.UserSummary, error) {
+	return m.users, nil
+}
+
+func (m *mockAdminService) RecentChanges(ctx context.Context, limit int) ([]domain.Shortcut, error) {
+	if limit < 0 {
+		return nil, service.InvalidQueryError{Message: "bad limit"}
+	}
+	if limit > 0 && limit < len(m.changes) {
+		return m.changes[:limit], nil
+	}
+	return m.changes, nil
+}
+
+func (m *mockAdminService) StorageStats(ctx context.Context) (*domain.StorageStats, error) {
+	if m.storage == nil {
+		return nil, service.NotFoundError{Message: "no storage stats"}
+	}
+	return m.storage, nil
+}
+
+func TestHandler_AdminAPI(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "users", path: "/api/admin/users", wantStatus: http.StatusOK, wantBody: `"links":2`},
+		{name: "changes", path: "/api/admin/changes", wantStatus: http.StatusOK, wantBody: `docs.example.com/v2`},
+		{name: "changes with a limit", path: "/api/admin/changes?limit=1", wantStatus: http.StatusOK, wantBody: `"id":2`},
+		{name: "malformed limit", path: "/api/admin/changes?limit=many", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", path: "/api/admin/changes?limit=-1", wantStatus: http.StatusBadRequest},
+		{name: "storage", path: "/api/admin/storage", wantStatus: http.StatusOK, wantBody: `"linktable"`},
+		{name: "error rates", path: "/api/admin/errors", wantStatus: http.StatusOK, wantBody: `"last_hour"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GET %s status = %d, want %d, body = %s", tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s body = %s, want %s", tt.path, w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	// Backends that don't report their size answer 404
+	handler.adminService.(*mockAdminService).storage = nil
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/storage", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /api/admin/storage without storage stats status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_AdminErrorRates(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	for _, path := range []string{"/api/admin/trash", "/api/admin/changes?limit=-1", "/api/me/favorites"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/errors", nil))
+	var rates domain.ErrorRates
+	if err := json.NewDecoder(w.Body).Decode(&rates); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	// The request asking for the rates is counted once it has been answered
+	want := domain.ResponseCounts{Requests: 3, ClientErrors: 1}
+	if rates.LastMinute != want || rates.Total != want {
+		t.Errorf("GET /api/admin/errors = %+v, want %+v in the last minute and in total", rates, want)
+	}
+}
+
+func TestHandler_AdminPage(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /admin/ status = %d, want %d, body = %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	for _, want := range []string{"alice (editor): 2 links", "Changed docs", "Storage: 1 links"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET /admin/ body = %s, want %q", w.Body.String(), want)
+		}
+	}
+
+	// The page leaves out storage the backend can't report
+	handler.adminService.(*mockAdminService).storage = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/", nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Storage:") {
+		t.Errorf("GET /admin/ without storage stats = %d, %s, want the page without storage", w.Code, w.Body.String())
+	}
+}
+
+func TestHandler_Admin_Forbidden(t *testing.T) {
+	handler := setupTestHandler()
+	handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": domain.RoleEditor}}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	for _, path := range []string{"/admin/", "/api/admin/users", "/api/admin/changes", "/api/admin/storage", "/api/admin/errors"} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Code != http.StatusForbidden {
+				t.Errorf("GET %s status = %d, want %d", path, w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// aliasRoute matches an alias in a route path; like words, aliases may sit in a team
+// namespace
+const aliasRoute = "{alias:[^/]+(?:/[^/]+)?}"
+
+// ListAliasesHandler lists the other words a golink answers to
+func (h *Handler) ListAliasesHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+
+	aliases, err := h.linkService.ListAliases(r.Context(), word, h.getUserID(r))
+	if err != nil {
+		h.writeAPIError(w, err, "list aliases of "+word)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"word": word, "aliases": aliases})
+}
+
+// AddAliasHandler makes another word resolve to a golink
+func (h *Handler) AddAliasHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	word, alias := vars["word"], vars["alias"]
+	userID := h.getUserID(r)
+
+	if err := h.linkService.AddAlias(r.Context(), word, alias, userID); err != nil {
+		h.writeAPIError(w, err, "add alias "+alias)
+		return
+	}
+
+	h.logger.Info("alias", "word", word, "user", userID, "alias", alias)
+
+	h.writeAliases(w, r, word)
+}
+
+// RemoveAliasHandler stops a word resolving to a golink
+func (h *Handler) RemoveAliasHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	word, alias := vars["word"], vars["alias"]
+	userID := h.getUserID(r)
+
+	if err := h.linkService.RemoveAlias(r.Context(), word, alias, userID); err != nil {
+		h.writeAPIError(w, err, "remove alias "+alias)
+		return
+	}
+
+	h.logger.Info("unalias", "word", word, "user", userID, "alias", alias)
+
+	h.writeAliases(w, r, word)
+}
+
+// writeAliases responds with the aliases a golink has after a change
+func (h *Handler) writeAliases(w http.ResponseWriter, r *http.Request, word string) {
+	aliases, err := h.linkService.ListAliases(r.Context(), word, h.getUserID(r))
+	if err != nil {
+		h.writeAPIError(w, err, "list aliases of "+word)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"word": word, "aliases": aliases})
+}
+
+// RenameHandler moves a golink to a new word, keeping the old one as an alias
+func (h *Handler) RenameHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+
+	var req domain.RenameRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID := h.getUserID(r)
+
+	shortcut, err := h.linkService.RenameLink(r.Context(), word, req, userID)
+	if err == nil && shortcut == nil {
+		err = service.NotFoundError{Message: word + " can't be found after renaming it"}
+	}
+	if err != nil {
+		h.writeAPIError(w, err, "rename "+word)
+		return
+	}
+
+	h.logger.Info("rename", "word", word, "user", userID, "new_word", shortcut.Word)
+
+	writeJSON(w, http.StatusOK, shortcut)
+}
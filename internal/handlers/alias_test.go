@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_Aliases(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantStatus  int
+		wantAliases []string
+	}{
+		{name: "none yet", method: "GET", path: "/api/links/docs/aliases", wantStatus: http.StatusOK, wantAliases: []string{}},
+		{name: "add", method: "PUT", path: "/api/links/docs/aliases/documentation", wantStatus: http.StatusOK,
+			wantAliases: []string{"documentation"}},
+		{name: "add namespaced", method: "PUT", path: "/api/links/docs/aliases/team/docs", wantStatus: http.StatusOK,
+			wantAliases: []string{"documentation", "team/docs"}},
+		{name: "add a taken word", method: "PUT", path: "/api/links/docs/aliases/github", wantStatus: http.StatusBadRequest},
+		{name: "add to missing link", method: "PUT", path: "/api/links/missing/aliases/gone", wantStatus: http.StatusNotFound},
+		{name: "list", method: "GET", path: "/api/links/docs/aliases", wantStatus: http.StatusOK,
+			wantAliases: []string{"documentation", "team/docs"}},
+		{name: "remove", method: "DELETE", path: "/api/links/docs/aliases/documentation", wantStatus: http.StatusOK,
+			wantAliases: []string{"team/docs"}},
+		{name: "remove twice", method: "DELETE", path: "/api/links/docs/aliases/documentation", wantStatus: http.StatusNotFound},
+		{name: "list missing link", method: "GET", path: "/api/links/missing/aliases", wantStatus: http.StatusNotFound},
+		{name: "wrong method", method: "POST", path: "/api/links/docs/aliases/documentation", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d, body = %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantAliases == nil {
+				return
+			}
+
+			var resp struct {
+				Word    string   `json:"word"`
+				Aliases []string `json:"aliases"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Word != "docs" || strings.Join(resp.Aliases, ",") != strings.Join(tt.wantAliases, ",") {
+				t.Errorf("%s %s = %+v, want the aliases %v of docs", tt.method, tt.path, resp, tt.wantAliases)
+			}
+		})
+	}
+}
+
+func TestHandler_RenameHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{"rename", "/api/links/docs/rename", "application/json", `{"word": "documentation"}`, http.StatusOK},
+		{"taken word", "/api/links/docs/rename", "application/json", `{"word": "github"}`, http.StatusBadRequest},
+		{"no word", "/api/links/docs/rename", "application/json", `{}`, http.StatusBadRequest},
+		{"unknown field", "/api/links/docs/rename", "application/json", `{"word": "documentation", "keep": false}`, http.StatusBadRequest},
+		{"not json", "/api/links/docs/rename", "text/plain", `word=documentation`, http.StatusUnsupportedMediaType},
+		{"missing link", "/api/links/missing/rename", "application/json", `{"word": "documentation"}`, http.StatusNotFound},
+		{"gone after the rename", "/api/links/docs/rename", "application/json", `{"word": "vanished"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("RenameHandler() status = %v, want %v, body = %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var shortcut domain.Shortcut
+			if err := json.NewDecoder(w.Body).Decode(&shortcut); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if shortcut.Word != "documentation" || shortcut.Link != "https://docs.example.com" {
+				t.Errorf("RenameHandler() = %+v, want docs moved to documentation", shortcut)
+			}
+		})
+	}
+}
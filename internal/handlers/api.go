@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// maxAPIBodyBytes bounds the request body accepted by single-link API endpoints
+const maxAPIBodyBytes = 1 << 20
+
+// registerAPIv1 registers the versioned JSON API on a /api/v1 subrouter
+func (h *Handler) registerAPIv1(router *mux.Router) {
+	router.HandleFunc("/links", h.APIListLinksHandler).Methods("GET")
+	router.HandleFunc("/links", h.requireRole(domain.RoleEditor, h.APICreateLinkHandler)).Methods("POST")
+	router.HandleFunc("/links", methodNotAllowed("GET", "POST"))
+	router.HandleFunc("/links/"+wordRoute, h.APIGetLinkHandler).Methods("GET")
+	router.HandleFunc("/links/"+wordRoute, h.requireRole(domain.RoleEditor, h.APIPutLinkHandler)).Methods("PUT")
+	router.HandleFunc("/links/"+wordRoute, h.requireRole(domain.RoleEditor, h.APIDeleteLinkHandler)).Methods("DELETE")
+	router.HandleFunc("/links/"+wordRoute, methodNotAllowed("GET", "PUT", "DELETE"))
+	router.HandleFunc("/queries/popular", h.APIPopularQueriesHandler).Methods("GET")
+	router.HandleFunc("/queries/popular", methodNotAllowed("GET"))
+	router.HandleFunc("/keys", h.ListAPIKeysHandler).Methods("GET")
+	router.HandleFunc("/keys", h.CreateAPIKeyHandler).Methods("POST")
+	router.HandleFunc("/keys", methodNotAllowed("GET", "POST"))
+	router.HandleFunc("/keys/{id:[0-9]+}", h.RevokeAPIKeyHandler).Methods("DELETE")
+	router.HandleFunc("/keys/{id:[0-9]+}", methodNotAllowed("DELETE"))
+	router.HandleFunc("/roles", h.ListRolesHandler).Methods("GET")
+	router.HandleFunc("/roles", methodNotAllowed("GET"))
+	router.HandleFunc("/roles/{user}", h.SetRoleHandler).Methods("PUT")
+	router.HandleFunc("/roles/{user}", h.ResetRoleHandler).Methods("DELETE")
+	router.HandleFunc("/roles/{user}", methodNotAllowed("PUT", "DELETE"))
+	router.HandleFunc("/namespaces", h.ListNamespacesHandler).Methods("GET")
+	router.HandleFunc("/namespaces", h.requireRole(domain.RoleEditor, h.CreateNamespaceHandler)).Methods("POST")
+	router.HandleFunc("/namespaces", methodNotAllowed("GET", "POST"))
+	router.HandleFunc("/namespaces/{name}", h.GetNamespaceHandler).Methods("GET")
+	router.HandleFunc("/namespaces/{name}", h.DeleteNamespaceHandler).Methods("DELETE")
+	router.HandleFunc("/namespaces/{name}", methodNotAllowed("GET", "DELETE"))
+	router.HandleFunc("/namespaces/{name}/members/{user}", h.SetNamespaceMemberHandler).Methods("PUT")
+	router.HandleFunc("/namespaces/{name}/members/{user}", h.RemoveNamespaceMemberHandler).Methods("DELETE")
+	router.HandleFunc("/namespaces/{name}/members/{user}", methodNotAllowed("PUT", "DELETE"))
+
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, http.StatusNotFound, "Not found")
+	})
+}
+
+// methodNotAllowed answers requests using a method a route does not support. Routes
+// register it explicitly because mux's MethodNotAllowedHandler is unreliable on subrouters.
+func methodNotAllowed(allowed ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// APIListLinksHandler returns a page of keywords selected by the limit and offset parameters,
+// optionally filtered by a q search term. Responses carry an ETag so polling clients can
+// revalidate with If-None-Match and get a 304 while the list is unchanged.
+func (h *Handler) APIListLinksHandler(w http.ResponseWriter, r *http.Request) {
+	userID := h.getUserID(r)
+
+	etag, err := h.linkService.KeywordsETag(r.Context(), userID)
+	if err != nil {
+		h.writeAPIError(w, err, "get keywords version")
+		return
+	}
+	w.Header().Set("ETag", etag)
+	// The list includes the requester's private links, so shared caches mustn't keep it
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	limit, ok := intQueryParam(w, r, "limit")
+	if !ok {
+		return
+	}
+	offset, ok := intQueryParam(w, r, "offset")
+	if !ok {
+		return
+	}
+
+	page, err := h.linkService.ListKeywords(r.Context(), r.URL.Query().Get("q"), "", limit, offset, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "list links")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using the weak
+// comparison RFC 9110 requires for GET
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// intQueryParam parses an optional integer query parameter, writing an error response
+// if it is malformed. A missing parameter is 0.
+func intQueryParam(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid value for "+name+" parameter")
+		return 0, false
+	}
+	return parsed, true
+}
+
+// APICreateLinkHandler creates a new keyword, refusing to overwrite an existing one
+func (h *Handler) APICreateLinkHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, ok := decodeLinkRequest(w, r)
+	if !ok {
+		return
+	}
+	req.Word = strings.TrimSpace(req.Word)
+
+	if req.Word != "" {
+		if _, err := h.linkService.GetShortcut(ctx, req.Word, h.getUserID(r)); err == nil {
+			writeJSONError(w, http.StatusConflict, "A golink for "+req.Word+" already exists")
+			return
+		} else if _, ok := err.(service.NotFoundError); !ok {
+			h.writeAPIError(w, err, "check link "+req.Word)
+			return
+		}
+	}
+
+	h.saveLink(w, r, req, http.StatusCreated)
+}
+
+// APIGetLinkHandler returns the current version of a keyword, with when it was first
+// created and when and by whom it was last changed
+func (h *Handler) APIGetLinkHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+
+	detail, err := h.linkService.GetLinkDetail(r.Context(), word, h.getUserID(r))
+	if err != nil {
+		h.writeAPIError(w, err, "get link "+word)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// APIPutLinkHandler creates or replaces the keyword named in the path
+func (h *Handler) APIPutLinkHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+
+	req, ok := decodeLinkRequest(w, r)
+	if !ok {
+		return
+	}
+	if req.Word != "" && req.Word != word {
+		writeJSONError(w, http.StatusBadRequest, "Word in body does not match the URL")
+		return
+	}
+	req.Word = word
+
+	status := http.StatusOK
+	if _, err := h.linkService.GetShortcut(r.Context(), word, h.getUserID(r)); err != nil {
+		if _, ok := err.(service.NotFoundError); !ok {
+			h.writeAPIError(w, err, "check link "+word)
+			return
+		}
+		status = http.StatusCreated
+	}
+
+	h.saveLink(w, r, req, status)
+}
+
+// APIDeleteLinkHandler moves a keyword and all of its versions to the trash
+func (h *Handler) APIDeleteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+	userID := h.getUserID(r)
+
+	if err := h.linkService.DeleteLink(r.Context(), word, userID); err != nil {
+		h.writeAPIError(w, err, "delete link "+word)
+		return
+	}
+
+	h.logger.Info("delete", "word", word, "user", userID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// APIPopularQueriesHandler returns the most used keywords
+func (h *Handler) APIPopularQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	days, ok := intQueryParam(w, r, "days")
+	if !ok {
+		return
+	}
+	limit, ok := intQueryParam(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	queries, err := h.linkService.GetRecentQueries(r.Context(), days, limit)
+	if err != nil {
+		h.writeAPIError(w, err, "get popular queries")
+		return
+	}
+	if queries == nil {
+		queries = []domain.PopularQuery{}
+	}
+
+	writeJSON(w, http.StatusOK, queries)
+}
+
+// saveLink stores a link request and responds with the stored version
+func (h *Handler) saveLink(w http.ResponseWriter, r *http.Request, req domain.LinkRequest, status int) {
+	ctx := r.Context()
+	userID := h.getUserID(r)
+
+	if err := h.linkService.UpdateLink(ctx, req, userID); err != nil {
+		h.writeAPIError(w, err, "save link "+req.Word)
+		return
+	}
+
+	h.logger.Info("update", "word", req.Word, "user", userID, "link", req.Link)
+
+	detail, err := h.linkService.GetLinkDetail(ctx, req.Word, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "get link "+req.Word)
+		return
+	}
+
+	if status == http.StatusCreated {
+		w.Header().Set("Location", h.config.BaseURL+"/api/v1/links/"+url.PathEscape(req.Word))
+	}
+	writeJSON(w, status, detail)
+}
+
+// decodeLinkRequest reads a JSON link request, writing an error response if the
+// body is not JSON or cannot be parsed
+func decodeLinkRequest(w http.ResponseWriter, r *http.Request) (domain.LinkRequest, bool) {
+	var req domain.LinkRequest
+	ok := decodeJSONBody(w, r, &req)
+	return req, ok
+}
+
+// decodeJSONBody reads a JSON request body into v, writing an error response if the
+// body is not JSON, cannot be parsed or has fields v does not
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeJSONError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return false
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+// writeAPIError maps service errors onto HTTP status codes
+func (h *Handler) writeAPIError(w http.ResponseWriter, err error, action string) {
+	switch err.(type) {
+	case service.InvalidQueryError:
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	case service.NotFoundError:
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case service.ForbiddenError:
+		writeJSONError(w, http.StatusForbidden, err.Error())
+	case service.UnauthorizedError:
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeJSONError(w, http.StatusUnauthorized, err.Error())
+	case service.AliasLoopError:
+		writeJSONError(w, http.StatusLoopDetected, err.Error())
+	case service.ArchivedError:
+		writeJSONError(w, http.StatusGone, err.Error())
+	default:
+		h.logger.Error("Failed to "+action, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_APIListLinksHandler_ETag(t *testing.T) {
+	handler := setupTestHandler()
+	handler.linkService.(*mockLinkService).allKeywords = []domain.KeywordInfo{{Word: "a"}}
+
+	req := httptest.NewRequest("GET", "/api/v1/links", nil)
+	w := httptest.NewRecorder()
+	handler.APIListLinksHandler(w, req)
+
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("APIListLinksHandler() status = %d, ETag = %q", w.Code, etag)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{name: "matching", ifNoneMatch: etag, wantStatus: http.StatusNotModified},
+		{name: "weak match", ifNoneMatch: "W/" + etag, wantStatus: http.StatusNotModified},
+		{name: "one of several", ifNoneMatch: `"other", ` + etag, wantStatus: http.StatusNotModified},
+		{name: "wildcard", ifNoneMatch: "*", wantStatus: http.StatusNotModified},
+		{name: "stale", ifNoneMatch: `"k0"`, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/links", nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			w := httptest.NewRecorder()
+			handler.APIListLinksHandler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("APIListLinksHandler() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Header().Get("ETag") != etag {
+				t.Errorf("APIListLinksHandler() ETag = %q, want %q", w.Header().Get("ETag"), etag)
+			}
+			if tt.wantStatus == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("APIListLinksHandler() 304 body = %q, want empty", w.Body.String())
+			}
+		})
+	}
+
+	// Adding a keyword changes the tag, so the old one no longer matches
+	handler.linkService.(*mockLinkService).allKeywords = append(handler.linkService.(*mockLinkService).allKeywords, domain.KeywordInfo{Word: "b"})
+	req = httptest.NewRequest("GET", "/api/v1/links", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.APIListLinksHandler(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("APIListLinksHandler() after change status = %d, ETag = %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestHandler_APIListLinksHandler_PerUser(t *testing.T) {
+	handler := setupTestHandler()
+	links := handler.linkService.(*mockLinkService)
+	list := handler.APIKeyMiddleware(http.HandlerFunc(handler.APIListLinksHandler))
+
+	etags := map[string]string{}
+	for _, authorization := range []string{"", "Bearer glk_alice"} {
+		req := httptest.NewRequest("GET", "/api/v1/links", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		list.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("APIListLinksHandler() status = %d, want %d", w.Code, http.StatusOK)
+		}
+		// Lists include the requester's private links, so only their own cache may keep them
+		if got := w.Header().Get("Cache-Control"); got != "private, no-cache" {
+			t.Errorf("APIListLinksHandler() Cache-Control = %q, want private, no-cache", got)
+		}
+		etags[links.viewer] = w.Header().Get("ETag")
+	}
+
+	if len(etags) != 2 || etags["alice"] == etags["DefaultUser"] {
+		t.Errorf("APIListLinksHandler() ETags = %v, want one per user", etags)
+	}
+}
+
+func TestHandler_APIListLinksHandler(t *testing.T) {
+	handler := setupTestHandler()
+	handler.linkService.(*mockLinkService).allKeywords = []domain.KeywordInfo{
+		{Word: "a"}, {Word: "b"}, {Word: "c"},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantWords []string
+		wantTotal int
+	}{
+		{name: "page", query: "?limit=2&offset=1", wantWords: []string{"b", "c"}, wantTotal: 3},
+		{name: "search", query: "?q=b", wantWords: []string{"b"}, wantTotal: 1},
+		{name: "search without matches", query: "?q=zzz", wantWords: nil, wantTotal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/links"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.APIListLinksHandler(w, req)
+
+			var page domain.KeywordPage
+			if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			var words []string
+			for _, keyword := range page.Keywords {
+				words = append(words, keyword.Word)
+			}
+			if !reflect.DeepEqual(words, tt.wantWords) || page.Total != tt.wantTotal {
+				t.Errorf("APIListLinksHandler() = %+v, want words %v total %d", page, tt.wantWords, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestHandler_APIv1(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		contentType    string
+		body           string
+		updateError    error
+		expectedStatus int
+		expectedWord   string
+		expectedHeader string
+	}{
+		{
+			name:           "list links",
+			method:         "GET",
+			path:           "/api/v1/links?limit=10&offset=0",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "list links with invalid limit",
+			method:         "GET",
+			path:           "/api/v1/links?limit=ten",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "list links with negative offset",
+			method:         "GET",
+			path:           "/api/v1/links?offset=-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "get link",
+			method:         "GET",
+			path:           "/api/v1/links/docs",
+			expectedStatus: http.StatusOK,
+			expectedWord:   "docs",
+		},
+		{
+			name:           "get missing link",
+			method:         "GET",
+			path:           "/api/v1/links/missing",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "create link",
+			method:         "POST",
+			path:           "/api/v1/links",
+			contentType:    "application/json; charset=utf-8",
+			body:           `{"word":"wiki","link":"https://wiki.example.com"}`,
+			expectedStatus: http.StatusCreated,
+			expectedWord:   "wiki",
+			expectedHeader: "http://localhost:8080/api/v1/links/wiki",
+		},
+		{
+			name:           "create existing link",
+			method:         "POST",
+			path:           "/api/v1/links",
+			contentType:    "application/json",
+			body:           `{"word":"docs","link":"https://other.example.com"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "create without JSON content type",
+			method:         "POST",
+			path:           "/api/v1/links",
+			contentType:    "application/x-www-form-urlencoded",
+			body:           "word=wiki&link=https://wiki.example.com",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "create with unknown field",
+			method:         "POST",
+			path:           "/api/v1/links",
+			contentType:    "application/json",
+			body:           `{"word":"wiki","url":"https://wiki.example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "create invalid link",
+			method:         "POST",
+			path:           "/api/v1/links",
+			contentType:    "application/json",
+			body:           `{"word":"wiki","link":"nope"}`,
+			updateError:    service.InvalidQueryError{Message: "invalid"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "replace link",
+			method:         "PUT",
+			path:           "/api/v1/links/docs",
+			contentType:    "application/json",
+			body:           `{"link":"https://new.example.com"}`,
+			expectedStatus: http.StatusOK,
+			expectedWord:   "docs",
+		},
+		{
+			name:           "put new link",
+			method:         "PUT",
+			path:           "/api/v1/links/wiki",
+			contentType:    "application/json",
+			body:           `{"link":"https://wiki.example.com"}`,
+			expectedStatus: http.StatusCreated,
+			expectedWord:   "wiki",
+			expectedHeader: "http://localhost:8080/api/v1/links/wiki",
+		},
+		{
+			name:           "put mismatched word",
+			method:         "PUT",
+			path:           "/api/v1/links/docs",
+			contentType:    "application/json",
+			body:           `{"word":"wiki","link":"https://wiki.example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "put link owned by another user",
+			method:         "PUT",
+			path:           "/api/v1/links/docs",
+			contentType:    "application/json",
+			body:           `{"link":"https://new.example.com"}`,
+			updateError:    service.ForbiddenError{Message: "forbidden"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "delete link",
+			method:         "DELETE",
+			path:           "/api/v1/links/docs",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "delete missing link",
+			method:         "DELETE",
+			path:           "/api/v1/links/missing",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "popular queries",
+			method:         "GET",
+			path:           "/api/v1/queries/popular",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "popular queries this month",
+			method:         "GET",
+			path:           "/api/v1/queries/popular?days=30&limit=50",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "popular queries with a malformed window",
+			method:         "GET",
+			path:           "/api/v1/queries/popular?days=week",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "popular queries with a rejected limit",
+			method:         "GET",
+			path:           "/api/v1/queries/popular?limit=-5",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown route",
+			method:         "GET",
+			path:           "/api/v1/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "method not allowed",
+			method:         "PATCH",
+			path:           "/api/v1/links/docs",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.linkService.(*mockLinkService).updateError = tt.updateError
+
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("%s %s status = %v, want %v (body %s)", tt.method, tt.path, w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Location"); got != tt.expectedHeader {
+				t.Errorf("%s %s Location = %q, want %q", tt.method, tt.path, got, tt.expectedHeader)
+			}
+			if w.Code == http.StatusNoContent {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("%s %s Content-Type = %q, want application/json", tt.method, tt.path, ct)
+			}
+
+			if w.Code >= 400 {
+				var body map[string]string
+				if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body["detail"] == "" {
+					t.Errorf("%s %s error body = %v, want detail message", tt.method, tt.path, body)
+				}
+				return
+			}
+
+			if tt.expectedWord != "" {
+				var shortcut domain.Shortcut
+				if err := json.NewDecoder(w.Body).Decode(&shortcut); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if shortcut.Word != tt.expectedWord {
+					t.Errorf("%s %s word = %q, want %q", tt.method, tt.path, shortcut.Word, tt.expectedWord)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_APIGetLinkHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"existing word", "/api/v1/links/docs", http.StatusOK},
+		{"missing word", "/api/v1/links/nonexistent", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("APIGetLinkHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var detail domain.LinkDetail
+			if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if detail.Word != "docs" || detail.UpdatedBy != "alice" || !detail.UpdatedAt.After(detail.CreatedAt) {
+				t.Errorf("APIGetLinkHandler() = %+v, want docs last changed by alice after it was created", detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyService interface for API key operations
+type APIKeyService interface {
+	CreateKey(ctx context.Context, req domain.APIKeyRequest, requester string) (*domain.CreatedAPIKey, error)
+	ListKeys(ctx context.Context, requester string) ([]domain.APIKey, error)
+	RevokeKey(ctx context.Context, id int, requester string) error
+	Authenticate(ctx context.Context, token string) (*domain.APIKey, error)
+}
+
+// apiKeyUserKey carries the owner of the API key a request authenticated with
+type apiKeyUserKey struct{}
+
+// APIKeyMiddleware authenticates requests carrying an Authorization: Bearer API key,
+// attributing them to the key's owner. Requests without a bearer token pass through
+// unchanged; requests with an unknown or revoked key are rejected with 401.
+func (h *Handler) APIKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := h.apiKeyService.Authenticate(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			h.writeAPIError(w, err, "authenticate api key")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), apiKeyUserKey{}, key.User)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// CreateAPIKeyHandler mints a new API key, returning its secret once
+func (h *Handler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.APIKeyRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID := h.getUserID(r)
+	created, err := h.apiKeyService.CreateKey(r.Context(), req, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "create api key")
+		return
+	}
+
+	h.logger.Info("api key created", "id", created.ID, "name", created.Name, "for", created.User, "user", userID)
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListAPIKeysHandler lists every API key without their secrets
+func (h *Handler) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.ListKeys(r.Context(), h.getUserID(r))
+	if err != nil {
+		h.writeAPIError(w, err, "list api keys")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// RevokeAPIKeyHandler revokes an API key
+func (h *Handler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid API key id")
+		return
+	}
+
+	userID := h.getUserID(r)
+	if err := h.apiKeyService.RevokeKey(r.Context(), id, userID); err != nil {
+		h.writeAPIError(w, err, "revoke api key")
+		return
+	}
+
+	h.logger.Info("api key revoked", "id", id, "user", userID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+type mockAPIKeyService struct {
+	keys map[string]*domain.APIKey
+}
+
+func newMockAPIKeyService() *mockAPIKeyService {
+	return &mockAPIKeyService{keys: map[string]*domain.APIKey{
+		"glk_alice": {ID: 1, Name: "ci", User: "alice"},
+	}}
+}
+
+func (m *mockAPIKeyService) CreateKey(ctx context.Context, req domain.APIKeyRequest, requester string) (*domain.CreatedAPIKey, error) {
+	if requester != "DefaultUser" {
+		return nil, service.ForbiddenError{Message: "admins only"}
+	}
+	if req.Name == "" {
+		return nil, service.InvalidQueryError{Message: "name required"}
+	}
+	if req.User == "" {
+		req.User = requester
+	}
+	key := &domain.APIKey{ID: len(m.keys) + 1, Name: req.Name, User: req.User}
+	m.keys["glk_"+req.Name] = key
+	return &domain.CreatedAPIKey{APIKey: *key, Key: "glk_" + req.Name}, nil
+}
+
+func (m *mockAPIKeyService) ListKeys(ctx context.Context, requester string) ([]domain.APIKey, error) {
+	if requester != "DefaultUser" {
+		return nil, service.ForbiddenError{Message: "admins only"}
+	}
+	keys := []domain.APIKey{}
+	for _, key := range m.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyService) RevokeKey(ctx context.Context, id int, requester string) error {
+	if requester != "DefaultUser" {
+		return service.ForbiddenError{Message: "admins only"}
+	}
+	for token, key := range m.keys {
+		if key.ID == id {
+			delete(m.keys, token)
+			return nil
+		}
+	}
+	return service.NotFoundError{Message: "not found"}
+}
+
+func (m *mockAPIKeyService) Authenticate(ctx context.Context, token string) (*domain.APIKey, error) {
+	if key, ok := m.keys[token]; ok {
+		return key, nil
+	}
+	return nil, service.UnauthorizedError{Message: "Invalid API key"}
+}
+
+func TestHandler_APIKeyMiddleware(t *testing.T) {
+	handler := setupTestHandler()
+	whoami := handler.APIKeyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(handler.getUserID(r)))
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantUser      string
+	}{
+		{name: "no credentials", wantStatus: http.StatusOK, wantUser: "DefaultUser"},
+		{name: "valid key", authorization: "Bearer glk_alice", wantStatus: http.StatusOK, wantUser: "alice"},
+		{name: "scheme is case insensitive", authorization: "bearer glk_alice", wantStatus: http.StatusOK, wantUser: "alice"},
+		{name: "unknown key", authorization: "Bearer glk_nobody", wantStatus: http.StatusUnauthorized},
+		{name: "other schemes pass through", authorization: "Basic YWxpY2U6cGFzcw==", wantStatus: http.StatusOK, wantUser: "DefaultUser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/links", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			whoami.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("APIKeyMiddleware() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized {
+				if w.Header().Get("WWW-Authenticate") == "" {
+					t.Error("APIKeyMiddleware() 401 without WWW-Authenticate")
+				}
+				return
+			}
+			if w.Body.String() != tt.wantUser {
+				t.Errorf("APIKeyMiddleware() user = %q, want %q", w.Body.String(), tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestHandler_APIKeys(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	do := func(method, path, body, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/v1/keys", `{"name": "deploy", "user": "bob"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /api/v1/keys status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var created domain.CreatedAPIKey
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.Key == "" || created.User != "bob" {
+		t.Errorf("POST /api/v1/keys = %+v", created)
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "missing name", method: "POST", path: "/api/v1/keys", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown field", method: "POST", path: "/api/v1/keys", body: `{"name": "x", "admin": true}`, wantStatus: http.StatusBadRequest},
+		{name: "non-admin via key", method: "POST", path: "/api/v1/keys", body: `{"name": "x"}`, authorization: "Bearer " + created.Key, wantStatus: http.StatusForbidden},
+		{name: "list", method: "GET", path: "/api/v1/keys", wantStatus: http.StatusOK},
+		{name: "list by non-admin", method: "GET", path: "/api/v1/keys", authorization: "Bearer " + created.Key, wantStatus: http.StatusForbidden},
+		{name: "invalid key", method: "GET", path: "/api/v1/links", authorization: "Bearer glk_revoked", wantStatus: http.StatusUnauthorized},
+		{name: "revoke", method: "DELETE", path: "/api/v1/keys/1", wantStatus: http.StatusNoContent},
+		{name: "revoke again", method: "DELETE", path: "/api/v1/keys/1", wantStatus: http.StatusNotFound},
+		{name: "revoked key rejected", method: "GET", path: "/api/v1/links", authorization: "Bearer glk_alice", wantStatus: http.StatusUnauthorized},
+		{name: "wrong method", method: "PUT", path: "/api/v1/keys/2", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(tt.method, tt.path, tt.body, tt.authorization); w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d, body = %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// ArchiveHandler archives a golink, so following it explains that it was retired
+func (h *Handler) ArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+	userID := h.getUserID(r)
+
+	shortcut, err := h.linkService.ArchiveLink(r.Context(), word, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "archive "+word)
+		return
+	}
+
+	h.logger.Info("archive", "word", word, "user", userID)
+
+	writeJSON(w, http.StatusOK, shortcut)
+}
+
+// UnarchiveHandler makes an archived golink resolve again
+func (h *Handler) UnarchiveHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+	userID := h.getUserID(r)
+
+	shortcut, err := h.linkService.UnarchiveLink(r.Context(), word, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "unarchive "+word)
+		return
+	}
+
+	h.logger.Info("unarchive", "word", word, "user", userID)
+
+	writeJSON(w, http.StatusOK, shortcut)
+}
+
+// renderArchived explains that the golink a query leads to has been archived, showing
+// where it used to go so anyone still relying on it can find their way
+func (h *Handler) renderArchived(w http.ResponseWriter, query string, archived service.ArchivedError) {
+	data := struct {
+		BaseURL  string
+		Query    string
+		Shortcut *domain.Shortcut
+	}{
+		BaseURL:  h.config.BaseURL,
+		Query:    query,
+		Shortcut: archived.Shortcut,
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusGone)
+	if err := h.templates.ExecuteTemplate(w, "archived.html", data); err != nil {
+		h.logger.Error("Failed to execute template", "err", err)
+	}
+}
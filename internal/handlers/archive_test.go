@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_RedirectHandler_Archived(t *testing.T) {
+	handler := setupTestHandler()
+	handler.linkService.(*mockLinkService).getError = service.ArchivedError{
+		Message: "legacy has been archived",
+		Shortcut: &domain.Shortcut{
+			Word: "legacy", Link: "https://legacy.example.com", User: "alice", UpdatedBy: "bob", Archived: true,
+		},
+	}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		wantBody string
+	}{
+		{name: "page saying where it went", path: "/query/legacy", wantBody: "legacy was archived by bob and went to https://legacy.example.com"},
+		{name: "json", path: "/query/legacy", accept: "application/json", wantBody: `"detail":"legacy has been archived"`},
+		{name: "resolution detail", path: "/api/resolve/detail?q=legacy", wantBody: `"detail":"legacy has been archived"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusGone {
+				t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, http.StatusGone)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s body = %q, want it to contain %q", tt.path, w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_ArchiveHandlers(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		wantStatus   int
+		wantArchived bool
+	}{
+		{name: "archive", method: "POST", path: "/api/links/docs/archive", wantStatus: http.StatusOK, wantArchived: true},
+		{name: "archive missing link", method: "POST", path: "/api/links/missing/archive", wantStatus: http.StatusNotFound},
+		{name: "unarchive a link that isn't archived", method: "POST", path: "/api/links/docs/unarchive", wantStatus: http.StatusBadRequest},
+		{name: "unarchive missing link", method: "POST", path: "/api/links/missing/unarchive", wantStatus: http.StatusNotFound},
+		{name: "wrong method", method: "PUT", path: "/api/links/docs/archive", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d, body = %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var shortcut domain.Shortcut
+			if err := json.NewDecoder(w.Body).Decode(&shortcut); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if shortcut.Archived != tt.wantArchived || shortcut.UpdatedBy != "DefaultUser" {
+				t.Errorf("%s %s = %+v, want it archived by DefaultUser", tt.method, tt.path, shortcut)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golinks/internal/auth"
+)
+
+// OAuthProvider interface for signing users in with an external identity provider
+type OAuthProvider interface {
+	AuthCodeURL(state string) string
+	Exchange(ctx context.Context, code string) (string, error)
+}
+
+// PasswordAuthenticator interface for signing users in with a username and password
+type PasswordAuthenticator interface {
+	Authenticate(ctx context.Context, username, password string) (string, error)
+}
+
+// IdentityFunc returns the user a request was signed in as by something in front of
+// golinks, and false if it wasn't signed in
+type IdentityFunc func(r *http.Request) (string, bool)
+
+const (
+	// oauthStateCookie ties a login callback to the browser that started the login
+	oauthStateCookie = "golinks_oauth_state"
+
+	// oauthStateTTL is how long a user has to complete a login
+	oauthStateTTL = 10 * time.Minute
+
+	// maxLoginBodyBytes bounds the login form
+	maxLoginBodyBytes = 4 << 10
+)
+
+// LoginHandler starts a login, sending the user to the identity provider or showing the
+// username and password form
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	if h.passwords != nil {
+		h.renderLogin(w, r, http.StatusOK, r.URL.Query().Get("next"), "")
+		return
+	}
+	if h.oauth == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		h.logger.Error("Failed to generate login state", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	state := base64.RawURLEncoding.EncodeToString(nonce)
+	next := base64.RawURLEncoding.EncodeToString([]byte(localPath(r.URL.Query().Get("next"))))
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state + "." + next,
+		Path:     "/auth/",
+		MaxAge:   int(oauthStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.sessions.Secure(),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, h.oauth.AuthCodeURL(state), http.StatusFound)
+}
+
+// CallbackHandler finishes a login, starting a session for the signed-in user
+func (h *Handler) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	if h.oauth == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if reason := r.URL.Query().Get("error"); reason != "" {
+		http.Error(w, "Login was cancelled: "+reason, http.StatusUnauthorized)
+		return
+	}
+
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil {
+		http.Error(w, "Login expired, please try again", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Path: "/auth/", MaxAge: -1})
+
+	state, encodedNext, _ := strings.Cut(cookie.Value, ".")
+	if subtle.ConstantTimeCompare([]byte(state), []byte(r.URL.Query().Get("state"))) != 1 {
+		http.Error(w, "Invalid login state, please try again", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.oauth.Exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		if errors.Is(err, auth.ErrDomainNotAllowed) {
+			http.Error(w, "Your account is not allowed to sign in", http.StatusForbidden)
+			return
+		}
+		h.logger.Error("Failed to complete login", "err", err)
+		http.Error(w, "Login failed, please try again", http.StatusBadGateway)
+		return
+	}
+
+	if err := h.sessions.Issue(r.Context(), w, user); err != nil {
+		h.logger.Error("Failed to start session", "user", user, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("login", "user", user)
+
+	next, _ := base64.RawURLEncoding.DecodeString(encodedNext)
+	http.Redirect(w, r, h.config.BaseURL+localPath(string(next)), http.StatusFound)
+}
+
+// PasswordLoginHandler checks the login form and starts a session for the user
+func (h *Handler) PasswordLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if h.passwords == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxLoginBodyBytes)
+	if err := r.ParseForm(); err != nil {
+		h.renderLogin(w, r, http.StatusBadRequest, "", "Invalid login form")
+		return
+	}
+	username := r.PostForm.Get("username")
+	next := r.PostForm.Get("next")
+
+	user, err := h.passwords.Authenticate(r.Context(), username, r.PostForm.Get("password"))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			h.logger.Info("login failed", "user", username)
+			h.renderLogin(w, r, http.StatusUnauthorized, next, "Invalid username or password")
+		case errors.Is(err, auth.ErrGroupNotAllowed):
+			h.logger.Info("login refused: not in an allowed group", "user", username)
+			h.renderLogin(w, r, http.StatusForbidden, next, "Your account is not allowed to sign in")
+		default:
+			h.logger.Error("Failed to check login", "user", username, "err", err)
+			h.renderLogin(w, r, http.StatusBadGateway, next, "Login is unavailable, please try again later")
+		}
+		return
+	}
+
+	if err := h.sessions.Issue(r.Context(), w, user); err != nil {
+		h.logger.Error("Failed to start session", "user", user, "err", err)
+		h.renderLogin(w, r, http.StatusInternalServerError, next, "Internal server error")
+		return
+	}
+	h.logger.Info("login", "user", user)
+
+	http.Redirect(w, r, h.config.BaseURL+localPath(next), http.StatusSeeOther)
+}
+
+// renderLogin shows the username and password form
+func (h *Handler) renderLogin(w http.ResponseWriter, r *http.Request, status int, next, message string) {
+	data := struct {
+		BaseURL   string
+		Next      string
+		Error     string
+		CSRFToken string
+	}{
+		BaseURL:   h.config.BaseURL,
+		Next:      localPath(next),
+		Error:     message,
+		CSRFToken: h.csrfToken(w, r),
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := h.templates.ExecuteTemplate(w, "login.html", data); err != nil {
+		h.logger.Error("Failed to execute template", "err", err)
+	}
+}
+
+// LogoutHandler ends the user's session
+func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if h.sessions != nil {
+		h.sessions.Clear(w, r)
+	}
+	http.Redirect(w, r, h.config.BaseURL+"/homepage/", http.StatusFound)
+}
+
+// SetIdentityFunc makes identify decide who requests come from, ahead of golinks' own
+// sessions, and requires every request to be signed in by it or carry an API key
+func (h *Handler) SetIdentityFunc(identify IdentityFunc) {
+	h.identify = identify
+}
+
+// RequireLogin turns away requests that are neither signed in nor carrying an API key
+// when sign-in is configured. Browsers are sent to log in and come back;
+// API clients get a 401.
+func (h *Handler) RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (h.sessions == nil && h.identify == nil) || strings.HasPrefix(r.URL.Path, "/auth/") || strings.HasPrefix(r.URL.Path, "/static/") || isProbe(r.URL.Path) ||
+			r.URL.Path == "/opensearch.xml" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := h.authenticatedUser(r); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		isAPI := strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/graphql"
+		if h.sessions != nil && r.Method == http.MethodGet && !isAPI && !wantsJSON(r) {
+			loginURL := h.config.BaseURL + "/auth/login?next=" + url.QueryEscape(r.URL.RequestURI())
+			http.Redirect(w, r, loginURL, http.StatusFound)
+			return
+		}
+
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSONError(w, http.StatusUnauthorized, "Sign in or send an API key")
+	})
+}
+
+// authenticatedUser returns the user a request is authenticated as, by API key, the
+// embedding application or session
+func (h *Handler) authenticatedUser(r *http.Request) (string, bool) {
+	if userID, ok := r.Context().Value(apiKeyUserKey{}).(string); ok {
+		return userID, true
+	}
+	if h.identify != nil {
+		if userID, ok := h.identify(r); ok && userID != "" {
+			return userID, true
+		}
+	}
+	if h.sessions != nil {
+		return h.sessions.User(r)
+	}
+	return "", false
+}
+
+// localPath returns next if it is a path on this site, or the homepage otherwise, so
+// logins cannot be used to redirect users elsewhere
+func localPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/homepage/"
+	}
+	return next
+}
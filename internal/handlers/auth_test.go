@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golinks/internal/auth"
+
+	"github.com/gorilla/mux"
+)
+
+type mockOAuthProvider struct{}
+
+func (m *mockOAuthProvider) AuthCodeURL(state string) string {
+	return "https://idp.example.com/auth?state=" + url.QueryEscape(state)
+}
+
+func (m *mockOAuthProvider) Exchange(ctx context.Context, code string) (string, error) {
+	switch code {
+	case "good":
+		return "alice@example.com", nil
+	case "blocked":
+		return "", auth.ErrDomainNotAllowed
+	}
+	return "", errors.New("invalid_grant")
+}
+
+type mockPasswordAuthenticator struct{}
+
+func (m *mockPasswordAuthenticator) Authenticate(ctx context.Context, username, password string) (string, error) {
+	switch {
+	case username == "down":
+		return "", errors.New("connection refused")
+	case password != "hunter2":
+		return "", auth.ErrInvalidCredentials
+	case username == "contractor":
+		return "", auth.ErrGroupNotAllowed
+	}
+	return strings.ToLower(username), nil
+}
+
+// mockSessionStore keeps sessions in memory, ignoring expiry
+type mockSessionStore struct {
+	users map[string]string
+}
+
+func newMockSessionStore() *mockSessionStore {
+	return &mockSessionStore{users: map[string]string{}}
+}
+
+func (m *mockSessionStore) Create(ctx context.Context, tokenHash, user string, expiresAt time.Time) error {
+	m.users[tokenHash] = user
+	return nil
+}
+
+func (m *mockSessionStore) GetUser(ctx context.Context, tokenHash string, now time.Time) (string, error) {
+	return m.users[tokenHash], nil
+}
+
+func (m *mockSessionStore) Delete(ctx context.Context, tokenHash string) error {
+	delete(m.users, tokenHash)
+	return nil
+}
+
+func (m *mockSessionStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// setupLoginHandler returns a handler with sign-in enabled and a router serving it
+func setupLoginHandler(t *testing.T) (*Handler, *mux.Router) {
+	t.Helper()
+	handler := setupTestHandler()
+	handler.sessions = auth.NewSessions(newMockSessionStore(), false, slog.Default())
+	handler.oauth = &mockOAuthProvider{}
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	return handler, router
+}
+
+// login runs the login flow starting from next and returns the cookies it leaves behind
+func login(t *testing.T, router *mux.Router, next, code string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/auth/login?next="+url.QueryEscape(next), nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("LoginHandler() status = %d, want %d", w.Code, http.StatusFound)
+	}
+	providerURL, _ := url.Parse(w.Header().Get("Location"))
+	state := providerURL.Query().Get("state")
+
+	req := httptest.NewRequest("GET", "/auth/callback?code="+code+"&state="+url.QueryEscape(state), nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	callback := httptest.NewRecorder()
+	router.ServeHTTP(callback, req)
+	return callback
+}
+
+func TestHandler_LoginFlow(t *testing.T) {
+	handler, router := setupLoginHandler(t)
+
+	w := login(t, router, "/query/docs", "good")
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "http://localhost:8080/query/docs" {
+		t.Fatalf("CallbackHandler() status = %d, Location = %q", w.Code, w.Header().Get("Location"))
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	if user := handler.getUserID(req); user != "alice@example.com" {
+		t.Errorf("getUserID() after login = %q, want alice@example.com", user)
+	}
+
+	logout := httptest.NewRecorder()
+	logoutReq := httptest.NewRequest("GET", "/auth/logout", nil)
+	for _, cookie := range w.Result().Cookies() {
+		logoutReq.AddCookie(cookie)
+	}
+	router.ServeHTTP(logout, logoutReq)
+	if cookies := logout.Result().Cookies(); len(cookies) != 1 || cookies[0].Name != auth.SessionCookie || cookies[0].MaxAge >= 0 {
+		t.Errorf("LogoutHandler() cookies = %+v, want the session cleared", cookies)
+	}
+
+	// The old cookie no longer signs anyone in
+	if user := handler.getUserID(req); user != "DefaultUser" {
+		t.Errorf("getUserID() after logout = %q, want DefaultUser", user)
+	}
+
+	tests := []struct {
+		name         string
+		next         string
+		code         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "blocked domain", next: "/homepage/", code: "blocked", wantStatus: http.StatusForbidden},
+		{name: "failed exchange", next: "/homepage/", code: "bad", wantStatus: http.StatusBadGateway},
+		{name: "external next", next: "https://evil.example.com/", code: "good", wantStatus: http.StatusFound, wantLocation: "http://localhost:8080/homepage/"},
+		{name: "protocol relative next", next: "//evil.example.com/", code: "good", wantStatus: http.StatusFound, wantLocation: "http://localhost:8080/homepage/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := login(t, router, tt.next, tt.code)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("CallbackHandler() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantLocation != "" && w.Header().Get("Location") != tt.wantLocation {
+				t.Errorf("CallbackHandler() Location = %q, want %q", w.Header().Get("Location"), tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestHandler_CallbackHandler_InvalidState(t *testing.T) {
+	_, router := setupLoginHandler(t)
+
+	tests := []struct {
+		name   string
+		cookie string
+		query  string
+		want   int
+	}{
+		{name: "no state cookie", query: "?code=good&state=abc", want: http.StatusBadRequest},
+		{name: "mismatched state", cookie: "abc.", query: "?code=good&state=xyz", want: http.StatusBadRequest},
+		{name: "provider error", cookie: "abc.", query: "?error=access_denied&state=abc", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/auth/callback"+tt.query, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("CallbackHandler() status = %d, want %d", w.Code, tt.want)
+			}
+			if strings.Contains(w.Header().Get("Set-Cookie"), auth.SessionCookie) {
+				t.Error("CallbackHandler() started a session for an invalid login")
+			}
+		})
+	}
+}
+
+func TestHandler_RequireLogin(t *testing.T) {
+	_, router := setupLoginHandler(t)
+	session := login(t, router, "/homepage/", "good").Result().Cookies()
+
+	tests := []struct {
+		name          string
+		path          string
+		accept        string
+		authorization string
+		signedIn      bool
+		wantStatus    int
+		wantLocation  string
+	}{
+		{name: "browser is sent to log in", path: "/query/docs", wantStatus: http.StatusFound, wantLocation: "http://localhost:8080/auth/login?next=%2Fquery%2Fdocs"},
+		{name: "api gets 401", path: "/api/v1/links", wantStatus: http.StatusUnauthorized},
+		{name: "json client gets 401", path: "/homepage/", accept: "application/json", wantStatus: http.StatusUnauthorized},
+		{name: "signed in", path: "/homepage/", signedIn: true, wantStatus: http.StatusOK},
+		{name: "api key", path: "/api/v1/links", authorization: "Bearer glk_alice", wantStatus: http.StatusOK},
+		{name: "login page is public", path: "/auth/login", wantStatus: http.StatusFound, wantLocation: "https://idp.example.com/auth?state="},
+		{name: "opensearch description is public", path: "/opensearch.xml", wantStatus: http.StatusOK},
+		{name: "search needs login", path: "/search?q=docs", wantStatus: http.StatusFound, wantLocation: "http://localhost:8080/auth/login?next=%2Fsearch%3Fq%3Ddocs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.signedIn {
+				for _, cookie := range session {
+					req.AddCookie(cookie)
+				}
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("RequireLogin() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantLocation != "" && !strings.HasPrefix(w.Header().Get("Location"), tt.wantLocation) {
+				t.Errorf("RequireLogin() Location = %q, want %q", w.Header().Get("Location"), tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestHandler_SetIdentityFunc(t *testing.T) {
+	handler := setupTestHandler()
+	handler.SetIdentityFunc(func(r *http.Request) (string, bool) {
+		user := r.Header.Get("X-Portal-User")
+		return user, user != ""
+	})
+	whoami := handler.APIKeyMiddleware(handler.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(handler.getUserID(r)))
+	})))
+
+	tests := []struct {
+		name          string
+		path          string
+		user          string
+		authorization string
+		wantStatus    int
+		wantUser      string
+	}{
+		{name: "signed in by the portal", path: "/homepage/", user: "carol", wantStatus: http.StatusOK, wantUser: "carol"},
+		{name: "api key", path: "/api/v1/links", authorization: "Bearer glk_alice", wantStatus: http.StatusOK, wantUser: "alice"},
+		{name: "api key wins", path: "/api/v1/links", user: "carol", authorization: "Bearer glk_alice", wantStatus: http.StatusOK, wantUser: "alice"},
+		{name: "browser without a user gets 401", path: "/homepage/", wantStatus: http.StatusUnauthorized},
+		{name: "probes are public", path: "/healthz", wantStatus: http.StatusOK, wantUser: "DefaultUser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set("X-Portal-User", tt.user)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			whoami.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("RequireLogin() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantUser != "" && w.Body.String() != tt.wantUser {
+				t.Errorf("RequireLogin() user = %q, want %q", w.Body.String(), tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestHandler_LoginDisabled(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	for _, path := range []string{"/auth/login", "/auth/callback"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s status = %d without a login provider, want 404", path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/homepage/", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || handler.getUserID(req) != "DefaultUser" {
+		t.Errorf("GET /homepage/ status = %d without a login provider, want 200 as DefaultUser", w.Code)
+	}
+}
+
+func TestHandler_PasswordLogin(t *testing.T) {
+	handler := setupTestHandler()
+	handler.sessions = auth.NewSessions(newMockSessionStore(), false, slog.Default())
+	handler.passwords = &mockPasswordAuthenticator{}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	t.Run("shows the form", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/auth/login?next=/query/docs", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("LoginHandler() status = %d, want %d", w.Code, http.StatusOK)
+		}
+		if !strings.Contains(w.Body.String(), `value="/query/docs"`) {
+			t.Errorf("LoginHandler() body does not carry next: %s", w.Body.String())
+		}
+	})
+
+	tests := []struct {
+		name         string
+		username     string
+		password     string
+		next         string
+		wantStatus   int
+		wantLocation string
+		wantUser     string
+	}{
+		{"valid login", "Alice", "hunter2", "/query/docs", http.StatusSeeOther, "/query/docs", "alice"},
+		{"external next", "alice", "hunter2", "https://evil.example", http.StatusSeeOther, "/homepage/", "alice"},
+		{"wrong password", "alice", "wrong", "/", http.StatusUnauthorized, "", ""},
+		{"not in an allowed group", "contractor", "hunter2", "/", http.StatusForbidden, "", ""},
+		{"directory unavailable", "down", "hunter2", "/", http.StatusBadGateway, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"username": {tt.username}, "password": {tt.password}, "next": {tt.next}}
+			req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("PasswordLoginHandler() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantLocation == "" {
+				for _, cookie := range w.Result().Cookies() {
+					if cookie.Name == auth.SessionCookie {
+						t.Errorf("PasswordLoginHandler() started a session on a failed login")
+					}
+				}
+				if !strings.Contains(w.Body.String(), `class="error"`) {
+					t.Errorf("PasswordLoginHandler() did not show an error: %s", w.Body.String())
+				}
+				return
+			}
+			if got := w.Header().Get("Location"); got != handler.config.BaseURL+tt.wantLocation {
+				t.Errorf("PasswordLoginHandler() Location = %q, want %q", got, tt.wantLocation)
+			}
+
+			next := httptest.NewRequest("GET", "/homepage/", nil)
+			for _, cookie := range w.Result().Cookies() {
+				next.AddCookie(cookie)
+			}
+			if got := handler.getUserID(next); got != tt.wantUser {
+				t.Errorf("getUserID() after login = %q, want %q", got, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	tests := map[string]string{
+		"":                      "/homepage/",
+		"/query/docs?x=1":       "/query/docs?x=1",
+		"https://evil.example":  "/homepage/",
+		"//evil.example":        "/homepage/",
+		"/\\evil.example":       "/homepage/",
+		"javascript:alert(1)":   "/homepage/",
+		"/homepage/?tag=people": "/homepage/?tag=people",
+	}
+
+	for next, want := range tests {
+		if got := localPath(next); got != want {
+			t.Errorf("localPath(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
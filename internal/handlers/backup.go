@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"golinks/internal/domain"
+)
+
+// BackupService interface for database backup operations
+type BackupService interface {
+	ListBackups(ctx context.Context) ([]domain.Backup, error)
+	CreateBackup(ctx context.Context) (*domain.Backup, error)
+	RestoreBackup(ctx context.Context, req domain.RestoreRequest) (*domain.Backup, error)
+}
+
+// ListBackupsHandler lists the stored backups, newest first
+func (h *Handler) ListBackupsHandler(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.backupService.ListBackups(r.Context())
+	if err != nil {
+		h.writeAPIError(w, err, "list backups")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, backups)
+}
+
+// CreateBackupHandler snapshots the database into backup storage
+func (h *Handler) CreateBackupHandler(w http.ResponseWriter, r *http.Request) {
+	backup, err := h.backupService.CreateBackup(r.Context())
+	if err != nil {
+		h.writeAPIError(w, err, "create backup")
+		return
+	}
+
+	h.logger.Info("backup created", "name", backup.Name, "size", backup.Size, "user", h.getUserID(r))
+
+	writeJSON(w, http.StatusCreated, backup)
+}
+
+// RestoreBackupHandler replaces the database with a stored backup
+func (h *Handler) RestoreBackupHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.RestoreRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	backup, err := h.backupService.RestoreBackup(r.Context(), req)
+	if err != nil {
+		h.writeAPIError(w, err, "restore backup")
+		return
+	}
+
+	h.logger.Info("backup restored", "name", backup.Name, "user", h.getUserID(r))
+
+	writeJSON(w, http.StatusOK, backup)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// mockBackupService keeps backups in memory and records the last restore
+type mockBackupService struct {
+	backups  []domain.Backup
+	restored string
+}
+
+func newMockBackupService() *mockBackupService {
+	return &mockBackupService{
+		backups: []domain.Backup{{Name: "golinks-20240101-000000.000.db", Size: 4096, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+}
+
+func (m *mockBackupService) ListBackups(ctx context.Context) ([]domain.Backup, error) {
+	return m.backups, nil
+}
+
+func (m *mockBackupService) CreateBackup(ctx context.Context) (*domain.Backup, error) {
+	backup := domain.Backup{Name: "golinks-20240102-000000.000.db", Size: 8192, CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	m.backups = append([]domain.Backup{backup}, m.backups...)
+	return &backup, nil
+}
+
+func (m *mockBackupService) RestoreBackup(ctx context.Context, req domain.RestoreRequest) (*domain.Backup, error) {
+	for i := range m.backups {
+		if m.backups[i].Name == req.Name {
+			m.restored = req.Name
+			return &m.backups[i], nil
+		}
+	}
+	return nil, service.NotFoundError{Message: "no backup"}
+}
+
+func TestHandler_Backups(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedName   string
+	}{
+		{"create", "POST", "/api/admin/backup", "", http.StatusCreated, "golinks-20240102-000000.000.db"},
+		{"restore", "POST", "/api/admin/restore", `{"name": "golinks-20240101-000000.000.db"}`, http.StatusOK, "golinks-20240101-000000.000.db"},
+		{"restore missing", "POST", "/api/admin/restore", `{"name": "nope.db"}`, http.StatusNotFound, ""},
+		{"restore without a body", "POST", "/api/admin/restore", "", http.StatusUnsupportedMediaType, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("%s %s status = %d, want %d: %s", tt.method, tt.path, w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedName != "" {
+				var backup domain.Backup
+				if err := json.NewDecoder(w.Body).Decode(&backup); err != nil || backup.Name != tt.expectedName {
+					t.Errorf("%s %s = %+v, %v, want %s", tt.method, tt.path, backup, err, tt.expectedName)
+				}
+			}
+		})
+	}
+
+	if restored := handler.backupService.(*mockBackupService).restored; restored != "golinks-20240101-000000.000.db" {
+		t.Errorf("restored %q, want the requested backup", restored)
+	}
+
+	req := httptest.NewRequest("GET", "/api/admin/backups", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var backups []domain.Backup
+	if err := json.NewDecoder(w.Body).Decode(&backups); err != nil || len(backups) != 2 {
+		t.Errorf("GET /api/admin/backups = %+v, %v, want both backups", backups, err)
+	}
+}
+
+func TestHandler_Backups_Forbidden(t *testing.T) {
+	handler := setupTestHandler()
+	handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": domain.RoleEditor}}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"GET", "/api/admin/backups", ""},
+		{"POST", "/api/admin/backup", ""},
+		{"POST", "/api/admin/restore", `{"name": "golinks-20240101-000000.000.db"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, http.StatusForbidden)
+			}
+		})
+	}
+
+	if restored := handler.backupService.(*mockBackupService).restored; restored != "" {
+		t.Errorf("an editor restored %q", restored)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CSRFMiddleware(t *testing.T) {
+	const token = "token123"
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		headers     map[string]string
+		cookie      string
+		apiKey      bool
+		wantStatus  int
+	}{
+		{name: "page load", method: "GET", headers: map[string]string{"Sec-Fetch-Site": "cross-site"}, wantStatus: http.StatusOK},
+		{name: "form without cookie", method: "POST", contentType: "application/x-www-form-urlencoded", body: "word=x", headers: map[string]string{"Origin": "https://evil.example.com"}, wantStatus: http.StatusForbidden},
+		{name: "form without token", method: "POST", contentType: "application/x-www-form-urlencoded", body: "word=x", headers: map[string]string{"Origin": "https://evil.example.com"}, cookie: token, wantStatus: http.StatusForbidden},
+		{name: "form with token field", method: "POST", contentType: "application/x-www-form-urlencoded", body: "word=x&csrf_token=" + token, headers: map[string]string{"Origin": "http://localhost:8080"}, cookie: token, wantStatus: http.StatusOK},
+		{name: "form with wrong token header", method: "POST", contentType: "application/x-www-form-urlencoded", headers: map[string]string{"Origin": "http://localhost:8080", "X-CSRF-Token": "guess"}, cookie: token, wantStatus: http.StatusForbidden},
+		{name: "form with token header", method: "POST", contentType: "application/x-www-form-urlencoded", headers: map[string]string{"Origin": "http://localhost:8080", "X-CSRF-Token": token}, cookie: token, wantStatus: http.StatusOK},
+		{name: "JSON as plain text", method: "POST", contentType: "text/plain", body: `{"word":"x"}`, headers: map[string]string{"Sec-Fetch-Site": "cross-site"}, cookie: token, wantStatus: http.StatusForbidden},
+		{name: "no body type", method: "POST", headers: map[string]string{"Origin": "https://evil.example.com"}, wantStatus: http.StatusForbidden},
+		{name: "JSON needs a preflight", method: "POST", contentType: "application/json", body: `{"word":"x"}`, headers: map[string]string{"Origin": "http://localhost:8080"}, wantStatus: http.StatusOK},
+		{name: "delete needs a preflight", method: "DELETE", headers: map[string]string{"Origin": "http://localhost:8080"}, wantStatus: http.StatusOK},
+		{name: "command line client", method: "POST", contentType: "application/x-www-form-urlencoded", body: "word=x", wantStatus: http.StatusOK},
+		{name: "API key", method: "POST", contentType: "text/csv", headers: map[string]string{"Origin": "http://localhost:8080"}, apiKey: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/update/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: csrfCookie, Value: tt.cookie})
+			}
+			if tt.apiKey {
+				req = req.WithContext(context.WithValue(req.Context(), apiKeyUserKey{}, "alice"))
+			}
+			w := httptest.NewRecorder()
+			handler.CSRFMiddleware(next).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.name, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_CSRFToken_Pages(t *testing.T) {
+	handler := setupTestHandler()
+
+	req := httptest.NewRequest("GET", "/homepage/", nil)
+	w := httptest.NewRecorder()
+	handler.HomepageHandler(w, req)
+
+	var token string
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == csrfCookie {
+			token = cookie.Value
+		}
+	}
+	if token == "" || !strings.Contains(w.Body.String(), `data-csrf="`+token+`"`) {
+		t.Fatalf("homepage set CSRF cookie %q and rendered %s", token, w.Body.String())
+	}
+
+	// The token is kept for the browser's later pages, like the login form
+	req = httptest.NewRequest("GET", "/auth/login", nil)
+	req.AddCookie(&http.Cookie{Name: csrfCookie, Value: token})
+	w = httptest.NewRecorder()
+	handler.renderLogin(w, req, http.StatusOK, "/homepage/", "")
+
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("login page replaced the CSRF cookie: %v", w.Result().Cookies())
+	}
+	if !strings.Contains(w.Body.String(), `value="`+token+`"`) {
+		t.Errorf("login page = %s, want the CSRF token %s", w.Body.String(), token)
+	}
+}
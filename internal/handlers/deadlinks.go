@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"golinks/internal/domain"
+)
+
+// BrokenLinkReporter reports what the dead link checker found
+type BrokenLinkReporter interface {
+	BrokenLinks(ctx context.Context) (*domain.BrokenLinkReport, error)
+}
+
+// SetBrokenLinkReporter lets admins see the golinks the dead link checker found broken.
+// Without it the report answers 404.
+func (h *Handler) SetBrokenLinkReporter(reporter BrokenLinkReporter) {
+	h.brokenLinks = reporter
+}
+
+// BrokenLinksHandler reports the golinks whose targets looked broken when last checked,
+// with their owners, longest broken first
+func (h *Handler) BrokenLinksHandler(w http.ResponseWriter, r *http.Request) {
+	if h.brokenLinks == nil {
+		writeJSONError(w, http.StatusNotFound, "Dead link checks are off on this server")
+		return
+	}
+
+	report, err := h.brokenLinks.BrokenLinks(r.Context())
+	if err != nil {
+		h.writeAPIError(w, err, "get broken links")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// stubBrokenLinks reports a fixed set of broken links
+type stubBrokenLinks struct {
+	links []domain.LinkHealth
+}
+
+func (s *stubBrokenLinks) BrokenLinks(ctx context.Context) (*domain.BrokenLinkReport, error) {
+	return &domain.BrokenLinkReport{Links: s.links}, nil
+}
+
+func TestHandler_BrokenLinksHandler(t *testing.T) {
+	since := time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC)
+	reporter := &stubBrokenLinks{links: []domain.LinkHealth{
+		{Word: "old", Link: "https://old.example.com", Owner: "alice", Problem: "it answered 404 Not Found", CheckedAt: since, BrokenSince: &since},
+	}}
+
+	tests := []struct {
+		name       string
+		role       domain.Role
+		noChecker  bool
+		wantStatus int
+	}{
+		{name: "admin", role: domain.RoleAdmin, wantStatus: http.StatusOK},
+		{name: "editor", role: domain.RoleEditor, wantStatus: http.StatusForbidden},
+		{name: "checks off", role: domain.RoleAdmin, noChecker: true, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			if !tt.noChecker {
+				handler.SetBrokenLinkReporter(reporter)
+			}
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/api/admin/reports/broken", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GET /api/admin/reports/broken status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var got domain.BrokenLinkReport
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil || len(got.Links) != 1 || got.Links[0].Problem != "it answered 404 Not Found" {
+				t.Errorf("GET /api/admin/reports/broken = %+v, %v", got, err)
+			}
+		})
+	}
+}
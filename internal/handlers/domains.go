@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+)
+
+// SetDomainPolicy lets admins change the domains new links may point to while the server
+// runs. Without it the domain list endpoints answer 404.
+func (h *Handler) SetDomainPolicy(policy *service.DomainPolicy) {
+	h.domains = policy
+}
+
+// GetDomainsHandler reports the domains new links may and may not point to
+func (h *Handler) GetDomainsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.domains == nil {
+		writeJSONError(w, http.StatusNotFound, "The domain lists cannot be changed on this server")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.domains.Lists())
+}
+
+// SetDomainsHandler replaces the domain lists until the server restarts, such as to deny
+// a shortener that is being abused. Links already stored are left alone.
+func (h *Handler) SetDomainsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.domains == nil {
+		writeJSONError(w, http.StatusNotFound, "The domain lists cannot be changed on this server")
+		return
+	}
+
+	var req domain.DomainLists
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	lists, err := h.domains.SetLists(req)
+	if err != nil {
+		h.writeAPIError(w, err, "set domain lists")
+		return
+	}
+	// Logged at warn so the change is recorded at any level short of error
+	h.logger.Warn("domain lists set", "denied", lists.Denied, "allowed", lists.Allowed, "user", h.getUserID(r))
+
+	writeJSON(w, http.StatusOK, lists)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_Domains(t *testing.T) {
+	configured := domain.DomainLists{Denied: []string{"bit.ly"}, Allowed: []string{}}
+
+	tests := []struct {
+		name       string
+		role       domain.Role
+		method     string
+		body       string
+		noPolicy   bool
+		wantStatus int
+		wantLists  domain.DomainLists
+	}{
+		{name: "report", role: domain.RoleAdmin, method: "GET", wantStatus: http.StatusOK, wantLists: configured},
+		{
+			name: "replace", role: domain.RoleAdmin, method: "PUT", body: `{"denied": ["TinyURL.com"], "allowed": ["example.com"]}`,
+			wantStatus: http.StatusOK, wantLists: domain.DomainLists{Denied: []string{"tinyurl.com"}, Allowed: []string{"example.com"}},
+		},
+		{name: "clear", role: domain.RoleAdmin, method: "PUT", body: `{}`, wantStatus: http.StatusOK, wantLists: domain.DomainLists{Denied: []string{}, Allowed: []string{}}},
+		{name: "not a domain", role: domain.RoleAdmin, method: "PUT", body: `{"denied": ["https://bit.ly"]}`, wantStatus: http.StatusBadRequest, wantLists: configured},
+		{name: "editor", role: domain.RoleEditor, method: "PUT", body: `{"denied": []}`, wantStatus: http.StatusForbidden, wantLists: configured},
+		{name: "not adjustable", role: domain.RoleAdmin, method: "GET", noPolicy: true, wantStatus: http.StatusNotFound, wantLists: configured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			policy, err := service.NewDomainPolicy(configured)
+			if err != nil {
+				t.Fatalf("NewDomainPolicy() error = %v", err)
+			}
+			if !tt.noPolicy {
+				handler.SetDomainPolicy(policy)
+			}
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest(tt.method, "/api/admin/domains", strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s /api/admin/domains status = %d, want %d, body = %s", tt.method, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if lists := policy.Lists(); !reflect.DeepEqual(lists, tt.wantLists) {
+				t.Errorf("%s /api/admin/domains left the lists at %+v, want %+v", tt.method, lists, tt.wantLists)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var got domain.DomainLists
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil || !reflect.DeepEqual(got, tt.wantLists) {
+				t.Errorf("%s /api/admin/domains = %+v, %v, want %+v", tt.method, got, err, tt.wantLists)
+			}
+		})
+	}
+}
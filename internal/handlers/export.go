@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// exportFormatJSON is the only format links can be exported in so far
+const exportFormatJSON = "json"
+
+// ExportLinksHandler downloads every link, private ones included, with its owner, dates
+// and tags. Pass history=true to include every version of each link, and user, tag or
+// namespace to export only the links with that owner, tag or namespace.
+func (h *Handler) ExportLinksHandler(w http.ResponseWriter, r *http.Request) {
+	if format := r.URL.Query().Get("format"); format != "" && format != exportFormatJSON {
+		writeJSONError(w, http.StatusBadRequest, "Links can only be exported as json")
+		return
+	}
+
+	query := r.URL.Query()
+	req := domain.ExportRequest{
+		User:      strings.TrimSpace(query.Get("user")),
+		Tag:       strings.TrimSpace(query.Get("tag")),
+		Namespace: strings.TrimSpace(query.Get("namespace")),
+	}
+	if value := r.URL.Query().Get("history"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "Invalid value for history parameter")
+			return
+		}
+		req.History = parsed
+	}
+
+	export, err := h.linkService.ExportLinks(r.Context(), req)
+	if err != nil {
+		h.writeAPIError(w, err, "export links")
+		return
+	}
+
+	h.logger.Info("export", "user", h.getUserID(r), "links", len(export.Links), "history", req.History,
+		"owner", req.User, "tag", req.Tag, "namespace", req.Namespace)
+
+	filename := fmt.Sprintf("golinks-%s.json", export.ExportedAt.Format("20060102-150405"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	writeJSON(w, http.StatusOK, export)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_ExportLinksHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        domain.Role
+		query       string
+		wantStatus  int
+		wantRequest domain.ExportRequest
+	}{
+		{name: "latest versions", role: domain.RoleAdmin, wantStatus: http.StatusOK},
+		{name: "with history", role: domain.RoleAdmin, query: "?format=json&history=true", wantStatus: http.StatusOK,
+			wantRequest: domain.ExportRequest{History: true}},
+		{name: "filtered", role: domain.RoleAdmin, query: "?user=alice&tag=Docs&namespace=%20team%20", wantStatus: http.StatusOK,
+			wantRequest: domain.ExportRequest{User: "alice", Tag: "Docs", Namespace: "team"}},
+		{name: "unknown format", role: domain.RoleAdmin, query: "?format=csv", wantStatus: http.StatusBadRequest},
+		{name: "bad history", role: domain.RoleAdmin, query: "?history=maybe", wantStatus: http.StatusBadRequest},
+		{name: "editor", role: domain.RoleEditor, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			links := handler.linkService.(*mockLinkService)
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/api/links/export"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ExportLinksHandler() status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			if links.exportRequest != tt.wantRequest {
+				t.Errorf("ExportLinksHandler() request = %+v, want %+v", links.exportRequest, tt.wantRequest)
+			}
+			want := `attachment; filename="golinks-20240304-050607.json"`
+			if got := w.Header().Get("Content-Disposition"); got != want {
+				t.Errorf("ExportLinksHandler() Content-Disposition = %q, want %q", got, want)
+			}
+			var export domain.LinkExport
+			if err := json.NewDecoder(w.Body).Decode(&export); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(export.Links) != len(links.links) {
+				t.Errorf("ExportLinksHandler() exported %d links, want %d", len(export.Links), len(links.links))
+			}
+		})
+	}
+}
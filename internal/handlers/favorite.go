@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// FavoriteService interface for the links each user pins
+type FavoriteService interface {
+	AddFavorite(ctx context.Context, word, userID string) error
+	RemoveFavorite(ctx context.Context, word, userID string) error
+	ListFavorites(ctx context.Context, userID string) ([]domain.KeywordInfo, error)
+}
+
+// ListFavoritesHandler lists the links the user has pinned
+func (h *Handler) ListFavoritesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := h.getUserID(r)
+
+	favorites, err := h.favoriteService.ListFavorites(r.Context(), userID)
+	if err != nil {
+		h.writeAPIError(w, err, "list favorites of "+userID)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, favorites)
+}
+
+// AddFavoriteHandler pins the link named in the path for the user
+func (h *Handler) AddFavoriteHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+	userID := h.getUserID(r)
+
+	if err := h.favoriteService.AddFavorite(r.Context(), word, userID); err != nil {
+		h.writeAPIError(w, err, "pin "+word)
+		return
+	}
+
+	h.logger.Info("pin", "word", word, "user", userID)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// RemoveFavoriteHandler unpins the link named in the path for the user
+func (h *Handler) RemoveFavoriteHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+	userID := h.getUserID(r)
+
+	if err := h.favoriteService.RemoveFavorite(r.Context(), word, userID); err != nil {
+		h.writeAPIError(w, err, "unpin "+word)
+		return
+	}
+
+	h.logger.Info("unpin", "word", word, "user", userID)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
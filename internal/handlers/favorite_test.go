@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// mockFavoriteService lets anyone pin the links in the link mock
+type mockFavoriteService struct {
+	links     map[string]string
+	favorites map[string]map[string]bool
+}
+
+func newMockFavoriteService() *mockFavoriteService {
+	return &mockFavoriteService{
+		links: map[string]string{
+			"docs":   "https://docs.example.com",
+			"github": "https://github.com",
+		},
+		favorites: map[string]map[string]bool{},
+	}
+}
+
+func (m *mockFavoriteService) AddFavorite(ctx context.Context, word, userID string) error {
+	if _, ok := m.links[word]; !ok {
+		return service.NotFoundError{Message: "no golink"}
+	}
+	if m.favorites[userID] == nil {
+		m.favorites[userID] = map[string]bool{}
+	}
+	m.favorites[userID][word] = true
+	return nil
+}
+
+func (m *mockFavoriteService) RemoveFavorite(ctx context.Context, word, userID string) error {
+	if !m.favorites[userID][word] {
+		return service.NotFoundError{Message: "not pinned"}
+	}
+	delete(m.favorites[userID], word)
+	return nil
+}
+
+func (m *mockFavoriteService) ListFavorites(ctx context.Context, userID string) ([]domain.KeywordInfo, error) {
+	favorites := []domain.KeywordInfo{}
+	for word := range m.favorites[userID] {
+		favorites = append(favorites, domain.KeywordInfo{Word: word, Link: m.links[word]})
+	}
+	sort.Slice(favorites, func(i, j int) bool { return favorites[i].Word < favorites[j].Word })
+	return favorites, nil
+}
+
+func TestHandler_Favorites(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantWords  []string
+	}{
+		{name: "none pinned", method: "GET", path: "/api/me/favorites", wantStatus: http.StatusOK, wantWords: []string{}},
+		{name: "pin", method: "PUT", path: "/api/me/favorites/docs", wantStatus: http.StatusOK},
+		{name: "pin again", method: "PUT", path: "/api/me/favorites/docs", wantStatus: http.StatusOK},
+		{name: "pin missing", method: "PUT", path: "/api/me/favorites/missing", wantStatus: http.StatusNotFound},
+		{name: "list", method: "GET", path: "/api/me/favorites", wantStatus: http.StatusOK, wantWords: []string{"docs"}},
+		{name: "unpin not pinned", method: "DELETE", path: "/api/me/favorites/github", wantStatus: http.StatusNotFound},
+		{name: "unpin", method: "DELETE", path: "/api/me/favorites/docs", wantStatus: http.StatusOK},
+		{name: "list after unpin", method: "GET", path: "/api/me/favorites", wantStatus: http.StatusOK, wantWords: []string{}},
+		{name: "wrong method", method: "POST", path: "/api/me/favorites/docs", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d, body = %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantWords == nil {
+				return
+			}
+
+			var favorites []domain.KeywordInfo
+			if err := json.NewDecoder(w.Body).Decode(&favorites); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			words := []string{}
+			for _, favorite := range favorites {
+				words = append(words, favorite.Word)
+			}
+			if strings.Join(words, ",") != strings.Join(tt.wantWords, ",") {
+				t.Errorf("%s %s words = %v, want %v", tt.method, tt.path, words, tt.wantWords)
+			}
+		})
+	}
+}
+
+func TestHandler_HomepageFavorites(t *testing.T) {
+	handler, router := setupLoginHandler(t)
+	handler.favoriteService.(*mockFavoriteService).favorites["alice@example.com"] = map[string]bool{"github": true}
+
+	req := httptest.NewRequest("GET", "/homepage/", nil)
+	for _, cookie := range login(t, router, "/", "good").Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "Pinned: github") {
+		t.Errorf("signed-in homepage = %s, want the pinned links", w.Body.String())
+	}
+
+	// Pinning needs a user of one's own, so the homepage only offers it with sign-in
+	handler.sessions = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/homepage/", nil))
+	if body := w.Body.String(); strings.Contains(body, "Pinned:") {
+		t.Errorf("homepage without sign-in offers pins: %s", body)
+	}
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/graphql-go/graphql"
+)
+
+// graphQLRequest is the standard GraphQL-over-HTTP request body
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// graphQLUserKey carries the requesting user into resolvers
+type graphQLUserKey struct{}
+
+// graphQLRoleKey carries the requesting user's role into resolvers
+type graphQLRoleKey struct{}
+
+// GraphQLHandler serves the GraphQL API for links, tags and analytics
+func (h *Handler) GraphQLHandler() http.HandlerFunc {
+	schema := mustGraphQLSchema(h.linkService, h.tagService)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if r.Method == http.MethodGet {
+			req.Query = r.URL.Query().Get("query")
+			req.OperationName = r.URL.Query().Get("operationName")
+			if variables := r.URL.Query().Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					writeJSONError(w, http.StatusBadRequest, "Invalid variables")
+					return
+				}
+			}
+		} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBodyBytes)).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+
+		if req.Query == "" {
+			writeJSONError(w, http.StatusBadRequest, "Missing query")
+			return
+		}
+
+		userID := h.getUserID(r)
+		role, err := h.roleService.RoleOf(r.Context(), userID)
+		if err != nil {
+			h.writeAPIError(w, err, "get role")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), graphQLUserKey{}, userID)
+		ctx = context.WithValue(ctx, graphQLRoleKey{}, role)
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        ctx,
+		})
+
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// mustGraphQLSchema builds the GraphQL schema, panicking on the programming errors
+// graphql.NewSchema reports
+func mustGraphQLSchema(links LinkService, tags TagService) graphql.Schema {
+	createdAt := func() *graphql.Field {
+		return &graphql.Field{
+			Type: graphql.DateTime,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				switch source := p.Source.(type) {
+				case *domain.Shortcut:
+					return source.CreatedAt, nil
+				case domain.Shortcut:
+					return source.CreatedAt, nil
+				case domain.KeywordInfo:
+					return source.CreatedAt, nil
+				}
+				return time.Time{}, nil
+			},
+		}
+	}
+	updatedBy := func() *graphql.Field {
+		return &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				switch source := p.Source.(type) {
+				case *domain.Shortcut:
+					return source.UpdatedBy, nil
+				case domain.Shortcut:
+					return source.UpdatedBy, nil
+				case domain.KeywordInfo:
+					return source.UpdatedBy, nil
+				}
+				return "", nil
+			},
+		}
+	}
+	metadata := func() *graphql.Field {
+		return &graphql.Field{
+			Type:        graphql.String,
+			Description: "The link's metadata as a JSON object, if it has any",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				var fields map[string]interface{}
+				switch source := p.Source.(type) {
+				case *domain.Shortcut:
+					fields = source.Metadata
+				case domain.Shortcut:
+					fields = source.Metadata
+				case domain.KeywordInfo:
+					fields = source.Metadata
+				}
+				if len(fields) == 0 {
+					return nil, nil
+				}
+				encoded, err := json.Marshal(fields)
+				if err != nil {
+					return nil, err
+				}
+				return string(encoded), nil
+			},
+		}
+	}
+
+	shortcutType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Link",
+		Description: "A version of a golink",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.Int},
+			"word":        &graphql.Field{Type: graphql.String},
+			"link":        &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"user":        &graphql.Field{Type: graphql.String},
+			"icon":        &graphql.Field{Type: graphql.String},
+			"private":     &graphql.Field{Type: graphql.Boolean},
+			"prefix":      &graphql.Field{Type: graphql.Boolean},
+			"archived":    &graphql.Field{Type: graphql.Boolean},
+			"metadata":    metadata(),
+			"createdAt":   createdAt(),
+			"updatedBy":   updatedBy(),
+		},
+	})
+
+	keywordType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Keyword",
+		Description: "A golink with its aliases and tags",
+		Fields: graphql.Fields{
+			"word":        &graphql.Field{Type: graphql.String},
+			"aliases":     &graphql.Field{Type: graphql.String},
+			"link":        &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"icon":        &graphql.Field{Type: graphql.String},
+			"private":     &graphql.Field{Type: graphql.Boolean},
+			"prefix":      &graphql.Field{Type: graphql.Boolean},
+			"archived":    &graphql.Field{Type: graphql.Boolean},
+			"metadata":    metadata(),
+			"tags":        &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"createdAt":   createdAt(),
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if keyword, ok := p.Source.(domain.KeywordInfo); ok {
+						return keyword.UpdatedAt, nil
+					}
+					return time.Time{}, nil
+				},
+			},
+			"updatedBy": updatedBy(),
+		},
+	})
+
+	popularQueryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PopularQuery",
+		Fields: graphql.Fields{
+			"count": &graphql.Field{Type: graphql.Int},
+			"word":  &graphql.Field{Type: graphql.String},
+			"link":  &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	wordArgs := graphql.FieldConfigArgument{
+		"word": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+	}
+	linkArgs := graphql.FieldConfigArgument{
+		"word":        &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+		"link":        &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+		"description": &graphql.ArgumentConfig{Type: graphql.String},
+		"icon":        &graphql.ArgumentConfig{Type: graphql.String},
+		"private":     &graphql.ArgumentConfig{Type: graphql.Boolean},
+		"prefix":      &graphql.ArgumentConfig{Type: graphql.Boolean},
+		"archived":    &graphql.ArgumentConfig{Type: graphql.Boolean},
+		"owner":       &graphql.ArgumentConfig{Type: graphql.String},
+		"force":       &graphql.ArgumentConfig{Type: graphql.Boolean},
+	}
+
+	saveLink := func(p graphql.ResolveParams) (interface{}, error) {
+		req := domain.LinkRequest{
+			Word: p.Args["word"].(string),
+			Link: p.Args["link"].(string),
+		}
+		req.Description, _ = p.Args["description"].(string)
+		req.Icon, _ = p.Args["icon"].(string)
+		req.Private, _ = p.Args["private"].(bool)
+		req.Prefix, _ = p.Args["prefix"].(bool)
+		req.Archived, _ = p.Args["archived"].(bool)
+		req.Owner, _ = p.Args["owner"].(string)
+		req.Force, _ = p.Args["force"].(bool)
+
+		if err := links.UpdateLink(p.Context, req, graphQLUser(p.Context)); err != nil {
+			return nil, err
+		}
+		return links.GetShortcut(p.Context, req.Word, graphQLUser(p.Context))
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"link": &graphql.Field{
+				Type:        shortcutType,
+				Description: "The current version of a golink",
+				Args:        wordArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return links.GetShortcut(p.Context, p.Args["word"].(string), graphQLUser(p.Context))
+				},
+			},
+			"keywords": &graphql.Field{
+				Type:        graphql.NewList(keywordType),
+				Description: "Every golink, optionally filtered by tag",
+				Args: graphql.FieldConfigArgument{
+					"tag": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if tag, _ := p.Args["tag"].(string); tag != "" {
+						return tags.GetKeywordsByTag(p.Context, tag, graphQLUser(p.Context))
+					}
+					return links.GetAllKeywords(p.Context, graphQLUser(p.Context))
+				},
+			},
+			"history": &graphql.Field{
+				Type:        graphql.NewList(shortcutType),
+				Description: "Every version of a golink, newest first",
+				Args:        wordArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return links.GetHistory(p.Context, p.Args["word"].(string), graphQLUser(p.Context))
+				},
+			},
+			"tags": &graphql.Field{
+				Type:        graphql.NewList(graphql.String),
+				Description: "The tags of a golink",
+				Args:        wordArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return tags.GetTags(p.Context, p.Args["word"].(string), graphQLUser(p.Context))
+				},
+			},
+			"popularQueries": &graphql.Field{
+				Type:        graphql.NewList(popularQueryType),
+				Description: "The most used golinks over the last few days",
+				Args: graphql.FieldConfigArgument{
+					"days":  &graphql.ArgumentConfig{Type: graphql.Int, Description: "Window in days, 3 by default"},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Number of golinks, 20 by default"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					days, _ := p.Args["days"].(int)
+					limit, _ := p.Args["limit"].(int)
+					return links.GetRecentQueries(p.Context, days, limit)
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createLink": &graphql.Field{
+				Type:        shortcutType,
+				Description: "Create a golink, or add a new version of one you own",
+				Args:        linkArgs,
+				Resolve:     editorOnly(saveLink),
+			},
+			"updateLink": &graphql.Field{
+				Type:        shortcutType,
+				Description: "Point an existing golink at a new target",
+				Args:        linkArgs,
+				Resolve: editorOnly(func(p graphql.ResolveParams) (interface{}, error) {
+					if _, err := links.GetShortcut(p.Context, p.Args["word"].(string), graphQLUser(p.Context)); err != nil {
+						return nil, err
+					}
+					return saveLink(p)
+				}),
+			},
+			"deleteLink": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Move a golink and all of its versions to the trash",
+				Args:        wordArgs,
+				Resolve: editorOnly(func(p graphql.ResolveParams) (interface{}, error) {
+					if err := links.DeleteLink(p.Context, p.Args["word"].(string), graphQLUser(p.Context)); err != nil {
+						return false, err
+					}
+					return true, nil
+				}),
+			},
+			"addTags": &graphql.Field{
+				Type:        graphql.NewList(graphql.String),
+				Description: "Add tags to a golink, returning all of its tags",
+				Args: graphql.FieldConfigArgument{
+					"word": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"tags": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+				},
+				Resolve: editorOnly(func(p graphql.ResolveParams) (interface{}, error) {
+					var names []string
+					for _, tag := range p.Args["tags"].([]interface{}) {
+						names = append(names, tag.(string))
+					}
+					return tags.AddTags(p.Context, p.Args["word"].(string), names, graphQLUser(p.Context))
+				}),
+			},
+			"removeTag": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Remove a tag from a golink",
+				Args: graphql.FieldConfigArgument{
+					"word": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"tag":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: editorOnly(func(p graphql.ResolveParams) (interface{}, error) {
+					if err := tags.RemoveTag(p.Context, p.Args["word"].(string), p.Args["tag"].(string), graphQLUser(p.Context)); err != nil {
+						return false, err
+					}
+					return true, nil
+				}),
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// editorOnly wraps a mutation resolver so that only editors and admins can run it
+func editorOnly(resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		role, _ := p.Context.Value(graphQLRoleKey{}).(domain.Role)
+		if !role.Includes(domain.RoleEditor) {
+			return nil, service.ForbiddenError{Message: "This needs the editor role"}
+		}
+		return resolve(p)
+	}
+}
+
+// graphQLUser returns the user making a GraphQL request
+func graphQLUser(ctx context.Context) string {
+	userID, _ := ctx.Value(graphQLUserKey{}).(string)
+	return userID
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+)
+
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func doGraphQL(t *testing.T, handler *Handler, query string, variables map[string]interface{}) graphQLResponse {
+	t.Helper()
+
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.GraphQLHandler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GraphQLHandler() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var resp graphQLResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestHandler_GraphQLHandler_Queries(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      string
+		wantError bool
+	}{
+		{
+			name:  "link",
+			query: `{ link(word: "docs") { word link user } }`,
+			want:  `{"link":{"link":"https://docs.example.com","user":"DefaultUser","word":"docs"}}`,
+		},
+		{
+			name:      "missing link",
+			query:     `query($word: String!) { link(word: $word) { word } }`,
+			variables: map[string]interface{}{"word": "missing"},
+			wantError: true,
+		},
+		{
+			name:  "keywords",
+			query: `{ keywords { word link } }`,
+			want:  `{"keywords":[{"link":"https://docs.example.com","word":"docs"}]}`,
+		},
+		{
+			name:  "keywords by tag",
+			query: `{ keywords(tag: "engineering") { word } }`,
+			want:  `{"keywords":[{"word":"docs"}]}`,
+		},
+		{
+			name:  "history",
+			query: `{ history(word: "docs") { id link } }`,
+			want:  `{"history":[{"id":2,"link":"https://docs.example.com"},{"id":1,"link":"https://docs.example.com/old"}]}`,
+		},
+		{
+			name:  "metadata as json",
+			query: `{ history(word: "docs") { id metadata } }`,
+			want:  `{"history":[{"id":2,"metadata":"{\"team\":\"docs\"}"},{"id":1,"metadata":null}]}`,
+		},
+		{
+			name:  "tags",
+			query: `{ tags(word: "docs") }`,
+			want:  `{"tags":["engineering"]}`,
+		},
+		{
+			name:  "popular queries",
+			query: `{ popularQueries { count word } }`,
+			want:  `{"popularQueries":[{"count":5,"word":"docs"}]}`,
+		},
+		{
+			name:  "popular queries this week",
+			query: `{ popularQueries(days: 7, limit: 5) { word } }`,
+			want:  `{"popularQueries":[{"word":"docs"}]}`,
+		},
+		{
+			name:      "syntax error",
+			query:     `{ link(word: }`,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doGraphQL(t, setupTestHandler(), tt.query, tt.variables)
+
+			if (len(resp.Errors) > 0) != tt.wantError {
+				t.Fatalf("GraphQLHandler() errors = %+v, wantError %v", resp.Errors, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+
+			got, err := json.Marshal(resp.Data)
+			if err != nil {
+				t.Fatalf("Failed to marshal data: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("GraphQLHandler() data = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_GraphQLHandler_Mutations(t *testing.T) {
+	handler := setupTestHandler()
+	mockService := handler.linkService.(*mockLinkService)
+
+	resp := doGraphQL(t, handler, `mutation { createLink(word: "wiki", link: "https://wiki.example.com") { word link } }`, nil)
+	if len(resp.Errors) > 0 || mockService.links["wiki"] != "https://wiki.example.com" {
+		t.Errorf("createLink errors = %+v, links = %v", resp.Errors, mockService.links)
+	}
+
+	resp = doGraphQL(t, handler, `mutation { updateLink(word: "missing", link: "https://example.com") { word } }`, nil)
+	if len(resp.Errors) == 0 {
+		t.Error("updateLink expected error for missing word")
+	}
+
+	resp = doGraphQL(t, handler, `mutation { updateLink(word: "docs", link: "https://new.example.com") { link } }`, nil)
+	if len(resp.Errors) > 0 || mockService.links["docs"] != "https://new.example.com" {
+		t.Errorf("updateLink errors = %+v, links = %v", resp.Errors, mockService.links)
+	}
+
+	resp = doGraphQL(t, handler, `mutation { addTags(word: "docs", tags: ["howto"]) }`, nil)
+	if len(resp.Errors) > 0 || string(resp.Data["addTags"]) != `["engineering","howto"]` {
+		t.Errorf("addTags = %s, errors = %+v", resp.Data["addTags"], resp.Errors)
+	}
+
+	resp = doGraphQL(t, handler, `mutation { removeTag(word: "docs", tag: "howto") }`, nil)
+	if len(resp.Errors) > 0 || string(resp.Data["removeTag"]) != "true" {
+		t.Errorf("removeTag = %s, errors = %+v", resp.Data["removeTag"], resp.Errors)
+	}
+
+	resp = doGraphQL(t, handler, `mutation { deleteLink(word: "docs") }`, nil)
+	if len(resp.Errors) > 0 || string(resp.Data["deleteLink"]) != "true" {
+		t.Errorf("deleteLink = %s, errors = %+v", resp.Data["deleteLink"], resp.Errors)
+	}
+	if _, exists := mockService.links["docs"]; exists {
+		t.Error("deleteLink did not remove docs")
+	}
+
+	mockService.updateError = service.ForbiddenError{Message: "docs is owned by alice"}
+	resp = doGraphQL(t, handler, `mutation { createLink(word: "github", link: "https://github.com/x") { word } }`, nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "docs is owned by alice" {
+		t.Errorf("createLink errors = %+v, want ownership error", resp.Errors)
+	}
+}
+
+func TestHandler_GraphQLHandler_ViewerCannotMutate(t *testing.T) {
+	handler := setupTestHandler()
+	handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": domain.RoleViewer}}
+
+	resp := doGraphQL(t, handler, `{ link(word: "docs") { link } }`, nil)
+	if len(resp.Errors) != 0 {
+		t.Errorf("link query errors = %+v, want none for a viewer", resp.Errors)
+	}
+
+	for _, mutation := range []string{
+		`mutation { createLink(word: "wiki", link: "https://wiki.example.com") { word } }`,
+		`mutation { deleteLink(word: "docs") }`,
+		`mutation { addTags(word: "docs", tags: ["howto"]) }`,
+	} {
+		resp := doGraphQL(t, handler, mutation, nil)
+		if len(resp.Errors) != 1 || resp.Errors[0].Message != "This needs the editor role" {
+			t.Errorf("%s errors = %+v, want a role error", mutation, resp.Errors)
+		}
+	}
+}
+
+func TestHandler_GraphQLHandler_BadRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"invalid JSON", "POST", "/graphql", "{", http.StatusBadRequest},
+		{"missing query", "POST", "/graphql", `{"query": ""}`, http.StatusBadRequest},
+		{"GET query", "GET", "/graphql?query=" + url.QueryEscape(`{ tags(word: "docs") }`), "", http.StatusOK},
+		{"GET invalid variables", "GET", "/graphql?query=x&variables=nope", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			setupTestHandler().GraphQLHandler().ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("GraphQLHandler() status = %v, want %v", w.Code, tt.want)
+			}
+		})
+	}
+}
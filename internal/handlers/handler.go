@@ -5,10 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
-	"log"
+	"log/slog"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"golinks/internal/auth"
 	"golinks/internal/config"
 	"golinks/internal/domain"
 	"golinks/internal/service"
@@ -18,48 +27,255 @@ import (
 
 // LinkService interface for link operations
 type LinkService interface {
-	GetLink(ctx context.Context, word string, searchTerm string) (string, error)
+	GetLink(ctx context.Context, word, searchTerm, userID string) (string, error)
 	UpdateLink(ctx context.Context, req domain.LinkRequest, userID string) error
-	GetRecentQueries(ctx context.Context) ([]domain.PopularQuery, error)
-	GetAllKeywords(ctx context.Context) ([]domain.KeywordInfo, error)
+	GetRecentQueries(ctx context.Context, days, limit int) ([]domain.PopularQuery, error)
+	GetAllKeywords(ctx context.Context, userID string) ([]domain.KeywordInfo, error)
+	ListKeywords(ctx context.Context, search, sort string, limit, offset int, userID string) (*domain.KeywordPage, error)
+	SuggestLinks(ctx context.Context, query, userID string, limit int) (*domain.Suggestions, error)
+	KeywordsETag(ctx context.Context, userID string) (string, error)
+	ResolveDetail(ctx context.Context, query string, logQuery bool, userID string) (*domain.Resolution, error)
+	DeleteLink(ctx context.Context, word string, userID string) error
+	GetShortcut(ctx context.Context, word, userID string) (*domain.Shortcut, error)
+	GetLinkDetail(ctx context.Context, word, userID string) (*domain.LinkDetail, error)
+	GetHistory(ctx context.Context, word, userID string) ([]domain.Shortcut, error)
+	RollbackLink(ctx context.Context, word string, revisionID int, userID string) (*domain.Shortcut, error)
+	TransferLink(ctx context.Context, word string, req domain.TransferRequest, userID string) (*domain.Shortcut, error)
+	ArchiveLink(ctx context.Context, word, userID string) (*domain.Shortcut, error)
+	UnarchiveLink(ctx context.Context, word, userID string) (*domain.Shortcut, error)
+	SetMetadata(ctx context.Context, word string, metadata map[string]interface{}, userID string) (*domain.Shortcut, error)
+	RenameLink(ctx context.Context, word string, req domain.RenameRequest, userID string) (*domain.Shortcut, error)
+	ListAliases(ctx context.Context, word, userID string) ([]string, error)
+	AddAlias(ctx context.Context, word, alias, userID string) error
+	RemoveAlias(ctx context.Context, word, alias, userID string) error
+	BulkUpdateLinks(ctx context.Context, reqs []domain.LinkRequest, userID string) ([]domain.BulkLinkResult, error)
+	ImportLinks(ctx context.Context, links []domain.ImportLink, policy, userID string, dryRun bool) (*domain.ImportReport, error)
+	ExportLinks(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error)
+	ListTrash(ctx context.Context) ([]domain.TrashedLink, error)
+	RestoreLink(ctx context.Context, word, userID string) (*domain.Shortcut, error)
+	PurgeLink(ctx context.Context, word string) error
+	GetChanges(ctx context.Context, since, limit int) (*domain.LinkChanges, error)
+	CreatedLinks(ctx context.Context, userID, cursor string, limit int) ([]domain.LinkActivity, error)
+	DeletedLinks(ctx context.Context, userID, cursor string, limit int) ([]domain.LinkActivity, error)
+	GetLinkStats(ctx context.Context, word, interval string, days int, userID string) (*domain.LinkStats, error)
+	GetUserLinks(ctx context.Context, userID string) (*domain.UserLinks, error)
+	StaleLinks(ctx context.Context, days int) (*domain.StaleLinkReport, error)
+	PruneQueries(ctx context.Context) (*domain.QueryPrune, error)
+	SubscribeClicks() (<-chan domain.ClickEvent, func())
+	ClickCounters() domain.ClickCounters
+	CacheStats() []domain.CacheStats
 }
 
+// wordRoute matches a golink word in a route path; words may sit in a team namespace,
+// like payments/runbook
+const wordRoute = "{word:[^/]+(?:/[^/]+)?}"
+
+// maxBulkBodyBytes bounds the request body accepted by the bulk and import endpoints
+const maxBulkBodyBytes = 5 << 20
+
 // Handler holds the HTTP handlers
 type Handler struct {
-	linkService LinkService
-	config      *config.Config
-	templates   *template.Template
+	linkService   LinkService
+	tagService    TagService
+	apiKeyService APIKeyService
+	roleService   RoleService
+	config        *config.Config
+	templates     *template.Template
+	logger        *slog.Logger
+
+	namespaceService NamespaceService
+	backupService    BackupService
+	favoriteService  FavoriteService
+	adminService     AdminService
+
+	// responses counts the responses sent and how many were errors, for the admin dashboard
+	responses *responseCounter
+
+	// sessions is set when sign-in is configured, along with either oauth for Google
+	// or passwords for LDAP
+	sessions  *auth.Sessions
+	oauth     OAuthProvider
+	passwords PasswordAuthenticator
+
+	// identify names the user a request comes from when an application embedding golinks
+	// signs users in itself
+	identify IdentityFunc
+
+	// readiness holds the checks /readyz runs, by name
+	readiness map[string]HealthCheck
+
+	// logLevel is the level the server logs at, when admins may change it
+	logLevel *slog.LevelVar
+
+	// domains limits where new links may point, when admins may change it
+	domains *service.DomainPolicy
+
+	// brokenLinks reports the dead link checker's findings, when it runs
+	brokenLinks BrokenLinkReporter
+
+	// wordRules are the syntax new keywords must follow, shown beside the form adding them
+	wordRules *service.WordRules
+
+	// closing is closed by CloseStreams to end long-lived streams before shutdown
+	closing     chan struct{}
+	closingOnce sync.Once
 }
 
-// NewHandler creates a new handler
-func NewHandler(linkService LinkService, cfg *config.Config) *Handler {
+// NewHandler creates a new handler that logs requests and failures to logger
+func NewHandler(
+	linkService LinkService,
+	tagService TagService,
+	apiKeyService APIKeyService,
+	roleService RoleService,
+	namespaceService NamespaceService,
+	backupService BackupService,
+	favoriteService FavoriteService,
+	adminService AdminService,
+	sessionStore auth.SessionStore,
+	cfg *config.Config,
+	logger *slog.Logger,
+) *Handler {
 	// Load templates
 	templates := template.Must(template.New("").Funcs(template.FuncMap{
-		"urlify": func(url string) template.HTML {
-			if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
-				return template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, url, url))
-			}
-			return template.HTML(url)
-		},
-	}).ParseGlob("web/templates/*.html"))
+		"urlify": urlify(cfg.AllowedSchemes),
+		"icon":   service.IconGlyph,
+	}).ParseGlob(filepath.Join(cfg.WebDir, "templates", "*.html")))
+
+	h := &Handler{
+		linkService:   linkService,
+		tagService:    tagService,
+		apiKeyService: apiKeyService,
+		roleService:   roleService,
+		config:        cfg,
+		templates:     templates,
+		logger:        logger,
+
+		namespaceService: namespaceService,
+		backupService:    backupService,
+		favoriteService:  favoriteService,
+		adminService:     adminService,
+
+		responses: newResponseCounter(time.Now),
+		closing:   make(chan struct{}),
+	}
+	h.AddReadinessCheck("web", readableDirs(filepath.Join(cfg.WebDir, "templates"), filepath.Join(cfg.WebDir, "static")))
 
-	return &Handler{
-		linkService: linkService,
-		config:      cfg,
-		templates:   templates,
+	if cfg.GoogleClientID != "" || cfg.LDAPURL != "" {
+		h.sessions = auth.NewSessions(sessionStore, strings.HasPrefix(cfg.BaseURL, "https://"), logger)
 	}
+
+	switch {
+	case cfg.LDAPURL != "":
+		if cfg.GoogleClientID != "" {
+			h.logger.Warn("Both LDAP_URL and GOOGLE_CLIENT_ID are set; signing in with LDAP")
+		}
+		h.passwords = auth.NewLDAP(auth.LDAPConfig{
+			URL:           cfg.LDAPURL,
+			StartTLS:      cfg.LDAPStartTLS,
+			BindDN:        cfg.LDAPBindDN,
+			BindPassword:  cfg.LDAPBindPassword,
+			BaseDN:        cfg.LDAPBaseDN,
+			UserFilter:    cfg.LDAPUserFilter,
+			AllowedGroups: cfg.LDAPAllowedGroups,
+		})
+	case cfg.GoogleClientID != "":
+		h.oauth = auth.NewGoogle(cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.BaseURL+"/auth/callback", cfg.AllowedDomains)
+	}
+
+	return h
 }
 
 // RegisterRoutes registers all HTTP routes
 func (h *Handler) RegisterRoutes(router *mux.Router) {
+	if h.config.ResponseTimeHeader {
+		router.Use(ResponseTimeMiddleware)
+	}
+	router.Use(h.responses.middleware, h.APIKeyMiddleware, h.RequireLogin, h.CSRFMiddleware)
+
+	// Health probes
+	router.HandleFunc("/healthz", h.HealthzHandler).Methods("GET", "HEAD")
+	router.HandleFunc("/readyz", h.ReadyzHandler).Methods("GET", "HEAD")
+
 	// Static files
-	router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("web/static/"))))
+	router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir(filepath.Join(h.config.WebDir, "static")))))
 
 	// API routes
 	router.HandleFunc("/query/{path:.*}", h.RedirectHandler).Methods("GET")
-	router.HandleFunc("/update/", h.UpdateLinkHandler).Methods("POST")
+	router.HandleFunc("/search", h.SearchHandler).Methods("GET")
+	router.HandleFunc("/opensearch.xml", h.OpenSearchHandler).Methods("GET")
+	router.HandleFunc("/update/", h.requireRole(domain.RoleEditor, h.UpdateLinkHandler)).Methods("POST")
 	router.HandleFunc("/homepage/", h.HomepageHandler).Methods("GET")
+	router.HandleFunc("/homepage/keywords", h.KeywordTableHandler).Methods("GET")
 	router.HandleFunc("/setup/", h.SetupHandler).Methods("GET")
+	router.HandleFunc("/stats/"+wordRoute, h.StatsPageHandler).Methods("GET")
+	router.HandleFunc("/admin/", h.requireRole(domain.RoleAdmin, h.AdminPageHandler)).Methods("GET")
+	router.HandleFunc("/auth/login", h.LoginHandler).Methods("GET")
+	router.HandleFunc("/auth/login", h.PasswordLoginHandler).Methods("POST")
+	router.HandleFunc("/auth/callback", h.CallbackHandler).Methods("GET")
+	router.HandleFunc("/auth/logout", h.LogoutHandler).Methods("GET", "POST")
+	router.HandleFunc("/api/resolve/detail", h.ResolveDetailHandler).Methods("GET")
+	router.HandleFunc("/api/suggest", h.SuggestHandler).Methods("GET")
+	router.HandleFunc("/api/launcher/search", h.LauncherSearchHandler).Methods("GET")
+	router.HandleFunc("/api/launcher/links", h.requireRole(domain.RoleEditor, h.LauncherCreateHandler)).Methods("POST")
+	router.HandleFunc("/api/triggers/me", h.requireAPIKey(h.TriggerAuthHandler)).Methods("GET")
+	router.HandleFunc("/api/triggers/links/created", h.requireAPIKey(h.CreatedLinksTriggerHandler)).Methods("GET")
+	router.HandleFunc("/api/triggers/links/deleted", h.requireAPIKey(h.DeletedLinksTriggerHandler)).Methods("GET")
+	router.HandleFunc("/api/links/bulk", h.requireRole(domain.RoleEditor, h.BulkLinksHandler)).Methods("POST")
+	router.HandleFunc("/api/links/import", h.requireRole(domain.RoleEditor, h.ImportLinksHandler)).Methods("POST")
+	router.HandleFunc("/api/links/export", h.requireRole(domain.RoleAdmin, h.ExportLinksHandler)).Methods("GET")
+	router.HandleFunc("/api/links/"+wordRoute, h.requireRole(domain.RoleEditor, h.DeleteLinkHandler)).Methods("DELETE")
+	router.HandleFunc("/api/links/"+wordRoute+"/history", h.HistoryHandler).Methods("GET")
+	router.HandleFunc("/api/links/"+wordRoute+"/stats", h.LinkStatsHandler).Methods("GET")
+	router.HandleFunc("/api/links/"+wordRoute+"/rollback/{id:[0-9]+}", h.requireRole(domain.RoleEditor, h.RollbackHandler)).Methods("POST")
+	router.HandleFunc("/api/links/"+wordRoute+"/transfer", h.requireRole(domain.RoleEditor, h.TransferHandler)).Methods("POST")
+	router.HandleFunc("/api/links/"+wordRoute+"/archive", h.requireRole(domain.RoleEditor, h.ArchiveHandler)).Methods("POST")
+	router.HandleFunc("/api/links/"+wordRoute+"/unarchive", h.requireRole(domain.RoleEditor, h.UnarchiveHandler)).Methods("POST")
+	router.HandleFunc("/api/links/"+wordRoute+"/metadata", h.requireRole(domain.RoleEditor, h.MetadataHandler)).Methods("PUT")
+	router.HandleFunc("/api/links/"+wordRoute+"/rename", h.requireRole(domain.RoleEditor, h.RenameHandler)).Methods("POST")
+	router.HandleFunc("/api/links/"+wordRoute+"/aliases", h.ListAliasesHandler).Methods("GET")
+	router.HandleFunc("/api/links/"+wordRoute+"/aliases/"+aliasRoute, h.requireRole(domain.RoleEditor, h.AddAliasHandler)).Methods("PUT")
+	router.HandleFunc("/api/links/"+wordRoute+"/aliases/"+aliasRoute, h.requireRole(domain.RoleEditor, h.RemoveAliasHandler)).Methods("DELETE")
+	router.HandleFunc("/api/links/"+wordRoute+"/tags", h.GetTagsHandler).Methods("GET")
+	router.HandleFunc("/api/links/"+wordRoute+"/tags", h.requireRole(domain.RoleEditor, h.AddTagsHandler)).Methods("POST")
+	router.HandleFunc("/api/links/"+wordRoute+"/tags/{tag}", h.requireRole(domain.RoleEditor, h.RemoveTagHandler)).Methods("DELETE")
+	router.HandleFunc("/api/tags/{tag}", h.KeywordsByTagHandler).Methods("GET")
+	router.HandleFunc("/api/me/links", h.UserLinksHandler).Methods("GET")
+	router.HandleFunc("/api/me/favorites", h.ListFavoritesHandler).Methods("GET")
+	router.HandleFunc("/api/me/favorites/"+wordRoute, h.AddFavoriteHandler).Methods("PUT")
+	router.HandleFunc("/api/me/favorites/"+wordRoute, h.RemoveFavoriteHandler).Methods("DELETE")
+	router.HandleFunc("/api/stats/stream", h.StatsStreamHandler).Methods("GET")
+
+	// Admin API
+	router.HandleFunc("/api/admin/backups", h.requireRole(domain.RoleAdmin, h.ListBackupsHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/backup", h.requireRole(domain.RoleAdmin, h.CreateBackupHandler)).Methods("POST")
+	router.HandleFunc("/api/admin/restore", h.requireRole(domain.RoleAdmin, h.RestoreBackupHandler)).Methods("POST")
+	router.HandleFunc("/api/admin/users", h.requireRole(domain.RoleAdmin, h.AdminUsersHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/changes", h.requireRole(domain.RoleAdmin, h.AdminChangesHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/storage", h.requireRole(domain.RoleAdmin, h.StorageStatsHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/errors", h.requireRole(domain.RoleAdmin, h.ErrorRatesHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/trash", h.requireRole(domain.RoleAdmin, h.ListTrashHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/reports/stale", h.requireRole(domain.RoleAdmin, h.StaleLinksHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/reports/broken", h.requireRole(domain.RoleAdmin, h.BrokenLinksHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/cache", h.requireRole(domain.RoleAdmin, h.CacheStatsHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/queries/prune", h.requireRole(domain.RoleAdmin, h.PruneQueriesHandler)).Methods("POST")
+	router.HandleFunc("/api/admin/loglevel", h.requireRole(domain.RoleAdmin, h.GetLogLevelHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/loglevel", h.requireRole(domain.RoleAdmin, h.SetLogLevelHandler)).Methods("PUT")
+	router.HandleFunc("/api/admin/domains", h.requireRole(domain.RoleAdmin, h.GetDomainsHandler)).Methods("GET")
+	router.HandleFunc("/api/admin/domains", h.requireRole(domain.RoleAdmin, h.SetDomainsHandler)).Methods("PUT")
+	router.HandleFunc("/api/admin/trash/"+wordRoute+"/restore", h.requireRole(domain.RoleAdmin, h.RestoreTrashHandler)).Methods("POST")
+	router.HandleFunc("/api/admin/trash/"+wordRoute, h.requireRole(domain.RoleAdmin, h.PurgeTrashHandler)).Methods("DELETE")
+	router.HandleFunc("/api/sync/changes", h.requireRole(domain.RoleAdmin, h.SyncChangesHandler)).Methods("GET")
+
+	// Versioned JSON API
+	h.registerAPIv1(router.PathPrefix("/api/v1").Subrouter())
+
+	// GraphQL gateway
+	router.Handle("/graphql", h.GraphQLHandler()).Methods("GET", "POST")
+
+	// API documentation
+	router.HandleFunc("/api/version", h.VersionHandler).Methods("GET")
+	router.HandleFunc("/api/openapi.json", h.OpenAPIHandler(router)).Methods("GET")
+	router.HandleFunc("/api/docs", h.APIDocsHandler).Methods("GET")
 
 	// Root redirect to homepage
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -69,29 +285,163 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 
 // RedirectHandler handles golink redirects
 func (h *Handler) RedirectHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
 	vars := mux.Vars(r)
-	queryPath := vars["path"]
-	queryPath = strings.TrimSuffix(queryPath, "/")
+	queryPath := strings.TrimSuffix(queryWord(vars["path"]), "/")
+
+	var params url.Values
+	if h.config.QueryPassthrough {
+		params = r.URL.Query()
+	}
+	h.followQuery(w, r, queryPath, params)
+}
+
+// queryWord decodes a word taken from a redirect path. Words can't contain '%', so one
+// still percent-encoded, as some clients send non-ASCII words encoded twice, is decoded
+// again, and the word is normalized the way words are stored.
+func queryWord(path string) string {
+	if decoded, err := url.PathUnescape(path); err == nil && utf8.ValidString(decoded) {
+		path = decoded
+	}
+	return service.NormalizeWord(path)
+}
+
+// followQuery redirects to the target of a golink query, with params added to its query
+// string, or to the homepage offering to create the link if it is missing. Clients asking
+// for JSON get the resolution instead.
+func (h *Handler) followQuery(w http.ResponseWriter, r *http.Request, queryPath string, params url.Values) {
+	// Log the page the click came from and what sent it, for the link's stats
+	ctx := service.WithClient(service.WithReferrer(r.Context(), referrerHost(r)), clientType(r))
+	r = r.WithContext(ctx)
 
 	userID := h.getUserID(r)
 
-	targetURL, err := h.linkService.GetLink(ctx, queryPath, "")
+	w.Header().Add("Vary", "Accept")
+	if wantsJSON(r) {
+		h.resolveJSON(w, r, queryPath, userID, params)
+		return
+	}
+
+	resolution, err := h.linkService.ResolveDetail(ctx, queryPath, true, userID)
 	if err != nil {
 		if _, ok := err.(service.InvalidQueryError); ok {
-			// Redirect to homepage with missing query parameter
-			redirectURL := fmt.Sprintf("%s/homepage/?missing=%s", h.config.BaseURL, queryPath)
+			// Search for the query elsewhere, or offer to create the link on the homepage
+			redirectURL := fmt.Sprintf("%s/homepage/?missing=%s", h.config.BaseURL, url.QueryEscape(queryPath))
+			if h.config.FallbackSearchURL != "" {
+				redirectURL = strings.ReplaceAll(h.config.FallbackSearchURL, "{*}", url.QueryEscape(queryPath))
+			}
 			http.Redirect(w, r, redirectURL, http.StatusFound)
 			return
 		}
+		if loop, ok := err.(service.AliasLoopError); ok {
+			h.renderAliasLoop(w, queryPath, loop)
+			return
+		}
+		if archived, ok := err.(service.ArchivedError); ok {
+			h.renderArchived(w, queryPath, archived)
+			return
+		}
 
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("query word=%s user=%s response=%s", queryPath, userID, targetURL)
-	http.Redirect(w, r, targetURL, http.StatusFound)
+	resolution.URL = withQuery(resolution.URL, params)
+	targetURL := resolution.URL
+	h.logger.Info("query", "word", queryPath, "user", userID, "response", targetURL)
+
+	if !service.IsWebURL(targetURL) {
+		h.renderOpenApp(w, targetURL)
+		return
+	}
+	if !h.trustedDestination(targetURL) {
+		h.renderInterstitial(w, resolution)
+		return
+	}
+
+	http.Redirect(w, r, targetURL, h.redirectStatus())
+}
+
+// redirectStatus is the status golinks redirect to their targets with, 302 unless
+// REDIRECT_STATUS says otherwise
+func (h *Handler) redirectStatus() int {
+	if h.config.RedirectStatus == 0 {
+		return http.StatusFound
+	}
+	return h.config.RedirectStatus
+}
+
+// withQuery adds params to the query string of targetURL, replacing any of the target's own
+// parameters with the same names
+func withQuery(targetURL string, params url.Values) string {
+	if len(params) == 0 {
+		return targetURL
+	}
+	target, err := url.Parse(targetURL)
+	if err != nil {
+		return targetURL
+	}
+
+	// Keep the target's query string as written unless a parameter in it is replaced
+	query := target.Query()
+	replaced := false
+	for key := range params {
+		if query.Has(key) {
+			replaced = true
+		}
+		query[key] = params[key]
+	}
+	if replaced || target.RawQuery == "" {
+		target.RawQuery = query.Encode()
+	} else {
+		target.RawQuery += "&" + params.Encode()
+	}
+	return target.String()
+}
+
+// resolveJSON answers a redirect route with the resolution instead of a 302, for clients
+// that asked for JSON. params are added to the resolved URL as when redirecting.
+func (h *Handler) resolveJSON(w http.ResponseWriter, r *http.Request, queryPath, userID string, params url.Values) {
+	resolution, err := h.linkService.ResolveDetail(r.Context(), queryPath, true, userID)
+	if err != nil {
+		switch err.(type) {
+		case service.InvalidQueryError:
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		case service.AliasLoopError:
+			writeJSONError(w, http.StatusLoopDetected, err.Error())
+			return
+		case service.ArchivedError:
+			writeJSONError(w, http.StatusGone, err.Error())
+			return
+		}
+
+		h.logger.Error("Failed to resolve query", "query", queryPath, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	resolution.URL = withQuery(resolution.URL, params)
+	h.logger.Info("query", "word", queryPath, "user", userID, "response", resolution.URL)
+
+	writeJSON(w, http.StatusOK, resolution)
+}
+
+// renderOpenApp serves a page that hands a non-HTTP target (slack://, zoommtg://) to the
+// browser, since redirects to custom schemes are handled inconsistently across browsers
+func (h *Handler) renderOpenApp(w http.ResponseWriter, targetURL string) {
+	data := struct {
+		// Target has already passed the service's scheme allowlist
+		Target template.URL
+	}{
+		Target: template.URL(targetURL),
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := h.templates.ExecuteTemplate(w, "open.html", data); err != nil {
+		h.logger.Error("Failed to execute template", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
 }
 
 // UpdateLinkHandler handles link creation/updates
@@ -107,10 +457,12 @@ func (h *Handler) UpdateLinkHandler(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserID(r)
 
 	if err := h.linkService.UpdateLink(ctx, req, userID); err != nil {
-		if _, ok := err.(service.InvalidQueryError); ok {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{"detail": err.Error()})
+		switch err.(type) {
+		case service.InvalidQueryError:
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		case service.ForbiddenError:
+			writeJSONError(w, http.StatusForbidden, err.Error())
 			return
 		}
 
@@ -118,10 +470,157 @@ func (h *Handler) UpdateLinkHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("update word=%s user=%s link=%s", req.Word, userID, req.Link)
+	h.logger.Info("update", "word", req.Word, "user", userID, "link", req.Link)
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// BulkLinksHandler creates many links from a JSON array of link requests
+func (h *Handler) BulkLinksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var reqs []domain.LinkRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBulkBodyBytes)).Decode(&reqs); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid JSON: expected an array of links")
+		return
+	}
+
+	userID := h.getUserID(r)
+
+	results, err := h.linkService.BulkUpdateLinks(ctx, reqs, userID)
+	if err != nil {
+		if _, ok := err.(service.InvalidQueryError); ok {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.logger.Error("Failed to bulk create links", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	created := 0
+	for _, result := range results {
+		if result.Status == domain.BulkStatusCreated {
+			created++
+		}
+	}
+
+	h.logger.Info("bulk", "user", userID, "created", created, "failed", len(results)-created)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"created": created,
+		"failed":  len(results) - created,
+		"results": results,
+	})
+}
+
+// DeleteLinkHandler handles golink deletion, moving the link to the trash
+func (h *Handler) DeleteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	word := mux.Vars(r)["word"]
+	userID := h.getUserID(r)
+
+	if err := h.linkService.DeleteLink(ctx, word, userID); err != nil {
+		switch err.(type) {
+		case service.NotFoundError:
+			writeJSONError(w, http.StatusNotFound, err.Error())
+		case service.ForbiddenError:
+			writeJSONError(w, http.StatusForbidden, err.Error())
+		default:
+			h.logger.Error("Failed to delete link", "word", word, "err", err)
+			writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	h.logger.Info("delete", "word", word, "user", userID)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// HistoryHandler returns every revision of a golink
+func (h *Handler) HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	word := mux.Vars(r)["word"]
+
+	history, err := h.linkService.GetHistory(ctx, word, h.getUserID(r))
+	if err != nil {
+		if _, ok := err.(service.NotFoundError); ok {
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.logger.Error("Failed to get history", "word", word, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
+
+// RollbackHandler restores a previous revision of a golink
+func (h *Handler) RollbackHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	word := vars["word"]
+	revisionID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid revision id")
+		return
+	}
+
+	userID := h.getUserID(r)
+
+	shortcut, err := h.linkService.RollbackLink(ctx, word, revisionID, userID)
+	if err != nil {
+		switch err.(type) {
+		case service.NotFoundError:
+			writeJSONError(w, http.StatusNotFound, err.Error())
+		case service.InvalidQueryError:
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+		case service.ForbiddenError:
+			writeJSONError(w, http.StatusForbidden, err.Error())
+		default:
+			h.logger.Error("Failed to roll back", "word", word, "revision", revisionID, "err", err)
+			writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	h.logger.Info("rollback", "word", word, "user", userID, "revision", revisionID, "link", shortcut.Link)
+
+	writeJSON(w, http.StatusOK, shortcut)
+}
+
+// TransferHandler hands a golink over to another user
+func (h *Handler) TransferHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+
+	var req domain.TransferRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID := h.getUserID(r)
+
+	shortcut, err := h.linkService.TransferLink(r.Context(), word, req, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "transfer "+word)
+		return
+	}
+
+	h.logger.Info("transfer", "word", word, "user", userID, "owner", shortcut.User)
+
+	writeJSON(w, http.StatusOK, shortcut)
+}
+
+// SetWordRules shows people adding a keyword the rules it must follow
+func (h *Handler) SetWordRules(rules *service.WordRules) {
+	h.wordRules = rules
 }
 
 // HomepageHandler handles the homepage
@@ -136,51 +635,233 @@ func (h *Handler) HomepageHandler(w http.ResponseWriter, r *http.Request) {
 	reason := r.URL.Query().Get("reason")
 	missing := r.URL.Query().Get("missing")
 
+	// The popular queries window and size fall back to their defaults when malformed
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 1 || days > service.MaxPopularDays {
+		days = service.DefaultPopularDays
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 || limit > service.MaxPopularLimit {
+		limit = service.DefaultPopularLimit
+	}
+
 	// Get recent queries and keywords
-	recentQueries, err := h.linkService.GetRecentQueries(ctx)
+	recentQueries, err := h.linkService.GetRecentQueries(ctx, days, limit)
 	if err != nil {
-		log.Printf("Failed to get recent queries: %v", err)
+		h.logger.Error("Failed to get recent queries", "err", err)
 		recentQueries = []domain.PopularQuery{}
 	}
 
-	allKeywords, err := h.linkService.GetAllKeywords(ctx)
+	table, keywordPage, err := h.keywordTable(r, userID)
+	h.logger.Info("homepage", "user", userID)
+
+	w.Header().Add("Vary", "Accept")
+	if wantsJSON(r) {
+		if err != nil {
+			h.writeAPIError(w, err, "get keywords")
+			return
+		}
+		if keywordPage == nil {
+			keywordPage = &domain.KeywordPage{Keywords: table.AllKeywords, Total: table.Total}
+		}
+		writeJSON(w, http.StatusOK, homepageJSON{
+			KeywordPage:   keywordPage,
+			Tag:           table.Tag,
+			RecentQueries: recentQueries,
+		})
+		return
+	}
+
 	if err != nil {
-		log.Printf("Failed to get all keywords: %v", err)
-		allKeywords = []domain.KeywordInfo{}
+		h.logger.Error("Failed to get all keywords", "err", err)
+		table.AllKeywords = []domain.KeywordInfo{}
 	}
 
-	log.Printf("homepage user=%s", userID)
+	// Signed-in users also see the links they follow most
+	var yourQueries []domain.PopularQuery
+	if h.sessions != nil {
+		if links, err := h.linkService.GetUserLinks(ctx, userID); err != nil {
+			h.logger.Error("Failed to get links", "user", userID, "err", err)
+		} else {
+			yourQueries = links.MostUsed
+		}
+	}
 
 	data := struct {
+		keywordTable
 		Success       string
 		Failure       string
 		Reason        string
 		Missing       string
 		RecentQueries []domain.PopularQuery
-		AllKeywords   []domain.KeywordInfo
-		BaseURL       string
+		PopularDays   int
+		YourQueries   []domain.PopularQuery
+		User          string
+		SignedIn      bool
+		CanEdit       bool
+		WordRules     string
+		WordMaxLength int
+		CSRFToken     string
+		Build         buildInfo
 	}{
+		keywordTable:  table,
 		Success:       success,
 		Failure:       failure,
 		Reason:        reason,
 		Missing:       missing,
 		RecentQueries: recentQueries,
-		AllKeywords:   allKeywords,
-		BaseURL:       h.config.BaseURL,
+		PopularDays:   days,
+		YourQueries:   yourQueries,
+		User:          userID,
+		SignedIn:      h.sessions != nil,
+		CanEdit:       h.canEdit(r),
+		WordRules:     h.wordRules.Describe(),
+		CSRFToken:     h.csrfToken(w, r),
+		Build:         currentBuild(),
+	}
+
+	if h.wordRules != nil {
+		data.WordMaxLength = h.wordRules.MaxLength
 	}
 
 	w.Header().Set("Content-Type", "text/html")
 	if err := h.templates.ExecuteTemplate(w, "homepage.html", data); err != nil {
-		log.Printf("Failed to execute template: %v", err)
+		h.logger.Error("Failed to execute template", "err", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
+// KeywordTableHandler renders the homepage's keyword list on its own, taking the same page,
+// sort, q and tag parameters as the homepage, so the homepage can page, sort and search it
+// in place
+func (h *Handler) KeywordTableHandler(w http.ResponseWriter, r *http.Request) {
+	table, _, err := h.keywordTable(r, h.getUserID(r))
+	if err != nil {
+		if _, ok := err.(service.InvalidQueryError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to get all keywords", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := h.templates.ExecuteTemplate(w, "keyword-table", table); err != nil {
+		h.logger.Error("Failed to execute template", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// keywordTable is the homepage's keyword list: a page of keywords in Sort order, or every
+// keyword tagged Tag. Signed-in users can pin keywords from it; Favorites lists those
+// they have pinned and Pinned holds their words.
+type keywordTable struct {
+	AllKeywords []domain.KeywordInfo
+	Tag         string
+	Search      string
+	Sort        string
+	Total       int
+	Page        int
+	PrevPage    int
+	NextPage    int
+	BaseURL     string
+	ShowIcons   bool
+	CanPin      bool
+	Favorites   []domain.KeywordInfo
+	Pinned      map[string]bool
+}
+
+// Query returns the homepage query string showing page of the table sorted by sort, keeping
+// its search
+func (t keywordTable) Query(page int, sort string) string {
+	values := url.Values{}
+	if t.Search != "" {
+		values.Set("q", t.Search)
+	}
+	if sort != domain.KeywordSortNewest {
+		values.Set("sort", sort)
+	}
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+// keywordTable loads the keyword list picked by the page, sort, q and tag parameters of r
+// for userID, along with the page of keywords it came from unless it lists a tag. Malformed
+// pages and sorts fall back to the first page, newest first.
+func (h *Handler) keywordTable(r *http.Request, userID string) (keywordTable, *domain.KeywordPage, error) {
+	ctx := r.Context()
+	table := keywordTable{
+		Tag:       r.URL.Query().Get("tag"),
+		Search:    strings.TrimSpace(r.URL.Query().Get("q")),
+		Sort:      r.URL.Query().Get("sort"),
+		BaseURL:   h.config.BaseURL,
+		ShowIcons: h.config.LinkIcons,
+	}
+	switch table.Sort {
+	case domain.KeywordSortAlphabetical, domain.KeywordSortMostUsed:
+	default:
+		table.Sort = domain.KeywordSortNewest
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	table.Page = page
+
+	// Only signed-in users have favorites of their own
+	if h.sessions != nil {
+		table.CanPin = true
+		if favorites, err := h.favoriteService.ListFavorites(ctx, userID); err != nil {
+			h.logger.Error("Failed to get favorites", "user", userID, "err", err)
+		} else {
+			table.Favorites = favorites
+		}
+		table.Pinned = make(map[string]bool, len(table.Favorites))
+		for _, favorite := range table.Favorites {
+			table.Pinned[favorite.Word] = true
+		}
+	}
+
+	if table.Tag != "" {
+		keywords, err := h.tagService.GetKeywordsByTag(ctx, table.Tag, userID)
+		table.AllKeywords, table.Total = keywords, len(keywords)
+		return table, nil, err
+	}
+
+	keywordPage, err := h.linkService.ListKeywords(
+		ctx, table.Search, table.Sort, service.DefaultKeywordPageSize, (page-1)*service.DefaultKeywordPageSize, userID,
+	)
+	if err != nil {
+		return table, nil, err
+	}
+	table.AllKeywords, table.Total = keywordPage.Keywords, keywordPage.Total
+	if page > 1 {
+		table.PrevPage = page - 1
+	}
+	if keywordPage.Offset+len(keywordPage.Keywords) < keywordPage.Total {
+		table.NextPage = page + 1
+	}
+	return table, keywordPage, nil
+}
+
+// homepageJSON is the homepage as served to clients that ask for JSON
+type homepageJSON struct {
+	*domain.KeywordPage
+	Tag           string                `json:"tag,omitempty"`
+	RecentQueries []domain.PopularQuery `json:"recent_queries"`
+}
+
 // SetupHandler handles the setup page
 func (h *Handler) SetupHandler(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserID(r)
 
-	log.Printf("setup user=%s", userID)
+	h.logger.Info("setup", "user", userID)
 
 	data := struct {
 		BaseURL string
@@ -190,13 +871,102 @@ func (h *Handler) SetupHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/html")
 	if err := h.templates.ExecuteTemplate(w, "setup.html", data); err != nil {
-		log.Printf("Failed to execute template: %v", err)
+		h.logger.Error("Failed to execute template", "err", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
-// getUserID extracts user ID from request (simplified - no OAuth2 for now)
+// ResolveDetailHandler resolves a query and returns the target with resolution metadata
+func (h *Handler) ResolveDetailHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeJSONError(w, http.StatusBadRequest, "Missing query parameter q")
+		return
+	}
+
+	logQuery := false
+	if value := r.URL.Query().Get("log"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "Invalid value for log parameter")
+			return
+		}
+		logQuery = parsed
+	}
+
+	resolution, err := h.linkService.ResolveDetail(ctx, query, logQuery, h.getUserID(r))
+	if err != nil {
+		switch err.(type) {
+		case service.InvalidQueryError:
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		case service.AliasLoopError:
+			writeJSONError(w, http.StatusLoopDetected, err.Error())
+			return
+		case service.ArchivedError:
+			writeJSONError(w, http.StatusGone, err.Error())
+			return
+		}
+
+		h.logger.Error("Failed to resolve query", "query", query, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resolution)
+}
+
+// wantsJSON reports whether the Accept header prefers application/json to HTML, so routes
+// built for browsers can serve programmatic clients too. Ties, including */*, go to HTML.
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+
+	jsonQ, htmlQ := -1.0, -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case "application/json":
+			jsonQ = math.Max(jsonQ, q)
+		case "text/html", "*/*", "text/*":
+			htmlQ = math.Max(htmlQ, q)
+		}
+	}
+
+	return jsonQ > 0 && jsonQ > htmlQ
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError writes an error message in the {"detail": ...} shape used by the API
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"detail": message})
+}
+
+// getUserID returns the user a request acts as: the owner of its API key, the signed-in
+// user, or DefaultUser when no login provider is configured
 func (h *Handler) getUserID(r *http.Request) string {
-	// For now, return a default user. In production, this would extract from OAuth2 cookie
+	if userID, ok := h.authenticatedUser(r); ok {
+		return userID
+	}
+	// Without a login provider everyone shares one identity
 	return "DefaultUser"
 }
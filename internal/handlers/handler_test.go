@@ -4,11 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"html/template"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"golinks/internal/config"
 	"golinks/internal/domain"
@@ -24,9 +30,43 @@ type mockLinkService struct {
 	allKeywords   []domain.KeywordInfo
 	updateError   error
 	getError      error
+	deleteError   error
+
+	// trash holds the links removed by DeleteLink
+	trash map[string]string
+
+	// viewer is the user the last lookup was made for
+	viewer string
+
+	// sort is the order the last keyword page was asked for in
+	sort string
+
+	// popularDays and popularLimit record the last popular queries window asked for
+	popularDays  int
+	popularLimit int
+
+	// pruneError is returned by PruneQueries
+	pruneError error
+
+	// aliases holds the aliases of each link, added by AddAlias
+	aliases map[string][]string
+
+	// clicks is the channel SubscribeClicks hands out
+	clicks chan domain.ClickEvent
+
+	// imported and importPolicy record the last import
+	imported     []domain.ImportLink
+	importPolicy string
+
+	// exportRequest records what the last export asked for
+	exportRequest domain.ExportRequest
+
+	// changesSince records the cursor changes were last asked for since
+	changesSince int
 }
 
-func (m *mockLinkService) GetLink(ctx context.Context, word string, searchTerm string) (string, error) {
+func (m *mockLinkService) GetLink(ctx context.Context, word, searchTerm, userID string) (string, error) {
+	m.viewer = userID
 	if m.getError != nil {
 		return "", m.getError
 	}
@@ -44,14 +84,580 @@ func (m *mockLinkService) UpdateLink(ctx context.Context, req domain.LinkRequest
 	return nil
 }
 
-func (m *mockLinkService) GetRecentQueries(ctx context.Context) ([]domain.PopularQuery, error) {
+func (m *mockLinkService) GetRecentQueries(ctx context.Context, days, limit int) ([]domain.PopularQuery, error) {
+	if days < 0 || limit < 0 {
+		return nil, service.InvalidQueryError{Message: "bad window"}
+	}
+	m.popularDays, m.popularLimit = days, limit
 	return m.recentQueries, nil
 }
 
-func (m *mockLinkService) GetAllKeywords(ctx context.Context) ([]domain.KeywordInfo, error) {
+func (m *mockLinkService) GetAllKeywords(ctx context.Context, userID string) ([]domain.KeywordInfo, error) {
+	m.viewer = userID
 	return m.allKeywords, nil
 }
 
+func (m *mockLinkService) KeywordsETag(ctx context.Context, userID string) (string, error) {
+	if m.getError != nil {
+		return "", m.getError
+	}
+	return fmt.Sprintf(`"k%d-%s"`, len(m.allKeywords), userID), nil
+}
+
+func (m *mockLinkService) ListKeywords(
+	ctx context.Context, search, sort string, limit, offset int, userID string,
+) (*domain.KeywordPage, error) {
+	m.viewer = userID
+	m.sort = sort
+	if limit < 0 || offset < 0 {
+		return nil, service.InvalidQueryError{Message: "negative"}
+	}
+	if limit == 0 {
+		limit = service.DefaultKeywordPageSize
+	}
+	var matches []domain.KeywordInfo
+	for _, keyword := range m.allKeywords {
+		if strings.Contains(keyword.Word+" "+keyword.Link, search) {
+			matches = append(matches, keyword)
+		}
+	}
+	keywords := []domain.KeywordInfo{}
+	for i := offset; i < len(matches) && i < offset+limit; i++ {
+		keywords = append(keywords, matches[i])
+	}
+	return &domain.KeywordPage{Keywords: keywords, Query: search, Total: len(matches), Limit: limit, Offset: offset}, nil
+}
+
+func (m *mockLinkService) ResolveDetail(
+	ctx context.Context, query string, logQuery bool, userID string,
+) (*domain.Resolution, error) {
+	m.viewer = userID
+	if m.getError != nil {
+		return nil, m.getError
+	}
+	if link, exists := m.links[query]; exists {
+		return &domain.Resolution{Query: query, URL: link, Word: query, ResolvedWord: query}, nil
+	}
+	return nil, service.InvalidQueryError{Message: "not found"}
+}
+
+func (m *mockLinkService) DeleteLink(ctx context.Context, word string, userID string) error {
+	if m.deleteError != nil {
+		return m.deleteError
+	}
+	if _, exists := m.links[word]; !exists {
+		return service.NotFoundError{Message: "not found"}
+	}
+	if m.trash == nil {
+		m.trash = map[string]string{}
+	}
+	m.trash[word] = m.links[word]
+	delete(m.links, word)
+	return nil
+}
+
+func (m *mockLinkService) ListTrash(ctx context.Context) ([]domain.TrashedLink, error) {
+	trash := []domain.TrashedLink{}
+	for word, link := range m.trash {
+		trash = append(trash, domain.TrashedLink{Word: word, Link: link})
+	}
+	return trash, nil
+}
+
+func (m *mockLinkService) RestoreLink(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	if _, exists := m.links[word]; exists {
+		return nil, service.InvalidQueryError{Message: "already exists"}
+	}
+	link, exists := m.trash[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	m.links[word] = link
+	delete(m.trash, word)
+	return &domain.Shortcut{Word: word, Link: link}, nil
+}
+
+func (m *mockLinkService) PurgeLink(ctx context.Context, word string) error {
+	if _, exists := m.trash[word]; !exists {
+		return service.NotFoundError{Message: "not found"}
+	}
+	delete(m.trash, word)
+	return nil
+}
+
+func (m *mockLinkService) GetLinkStats(
+	ctx context.Context, word, interval string, days int, userID string,
+) (*domain.LinkStats, error) {
+	if interval != "" && interval != domain.IntervalDay && interval != domain.IntervalWeek {
+		return nil, service.InvalidQueryError{Message: "bad interval"}
+	}
+	if _, exists := m.links[word]; !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	if interval == "" {
+		interval = domain.IntervalDay
+	}
+	since := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return &domain.LinkStats{
+		Word:     word,
+		Interval: interval,
+		Since:    since,
+		Total:    6,
+		Clicks: []domain.ClickCount{
+			{Start: since, Count: 2},
+			{Start: since.AddDate(0, 0, 1), Count: 4},
+		},
+		Referrers: []domain.ReferrerCount{{Referrer: "wiki.example.com", Count: 3}},
+	}, nil
+}
+
+func (m *mockLinkService) GetUserLinks(ctx context.Context, userID string) (*domain.UserLinks, error) {
+	links := &domain.UserLinks{User: userID, MostUsed: []domain.PopularQuery{}, Created: []domain.KeywordInfo{}}
+	for word, link := range m.links {
+		links.MostUsed = append(links.MostUsed, domain.PopularQuery{Count: 1, Word: word, Link: link})
+	}
+	return links, nil
+}
+
+func (m *mockLinkService) StaleLinks(ctx context.Context, days int) (*domain.StaleLinkReport, error) {
+	if days < 0 {
+		return nil, service.InvalidQueryError{Message: "bad days"}
+	}
+	if days == 0 {
+		days = service.DefaultStaleDays
+	}
+	return &domain.StaleLinkReport{Days: days, Links: []domain.StaleLink{{Word: "github", Link: "https://github.com", User: "bob"}}}, nil
+}
+
+func (m *mockLinkService) PruneQueries(ctx context.Context) (*domain.QueryPrune, error) {
+	if m.pruneError != nil {
+		return nil, m.pruneError
+	}
+	return &domain.QueryPrune{Before: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Pruned: 3}, nil
+}
+
+func (m *mockLinkService) SubscribeClicks() (<-chan domain.ClickEvent, func()) {
+	return m.clicks, func() {}
+}
+
+func (m *mockLinkService) CacheStats() []domain.CacheStats {
+	return []domain.CacheStats{{Name: "links", Entries: 1, Capacity: 10, Hits: 2, Misses: 1}}
+}
+
+func (m *mockLinkService) ClickCounters() domain.ClickCounters {
+	return domain.ClickCounters{LastMinute: 1, LastHour: 2, Total: 3}
+}
+
+func (m *mockLinkService) GetShortcut(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	m.viewer = userID
+	if m.getError != nil {
+		return nil, m.getError
+	}
+	link, exists := m.links[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	return &domain.Shortcut{ID: 1, Word: word, Link: link, User: "DefaultUser"}, nil
+}
+
+func (m *mockLinkService) GetLinkDetail(ctx context.Context, word, userID string) (*domain.LinkDetail, error) {
+	shortcut, err := m.GetShortcut(ctx, word, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LinkDetail{
+		ID:        shortcut.ID,
+		Word:      shortcut.Word,
+		Link:      shortcut.Link,
+		User:      shortcut.User,
+		CreatedAt: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedBy: "alice",
+	}, nil
+}
+
+func (m *mockLinkService) GetHistory(ctx context.Context, word, userID string) ([]domain.Shortcut, error) {
+	m.viewer = userID
+	link, exists := m.links[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	return []domain.Shortcut{
+		{ID: 2, Word: word, Link: link, User: "DefaultUser", Metadata: map[string]interface{}{"team": "docs"}},
+		{ID: 1, Word: word, Link: link + "/old", User: "DefaultUser"},
+	}, nil
+}
+
+func (m *mockLinkService) RollbackLink(ctx context.Context, word string, revisionID int, userID string) (*domain.Shortcut, error) {
+	link, exists := m.links[word]
+	if !exists || revisionID != 1 {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	m.links[word] = link + "/old"
+	return &domain.Shortcut{ID: 3, Word: word, Link: m.links[word], User: userID}, nil
+}
+
+func (m *mockLinkService) TransferLink(
+	ctx context.Context, word string, req domain.TransferRequest, userID string,
+) (*domain.Shortcut, error) {
+	link, exists := m.links[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	if req.Owner == "" {
+		return nil, service.InvalidQueryError{Message: "owner required"}
+	}
+	return &domain.Shortcut{ID: 3, Word: word, Link: link, User: req.Owner, UpdatedBy: userID}, nil
+}
+
+func (m *mockLinkService) ArchiveLink(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	link, exists := m.links[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	return &domain.Shortcut{ID: 3, Word: word, Link: link, User: "alice", UpdatedBy: userID, Archived: true}, nil
+}
+
+func (m *mockLinkService) UnarchiveLink(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	link, exists := m.links[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	return nil, service.InvalidQueryError{Message: word + " is not archived: " + link}
+}
+
+func (m *mockLinkService) SetMetadata(
+	ctx context.Context, word string, metadata map[string]interface{}, userID string,
+) (*domain.Shortcut, error) {
+	link, exists := m.links[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	if _, blank := metadata[""]; blank {
+		return nil, service.InvalidQueryError{Message: "blank key"}
+	}
+	return &domain.Shortcut{ID: 3, Word: word, Link: link, User: "alice", UpdatedBy: userID, Metadata: metadata}, nil
+}
+
+func (m *mockLinkService) RenameLink(
+	ctx context.Context, word string, req domain.RenameRequest, userID string,
+) (*domain.Shortcut, error) {
+	link, exists := m.links[word]
+	if !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	if _, taken := m.links[req.Word]; taken || req.Word == "" {
+		return nil, service.InvalidQueryError{Message: "word taken"}
+	}
+	delete(m.links, word)
+	m.links[req.Word] = link
+	// vanished stands for a golink that can't be read back after the rename
+	if req.Word == "vanished" {
+		return nil, nil
+	}
+	return &domain.Shortcut{ID: 3, Word: req.Word, Link: link, User: userID, UpdatedBy: userID}, nil
+}
+
+func (m *mockLinkService) ListAliases(ctx context.Context, word, userID string) ([]string, error) {
+	if _, exists := m.links[word]; !exists {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	return append([]string{}, m.aliases[word]...), nil
+}
+
+func (m *mockLinkService) AddAlias(ctx context.Context, word, alias, userID string) error {
+	if _, exists := m.links[word]; !exists {
+		return service.NotFoundError{Message: "not found"}
+	}
+	if _, taken := m.links[alias]; taken {
+		return service.InvalidQueryError{Message: "alias taken"}
+	}
+	if m.aliases == nil {
+		m.aliases = map[string][]string{}
+	}
+	m.aliases[word] = append(m.aliases[word], alias)
+	return nil
+}
+
+func (m *mockLinkService) RemoveAlias(ctx context.Context, word, alias, userID string) error {
+	for i, existing := range m.aliases[word] {
+		if existing == alias {
+			m.aliases[word] = append(m.aliases[word][:i], m.aliases[word][i+1:]...)
+			return nil
+		}
+	}
+	return service.NotFoundError{Message: "not an alias"}
+}
+
+func (m *mockLinkService) BulkUpdateLinks(ctx context.Context, reqs []domain.LinkRequest, userID string) ([]domain.BulkLinkResult, error) {
+	if len(reqs) == 0 {
+		return nil, service.InvalidQueryError{Message: "No links given"}
+	}
+	results := make([]domain.BulkLinkResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = domain.BulkLinkResult{Index: i, Word: req.Word, Status: domain.BulkStatusCreated}
+		if req.Link == "" {
+			results[i].Status = domain.BulkStatusFailed
+			results[i].Error = "No link given"
+			continue
+		}
+		m.links[req.Word] = req.Link
+	}
+	return results, nil
+}
+
+func (m *mockLinkService) ImportLinks(
+	ctx context.Context, links []domain.ImportLink, policy, userID string, dryRun bool,
+) (*domain.ImportReport, error) {
+	if policy != domain.ImportSkip && policy != domain.ImportOverwrite {
+		return nil, service.InvalidQueryError{Message: "Unknown conflict policy"}
+	}
+	m.imported, m.importPolicy = links, policy
+	report := &domain.ImportReport{Results: make([]domain.BulkLinkResult, len(links)), DryRun: dryRun}
+	for i, link := range links {
+		report.Results[i] = domain.BulkLinkResult{Index: i, Word: link.Word, Status: domain.BulkStatusCreated}
+		if _, exists := m.links[link.Word]; exists {
+			report.Results[i].Status = domain.BulkStatusSkipped
+			report.Skipped++
+			continue
+		}
+		report.Created++
+	}
+	return report, nil
+}
+
+func (m *mockLinkService) ExportLinks(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error) {
+	m.exportRequest = req
+	export := &domain.LinkExport{ExportedAt: time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)}
+	for word, link := range m.links {
+		export.Links = append(export.Links, domain.ExportedLink{Word: word, Link: link, Tags: []string{}})
+	}
+	return export, nil
+}
+
+func (m *mockLinkService) SuggestLinks(ctx context.Context, query, userID string, limit int) (*domain.Suggestions, error) {
+	if limit < 0 {
+		return nil, service.InvalidQueryError{Message: "bad limit"}
+	}
+	m.viewer = userID
+	suggestions := &domain.Suggestions{Query: query, Suggestions: []domain.Suggestion{}}
+	fields := strings.Fields(query)
+	for word, link := range m.links {
+		if len(fields) > 0 && strings.HasPrefix(word, fields[0]) {
+			suggestions.Suggestions = append(suggestions.Suggestions, domain.Suggestion{Word: word, Link: link, Description: link})
+		}
+	}
+	return suggestions, nil
+}
+
+func (m *mockLinkService) CreatedLinks(ctx context.Context, userID, cursor string, limit int) ([]domain.LinkActivity, error) {
+	return m.activity(domain.LinkCreated, m.links, userID, cursor, limit)
+}
+
+func (m *mockLinkService) DeletedLinks(ctx context.Context, userID, cursor string, limit int) ([]domain.LinkActivity, error) {
+	return m.activity(domain.LinkDeleted, m.trash, userID, cursor, limit)
+}
+
+// activity lists links by word, each with its word as ID, the page after cursor
+func (m *mockLinkService) activity(action string, links map[string]string, userID, cursor string, limit int) ([]domain.LinkActivity, error) {
+	if limit < 0 {
+		return nil, service.InvalidQueryError{Message: "bad limit"}
+	}
+	m.viewer = userID
+	words := []string{}
+	for word := range links {
+		if word > cursor {
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	activity := []domain.LinkActivity{}
+	for _, word := range words {
+		activity = append(activity, domain.LinkActivity{ID: word, Action: action, Word: word, Link: links[word]})
+	}
+	return activity, nil
+}
+
+func (m *mockLinkService) GetChanges(ctx context.Context, since, limit int) (*domain.LinkChanges, error) {
+	if since < 0 || limit < 0 {
+		return nil, service.InvalidQueryError{Message: "bad page"}
+	}
+	m.changesSince = since
+	changes := &domain.LinkChanges{Versions: []domain.Shortcut{}, Cursor: since}
+	for word, link := range m.links {
+		changes.Cursor++
+		changes.Versions = append(changes.Versions, domain.Shortcut{ID: changes.Cursor, Word: word, Link: link})
+		changes.Words = append(changes.Words, word)
+	}
+	sort.Strings(changes.Words)
+	return changes, nil
+}
+
+// memoryShortcutRepository backs a real LinkService in handler tests
+type memoryShortcutRepository struct {
+	shortcuts map[string]*domain.Shortcut
+}
+
+func (m *memoryShortcutRepository) GetByWord(ctx context.Context, word string) (*domain.Shortcut, error) {
+	return m.shortcuts[word], nil
+}
+
+func (m *memoryShortcutRepository) Create(ctx context.Context, shortcut *domain.Shortcut) error {
+	shortcut.ID = len(m.shortcuts) + 1
+	m.shortcuts[shortcut.Word] = shortcut
+	return nil
+}
+
+func (m *memoryShortcutRepository) GetAllKeywords(ctx context.Context, viewer string) ([]domain.KeywordInfo, error) {
+	return nil, nil
+}
+
+func (m *memoryShortcutRepository) GetKeywordsByOwner(ctx context.Context, owner string) ([]domain.KeywordInfo, error) {
+	return nil, nil
+}
+
+func (m *memoryShortcutRepository) GetKeywordsPage(
+	ctx context.Context, targetPrefixes []string, search, sort, viewer string, limit, offset int,
+) ([]domain.KeywordInfo, int, error) {
+	return nil, 0, nil
+}
+
+func (m *memoryShortcutRepository) GetKeywordsByPrefix(
+	ctx context.Context, prefix, viewer string, limit int,
+) ([]domain.KeywordInfo, error) {
+	return nil, nil
+}
+
+func (m *memoryShortcutRepository) GetKeywordsVersion(ctx context.Context) (string, error) {
+	return fmt.Sprint(len(m.shortcuts)), nil
+}
+
+func (m *memoryShortcutRepository) DeleteByWord(ctx context.Context, word string) (int64, error) {
+	delete(m.shortcuts, word)
+	return 1, nil
+}
+
+func (m *memoryShortcutRepository) GetDeleted(ctx context.Context) ([]domain.TrashedLink, error) {
+	return nil, nil
+}
+
+func (m *memoryShortcutRepository) RestoreByWord(ctx context.Context, word string) (int64, error) {
+	return 0, nil
+}
+
+func (m *memoryShortcutRepository) PurgeByWord(ctx context.Context, word string) (int64, error) {
+	return 0, nil
+}
+
+func (m *memoryShortcutRepository) CreateBatch(ctx context.Context, shortcuts []*domain.Shortcut) error {
+	for _, shortcut := range shortcuts {
+		_ = m.Create(ctx, shortcut)
+	}
+	return nil
+}
+
+func (m *memoryShortcutRepository) GetByID(ctx context.Context, id int) (*domain.Shortcut, error) {
+	for _, shortcut := range m.shortcuts {
+		if shortcut.ID == id {
+			return shortcut, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryShortcutRepository) GetHistory(ctx context.Context, word string) ([]domain.Shortcut, error) {
+	if shortcut, exists := m.shortcuts[word]; exists {
+		return []domain.Shortcut{*shortcut}, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryShortcutRepository) GetAllVersions(ctx context.Context) ([]domain.Shortcut, error) {
+	var versions []domain.Shortcut
+	for _, shortcut := range m.shortcuts {
+		versions = append(versions, *shortcut)
+	}
+	return versions, nil
+}
+
+func (m *memoryShortcutRepository) GetVersionsSince(ctx context.Context, since, limit int) ([]domain.Shortcut, error) {
+	var versions []domain.Shortcut
+	for _, shortcut := range m.shortcuts {
+		if shortcut.ID > since {
+			versions = append(versions, *shortcut)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID < versions[j].ID })
+	if len(versions) > limit {
+		versions = versions[:limit]
+	}
+	return versions, nil
+}
+
+func (m *memoryShortcutRepository) GetCreated(ctx context.Context, viewer string, before, limit int) ([]domain.Shortcut, error) {
+	return nil, nil
+}
+
+func (m *memoryShortcutRepository) GetWords(ctx context.Context) ([]string, error) {
+	var words []string
+	for word := range m.shortcuts {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words, nil
+}
+
+// memoryQueryRepository records logged query word IDs, referrers and clients
+type memoryQueryRepository struct {
+	logged    []int
+	referrers []string
+	clients   []string
+}
+
+func (m *memoryQueryRepository) Create(ctx context.Context, query *domain.Query) error {
+	m.logged = append(m.logged, query.WordID)
+	m.referrers = append(m.referrers, query.Referrer)
+	m.clients = append(m.clients, query.Client)
+	return nil
+}
+
+func (m *memoryQueryRepository) CreateBatch(ctx context.Context, queries []*domain.Query) error {
+	for _, query := range queries {
+		if err := m.Create(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryQueryRepository) GetUserTopLinks(
+	ctx context.Context, user string, since time.Time, limit int,
+) ([]domain.PopularQuery, error) {
+	return nil, nil
+}
+
+func (m *memoryQueryRepository) GetStaleLinks(ctx context.Context, since time.Time) ([]domain.StaleLink, error) {
+	return nil, nil
+}
+
+func (m *memoryQueryRepository) PruneBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *memoryQueryRepository) GetRecentQueries(ctx context.Context, timeWindowDays, numResults int) ([]domain.PopularQuery, error) {
+	return nil, nil
+}
+
+func (m *memoryQueryRepository) GetDailyClicks(ctx context.Context, word string, since time.Time) ([]domain.ClickCount, error) {
+	return nil, nil
+}
+
+func (m *memoryQueryRepository) GetTopReferrers(
+	ctx context.Context, word string, since time.Time, limit int,
+) ([]domain.ReferrerCount, error) {
+	return nil, nil
+}
+
 func setupTestHandler() *Handler {
 	cfg := &config.Config{
 		BaseURL: "http://localhost:8080",
@@ -59,12 +665,7 @@ func setupTestHandler() *Handler {
 
 	// Create simple templates for testing
 	templates := template.Must(template.New("").Funcs(template.FuncMap{
-		"urlify": func(url string) template.HTML {
-			if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
-				return template.HTML(`<a href="` + url + `">` + url + `</a>`)
-			}
-			return template.HTML(url)
-		},
+		"urlify": urlify(nil),
 	}).Parse(`
 		{{define "homepage.html"}}
 		<html>
@@ -74,7 +675,63 @@ func setupTestHandler() *Handler {
 			{{if .Success}}<div>Success: {{.Success}}</div>{{end}}
 			{{if .Failure}}<div>Failure: {{.Failure}} - {{.Reason}}</div>{{end}}
 			<div>Recent Queries: {{len .RecentQueries}}</div>
-			<div>All Keywords: {{len .AllKeywords}}</div>
+			<div>Your Queries: {{len .YourQueries}}</div>
+			{{if .CanPin}}<div>Pinned: {{range .Favorites}}{{.Word}} {{end}}</div>{{end}}
+			<div>All Keywords: {{len .AllKeywords}} of {{.Total}}</div>
+			<div>Pages: {{.PrevPage}} {{.NextPage}}</div>
+			{{if .CanEdit}}<form id="linkForm" data-csrf="{{.CSRFToken}}"></form>{{end}}
+			{{if .WordRules}}<p>Rules: {{.WordRules}}</p>{{end}}
+		</body>
+		</html>
+		{{end}}
+		{{define "keyword-table"}}
+		<div id="keyword-table">Sort: {{.Sort}} Keywords: {{len .AllKeywords}} of {{.Total}} Pages: {{.PrevPage}} {{.NextPage}}</div>
+		{{end}}
+		{{define "open.html"}}
+		<html>
+		<body>
+			<a href="{{.Target}}">Open</a>
+		</body>
+		</html>
+		{{end}}
+		{{define "interstitial.html"}}
+		<html>
+		<body>
+			<p>{{.Word}} by {{.Owner}}</p>
+			<a href="{{.Target}}">Continue to {{.Host}}</a>
+		</body>
+		</html>
+		{{end}}
+		{{define "loop.html"}}
+		<html>
+		<body>
+			<p>{{.Message}}</p>
+			{{range .Chain}}<a href="{{$.BaseURL}}/homepage/?q={{.}}">{{.}}</a>{{end}}
+		</body>
+		</html>
+		{{end}}
+		{{define "archived.html"}}
+		<html>
+		<body>
+			<p>{{.Shortcut.Word}} was archived by {{.Shortcut.UpdatedBy}} and went to {{.Shortcut.Link}}</p>
+		</body>
+		</html>
+		{{end}}
+		{{define "swagger.html"}}
+		<html>
+		<body>
+			<div id="swagger-ui" data-url="{{.BaseURL}}/api/openapi.json"></div>
+		</body>
+		</html>
+		{{end}}
+		{{define "login.html"}}
+		<html>
+		<body>
+			{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+			<form method="post" action="{{.BaseURL}}/auth/login">
+				<input type="hidden" name="next" value="{{.Next}}">
+				<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+			</form>
 		</body>
 		</html>
 		{{end}}
@@ -86,6 +743,24 @@ func setupTestHandler() *Handler {
 		</body>
 		</html>
 		{{end}}
+		{{define "admin.html"}}
+		<html>
+		<body>
+			<p>Requests: {{.Errors.Total.Requests}}</p>
+			{{range .Users}}<p>{{.User}} ({{.Role}}): {{.Links}} links</p>{{end}}
+			{{range .Changes}}<p>Changed {{.Word}}</p>{{end}}
+			{{if .Storage}}<p>Storage: {{.Storage.Links}} links</p>{{end}}
+		</body>
+		</html>
+		{{end}}
+		{{define "stats.html"}}
+		<html>
+		<body>
+			<h1>{{.Stats.Word}}: {{.Stats.Total}} clicks</h1>
+			{{range .Buckets}}<p>{{.Start.Format "2006-01-02"}} {{.Count}} {{.Percent}}%</p>{{end}}
+		</body>
+		</html>
+		{{end}}
 	`))
 
 	mockService := &mockLinkService{
@@ -101,10 +776,27 @@ func setupTestHandler() *Handler {
 		},
 	}
 
+	mockTags := &mockTagService{
+		tags: map[string][]string{
+			"docs": {"engineering"},
+		},
+	}
+
 	handler := &Handler{
-		linkService: mockService,
-		config:      cfg,
-		templates:   templates,
+		linkService:   mockService,
+		tagService:    mockTags,
+		apiKeyService: newMockAPIKeyService(),
+		roleService:   newMockRoleService(),
+		config:        cfg,
+		templates:     templates,
+		logger:        slog.Default(),
+
+		namespaceService: newMockNamespaceService(),
+		backupService:    newMockBackupService(),
+		favoriteService:  newMockFavoriteService(),
+		adminService:     newMockAdminService(),
+
+		responses: newResponseCounter(time.Now),
 	}
 
 	return handler
@@ -170,6 +862,249 @@ func TestHandler_RedirectHandler(t *testing.T) {
 	}
 }
 
+func TestHandler_RedirectHandler_Status(t *testing.T) {
+	tests := []struct {
+		name           string
+		redirectStatus int
+		path           string
+		wantStatus     int
+	}{
+		{name: "default", path: "/query/docs", wantStatus: http.StatusFound},
+		{name: "permanent", redirectStatus: http.StatusMovedPermanently, path: "/query/docs", wantStatus: http.StatusMovedPermanently},
+		{name: "temporary keeping the method", redirectStatus: http.StatusTemporaryRedirect, path: "/query/docs", wantStatus: http.StatusTemporaryRedirect},
+		{name: "permanent keeping the method", redirectStatus: http.StatusPermanentRedirect, path: "/query/docs", wantStatus: http.StatusPermanentRedirect},
+		{name: "missing link stays temporary", redirectStatus: http.StatusMovedPermanently, path: "/query/nonexistent", wantStatus: http.StatusFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.config.RedirectStatus = tt.redirectStatus
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_RedirectHandler_FallbackSearch(t *testing.T) {
+	handler := setupTestHandler()
+	handler.config.FallbackSearchURL = "https://wiki.example.com/search?q={*}&src=golinks"
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		path         string
+		wantLocation string
+	}{
+		{path: "/query/docs", wantLocation: "https://docs.example.com"},
+		{path: "/query/payroll/2024", wantLocation: "https://wiki.example.com/search?q=payroll%2F2024&src=golinks"},
+		{path: "/search?q=vacation+policy", wantLocation: "https://wiki.example.com/search?q=vacation+policy&src=golinks"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound {
+			t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, http.StatusFound)
+		}
+		if location := w.Header().Get("Location"); location != tt.wantLocation {
+			t.Errorf("GET %s Location = %q, want %q", tt.path, location, tt.wantLocation)
+		}
+	}
+}
+
+func TestHandler_RedirectHandler_AliasLoop(t *testing.T) {
+	handler := setupTestHandler()
+	handler.linkService.(*mockLinkService).getError = service.AliasLoopError{
+		Message: "The aliases of ping loop back through ping",
+		Chain:   []string{"ping", "pong", "ping"},
+	}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name     string
+		accept   string
+		wantBody []string
+	}{
+		{
+			name: "page naming the chain",
+			wantBody: []string{
+				"The aliases of ping loop back through ping",
+				`<a href="http://localhost:8080/homepage/?q=pong">pong</a>`,
+			},
+		},
+		{name: "json", accept: "application/json", wantBody: []string{`"detail":"The aliases of ping loop back through ping"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/query/ping", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusLoopDetected {
+				t.Errorf("GET /query/ping status = %d, want %d", w.Code, http.StatusLoopDetected)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("GET /query/ping body = %q, want it to contain %q", w.Body.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_RedirectHandler_UnicodeWords(t *testing.T) {
+	handler := setupTestHandler()
+	handler.linkService.(*mockLinkService).links["\u00e9quipe"] = "https://team.example.com"
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	for _, path := range []string{
+		"/query/%C3%A9quipe",
+		"/query/%25C3%25A9quipe",
+		"/query/e%CC%81quipe",
+		"/query/\u00e9quipe/",
+	} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if location := w.Header().Get("Location"); location != "https://team.example.com" {
+			t.Errorf("GET %s Location = %q, want https://team.example.com", path, location)
+		}
+	}
+}
+
+func TestWithQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		params url.Values
+		want   string
+	}{
+		{name: "no params", target: "https://example.com/d?b=1&a=2", want: "https://example.com/d?b=1&a=2"},
+		{name: "target without query", target: "https://example.com/d", params: url.Values{"env": {"prod"}}, want: "https://example.com/d?env=prod"},
+		{name: "appended as written", target: "https://example.com/d?orgId=1&b=x+y", params: url.Values{"env": {"prod"}}, want: "https://example.com/d?orgId=1&b=x+y&env=prod"},
+		{name: "replaces target parameters", target: "https://example.com/d?orgId=1", params: url.Values{"orgId": {"2"}}, want: "https://example.com/d?orgId=2"},
+		{name: "keeps fragment", target: "https://example.com/d#panel", params: url.Values{"env": {"prod"}}, want: "https://example.com/d?env=prod#panel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withQuery(tt.target, tt.params); got != tt.want {
+				t.Errorf("withQuery(%q, %v) = %q, want %q", tt.target, tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_RedirectHandler_QueryPassthrough(t *testing.T) {
+	tests := []struct {
+		name         string
+		passthrough  bool
+		path         string
+		accept       string
+		wantLocation string
+		wantURL      string
+	}{
+		{name: "dropped by default", path: "/query/docs?env=prod", wantLocation: "https://docs.example.com"},
+		{name: "passed through", passthrough: true, path: "/query/docs?env=prod", wantLocation: "https://docs.example.com?env=prod"},
+		{name: "passed through to JSON", passthrough: true, path: "/query/docs?env=prod", accept: "application/json", wantURL: "https://docs.example.com?env=prod"},
+		{name: "search query is not passed through", passthrough: true, path: "/search?q=docs", wantLocation: "https://docs.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.config.QueryPassthrough = tt.passthrough
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if location := w.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("GET %s Location = %q, want %q", tt.path, location, tt.wantLocation)
+			}
+			if tt.wantURL != "" {
+				var resolution domain.Resolution
+				if err := json.Unmarshal(w.Body.Bytes(), &resolution); err != nil {
+					t.Fatalf("Failed to decode %s: %v", w.Body.String(), err)
+				}
+				if resolution.URL != tt.wantURL {
+					t.Errorf("GET %s url = %q, want %q", tt.path, resolution.URL, tt.wantURL)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_RedirectHandler_NonWebScheme(t *testing.T) {
+	handler := setupTestHandler()
+	mockService := handler.linkService.(*mockLinkService)
+	mockService.links["chat"] = "slack://channel?id=C123"
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest("GET", "/query/chat", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("RedirectHandler() status = %v, want %v", w.Code, http.StatusOK)
+	}
+	if location := w.Header().Get("Location"); location != "" {
+		t.Errorf("RedirectHandler() should not redirect to custom schemes, got Location %v", location)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `href="slack://channel?id=C123"`) {
+		t.Errorf("RedirectHandler() body should link to the app, got %q", body)
+	}
+}
+
+func TestHandler_LookupsUseRequester(t *testing.T) {
+	handler := setupTestHandler()
+	mockService := handler.linkService.(*mockLinkService)
+	mockService.links["docs"] = "https://docs.example.com"
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	// Private links only resolve and list for their owner, so lookups are made as the requester
+	for _, path := range []string{"/query/docs", "/homepage/", "/api/v1/links/docs", "/api/resolve/detail?q=docs", "/api/links/docs/history"} {
+		t.Run(path, func(t *testing.T) {
+			mockService.viewer = ""
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set("Authorization", "Bearer glk_alice")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if mockService.viewer != "alice" {
+				t.Errorf("%s looked up links as %q, want alice", path, mockService.viewer)
+			}
+		})
+	}
+}
+
 func TestHandler_UpdateLinkHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -199,6 +1134,15 @@ func TestHandler_UpdateLinkHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			setupError:     service.InvalidQueryError{Message: "test error"},
 		},
+		{
+			name: "word owned by another user",
+			requestBody: domain.LinkRequest{
+				Word: "docs",
+				Link: "https://other.com",
+			},
+			expectedStatus: http.StatusForbidden,
+			setupError:     service.ForbiddenError{Message: "docs is owned by alice"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -260,7 +1204,7 @@ func TestHandler_HomepageHandler(t *testing.T) {
 			name:           "basic homepage",
 			queryParams:    "",
 			expectedStatus: http.StatusOK,
-			expectedBody:   []string{"<h1>GoLinks</h1>", "Recent Queries: 1", "All Keywords: 1"},
+			expectedBody:   []string{"<h1>GoLinks</h1>", "Recent Queries: 1", "All Keywords: 1 of 1", "Pages: 0 0"},
 		},
 		{
 			name:           "homepage with success message",
@@ -280,29 +1224,252 @@ func TestHandler_HomepageHandler(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody:   []string{"Missing: nonexistent"},
 		},
+		{
+			name:           "homepage filtered by unused tag",
+			queryParams:    "?tag=unused",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"All Keywords: 0"},
+		},
+		{
+			name:           "homepage past the last page",
+			queryParams:    "?page=3",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"All Keywords: 0 of 1", "Pages: 2 0"},
+		},
+		{
+			name:           "homepage search",
+			queryParams:    "?q=nomatch",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"All Keywords: 0 of 0"},
+		},
+		{
+			name:           "homepage with invalid page",
+			queryParams:    "?page=abc",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"All Keywords: 1 of 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/homepage/"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			handler.HomepageHandler(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("HomepageHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+
+			body := w.Body.String()
+			for _, expected := range tt.expectedBody {
+				if !strings.Contains(body, expected) {
+					t.Errorf("HomepageHandler() body should contain %q, got %q", expected, body)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_HomepageHandler_Pagination(t *testing.T) {
+	handler := setupTestHandler()
+	mockService := handler.linkService.(*mockLinkService)
+	mockService.allKeywords = nil
+	for i := 0; i < service.DefaultKeywordPageSize*2+1; i++ {
+		mockService.allKeywords = append(mockService.allKeywords, domain.KeywordInfo{Word: strconv.Itoa(i)})
+	}
+
+	tests := []struct {
+		page     string
+		expected string
+	}{
+		{"1", "Pages: 0 2"},
+		{"2", "Pages: 1 3"},
+		{"3", "Pages: 2 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run("page "+tt.page, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/homepage/?page="+tt.page, nil)
+			w := httptest.NewRecorder()
+
+			handler.HomepageHandler(w, req)
+
+			if body := w.Body.String(); !strings.Contains(body, tt.expected) {
+				t.Errorf("HomepageHandler() body should contain %q, got %q", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestHandler_KeywordTableHandler(t *testing.T) {
+	handler := setupTestHandler()
+	mockService := handler.linkService.(*mockLinkService)
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantSort string
+		wantBody string
+	}{
+		{"defaults", "", domain.KeywordSortNewest, "Keywords: 1 of 1 Pages: 0 0"},
+		{"sorted search", "?sort=alphabetical&q=nomatch", domain.KeywordSortAlphabetical, "Keywords: 0 of 0"},
+		{"most used", "?sort=most_used&page=2", domain.KeywordSortMostUsed, "Keywords: 0 of 1 Pages: 1 0"},
+		{"unknown sort", "?sort=oldest", domain.KeywordSortNewest, "Keywords: 1 of 1"},
+		{"tag", "?tag=unused", "", "Keywords: 0 of 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.sort = ""
+			req := httptest.NewRequest("GET", "/homepage/keywords"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			body := w.Body.String()
+			if w.Code != http.StatusOK || !strings.Contains(body, tt.wantBody) || strings.Contains(body, "<html>") {
+				t.Errorf("GET /homepage/keywords%s = %d %q, want the table alone with %q", tt.query, w.Code, body, tt.wantBody)
+			}
+			if mockService.sort != tt.wantSort {
+				t.Errorf("GET /homepage/keywords%s listed keywords by %q, want %q", tt.query, mockService.sort, tt.wantSort)
+			}
+		})
+	}
+}
+
+func TestKeywordTable_Query(t *testing.T) {
+	tests := []struct {
+		search string
+		page   int
+		sort   string
+		want   string
+	}{
+		{"", 1, domain.KeywordSortNewest, ""},
+		{"", 2, domain.KeywordSortNewest, "?page=2"},
+		{"", 1, domain.KeywordSortMostUsed, "?sort=most_used"},
+		{"a&b", 3, domain.KeywordSortAlphabetical, "?page=3&q=a%26b&sort=alphabetical"},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest("GET", "/homepage/"+tt.queryParams, nil)
-			w := httptest.NewRecorder()
+		table := keywordTable{Search: tt.search}
+		if got := table.Query(tt.page, tt.sort); got != tt.want {
+			t.Errorf("keywordTable{Search: %q}.Query(%d, %q) = %q, want %q", tt.search, tt.page, tt.sort, got, tt.want)
+		}
+	}
+}
 
-			handler.HomepageHandler(w, req)
+func TestWantsJSON(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", false},
+		{"*/*", false},
+		{"application/json, */*;q=0.5", true},
+		{"text/html;q=0.5, application/json", true},
+		{"application/json;q=0.5, text/html", false},
+		{"application/json;q=0", false},
+		{"application/json;q=bogus", false},
+	}
 
-			if w.Code != tt.expectedStatus {
-				t.Errorf("HomepageHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
 			}
-
-			body := w.Body.String()
-			for _, expected := range tt.expectedBody {
-				if !strings.Contains(body, expected) {
-					t.Errorf("HomepageHandler() body should contain %q, got %q", expected, body)
-				}
+			if got := wantsJSON(req); got != tt.want {
+				t.Errorf("wantsJSON(%q) = %v, want %v", tt.accept, got, tt.want)
 			}
 		})
 	}
 }
 
+func TestHandler_ContentNegotiation(t *testing.T) {
+	handler := setupTestHandler()
+	handler.linkService.(*mockLinkService).allKeywords = []domain.KeywordInfo{{Word: "docs"}, {Word: "wiki"}}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/query/{path:.*}", handler.RedirectHandler).Methods("GET")
+	router.HandleFunc("/homepage/", handler.HomepageHandler).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("resolved query", func(t *testing.T) {
+		w := get("/query/docs")
+		if w.Code != http.StatusOK || w.Header().Get("Location") != "" {
+			t.Fatalf("RedirectHandler() status = %d, Location = %q, want 200 without redirect", w.Code, w.Header().Get("Location"))
+		}
+		if !strings.Contains(w.Header().Get("Vary"), "Accept") {
+			t.Errorf("RedirectHandler() Vary = %q, want Accept", w.Header().Get("Vary"))
+		}
+		var resolution domain.Resolution
+		if err := json.NewDecoder(w.Body).Decode(&resolution); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resolution.URL != "https://docs.example.com" || resolution.Word != "docs" {
+			t.Errorf("RedirectHandler() = %+v", resolution)
+		}
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		w := get("/query/nonexistent")
+		if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), `"detail"`) {
+			t.Errorf("RedirectHandler() status = %d, body = %s, want 404 JSON error", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("homepage", func(t *testing.T) {
+		w := get("/homepage/?q=wiki")
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			t.Fatalf("HomepageHandler() status = %d, Content-Type = %q", w.Code, w.Header().Get("Content-Type"))
+		}
+		var page struct {
+			Keywords      []domain.KeywordInfo  `json:"keywords"`
+			Total         int                   `json:"total"`
+			Query         string                `json:"query"`
+			RecentQueries []domain.PopularQuery `json:"recent_queries"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if page.Total != 1 || len(page.Keywords) != 1 || page.Keywords[0].Word != "wiki" || page.Query != "wiki" {
+			t.Errorf("HomepageHandler() = %+v", page)
+		}
+		if page.RecentQueries == nil {
+			t.Error("HomepageHandler() recent_queries missing")
+		}
+	})
+
+	t.Run("homepage by tag", func(t *testing.T) {
+		w := get("/homepage/?tag=docs")
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"tag":"docs"`) {
+			t.Errorf("HomepageHandler() status = %d, body = %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("browsers still get HTML", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/query/docs", nil)
+		req.Header.Set("Accept", "text/html,*/*;q=0.8")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusFound {
+			t.Errorf("RedirectHandler() status = %d, want %d", w.Code, http.StatusFound)
+		}
+	})
+}
+
 func TestHandler_SetupHandler(t *testing.T) {
 	handler := setupTestHandler()
 
@@ -394,3 +1561,395 @@ func TestHandler_MethodNotAllowed(t *testing.T) {
 		t.Errorf("Wrong method should return %v, got %v", http.StatusMethodNotAllowed, w.Code)
 	}
 }
+
+func TestHandler_HomepageHandler_PopularWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantDays  int
+		wantLimit int
+	}{
+		{"defaults", "", service.DefaultPopularDays, service.DefaultPopularLimit},
+		{"this month", "?days=30&limit=10", 30, 10},
+		{"malformed values fall back", "?days=week&limit=lots", service.DefaultPopularDays, service.DefaultPopularLimit},
+		{"out of range values fall back", "?days=9999&limit=0", service.DefaultPopularDays, service.DefaultPopularLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			req := httptest.NewRequest("GET", "/homepage/"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.HomepageHandler(w, req)
+
+			mock := handler.linkService.(*mockLinkService)
+			if w.Code != http.StatusOK || mock.popularDays != tt.wantDays || mock.popularLimit != tt.wantLimit {
+				t.Errorf("GET /homepage/%s = %d asking for %d days, %d results, want %d, %d",
+					tt.query, w.Code, mock.popularDays, mock.popularLimit, tt.wantDays, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestHandler_HomepageHandler_WordRules(t *testing.T) {
+	rules, err := service.NewWordRules(2, 20, "-", "", "")
+	if err != nil {
+		t.Fatalf("NewWordRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		rules *service.WordRules
+		want  string
+	}{
+		{"no rules", nil, ""},
+		{"rules", rules, "Rules: Keywords are 2 to 20 characters long."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.SetWordRules(tt.rules)
+			req := httptest.NewRequest("GET", "/homepage/", nil)
+			w := httptest.NewRecorder()
+			handler.HomepageHandler(w, req)
+
+			body := w.Body.String()
+			if tt.want == "" && strings.Contains(body, "Rules:") {
+				t.Errorf("homepage shows rules without any set: %s", body)
+			}
+			if tt.want != "" && !strings.Contains(body, tt.want) {
+				t.Errorf("homepage = %s, want it to contain %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_ResolveDetailHandler(t *testing.T) {
+	shortcutRepo := &memoryShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"search": {ID: 1, Word: "search", Link: "https://google.com/search?q={*}", User: "alice"},
+		"docs":   {ID: 2, Word: "docs", Link: "https://docs.example.com", User: "bob"},
+		"d":      {ID: 3, Word: "d", Link: "docs", User: "carol"},
+	}}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantLogged int
+		want       domain.Resolution
+	}{
+		{
+			name:       "substitution",
+			query:      "q=search+golang",
+			wantStatus: http.StatusOK,
+			want: domain.Resolution{
+				Query:        "search golang",
+				URL:          "https://google.com/search?q=golang",
+				Word:         "search",
+				SearchTerm:   "golang",
+				ResolvedWord: "search",
+				Substituted:  true,
+				Hops:         0,
+				Owner:        "alice",
+			},
+		},
+		{
+			name:       "alias with logging",
+			query:      "q=d&log=true",
+			wantStatus: http.StatusOK,
+			wantLogged: 2,
+			want: domain.Resolution{
+				Query:        "d",
+				URL:          "https://docs.example.com",
+				Word:         "d",
+				ResolvedWord: "docs",
+				Substituted:  false,
+				Hops:         1,
+				Owner:        "carol",
+			},
+		},
+		{
+			name:       "unknown query",
+			query:      "q=nonexistent",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing query",
+			query:      "",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid log flag",
+			query:      "q=docs&log=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryRepo := &memoryQueryRepository{}
+			handler := setupTestHandler()
+			handler.linkService = service.NewLinkService(shortcutRepo, queryRepo)
+
+			req := httptest.NewRequest("GET", "/api/resolve/detail?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ResolveDetailHandler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ResolveDetailHandler() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if len(queryRepo.logged) != tt.wantLogged {
+				t.Errorf("ResolveDetailHandler() logged %d queries, want %d", len(queryRepo.logged), tt.wantLogged)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got domain.Resolution
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveDetailHandler() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteLinkHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setupError     error
+		expectedStatus int
+	}{
+		{
+			name:           "successful delete",
+			path:           "/api/links/docs",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing word",
+			path:           "/api/links/nonexistent",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "not the owner",
+			path:           "/api/links/docs",
+			setupError:     service.ForbiddenError{Message: "forbidden"},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			mockService := handler.linkService.(*mockLinkService)
+			mockService.deleteError = tt.setupError
+
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("DELETE", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("DeleteLinkHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if w.Code == http.StatusOK {
+				if _, exists := mockService.links["docs"]; exists {
+					t.Error("DeleteLinkHandler() did not delete the link")
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_HistoryHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"existing word", "/api/links/docs/history", http.StatusOK, 2},
+		{"missing word", "/api/links/nonexistent/history", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("HistoryHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var history []domain.Shortcut
+			if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(history) != tt.expectedCount {
+				t.Errorf("HistoryHandler() returned %d revisions, want %d", len(history), tt.expectedCount)
+			}
+		})
+	}
+}
+
+func TestHandler_RollbackHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedLink   string
+	}{
+		{"valid revision", "/api/links/docs/rollback/1", http.StatusOK, "https://docs.example.com/old"},
+		{"unknown revision", "/api/links/docs/rollback/99", http.StatusNotFound, ""},
+		{"non-numeric revision", "/api/links/docs/rollback/abc", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("RollbackHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var shortcut domain.Shortcut
+			if err := json.NewDecoder(w.Body).Decode(&shortcut); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if shortcut.Link != tt.expectedLink {
+				t.Errorf("RollbackHandler() link = %v, want %v", shortcut.Link, tt.expectedLink)
+			}
+		})
+	}
+}
+
+func TestHandler_TransferHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{"transfer", "/api/links/docs/transfer", "application/json", `{"owner": "bob"}`, http.StatusOK},
+		{"no owner", "/api/links/docs/transfer", "application/json", `{}`, http.StatusBadRequest},
+		{"unknown field", "/api/links/docs/transfer", "application/json", `{"owner": "bob", "force": true}`, http.StatusBadRequest},
+		{"not json", "/api/links/docs/transfer", "text/plain", `owner=bob`, http.StatusUnsupportedMediaType},
+		{"missing link", "/api/links/missing/transfer", "application/json", `{"owner": "bob"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("TransferHandler() status = %v, want %v, body = %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var shortcut domain.Shortcut
+			if err := json.NewDecoder(w.Body).Decode(&shortcut); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if shortcut.User != "bob" || shortcut.UpdatedBy != "DefaultUser" {
+				t.Errorf("TransferHandler() = %+v, want docs handed to bob by DefaultUser", shortcut)
+			}
+		})
+	}
+}
+
+func TestHandler_BulkLinksHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCreate int
+		expectedFailed int
+	}{
+		{
+			name:           "mixed batch",
+			body:           `[{"word": "a", "link": "https://a.com"}, {"word": "b", "link": ""}]`,
+			expectedStatus: http.StatusOK,
+			expectedCreate: 1,
+			expectedFailed: 1,
+		},
+		{
+			name:           "not an array",
+			body:           `{"word": "a", "link": "https://a.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "empty array",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/api/links/bulk", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("BulkLinksHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Created int                     `json:"created"`
+				Failed  int                     `json:"failed"`
+				Results []domain.BulkLinkResult `json:"results"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Created != tt.expectedCreate || response.Failed != tt.expectedFailed {
+				t.Errorf("BulkLinksHandler() created=%d failed=%d, want %d/%d",
+					response.Created, response.Failed, tt.expectedCreate, tt.expectedFailed)
+			}
+			if len(response.Results) != tt.expectedCreate+tt.expectedFailed {
+				t.Errorf("BulkLinksHandler() returned %d results", len(response.Results))
+			}
+		})
+	}
+}
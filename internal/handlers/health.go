@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"time"
+)
+
+// Version is the build version reported by the health endpoints. Release builds set it
+// with -ldflags "-X golinks/internal/handlers.Version=v1.2.3"; other builds report the
+// VCS revision Go recorded in the binary, if any.
+var Version = ""
+
+// Commit and BuildDate are the git commit and time of the build, set the same way as
+// Version. Without them the commit Go recorded in the binary is reported, and no date.
+var (
+	Commit    = ""
+	BuildDate = ""
+)
+
+// readinessTimeout bounds each readiness check, so a hung dependency fails the probe
+// rather than stalling it
+const readinessTimeout = 2 * time.Second
+
+// HealthCheck reports whether a dependency the server needs is usable
+type HealthCheck func(ctx context.Context) error
+
+// healthStatus is the body of the liveness and readiness endpoints
+type healthStatus struct {
+	Status  string            `json:"status"`
+	Version string            `json:"version"`
+	Checks  map[string]string `json:"checks,omitempty"`
+}
+
+// buildInfo is the body of the version endpoint
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// AddReadinessCheck makes /readyz fail while check does
+func (h *Handler) AddReadinessCheck(name string, check HealthCheck) {
+	if h.readiness == nil {
+		h.readiness = map[string]HealthCheck{}
+	}
+	h.readiness[name] = check
+}
+
+// HealthzHandler reports that the process is up and serving, without checking its
+// dependencies, for liveness probes
+func (h *Handler) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, healthStatus{Status: "ok", Version: buildVersion()})
+}
+
+// ReadyzHandler runs every readiness check and reports whether the server can take
+// traffic, for readiness probes and load balancers
+func (h *Handler) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(h.readiness))
+	for name := range h.readiness {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	status := healthStatus{Status: "ok", Version: buildVersion(), Checks: map[string]string{}}
+	code := http.StatusOK
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		err := h.readiness[name](ctx)
+		cancel()
+
+		if err != nil {
+			status.Checks[name] = err.Error()
+			status.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		status.Checks[name] = "ok"
+	}
+
+	writeJSON(w, code, status)
+}
+
+// VersionHandler reports the version, commit and date of the running build, so operators
+// can confirm what is deployed
+func (h *Handler) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, currentBuild())
+}
+
+// isProbe reports whether path is a health endpoint, which answers without sign-in
+func isProbe(path string) bool {
+	return path == "/healthz" || path == "/readyz"
+}
+
+// readableDirs returns a check that each of dirs can be listed
+func readableDirs(dirs ...string) HealthCheck {
+	return func(ctx context.Context) error {
+		for _, dir := range dirs {
+			f, err := os.Open(dir)
+			if err != nil {
+				return err
+			}
+			_, err = f.Readdirnames(1)
+			f.Close()
+			if err != nil && err != io.EOF {
+				return fmt.Errorf("%s is not readable: %w", dir, err)
+			}
+		}
+		return nil
+	}
+}
+
+// currentBuild describes the running binary
+func currentBuild() buildInfo {
+	commit := Commit
+	if commit == "" {
+		commit, _ = vcsRevision()
+	}
+	return buildInfo{Version: buildVersion(), Commit: commit, BuildDate: BuildDate, GoVersion: runtime.Version()}
+}
+
+// buildVersion returns Version, or failing that the VCS revision of the binary
+func buildVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	revision, modified := vcsRevision()
+	if revision == "" {
+		return "dev"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// vcsRevision returns the commit Go recorded in the binary, if any, and whether the
+// working tree had changes
+func vcsRevision() (revision string, modified bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", false
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	return revision, modified
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"golinks/internal/auth"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_HealthProbes(t *testing.T) {
+	healthy := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name           string
+		path           string
+		checks         map[string]HealthCheck
+		expectedStatus int
+		expectedChecks map[string]string
+	}{
+		{
+			name:           "alive whatever its dependencies",
+			path:           "/healthz",
+			checks:         map[string]HealthCheck{"database": down},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "ready",
+			path:           "/readyz",
+			checks:         map[string]HealthCheck{"database": healthy, "web": healthy},
+			expectedStatus: http.StatusOK,
+			expectedChecks: map[string]string{"database": "ok", "web": "ok"},
+		},
+		{
+			name:           "not ready",
+			path:           "/readyz",
+			checks:         map[string]HealthCheck{"database": down, "web": healthy},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"database": "connection refused", "web": "ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			// Probes answer without signing in
+			handler.sessions = auth.NewSessions(newMockSessionStore(), false, slog.Default())
+			for name, check := range tt.checks {
+				handler.AddReadinessCheck(name, check)
+			}
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("GET %s status = %d, want %d: %s", tt.path, w.Code, tt.expectedStatus, w.Body.String())
+			}
+			var status healthStatus
+			if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+				t.Fatalf("GET %s returned invalid JSON: %v", tt.path, err)
+			}
+			if status.Version == "" {
+				t.Errorf("GET %s reported no version", tt.path)
+			}
+			if len(status.Checks) != len(tt.expectedChecks) {
+				t.Errorf("GET %s checks = %v, want %v", tt.path, status.Checks, tt.expectedChecks)
+			}
+			for name, want := range tt.expectedChecks {
+				if got := status.Checks[name]; got != want {
+					t.Errorf("GET %s check %s = %q, want %q", tt.path, name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_readableDirs(t *testing.T) {
+	empty := t.TempDir()
+
+	if err := readableDirs(empty)(context.Background()); err != nil {
+		t.Errorf("readableDirs() of an empty directory error = %v", err)
+	}
+	if err := readableDirs(empty, empty+"/missing")(context.Background()); err == nil {
+		t.Error("readableDirs() of a missing directory expected error")
+	}
+}
+
+func Test_buildVersion(t *testing.T) {
+	defer func(version string) { Version = version }(Version)
+
+	Version = "v1.2.3"
+	if got := buildVersion(); got != "v1.2.3" {
+		t.Errorf("buildVersion() = %q, want the version set at build time", got)
+	}
+
+	Version = ""
+	if got := buildVersion(); got == "" {
+		t.Error("buildVersion() is empty without a version set")
+	}
+}
+
+func TestHandler_VersionHandler(t *testing.T) {
+	defer func(version, commit, date string) { Version, Commit, BuildDate = version, commit, date }(Version, Commit, BuildDate)
+	Version, Commit, BuildDate = "v1.2.3", "0123456789abcdef", "2024-03-01T12:00:00Z"
+
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/version status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var info buildInfo
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("GET /api/version returned invalid JSON: %v", err)
+	}
+	want := buildInfo{Version: "v1.2.3", Commit: "0123456789abcdef", BuildDate: "2024-03-01T12:00:00Z", GoVersion: runtime.Version()}
+	if info != want {
+		t.Errorf("GET /api/version = %+v, want %+v", info, want)
+	}
+}
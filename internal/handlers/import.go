@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// importColumns are the columns of an imported CSV file, in the order used when it has no
+// header row. Only word and link are required.
+var importColumns = []string{"word", "link", "owner", "tags", "description"}
+
+// Formats links can be imported from: our own CSV files, and the exports of hosted go link
+// services
+const (
+	importFormatCSV       = "csv"
+	importFormatTrotto    = "trotto"
+	importFormatGoLinksIO = "golinksio"
+)
+
+// importParsers read the links of an imported file in each format, with the line each
+// starts on, or 0 for formats without lines
+var importParsers = map[string]func(io.Reader) ([]domain.ImportLink, []int, error){
+	importFormatCSV:       parseImportCSV,
+	importFormatTrotto:    parseTrottoExport,
+	importFormatGoLinksIO: parseGoLinksIOExport,
+}
+
+// ImportLinksHandler imports links from a CSV file of word,link,owner,tags,description
+// rows, or with the format parameter from a Trotto or golinks.io export. The on_conflict
+// parameter chooses whether words that already exist are skipped (the default) or
+// overwritten, and dry_run=true reports what the import would do without changing anything.
+func (h *Handler) ImportLinksHandler(w http.ResponseWriter, r *http.Request) {
+	policy := r.URL.Query().Get("on_conflict")
+	if policy == "" {
+		policy = domain.ImportSkip
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = importFormatCSV
+	}
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "Invalid value for dry_run parameter")
+			return
+		}
+		dryRun = parsed
+	}
+	links, lines, err := ParseImport(format, http.MaxBytesReader(w, r.Body, maxBulkBodyBytes))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID := h.getUserID(r)
+
+	report, err := h.linkService.ImportLinks(r.Context(), links, policy, userID, dryRun)
+	if err != nil {
+		h.writeAPIError(w, err, "import links")
+		return
+	}
+	for i := range report.Results {
+		report.Results[i].Line = lines[i]
+	}
+
+	h.logger.Info("import", "user", userID, "format", format, "policy", policy, "dry_run", dryRun, "created", report.Created,
+		"updated", report.Updated, "skipped", report.Skipped, "failed", report.Failed)
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ParseImport reads the links of a file in one of the import formats, csv, trotto or
+// golinksio, with the line each starts on, or 0 for formats without lines
+func ParseImport(format string, body io.Reader) ([]domain.ImportLink, []int, error) {
+	parse, ok := importParsers[format]
+	if !ok {
+		return nil, nil, fmt.Errorf("Unknown import format %q, expected %s, %s or %s",
+			format, importFormatCSV, importFormatTrotto, importFormatGoLinksIO)
+	}
+
+	links, lines, err := parse(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid %s file: %w", format, err)
+	}
+	return links, lines, nil
+}
+
+// parseImportCSV reads the links of an imported CSV file with the line each starts on. A
+// first row with a word column is a header naming the columns, in any order.
+func parseImportCSV(body io.Reader) ([]domain.ImportLink, []int, error) {
+	columns := map[string]int{}
+	for i, name := range importColumns {
+		columns[name] = i
+	}
+
+	return readImportCSV(body, columns, func(record []string) (map[string]int, error) {
+		if !isImportHeader(record) {
+			return nil, nil
+		}
+		return importHeader(record)
+	})
+}
+
+// readImportCSV reads the links of a CSV file with the line each starts on, taking fields
+// from the word, link, owner, tags and description columns. header maps the columns of a header row, or
+// returns nil if the first row is not one; columns are used without a header.
+func readImportCSV(
+	body io.Reader, columns map[string]int, header func(record []string) (map[string]int, error),
+) ([]domain.ImportLink, []int, error) {
+
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var links []domain.ImportLink
+	var lines []int
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			named, err := header(record)
+			if err != nil {
+				return nil, nil, err
+			}
+			if named != nil {
+				columns = named
+				continue
+			}
+		}
+		if columns == nil {
+			return nil, nil, errors.New("the file has no header row")
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		link := domain.ImportLink{
+			LinkRequest: domain.LinkRequest{
+				Word: field("word"), Link: field("link"), Owner: field("owner"), Description: field("description"),
+			},
+		}
+		if tags := field("tags"); tags != "" {
+			link.Tags = strings.FieldsFunc(tags, isTagSeparator)
+		}
+		links = append(links, link)
+		lines = append(lines, line)
+	}
+	return links, lines, nil
+}
+
+// isImportHeader reports whether the first row of an imported file is a header, which
+// names a word column
+func isImportHeader(record []string) bool {
+	for _, name := range record {
+		if strings.EqualFold(strings.TrimSpace(name), "word") {
+			return true
+		}
+	}
+	return false
+}
+
+// importHeader maps the columns named by a header row to their positions
+func importHeader(record []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, column := range importColumns {
+			known = known || column == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown column %q, expected %s", name, strings.Join(importColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("column %q appears twice", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["link"]; !ok {
+		return nil, errors.New("the header has no link column")
+	}
+	return columns, nil
+}
+
+// isTagSeparator splits the tags column, which may separate tags with commas, semicolons
+// or spaces
+func isTagSeparator(r rune) bool {
+	return r == ',' || r == ';' || r == ' '
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"golinks/internal/domain"
+)
+
+func TestHandler_ImportLinksHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		expectedStatus int
+		expectedPolicy string
+		expectedLinks  []domain.ImportLink
+		expectedLines  []int
+		expectedReport domain.ImportReport
+	}{
+		{
+			name:           "no header",
+			body:           "docs,https://docs.example.com\nwiki, https://wiki.example.com,alice,\"team;wiki, docs\"\n",
+			expectedStatus: http.StatusOK,
+			expectedPolicy: domain.ImportSkip,
+			expectedLinks: []domain.ImportLink{
+				{LinkRequest: domain.LinkRequest{Word: "docs", Link: "https://docs.example.com"}},
+				{LinkRequest: domain.LinkRequest{Word: "wiki", Link: "https://wiki.example.com", Owner: "alice"}, Tags: []string{"team", "wiki", "docs"}},
+			},
+			expectedLines:  []int{1, 2},
+			expectedReport: domain.ImportReport{Created: 1, Skipped: 1},
+		},
+		{
+			name:           "header in another order",
+			query:          "?on_conflict=overwrite",
+			body:           "Tags,Link,Word\n\"multi\nline\",https://example.com,new\n",
+			expectedStatus: http.StatusOK,
+			expectedPolicy: domain.ImportOverwrite,
+			expectedLinks: []domain.ImportLink{
+				{LinkRequest: domain.LinkRequest{Word: "new", Link: "https://example.com"}, Tags: []string{"multi\nline"}},
+			},
+			expectedLines:  []int{2},
+			expectedReport: domain.ImportReport{Created: 1},
+		},
+		{
+			name:           "Trotto export",
+			query:          "?format=trotto",
+			body:           `[{"shortpath": "gh/%s", "destination_url": "https://github.com/%s", "owner": "bob"}]`,
+			expectedStatus: http.StatusOK,
+			expectedPolicy: domain.ImportSkip,
+			expectedLinks: []domain.ImportLink{
+				{LinkRequest: domain.LinkRequest{Word: "gh", Link: "https://github.com/{*}", Owner: "bob"}},
+			},
+			expectedLines:  []int{0},
+			expectedReport: domain.ImportReport{Created: 1},
+		},
+		{
+			name:           "dry run",
+			query:          "?dry_run=true",
+			body:           "new,https://example.com\n",
+			expectedStatus: http.StatusOK,
+			expectedPolicy: domain.ImportSkip,
+			expectedLinks: []domain.ImportLink{
+				{LinkRequest: domain.LinkRequest{Word: "new", Link: "https://example.com"}},
+			},
+			expectedLines:  []int{1},
+			expectedReport: domain.ImportReport{Created: 1, DryRun: true},
+		},
+		{
+			name:           "invalid dry run",
+			query:          "?dry_run=maybe",
+			body:           "new,https://example.com\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown format",
+			query:          "?format=xlsx",
+			body:           "new,https://example.com\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown policy",
+			query:          "?on_conflict=merge",
+			body:           "new,https://example.com\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown column",
+			body:           "word,url\nnew,https://example.com\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "header without a link",
+			body:           "word,owner\nnew,alice\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "bad quoting",
+			body:           "new,\"https://example.com\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			links := handler.linkService.(*mockLinkService)
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/api/links/import"+tt.query, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("ImportLinksHandler() status = %v, want %v: %s", w.Code, tt.expectedStatus, w.Body)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if links.importPolicy != tt.expectedPolicy {
+				t.Errorf("ImportLinksHandler() policy = %q, want %q", links.importPolicy, tt.expectedPolicy)
+			}
+			if !reflect.DeepEqual(links.imported, tt.expectedLinks) {
+				t.Errorf("ImportLinksHandler() imported %+v, want %+v", links.imported, tt.expectedLinks)
+			}
+
+			var report domain.ImportReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			for i, result := range report.Results {
+				if result.Line != tt.expectedLines[i] {
+					t.Errorf("result %d line = %d, want %d", i, result.Line, tt.expectedLines[i])
+				}
+			}
+			report.Results = nil
+			if !reflect.DeepEqual(report, tt.expectedReport) {
+				t.Errorf("ImportLinksHandler() report = %+v, want %+v", report, tt.expectedReport)
+			}
+		})
+	}
+}
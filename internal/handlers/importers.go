@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// trottoLink is a link in a Trotto export, as listed by its /_/api/links endpoint
+type trottoLink struct {
+	Shortpath      string `json:"shortpath"`
+	DestinationURL string `json:"destination_url"`
+	Owner          string `json:"owner"`
+}
+
+// trottoPlaceholder matches the placeholders of Trotto's programmatic links, %s for the
+// rest of the query or %1 to %9 for one word of it, along with a hex digit after one so
+// percent-encoded characters like %20 can be told apart
+var trottoPlaceholder = regexp.MustCompile(`%[s1-9][0-9A-Fa-f]?`)
+
+// goLinksIOColumns maps the header names of a golinks.io CSV export, lower case and
+// without spaces, dashes or underscores, to the columns they hold. Other columns, like
+// visit counts, are ignored.
+var goLinksIOColumns = map[string]string{
+	"name":           "word",
+	"golink":         "word",
+	"shortlink":      "word",
+	"keyword":        "word",
+	"alias":          "word",
+	"url":            "link",
+	"destination":    "link",
+	"destinationurl": "link",
+	"targeturl":      "link",
+	"owner":          "owner",
+	"owneremail":     "owner",
+	"createdby":      "owner",
+	"creator":        "owner",
+	"tags":           "tags",
+	"description":    "description",
+}
+
+// parseTrottoExport reads the links of a Trotto JSON export, turning its programmatic
+// links, like gh/%s, into words with placeholders
+func parseTrottoExport(body io.Reader) ([]domain.ImportLink, []int, error) {
+	var exported []trottoLink
+	if err := json.NewDecoder(body).Decode(&exported); err != nil {
+		return nil, nil, fmt.Errorf("expected a JSON array of Trotto links: %w", err)
+	}
+
+	links := make([]domain.ImportLink, len(exported))
+	for i, link := range exported {
+		// The placeholders of a programmatic link follow its word, as in pr/%1/%2
+		word, _, _ := strings.Cut(link.Shortpath, "/%")
+		links[i] = domain.ImportLink{LinkRequest: domain.LinkRequest{
+			Word:  strings.TrimSpace(word),
+			Link:  strings.TrimSpace(convertTrottoPlaceholders(link.DestinationURL)),
+			Owner: strings.TrimSpace(link.Owner),
+		}}
+	}
+	return links, make([]int, len(links)), nil
+}
+
+// convertTrottoPlaceholders rewrites Trotto's %s and %1 placeholders as {*} and {1},
+// leaving percent-encoded characters alone
+func convertTrottoPlaceholders(link string) string {
+	return trottoPlaceholder.ReplaceAllStringFunc(link, func(match string) string {
+		switch {
+		case match[1] == 's':
+			return "{*}" + match[2:]
+		case len(match) == 3:
+			return match
+		default:
+			return "{" + match[1:] + "}"
+		}
+	})
+}
+
+// parseGoLinksIOExport reads the links of a golinks.io CSV export, which names its columns
+// in a header row and may write words as go/word or with a trailing /{*}
+func parseGoLinksIOExport(body io.Reader) ([]domain.ImportLink, []int, error) {
+	links, lines, err := readImportCSV(body, nil, goLinksIOHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range links {
+		word := strings.TrimPrefix(links[i].Word, "go/")
+		word = strings.TrimSuffix(strings.TrimSuffix(word, "/{*}"), "/%s")
+		links[i].Word = word
+		links[i].Link = convertTrottoPlaceholders(links[i].Link)
+	}
+	return links, lines, nil
+}
+
+// goLinksIOHeader maps the columns of a golinks.io header row
+func goLinksIOHeader(record []string) (map[string]int, error) {
+	normalize := strings.NewReplacer(" ", "", "-", "", "_", "")
+	columns := map[string]int{}
+	for i, name := range record {
+		column, ok := goLinksIOColumns[normalize.Replace(strings.ToLower(strings.TrimSpace(name)))]
+		if !ok {
+			continue
+		}
+		if _, ok := columns[column]; !ok {
+			columns[column] = i
+		}
+	}
+	_, hasWord := columns["word"]
+	_, hasLink := columns["link"]
+	if !hasWord || !hasLink {
+		return nil, errors.New("expected a header row with name and url columns")
+	}
+	return columns, nil
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestConvertTrottoPlaceholders(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{link: "https://github.com/%s", want: "https://github.com/{*}"},
+		{link: "https://github.com/org/%2/pull/%1", want: "https://github.com/org/{2}/pull/{1}"},
+		{link: "https://example.com/a%20b?q=%s", want: "https://example.com/a%20b?q={*}"},
+		{link: "https://example.com/%1a", want: "https://example.com/%1a"},
+		{link: "https://example.com/%sa", want: "https://example.com/{*}a"},
+		{link: "https://example.com/100%", want: "https://example.com/100%"},
+	}
+
+	for _, tt := range tests {
+		if got := convertTrottoPlaceholders(tt.link); got != tt.want {
+			t.Errorf("convertTrottoPlaceholders(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestImportParsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		body      string
+		wantLinks []domain.ImportLink
+		wantLines []int
+		wantErr   string
+	}{
+		{
+			name:   "Trotto",
+			format: importFormatTrotto,
+			body: `[
+				{"id": 1, "shortpath": "docs", "destination_url": "https://docs.example.com", "owner": "alice@example.com", "visits_count": 3},
+				{"id": 2, "shortpath": "gh/%s", "destination_url": "https://github.com/%s", "owner": "bob@example.com"},
+				{"id": 3, "shortpath": "pr/%1/%2", "destination_url": "https://github.com/org/%2/pull/%1", "owner": "bob@example.com"}
+			]`,
+			wantLinks: []domain.ImportLink{
+				{LinkRequest: domain.LinkRequest{Word: "docs", Link: "https://docs.example.com", Owner: "alice@example.com"}},
+				{LinkRequest: domain.LinkRequest{Word: "gh", Link: "https://github.com/{*}", Owner: "bob@example.com"}},
+				{LinkRequest: domain.LinkRequest{Word: "pr", Link: "https://github.com/org/{2}/pull/{1}", Owner: "bob@example.com"}},
+			},
+			wantLines: []int{0, 0, 0},
+		},
+		{
+			name:    "Trotto object",
+			format:  importFormatTrotto,
+			body:    `{"shortpath": "docs"}`,
+			wantErr: "JSON array",
+		},
+		{
+			name:   "golinks.io",
+			format: importFormatGoLinksIO,
+			body: "Name,Description,Destination URL,Owner Email,Tags,Visits\n" +
+				"go/docs,Team docs,https://docs.example.com,alice@example.com,\"eng, docs\",12\n" +
+				"jira/{*},,https://jira.example.com/browse/{*},,,0\n",
+			wantLinks: []domain.ImportLink{
+				{LinkRequest: domain.LinkRequest{Word: "docs", Link: "https://docs.example.com", Description: "Team docs", Owner: "alice@example.com"}, Tags: []string{"eng", "docs"}},
+				{LinkRequest: domain.LinkRequest{Word: "jira", Link: "https://jira.example.com/browse/{*}"}},
+			},
+			wantLines: []int{2, 3},
+		},
+		{
+			name:    "golinks.io without a header",
+			format:  importFormatGoLinksIO,
+			body:    "docs,https://docs.example.com\n",
+			wantErr: "name and url columns",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, lines, err := importParsers[tt.format](strings.NewReader(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("importParsers[%s]() error = %v, want %q", tt.format, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("importParsers[%s]() error = %v", tt.format, err)
+			}
+			if !reflect.DeepEqual(links, tt.wantLinks) {
+				t.Errorf("importParsers[%s]() = %+v, want %+v", tt.format, links, tt.wantLinks)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("importParsers[%s]() lines = %v, want %v", tt.format, lines, tt.wantLines)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// trustedDestination reports whether golinks may redirect to targetURL without warning:
+// when no trusted domains are configured, or it points to one of them, a subdomain of one
+// or the golinks server itself
+func (h *Handler) trustedDestination(targetURL string) bool {
+	if len(h.config.TrustedDomains) == 0 {
+		return true
+	}
+	target, err := url.Parse(targetURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+
+	trusted := h.config.TrustedDomains
+	if base, err := url.Parse(h.config.BaseURL); err == nil && base.Hostname() != "" {
+		trusted = append([]string{base.Hostname()}, trusted...)
+	}
+	for _, trustedDomain := range trusted {
+		trustedDomain = strings.ToLower(strings.Trim(trustedDomain, "."))
+		if host == trustedDomain || strings.HasSuffix(host, "."+trustedDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderInterstitial shows where a golink leads and who owns it before following it, for
+// destinations outside the trusted domains
+func (h *Handler) renderInterstitial(w http.ResponseWriter, resolution *domain.Resolution) {
+	data := struct {
+		BaseURL string
+		Word    string
+		Target  string
+		Host    string
+		Owner   string
+	}{
+		BaseURL: h.config.BaseURL,
+		Word:    resolution.ResolvedWord,
+		Target:  resolution.URL,
+		Owner:   resolution.Owner,
+	}
+	if target, err := url.Parse(resolution.URL); err == nil {
+		data.Host = target.Host
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := h.templates.ExecuteTemplate(w, "interstitial.html", data); err != nil {
+		h.logger.Error("Failed to execute template", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
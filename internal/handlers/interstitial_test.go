@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_TrustedDestination(t *testing.T) {
+	handler := setupTestHandler()
+	handler.config.TrustedDomains = []string{"example.com", ".Example.NET."}
+
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{target: "https://example.com/page", want: true},
+		{target: "https://docs.example.com", want: true},
+		{target: "https://WIKI.EXAMPLE.NET:8443/", want: true},
+		{target: "http://localhost:8080/homepage/", want: true},
+		{target: "https://example.com.evil.test", want: false},
+		{target: "https://notexample.com", want: false},
+		{target: "https://evil.test/?next=example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := handler.trustedDestination(tt.target); got != tt.want {
+			t.Errorf("trustedDestination(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+
+	handler.config.TrustedDomains = nil
+	if !handler.trustedDestination("https://evil.test") {
+		t.Error("trustedDestination() = false without trusted domains, want every destination trusted")
+	}
+}
+
+func TestHandler_RedirectHandler_Interstitial(t *testing.T) {
+	handler := setupTestHandler()
+	handler.config.TrustedDomains = []string{"docs.example.com"}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantLocation string
+		wantBody     string
+	}{
+		{name: "trusted destination redirects", path: "/query/docs", wantStatus: http.StatusFound, wantLocation: "https://docs.example.com"},
+		{name: "untrusted destination warns", path: "/query/github", wantStatus: http.StatusOK, wantBody: `<a href="https://github.com">Continue to github.com</a>`},
+		{name: "missing link", path: "/query/nonexistent", wantStatus: http.StatusFound, wantLocation: "http://localhost:8080/homepage/?missing=nonexistent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GET %s status = %d, want %d", tt.path, w.Code, tt.wantStatus)
+			}
+			if location := w.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("GET %s Location = %q, want %q", tt.path, location, tt.wantLocation)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s body = %s, want it to contain %s", tt.path, w.Body.String(), tt.wantBody)
+			}
+			if tt.wantBody != "" && w.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("GET %s Cache-Control = %q, want no-store", tt.path, w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+)
+
+// Actions a launcher item asks the workflow to take with its arg
+const (
+	launcherActionOpen   = "open"
+	launcherActionCreate = "create"
+)
+
+// launcherItems is a script filter result, the JSON Alfred reads from a script filter
+// and Raycast script filter extensions accept
+type launcherItems struct {
+	Items []launcherItem `json:"items"`
+}
+
+// launcherItem is one row of a script filter result. Arg is what the workflow acts on:
+// the URL to open, or the query to create a link from, as Variables["action"] says.
+type launcherItem struct {
+	UID          string            `json:"uid,omitempty"`
+	Title        string            `json:"title"`
+	Subtitle     string            `json:"subtitle"`
+	Arg          string            `json:"arg,omitempty"`
+	Autocomplete string            `json:"autocomplete,omitempty"`
+	Valid        bool              `json:"valid"`
+	Variables    map[string]string `json:"variables,omitempty"`
+}
+
+// LauncherSearchHandler offers the golinks matching what has been typed into a launcher
+// as script filter items, each opening its link with the rest of the query. When no
+// keyword matches the first word exactly, it also offers to create one from a query
+// like "word https://example.com".
+func (h *Handler) LauncherSearchHandler(w http.ResponseWriter, r *http.Request) {
+	query := searchQuery(r.URL.Query().Get("q"))
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		writeJSON(w, http.StatusOK, launcherItems{Items: []launcherItem{{
+			Title:     "Open golinks",
+			Subtitle:  "Type a keyword, or a keyword and a URL to create one",
+			Arg:       h.config.BaseURL + "/homepage/",
+			Valid:     true,
+			Variables: map[string]string{"action": launcherActionOpen},
+		}}})
+		return
+	}
+
+	suggestions, err := h.linkService.SuggestLinks(r.Context(), query, h.getUserID(r), 0)
+	if err != nil {
+		h.writeLauncherError(w, err, "suggest links")
+		return
+	}
+
+	word, terms := fields[0], fields[1:]
+	items := launcherItems{Items: []launcherItem{}}
+	exact := false
+	for _, suggestion := range suggestions.Suggestions {
+		exact = exact || suggestion.Word == word
+		followed := strings.Join(append([]string{suggestion.Word}, terms...), " ")
+		items.Items = append(items.Items, launcherItem{
+			UID:          suggestion.Word,
+			Title:        "go/" + followed,
+			Subtitle:     suggestion.Description,
+			Arg:          h.config.BaseURL + "/search?q=" + url.QueryEscape(followed),
+			Autocomplete: suggestion.Word + " ",
+			Valid:        true,
+			Variables:    map[string]string{"action": launcherActionOpen},
+		})
+	}
+
+	if !exact {
+		items.Items = append(items.Items, h.launcherCreateItem(word, terms))
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age=10")
+	writeJSON(w, http.StatusOK, items)
+}
+
+// launcherCreateItem offers to create a missing keyword: straight from the launcher when
+// the query is the keyword and its link, or on the homepage otherwise
+func (h *Handler) launcherCreateItem(word string, terms []string) launcherItem {
+	if len(terms) != 1 {
+		return launcherItem{
+			Title:     "Create go/" + word,
+			Subtitle:  "Add a link after the keyword to create it here, or open the homepage",
+			Arg:       h.config.BaseURL + "/homepage/?missing=" + url.QueryEscape(word),
+			Valid:     true,
+			Variables: map[string]string{"action": launcherActionOpen},
+		}
+	}
+
+	return launcherItem{
+		Title:     "Create go/" + word,
+		Subtitle:  "Point go/" + word + " at " + terms[0],
+		Arg:       word + " " + terms[0],
+		Valid:     true,
+		Variables: map[string]string{"action": launcherActionCreate},
+	}
+}
+
+// LauncherCreateHandler creates a golink from a launcher query of a keyword and its link,
+// such as "docs https://docs.example.com", given as the q form value. Like POST
+// /api/v1/links it never overwrites an existing keyword. The answer is a script filter
+// item naming the new link, or a single invalid item saying what went wrong.
+func (h *Handler) LauncherCreateHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAPIBodyBytes)
+	fields := strings.Fields(searchQuery(r.FormValue("q")))
+	if len(fields) != 2 {
+		writeLauncherItem(w, http.StatusBadRequest, launcherItem{
+			Title:    "Cannot create a golink",
+			Subtitle: "Give a keyword and the link it goes to, like: docs https://docs.example.com",
+		})
+		return
+	}
+
+	ctx := r.Context()
+	userID := h.getUserID(r)
+	req := domain.LinkRequest{Word: fields[0], Link: fields[1]}
+
+	if _, err := h.linkService.GetShortcut(ctx, req.Word, userID); err == nil {
+		writeLauncherItem(w, http.StatusConflict, launcherItem{
+			Title:    "go/" + req.Word + " already exists",
+			Subtitle: "Edit it on the homepage instead",
+		})
+		return
+	} else if _, ok := err.(service.NotFoundError); !ok {
+		h.writeLauncherError(w, err, "check link "+req.Word)
+		return
+	}
+
+	if err := h.linkService.UpdateLink(ctx, req, userID); err != nil {
+		h.writeLauncherError(w, err, "save link "+req.Word)
+		return
+	}
+
+	writeLauncherItem(w, http.StatusCreated, launcherItem{
+		UID:       req.Word,
+		Title:     "Created go/" + req.Word,
+		Subtitle:  req.Link,
+		Arg:       h.config.BaseURL + "/query/" + req.Word,
+		Valid:     true,
+		Variables: map[string]string{"action": launcherActionOpen},
+	})
+}
+
+// writeLauncherItem answers with a script filter result holding a single item
+func writeLauncherItem(w http.ResponseWriter, status int, item launcherItem) {
+	writeJSON(w, status, launcherItems{Items: []launcherItem{item}})
+}
+
+// writeLauncherError answers with the status writeAPIError would, but as an invalid item
+// a launcher can show in place of results
+func (h *Handler) writeLauncherError(w http.ResponseWriter, err error, action string) {
+	status, message := http.StatusInternalServerError, "Internal server error"
+	switch err.(type) {
+	case service.InvalidQueryError:
+		status, message = http.StatusBadRequest, err.Error()
+	case service.NotFoundError:
+		status, message = http.StatusNotFound, err.Error()
+	case service.ForbiddenError:
+		status, message = http.StatusForbidden, err.Error()
+	case service.UnauthorizedError:
+		status, message = http.StatusUnauthorized, err.Error()
+	case service.AliasLoopError:
+		status, message = http.StatusLoopDetected, err.Error()
+	case service.ArchivedError:
+		status, message = http.StatusGone, err.Error()
+	default:
+		h.logger.Error("Failed to "+action, "err", err)
+	}
+	writeLauncherItem(w, status, launcherItem{Title: "golinks could not do that", Subtitle: message})
+}
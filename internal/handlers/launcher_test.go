@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_LauncherSearch(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expectedItems []launcherItem
+	}{
+		{
+			name:  "empty query",
+			query: "",
+			expectedItems: []launcherItem{{
+				Title: "Open golinks", Subtitle: "Type a keyword, or a keyword and a URL to create one",
+				Arg: "http://localhost:8080/homepage/", Valid: true, Variables: map[string]string{"action": "open"},
+			}},
+		},
+		{
+			name:  "exact match",
+			query: "docs",
+			expectedItems: []launcherItem{{
+				UID: "docs", Title: "go/docs", Subtitle: "https://docs.example.com",
+				Arg: "http://localhost:8080/search?q=docs", Autocomplete: "docs ", Valid: true,
+				Variables: map[string]string{"action": "open"},
+			}},
+		},
+		{
+			name:  "match followed with terms",
+			query: "go/docs api guide",
+			expectedItems: []launcherItem{{
+				UID: "docs", Title: "go/docs api guide", Subtitle: "https://docs.example.com",
+				Arg: "http://localhost:8080/search?q=docs+api+guide", Autocomplete: "docs ", Valid: true,
+				Variables: map[string]string{"action": "open"},
+			}},
+		},
+		{
+			name:  "prefix offers creating the word",
+			query: "git",
+			expectedItems: []launcherItem{
+				{
+					UID: "github", Title: "go/github", Subtitle: "https://github.com",
+					Arg: "http://localhost:8080/search?q=github", Autocomplete: "github ", Valid: true,
+					Variables: map[string]string{"action": "open"},
+				},
+				{
+					Title: "Create go/git", Subtitle: "Add a link after the keyword to create it here, or open the homepage",
+					Arg: "http://localhost:8080/homepage/?missing=git", Valid: true,
+					Variables: map[string]string{"action": "open"},
+				},
+			},
+		},
+		{
+			name:  "missing word with a link",
+			query: "wiki https://wiki.example.com",
+			expectedItems: []launcherItem{{
+				Title: "Create go/wiki", Subtitle: "Point go/wiki at https://wiki.example.com",
+				Arg: "wiki https://wiki.example.com", Valid: true, Variables: map[string]string{"action": "create"},
+			}},
+		},
+		{
+			name:  "missing word with search terms",
+			query: "wiki new hires",
+			expectedItems: []launcherItem{{
+				Title: "Create go/wiki", Subtitle: "Add a link after the keyword to create it here, or open the homepage",
+				Arg: "http://localhost:8080/homepage/?missing=wiki", Valid: true,
+				Variables: map[string]string{"action": "open"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/api/launcher/search?q="+url.QueryEscape(tt.query), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET /api/launcher/search?q=%s status = %d: %s", tt.query, w.Code, w.Body.String())
+			}
+
+			var result launcherItems
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				t.Fatalf("Failed to decode items: %v", err)
+			}
+			got, _ := json.Marshal(result.Items)
+			want, _ := json.Marshal(tt.expectedItems)
+			if string(got) != string(want) {
+				t.Errorf("items = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestHandler_LauncherCreate(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		updateError    error
+		expectedStatus int
+		expectedTitle  string
+		expectedLink   string
+	}{
+		{"creates link", "wiki https://wiki.example.com", nil, http.StatusCreated, "Created go/wiki", "https://wiki.example.com"},
+		{"drops go prefix", "go/wiki https://wiki.example.com", nil, http.StatusCreated, "Created go/wiki", "https://wiki.example.com"},
+		{"existing word", "docs https://other.example.com", nil, http.StatusConflict, "go/docs already exists", ""},
+		{"no link", "wiki", nil, http.StatusBadRequest, "Cannot create a golink", ""},
+		{"too many terms", "wiki https://wiki.example.com extra", nil, http.StatusBadRequest, "Cannot create a golink", ""},
+		{"save fails", "wiki https://wiki.example.com", errors.New("disk full"), http.StatusInternalServerError, "golinks could not do that", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			mockService := handler.linkService.(*mockLinkService)
+			mockService.updateError = tt.updateError
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			form := url.Values{"q": {tt.query}}.Encode()
+			req := httptest.NewRequest("POST", "/api/launcher/links", strings.NewReader(form))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("POST /api/launcher/links q=%s status = %d, want %d: %s", tt.query, w.Code, tt.expectedStatus, w.Body.String())
+			}
+
+			var result launcherItems
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				t.Fatalf("Failed to decode items: %v", err)
+			}
+			if len(result.Items) != 1 || result.Items[0].Title != tt.expectedTitle {
+				t.Fatalf("items = %+v, want one titled %q", result.Items, tt.expectedTitle)
+			}
+			if valid := tt.expectedStatus == http.StatusCreated; result.Items[0].Valid != valid {
+				t.Errorf("valid = %v, want %v", result.Items[0].Valid, valid)
+			}
+			if tt.expectedLink != "" && mockService.links["wiki"] != tt.expectedLink {
+				t.Errorf("wiki links to %q, want %q", mockService.links["wiki"], tt.expectedLink)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"golinks/internal/domain"
+	"golinks/internal/logger"
+)
+
+// SetLogLevel lets admins change level, the level the server logs at, while it runs.
+// Without it the log level endpoints answer 404.
+func (h *Handler) SetLogLevel(level *slog.LevelVar) {
+	h.logLevel = level
+}
+
+// GetLogLevelHandler reports the level the server logs at
+func (h *Handler) GetLogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	if h.logLevel == nil {
+		writeJSONError(w, http.StatusNotFound, "The log level cannot be changed on this server")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, domain.LogLevel{Level: h.logLevel.Level().String()})
+}
+
+// SetLogLevelHandler changes the level the server logs at until it restarts, such as to
+// debug while diagnosing an issue
+func (h *Handler) SetLogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	if h.logLevel == nil {
+		writeJSONError(w, http.StatusNotFound, "The log level cannot be changed on this server")
+		return
+	}
+
+	var req domain.LogLevel
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	previous := h.logLevel.Level()
+	h.logLevel.Set(level)
+	// Logged at warn so the change is recorded at any level short of error
+	h.logger.Warn("log level set", "level", level.String(), "previous", previous.String(), "user", h.getUserID(r))
+
+	writeJSON(w, http.StatusOK, domain.LogLevel{Level: level.String()})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_LogLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       domain.Role
+		method     string
+		body       string
+		noLevel    bool
+		wantStatus int
+		wantLevel  slog.Level
+	}{
+		{name: "report", role: domain.RoleAdmin, method: "GET", wantStatus: http.StatusOK, wantLevel: slog.LevelInfo},
+		{name: "turn on debug", role: domain.RoleAdmin, method: "PUT", body: `{"level": "debug"}`, wantStatus: http.StatusOK, wantLevel: slog.LevelDebug},
+		{name: "upper case", role: domain.RoleAdmin, method: "PUT", body: `{"level": "WARN"}`, wantStatus: http.StatusOK, wantLevel: slog.LevelWarn},
+		{name: "unknown level", role: domain.RoleAdmin, method: "PUT", body: `{"level": "verbose"}`, wantStatus: http.StatusBadRequest, wantLevel: slog.LevelInfo},
+		{name: "no level", role: domain.RoleAdmin, method: "PUT", body: `{}`, wantStatus: http.StatusBadRequest, wantLevel: slog.LevelInfo},
+		{name: "editor", role: domain.RoleEditor, method: "PUT", body: `{"level": "debug"}`, wantStatus: http.StatusForbidden, wantLevel: slog.LevelInfo},
+		{name: "not adjustable", role: domain.RoleAdmin, method: "PUT", body: `{"level": "debug"}`, noLevel: true, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			level := new(slog.LevelVar)
+			if !tt.noLevel {
+				handler.SetLogLevel(level)
+			}
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest(tt.method, "/api/admin/loglevel", strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s /api/admin/loglevel status = %d, want %d, body = %s", tt.method, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if level.Level() != tt.wantLevel {
+				t.Errorf("%s /api/admin/loglevel left the level at %v, want %v", tt.method, level.Level(), tt.wantLevel)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var got domain.LogLevel
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil || got.Level != tt.wantLevel.String() {
+				t.Errorf("%s /api/admin/loglevel = %+v, %v, want %s", tt.method, got, err, tt.wantLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golinks/internal/service"
+)
+
+// renderAliasLoop explains that a golink's aliases loop, or chain through too many
+// aliases, naming each word passed through so its owner can fix one of them
+func (h *Handler) renderAliasLoop(w http.ResponseWriter, query string, loop service.AliasLoopError) {
+	data := struct {
+		BaseURL string
+		Query   string
+		Message string
+		Chain   []string
+	}{
+		BaseURL: h.config.BaseURL,
+		Query:   query,
+		Message: loop.Message,
+		Chain:   loop.Chain,
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusLoopDetected)
+	if err := h.templates.ExecuteTemplate(w, "loop.html", data); err != nil {
+		h.logger.Error("Failed to execute template", "err", err)
+	}
+}
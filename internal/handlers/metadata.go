@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// MetadataHandler replaces a golink's metadata with the JSON object in the request body,
+// leaving the rest of the link as it is
+func (h *Handler) MetadataHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+	userID := h.getUserID(r)
+
+	var metadata map[string]interface{}
+	if !decodeJSONBody(w, r, &metadata) {
+		return
+	}
+
+	shortcut, err := h.linkService.SetMetadata(r.Context(), word, metadata, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "set metadata of "+word)
+		return
+	}
+
+	h.logger.Info("metadata", "word", word, "user", userID)
+
+	writeJSON(w, http.StatusOK, shortcut)
+}
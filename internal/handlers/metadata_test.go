@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_MetadataHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantTeam    string
+	}{
+		{name: "sets metadata", path: "/api/links/docs/metadata", body: `{"team": "docs", "tier": 2}`, wantStatus: http.StatusOK, wantTeam: "docs"},
+		{name: "clears metadata", path: "/api/links/docs/metadata", body: `{}`, wantStatus: http.StatusOK},
+		{name: "missing link", path: "/api/links/missing/metadata", body: `{"team": "docs"}`, wantStatus: http.StatusNotFound},
+		{name: "invalid metadata", path: "/api/links/docs/metadata", body: `{"": "docs"}`, wantStatus: http.StatusBadRequest},
+		{name: "not an object", path: "/api/links/docs/metadata", body: `["docs"]`, wantStatus: http.StatusBadRequest},
+		{name: "not json", path: "/api/links/docs/metadata", contentType: "text/plain", body: `{}`, wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("PUT", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("PUT %s status = %d, want %d, body = %s", tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var shortcut domain.Shortcut
+			if err := json.NewDecoder(w.Body).Decode(&shortcut); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if team, _ := shortcut.Metadata["team"].(string); team != tt.wantTeam || shortcut.UpdatedBy != "DefaultUser" {
+				t.Errorf("PUT %s = %+v, want team %q set by DefaultUser", tt.path, shortcut, tt.wantTeam)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// ResponseTimeHeader is the header carrying the request latency in milliseconds
+const ResponseTimeHeader = "X-Response-Time"
+
+// ResponseTimeMiddleware adds an X-Response-Time header to every response
+func ResponseTimeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &timingResponseWriter{ResponseWriter: w, start: time.Now()}
+		next.ServeHTTP(tw, r)
+		// Handlers that never write still need the header set
+		tw.setHeader()
+	})
+}
+
+// timingResponseWriter sets the response time header just before the headers are sent
+type timingResponseWriter struct {
+	http.ResponseWriter
+	start       time.Time
+	wroteHeader bool
+}
+
+func (w *timingResponseWriter) WriteHeader(statusCode int) {
+	w.setHeader()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *timingResponseWriter) Write(b []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer supports it
+func (w *timingResponseWriter) Flush() {
+	w.setHeader()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *timingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack implements http.Hijacker when the underlying writer supports it. A hijacked
+// connection gets no response time header, as the handler writes the response itself.
+func (w *timingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.wroteHeader = true
+	return hijack(w.ResponseWriter)
+}
+
+func (w *timingResponseWriter) setHeader() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	elapsed := float64(time.Since(w.start).Microseconds()) / 1000
+	w.Header().Set(ResponseTimeHeader, strconv.FormatFloat(elapsed, 'f', 3, 64))
+}
+
+// responseWindow is the longest rolling window responses are counted over, in seconds
+const responseWindow = 3600
+
+// responseCounter keeps rolling counts of the responses sent and how many were errors,
+// for the admin dashboard. It only sees the requests routed by this process.
+type responseCounter struct {
+	mu sync.Mutex
+
+	// counts holds the responses sent in each second of the last hour at index
+	// second % responseWindow, and seconds the Unix second each slot was last counted for
+	counts  [responseWindow]domain.ResponseCounts
+	seconds [responseWindow]int64
+
+	total domain.ResponseCounts
+	since time.Time
+	now   func() time.Time
+}
+
+func newResponseCounter(now func() time.Time) *responseCounter {
+	return &responseCounter{since: now().UTC().Truncate(time.Second), now: now}
+}
+
+// middleware counts the status of every response sent through next
+func (c *responseCounter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		c.count(sw.Status())
+	})
+}
+
+// count adds a response with status to the current second
+func (c *responseCounter) count(status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	second := c.now().Unix()
+	slot := second % responseWindow
+	if second > c.seconds[slot] {
+		c.seconds[slot] = second
+		c.counts[slot] = domain.ResponseCounts{}
+	}
+	// A response older than the slot's second is out of the window already
+	if second == c.seconds[slot] {
+		countStatus(&c.counts[slot], status)
+	}
+	countStatus(&c.total, status)
+}
+
+// rates sums the responses over the last minute and hour
+func (c *responseCounter) rates() domain.ErrorRates {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now().Unix()
+	rates := domain.ErrorRates{Total: c.total, Since: c.since}
+	for slot, second := range c.seconds {
+		age := now - second
+		if age < 0 || age >= responseWindow {
+			continue
+		}
+		if age < 60 {
+			addCounts(&rates.LastMinute, c.counts[slot])
+		}
+		addCounts(&rates.LastHour, c.counts[slot])
+	}
+	return rates
+}
+
+// countStatus counts a response with status in counts
+func countStatus(counts *domain.ResponseCounts, status int) {
+	counts.Requests++
+	switch {
+	case status >= 500:
+		counts.ServerErrors++
+	case status >= 400:
+		counts.ClientErrors++
+	}
+}
+
+// addCounts adds more to counts
+func addCounts(counts *domain.ResponseCounts, more domain.ResponseCounts) {
+	counts.Requests += more.Requests
+	counts.ClientErrors += more.ClientErrors
+	counts.ServerErrors += more.ServerErrors
+}
+
+// statusResponseWriter records the status code a handler responds with
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer supports it
+func (w *statusResponseWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack implements http.Hijacker when the underlying writer supports it
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+// Status returns the status code sent, which is 200 when the handler never wrote one
+func (w *statusResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// hijack takes over the connection of w, if it can be
+func hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer doesn't support hijacking")
+	}
+	return h.Hijack()
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestResponseTimeMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "handler writing a body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			},
+		},
+		{
+			name: "handler writing only a status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			name:    "handler writing nothing",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			ResponseTimeMiddleware(tt.handler).ServeHTTP(w, req)
+
+			value := w.Header().Get(ResponseTimeHeader)
+			if value == "" {
+				t.Fatalf("%s header missing", ResponseTimeHeader)
+			}
+			ms, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("%s header %q is not numeric: %v", ResponseTimeHeader, value, err)
+			}
+			if ms < 0 {
+				t.Errorf("%s header = %v, want non-negative", ResponseTimeHeader, ms)
+			}
+		})
+	}
+}
+
+func TestResponseTimeMiddleware_Toggle(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{"enabled", true},
+		{"disabled", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.config.ResponseTimeHeader = tt.enabled
+
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/query/docs", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			hasHeader := w.Header().Get(ResponseTimeHeader) != ""
+			if hasHeader != tt.enabled {
+				t.Errorf("%s present = %v, want %v", ResponseTimeHeader, hasHeader, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Hijack(t *testing.T) {
+	counter := newResponseCounter(time.Now)
+	handler := ResponseTimeMiddleware(counter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("response writer doesn't implement http.Hijacker")
+			return
+		}
+		conn, rw, err := hijacker.Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = rw.Flush()
+	})))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hijacked" {
+		t.Errorf("body = %q, want the response written to the hijacked connection", body)
+	}
+
+	// Writers that can't be hijacked say so rather than panicking
+	ResponseTimeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			t.Error("Hijack() of a recorder error = nil, want an error")
+		}
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestResponseCounter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	counter := newResponseCounter(func() time.Time { return now })
+	handler := counter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/silent":
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	serve := func(path string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	// Two hours ago, then half an hour ago, then now
+	now = now.Add(-2 * time.Hour)
+	serve("/broken")
+	now = now.Add(90 * time.Minute)
+	serve("/missing")
+	serve("/")
+	now = now.Add(30 * time.Minute)
+	serve("/broken")
+	serve("/silent")
+
+	rates := counter.rates()
+	tests := []struct {
+		name string
+		got  domain.ResponseCounts
+		want domain.ResponseCounts
+	}{
+		{"last minute", rates.LastMinute, domain.ResponseCounts{Requests: 2, ServerErrors: 1}},
+		{"last hour", rates.LastHour, domain.ResponseCounts{Requests: 4, ClientErrors: 1, ServerErrors: 1}},
+		{"total", rates.Total, domain.ResponseCounts{Requests: 5, ClientErrors: 1, ServerErrors: 2}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("responseCounter.rates() %s = %+v, want %+v", tt.name, tt.got, tt.want)
+		}
+	}
+}
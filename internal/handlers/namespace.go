@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// NamespaceService interface for team namespace operations
+type NamespaceService interface {
+	ListNamespaces(ctx context.Context) ([]domain.Namespace, error)
+	GetNamespace(ctx context.Context, name string) (*domain.Namespace, error)
+	CreateNamespace(ctx context.Context, req domain.NamespaceRequest, requester string) (*domain.Namespace, error)
+	DeleteNamespace(ctx context.Context, name, requester string) error
+	SetMember(ctx context.Context, name, user string, req domain.NamespaceMemberRequest, requester string) (*domain.NamespaceMember, error)
+	RemoveMember(ctx context.Context, name, user, requester string) error
+}
+
+// ListNamespacesHandler lists every namespace
+func (h *Handler) ListNamespacesHandler(w http.ResponseWriter, r *http.Request) {
+	namespaces, err := h.namespaceService.ListNamespaces(r.Context())
+	if err != nil {
+		h.writeAPIError(w, err, "list namespaces")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, namespaces)
+}
+
+// CreateNamespaceHandler creates a namespace owned by the requesting user
+func (h *Handler) CreateNamespaceHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.NamespaceRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID := h.getUserID(r)
+	namespace, err := h.namespaceService.CreateNamespace(r.Context(), req, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "create namespace")
+		return
+	}
+
+	h.logger.Info("namespace created", "name", namespace.Name, "user", userID)
+
+	w.Header().Set("Location", h.config.BaseURL+"/api/v1/namespaces/"+url.PathEscape(namespace.Name))
+	writeJSON(w, http.StatusCreated, namespace)
+}
+
+// GetNamespaceHandler returns a namespace with its members
+func (h *Handler) GetNamespaceHandler(w http.ResponseWriter, r *http.Request) {
+	namespace, err := h.namespaceService.GetNamespace(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		h.writeAPIError(w, err, "get namespace")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, namespace)
+}
+
+// DeleteNamespaceHandler deletes a namespace that no longer has any links
+func (h *Handler) DeleteNamespaceHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	userID := h.getUserID(r)
+	if err := h.namespaceService.DeleteNamespace(r.Context(), name, userID); err != nil {
+		h.writeAPIError(w, err, "delete namespace")
+		return
+	}
+
+	h.logger.Info("namespace deleted", "name", name, "user", userID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetNamespaceMemberHandler adds a user to a namespace or changes their role in it
+func (h *Handler) SetNamespaceMemberHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.NamespaceMemberRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	userID := h.getUserID(r)
+	member, err := h.namespaceService.SetMember(r.Context(), vars["name"], vars["user"], req, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "set namespace member")
+		return
+	}
+
+	h.logger.Info("namespace member set", "name", vars["name"], "for", member.User, "role", member.Role, "user", userID)
+
+	writeJSON(w, http.StatusOK, member)
+}
+
+// RemoveNamespaceMemberHandler takes a user out of a namespace
+func (h *Handler) RemoveNamespaceMemberHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := h.getUserID(r)
+	if err := h.namespaceService.RemoveMember(r.Context(), vars["name"], vars["user"], userID); err != nil {
+		h.writeAPIError(w, err, "remove namespace member")
+		return
+	}
+
+	h.logger.Info("namespace member removed", "name", vars["name"], "for", vars["user"], "user", userID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// mockNamespaceService lets anyone manage the namespaces they created
+type mockNamespaceService struct {
+	namespaces map[string]*domain.Namespace
+}
+
+func newMockNamespaceService() *mockNamespaceService {
+	return &mockNamespaceService{namespaces: map[string]*domain.Namespace{}}
+}
+
+func (m *mockNamespaceService) ListNamespaces(ctx context.Context) ([]domain.Namespace, error) {
+	namespaces := []domain.Namespace{}
+	for _, namespace := range m.namespaces {
+		namespaces = append(namespaces, *namespace)
+	}
+	return namespaces, nil
+}
+
+func (m *mockNamespaceService) GetNamespace(ctx context.Context, name string) (*domain.Namespace, error) {
+	namespace, ok := m.namespaces[name]
+	if !ok {
+		return nil, service.NotFoundError{Message: "no namespace"}
+	}
+	return namespace, nil
+}
+
+func (m *mockNamespaceService) CreateNamespace(ctx context.Context, req domain.NamespaceRequest, requester string) (*domain.Namespace, error) {
+	if req.Name == "" {
+		return nil, service.InvalidQueryError{Message: "name required"}
+	}
+	if _, ok := m.namespaces[req.Name]; ok {
+		return nil, service.InvalidQueryError{Message: "exists"}
+	}
+	namespace := &domain.Namespace{Name: req.Name, CreatedBy: requester, Members: []domain.NamespaceMember{
+		{User: requester, Role: domain.NamespaceOwnerRole},
+	}}
+	m.namespaces[req.Name] = namespace
+	return namespace, nil
+}
+
+func (m *mockNamespaceService) DeleteNamespace(ctx context.Context, name, requester string) error {
+	namespace, err := m.owned(name, requester)
+	if err != nil {
+		return err
+	}
+	delete(m.namespaces, namespace.Name)
+	return nil
+}
+
+func (m *mockNamespaceService) SetMember(
+	ctx context.Context, name, user string, req domain.NamespaceMemberRequest, requester string,
+) (*domain.NamespaceMember, error) {
+	namespace, err := m.owned(name, requester)
+	if err != nil {
+		return nil, err
+	}
+	if req.Role == "" {
+		req.Role = domain.NamespaceMemberRole
+	}
+	if !req.Role.Valid() {
+		return nil, service.InvalidQueryError{Message: "unknown role"}
+	}
+	member := domain.NamespaceMember{User: user, Role: req.Role}
+	namespace.Members = append(namespace.Members, member)
+	return &member, nil
+}
+
+func (m *mockNamespaceService) RemoveMember(ctx context.Context, name, user, requester string) error {
+	namespace, err := m.owned(name, requester)
+	if err != nil {
+		return err
+	}
+	for i, member := range namespace.Members {
+		if member.User == user {
+			namespace.Members = append(namespace.Members[:i], namespace.Members[i+1:]...)
+			return nil
+		}
+	}
+	return service.NotFoundError{Message: "not a member"}
+}
+
+func (m *mockNamespaceService) owned(name, requester string) (*domain.Namespace, error) {
+	namespace, ok := m.namespaces[name]
+	if !ok {
+		return nil, service.NotFoundError{Message: "no namespace"}
+	}
+	if namespace.CreatedBy != requester {
+		return nil, service.ForbiddenError{Message: "owners only"}
+	}
+	return namespace, nil
+}
+
+func TestHandler_Namespaces(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/v1/namespaces", `{"name": "payments"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /api/v1/namespaces status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Location"); got != "http://localhost:8080/api/v1/namespaces/payments" {
+		t.Errorf("POST /api/v1/namespaces Location = %q", got)
+	}
+	var namespace domain.Namespace
+	if err := json.NewDecoder(w.Body).Decode(&namespace); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if namespace.Name != "payments" || namespace.CreatedBy != "DefaultUser" {
+		t.Errorf("POST /api/v1/namespaces = %+v", namespace)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "list", method: "GET", path: "/api/v1/namespaces", wantStatus: http.StatusOK},
+		{name: "create again", method: "POST", path: "/api/v1/namespaces", body: `{"name": "payments"}`, wantStatus: http.StatusBadRequest},
+		{name: "not json", method: "POST", path: "/api/v1/namespaces", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "get", method: "GET", path: "/api/v1/namespaces/payments", wantStatus: http.StatusOK},
+		{name: "get unknown", method: "GET", path: "/api/v1/namespaces/infra", wantStatus: http.StatusNotFound},
+		{name: "add member", method: "PUT", path: "/api/v1/namespaces/payments/members/bob", body: `{}`, wantStatus: http.StatusOK},
+		{name: "unknown role", method: "PUT", path: "/api/v1/namespaces/payments/members/bob", body: `{"role": "admin"}`, wantStatus: http.StatusBadRequest},
+		{name: "remove member", method: "DELETE", path: "/api/v1/namespaces/payments/members/bob", wantStatus: http.StatusNoContent},
+		{name: "remove non-member", method: "DELETE", path: "/api/v1/namespaces/payments/members/bob", wantStatus: http.StatusNotFound},
+		{name: "wrong method", method: "POST", path: "/api/v1/namespaces/payments", wantStatus: http.StatusMethodNotAllowed},
+		{name: "delete", method: "DELETE", path: "/api/v1/namespaces/payments", wantStatus: http.StatusNoContent},
+		{name: "delete again", method: "DELETE", path: "/api/v1/namespaces/payments", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(tt.method, tt.path, tt.body); w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d, body = %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Namespaces_Forbidden(t *testing.T) {
+	handler := setupTestHandler()
+	handler.namespaceService.(*mockNamespaceService).namespaces["payments"] = &domain.Namespace{Name: "payments", CreatedBy: "alice"}
+	handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": domain.RoleViewer}}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"POST", "/api/v1/namespaces", `{"name": "infra"}`},
+		{"DELETE", "/api/v1/namespaces/payments", ""},
+		{"PUT", "/api/v1/namespaces/payments/members/bob", `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestHandler_NamespacedWordRoutes(t *testing.T) {
+	handler := setupTestHandler()
+	handler.linkService.(*mockLinkService).links["payments/runbook"] = "https://runbook.example.com"
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"GET", "/api/v1/links/payments/runbook", http.StatusOK},
+		{"GET", "/api/links/payments/runbook/history", http.StatusOK},
+		{"GET", "/api/v1/links/payments/runbook/old", http.StatusNotFound},
+		{"DELETE", "/api/v1/links/payments/runbook", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d, body = %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// routeDoc describes an API route for the generated OpenAPI spec
+type routeDoc struct {
+	Summary   string
+	Tag       string
+	Body      bool
+	Responses []int
+}
+
+// routeDocs documents API routes by "METHOD path template". Routes missing from this
+// table still appear in the spec with a generic description.
+var routeDocs = map[string]routeDoc{
+	"GET /api/openapi.json": {
+		Summary: "This OpenAPI description", Tag: "docs",
+		Responses: []int{http.StatusOK},
+	},
+	"GET /api/docs": {
+		Summary: "Swagger UI for this API", Tag: "docs",
+		Responses: []int{http.StatusOK},
+	},
+	"GET /api/version": {
+		Summary: "Version, git commit and build date of the running server", Tag: "server",
+		Responses: []int{http.StatusOK},
+	},
+	"GET /api/suggest": {
+		Summary: "Suggest the keywords starting with a prefix (q), for omnibox and extension autocomplete; format=opensearch answers in the OpenSearch suggestions format", Tag: "resolve",
+		Responses: []int{http.StatusOK, http.StatusBadRequest},
+	},
+	"GET /api/launcher/search": {
+		Summary: "Match a launcher query (q) as Alfred script filter items, offering to create a missing keyword", Tag: "resolve",
+		Responses: []int{http.StatusOK, http.StatusBadRequest},
+	},
+	"POST /api/launcher/links": {
+		Summary: "Create a link from a launcher query (q) of a keyword and its link, answering with a script filter item (editors)", Tag: "links",
+		Responses: []int{http.StatusCreated, http.StatusBadRequest, http.StatusForbidden, http.StatusConflict},
+	},
+	"GET /api/triggers/me": {
+		Summary: "Name the user an API key belongs to, for automation services to test a connection (API key only)", Tag: "triggers",
+		Responses: []int{http.StatusOK, http.StatusUnauthorized},
+	},
+	"GET /api/triggers/links/created": {
+		Summary: "List created links newest first, as a Zapier polling trigger, a page of limit after the id given as cursor (API key only)", Tag: "triggers",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusUnauthorized},
+	},
+	"GET /api/triggers/links/deleted": {
+		Summary: "List deleted links newest first, as a Zapier polling trigger, a page of limit after the id given as cursor (API key only)", Tag: "triggers",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusUnauthorized},
+	},
+	"GET /api/resolve/detail": {
+		Summary: "Resolve a query (q) and return the target URL with resolution metadata", Tag: "resolve",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusNotFound},
+	},
+	"POST /api/links/bulk": {
+		Summary: "Create many links in one transaction (editors)", Tag: "links", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden},
+	},
+	"POST /api/links/import": {
+		Summary: "Import links from a CSV file of word,link,owner,tags rows or a Trotto or golinks.io export, or check them with dry_run (editors)", Tag: "links", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden},
+	},
+	"GET /api/links/export": {
+		Summary: "Download every link, or those of a user, tag or namespace, with its owner, dates, tags and optionally history (admins)", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden},
+	},
+	"DELETE /api/links/{word}": {
+		Summary: "Move a keyword and all of its versions to the trash", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/links/{word}/history": {
+		Summary: "List every revision of a keyword", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusNotFound},
+	},
+	"GET /api/links/{word}/stats": {
+		Summary: "Count a keyword's clicks by day or week (interval, days) with its top referrers", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusNotFound},
+	},
+	"GET /api/me/links": {
+		Summary: "List the links you followed most in the last 90 days and the links you own", Tag: "links",
+		Responses: []int{http.StatusOK},
+	},
+	"GET /api/me/favorites": {
+		Summary: "List the links you have pinned, alphabetically", Tag: "links",
+		Responses: []int{http.StatusOK},
+	},
+	"PUT /api/me/favorites/{word}": {
+		Summary: "Pin a keyword to your homepage", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusNotFound},
+	},
+	"DELETE /api/me/favorites/{word}": {
+		Summary: "Unpin a keyword", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusNotFound},
+	},
+	"GET /api/stats/stream": {
+		Summary: "Stream followed links and rolling click counters as Server-Sent Events", Tag: "links",
+		Responses: []int{http.StatusOK},
+	},
+	"POST /api/links/{word}/rollback/{id}": {
+		Summary: "Restore a previous revision of a keyword", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"POST /api/links/{word}/transfer": {
+		Summary: "Hand a keyword over to another user (owner or admin)", Tag: "links", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnsupportedMediaType},
+	},
+	"POST /api/links/{word}/archive": {
+		Summary: "Archive a keyword, so following it explains that it was retired (owner or admin)", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"POST /api/links/{word}/unarchive": {
+		Summary: "Make an archived keyword resolve again (owner or admin)", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"PUT /api/links/{word}/metadata": {
+		Summary: "Replace the metadata of a keyword with a JSON object (owner or admin)", Tag: "links", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnsupportedMediaType},
+	},
+	"POST /api/links/{word}/rename": {
+		Summary: "Move a keyword to a new word, keeping the old one as an alias (owner or admin)", Tag: "links", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnsupportedMediaType},
+	},
+	"GET /api/links/{word}/aliases": {
+		Summary: "List the other words a keyword answers to", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusNotFound},
+	},
+	"PUT /api/links/{word}/aliases/{alias}": {
+		Summary: "Make another word resolve to a keyword (owner or admin)", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"DELETE /api/links/{word}/aliases/{alias}": {
+		Summary: "Stop a word resolving to a keyword (owner or admin)", Tag: "links",
+		Responses: []int{http.StatusOK, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/links/{word}/tags": {
+		Summary: "List a keyword's tags", Tag: "tags",
+		Responses: []int{http.StatusOK, http.StatusNotFound},
+	},
+	"POST /api/links/{word}/tags": {
+		Summary: "Add tags to a keyword (editors)", Tag: "tags", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"DELETE /api/links/{word}/tags/{tag}": {
+		Summary: "Remove a tag from a keyword (editors)", Tag: "tags",
+		Responses: []int{http.StatusOK, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/tags/{tag}": {
+		Summary: "List keywords carrying a tag", Tag: "tags",
+		Responses: []int{http.StatusOK, http.StatusBadRequest},
+	},
+	"GET /api/v1/links": {
+		Summary: "List the keywords visible to the caller a page at a time (limit, offset), optionally searching words, links and owners (q)", Tag: "v1",
+		Responses: []int{http.StatusOK, http.StatusNotModified, http.StatusBadRequest},
+	},
+	"POST /api/v1/links": {
+		Summary: "Create a keyword", Tag: "v1", Body: true,
+		Responses: []int{http.StatusCreated, http.StatusBadRequest, http.StatusForbidden, http.StatusConflict, http.StatusUnsupportedMediaType},
+	},
+	"GET /api/v1/links/{word}": {
+		Summary: "Get the current version of a keyword", Tag: "v1",
+		Responses: []int{http.StatusOK, http.StatusNotFound},
+	},
+	"PUT /api/v1/links/{word}": {
+		Summary: "Create or update a keyword", Tag: "v1", Body: true,
+		Responses: []int{http.StatusOK, http.StatusCreated, http.StatusBadRequest, http.StatusForbidden, http.StatusUnsupportedMediaType},
+	},
+	"DELETE /api/v1/links/{word}": {
+		Summary: "Move a keyword and all of its versions to the trash", Tag: "v1",
+		Responses: []int{http.StatusNoContent, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/v1/queries/popular": {
+		Summary: "Most used keywords over the last days (default 3) days, up to limit (default 20)", Tag: "v1",
+		Responses: []int{http.StatusOK, http.StatusBadRequest},
+	},
+	"GET /api/v1/keys": {
+		Summary: "List API keys without their secrets (admins only)", Tag: "keys",
+		Responses: []int{http.StatusOK, http.StatusForbidden},
+	},
+	"POST /api/v1/keys": {
+		Summary: "Mint an API key for a user; the secret is only returned here (admins only)", Tag: "keys", Body: true,
+		Responses: []int{http.StatusCreated, http.StatusBadRequest, http.StatusForbidden, http.StatusUnsupportedMediaType},
+	},
+	"DELETE /api/v1/keys/{id}": {
+		Summary: "Revoke an API key (admins only)", Tag: "keys",
+		Responses: []int{http.StatusNoContent, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/v1/roles": {
+		Summary: "List the roles assigned to users (admins only)", Tag: "roles",
+		Responses: []int{http.StatusOK, http.StatusForbidden},
+	},
+	"PUT /api/v1/roles/{user}": {
+		Summary: "Give a user the viewer, editor or admin role (admins only)", Tag: "roles", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusUnsupportedMediaType},
+	},
+	"DELETE /api/v1/roles/{user}": {
+		Summary: "Return a user to the default role (admins only)", Tag: "roles",
+		Responses: []int{http.StatusNoContent, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/v1/namespaces": {
+		Summary: "List team namespaces", Tag: "namespaces",
+		Responses: []int{http.StatusOK},
+	},
+	"POST /api/v1/namespaces": {
+		Summary: "Create a team namespace owned by the requesting user", Tag: "namespaces", Body: true,
+		Responses: []int{http.StatusCreated, http.StatusBadRequest, http.StatusForbidden, http.StatusUnsupportedMediaType},
+	},
+	"GET /api/v1/namespaces/{name}": {
+		Summary: "Get a team namespace and its members", Tag: "namespaces",
+		Responses: []int{http.StatusOK, http.StatusNotFound},
+	},
+	"DELETE /api/v1/namespaces/{name}": {
+		Summary: "Delete a team namespace that has no links left (owners and admins)", Tag: "namespaces",
+		Responses: []int{http.StatusNoContent, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"PUT /api/v1/namespaces/{name}/members/{user}": {
+		Summary: "Add a member or owner to a team namespace (owners and admins)", Tag: "namespaces", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnsupportedMediaType},
+	},
+	"DELETE /api/v1/namespaces/{name}/members/{user}": {
+		Summary: "Remove a user from a team namespace; members may remove themselves", Tag: "namespaces",
+		Responses: []int{http.StatusNoContent, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/admin/backups": {
+		Summary: "List database backups, newest first (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden},
+	},
+	"POST /api/admin/backup": {
+		Summary: "Snapshot the database into the backup directory (admins only)", Tag: "admin",
+		Responses: []int{http.StatusCreated, http.StatusBadRequest, http.StatusForbidden},
+	},
+	"POST /api/admin/restore": {
+		Summary: "Replace the database with a named backup (admins only)", Tag: "admin", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnsupportedMediaType},
+	},
+	"GET /api/admin/reports/stale": {
+		Summary: "List links nobody has followed or changed in the last days (default 90) days (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden},
+	},
+	"GET /api/admin/reports/broken": {
+		Summary: "List links whose targets looked broken when last checked, longest broken first (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/admin/cache": {
+		Summary: "Report the size, hits and misses of each in-memory cache (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusForbidden},
+	},
+	"POST /api/admin/queries/prune": {
+		Summary: "Roll queries older than QUERY_RETENTION_DAYS up into daily counts (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden},
+	},
+	"GET /api/admin/loglevel": {
+		Summary: "Report the level the server logs at (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusForbidden, http.StatusNotFound},
+	},
+	"PUT /api/admin/loglevel": {
+		Summary: "Change the level the server logs at until it restarts (admins only)", Tag: "admin", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/admin/domains": {
+		Summary: "Report the domains new links may and may not point to (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusForbidden, http.StatusNotFound},
+	},
+	"PUT /api/admin/domains": {
+		Summary: "Replace the domains new links may and may not point to until the server restarts (admins only)", Tag: "admin", Body: true,
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/admin/users": {
+		Summary: "List every user who owns links or holds a role, with their role and link counts (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusForbidden},
+	},
+	"GET /api/admin/changes": {
+		Summary: "List the limit (default 50, up to 500) keyword versions stored most recently, newest first (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden},
+	},
+	"GET /api/admin/storage": {
+		Summary: "Report the links in use and in the trash, the rows of each table and the database size (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/admin/errors": {
+		Summary: "Count the responses this server sent and how many were errors, over the last minute, hour and since it started (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusForbidden},
+	},
+	"GET /api/admin/trash": {
+		Summary: "List deleted keywords that can still be restored (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusForbidden},
+	},
+	"POST /api/admin/trash/{word}/restore": {
+		Summary: "Restore a deleted keyword with all of its versions (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"DELETE /api/admin/trash/{word}": {
+		Summary: "Permanently remove a deleted keyword (admins only)", Tag: "admin",
+		Responses: []int{http.StatusNoContent, http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/sync/changes": {
+		Summary: "List the keyword versions stored after a cursor, for a replica to pull (admins only)", Tag: "admin",
+		Responses: []int{http.StatusOK, http.StatusBadRequest, http.StatusForbidden},
+	},
+}
+
+// pathVariablePattern matches mux path variables, with an optional regexp
+var pathVariablePattern = regexp.MustCompile(`\{([^}:]+)(?::([^}]*))?\}`)
+
+type openAPISpec struct {
+	OpenAPI string                          `json:"openapi"`
+	Info    openAPIInfo                     `json:"info"`
+	Servers []openAPIServer                 `json:"servers,omitempty"`
+	Paths   map[string]map[string]operation `json:"paths"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type operation struct {
+	Summary     string              `json:"summary"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []parameter         `json:"parameters,omitempty"`
+	RequestBody *requestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name     string            `json:"name"`
+	In       string            `json:"in"`
+	Required bool              `json:"required"`
+	Schema   map[string]string `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]map[string]string `json:"content"`
+}
+
+type response struct {
+	Description string `json:"description"`
+}
+
+// buildOpenAPISpec describes every /api route registered on the router
+func buildOpenAPISpec(router *mux.Router, baseURL string) (*openAPISpec, error) {
+	spec := &openAPISpec{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: "GoLinks API", Version: "1.0.0"},
+		Paths:   map[string]map[string]operation{},
+	}
+	if baseURL != "" {
+		spec.Servers = []openAPIServer{{URL: baseURL}}
+	}
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(template, "/api/") {
+			return nil
+		}
+		// Subrouters and method fallbacks have no methods of their own
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		path, params := openAPIPath(template)
+		for _, method := range methods {
+			if spec.Paths[path] == nil {
+				spec.Paths[path] = map[string]operation{}
+			}
+			spec.Paths[path][strings.ToLower(method)] = newOperation(method, path, params)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// openAPIPath converts a mux path template into an OpenAPI path and its path parameters
+func openAPIPath(template string) (string, []parameter) {
+	var params []parameter
+	path := pathVariablePattern.ReplaceAllStringFunc(template, func(variable string) string {
+		match := pathVariablePattern.FindStringSubmatch(variable)
+		schema := map[string]string{"type": "string"}
+		if match[2] != "" {
+			schema["pattern"] = "^" + match[2] + "$"
+		}
+		params = append(params, parameter{Name: match[1], In: "path", Required: true, Schema: schema})
+		return "{" + match[1] + "}"
+	})
+	return path, params
+}
+
+func newOperation(method, path string, params []parameter) operation {
+	doc, ok := routeDocs[method+" "+path]
+	if !ok {
+		doc = routeDoc{Summary: method + " " + path, Responses: []int{http.StatusOK}}
+	}
+
+	op := operation{
+		Summary:    doc.Summary,
+		Parameters: params,
+		Responses:  map[string]response{},
+	}
+	if doc.Tag != "" {
+		op.Tags = []string{doc.Tag}
+	}
+	if doc.Body {
+		op.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]map[string]string{"application/json": {}},
+		}
+	}
+
+	for _, code := range doc.Responses {
+		op.Responses[strconv.Itoa(code)] = response{Description: http.StatusText(code)}
+	}
+
+	return op
+}
+
+// OpenAPIHandler serves the OpenAPI spec for the routes registered on router. The
+// spec is built on first request, once every route has been registered.
+func (h *Handler) OpenAPIHandler(router *mux.Router) http.HandlerFunc {
+	var (
+		once    sync.Once
+		spec    *openAPISpec
+		specErr error
+	)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			spec, specErr = buildOpenAPISpec(router, h.config.BaseURL)
+		})
+		if specErr != nil {
+			h.logger.Error("Failed to build OpenAPI spec", "err", specErr)
+			writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, spec)
+	}
+}
+
+// APIDocsHandler serves a Swagger UI page for the OpenAPI spec
+func (h *Handler) APIDocsHandler(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		BaseURL string
+	}{
+		BaseURL: h.config.BaseURL,
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "swagger.html", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func Test_openAPIPath(t *testing.T) {
+	tests := []struct {
+		template   string
+		wantPath   string
+		wantParams []string
+	}{
+		{"/api/v1/links", "/api/v1/links", nil},
+		{"/api/links/{word}/tags/{tag}", "/api/links/{word}/tags/{tag}", []string{"word", "tag"}},
+		{"/api/links/{word}/rollback/{id:[0-9]+}", "/api/links/{word}/rollback/{id}", []string{"word", "id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			path, params := openAPIPath(tt.template)
+			if path != tt.wantPath {
+				t.Errorf("openAPIPath() path = %q, want %q", path, tt.wantPath)
+			}
+			var names []string
+			for _, param := range params {
+				names = append(names, param.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantParams) {
+				t.Errorf("openAPIPath() params = %v, want %v", names, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestHandler_OpenAPIHandler(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest("GET", "/api/openapi.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("OpenAPIHandler() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var spec openAPISpec
+	if err := json.NewDecoder(w.Body).Decode(&spec); err != nil {
+		t.Fatalf("Failed to decode spec: %v", err)
+	}
+
+	if spec.OpenAPI != "3.0.3" {
+		t.Errorf("OpenAPIHandler() openapi = %q", spec.OpenAPI)
+	}
+
+	// Every registered API route must be described, with documented routes fully covered
+	for key, doc := range routeDocs {
+		method, path, _ := strings.Cut(key, " ")
+		op, ok := spec.Paths[path][strings.ToLower(method)]
+		if !ok {
+			t.Errorf("OpenAPIHandler() missing %s", key)
+			continue
+		}
+		if op.Summary != doc.Summary || len(op.Responses) != len(doc.Responses) {
+			t.Errorf("OpenAPIHandler() %s = %+v, want %+v", key, op, doc)
+		}
+	}
+
+	rollback := spec.Paths["/api/links/{word}/rollback/{id}"]["post"]
+	if len(rollback.Parameters) != 2 || rollback.Parameters[1].Schema["pattern"] != "^[0-9]+$" {
+		t.Errorf("OpenAPIHandler() rollback parameters = %+v", rollback.Parameters)
+	}
+	if _, ok := spec.Paths["/query/{path}"]; ok {
+		t.Error("OpenAPIHandler() should only describe /api routes")
+	}
+}
+
+func TestHandler_APIDocsHandler(t *testing.T) {
+	handler := setupTestHandler()
+
+	req := httptest.NewRequest("GET", "/api/docs", nil)
+	w := httptest.NewRecorder()
+	handler.APIDocsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("APIDocsHandler() status = %v, want %v", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), "http://localhost:8080/api/openapi.json") {
+		t.Errorf("APIDocsHandler() body missing spec URL: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+// openSearchDescription is an OpenSearch 1.1 description document, which lets browsers
+// add golinks as a search engine
+type openSearchDescription struct {
+	XMLName       xml.Name        `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName     string          `xml:"ShortName"`
+	Description   string          `xml:"Description"`
+	InputEncoding string          `xml:"InputEncoding"`
+	Image         openSearchImage `xml:"Image"`
+	URLs          []openSearchURL `xml:"Url"`
+}
+
+type openSearchImage struct {
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Type   string `xml:"type,attr"`
+	URL    string `xml:",chardata"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Method   string `xml:"method,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// OpenSearchHandler serves the OpenSearch description of /search, with keyword
+// suggestions from /api/suggest, for browsers to offer golinks as a search engine
+func (h *Handler) OpenSearchHandler(w http.ResponseWriter, r *http.Request) {
+	description := openSearchDescription{
+		ShortName:     "golinks",
+		Description:   "Follow golinks, like go docs",
+		InputEncoding: "UTF-8",
+		Image:         openSearchImage{Width: 16, Height: 16, Type: "image/x-icon", URL: h.config.BaseURL + "/static/favicon.ico"},
+		URLs: []openSearchURL{
+			{Type: "text/html", Method: "get", Template: h.config.BaseURL + "/search?q={searchTerms}"},
+			{
+				Type:     "application/x-suggestions+json",
+				Method:   "get",
+				Template: h.config.BaseURL + "/api/suggest?format=opensearch&q={searchTerms}",
+			},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/opensearchdescription+xml")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(description); err != nil {
+		h.logger.Error("Failed to write OpenSearch description", "err", err)
+	}
+}
+
+// SearchHandler follows a query typed into the browser's address bar with golinks as its
+// search engine, such as "docs" or "go wiki onboarding". A leading "go" or "go/", which
+// users type out of habit, is dropped.
+func (h *Handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	query := searchQuery(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Redirect(w, r, h.config.BaseURL+"/homepage/", http.StatusFound)
+		return
+	}
+
+	h.followQuery(w, r, query, nil)
+}
+
+// searchQuery trims a query typed into the address bar down to the golink query, without
+// the go prefix
+func searchQuery(q string) string {
+	q = strings.TrimSpace(q)
+	if rest, ok := strings.CutPrefix(q, "go/"); ok {
+		return strings.TrimSpace(rest)
+	}
+	if fields := strings.Fields(q); len(fields) > 1 && fields[0] == "go" {
+		return strings.TrimSpace(strings.TrimPrefix(q, "go"))
+	}
+	return q
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_OpenSearch(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest("GET", "/opensearch.xml", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /opensearch.xml status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/opensearchdescription+xml" {
+		t.Errorf("GET /opensearch.xml Content-Type = %q", contentType)
+	}
+
+	var description openSearchDescription
+	if err := xml.NewDecoder(w.Body).Decode(&description); err != nil {
+		t.Fatalf("Failed to decode OpenSearch description: %v", err)
+	}
+	templates := map[string]string{}
+	for _, u := range description.URLs {
+		templates[u.Type] = u.Template
+	}
+	if got, want := templates["text/html"], "http://localhost:8080/search?q={searchTerms}"; got != want {
+		t.Errorf("search template = %q, want %q", got, want)
+	}
+	if got, want := templates["application/x-suggestions+json"],
+		"http://localhost:8080/api/suggest?format=opensearch&q={searchTerms}"; got != want {
+		t.Errorf("suggestions template = %q, want %q", got, want)
+	}
+}
+
+func TestHandler_Search(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name     string
+		q        string
+		location string
+	}{
+		{"keyword", "docs", "https://docs.example.com"},
+		{"go prefix", "go docs", "https://docs.example.com"},
+		{"go slash prefix", "go/docs", "https://docs.example.com"},
+		{"missing keyword", "nothing here", "http://localhost:8080/homepage/?missing=nothing+here"},
+		{"just go", "go", "http://localhost:8080/homepage/?missing=go"},
+		{"empty", " ", "http://localhost:8080/homepage/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/search?q="+url.QueryEscape(tt.q), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusFound {
+				t.Fatalf("GET /search?q=%s status = %d, want %d", tt.q, w.Code, http.StatusFound)
+			}
+			if location := w.Header().Get("Location"); location != tt.location {
+				t.Errorf("GET /search?q=%s Location = %q, want %q", tt.q, location, tt.location)
+			}
+		})
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		q    string
+		want string
+	}{
+		{"docs", "docs"},
+		{"  wiki onboarding ", "wiki onboarding"},
+		{"go wiki onboarding", "wiki onboarding"},
+		{"go/wiki", "wiki"},
+		{"go", "go"},
+		{"gopher", "gopher"},
+		{"google maps", "google maps"},
+	}
+
+	for _, tt := range tests {
+		if got := searchQuery(tt.q); got != tt.want {
+			t.Errorf("searchQuery(%q) = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// RoleService interface for role operations
+type RoleService interface {
+	RoleOf(ctx context.Context, user string) (domain.Role, error)
+	ListRoles(ctx context.Context, requester string) ([]domain.UserRole, error)
+	SetRole(ctx context.Context, user string, req domain.RoleRequest, requester string) (*domain.UserRole, error)
+	ResetRole(ctx context.Context, user string, requester string) error
+}
+
+// requireRole wraps a handler so that only users holding at least role can use it
+func (h *Handler) requireRole(role domain.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := h.getUserID(r)
+		has, err := h.roleService.RoleOf(r.Context(), userID)
+		if err != nil {
+			h.writeAPIError(w, err, "get role")
+			return
+		}
+		if !has.Includes(role) {
+			h.logger.Info("forbidden", "path", r.URL.Path, "user", userID, "role", has, "needs", role)
+			h.writeAPIError(w, service.ForbiddenError{Message: "This needs the " + string(role) + " role"}, "check role")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// canEdit reports whether the requesting user may create and change golinks
+func (h *Handler) canEdit(r *http.Request) bool {
+	role, err := h.roleService.RoleOf(r.Context(), h.getUserID(r))
+	if err != nil {
+		h.logger.Error("Failed to get role", "err", err)
+		return false
+	}
+	return role.Includes(domain.RoleEditor)
+}
+
+// ListRolesHandler lists every assigned role
+func (h *Handler) ListRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roleService.ListRoles(r.Context(), h.getUserID(r))
+	if err != nil {
+		h.writeAPIError(w, err, "list roles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// SetRoleHandler assigns a role to a user
+func (h *Handler) SetRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.RoleRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID := h.getUserID(r)
+	userRole, err := h.roleService.SetRole(r.Context(), mux.Vars(r)["user"], req, userID)
+	if err != nil {
+		h.writeAPIError(w, err, "set role")
+		return
+	}
+
+	h.logger.Info("role set", "for", userRole.User, "role", userRole.Role, "user", userID)
+
+	writeJSON(w, http.StatusOK, userRole)
+}
+
+// ResetRoleHandler removes a user's assigned role, returning them to the default role
+func (h *Handler) ResetRoleHandler(w http.ResponseWriter, r *http.Request) {
+	user := mux.Vars(r)["user"]
+	userID := h.getUserID(r)
+	if err := h.roleService.ResetRole(r.Context(), user, userID); err != nil {
+		h.writeAPIError(w, err, "reset role")
+		return
+	}
+
+	h.logger.Info("role reset", "for", user, "user", userID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
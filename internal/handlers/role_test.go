@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// mockRoleService makes DefaultUser an admin and everyone else an editor unless assigned
+type mockRoleService struct {
+	roles map[string]domain.Role
+}
+
+func newMockRoleService() *mockRoleService {
+	return &mockRoleService{roles: map[string]domain.Role{"DefaultUser": domain.RoleAdmin}}
+}
+
+func (m *mockRoleService) RoleOf(ctx context.Context, user string) (domain.Role, error) {
+	if role, ok := m.roles[user]; ok {
+		return role, nil
+	}
+	return domain.RoleEditor, nil
+}
+
+func (m *mockRoleService) ListRoles(ctx context.Context, requester string) ([]domain.UserRole, error) {
+	if m.roles[requester] != domain.RoleAdmin {
+		return nil, service.ForbiddenError{Message: "admins only"}
+	}
+	roles := []domain.UserRole{}
+	for user, role := range m.roles {
+		roles = append(roles, domain.UserRole{User: user, Role: role})
+	}
+	return roles, nil
+}
+
+func (m *mockRoleService) SetRole(ctx context.Context, user string, req domain.RoleRequest, requester string) (*domain.UserRole, error) {
+	if m.roles[requester] != domain.RoleAdmin {
+		return nil, service.ForbiddenError{Message: "admins only"}
+	}
+	if !req.Role.Valid() {
+		return nil, service.InvalidQueryError{Message: "unknown role"}
+	}
+	m.roles[user] = req.Role
+	return &domain.UserRole{User: user, Role: req.Role}, nil
+}
+
+func (m *mockRoleService) ResetRole(ctx context.Context, user string, requester string) error {
+	if m.roles[requester] != domain.RoleAdmin {
+		return service.ForbiddenError{Message: "admins only"}
+	}
+	if _, ok := m.roles[user]; !ok {
+		return service.NotFoundError{Message: "no role"}
+	}
+	delete(m.roles, user)
+	return nil
+}
+
+func TestHandler_RequireRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       domain.Role
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "viewer follows links", role: domain.RoleViewer, method: "GET", path: "/query/docs", wantStatus: http.StatusFound},
+		{name: "viewer lists links", role: domain.RoleViewer, method: "GET", path: "/api/v1/links", wantStatus: http.StatusOK},
+		{name: "viewer cannot create", role: domain.RoleViewer, method: "POST", path: "/api/v1/links", body: `{"word": "new", "link": "https://example.com"}`, wantStatus: http.StatusForbidden},
+		{name: "viewer cannot delete", role: domain.RoleViewer, method: "DELETE", path: "/api/v1/links/docs", wantStatus: http.StatusForbidden},
+		{name: "viewer cannot tag", role: domain.RoleViewer, method: "POST", path: "/api/links/docs/tags", body: `{"tags": ["x"]}`, wantStatus: http.StatusForbidden},
+		{name: "editor creates", role: domain.RoleEditor, method: "POST", path: "/api/v1/links", body: `{"word": "new", "link": "https://example.com"}`, wantStatus: http.StatusCreated},
+		{name: "editor cannot assign roles", role: domain.RoleEditor, method: "PUT", path: "/api/v1/roles/bob", body: `{"role": "admin"}`, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d, body = %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Roles(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("PUT", "/api/v1/roles/alice", `{"role": "viewer"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT /api/v1/roles/alice status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var userRole domain.UserRole
+	if err := json.NewDecoder(w.Body).Decode(&userRole); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if userRole.User != "alice" || userRole.Role != domain.RoleViewer {
+		t.Errorf("PUT /api/v1/roles/alice = %+v", userRole)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "list", method: "GET", path: "/api/v1/roles", wantStatus: http.StatusOK},
+		{name: "unknown role", method: "PUT", path: "/api/v1/roles/bob", body: `{"role": "owner"}`, wantStatus: http.StatusBadRequest},
+		{name: "not json", method: "PUT", path: "/api/v1/roles/bob", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "reset", method: "DELETE", path: "/api/v1/roles/alice", wantStatus: http.StatusNoContent},
+		{name: "reset again", method: "DELETE", path: "/api/v1/roles/alice", wantStatus: http.StatusNotFound},
+		{name: "wrong method", method: "POST", path: "/api/v1/roles", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(tt.method, tt.path, tt.body); w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d, body = %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_HomepageHidesFormFromViewers(t *testing.T) {
+	tests := []struct {
+		role     domain.Role
+		wantForm bool
+	}{
+		{domain.RoleViewer, false},
+		{domain.RoleEditor, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+
+			w := httptest.NewRecorder()
+			handler.HomepageHandler(w, httptest.NewRequest("GET", "/homepage/", nil))
+
+			if got := strings.Contains(w.Body.String(), `id="linkForm"`); got != tt.wantForm {
+				t.Errorf("HomepageHandler() shows form = %v for a %s, want %v", got, tt.role, tt.wantForm)
+			}
+		})
+	}
+}
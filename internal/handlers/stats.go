@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// statsBucket is a row of the stats page, with its bar's width as a percentage of the
+// busiest bucket
+type statsBucket struct {
+	domain.ClickCount
+	Percent int
+}
+
+// LinkStatsHandler reports how often a golink was followed, bucketed by the interval
+// parameter (day or week) over the last days parameter days, with its top referrers
+func (h *Handler) LinkStatsHandler(w http.ResponseWriter, r *http.Request) {
+	days, ok := intQueryParam(w, r, "days")
+	if !ok {
+		return
+	}
+
+	word := mux.Vars(r)["word"]
+	interval := r.URL.Query().Get("interval")
+
+	stats, err := h.linkService.GetLinkStats(r.Context(), word, interval, days, h.getUserID(r))
+	if err != nil {
+		h.writeAPIError(w, err, "get stats for "+word)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// StatsPageHandler renders the click stats of a golink as a page, taking the same
+// parameters as LinkStatsHandler
+func (h *Handler) StatsPageHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+	interval := r.URL.Query().Get("interval")
+
+	days := 0
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid value for days parameter", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	stats, err := h.linkService.GetLinkStats(r.Context(), word, interval, days, h.getUserID(r))
+	if err != nil {
+		switch err.(type) {
+		case service.InvalidQueryError:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case service.NotFoundError:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			h.logger.Error("Failed to get stats", "word", word, "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Bars are drawn relative to the busiest bucket
+	peak := 0
+	for _, click := range stats.Clicks {
+		if click.Count > peak {
+			peak = click.Count
+		}
+	}
+	buckets := make([]statsBucket, len(stats.Clicks))
+	for i, click := range stats.Clicks {
+		buckets[i] = statsBucket{ClickCount: click}
+		if peak > 0 {
+			buckets[i].Percent = click.Count * 100 / peak
+		}
+	}
+
+	data := struct {
+		BaseURL string
+		Stats   *domain.LinkStats
+		Buckets []statsBucket
+	}{
+		BaseURL: h.config.BaseURL,
+		Stats:   stats,
+		Buckets: buckets,
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := h.templates.ExecuteTemplate(w, "stats.html", data); err != nil {
+		h.logger.Error("Failed to execute template", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// UserLinksHandler reports the links the requesting user follows most and the ones they own
+func (h *Handler) UserLinksHandler(w http.ResponseWriter, r *http.Request) {
+	userID := h.getUserID(r)
+
+	links, err := h.linkService.GetUserLinks(r.Context(), userID)
+	if err != nil {
+		h.writeAPIError(w, err, "get links for "+userID)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, links)
+}
+
+// StaleLinksHandler reports the golinks nobody has followed or changed in the last days
+// parameter days, with their owners, for cleanup campaigns
+func (h *Handler) StaleLinksHandler(w http.ResponseWriter, r *http.Request) {
+	days, ok := intQueryParam(w, r, "days")
+	if !ok {
+		return
+	}
+
+	report, err := h.linkService.StaleLinks(r.Context(), days)
+	if err != nil {
+		h.writeAPIError(w, err, "get stale links")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// CacheStatsHandler reports the size and hit rate of each in-memory cache
+func (h *Handler) CacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.linkService.CacheStats())
+}
+
+// PruneQueriesHandler rolls queries older than the retention period up into daily counts
+// now, rather than waiting for the background job
+func (h *Handler) PruneQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	prune, err := h.linkService.PruneQueries(r.Context())
+	if err != nil {
+		h.writeAPIError(w, err, "prune queries")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, prune)
+}
+
+// referrerHost returns the host of the web page a request's Referer header names, or "" if
+// there is none
+func referrerHost(r *http.Request) string {
+	referer := r.Header.Get("Referer")
+	if referer == "" {
+		return ""
+	}
+
+	u, err := url.Parse(referer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// cliAgents are User-Agent prefixes of command-line tools and HTTP libraries, lowercased
+var cliAgents = []string{
+	"curl/", "wget/", "httpie/", "python-requests/", "python-urllib/", "go-http-client/",
+	"powershell/", "libwww-perl/", "okhttp/", "node-fetch/", "axios/",
+}
+
+// clientType classifies what sent a request: a browser extension, a browser, a
+// command-line tool, or something else. Requests without a User-Agent are unclassified.
+func clientType(r *http.Request) string {
+	// Extensions send browser User-Agents, but their pages have their own URL schemes
+	for _, header := range []string{"Origin", "Referer"} {
+		value := strings.ToLower(r.Header.Get(header))
+		if strings.HasPrefix(value, "chrome-extension://") || strings.HasPrefix(value, "moz-extension://") ||
+			strings.HasPrefix(value, "safari-web-extension://") {
+			return domain.ClientExtension
+		}
+	}
+
+	agent := strings.ToLower(r.UserAgent())
+	switch {
+	case agent == "":
+		return ""
+	case strings.HasPrefix(agent, "mozilla/"):
+		return domain.ClientBrowser
+	}
+	for _, prefix := range cliAgents {
+		if strings.HasPrefix(agent, prefix) {
+			return domain.ClientCLI
+		}
+	}
+	return domain.ClientOther
+}
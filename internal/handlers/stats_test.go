@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_LinkStats(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"api", "/api/links/docs/stats", http.StatusOK, `"total":6`},
+		{"api by week", "/api/links/docs/stats?interval=week&days=28", http.StatusOK, `"interval":"week"`},
+		{"api bad interval", "/api/links/docs/stats?interval=month", http.StatusBadRequest, "bad interval"},
+		{"api bad days", "/api/links/docs/stats?days=many", http.StatusBadRequest, "days parameter"},
+		{"api missing link", "/api/links/nope/stats", http.StatusNotFound, "not found"},
+		{"page", "/stats/docs", http.StatusOK, "2024-01-02 4 100%"},
+		{"page bad days", "/stats/docs?days=many", http.StatusBadRequest, "days parameter"},
+		{"page missing link", "/stats/nope", http.StatusNotFound, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("GET %s status = %d, want %d: %s", tt.path, w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("GET %s body = %s, want it to contain %q", tt.path, w.Body.String(), tt.expectedBody)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/links/docs/stats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var stats domain.LinkStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil || len(stats.Clicks) != 2 || len(stats.Referrers) != 1 {
+		t.Errorf("GET /api/links/docs/stats = %+v, %v, want two buckets and a referrer", stats, err)
+	}
+}
+
+func TestHandler_RedirectHandler_Referrer(t *testing.T) {
+	shortcutRepo := &memoryShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}
+
+	tests := []struct {
+		name       string
+		referer    string
+		accept     string
+		agent      string
+		want       string
+		wantClient string
+	}{
+		{"no referer", "", "", "", "", ""},
+		{"page referer", "https://Wiki.example.com/page?id=1", "", "Mozilla/5.0", "wiki.example.com", domain.ClientBrowser},
+		{"json client", "https://chat.example.com/", "application/json", "curl/8.4.0", "chat.example.com", domain.ClientCLI},
+		{"malformed referer", "://", "", "", "", ""},
+		{"extension", "chrome-extension://abcdef/popup.html", "", "Mozilla/5.0", "", domain.ClientExtension},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryRepo := &memoryQueryRepository{}
+			handler := setupTestHandler()
+			handler.linkService = service.NewLinkService(shortcutRepo, queryRepo)
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/query/docs", nil)
+			if tt.referer != "" {
+				req.Header.Set("Referer", tt.referer)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			req.Header.Set("User-Agent", tt.agent)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if len(queryRepo.referrers) != 1 || queryRepo.referrers[0] != tt.want {
+				t.Errorf("GET /query/docs logged referrers %q, want [%q]", queryRepo.referrers, tt.want)
+			}
+			if len(queryRepo.clients) != 1 || queryRepo.clients[0] != tt.wantClient {
+				t.Errorf("GET /query/docs logged clients %q, want [%q]", queryRepo.clients, tt.wantClient)
+			}
+		})
+	}
+}
+
+func TestHandler_UserLinks(t *testing.T) {
+	handler, router := setupLoginHandler(t)
+
+	// Before signing in the request is sent to the login page
+	req := httptest.NewRequest("GET", "/api/me/links", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /api/me/links status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	cookies := login(t, router, "/homepage/", "good").Result().Cookies()
+
+	req = httptest.NewRequest("GET", "/api/me/links", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var links domain.UserLinks
+	if err := json.NewDecoder(w.Body).Decode(&links); err != nil || links.User != "alice@example.com" {
+		t.Errorf("GET /api/me/links = %+v, %v, want alice's links", links, err)
+	}
+
+	req = httptest.NewRequest("GET", "/homepage/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "Your Queries: 2") {
+		t.Errorf("signed-in homepage = %s, want the user's most used links", w.Body.String())
+	}
+
+	// Without sign-in everyone shares one user, so the homepage has nothing personal to show
+	handler.sessions = nil
+	req = httptest.NewRequest("GET", "/homepage/", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "Your Queries: 0") {
+		t.Errorf("homepage without sign-in = %s, want no personal links", w.Body.String())
+	}
+}
+
+func TestClientType(t *testing.T) {
+	tests := []struct {
+		name   string
+		agent  string
+		origin string
+		want   string
+	}{
+		{"chrome", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", "", domain.ClientBrowser},
+		{"firefox extension", "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "moz-extension://1234", domain.ClientExtension},
+		{"curl", "curl/8.4.0", "", domain.ClientCLI},
+		{"httpie", "HTTPie/3.2.2", "", domain.ClientCLI},
+		{"go", "Go-http-client/1.1", "", domain.ClientCLI},
+		{"bot", "Slackbot-LinkExpanding 1.0", "", domain.ClientOther},
+		{"no user agent", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/query/docs", nil)
+			req.Header.Set("User-Agent", tt.agent)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if got := clientType(req); got != tt.want {
+				t.Errorf("clientType(%q) = %q, want %q", tt.agent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_StaleLinks(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           domain.Role
+		path           string
+		expectedStatus int
+		expectedDays   int
+	}{
+		{"default window", domain.RoleAdmin, "/api/admin/reports/stale", http.StatusOK, service.DefaultStaleDays},
+		{"custom window", domain.RoleAdmin, "/api/admin/reports/stale?days=30", http.StatusOK, 30},
+		{"malformed days", domain.RoleAdmin, "/api/admin/reports/stale?days=soon", http.StatusBadRequest, 0},
+		{"rejected days", domain.RoleAdmin, "/api/admin/reports/stale?days=-1", http.StatusBadRequest, 0},
+		{"editor", domain.RoleEditor, "/api/admin/reports/stale", http.StatusForbidden, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("GET %s status = %d, want %d: %s", tt.path, w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var report domain.StaleLinkReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil || report.Days != tt.expectedDays || len(report.Links) != 1 {
+				t.Errorf("GET %s = %+v, %v, want %d days and one link", tt.path, report, err, tt.expectedDays)
+			}
+		})
+	}
+}
+
+func TestHandler_PruneQueries(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           domain.Role
+		pruneError     error
+		expectedStatus int
+	}{
+		{"admin", domain.RoleAdmin, nil, http.StatusOK},
+		{"retention off", domain.RoleAdmin, service.InvalidQueryError{Message: "query retention is not configured"}, http.StatusBadRequest},
+		{"editor", domain.RoleEditor, nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			handler.linkService.(*mockLinkService).pruneError = tt.pruneError
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/api/admin/queries/prune", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("POST /api/admin/queries/prune status = %d, want %d: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var prune domain.QueryPrune
+			if err := json.NewDecoder(w.Body).Decode(&prune); err != nil || prune.Pruned != 3 {
+				t.Errorf("POST /api/admin/queries/prune = %+v, %v, want 3 pruned", prune, err)
+			}
+		})
+	}
+}
+
+func TestHandler_CacheStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           domain.Role
+		expectedStatus int
+	}{
+		{"admin", domain.RoleAdmin, http.StatusOK},
+		{"editor", domain.RoleEditor, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/api/admin/cache", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("GET /api/admin/cache status = %d, want %d: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var stats []domain.CacheStats
+			if err := json.NewDecoder(w.Body).Decode(&stats); err != nil || len(stats) != 1 || stats[0].Hits != 2 {
+				t.Errorf("GET /api/admin/cache = %+v, %v, want the links cache", stats, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// statsStreamInterval is how often the live stats stream pushes the rolling counters
+const statsStreamInterval = 5 * time.Second
+
+// StatsStreamHandler pushes live golink activity as Server-Sent Events, for wallboards:
+// a click event for every public golink followed through this server, and a counters
+// event with the rolling click counts when the stream opens and every few seconds after
+func (h *Handler) StatsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	clicks, unsubscribe := h.linkService.SubscribeClicks()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop nginx buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(statsStreamInterval)
+	defer ticker.Stop()
+
+	event, data := "counters", interface{}(h.linkService.ClickCounters())
+	for {
+		if err := writeEvent(w, event, data); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.closing:
+			return
+		case click, ok := <-clicks:
+			if !ok {
+				return
+			}
+			event, data = "click", click
+		case <-ticker.C:
+			event, data = "counters", h.linkService.ClickCounters()
+		}
+	}
+}
+
+// CloseStreams ends every open live stats stream, as http.Server.Shutdown waits for
+// them otherwise. Register it with http.Server.RegisterOnShutdown.
+func (h *Handler) CloseStreams() {
+	h.closingOnce.Do(func() {
+		if h.closing != nil {
+			close(h.closing)
+		}
+	})
+}
+
+// writeEvent writes data as the JSON payload of a Server-Sent Event named event
+func writeEvent(w io.Writer, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// readEvent reads the next Server-Sent Event from r
+func readEvent(t *testing.T, r *bufio.Reader) (event, data string) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading the stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestHandler_StatsStream(t *testing.T) {
+	handler := setupTestHandler()
+	handler.closing = make(chan struct{})
+	clicks := make(chan domain.ClickEvent, 1)
+	handler.linkService.(*mockLinkService).clicks = clicks
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/stats/stream")
+	if err != nil {
+		t.Fatalf("GET /api/stats/stream error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET /api/stats/stream = %d %s, want an event stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	stream := bufio.NewReader(resp.Body)
+
+	// The counters are sent as soon as the stream opens
+	event, data := readEvent(t, stream)
+	var counters domain.ClickCounters
+	if err := json.Unmarshal([]byte(data), &counters); event != "counters" || err != nil || counters.Total != 3 {
+		t.Errorf("first event = %s %s, want the counters", event, data)
+	}
+
+	clicks <- domain.ClickEvent{Word: "docs", Client: domain.ClientCLI, Time: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	event, data = readEvent(t, stream)
+	var click domain.ClickEvent
+	if err := json.Unmarshal([]byte(data), &click); event != "click" || err != nil || click.Word != "docs" || click.Client != domain.ClientCLI {
+		t.Errorf("second event = %s %s, want the click on docs", event, data)
+	}
+
+	// Shutting down ends the stream
+	handler.CloseStreams()
+	handler.CloseStreams()
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(stream)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("reading the rest of the stream: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream stayed open after CloseStreams")
+	}
+}
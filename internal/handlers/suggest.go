@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// suggestFormatOpenSearch answers suggestions in the OpenSearch suggestions format that
+// browsers ask search engines for
+const suggestFormatOpenSearch = "opensearch"
+
+// SuggestHandler offers the keywords starting with what has been typed in q, in a compact
+// shape for browser omnibox and extension autocomplete. Responses vary with the user, as
+// private keywords are only suggested to their owner. With format=opensearch, q is what
+// was typed into the address bar and the answer is an OpenSearch suggestions array.
+func (h *Handler) SuggestHandler(w http.ResponseWriter, r *http.Request) {
+	limit, ok := intQueryParam(w, r, "limit")
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != suggestFormatOpenSearch {
+		writeJSONError(w, http.StatusBadRequest, "Suggestions can only be formatted for opensearch")
+		return
+	}
+	query := r.URL.Query().Get("q")
+	if format == suggestFormatOpenSearch {
+		query = searchQuery(query)
+	}
+
+	suggestions, err := h.linkService.SuggestLinks(r.Context(), query, h.getUserID(r), limit)
+	if err != nil {
+		h.writeAPIError(w, err, "suggest links")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age=10")
+	if format != suggestFormatOpenSearch {
+		writeJSON(w, http.StatusOK, suggestions)
+		return
+	}
+
+	// The query, then the completions, their descriptions and the pages they open
+	words, descriptions, urls := []string{}, []string{}, []string{}
+	for _, suggestion := range suggestions.Suggestions {
+		words = append(words, suggestion.Word)
+		descriptions = append(descriptions, suggestion.Description)
+		urls = append(urls, h.config.BaseURL+"/query/"+suggestion.Word)
+	}
+	w.Header().Set("Content-Type", "application/x-suggestions+json")
+	_ = json.NewEncoder(w).Encode([]interface{}{suggestions.Query, words, descriptions, urls})
+}
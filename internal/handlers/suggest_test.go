@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_Suggest(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedWords  []string
+	}{
+		{"prefix", "?q=gi", http.StatusOK, []string{"github"}},
+		{"no match", "?q=zz", http.StatusOK, []string{}},
+		{"no query", "", http.StatusOK, []string{}},
+		{"malformed limit", "?q=gi&limit=abc", http.StatusBadRequest, nil},
+		{"negative limit", "?q=gi&limit=-1", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/api/suggest"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("GET /api/suggest%s status = %d, want %d: %s", tt.query, w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var suggestions domain.Suggestions
+			if err := json.NewDecoder(w.Body).Decode(&suggestions); err != nil {
+				t.Fatalf("Failed to decode suggestions: %v", err)
+			}
+			words := []string{}
+			for _, suggestion := range suggestions.Suggestions {
+				words = append(words, suggestion.Word)
+			}
+			if len(words) != len(tt.expectedWords) || (len(words) > 0 && words[0] != tt.expectedWords[0]) {
+				t.Errorf("GET /api/suggest%s suggested %v, want %v", tt.query, words, tt.expectedWords)
+			}
+			if cache := w.Header().Get("Cache-Control"); cache != "private, max-age=10" {
+				t.Errorf("Cache-Control = %q, want private caching", cache)
+			}
+		})
+	}
+}
+
+func TestHandler_Suggest_OpenSearch(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest("GET", "/api/suggest?format=opensearch&q=go+gi", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/suggest?format=opensearch status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/x-suggestions+json" {
+		t.Errorf("Content-Type = %q, want application/x-suggestions+json", contentType)
+	}
+	want := `["gi",["github"],["https://github.com"],["http://localhost:8080/query/github"]]` + "\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("GET /api/suggest?format=opensearch = %s, want %s", got, want)
+	}
+
+	req = httptest.NewRequest("GET", "/api/suggest?format=xml&q=gi", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("GET /api/suggest?format=xml status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
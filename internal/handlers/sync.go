@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// SyncChangesHandler serves the link versions stored after the since cursor, a page of
+// up to limit at a time, for a replica to pull. The last page also lists the live and
+// trashed words and every link's tags.
+func (h *Handler) SyncChangesHandler(w http.ResponseWriter, r *http.Request) {
+	since, ok := intQueryParam(w, r, "since")
+	if !ok {
+		return
+	}
+	limit, ok := intQueryParam(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	changes, err := h.linkService.GetChanges(r.Context(), since, limit)
+	if err != nil {
+		h.writeAPIError(w, err, "get changes")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, changes)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_SyncChanges(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		role           domain.Role
+		expectedStatus int
+		expectedSince  int
+	}{
+		{"from the start", "", domain.RoleAdmin, http.StatusOK, 0},
+		{"since a cursor", "?since=12&limit=50", domain.RoleAdmin, http.StatusOK, 12},
+		{"malformed cursor", "?since=abc", domain.RoleAdmin, http.StatusBadRequest, 0},
+		{"negative cursor", "?since=-1", domain.RoleAdmin, http.StatusBadRequest, 0},
+		{"editor", "", domain.RoleEditor, http.StatusForbidden, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": tt.role}}
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+			mockService := handler.linkService.(*mockLinkService)
+
+			req := httptest.NewRequest("GET", "/api/sync/changes"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("GET /api/sync/changes%s status = %d, want %d: %s", tt.query, w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var changes domain.LinkChanges
+			if err := json.NewDecoder(w.Body).Decode(&changes); err != nil {
+				t.Fatalf("Failed to decode changes: %v", err)
+			}
+			if mockService.changesSince != tt.expectedSince {
+				t.Errorf("changes asked since %d, want %d", mockService.changesSince, tt.expectedSince)
+			}
+			if want := []string{"docs", "github"}; !reflect.DeepEqual(changes.Words, want) {
+				t.Errorf("changes words = %v, want %v", changes.Words, want)
+			}
+			if changes.Cursor != tt.expectedSince+2 {
+				t.Errorf("changes cursor = %d, want %d", changes.Cursor, tt.expectedSince+2)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// TagService interface for tag operations
+type TagService interface {
+	AddTags(ctx context.Context, word string, tags []string, userID string) ([]string, error)
+	RemoveTag(ctx context.Context, word, tag, userID string) error
+	GetTags(ctx context.Context, word, userID string) ([]string, error)
+	GetKeywordsByTag(ctx context.Context, tag, userID string) ([]domain.KeywordInfo, error)
+}
+
+// AddTagsHandler adds tags to a golink
+func (h *Handler) AddTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	word := mux.Vars(r)["word"]
+
+	var req domain.TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	tags, err := h.tagService.AddTags(ctx, word, req.Tags, h.getUserID(r))
+	if err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	h.logger.Info("tag", "word", word, "user", h.getUserID(r), "tags", req.Tags)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"word": word, "tags": tags})
+}
+
+// GetTagsHandler lists the tags of a golink
+func (h *Handler) GetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	word := mux.Vars(r)["word"]
+
+	tags, err := h.tagService.GetTags(ctx, word, h.getUserID(r))
+	if err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"word": word, "tags": tags})
+}
+
+// RemoveTagHandler removes a tag from a golink
+func (h *Handler) RemoveTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	word, tag := vars["word"], vars["tag"]
+
+	if err := h.tagService.RemoveTag(ctx, word, tag, h.getUserID(r)); err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	h.logger.Info("untag", "word", word, "user", h.getUserID(r), "tag", tag)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// KeywordsByTagHandler lists the golinks carrying a tag
+func (h *Handler) KeywordsByTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tag := mux.Vars(r)["tag"]
+
+	keywords, err := h.tagService.GetKeywordsByTag(ctx, tag, h.getUserID(r))
+	if err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+	if keywords == nil {
+		keywords = []domain.KeywordInfo{}
+	}
+
+	writeJSON(w, http.StatusOK, keywords)
+}
+
+// writeTagError maps tag service errors to HTTP responses
+func (h *Handler) writeTagError(w http.ResponseWriter, err error) {
+	switch err.(type) {
+	case service.InvalidQueryError:
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	case service.NotFoundError:
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case service.ForbiddenError:
+		writeJSONError(w, http.StatusForbidden, err.Error())
+	default:
+		h.logger.Error("Tag operation failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
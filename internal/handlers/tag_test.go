@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// Mock TagService for testing
+type mockTagService struct {
+	tags map[string][]string
+}
+
+func (m *mockTagService) AddTags(ctx context.Context, word string, tags []string, userID string) ([]string, error) {
+	if word == "nonexistent" {
+		return nil, service.NotFoundError{Message: "not found"}
+	}
+	if word == "locked" {
+		return nil, service.ForbiddenError{Message: "Only alice or an admin can tag locked"}
+	}
+	for _, tag := range tags {
+		if strings.Contains(tag, " ") {
+			return nil, service.InvalidQueryError{Message: "invalid tag"}
+		}
+	}
+	m.tags[word] = append(m.tags[word], tags...)
+	return m.tags[word], nil
+}
+
+func (m *mockTagService) RemoveTag(ctx context.Context, word, tag, userID string) error {
+	for i, existing := range m.tags[word] {
+		if existing == tag {
+			m.tags[word] = append(m.tags[word][:i], m.tags[word][i+1:]...)
+			return nil
+		}
+	}
+	return service.NotFoundError{Message: "not tagged"}
+}
+
+func (m *mockTagService) GetTags(ctx context.Context, word, userID string) ([]string, error) {
+	return m.tags[word], nil
+}
+
+func (m *mockTagService) GetKeywordsByTag(ctx context.Context, tag, userID string) ([]domain.KeywordInfo, error) {
+	var keywords []domain.KeywordInfo
+	for word, tags := range m.tags {
+		for _, existing := range tags {
+			if existing == tag {
+				keywords = append(keywords, domain.KeywordInfo{Word: word, Tags: tags})
+			}
+		}
+	}
+	return keywords, nil
+}
+
+func TestHandler_AddTagsHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+		expectedTags   []string
+	}{
+		{
+			name:           "add tags",
+			path:           "/api/links/docs/tags",
+			body:           `{"tags": ["howto"]}`,
+			expectedStatus: http.StatusOK,
+			expectedTags:   []string{"engineering", "howto"},
+		},
+		{
+			name:           "invalid JSON",
+			path:           "/api/links/docs/tags",
+			body:           `not json`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid tag",
+			path:           "/api/links/docs/tags",
+			body:           `{"tags": ["has space"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown word",
+			path:           "/api/links/nonexistent/tags",
+			body:           `{"tags": ["howto"]}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "someone else's word",
+			path:           "/api/links/locked/tags",
+			body:           `{"tags": ["howto"]}`,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("AddTagsHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Tags []string `json:"tags"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(response.Tags, tt.expectedTags) {
+				t.Errorf("AddTagsHandler() tags = %v, want %v", response.Tags, tt.expectedTags)
+			}
+		})
+	}
+}
+
+func TestHandler_RemoveTagHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"existing tag", "/api/links/docs/tags/engineering", http.StatusOK},
+		{"missing tag", "/api/links/docs/tags/unused", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("DELETE", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("RemoveTagHandler() status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_GetTagsHandler(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest("GET", "/api/links/docs/tags", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetTagsHandler() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Word string   `json:"word"`
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Word != "docs" || !reflect.DeepEqual(response.Tags, []string{"engineering"}) {
+		t.Errorf("GetTagsHandler() = %+v, want docs tagged engineering", response)
+	}
+}
+
+func TestHandler_KeywordsByTagHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		expectedWords int
+	}{
+		{"used tag", "/api/tags/engineering", 1},
+		{"unused tag", "/api/tags/unused", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestHandler()
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("KeywordsByTagHandler() status = %v, want %v", w.Code, http.StatusOK)
+			}
+
+			var keywords []domain.KeywordInfo
+			if err := json.NewDecoder(w.Body).Decode(&keywords); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(keywords) != tt.expectedWords {
+				t.Errorf("KeywordsByTagHandler() returned %d keywords, want %d", len(keywords), tt.expectedWords)
+			}
+		})
+	}
+}
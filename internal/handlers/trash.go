@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// ListTrashHandler lists deleted golinks that can still be restored
+func (h *Handler) ListTrashHandler(w http.ResponseWriter, r *http.Request) {
+	trash, err := h.linkService.ListTrash(r.Context())
+	if err != nil {
+		h.writeAPIError(w, err, "list trash")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, trash)
+}
+
+// RestoreTrashHandler takes a deleted golink back out of the trash
+func (h *Handler) RestoreTrashHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+
+	shortcut, err := h.linkService.RestoreLink(r.Context(), word, h.getUserID(r))
+	if err != nil {
+		h.writeAPIError(w, err, "restore link "+word)
+		return
+	}
+
+	h.logger.Info("restore", "word", word, "user", h.getUserID(r))
+
+	writeJSON(w, http.StatusOK, shortcut)
+}
+
+// PurgeTrashHandler permanently removes a deleted golink from the trash
+func (h *Handler) PurgeTrashHandler(w http.ResponseWriter, r *http.Request) {
+	word := mux.Vars(r)["word"]
+
+	if err := h.linkService.PurgeLink(r.Context(), word); err != nil {
+		h.writeAPIError(w, err, "purge link "+word)
+		return
+	}
+
+	h.logger.Info("purge", "word", word, "user", h.getUserID(r))
+
+	w.WriteHeader(http.StatusNoContent)
+}
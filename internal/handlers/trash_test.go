@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_Trash(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	mockService := handler.linkService.(*mockLinkService)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"delete docs", "DELETE", "/api/links/docs", http.StatusOK},
+		{"delete github", "DELETE", "/api/links/github", http.StatusOK},
+		{"restore", "POST", "/api/admin/trash/docs/restore", http.StatusOK},
+		{"restore a live link", "POST", "/api/admin/trash/docs/restore", http.StatusBadRequest},
+		{"restore a missing link", "POST", "/api/admin/trash/wiki/restore", http.StatusNotFound},
+		{"purge", "DELETE", "/api/admin/trash/github", http.StatusNoContent},
+		{"purge again", "DELETE", "/api/admin/trash/github", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("%s %s status = %d, want %d: %s", tt.method, tt.path, w.Code, tt.expectedStatus, w.Body.String())
+			}
+		})
+	}
+
+	if link := mockService.links["docs"]; link != "https://docs.example.com" {
+		t.Errorf("docs = %q after restoring it, want its link back", link)
+	}
+
+	req := httptest.NewRequest("GET", "/api/admin/trash", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var trash []domain.TrashedLink
+	if err := json.NewDecoder(w.Body).Decode(&trash); err != nil || trash == nil || len(trash) != 0 {
+		t.Errorf("GET /api/admin/trash = %+v, %v, want an empty trash", trash, err)
+	}
+}
+
+func TestHandler_Trash_Forbidden(t *testing.T) {
+	handler := setupTestHandler()
+	handler.roleService = &mockRoleService{roles: map[string]domain.Role{"DefaultUser": domain.RoleEditor}}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	mockService := handler.linkService.(*mockLinkService)
+	mockService.trash = map[string]string{"wiki": "https://wiki.example.com"}
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{"GET", "/api/admin/trash"},
+		{"POST", "/api/admin/trash/wiki/restore"},
+		{"DELETE", "/api/admin/trash/wiki"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, http.StatusForbidden)
+			}
+		})
+	}
+
+	if _, exists := mockService.trash["wiki"]; !exists {
+		t.Error("an editor changed the trash")
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"golinks/internal/domain"
+)
+
+// requireAPIKey turns away requests that don't carry an API key. Automation services act
+// for the user who connected them through a key rather than a browser session.
+func (h *Handler) requireAPIKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(apiKeyUserKey{}).(string); !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSONError(w, http.StatusUnauthorized, "Send an API key as a Bearer token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// TriggerAuthHandler names the user the API key belongs to, which Zapier calls to test a
+// connection and label it
+func (h *Handler) TriggerAuthHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"user": h.getUserID(r)})
+}
+
+// CreatedLinksTriggerHandler lists the golinks created most recently, newest first, as a
+// Zapier polling trigger: a bare array of items Zapier tells apart by id. The limit and
+// cursor parameters page further back, the cursor being the id of the last item seen.
+func (h *Handler) CreatedLinksTriggerHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeTrigger(w, r, "list created links", h.linkService.CreatedLinks)
+}
+
+// DeletedLinksTriggerHandler lists the golinks deleted most recently, newest first, as a
+// Zapier polling trigger, paged like CreatedLinksTriggerHandler
+func (h *Handler) DeletedLinksTriggerHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeTrigger(w, r, "list deleted links", h.linkService.DeletedLinks)
+}
+
+// writeTrigger answers a polling trigger with the page list returns for the API key's
+// user, linking created golinks to where they can be followed
+func (h *Handler) writeTrigger(
+	w http.ResponseWriter, r *http.Request, action string,
+	list func(ctx context.Context, userID, cursor string, limit int) ([]domain.LinkActivity, error),
+) {
+	limit, ok := intQueryParam(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	activity, err := list(r.Context(), h.getUserID(r), r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		h.writeAPIError(w, err, action)
+		return
+	}
+	for i := range activity {
+		if activity[i].Action == domain.LinkCreated {
+			activity[i].URL = h.config.BaseURL + "/query/" + activity[i].Word
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, activity)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golinks/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_Triggers(t *testing.T) {
+	handler := setupTestHandler()
+	mockService := handler.linkService.(*mockLinkService)
+	mockService.trash = map[string]string{"old": "https://old.example.com"}
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantStatus    int
+		wantWords     []string
+		wantURL       string
+	}{
+		{name: "created needs an api key", path: "/api/triggers/links/created", wantStatus: http.StatusUnauthorized},
+		{name: "deleted needs an api key", path: "/api/triggers/links/deleted", wantStatus: http.StatusUnauthorized},
+		{
+			name: "created", path: "/api/triggers/links/created", authorization: "Bearer glk_alice",
+			wantStatus: http.StatusOK, wantWords: []string{"docs", "github"}, wantURL: "http://localhost:8080/query/docs",
+		},
+		{
+			name: "created after cursor", path: "/api/triggers/links/created?cursor=docs&limit=10", authorization: "Bearer glk_alice",
+			wantStatus: http.StatusOK, wantWords: []string{"github"},
+		},
+		{name: "deleted", path: "/api/triggers/links/deleted", authorization: "Bearer glk_alice", wantStatus: http.StatusOK, wantWords: []string{"old"}},
+		{name: "invalid limit", path: "/api/triggers/links/created?limit=-1", authorization: "Bearer glk_alice", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GET %s status = %d, want %d: %s", tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			// Zapier expects a bare array of items
+			var activity []domain.LinkActivity
+			if err := json.Unmarshal(w.Body.Bytes(), &activity); err != nil {
+				t.Fatalf("Failed to decode %s: %v", w.Body.String(), err)
+			}
+			var words []string
+			for _, item := range activity {
+				words = append(words, item.Word)
+				if item.ID == "" {
+					t.Errorf("item %q has no id", item.Word)
+				}
+				if item.Action == domain.LinkDeleted && item.URL != "" {
+					t.Errorf("deleted item %q has URL %q, want none", item.Word, item.URL)
+				}
+			}
+			if len(words) != len(tt.wantWords) {
+				t.Fatalf("GET %s words = %v, want %v", tt.path, words, tt.wantWords)
+			}
+			for i := range words {
+				if words[i] != tt.wantWords[i] {
+					t.Errorf("GET %s words = %v, want %v", tt.path, words, tt.wantWords)
+				}
+			}
+			if tt.wantURL != "" && activity[0].URL != tt.wantURL {
+				t.Errorf("GET %s url = %q, want %q", tt.path, activity[0].URL, tt.wantURL)
+			}
+			if mockService.viewer != "alice" {
+				t.Errorf("listed for %q, want the API key's user alice", mockService.viewer)
+			}
+		})
+	}
+}
+
+func TestHandler_TriggerAuthHandler(t *testing.T) {
+	handler := setupTestHandler()
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	for _, tt := range []struct {
+		authorization string
+		wantStatus    int
+		wantUser      string
+	}{
+		{wantStatus: http.StatusUnauthorized},
+		{authorization: "Bearer glk_alice", wantStatus: http.StatusOK, wantUser: "alice"},
+	} {
+		req := httptest.NewRequest("GET", "/api/triggers/me", nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Fatalf("GET /api/triggers/me status = %d, want %d", w.Code, tt.wantStatus)
+		}
+		var body map[string]string
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		if tt.wantUser != "" && body["user"] != tt.wantUser {
+			t.Errorf("GET /api/triggers/me user = %q, want %q", body["user"], tt.wantUser)
+		}
+	}
+}
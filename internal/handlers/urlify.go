@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+
+	"golinks/internal/service"
+)
+
+// urlify returns the template function showing a stored link, as a clickable link if it
+// is a web URL or uses one of allowedSchemes, and as text otherwise. Links are escaped
+// either way, so quotes or markup in one can't break out of the page.
+func urlify(allowedSchemes []string) func(string) template.HTML {
+	linkable := map[string]bool{"http": true, "https": true}
+	for _, scheme := range allowedSchemes {
+		scheme = strings.ToLower(strings.TrimSpace(scheme))
+		if scheme != "" && !service.IsBlockedScheme(scheme) {
+			linkable[scheme] = true
+		}
+	}
+
+	return func(link string) template.HTML {
+		escaped := template.HTMLEscapeString(link)
+		u, err := url.Parse(link)
+		if err != nil || !linkable[strings.ToLower(u.Scheme)] {
+			return template.HTML(escaped)
+		}
+		if (u.Scheme == "http" || u.Scheme == "https") && u.Host == "" {
+			return template.HTML(escaped)
+		}
+		return template.HTML(`<a href="` + escaped + `">` + escaped + `</a>`)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"html/template"
+	"testing"
+)
+
+func TestUrlify(t *testing.T) {
+	render := urlify([]string{"slack", " Zoommtg ", "javascript"})
+
+	tests := []struct {
+		name string
+		link string
+		want template.HTML
+	}{
+		{"web URL", "https://example.com/a?b=1&c=2", `<a href="https://example.com/a?b=1&amp;c=2">https://example.com/a?b=1&amp;c=2</a>`},
+		{"quotes can't leave the attribute", `https://example.com/"onmouseover="alert(1)`, `<a href="https://example.com/&#34;onmouseover=&#34;alert(1)">https://example.com/&#34;onmouseover=&#34;alert(1)</a>`},
+		{"markup in an alias", "<script>alert(1)</script>", "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{"plain alias", "docs", "docs"},
+		{"allowed scheme", "slack://channel?id=C1", `<a href="slack://channel?id=C1">slack://channel?id=C1</a>`},
+		{"allowed scheme in another case", "zoommtg://zoom.us/join", `<a href="zoommtg://zoom.us/join">zoommtg://zoom.us/join</a>`},
+		{"blocked scheme even if configured", "javascript:alert(1)", "javascript:alert(1)"},
+		{"unknown scheme", "ftp://files.example.com", "ftp://files.example.com"},
+		{"web URL without a host", "http:///etc/passwd", "http:///etc/passwd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(tt.link); got != tt.want {
+				t.Errorf("urlify(%q) = %s, want %s", tt.link, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package logger builds the structured logger the server writes its logs with.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats a logger can write
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config selects how much is logged and how
+type Config struct {
+	// Level is the least severe level written: debug, info, warn or error
+	Level string
+
+	// Format is text for key=value lines or json for one JSON object per line
+	Format string
+
+	// LevelVar, when set, holds the level so it can be changed while the logger is in use.
+	// New sets it to Level.
+	LevelVar *slog.LevelVar
+}
+
+// ParseLevel parses a level name such as debug or WARN
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("log level must be debug, info, warn or error, not %q", name)
+	}
+	return level, nil
+}
+
+// New returns a logger writing records to w as cfg describes. An empty level is info and
+// an empty format is text.
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	if cfg.Level != "" {
+		var err error
+		if level, err = ParseLevel(cfg.Level); err != nil {
+			return nil, err
+		}
+	}
+	options := &slog.HandlerOptions{Level: level}
+	if cfg.LevelVar != nil {
+		cfg.LevelVar.Set(level)
+		options.Level = cfg.LevelVar
+	}
+
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("log format must be %s or %s, not %q", FormatText, FormatJSON, cfg.Format)
+	}
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    string
+		wantErr string
+	}{
+		{name: "defaults", cfg: Config{}, want: `level=INFO msg="link updated" word=docs user=alice`},
+		{name: "text", cfg: Config{Format: "text", Level: "info"}, want: `msg="link updated" word=docs`},
+		{name: "json", cfg: Config{Format: "JSON"}, want: `"msg":"link updated","word":"docs","user":"alice"`},
+		{name: "above the level", cfg: Config{Level: "warn"}, want: ""},
+		{name: "unknown format", cfg: Config{Format: "logfmt"}, wantErr: "log format must be text or json"},
+		{name: "unknown level", cfg: Config{Level: "verbose"}, wantErr: "log level must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log, err := New(&buf, tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("New(%+v) error = %v, want %q", tt.cfg, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(%+v) error = %v", tt.cfg, err)
+			}
+
+			log.Info("link updated", "word", "docs", "user", "alice")
+			if got := buf.String(); (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("New(%+v) logged %q, want %q", tt.cfg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_JSONAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(&buf, Config{Format: FormatJSON, Level: "debug"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	log.Debug("bulk", "user", "alice", "created", 3)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("logged %q, not JSON: %v", buf.String(), err)
+	}
+	if record["level"] != "DEBUG" || record["user"] != "alice" || record["created"] != float64(3) {
+		t.Errorf("logged %v, want the level and attributes as fields", record)
+	}
+}
+
+func TestNew_LevelVar(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	log, err := New(&buf, Config{Level: "warn", LevelVar: level})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if level.Level() != slog.LevelWarn {
+		t.Errorf("LevelVar = %v, want it set to %v", level.Level(), slog.LevelWarn)
+	}
+
+	log.Info("before")
+	level.Set(slog.LevelDebug)
+	log.Debug("after")
+	if got := buf.String(); strings.Contains(got, "before") || !strings.Contains(got, "after") {
+		t.Errorf("logged %q, want only the record after lowering the level", got)
+	}
+}
@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: golinks/v1/golinks.proto
+
+package golinksv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GoLinks_GetLink_FullMethodName        = "/golinks.v1.GoLinks/GetLink"
+	GoLinks_UpdateLink_FullMethodName     = "/golinks.v1.GoLinks/UpdateLink"
+	GoLinks_ListKeywords_FullMethodName   = "/golinks.v1.GoLinks/ListKeywords"
+	GoLinks_PopularQueries_FullMethodName = "/golinks.v1.GoLinks/PopularQueries"
+)
+
+// GoLinksClient is the client API for GoLinks service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GoLinks resolves and manages golinks for other internal services.
+type GoLinksClient interface {
+	// GetLink resolves a query such as "github golinks" to its target URL.
+	GetLink(ctx context.Context, in *GetLinkRequest, opts ...grpc.CallOption) (*GetLinkResponse, error)
+	// UpdateLink creates a golink or adds a new version of one.
+	UpdateLink(ctx context.Context, in *UpdateLinkRequest, opts ...grpc.CallOption) (*Link, error)
+	// ListKeywords returns every golink.
+	ListKeywords(ctx context.Context, in *ListKeywordsRequest, opts ...grpc.CallOption) (*ListKeywordsResponse, error)
+	// PopularQueries returns the most used golinks over the last few days.
+	PopularQueries(ctx context.Context, in *PopularQueriesRequest, opts ...grpc.CallOption) (*PopularQueriesResponse, error)
+}
+
+type goLinksClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGoLinksClient(cc grpc.ClientConnInterface) GoLinksClient {
+	return &goLinksClient{cc}
+}
+
+func (c *goLinksClient) GetLink(ctx context.Context, in *GetLinkRequest, opts ...grpc.CallOption) (*GetLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLinkResponse)
+	err := c.cc.Invoke(ctx, GoLinks_GetLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goLinksClient) UpdateLink(ctx context.Context, in *UpdateLinkRequest, opts ...grpc.CallOption) (*Link, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Link)
+	err := c.cc.Invoke(ctx, GoLinks_UpdateLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goLinksClient) ListKeywords(ctx context.Context, in *ListKeywordsRequest, opts ...grpc.CallOption) (*ListKeywordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListKeywordsResponse)
+	err := c.cc.Invoke(ctx, GoLinks_ListKeywords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goLinksClient) PopularQueries(ctx context.Context, in *PopularQueriesRequest, opts ...grpc.CallOption) (*PopularQueriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PopularQueriesResponse)
+	err := c.cc.Invoke(ctx, GoLinks_PopularQueries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GoLinksServer is the server API for GoLinks service.
+// All implementations must embed UnimplementedGoLinksServer
+// for forward compatibility.
+//
+// GoLinks resolves and manages golinks for other internal services.
+type GoLinksServer interface {
+	// GetLink resolves a query such as "github golinks" to its target URL.
+	GetLink(context.Context, *GetLinkRequest) (*GetLinkResponse, error)
+	// UpdateLink creates a golink or adds a new version of one.
+	UpdateLink(context.Context, *UpdateLinkRequest) (*Link, error)
+	// ListKeywords returns every golink.
+	ListKeywords(context.Context, *ListKeywordsRequest) (*ListKeywordsResponse, error)
+	// PopularQueries returns the most used golinks over the last few days.
+	PopularQueries(context.Context, *PopularQueriesRequest) (*PopularQueriesResponse, error)
+	mustEmbedUnimplementedGoLinksServer()
+}
+
+// UnimplementedGoLinksServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGoLinksServer struct{}
+
+func (UnimplementedGoLinksServer) GetLink(context.Context, *GetLinkRequest) (*GetLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLink not implemented")
+}
+func (UnimplementedGoLinksServer) UpdateLink(context.Context, *UpdateLinkRequest) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLink not implemented")
+}
+func (UnimplementedGoLinksServer) ListKeywords(context.Context, *ListKeywordsRequest) (*ListKeywordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListKeywords not implemented")
+}
+func (UnimplementedGoLinksServer) PopularQueries(context.Context, *PopularQueriesRequest) (*PopularQueriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PopularQueries not implemented")
+}
+func (UnimplementedGoLinksServer) mustEmbedUnimplementedGoLinksServer() {}
+func (UnimplementedGoLinksServer) testEmbeddedByValue()                 {}
+
+// UnsafeGoLinksServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GoLinksServer will
+// result in compilation errors.
+type UnsafeGoLinksServer interface {
+	mustEmbedUnimplementedGoLinksServer()
+}
+
+func RegisterGoLinksServer(s grpc.ServiceRegistrar, srv GoLinksServer) {
+	// If the following call pancis, it indicates UnimplementedGoLinksServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GoLinks_ServiceDesc, srv)
+}
+
+func _GoLinks_GetLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoLinksServer).GetLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoLinks_GetLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoLinksServer).GetLink(ctx, req.(*GetLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoLinks_UpdateLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoLinksServer).UpdateLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoLinks_UpdateLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoLinksServer).UpdateLink(ctx, req.(*UpdateLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoLinks_ListKeywords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListKeywordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoLinksServer).ListKeywords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoLinks_ListKeywords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoLinksServer).ListKeywords(ctx, req.(*ListKeywordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoLinks_PopularQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PopularQueriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoLinksServer).PopularQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoLinks_PopularQueries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoLinksServer).PopularQueries(ctx, req.(*PopularQueriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GoLinks_ServiceDesc is the grpc.ServiceDesc for GoLinks service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GoLinks_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "golinks.v1.GoLinks",
+	HandlerType: (*GoLinksServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLink",
+			Handler:    _GoLinks_GetLink_Handler,
+		},
+		{
+			MethodName: "UpdateLink",
+			Handler:    _GoLinks_UpdateLink_Handler,
+		},
+		{
+			MethodName: "ListKeywords",
+			Handler:    _GoLinks_ListKeywords_Handler,
+		},
+		{
+			MethodName: "PopularQueries",
+			Handler:    _GoLinks_PopularQueries_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "golinks/v1/golinks.proto",
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// AliasRepository handles database operations for aliases, other words a golink also
+// answers to. An alias points at a row of its golink rather than at its word, so it
+// follows the golink through new versions and renames, stops resolving while the golink
+// is in the trash and is purged along with it.
+type AliasRepository struct {
+	db *sql.DB
+}
+
+// NewAliasRepository creates a new alias repository
+func NewAliasRepository(db *sql.DB) *AliasRepository {
+	return &AliasRepository{db: db}
+}
+
+// GetTarget retrieves the word alias stands for, or "" if it isn't an alias of a golink
+// outside the trash
+func (r *AliasRepository) GetTarget(ctx context.Context, alias string) (string, error) {
+
+	query := `
+		SELECT l.word
+		FROM aliases a JOIN linktable l ON l.id = a.shortcut_id
+		WHERE a.word = ? AND l.deleted_at IS NULL
+	`
+
+	var word string
+	err := r.db.QueryRowContext(ctx, query, alias).Scan(&word)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get alias: %w", err)
+	}
+
+	return word, nil
+}
+
+// GetAliases retrieves the aliases of a word in alphabetical order
+func (r *AliasRepository) GetAliases(ctx context.Context, word string) ([]string, error) {
+
+	query := `
+		SELECT a.word
+		FROM aliases a JOIN linktable l ON l.id = a.shortcut_id
+		WHERE l.word = ? AND l.deleted_at IS NULL
+		ORDER BY a.word ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// GetAllAliases retrieves the word each alias of a golink outside the trash stands for
+func (r *AliasRepository) GetAllAliases(ctx context.Context) (map[string]string, error) {
+
+	query := `
+		SELECT a.word, l.word
+		FROM aliases a JOIN linktable l ON l.id = a.shortcut_id
+		WHERE l.deleted_at IS NULL
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := map[string]string{}
+	for rows.Next() {
+		var alias, word string
+		if err := rows.Scan(&alias, &word); err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+		aliases[alias] = word
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// Add makes alias another word for word, which must exist outside the trash
+func (r *AliasRepository) Add(ctx context.Context, alias, word, user string) error {
+
+	query := `
+		INSERT INTO aliases (word, shortcut_id, created_by)
+		VALUES (?, (SELECT MAX(id) FROM linktable WHERE word = ? AND deleted_at IS NULL), ?)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, alias, word, user); err != nil {
+		return fmt.Errorf("failed to add alias: %w", err)
+	}
+
+	return nil
+}
+
+// Remove deletes alias if it is an alias of word
+func (r *AliasRepository) Remove(ctx context.Context, alias, word string) (int64, error) {
+
+	query := `
+		DELETE FROM aliases
+		WHERE word = ? AND shortcut_id IN (SELECT id FROM linktable WHERE word = ? AND deleted_at IS NULL)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, alias, word)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove alias: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return removed, nil
+}
+
+// Rename moves every version of word outside the trash to newWord, so its history, stats,
+// tags, aliases and pins come along, and keeps word as an alias of it. An alias newWord
+// may have been of the same golink is dropped.
+func (r *AliasRepository) Rename(ctx context.Context, word, newWord, user string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	steps := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`DELETE FROM aliases WHERE word = ?`, []interface{}{newWord}},
+		{`UPDATE linktable SET word = ? WHERE word = ? AND deleted_at IS NULL`, []interface{}{newWord, word}},
+		{`UPDATE link_versions SET word = ? WHERE word_id IN (SELECT id FROM linktable WHERE word = ? AND deleted_at IS NULL)`,
+			[]interface{}{newWord, newWord}},
+		{`UPDATE favorites SET word = ?
+			WHERE word = ? AND NOT EXISTS (SELECT 1 FROM favorites f WHERE f."user" = favorites."user" AND f.word = ?)`,
+			[]interface{}{newWord, word, newWord}},
+		{`DELETE FROM favorites WHERE word = ?`, []interface{}{word}},
+		{`INSERT INTO aliases (word, shortcut_id, created_by)
+			VALUES (?, (SELECT MAX(id) FROM linktable WHERE word = ? AND deleted_at IS NULL), ?)`,
+			[]interface{}{word, newWord, user}},
+	}
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.query, step.args...); err != nil {
+			return fmt.Errorf("failed to rename shortcut: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
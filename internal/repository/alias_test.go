@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestAliasRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAliasRepository(db)
+	shortcutRepo := NewShortcutRepository(db)
+
+	for _, shortcut := range []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "github", Link: "https://github.com", User: "user2"},
+	} {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	for _, alias := range []string{"manual", "documentation"} {
+		if err := repo.Add(ctx, alias, "docs", "user1"); err != nil {
+			t.Fatalf("AliasRepository.Add() error = %v", err)
+		}
+	}
+
+	// Aliases follow their golink to new versions
+	if err := shortcutRepo.Create(ctx, &domain.Shortcut{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"}); err != nil {
+		t.Fatalf("Failed to update test shortcut: %v", err)
+	}
+
+	tests := []struct {
+		alias string
+		want  string
+	}{
+		{"manual", "docs"},
+		{"documentation", "docs"},
+		{"docs", ""},
+		{"missing", ""},
+	}
+	for _, tt := range tests {
+		if got, err := repo.GetTarget(ctx, tt.alias); err != nil || got != tt.want {
+			t.Errorf("AliasRepository.GetTarget(%q) = %q, %v, want %q", tt.alias, got, err, tt.want)
+		}
+	}
+
+	if aliases, err := repo.GetAliases(ctx, "docs"); err != nil || !reflect.DeepEqual(aliases, []string{"documentation", "manual"}) {
+		t.Errorf("AliasRepository.GetAliases() = %v, %v, want documentation and manual", aliases, err)
+	}
+	keywords, err := shortcutRepo.GetAllKeywords(ctx, "user1")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetAllKeywords() error = %v", err)
+	}
+	for _, keyword := range keywords {
+		want := []string(nil)
+		if keyword.Word == "docs" {
+			want = []string{"documentation", "manual"}
+		}
+		if !reflect.DeepEqual(keyword.AlsoKnownAs, want) {
+			t.Errorf("ShortcutRepository.GetAllKeywords() %s is also known as %v, want %v", keyword.Word, keyword.AlsoKnownAs, want)
+		}
+	}
+
+	if all, err := repo.GetAllAliases(ctx); err != nil || !reflect.DeepEqual(all, map[string]string{"documentation": "docs", "manual": "docs"}) {
+		t.Errorf("AliasRepository.GetAllAliases() = %v, %v, want documentation and manual standing for docs", all, err)
+	}
+
+	if removed, err := repo.Remove(ctx, "manual", "github"); err != nil || removed != 0 {
+		t.Errorf("AliasRepository.Remove() of another link's alias = %d, %v, want 0", removed, err)
+	}
+	if removed, err := repo.Remove(ctx, "manual", "docs"); err != nil || removed != 1 {
+		t.Errorf("AliasRepository.Remove() = %d, %v, want 1", removed, err)
+	}
+
+	// Aliases stop resolving while their golink is in the trash and go when it is purged
+	if _, err := shortcutRepo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if got, _ := repo.GetTarget(ctx, "documentation"); got != "" {
+		t.Errorf("AliasRepository.GetTarget() = %q, want no trashed links", got)
+	}
+	if all, err := repo.GetAllAliases(ctx); err != nil || len(all) != 0 {
+		t.Errorf("AliasRepository.GetAllAliases() = %v, %v, want no aliases of trashed links", all, err)
+	}
+	if _, err := shortcutRepo.RestoreByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.RestoreByWord() error = %v", err)
+	}
+	if got, _ := repo.GetTarget(ctx, "documentation"); got != "docs" {
+		t.Errorf("AliasRepository.GetTarget() = %q after restore, want docs", got)
+	}
+	if _, err := shortcutRepo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if _, err := shortcutRepo.PurgeByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.PurgeByWord() error = %v", err)
+	}
+	var remaining int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM aliases`).Scan(&remaining); err != nil || remaining != 0 {
+		t.Errorf("purge left %d aliases behind, %v", remaining, err)
+	}
+}
+
+func TestAliasRepository_Rename(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAliasRepository(db)
+	shortcutRepo := NewShortcutRepository(db)
+	favoriteRepo := NewFavoriteRepository(db)
+
+	for _, shortcut := range []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"},
+	} {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if err := repo.Add(ctx, "guide", "docs", "user1"); err != nil {
+		t.Fatalf("AliasRepository.Add() error = %v", err)
+	}
+	if err := repo.Add(ctx, "manual", "docs", "user1"); err != nil {
+		t.Fatalf("AliasRepository.Add() error = %v", err)
+	}
+	for _, user := range []string{"user1", "user2"} {
+		if err := favoriteRepo.Add(ctx, user, "docs"); err != nil {
+			t.Fatalf("FavoriteRepository.Add() error = %v", err)
+		}
+	}
+	if err := favoriteRepo.Add(ctx, "user2", "guide"); err != nil {
+		t.Fatalf("FavoriteRepository.Add() error = %v", err)
+	}
+
+	if err := repo.Rename(ctx, "docs", "guide", "user2"); err != nil {
+		t.Fatalf("AliasRepository.Rename() error = %v", err)
+	}
+
+	if shortcut, _ := shortcutRepo.GetByWord(ctx, "docs"); shortcut != nil {
+		t.Errorf("ShortcutRepository.GetByWord() = %+v, want docs gone", shortcut)
+	}
+	history, err := shortcutRepo.GetHistory(ctx, "guide")
+	if err != nil || len(history) != 2 || history[0].Link != "https://docs.example.com/v2" {
+		t.Errorf("ShortcutRepository.GetHistory() = %+v, %v, want both versions of docs", history, err)
+	}
+	if aliases, err := repo.GetAliases(ctx, "guide"); err != nil || !reflect.DeepEqual(aliases, []string{"docs", "manual"}) {
+		t.Errorf("AliasRepository.GetAliases() = %v, %v, want docs and manual", aliases, err)
+	}
+	if got, _ := repo.GetTarget(ctx, "docs"); got != "guide" {
+		t.Errorf("AliasRepository.GetTarget() = %q, want the old word to stand for guide", got)
+	}
+
+	for _, user := range []string{"user1", "user2"} {
+		favorites, err := favoriteRepo.GetKeywords(ctx, user)
+		if err != nil || len(favorites) != 1 || favorites[0].Word != "guide" {
+			t.Errorf("FavoriteRepository.GetKeywords(%s) = %+v, %v, want guide pinned once", user, favorites, err)
+		}
+	}
+}
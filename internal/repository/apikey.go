@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// APIKeyRepository handles database operations for API keys
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// apiKeyColumns are the columns scanned by scanAPIKey
+const apiKeyColumns = `id, name, "user", prefix, created_at, revoked_at`
+
+// scanAPIKey reads an API key row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.Name, &key.User, &key.Prefix, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
+
+// Create stores a new API key under the hash of its secret
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey, keyHash string) error {
+
+	query := `
+		INSERT INTO api_keys (name, "user", prefix, key_hash, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	key.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	err := r.db.QueryRowContext(ctx, query, key.Name, key.User, key.Prefix, keyHash, key.CreatedAt).Scan(&key.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash retrieves the API key whose secret has the given hash, or nil if there is none
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// List retrieves every API key, newest first, including revoked ones
+func (r *APIKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke marks an API key as revoked, returning the number of keys affected. Keys that
+// are already revoked keep their original revocation time.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int) (int64, error) {
+
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC().Truncate(time.Second), id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return revoked, nil
+}
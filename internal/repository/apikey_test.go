@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	ci := &domain.APIKey{Name: "ci", User: "alice", Prefix: "glk_aaaa"}
+	if err := repo.Create(ctx, ci, "hash-ci"); err != nil {
+		t.Fatalf("APIKeyRepository.Create() error = %v", err)
+	}
+	bot := &domain.APIKey{Name: "bot", User: "bob", Prefix: "glk_bbbb"}
+	if err := repo.Create(ctx, bot, "hash-bot"); err != nil {
+		t.Fatalf("APIKeyRepository.Create() error = %v", err)
+	}
+	if ci.ID == 0 || bot.ID == 0 || ci.CreatedAt.IsZero() {
+		t.Fatalf("APIKeyRepository.Create() did not fill in id and created_at: %+v %+v", ci, bot)
+	}
+
+	// Secrets are unique
+	if err := repo.Create(ctx, &domain.APIKey{Name: "dup", User: "alice", Prefix: "glk_aaaa"}, "hash-ci"); err == nil {
+		t.Error("APIKeyRepository.Create() with a duplicate hash should fail")
+	}
+
+	got, err := repo.GetByHash(ctx, "hash-ci")
+	if err != nil {
+		t.Fatalf("APIKeyRepository.GetByHash() error = %v", err)
+	}
+	if got == nil || got.ID != ci.ID || got.User != "alice" || got.Name != "ci" || got.RevokedAt != nil {
+		t.Errorf("APIKeyRepository.GetByHash() = %+v, want %+v", got, ci)
+	}
+
+	if got, err := repo.GetByHash(ctx, "unknown"); err != nil || got != nil {
+		t.Errorf("APIKeyRepository.GetByHash(unknown) = %+v, %v, want nil, nil", got, err)
+	}
+
+	revoked, err := repo.Revoke(ctx, ci.ID)
+	if err != nil || revoked != 1 {
+		t.Fatalf("APIKeyRepository.Revoke() = %d, %v, want 1", revoked, err)
+	}
+	if revoked, _ := repo.Revoke(ctx, ci.ID); revoked != 0 {
+		t.Errorf("APIKeyRepository.Revoke() twice = %d, want 0", revoked)
+	}
+	if revoked, _ := repo.Revoke(ctx, 999); revoked != 0 {
+		t.Errorf("APIKeyRepository.Revoke(missing) = %d, want 0", revoked)
+	}
+
+	got, err = repo.GetByHash(ctx, "hash-ci")
+	if err != nil || got == nil || got.RevokedAt == nil {
+		t.Errorf("APIKeyRepository.GetByHash() after revoke = %+v, %v, want revoked_at set", got, err)
+	}
+
+	keys, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("APIKeyRepository.List() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].Name != "bot" || keys[1].Name != "ci" || keys[1].RevokedAt == nil {
+		t.Errorf("APIKeyRepository.List() = %+v, want bot then revoked ci", keys)
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golinks/internal/database"
+	"golinks/internal/domain"
+)
+
+// SQLiteSnapshots backs up and restores a SQLite database with SQLite's online backup API
+type SQLiteSnapshots struct {
+	db         *sql.DB
+	migrations *database.Migrator
+
+	// prepare readies restored data for the store, as a snapshot may be from a store
+	// with another word mode
+	prepare func(ctx context.Context) error
+}
+
+// NewSQLiteSnapshots creates snapshots of db. Restoring brings the restored schema up
+// to date with migrations, if given, as the snapshot may come from an older version.
+func NewSQLiteSnapshots(db *sql.DB, migrations *database.Migrator) *SQLiteSnapshots {
+	return &SQLiteSnapshots{db: db, migrations: migrations}
+}
+
+// Snapshot writes a consistent copy of the database to a new file at path
+func (s *SQLiteSnapshots) Snapshot(ctx context.Context, path string) error {
+	return database.BackupSQLite(ctx, s.db, path)
+}
+
+// Restore replaces the database with the snapshot at path
+func (s *SQLiteSnapshots) Restore(ctx context.Context, path string) error {
+	if err := database.RestoreSQLite(ctx, s.db, path); err != nil {
+		return err
+	}
+
+	if s.migrations != nil {
+		if _, err := s.migrations.Up(ctx); err != nil {
+			return fmt.Errorf("failed to migrate restored database: %w", err)
+		}
+	}
+
+	if s.prepare != nil {
+		if err := s.prepare(ctx); err != nil {
+			return fmt.Errorf("failed to prepare restored database: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// BackupDirectory keeps backups as files in a local directory
+type BackupDirectory struct {
+	dir string
+}
+
+// NewBackupDirectory creates backup storage in dir, which is created on the first save
+func NewBackupDirectory(dir string) *BackupDirectory {
+	return &BackupDirectory{dir: dir}
+}
+
+// Save writes a backup from r under name
+func (d *BackupDirectory) Save(ctx context.Context, name string, r io.Reader) (*domain.Backup, error) {
+	if err := os.MkdirAll(d.dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Write under a temporary name so a partial backup is never listed
+	tmp, err := os.CreateTemp(d.dir, "."+name+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	path := filepath.Join(d.dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup: %w", err)
+	}
+
+	return backupFromFile(info), nil
+}
+
+// Open reads the named backup, returning nil if there is no such backup
+func (d *BackupDirectory) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(d.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+
+	return f, nil
+}
+
+// List returns the backups in the directory, newest first
+func (d *BackupDirectory) List(ctx context.Context) ([]domain.Backup, error) {
+	entries, err := os.ReadDir(d.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []domain.Backup
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat backup: %w", err)
+		}
+		backups = append(backups, *backupFromFile(info))
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// backupFromFile describes a backup file
+func backupFromFile(info os.FileInfo) *domain.Backup {
+	return &domain.Backup{Name: info.Name(), Size: info.Size(), CreatedAt: info.ModTime().UTC()}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golinks/internal/database"
+)
+
+func TestBackupDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+	backups := NewBackupDirectory(dir)
+	ctx := context.Background()
+
+	list, err := backups.List(ctx)
+	if err != nil || len(list) != 0 {
+		t.Errorf("List() before any backup = %+v, %v, want none", list, err)
+	}
+
+	first, err := backups.Save(ctx, "first.db", strings.NewReader("first"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if first.Name != "first.db" || first.Size != 5 {
+		t.Errorf("Save() = %+v, want first.db of 5 bytes", first)
+	}
+	if _, err := backups.Save(ctx, "second.db", strings.NewReader("second")); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	// Make the order independent of the file system's timestamp resolution
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "first.db"), old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	list, err = backups.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "second.db" || list[1].Name != "first.db" {
+		t.Errorf("List() = %+v, want second.db then first.db", list)
+	}
+
+	r, err := backups.Open(ctx, "first.db")
+	if err != nil || r == nil {
+		t.Fatalf("Open() = %v, %v, want the backup", r, err)
+	}
+	content, _ := io.ReadAll(r)
+	r.Close()
+	if string(content) != "first" {
+		t.Errorf("Open() read %q, want first", content)
+	}
+
+	if r, err := backups.Open(ctx, "missing.db"); err != nil || r != nil {
+		t.Errorf("Open() of a missing backup = %v, %v, want nil", r, err)
+	}
+}
+
+func TestSQLiteSnapshots_RestoreMigrates(t *testing.T) {
+	dir := t.TempDir()
+	db, err := database.NewSQLiteDB(filepath.Join(dir, "golinks.db"), database.Pool{})
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	migrations := database.NewMigrator(db, database.SQLiteMigrations)
+	if _, err := migrations.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	snapshots := NewSQLiteSnapshots(db, migrations)
+
+	// Take the snapshot on an older schema, as a backup from an earlier release would be
+	if _, err := migrations.Down(ctx, 1); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	path := filepath.Join(dir, "snapshot.db")
+	if err := snapshots.Snapshot(ctx, path); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if _, err := migrations.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	if err := snapshots.Restore(ctx, path); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	pending, err := migrations.Pending(ctx)
+	if err != nil || len(pending) != 0 {
+		t.Errorf("Pending() after Restore() = %+v, %v, want the restored schema brought up to date", pending, err)
+	}
+}
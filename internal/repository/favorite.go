@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"golinks/internal/domain"
+)
+
+// FavoriteRepository handles database operations for the links each user has pinned.
+// Favorites are stored by word, so they follow a link through its versions and come back
+// with it when it is restored from the trash.
+type FavoriteRepository struct {
+	db *sql.DB
+}
+
+// NewFavoriteRepository creates a new favorite repository
+func NewFavoriteRepository(db *sql.DB) *FavoriteRepository {
+	return &FavoriteRepository{db: db}
+}
+
+// Add pins a word for a user, doing nothing if it is pinned already
+func (r *FavoriteRepository) Add(ctx context.Context, user, word string) error {
+
+	query := `INSERT INTO favorites ("user", word) VALUES (?, ?) ON CONFLICT ("user", word) DO NOTHING`
+
+	if _, err := r.db.ExecContext(ctx, query, user, word); err != nil {
+		return fmt.Errorf("failed to add favorite: %w", err)
+	}
+
+	return nil
+}
+
+// Remove unpins a word for a user
+func (r *FavoriteRepository) Remove(ctx context.Context, user, word string) (int64, error) {
+
+	query := `DELETE FROM favorites WHERE "user" = ? AND word = ?`
+
+	result, err := r.db.ExecContext(ctx, query, user, word)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove favorite: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return removed, nil
+}
+
+// GetKeywords retrieves the keywords a user has pinned that are still visible to them,
+// in alphabetical order. Pinned words that have been deleted are left out.
+func (r *FavoriteRepository) GetKeywords(ctx context.Context, user string) ([]domain.KeywordInfo, error) {
+
+	visible, args := visibleFilter(user)
+	query := keywordColumns + latestKeywordFrom + `
+		AND l.word IN (SELECT word FROM favorites WHERE "user" = ?)
+		AND ` + visible + `
+		ORDER BY l.word ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{user}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get favorites: %w", err)
+	}
+	defer rows.Close()
+
+	return scanKeywords(rows)
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestFavoriteRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewFavoriteRepository(db)
+	shortcutRepo := NewShortcutRepository(db)
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user1", Description: "Team docs"},
+		{Word: "github", Link: "https://github.com", User: "user2"},
+		{Word: "payroll", Link: "https://payroll.example.com", User: "user2", Private: true},
+	}
+	for _, shortcut := range shortcuts {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	pins := []struct {
+		user string
+		word string
+	}{
+		{"user1", "github"},
+		{"user1", "docs"},
+		{"user1", "docs"},
+		{"user1", "payroll"},
+		{"user2", "payroll"},
+	}
+	for _, pin := range pins {
+		if err := repo.Add(ctx, pin.user, pin.word); err != nil {
+			t.Fatalf("FavoriteRepository.Add() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		user string
+		want []string
+	}{
+		{"someone else's private link is left out", "user1", []string{"docs", "github"}},
+		{"own private link", "user2", []string{"payroll"}},
+		{"nothing pinned", "user3", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keywords, err := repo.GetKeywords(ctx, tt.user)
+			if err != nil {
+				t.Fatalf("FavoriteRepository.GetKeywords() error = %v", err)
+			}
+			var words []string
+			for _, keyword := range keywords {
+				words = append(words, keyword.Word)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("FavoriteRepository.GetKeywords() = %v, want %v", words, tt.want)
+			}
+		})
+	}
+
+	// Favorites follow the newest version of a link
+	if keywords, _ := repo.GetKeywords(ctx, "user1"); len(keywords) == 0 || keywords[0].Link != "https://docs.example.com/v2" ||
+		keywords[0].Description != "Team docs" {
+		t.Errorf("FavoriteRepository.GetKeywords() = %+v, want the latest docs version", keywords)
+	}
+
+	if removed, err := repo.Remove(ctx, "user1", "github"); err != nil || removed != 1 {
+		t.Errorf("FavoriteRepository.Remove() = %d, %v, want 1", removed, err)
+	}
+	if removed, err := repo.Remove(ctx, "user1", "github"); err != nil || removed != 0 {
+		t.Errorf("FavoriteRepository.Remove() of an unpinned word = %d, %v, want 0", removed, err)
+	}
+
+	// Trashed links drop out of favorites, come back when restored and are forgotten when purged
+	if _, err := shortcutRepo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if keywords, _ := repo.GetKeywords(ctx, "user1"); len(keywords) != 0 {
+		t.Errorf("FavoriteRepository.GetKeywords() = %+v, want no trashed links", keywords)
+	}
+	if _, err := shortcutRepo.RestoreByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.RestoreByWord() error = %v", err)
+	}
+	if keywords, _ := repo.GetKeywords(ctx, "user1"); len(keywords) != 1 {
+		t.Errorf("FavoriteRepository.GetKeywords() = %+v after restore, want docs back", keywords)
+	}
+	if _, err := shortcutRepo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if _, err := shortcutRepo.PurgeByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.PurgeByWord() error = %v", err)
+	}
+	var remaining int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM favorites WHERE word = 'docs'`).Scan(&remaining); err != nil || remaining != 0 {
+		t.Errorf("purge left %d favorites behind, %v", remaining, err)
+	}
+}
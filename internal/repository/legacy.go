@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// legacyTimeLayouts are the ways the Python implementation's SQLite databases may hold a
+// timestamp as text: SQLite's CURRENT_TIMESTAMP and Python's datetime.isoformat
+var legacyTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+// LegacyRepository copies the links and queries of a database from the legacy Python
+// implementation into this one
+type LegacyRepository struct {
+	db *sql.DB
+
+	// resetSequences moves PostgreSQL's id sequences past the copied ids, which inserting
+	// ids explicitly leaves behind
+	resetSequences bool
+}
+
+// NewLegacyRepository creates a legacy repository copying into db
+func NewLegacyRepository(db *sql.DB, resetSequences bool) *LegacyRepository {
+	return &LegacyRepository{db: db, resetSequences: resetSequences}
+}
+
+// Import copies every row of the legacy SQLite database's linktable and queries tables,
+// keeping their ids and timestamps so history and click stats carry over unchanged.
+// Queries of links that no longer exist are skipped. It refuses to copy into a database
+// that already holds links, whose ids would clash, and copies everything or nothing.
+func (r *LegacyRepository) Import(ctx context.Context, legacy *sql.DB) (*domain.LegacyMigration, error) {
+	linkColumns, err := legacyColumns(ctx, legacy, "linktable")
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range []string{"id", "word", "link", "user"} {
+		if !linkColumns[column] {
+			return nil, fmt.Errorf("legacy linktable has no %s column", column)
+		}
+	}
+	queryColumns, err := legacyColumns(ctx, legacy, "queries")
+	if err != nil {
+		return nil, err
+	}
+	if !queryColumns["query_id"] || !queryColumns["word_id"] {
+		return nil, errors.New("legacy queries table has no query_id or word_id column")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var existing int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM linktable`).Scan(&existing); err != nil {
+		return nil, fmt.Errorf("failed to count links: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("the database already holds %d links; copy legacy data into a new one", existing)
+	}
+
+	migration := &domain.LegacyMigration{}
+	now := time.Now().UTC().Truncate(time.Second)
+	linkIDs := map[int]bool{}
+
+	rows, err := legacy.QueryContext(ctx, fmt.Sprintf(
+		`SELECT id, word, link, "user", %s FROM linktable ORDER BY id`, legacyTimeColumn(linkColumns)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy links: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var word, link, user string
+		var created interface{}
+		if err := rows.Scan(&id, &word, &link, &user, &created); err != nil {
+			return nil, fmt.Errorf("failed to scan legacy link: %w", err)
+		}
+		createdAt, err := legacyTime(created, now)
+		if err != nil {
+			return nil, fmt.Errorf("legacy link %d: %w", id, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO linktable (id, word, link, "user", created_at) VALUES (?, ?, ?, ?, ?)`,
+			id, word, link, user, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy legacy link %d: %w", id, err)
+		}
+		linkIDs[id] = true
+		migration.Links++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating legacy links: %w", err)
+	}
+
+	queries, err := legacy.QueryContext(ctx, fmt.Sprintf(
+		`SELECT query_id, word_id, %s FROM queries ORDER BY query_id`, legacyTimeColumn(queryColumns)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy queries: %w", err)
+	}
+	defer queries.Close()
+	for queries.Next() {
+		var id, wordID int
+		var created interface{}
+		if err := queries.Scan(&id, &wordID, &created); err != nil {
+			return nil, fmt.Errorf("failed to scan legacy query: %w", err)
+		}
+		if !linkIDs[wordID] {
+			migration.SkippedQueries++
+			continue
+		}
+		createdAt, err := legacyTime(created, now)
+		if err != nil {
+			return nil, fmt.Errorf("legacy query %d: %w", id, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO queries (query_id, word_id, created_at) VALUES (?, ?, ?)`, id, wordID, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy legacy query %d: %w", id, err)
+		}
+		migration.Queries++
+	}
+	if err := queries.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating legacy queries: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, recountClicks); err != nil {
+		return nil, fmt.Errorf("failed to count legacy clicks: %w", err)
+	}
+
+	if r.resetSequences {
+		steps := []string{
+			`SELECT setval(pg_get_serial_sequence('linktable', 'id'), MAX(id)) FROM linktable HAVING COUNT(*) > 0`,
+			`SELECT setval(pg_get_serial_sequence('queries', 'query_id'), MAX(query_id)) FROM queries HAVING COUNT(*) > 0`,
+		}
+		for _, step := range steps {
+			if _, err := tx.ExecContext(ctx, step); err != nil {
+				return nil, fmt.Errorf("failed to reset id sequences: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return migration, nil
+}
+
+// legacyColumns returns the names of the columns of a table in the legacy database
+func legacyColumns(ctx context.Context, legacy *sql.DB, table string) (map[string]bool, error) {
+	rows, err := legacy.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legacy table info for %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, dataType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, fmt.Errorf("failed to scan legacy table info for %s: %w", table, err)
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating legacy table info for %s: %w", table, err)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("legacy database has no %s table", table)
+	}
+	return columns, nil
+}
+
+// legacyTimeColumn selects a legacy table's created_at column, or NULL for tables from
+// before timestamps were recorded
+func legacyTimeColumn(columns map[string]bool) string {
+	if columns["created_at"] {
+		return "created_at"
+	}
+	return "NULL"
+}
+
+// legacyTime reads a legacy timestamp, which SQLite may hand back as a time, as text or
+// as Unix seconds, using fallback when it is missing
+func legacyTime(value interface{}, fallback time.Time) (time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return fallback, nil
+	case time.Time:
+		return v.UTC(), nil
+	case int64:
+		return time.Unix(v, 0).UTC(), nil
+	case float64:
+		return time.Unix(0, int64(v*float64(time.Second))).UTC(), nil
+	case []byte:
+		return legacyTime(string(v), fallback)
+	case string:
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return legacyTime(seconds, fallback)
+		}
+		for _, layout := range legacyTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unrecognized timestamp %q", v)
+	default:
+		return time.Time{}, fmt.Errorf("unrecognized timestamp %v", value)
+	}
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"golinks/internal/database"
+	"golinks/internal/domain"
+)
+
+// newLegacyDB creates a database laid out like the Python implementation's, running the
+// given statements on it
+func newLegacyDB(t *testing.T, statements ...string) *sql.DB {
+	t.Helper()
+	db, err := database.NewSQLiteDB(":memory:", database.Pool{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("Failed to create legacy database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up legacy database: %v", err)
+		}
+	}
+	return db
+}
+
+func TestLegacyRepository_Import(t *testing.T) {
+	legacy := newLegacyDB(t,
+		`CREATE TABLE linktable (id INTEGER PRIMARY KEY, word TEXT, link TEXT, user TEXT, created_at TEXT)`,
+		`CREATE TABLE queries (query_id INTEGER PRIMARY KEY, word_id INTEGER, created_at REAL)`,
+		`INSERT INTO linktable VALUES (3, 'docs', 'https://docs.example.com', 'alice', '2019-05-01 09:30:00')`,
+		`INSERT INTO linktable VALUES (7, 'docs', 'https://docs.example.com/v2', 'bob', '2020-01-02T03:04:05.123456')`,
+		`INSERT INTO linktable VALUES (8, 'wiki', 'https://wiki.example.com', 'alice', NULL)`,
+		`INSERT INTO queries VALUES (10, 3, 1556703000)`,
+		`INSERT INTO queries VALUES (11, 7, 1577934245.5)`,
+		`INSERT INTO queries VALUES (12, 99, 1577934245)`,
+	)
+	db := setupTestDB(t)
+	defer db.Close()
+	shortcuts := NewShortcutRepository(db)
+	ctx := context.Background()
+
+	migration, err := NewLegacyRepository(db, false).Import(ctx, legacy)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if migration.Links != 3 || migration.Queries != 2 || migration.SkippedQueries != 1 {
+		t.Errorf("Import() = %+v, want 3 links, 2 queries and 1 skipped", migration)
+	}
+
+	docs, err := shortcuts.GetByWord(ctx, "docs")
+	if err != nil {
+		t.Fatalf("GetByWord() error = %v", err)
+	}
+	if docs.ID != 7 || docs.Link != "https://docs.example.com/v2" || docs.User != "bob" {
+		t.Errorf("GetByWord(docs) = %+v, want the newest version, id 7", docs)
+	}
+	history, err := shortcuts.GetHistory(ctx, "docs")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("GetHistory(docs) = %+v, %v, want 2 versions", history, err)
+	}
+	for _, version := range history {
+		want := map[int]time.Time{
+			3: time.Date(2019, 5, 1, 9, 30, 0, 0, time.UTC),
+			7: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		}[version.ID]
+		if !version.CreatedAt.Truncate(time.Second).Equal(want) {
+			t.Errorf("version %d created at %v, want %v", version.ID, version.CreatedAt, want)
+		}
+	}
+
+	var firstQuery time.Time
+	if err := db.QueryRow(`SELECT created_at FROM queries WHERE query_id = 10 AND word_id = 3`).Scan(&firstQuery); err != nil {
+		t.Fatalf("Failed to read copied query: %v", err)
+	}
+	if !firstQuery.Equal(time.Unix(1556703000, 0)) {
+		t.Errorf("copied query created at %v, want %v", firstQuery, time.Unix(1556703000, 0).UTC())
+	}
+
+	// Copied queries are counted as clicks
+	if keywords, _ := shortcuts.GetKeywordsByPrefix(ctx, "docs", "", 1); len(keywords) != 1 || keywords[0].ClickCount != 2 {
+		t.Errorf("GetKeywordsByPrefix(docs) = %+v, want the 2 copied clicks counted", keywords)
+	}
+
+	// New links take ids after the copied ones
+	added := &domain.Shortcut{Word: "new", Link: "https://new.example.com", User: "alice"}
+	if err := shortcuts.Create(ctx, added); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if added.ID != 9 {
+		t.Errorf("Create() id = %d, want 9", added.ID)
+	}
+
+	if _, err := NewLegacyRepository(db, false).Import(ctx, legacy); err == nil || !strings.Contains(err.Error(), "already holds 4 links") {
+		t.Errorf("Import() into a database with links error = %v, want already holds", err)
+	}
+}
+
+func TestLegacyRepository_ImportErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements []string
+		wantErr    string
+	}{
+		{
+			name:    "no linktable",
+			wantErr: "no linktable table",
+		},
+		{
+			name:       "no user column",
+			statements: []string{`CREATE TABLE linktable (id INTEGER, word TEXT, link TEXT)`},
+			wantErr:    "no user column",
+		},
+		{
+			name: "no queries table",
+			statements: []string{
+				`CREATE TABLE linktable (id INTEGER, word TEXT, link TEXT, user TEXT)`,
+			},
+			wantErr: "no queries table",
+		},
+		{
+			name: "bad timestamp",
+			statements: []string{
+				`CREATE TABLE linktable (id INTEGER, word TEXT, link TEXT, user TEXT, created_at TEXT)`,
+				`CREATE TABLE queries (query_id INTEGER, word_id INTEGER)`,
+				`INSERT INTO linktable VALUES (1, 'docs', 'https://docs.example.com', 'alice', 'yesterday')`,
+			},
+			wantErr: "unrecognized timestamp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+			_, err := NewLegacyRepository(db, false).Import(context.Background(), newLegacyDB(t, tt.statements...))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Import() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
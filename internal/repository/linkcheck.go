@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// errPrivateAddress stops the link checker connecting to the server's own network
+var errPrivateAddress = errors.New("refusing to connect to a private address")
+
+// LinkChecker requests link targets to find ones that are broken, such as from a typo.
+// It never connects to loopback, private or link-local addresses, so links can't be used
+// to probe the network golinks runs in; those targets go unchecked.
+type LinkChecker struct {
+	client *http.Client
+}
+
+// NewLinkChecker creates a checker giving each target timeout to answer
+func NewLinkChecker(timeout time.Duration) *LinkChecker {
+	return newLinkChecker(timeout, guardPrivateAddress)
+}
+
+// newLinkChecker creates a checker whose connections are vetted by control before they
+// are made
+func newLinkChecker(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *LinkChecker {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+	}
+	return &LinkChecker{client: &http.Client{Transport: transport, Timeout: timeout}}
+}
+
+// guardPrivateAddress refuses connections to addresses outside the public internet. It
+// sees the address after DNS resolution, so a public name resolving to a private address
+// is refused too.
+func guardPrivateAddress(network, address string, c syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return errPrivateAddress
+	}
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// Check requests link, returning an error describing why it looks broken: a host that
+// doesn't exist, or an answer of 404, 410 or another client or server error short of
+// asking to sign in. Targets that can't be checked, because they are private, slow or
+// refuse HEAD and GET alike, return nil.
+func (c *LinkChecker) Check(ctx context.Context, link string) error {
+	status, err := c.request(ctx, http.MethodHead, link)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		// Some servers only answer GET
+		status, err = c.request(ctx, http.MethodGet, link)
+	}
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return fmt.Errorf("%s doesn't exist", dnsErr.Name)
+		}
+		return nil
+	}
+
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden,
+		status == http.StatusProxyAuthRequired, status == http.StatusTooManyRequests:
+		// The page is there, but not for golinks
+		return nil
+	case status >= 400:
+		return fmt.Errorf("it answered %d %s", status, http.StatusText(status))
+	}
+	return nil
+}
+
+// request makes one request for link, following redirects, and returns the final status
+func (c *LinkChecker) request(ctx context.Context, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "golinks link checker")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Only the status matters, so read no more of a GET than needed to reuse the connection
+	_, _ = io.CopyN(io.Discard, resp.Body, 4096)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkChecker_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/moved":
+			http.Redirect(w, r, "/missing", http.StatusFound)
+		case "/missing":
+			http.NotFound(w, r)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/sso":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/get-only":
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	// The test server listens on loopback, which NewLinkChecker refuses
+	checker := newLinkChecker(50*time.Millisecond, nil)
+
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{path: "/ok"},
+		{path: "/missing", wantErr: "it answered 404 Not Found"},
+		{path: "/moved", wantErr: "it answered 404 Not Found"},
+		{path: "/broken", wantErr: "it answered 500 Internal Server Error"},
+		{path: "/sso"},
+		{path: "/get-only"},
+		{path: "/slow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := checker.Check(context.Background(), server.URL+tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LinkChecker.Check(%s) error = %v", tt.path, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LinkChecker.Check(%s) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLinkChecker_PrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(http.NotFound))
+	defer server.Close()
+
+	// The target is broken, but on loopback, so it goes unchecked
+	if err := NewLinkChecker(time.Second).Check(context.Background(), server.URL); err != nil {
+		t.Errorf("LinkChecker.Check(%s) error = %v, want loopback left unchecked", server.URL, err)
+	}
+
+	tests := []struct {
+		address string
+		want    error
+	}{
+		{"93.184.216.34:443", nil},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", nil},
+		{"127.0.0.1:80", errPrivateAddress},
+		{"10.1.2.3:443", errPrivateAddress},
+		{"192.168.0.1:443", errPrivateAddress},
+		{"169.254.169.254:80", errPrivateAddress},
+		{"0.0.0.0:80", errPrivateAddress},
+		{"[::1]:80", errPrivateAddress},
+		{"[fd00::1]:80", errPrivateAddress},
+		{"[::ffff:127.0.0.1]:80", errPrivateAddress},
+	}
+	for _, tt := range tests {
+		if err := guardPrivateAddress("tcp", tt.address, nil); err != tt.want {
+			t.Errorf("guardPrivateAddress(%s) = %v, want %v", tt.address, err, tt.want)
+		}
+	}
+}
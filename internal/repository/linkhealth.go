@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"golinks/internal/domain"
+)
+
+// LinkHealthRepository records what the last check of each golink's target found
+type LinkHealthRepository struct {
+	db *sql.DB
+}
+
+// NewLinkHealthRepository creates a new link health repository
+func NewLinkHealthRepository(db *sql.DB) *LinkHealthRepository {
+	return &LinkHealthRepository{db: db}
+}
+
+// GetAll retrieves the findings of the last check, by word
+func (r *LinkHealthRepository) GetAll(ctx context.Context) ([]domain.LinkHealth, error) {
+
+	query := `
+		SELECT word, link, owner, problem, checked_at, broken_since
+		FROM link_health
+		ORDER BY word
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link health: %w", err)
+	}
+	defer rows.Close()
+
+	health := []domain.LinkHealth{}
+	for rows.Next() {
+		var h domain.LinkHealth
+		var brokenSince sql.NullTime
+		if err := rows.Scan(&h.Word, &h.Link, &h.Owner, &h.Problem, &h.CheckedAt, &brokenSince); err != nil {
+			return nil, fmt.Errorf("failed to scan link health: %w", err)
+		}
+		if brokenSince.Valid {
+			h.BrokenSince = &brokenSince.Time
+		}
+		health = append(health, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating link health: %w", err)
+	}
+
+	return health, nil
+}
+
+// Replace stores the findings of a check in place of the last one's, so links deleted
+// since drop out
+func (r *LinkHealthRepository) Replace(ctx context.Context, health []domain.LinkHealth) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM link_health`); err != nil {
+		return fmt.Errorf("failed to clear link health: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO link_health (word, link, owner, problem, checked_at, broken_since)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, h := range health {
+		var brokenSince sql.NullTime
+		if h.BrokenSince != nil {
+			brokenSince = sql.NullTime{Time: *h.BrokenSince, Valid: true}
+		}
+		if _, err := stmt.ExecContext(ctx, h.Word, h.Link, h.Owner, h.Problem, h.CheckedAt, brokenSince); err != nil {
+			return fmt.Errorf("failed to store link health of %s: %w", h.Word, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkHealthRepository_Replace(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewLinkHealthRepository(db)
+
+	health, err := repo.GetAll(ctx)
+	if err != nil || len(health) != 0 {
+		t.Fatalf("LinkHealthRepository.GetAll() = %+v, %v before any check, want none", health, err)
+	}
+
+	checked := time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC)
+	brokenSince := checked.Add(-48 * time.Hour)
+	first := []domain.LinkHealth{
+		{Word: "docs", Link: "https://docs.example.com", Owner: "alice", CheckedAt: checked},
+		{Word: "old", Link: "https://old.example.com", Owner: "bob", Problem: "it answered 404 Not Found", CheckedAt: checked, BrokenSince: &brokenSince},
+	}
+	second := []domain.LinkHealth{
+		{Word: "wiki", Link: "https://wiki.example.com", Owner: "alice", CheckedAt: checked.Add(24 * time.Hour)},
+	}
+
+	for _, want := range [][]domain.LinkHealth{first, second} {
+		if err := repo.Replace(ctx, want); err != nil {
+			t.Fatalf("LinkHealthRepository.Replace() error = %v", err)
+		}
+		got, err := repo.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("LinkHealthRepository.GetAll() error = %v", err)
+		}
+		for i := range got {
+			got[i].CheckedAt = got[i].CheckedAt.UTC()
+			if got[i].BrokenSince != nil {
+				since := got[i].BrokenSince.UTC()
+				got[i].BrokenSince = &since
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("LinkHealthRepository.GetAll() = %+v, want %+v", got, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// SMTPMailer emails the owners of golinks through an SMTP server
+type SMTPMailer struct {
+	addr    string
+	from    string
+	auth    smtp.Auth
+	baseURL string
+
+	// send delivers a message, and is smtp.SendMail but in tests
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPMailer creates a mailer sending from from through the server at addr, signing
+// in with username and password if given, and linking each golink to its entry under
+// baseURL
+func NewSMTPMailer(addr, from, username, password, baseURL string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("SMTP address must be host:port: %w", err)
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+
+	m := &SMTPMailer{
+		addr:    addr,
+		from:    sender.Address,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		send:    smtp.SendMail,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+// SendBrokenLinks emails owner, whose user name must be an email address, the golinks of
+// theirs whose targets stopped working
+func (m *SMTPMailer) SendBrokenLinks(ctx context.Context, owner string, links []domain.LinkHealth) error {
+	to, err := mail.ParseAddress(owner)
+	if err != nil {
+		return fmt.Errorf("%q is not an email address: %w", owner, err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: golinks <%s>\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to.Address)
+	subject := "A golink of yours looks broken"
+	if len(links) > 1 {
+		subject = fmt.Sprintf("%d golinks of yours look broken", len(links))
+	}
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	msg.WriteString("The targets of these golinks didn't work when golinks last checked them:\r\n")
+	for _, link := range links {
+		fmt.Fprintf(&msg, "\r\ngo/%s -> %s\r\n", link.Word, link.Link)
+		fmt.Fprintf(&msg, "  %s\r\n", link.Problem)
+		fmt.Fprintf(&msg, "  Update or delete it at %s/homepage/?q=%s\r\n", m.baseURL, url.QueryEscape(link.Word))
+	}
+
+	if err := m.send(m.addr, m.auth, m.from, []string{to.Address}, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to email %s: %w", to.Address, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestSMTPMailer_SendBrokenLinks(t *testing.T) {
+	mailer, err := NewSMTPMailer("smtp.example.com:587", "golinks@example.com", "", "", "https://go.example.com/")
+	if err != nil {
+		t.Fatalf("NewSMTPMailer() error = %v", err)
+	}
+	var sentTo []string
+	var sent string
+	mailer.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentTo, sent = to, string(msg)
+		return nil
+	}
+
+	links := []domain.LinkHealth{
+		{Word: "old", Link: "https://old.example.com", Problem: "it answered 404 Not Found"},
+		{Word: "team/wiki", Link: "https://wiki.example.com", Problem: "wiki.example.com doesn't exist"},
+	}
+	if err := mailer.SendBrokenLinks(context.Background(), "alice@example.com", links); err != nil {
+		t.Fatalf("SMTPMailer.SendBrokenLinks() error = %v", err)
+	}
+
+	if len(sentTo) != 1 || sentTo[0] != "alice@example.com" {
+		t.Errorf("SMTPMailer.SendBrokenLinks() sent to %v, want alice@example.com", sentTo)
+	}
+	for _, want := range []string{
+		"To: alice@example.com\r\n",
+		"Subject: 2 golinks of yours look broken\r\n",
+		"go/old -> https://old.example.com\r\n  it answered 404 Not Found\r\n",
+		"Update or delete it at https://go.example.com/homepage/?q=team%2Fwiki\r\n",
+	} {
+		if !strings.Contains(sent, want) {
+			t.Errorf("SMTPMailer.SendBrokenLinks() sent %q, want it to contain %q", sent, want)
+		}
+	}
+
+	if err := mailer.SendBrokenLinks(context.Background(), "alice\r\nBcc: eve@example.com", links); err == nil {
+		t.Error("SMTPMailer.SendBrokenLinks() to a user name that isn't an address succeeded")
+	}
+}
+
+func TestNewSMTPMailer(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		from    string
+		wantErr bool
+	}{
+		{name: "valid", addr: "smtp.example.com:587", from: "golinks@example.com"},
+		{name: "named sender", addr: "smtp.example.com:25", from: "Go Links <golinks@example.com>"},
+		{name: "no port", addr: "smtp.example.com", from: "golinks@example.com", wantErr: true},
+		{name: "no sender", addr: "smtp.example.com:587", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSMTPMailer(tt.addr, tt.from, "", "", "https://go.example.com"); (err != nil) != tt.wantErr {
+				t.Errorf("NewSMTPMailer(%q, %q) error = %v, wantErr %v", tt.addr, tt.from, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// NamespaceRepository handles database operations for team namespaces and their members
+type NamespaceRepository struct {
+	db *sql.DB
+}
+
+// NewNamespaceRepository creates a new namespace repository
+func NewNamespaceRepository(db *sql.DB) *NamespaceRepository {
+	return &NamespaceRepository{db: db}
+}
+
+// Create stores a new namespace with its creator as the first owner
+func (r *NamespaceRepository) Create(ctx context.Context, namespace *domain.Namespace) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	namespace.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	owner := domain.NamespaceMember{User: namespace.CreatedBy, Role: domain.NamespaceOwnerRole, AddedAt: namespace.CreatedAt}
+
+	query := `INSERT INTO namespaces (name, created_by, created_at) VALUES (?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, namespace.Name, namespace.CreatedBy, namespace.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+
+	query = `INSERT INTO namespace_members (namespace, "user", role, added_at) VALUES (?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, namespace.Name, owner.User, string(owner.Role), owner.AddedAt); err != nil {
+		return fmt.Errorf("failed to add namespace owner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit namespace: %w", err)
+	}
+
+	namespace.Members = []domain.NamespaceMember{owner}
+	return nil
+}
+
+// Get retrieves a namespace with its members, or nil if it doesn't exist
+func (r *NamespaceRepository) Get(ctx context.Context, name string) (*domain.Namespace, error) {
+
+	query := `SELECT name, created_by, created_at FROM namespaces WHERE name = ?`
+
+	var namespace domain.Namespace
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&namespace.Name, &namespace.CreatedBy, &namespace.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	members, err := r.members(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	namespace.Members = members
+
+	return &namespace, nil
+}
+
+// members retrieves the members of a namespace, owners first
+func (r *NamespaceRepository) members(ctx context.Context, name string) ([]domain.NamespaceMember, error) {
+
+	query := `
+		SELECT "user", role, added_at FROM namespace_members
+		WHERE namespace = ?
+		ORDER BY role = 'owner' DESC, "user"
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []domain.NamespaceMember
+	for rows.Next() {
+		var member domain.NamespaceMember
+		if err := rows.Scan(&member.User, &member.Role, &member.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan namespace member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating namespace members: %w", err)
+	}
+
+	return members, nil
+}
+
+// List retrieves every namespace without its members, ordered by name
+func (r *NamespaceRepository) List(ctx context.Context) ([]domain.Namespace, error) {
+
+	query := `SELECT name, created_by, created_at FROM namespaces ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	defer rows.Close()
+
+	var namespaces []domain.Namespace
+	for rows.Next() {
+		var namespace domain.Namespace
+		if err := rows.Scan(&namespace.Name, &namespace.CreatedBy, &namespace.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan namespace: %w", err)
+		}
+		namespaces = append(namespaces, namespace)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating namespaces: %w", err)
+	}
+
+	return namespaces, nil
+}
+
+// Delete removes a namespace and its memberships, returning the number of namespaces removed
+func (r *NamespaceRepository) Delete(ctx context.Context, name string) (int64, error) {
+
+	query := `DELETE FROM namespaces WHERE name = ?`
+
+	result, err := r.db.ExecContext(ctx, query, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete namespace: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// MemberRole retrieves a user's role in a namespace, or "" if they aren't a member
+func (r *NamespaceRepository) MemberRole(ctx context.Context, name, user string) (domain.NamespaceRole, error) {
+
+	query := `SELECT role FROM namespace_members WHERE namespace = ? AND "user" = ?`
+
+	var role string
+	if err := r.db.QueryRowContext(ctx, query, name, user).Scan(&role); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get namespace member: %w", err)
+	}
+
+	return domain.NamespaceRole(role), nil
+}
+
+// SetMember adds a user to a namespace, or changes the role of an existing member
+func (r *NamespaceRepository) SetMember(ctx context.Context, name string, member *domain.NamespaceMember) error {
+
+	query := `
+		INSERT INTO namespace_members (namespace, "user", role, added_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(namespace, "user") DO UPDATE SET role = excluded.role
+	`
+
+	member.AddedAt = time.Now().UTC().Truncate(time.Second)
+	if _, err := r.db.ExecContext(ctx, query, name, member.User, string(member.Role), member.AddedAt); err != nil {
+		return fmt.Errorf("failed to set namespace member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes a user from a namespace, returning the number of members removed
+func (r *NamespaceRepository) RemoveMember(ctx context.Context, name, user string) (int64, error) {
+
+	query := `DELETE FROM namespace_members WHERE namespace = ? AND "user" = ?`
+
+	result, err := r.db.ExecContext(ctx, query, name, user)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove namespace member: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return removed, nil
+}
+
+// CountLinks returns the number of words under a namespace's prefix
+func (r *NamespaceRepository) CountLinks(ctx context.Context, name string) (int, error) {
+
+	query := `SELECT COUNT(DISTINCT word) FROM linktable WHERE substr(word, 1, ?) = ? AND deleted_at IS NULL`
+
+	prefix := name + "/"
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, len(prefix), prefix).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count namespace links: %w", err)
+	}
+
+	return count, nil
+}
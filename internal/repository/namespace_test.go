@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestNamespaceRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewNamespaceRepository(db)
+	ctx := context.Background()
+
+	if namespace, err := repo.Get(ctx, "payments"); err != nil || namespace != nil {
+		t.Fatalf("NamespaceRepository.Get() = %+v, %v, want none", namespace, err)
+	}
+
+	payments := &domain.Namespace{Name: "payments", CreatedBy: "alice"}
+	if err := repo.Create(ctx, payments); err != nil {
+		t.Fatalf("NamespaceRepository.Create() error = %v", err)
+	}
+	if payments.CreatedAt.IsZero() || len(payments.Members) != 1 {
+		t.Errorf("NamespaceRepository.Create() = %+v, want created_at and the creator as owner", payments)
+	}
+	if err := repo.Create(ctx, &domain.Namespace{Name: "payments", CreatedBy: "bob"}); err == nil {
+		t.Error("NamespaceRepository.Create() of an existing namespace should fail")
+	}
+	if err := repo.Create(ctx, &domain.Namespace{Name: "infra", CreatedBy: "bob"}); err != nil {
+		t.Fatalf("NamespaceRepository.Create() error = %v", err)
+	}
+
+	if err := repo.SetMember(ctx, "payments", &domain.NamespaceMember{User: "bob", Role: domain.NamespaceMemberRole}); err != nil {
+		t.Fatalf("NamespaceRepository.SetMember() error = %v", err)
+	}
+	// Setting a member again changes their role
+	if err := repo.SetMember(ctx, "payments", &domain.NamespaceMember{User: "carol", Role: domain.NamespaceMemberRole}); err != nil {
+		t.Fatalf("NamespaceRepository.SetMember() error = %v", err)
+	}
+	if err := repo.SetMember(ctx, "payments", &domain.NamespaceMember{User: "carol", Role: domain.NamespaceOwnerRole}); err != nil {
+		t.Fatalf("NamespaceRepository.SetMember() error = %v", err)
+	}
+
+	got, err := repo.Get(ctx, "payments")
+	if err != nil {
+		t.Fatalf("NamespaceRepository.Get() error = %v", err)
+	}
+	var users []string
+	for _, member := range got.Members {
+		users = append(users, member.User+":"+string(member.Role))
+	}
+	if want := "alice:owner carol:owner bob:member"; got.CreatedBy != "alice" || strings.Join(users, " ") != want {
+		t.Errorf("NamespaceRepository.Get() members = %v, want %s", users, want)
+	}
+
+	roleTests := []struct {
+		name string
+		user string
+		want domain.NamespaceRole
+	}{
+		{"owner", "alice", domain.NamespaceOwnerRole},
+		{"member", "bob", domain.NamespaceMemberRole},
+		{"not a member", "dave", ""},
+	}
+	for _, tt := range roleTests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, err := repo.MemberRole(ctx, "payments", tt.user)
+			if err != nil || role != tt.want {
+				t.Errorf("NamespaceRepository.MemberRole() = %q, %v, want %q", role, err, tt.want)
+			}
+		})
+	}
+
+	if removed, err := repo.RemoveMember(ctx, "payments", "bob"); err != nil || removed != 1 {
+		t.Errorf("NamespaceRepository.RemoveMember() = %d, %v, want 1", removed, err)
+	}
+	if removed, err := repo.RemoveMember(ctx, "payments", "bob"); err != nil || removed != 0 {
+		t.Errorf("NamespaceRepository.RemoveMember() twice = %d, %v, want 0", removed, err)
+	}
+
+	namespaces, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("NamespaceRepository.List() error = %v", err)
+	}
+	if len(namespaces) != 2 || namespaces[0].Name != "infra" || namespaces[1].Name != "payments" {
+		t.Errorf("NamespaceRepository.List() = %+v, want infra then payments", namespaces)
+	}
+
+	// Deleting a namespace removes its members too
+	if deleted, err := repo.Delete(ctx, "payments"); err != nil || deleted != 1 {
+		t.Errorf("NamespaceRepository.Delete() = %d, %v, want 1", deleted, err)
+	}
+	if role, err := repo.MemberRole(ctx, "payments", "alice"); err != nil || role != "" {
+		t.Errorf("NamespaceRepository.MemberRole() after delete = %q, %v, want none", role, err)
+	}
+}
+
+func TestNamespaceRepository_CountLinks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	repo := NewNamespaceRepository(db)
+	ctx := context.Background()
+
+	for _, shortcut := range []*domain.Shortcut{
+		{Word: "payments/runbook", Link: "https://runbook.example.com", User: "alice"},
+		{Word: "payments/runbook", Link: "https://runbook.example.com/v2", User: "alice"},
+		{Word: "payments/oncall", Link: "https://oncall.example.com", User: "bob"},
+		{Word: "payments", Link: "https://payments.example.com", User: "bob"},
+		{Word: "payments-old/runbook", Link: "https://old.example.com", User: "bob"},
+	} {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	count, err := repo.CountLinks(ctx, "payments")
+	if err != nil {
+		t.Fatalf("NamespaceRepository.CountLinks() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("NamespaceRepository.CountLinks() = %d, want 2", count)
+	}
+}
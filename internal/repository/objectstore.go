@@ -0,0 +1,380 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// ObjectStoreOptions locates and authenticates with an object store. Endpoint and Region
+// default by the scheme of the store's URL.
+type ObjectStoreOptions struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// ObjectStore keeps backups as objects under a prefix of a bucket in S3 or a store with
+// an S3-compatible API, such as Google Cloud Storage with HMAC keys or MinIO
+type ObjectStore struct {
+	client   *http.Client
+	endpoint *url.URL
+	bucket   string
+	prefix   string
+
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+
+	now func() time.Time
+}
+
+// NewObjectStore creates backup storage at location, an s3://bucket/prefix or
+// gs://bucket/prefix URL
+func NewObjectStore(location string, opts ObjectStoreOptions) (*ObjectStore, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object store URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("object store URL %s names no bucket", location)
+	}
+
+	region, endpoint := opts.Region, opts.Endpoint
+	switch u.Scheme {
+	case "s3":
+		if region == "" {
+			region = "us-east-1"
+		}
+		if endpoint == "" {
+			endpoint = "https://s3." + region + ".amazonaws.com"
+		}
+	case "gs":
+		if region == "" {
+			region = "auto"
+		}
+		if endpoint == "" {
+			endpoint = "https://storage.googleapis.com"
+		}
+	default:
+		return nil, fmt.Errorf("object store URL %s must start with s3:// or gs://", location)
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid object store endpoint %q", endpoint)
+	}
+	if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, errors.New("object store needs an access key ID and secret")
+	}
+
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &ObjectStore{
+		client:          &http.Client{Timeout: 10 * time.Minute},
+		endpoint:        endpointURL,
+		bucket:          u.Host,
+		prefix:          prefix,
+		region:          region,
+		accessKeyID:     opts.AccessKeyID,
+		secretAccessKey: opts.SecretAccessKey,
+		now:             time.Now,
+	}, nil
+}
+
+// Save uploads a backup from r under name
+func (s *ObjectStore) Save(ctx context.Context, name string, r io.Reader) (*domain.Backup, error) {
+	// Requests are signed with a hash of their body, so read it once to hash it first
+	body, ok := r.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, s.prefix+name, nil, io.NopCloser(body), hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload backup: %w", err)
+	}
+	resp.Body.Close()
+
+	return &domain.Backup{Name: name, Size: size, CreatedAt: s.now().UTC()}, nil
+}
+
+// Open downloads the named backup, returning nil if there is no such backup
+func (s *ObjectStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, s.prefix+name, nil, nil, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if errors.Is(err, errObjectNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+
+	return resp.Body, nil
+}
+
+// Delete removes the named backup, if it exists
+func (s *ObjectStore) Delete(ctx context.Context, name string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, s.prefix+name, nil, nil, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if errors.Is(err, errObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// listBucketResult is a page of a ListObjectsV2 response
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the backups directly under the prefix, newest first
+func (s *ObjectStore) List(ctx context.Context) ([]domain.Backup, error) {
+	var backups []domain.Backup
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, "", query, nil, emptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups: %w", err)
+		}
+
+		var page listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup list: %w", err)
+		}
+
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(object.Key, s.prefix)
+			if name == "" || strings.Contains(name, "/") {
+				continue
+			}
+			backups = append(backups, domain.Backup{Name: name, Size: object.Size, CreatedAt: object.LastModified.UTC()})
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// errObjectNotFound is returned by do when the object store answers 404
+var errObjectNotFound = errors.New("object not found")
+
+// objectStoreError is the error document an S3-compatible store answers failures with
+type objectStoreError struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do sends a signed request, turning error responses into errors
+func (s *ObjectStore) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && req.URL.RawQuery == "" {
+		return nil, errObjectNotFound
+	}
+	var body objectStoreError
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err != nil || body.Code == "" {
+		return nil, fmt.Errorf("object store answered %s", resp.Status)
+	}
+	return nil, fmt.Errorf("object store answered %s: %s: %s", resp.Status, body.Code, body.Message)
+}
+
+// newRequest creates a request for key in the bucket, or for the bucket itself if key is
+// empty, signed with AWS Signature Version 4
+func (s *ObjectStore) newRequest(
+	ctx context.Context, method, key string, query url.Values, body io.ReadCloser, payloadHash string,
+) (*http.Request, error) {
+
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object store request: %w", err)
+	}
+	if body != nil {
+		req.Body = body
+	}
+
+	signRequest(req, payloadHash, s.region, s.accessKeyID, s.secretAccessKey, s.now())
+	return req, nil
+}
+
+// signRequest signs req for the s3 service with AWS Signature Version 4, covering the
+// host and every header already set
+func signRequest(req *http.Request, payloadHash, region, accessKeyID, secretAccessKey string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query sorted by key, as Signature Version 4 signs it
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte of s but unreserved characters, and slashes
+// unless encodeSlash is set, as Signature Version 4 expects
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// postgresTestDSN names the PostgreSQL database the backend tests also run against. Its
+// schema is dropped before every test, so point it at a database kept for them.
+const postgresTestDSN = "POSTGRES_TEST_DSN"
+
+// forEachBackend runs test against a freshly migrated SQLite store and, when
+// POSTGRES_TEST_DSN is set, a PostgreSQL one. Running the same checks on both keeps the
+// PostgreSQL expectations honest where there is no server to run them against.
+func forEachBackend(t *testing.T, uniqueWords bool, test func(t *testing.T, store *Store)) {
+	t.Helper()
+	backends := []struct {
+		driver string
+		dsn    string
+	}{
+		{"sqlite", filepath.Join(t.TempDir(), "golinks.db")},
+		{"postgres", os.Getenv(postgresTestDSN)},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.driver, func(t *testing.T) {
+			if backend.dsn == "" {
+				t.Skipf("%s is not set", postgresTestDSN)
+			}
+			store, err := Open(backend.driver, Options{DSN: backend.dsn, UniqueWords: uniqueWords})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer store.Close()
+			ctx := context.Background()
+
+			if backend.driver == "postgres" {
+				db := store.Closer.(*sql.DB)
+				if _, err := db.ExecContext(ctx, `DROP SCHEMA public CASCADE; CREATE SCHEMA public`); err != nil {
+					t.Fatalf("Failed to reset the test database: %v", err)
+				}
+			}
+			if _, err := store.Migrations.Up(ctx); err != nil {
+				t.Fatalf("Migrations.Up() error = %v", err)
+			}
+			if err := store.Shortcuts.ApplyWordMode(ctx); err != nil {
+				t.Fatalf("Shortcuts.ApplyWordMode() error = %v", err)
+			}
+
+			test(t, store)
+		})
+	}
+}
+
+func TestBackends_Links(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		name := "versions as rows"
+		if uniqueWords {
+			name = "unique words"
+		}
+		t.Run(name, func(t *testing.T) {
+			forEachBackend(t, uniqueWords, testLinks)
+		})
+	}
+}
+
+func testLinks(t *testing.T, store *Store) {
+	ctx := context.Background()
+
+	before, err := store.Shortcuts.GetKeywordsVersion(ctx)
+	if err != nil {
+		t.Fatalf("Shortcuts.GetKeywordsVersion() error = %v", err)
+	}
+
+	docs := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "alice"}
+	if err := store.Shortcuts.Create(ctx, docs); err != nil {
+		t.Fatalf("Shortcuts.Create() error = %v", err)
+	}
+	firstDocs := docs.ID
+	docs = &domain.Shortcut{Word: "docs", Link: "https://docs.example.com/v2", User: "alice", UpdatedBy: "bob"}
+	if err := store.Shortcuts.Create(ctx, docs); err != nil {
+		t.Fatalf("Shortcuts.Create() error = %v", err)
+	}
+	wiki := &domain.Shortcut{Word: "wiki", Link: "https://wiki.example.com", User: "alice"}
+	payroll := &domain.Shortcut{Word: "payroll", Link: "https://payroll.example.com", User: "bob", Private: true}
+	if err := store.Shortcuts.CreateBatch(ctx, []*domain.Shortcut{wiki, payroll}); err != nil {
+		t.Fatalf("Shortcuts.CreateBatch() error = %v", err)
+	}
+
+	if after, err := store.Shortcuts.GetKeywordsVersion(ctx); err != nil || after == before {
+		t.Errorf("Shortcuts.GetKeywordsVersion() = %q, %v, want it to change from %q", after, err, before)
+	}
+	if got, err := store.Shortcuts.GetByWord(ctx, "docs"); err != nil || got == nil || got.Link != docs.Link || got.UpdatedBy != "bob" {
+		t.Errorf("Shortcuts.GetByWord() = %+v, %v, want the second version", got, err)
+	}
+	if history, err := store.Shortcuts.GetHistory(ctx, "docs"); err != nil || len(history) != 2 || history[0].Link != docs.Link {
+		t.Errorf("Shortcuts.GetHistory() = %+v, %v, want both versions newest first", history, err)
+	}
+
+	if err := store.Tags.AddTag(ctx, docs.ID, "engineering"); err != nil {
+		t.Fatalf("Tags.AddTag() error = %v", err)
+	}
+	if err := store.Aliases.Add(ctx, "d", "docs", "alice"); err != nil {
+		t.Fatalf("Aliases.Add() error = %v", err)
+	}
+	if target, err := store.Aliases.GetTarget(ctx, "d"); err != nil || target != "docs" {
+		t.Errorf("Aliases.GetTarget() = %q, %v, want docs", target, err)
+	}
+
+	// docs is clicked twice, once through its first version, and wiki once
+	clicks := []*domain.Query{{WordID: firstDocs}, {WordID: docs.ID, Referrer: "chat.example.com"}, {WordID: wiki.ID}}
+	if err := store.Queries.CreateBatch(ctx, clicks); err != nil {
+		t.Fatalf("Queries.CreateBatch() error = %v", err)
+	}
+
+	keywords, total, err := store.Shortcuts.GetKeywordsPage(ctx, nil, "", domain.KeywordSortMostUsed, "alice", 10, 0)
+	if err != nil {
+		t.Fatalf("Shortcuts.GetKeywordsPage() error = %v", err)
+	}
+	var words []string
+	var counts []int
+	for _, keyword := range keywords {
+		words = append(words, keyword.Word)
+		counts = append(counts, keyword.ClickCount)
+	}
+	if total != 2 || !reflect.DeepEqual(words, []string{"docs", "wiki"}) || !reflect.DeepEqual(counts, []int{2, 1}) {
+		t.Errorf("Shortcuts.GetKeywordsPage() = %v clicked %v of %d, want docs and wiki clicked [2 1] of 2", words, counts, total)
+	}
+	if len(keywords) > 0 && (!reflect.DeepEqual(keywords[0].Tags, []string{"engineering"}) || !reflect.DeepEqual(keywords[0].AlsoKnownAs, []string{"d"})) {
+		t.Errorf("Shortcuts.GetKeywordsPage() docs = %+v, want it tagged engineering with alias d", keywords[0])
+	}
+	if tagged, err := store.Tags.GetKeywordsByTag(ctx, "engineering", "alice"); err != nil || len(tagged) != 1 || tagged[0].Word != "docs" {
+		t.Errorf("Tags.GetKeywordsByTag() = %+v, %v, want docs", tagged, err)
+	}
+
+	// Rolling everything up keeps the counts and the daily clicks
+	if pruned, err := store.Queries.PruneBefore(ctx, time.Now().Add(time.Hour)); err != nil || pruned != 3 {
+		t.Errorf("Queries.PruneBefore() = %d, %v, want 3", pruned, err)
+	}
+	daily, err := store.Queries.GetDailyClicks(ctx, "docs", time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("Queries.GetDailyClicks() error = %v", err)
+	}
+	clicked := 0
+	for _, day := range daily {
+		clicked += day.Count
+	}
+	if clicked != 2 {
+		t.Errorf("Queries.GetDailyClicks() = %+v, want 2 clicks", daily)
+	}
+	if keywords, err := store.Shortcuts.GetKeywordsByPrefix(ctx, "docs", "alice", 1); err != nil || len(keywords) != 1 || keywords[0].ClickCount != 2 {
+		t.Errorf("Shortcuts.GetKeywordsByPrefix() = %+v, %v, want docs clicked twice after the rollup", keywords, err)
+	}
+
+	// Deleted links wait in the trash until restored or purged
+	if _, err := store.Shortcuts.DeleteByWord(ctx, "wiki"); err != nil {
+		t.Fatalf("Shortcuts.DeleteByWord() error = %v", err)
+	}
+	if trash, err := store.Shortcuts.GetDeleted(ctx); err != nil || len(trash) != 1 || trash[0].Word != "wiki" {
+		t.Errorf("Shortcuts.GetDeleted() = %+v, %v, want wiki", trash, err)
+	}
+	if restored, err := store.Shortcuts.RestoreByWord(ctx, "wiki"); err != nil || restored == 0 {
+		t.Errorf("Shortcuts.RestoreByWord() = %d, %v, want wiki restored", restored, err)
+	}
+	if _, err := store.Shortcuts.DeleteByWord(ctx, "wiki"); err != nil {
+		t.Fatalf("Shortcuts.DeleteByWord() error = %v", err)
+	}
+	if purged, err := store.Shortcuts.PurgeByWord(ctx, "wiki"); err != nil || purged == 0 {
+		t.Errorf("Shortcuts.PurgeByWord() = %d, %v, want wiki purged", purged, err)
+	}
+	if got, err := store.Shortcuts.GetByWord(ctx, "wiki"); err != nil || got != nil {
+		t.Errorf("Shortcuts.GetByWord() after purging = %+v, %v, want nothing", got, err)
+	}
+}
+
+func TestBackends_Accounts(t *testing.T) {
+	forEachBackend(t, false, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		now := time.Now().UTC().Truncate(time.Second)
+
+		key := &domain.APIKey{Name: "ci", User: "alice", Prefix: "glk_ci"}
+		if err := store.APIKeys.Create(ctx, key, "hash-ci"); err != nil {
+			t.Fatalf("APIKeys.Create() error = %v", err)
+		}
+		if got, err := store.APIKeys.GetByHash(ctx, "hash-ci"); err != nil || got == nil || got.User != "alice" {
+			t.Errorf("APIKeys.GetByHash() = %+v, %v, want alice's key", got, err)
+		}
+		if revoked, err := store.APIKeys.Revoke(ctx, key.ID); err != nil || revoked != 1 {
+			t.Errorf("APIKeys.Revoke() = %d, %v, want 1", revoked, err)
+		}
+
+		if err := store.Sessions.Create(ctx, "hash-alice", "alice", now.Add(time.Hour)); err != nil {
+			t.Fatalf("Sessions.Create() error = %v", err)
+		}
+		if err := store.Sessions.Create(ctx, "hash-old", "bob", now.Add(-time.Hour)); err != nil {
+			t.Fatalf("Sessions.Create() error = %v", err)
+		}
+		if user, err := store.Sessions.GetUser(ctx, "hash-alice", now); err != nil || user != "alice" {
+			t.Errorf("Sessions.GetUser() = %q, %v, want alice", user, err)
+		}
+		if expired, err := store.Sessions.DeleteExpired(ctx, now); err != nil || expired != 1 {
+			t.Errorf("Sessions.DeleteExpired() = %d, %v, want 1", expired, err)
+		}
+
+		if err := store.Roles.Set(ctx, &domain.UserRole{User: "bob", Role: domain.RoleAdmin}); err != nil {
+			t.Fatalf("Roles.Set() error = %v", err)
+		}
+		if role, err := store.Roles.Get(ctx, "bob"); err != nil || role != domain.RoleAdmin {
+			t.Errorf("Roles.Get() = %q, %v, want admin", role, err)
+		}
+
+		if err := store.Namespaces.Create(ctx, &domain.Namespace{Name: "payments", CreatedBy: "alice"}); err != nil {
+			t.Fatalf("Namespaces.Create() error = %v", err)
+		}
+		member := &domain.NamespaceMember{User: "bob", Role: domain.NamespaceMemberRole}
+		if err := store.Namespaces.SetMember(ctx, "payments", member); err != nil {
+			t.Fatalf("Namespaces.SetMember() error = %v", err)
+		}
+		if role, err := store.Namespaces.MemberRole(ctx, "payments", "bob"); err != nil || role != domain.NamespaceMemberRole {
+			t.Errorf("Namespaces.MemberRole() = %q, %v, want member", role, err)
+		}
+
+		if err := store.Shortcuts.Create(ctx, &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "alice"}); err != nil {
+			t.Fatalf("Shortcuts.Create() error = %v", err)
+		}
+		if err := store.Favorites.Add(ctx, "bob", "docs"); err != nil {
+			t.Fatalf("Favorites.Add() error = %v", err)
+		}
+		if pinned, err := store.Favorites.GetKeywords(ctx, "bob"); err != nil || len(pinned) != 1 || pinned[0].Word != "docs" {
+			t.Errorf("Favorites.GetKeywords() = %+v, %v, want docs", pinned, err)
+		}
+
+		if err := store.SyncState.SetCursor(ctx, "https://primary.example.com", 42); err != nil {
+			t.Fatalf("SyncState.SetCursor() error = %v", err)
+		}
+		if cursor, err := store.SyncState.GetCursor(ctx, "https://primary.example.com"); err != nil || cursor != 42 {
+			t.Errorf("SyncState.GetCursor() = %d, %v, want 42", cursor, err)
+		}
+
+		if stats, err := store.Stats.GetStorageStats(ctx); err != nil || stats == nil {
+			t.Errorf("Stats.GetStorageStats() = %+v, %v", stats, err)
+		}
+	})
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"golinks/internal/domain"
 )
 
+// rollupDay is the layout of query_rollups.day
+const rollupDay = "2006-01-02"
+
 // QueryRepository handles database operations for queries
 type QueryRepository struct {
 	db *sql.DB
@@ -18,19 +22,112 @@ func NewQueryRepository(db *sql.DB) *QueryRepository {
 	return &QueryRepository{db: db}
 }
 
-// Create creates a new query log entry
-func (r *QueryRepository) Create(ctx context.Context, wordID int) error {
-	query := `INSERT INTO queries (word_id, created_at) VALUES (?, CURRENT_TIMESTAMP)`
+// countClick adds clicks to the click count of the word of the link version with ID
+// wordID, which its latest row keeps for all of its versions
+const countClick = `
+	UPDATE linktable SET click_count = click_count + ?
+	WHERE id = (SELECT MAX(l.id) FROM linktable l JOIN linktable c ON c.word = l.word WHERE c.id = ?)
+`
+
+// loggedClicks counts the queries logged for every version of the word of linktable row
+// linktable and the ones rolled up
+const loggedClicks = `(
+		(SELECT COUNT(*) FROM queries q JOIN linktable c ON c.id = q.word_id WHERE c.word = linktable.word)
+		+ (SELECT COALESCE(SUM(r.count), 0) FROM query_rollups r JOIN linktable c ON c.id = r.word_id WHERE c.word = linktable.word)
+	)`
 
-	_, err := r.db.ExecContext(ctx, query, wordID)
+// recountClicks sets the click count of every word to the queries logged for it and the
+// ones rolled up, in case a count drifted
+const recountClicks = `
+	UPDATE linktable SET click_count = ` + loggedClicks + `
+	WHERE id IN (SELECT MAX(id) FROM linktable GROUP BY word) AND click_count <> ` + loggedClicks + `
+`
+
+// Create creates a new query log entry for q.WordID, recording who followed it, the host the
+// click came from and the kind of client it came through when known, and counts the click
+func (r *QueryRepository) Create(ctx context.Context, q *domain.Query) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO queries (word_id, "user", referrer, client, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, q.WordID, q.User, q.Referrer, q.Client); err != nil {
 		return fmt.Errorf("failed to create query log: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, countClick, 1, q.WordID); err != nil {
+		return fmt.Errorf("failed to count click: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CreateBatch logs and counts queries in one transaction, each at its CreatedAt, or now
+// when that is unset. Either every query is logged or none is.
+func (r *QueryRepository) CreateBatch(ctx context.Context, queries []*domain.Query) error {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO queries (word_id, "user", referrer, client, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare query log: %w", err)
+	}
+	defer stmt.Close()
+
+	// Truncated to the second like CURRENT_TIMESTAMP, so batched and direct queries sort alike
+	now := time.Now().UTC()
+	var wordIDs []int
+	clicks := map[int]int{}
+	for _, q := range queries {
+		createdAt := q.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		createdAt = createdAt.UTC().Truncate(time.Second)
+		if _, err := stmt.ExecContext(ctx, q.WordID, q.User, q.Referrer, q.Client, createdAt); err != nil {
+			return fmt.Errorf("failed to create query log: %w", err)
+		}
+		if clicks[q.WordID] == 0 {
+			wordIDs = append(wordIDs, q.WordID)
+		}
+		clicks[q.WordID]++
+	}
+
+	// Counting each link once keeps a burst of clicks on a popular link to one update
+	for _, wordID := range wordIDs {
+		if _, err := tx.ExecContext(ctx, countClick, clicks[wordID], wordID); err != nil {
+			return fmt.Errorf("failed to count clicks: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	return nil
 }
 
-// GetRecentQueries retrieves popular queries from the last N days
+// GetRecentQueries retrieves popular queries from the last N days, leaving out private and
+// deleted links
 func (r *QueryRepository) GetRecentQueries(
 	ctx context.Context, timeWindowDays, numResults int,
 ) ([]domain.PopularQuery, error) {
@@ -39,13 +136,18 @@ func (r *QueryRepository) GetRecentQueries(
 		SELECT COUNT(q.word_id) as count, s.word, s.link
 		FROM queries q
 		JOIN linktable s ON q.word_id = s.id
-		WHERE q.created_at > datetime('now', '-' || ? || ' days')
-		GROUP BY q.word_id
+		WHERE q.created_at > ? AND s.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM linktable p
+				WHERE p.id = (SELECT MAX(id) FROM linktable WHERE word = s.word AND deleted_at IS NULL) AND p.private = TRUE
+			)
+		GROUP BY s.id, s.word, s.link
 		ORDER BY count DESC
 		LIMIT ?
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, timeWindowDays, numResults)
+	since := time.Now().UTC().Truncate(time.Second).AddDate(0, 0, -timeWindowDays)
+	rows, err := r.db.QueryContext(ctx, query, since, numResults)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recent queries: %w", err)
 	}
@@ -67,3 +169,221 @@ func (r *QueryRepository) GetRecentQueries(
 
 	return queries, nil
 }
+
+// GetDailyClicks counts the queries that resolved word on each day since since, oldest first.
+// Days without clicks are left out.
+func (r *QueryRepository) GetDailyClicks(
+	ctx context.Context, word string, since time.Time,
+) ([]domain.ClickCount, error) {
+
+	// Both dialects render timestamps as text starting with YYYY-MM-DD. Days already pruned
+	// from queries are read back from their rollups.
+	query := `
+		SELECT day, SUM(n)
+		FROM (
+			SELECT substr(CAST(q.created_at AS TEXT), 1, 10) AS day, COUNT(*) AS n
+			FROM queries q
+			JOIN linktable l ON q.word_id = l.id
+			WHERE l.word = ? AND l.deleted_at IS NULL AND q.created_at >= ?
+			GROUP BY substr(CAST(q.created_at AS TEXT), 1, 10)
+			UNION ALL
+			SELECT r.day, r.count AS n
+			FROM query_rollups r
+			JOIN linktable l ON r.word_id = l.id
+			WHERE l.word = ? AND l.deleted_at IS NULL AND r.day >= ?
+		) clicks
+		GROUP BY day
+		ORDER BY day
+	`
+
+	since = since.UTC().Truncate(time.Second)
+	rows, err := r.db.QueryContext(ctx, query, word, since, word, since.Format(rollupDay))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily clicks: %w", err)
+	}
+	defer rows.Close()
+
+	var clicks []domain.ClickCount
+	for rows.Next() {
+		var day string
+		var click domain.ClickCount
+		if err := rows.Scan(&day, &click.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan daily clicks: %w", err)
+		}
+		click.Start, err = time.Parse(rollupDay, day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse click day %q: %w", day, err)
+		}
+		clicks = append(clicks, click)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily clicks: %w", err)
+	}
+
+	return clicks, nil
+}
+
+// GetTopReferrers counts the queries that resolved word since since by referring host, most
+// frequent first. Clicks without a referrer are left out.
+func (r *QueryRepository) GetTopReferrers(
+	ctx context.Context, word string, since time.Time, limit int,
+) ([]domain.ReferrerCount, error) {
+
+	query := `
+		SELECT q.referrer, COUNT(*) AS count
+		FROM queries q
+		JOIN linktable l ON q.word_id = l.id
+		WHERE l.word = ? AND l.deleted_at IS NULL AND q.created_at >= ? AND q.referrer <> ''
+		GROUP BY q.referrer
+		ORDER BY count DESC, q.referrer
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, word, since.UTC().Truncate(time.Second), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top referrers: %w", err)
+	}
+	defer rows.Close()
+
+	var referrers []domain.ReferrerCount
+	for rows.Next() {
+		var rc domain.ReferrerCount
+		if err := rows.Scan(&rc.Referrer, &rc.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan referrer: %w", err)
+		}
+		referrers = append(referrers, rc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating referrers: %w", err)
+	}
+
+	return referrers, nil
+}
+
+// GetUserTopLinks retrieves the links user followed most since since, with their current
+// targets. Deleted links and other users' private links are left out.
+func (r *QueryRepository) GetUserTopLinks(
+	ctx context.Context, user string, since time.Time, limit int,
+) ([]domain.PopularQuery, error) {
+
+	query := `
+		SELECT COUNT(*) AS count, c.word, c.link
+		FROM queries q
+		JOIN linktable s ON q.word_id = s.id
+		JOIN linktable c ON c.id = (SELECT MAX(id) FROM linktable WHERE word = s.word AND deleted_at IS NULL)
+		WHERE q."user" = ? AND q.created_at >= ? AND s.deleted_at IS NULL
+			AND (c.private = FALSE OR c."user" = ?)
+		GROUP BY c.id, c.word, c.link
+		ORDER BY count DESC, c.word
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, user, since.UTC().Truncate(time.Second), user, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user top links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []domain.PopularQuery
+	for rows.Next() {
+		var pq domain.PopularQuery
+		if err := rows.Scan(&pq.Count, &pq.Word, &pq.Link); err != nil {
+			return nil, fmt.Errorf("failed to scan user top link: %w", err)
+		}
+		links = append(links, pq)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user top links: %w", err)
+	}
+
+	return links, nil
+}
+
+// GetStaleLinks retrieves the links that haven't been followed or changed since since,
+// grouped by owner. Clicks on any version of a link count as use.
+func (r *QueryRepository) GetStaleLinks(ctx context.Context, since time.Time) ([]domain.StaleLink, error) {
+	query := `
+		SELECT l.word, l.link, l."user", l.private, l.created_at
+		FROM linktable l
+		WHERE l.id IN (SELECT MAX(id) FROM linktable WHERE deleted_at IS NULL GROUP BY word)
+			AND l.created_at < ?
+			AND NOT EXISTS (
+				SELECT 1 FROM queries q
+				JOIN linktable v ON q.word_id = v.id
+				WHERE v.word = l.word AND q.created_at >= ?
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM query_rollups r
+				JOIN linktable v ON r.word_id = v.id
+				WHERE v.word = l.word AND r.day >= ?
+			)
+		ORDER BY l."user", l.word
+	`
+
+	since = since.UTC().Truncate(time.Second)
+	rows, err := r.db.QueryContext(ctx, query, since, since, since.Format(rollupDay))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []domain.StaleLink
+	for rows.Next() {
+		var link domain.StaleLink
+		if err := rows.Scan(&link.Word, &link.Link, &link.User, &link.Private, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stale link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stale links: %w", err)
+	}
+
+	return links, nil
+}
+
+// PruneBefore rolls queries logged before the given time up into per-link daily counts and
+// deletes them, returning how many were deleted. It also corrects any click count that
+// has drifted from the queries and rollups it stands for.
+func (r *QueryRepository) PruneBefore(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before = before.UTC().Truncate(time.Second)
+	rollup := `
+		INSERT INTO query_rollups (word_id, day, count)
+		SELECT word_id, substr(CAST(created_at AS TEXT), 1, 10), COUNT(*)
+		FROM queries
+		WHERE created_at < ?
+		GROUP BY word_id, substr(CAST(created_at AS TEXT), 1, 10)
+	`
+	if _, err := tx.ExecContext(ctx, rollup, before); err != nil {
+		return 0, fmt.Errorf("failed to roll up queries: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM queries WHERE created_at < ?`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune queries: %w", err)
+	}
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pruned queries: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, recountClicks); err != nil {
+		return 0, fmt.Errorf("failed to recount clicks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return pruned, nil
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	"golinks/internal/domain"
 )
@@ -44,7 +46,7 @@ func TestQueryRepository_Create(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := queryRepo.Create(context.Background(), tt.wordID)
+			err := queryRepo.Create(context.Background(), &domain.Query{WordID: tt.wordID})
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("QueryRepository.Create() error = %v, wantErr %v", err, tt.wantErr)
@@ -53,6 +55,83 @@ func TestQueryRepository_Create(t *testing.T) {
 	}
 }
 
+func TestQueryRepository_Create_Source(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	shortcut := &domain.Shortcut{Word: "test", Link: "https://test.com", User: "testuser"}
+	if err := NewShortcutRepository(db).Create(ctx, shortcut); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+
+	want := domain.Query{WordID: shortcut.ID, User: "alice", Referrer: "wiki.example.com", Client: domain.ClientBrowser}
+	if err := NewQueryRepository(db).Create(ctx, &want); err != nil {
+		t.Fatalf("QueryRepository.Create() error = %v", err)
+	}
+
+	var got domain.Query
+	err := db.QueryRow(`SELECT word_id, user, referrer, client FROM queries`).
+		Scan(&got.WordID, &got.User, &got.Referrer, &got.Client)
+	if err != nil || got != want {
+		t.Errorf("logged query = %+v, %v, want %+v", got, err, want)
+	}
+
+	var clickCount int
+	if err := db.QueryRow(`SELECT click_count FROM linktable WHERE id = ?`, shortcut.ID).Scan(&clickCount); err != nil || clickCount != 1 {
+		t.Errorf("click count = %d, %v, want 1", clickCount, err)
+	}
+}
+
+func TestQueryRepository_CreateBatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	shortcut := &domain.Shortcut{Word: "test", Link: "https://test.com", User: "testuser"}
+	if err := NewShortcutRepository(db).Create(ctx, shortcut); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+	queryRepo := NewQueryRepository(db)
+
+	clicked := time.Date(2024, 3, 1, 12, 30, 15, 500, time.UTC)
+	err := queryRepo.CreateBatch(ctx, []*domain.Query{
+		{WordID: shortcut.ID, User: "alice", CreatedAt: clicked},
+		{WordID: shortcut.ID, User: "bob", Referrer: "wiki.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("QueryRepository.CreateBatch() error = %v", err)
+	}
+
+	// A query for a missing link fails the whole batch
+	err = queryRepo.CreateBatch(ctx, []*domain.Query{{WordID: shortcut.ID}, {WordID: 999}})
+	if err == nil {
+		t.Error("QueryRepository.CreateBatch() with a missing link succeeded")
+	}
+	if err := queryRepo.CreateBatch(ctx, nil); err != nil {
+		t.Errorf("QueryRepository.CreateBatch(nil) error = %v", err)
+	}
+
+	var count, clickCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM queries`).Scan(&count); err != nil || count != 2 {
+		t.Fatalf("logged %d queries, %v, want 2", count, err)
+	}
+	if err := db.QueryRow(`SELECT click_count FROM linktable WHERE id = ?`, shortcut.ID).Scan(&clickCount); err != nil || clickCount != 2 {
+		t.Errorf("click count = %d, %v, want 2 from the batch that was logged", clickCount, err)
+	}
+
+	// Queries are logged at their click time, to the second
+	clicks, err := queryRepo.GetDailyClicks(ctx, "test", clicked.AddDate(0, 0, -1))
+	if err != nil || len(clicks) != 2 || !clicks[0].Start.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("QueryRepository.GetDailyClicks() = %+v, %v, want the batched day and today", clicks, err)
+	}
+	var user string
+	err = db.QueryRow(`SELECT "user" FROM queries WHERE created_at < ?`, clicked.Add(time.Second)).Scan(&user)
+	if err != nil || user != "alice" {
+		t.Errorf("query logged before %s by %q, %v, want alice", clicked.Add(time.Second), user, err)
+	}
+}
+
 func TestQueryRepository_GetRecentQueries(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -94,7 +173,7 @@ func TestQueryRepository_GetRecentQueries(t *testing.T) {
 
 		// Create multiple queries for this shortcut
 		for i := 0; i < data.count; i++ {
-			err := queryRepo.Create(context.Background(), shortcut.ID)
+			err := queryRepo.Create(context.Background(), &domain.Query{WordID: shortcut.ID})
 			if err != nil {
 				t.Fatalf("Failed to create query for word %s: %v", data.word, err)
 			}
@@ -178,6 +257,42 @@ func TestQueryRepository_GetRecentQueries(t *testing.T) {
 	}
 }
 
+func TestQueryRepository_GetRecentQueries_Private(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	queryRepo := NewQueryRepository(db)
+	ctx := context.Background()
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "payroll", Link: "https://payroll.example.com", User: "user1"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+		if err := queryRepo.Create(ctx, &domain.Query{WordID: shortcut.ID}); err != nil {
+			t.Fatalf("Failed to create query: %v", err)
+		}
+	}
+
+	// Queries logged while payroll was public are hidden once it becomes private
+	private := &domain.Shortcut{Word: "payroll", Link: "https://payroll.example.com", User: "user1", Private: true}
+	if err := shortcutRepo.Create(ctx, private); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+
+	queries, err := queryRepo.GetRecentQueries(ctx, 1, 10)
+	if err != nil {
+		t.Fatalf("QueryRepository.GetRecentQueries() error = %v", err)
+	}
+	if len(queries) != 1 || queries[0].Word != "docs" {
+		t.Errorf("QueryRepository.GetRecentQueries() = %+v, want only docs", queries)
+	}
+}
+
 func TestQueryRepository_GetRecentQueries_TimeWindow(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -197,7 +312,7 @@ func TestQueryRepository_GetRecentQueries_TimeWindow(t *testing.T) {
 	}
 
 	// Create a query
-	err = queryRepo.Create(context.Background(), shortcut.ID)
+	err = queryRepo.Create(context.Background(), &domain.Query{WordID: shortcut.ID})
 	if err != nil {
 		t.Fatalf("Failed to create query: %v", err)
 	}
@@ -237,6 +352,376 @@ func TestQueryRepository_GetRecentQueries_TimeWindow(t *testing.T) {
 	}
 }
 
+func TestQueryRepository_Stats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	queryRepo := NewQueryRepository(db)
+	ctx := context.Background()
+
+	first := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "user1"}
+	second := &domain.Shortcut{Word: "docs", Link: "https://docs.example.org", User: "user1"}
+	other := &domain.Shortcut{Word: "github", Link: "https://github.com", User: "user1"}
+	for _, shortcut := range []*domain.Shortcut{first, second, other} {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	// Clicks on every revision of docs count towards it, clicks on github don't
+	clicks := []struct {
+		wordID    int
+		referrer  string
+		createdAt string
+	}{
+		{first.ID, "wiki.example.com", "2024-01-01 09:00:00"},
+		{first.ID, "", "2024-01-02 09:00:00"},
+		{second.ID, "wiki.example.com", "2024-01-02 17:30:00"},
+		{second.ID, "chat.example.com", "2024-01-04 08:00:00"},
+		{other.ID, "chat.example.com", "2024-01-04 08:00:00"},
+	}
+	for _, c := range clicks {
+		if _, err := db.Exec(`INSERT INTO queries (word_id, referrer, created_at) VALUES (?, ?, ?)`,
+			c.wordID, c.referrer, c.createdAt); err != nil {
+			t.Fatalf("Failed to create query: %v", err)
+		}
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name          string
+		word          string
+		since         time.Time
+		limit         int
+		wantClicks    []domain.ClickCount
+		wantReferrers []domain.ReferrerCount
+	}{
+		{
+			name:       "all clicks",
+			word:       "docs",
+			since:      day(1),
+			limit:      10,
+			wantClicks: []domain.ClickCount{{Start: day(1), Count: 1}, {Start: day(2), Count: 2}, {Start: day(4), Count: 1}},
+			wantReferrers: []domain.ReferrerCount{
+				{Referrer: "wiki.example.com", Count: 2},
+				{Referrer: "chat.example.com", Count: 1},
+			},
+		},
+		{
+			name:          "since a later day",
+			word:          "docs",
+			since:         day(3),
+			limit:         10,
+			wantClicks:    []domain.ClickCount{{Start: day(4), Count: 1}},
+			wantReferrers: []domain.ReferrerCount{{Referrer: "chat.example.com", Count: 1}},
+		},
+		{
+			name:          "limited referrers",
+			word:          "docs",
+			since:         day(1),
+			limit:         1,
+			wantClicks:    []domain.ClickCount{{Start: day(1), Count: 1}, {Start: day(2), Count: 2}, {Start: day(4), Count: 1}},
+			wantReferrers: []domain.ReferrerCount{{Referrer: "wiki.example.com", Count: 2}},
+		},
+		{
+			name:  "unknown word",
+			word:  "missing",
+			since: day(1),
+			limit: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clicks, err := queryRepo.GetDailyClicks(ctx, tt.word, tt.since)
+			if err != nil {
+				t.Fatalf("QueryRepository.GetDailyClicks() error = %v", err)
+			}
+			if !reflect.DeepEqual(clicks, tt.wantClicks) {
+				t.Errorf("QueryRepository.GetDailyClicks() = %+v, want %+v", clicks, tt.wantClicks)
+			}
+
+			referrers, err := queryRepo.GetTopReferrers(ctx, tt.word, tt.since, tt.limit)
+			if err != nil {
+				t.Fatalf("QueryRepository.GetTopReferrers() error = %v", err)
+			}
+			if !reflect.DeepEqual(referrers, tt.wantReferrers) {
+				t.Errorf("QueryRepository.GetTopReferrers() = %+v, want %+v", referrers, tt.wantReferrers)
+			}
+		})
+	}
+
+	// Trashed links have no stats
+	if _, err := shortcutRepo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("DeleteByWord() error = %v", err)
+	}
+	if clicks, _ := queryRepo.GetDailyClicks(ctx, "docs", day(1)); len(clicks) != 0 {
+		t.Errorf("QueryRepository.GetDailyClicks() = %+v, want no clicks for a trashed link", clicks)
+	}
+}
+
+func TestQueryRepository_GetUserTopLinks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	queryRepo := NewQueryRepository(db)
+	ctx := context.Background()
+
+	docs := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "alice"}
+	github := &domain.Shortcut{Word: "github", Link: "https://github.com", User: "bob"}
+	payroll := &domain.Shortcut{Word: "payroll", Link: "https://payroll.example.com", User: "bob"}
+	for _, shortcut := range []*domain.Shortcut{docs, github, payroll} {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	follows := []struct {
+		wordID int
+		user   string
+	}{
+		{docs.ID, "alice"}, {docs.ID, "alice"}, {github.ID, "alice"}, {payroll.ID, "alice"},
+		{github.ID, "bob"}, {payroll.ID, "bob"},
+	}
+	for _, f := range follows {
+		if err := queryRepo.Create(ctx, &domain.Query{WordID: f.wordID, User: f.user}); err != nil {
+			t.Fatalf("Failed to create query: %v", err)
+		}
+	}
+
+	// Later versions replace the link shown, and a link made private hides it from others
+	docsV2 := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com/v2", User: "alice"}
+	private := &domain.Shortcut{Word: "payroll", Link: "https://payroll.example.com", User: "bob", Private: true}
+	for _, shortcut := range []*domain.Shortcut{docsV2, private} {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	since := time.Now().AddDate(0, 0, -1)
+	tests := []struct {
+		name  string
+		user  string
+		since time.Time
+		limit int
+		want  []domain.PopularQuery
+	}{
+		{
+			name:  "alice",
+			user:  "alice",
+			since: since,
+			limit: 10,
+			want: []domain.PopularQuery{
+				{Count: 2, Word: "docs", Link: "https://docs.example.com/v2"},
+				{Count: 1, Word: "github", Link: "https://github.com"},
+			},
+		},
+		{
+			name:  "owner still sees a private link",
+			user:  "bob",
+			since: since,
+			limit: 10,
+			want: []domain.PopularQuery{
+				{Count: 1, Word: "github", Link: "https://github.com"},
+				{Count: 1, Word: "payroll", Link: "https://payroll.example.com"},
+			},
+		},
+		{
+			name:  "limited",
+			user:  "alice",
+			since: since,
+			limit: 1,
+			want:  []domain.PopularQuery{{Count: 2, Word: "docs", Link: "https://docs.example.com/v2"}},
+		},
+		{name: "outside the window", user: "alice", since: time.Now().AddDate(0, 0, 1), limit: 10},
+		{name: "someone else", user: "carol", since: since, limit: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := queryRepo.GetUserTopLinks(ctx, tt.user, tt.since, tt.limit)
+			if err != nil {
+				t.Fatalf("QueryRepository.GetUserTopLinks() error = %v", err)
+			}
+			if !reflect.DeepEqual(links, tt.want) {
+				t.Errorf("QueryRepository.GetUserTopLinks() = %+v, want %+v", links, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryRepository_GetStaleLinks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	queryRepo := NewQueryRepository(db)
+	ctx := context.Background()
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "bob"},
+		{Word: "wiki", Link: "https://wiki.example.com", User: "alice"},
+		{Word: "wiki", Link: "https://wiki.example.org", User: "alice"},
+		{Word: "old", Link: "https://old.example.com", User: "bob"},
+		{Word: "new", Link: "https://new.example.com", User: "bob"},
+		{Word: "gone", Link: "https://gone.example.com", User: "bob"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if _, err := shortcutRepo.DeleteByWord(ctx, "gone"); err != nil {
+		t.Fatalf("DeleteByWord() error = %v", err)
+	}
+
+	// Everything but new was last changed in January; clicks on an older version of wiki
+	// count as well
+	if _, err := db.Exec(`UPDATE linktable SET created_at = '2024-01-01 00:00:00' WHERE word <> 'new'`); err != nil {
+		t.Fatalf("Failed to age links: %v", err)
+	}
+	clicks := []struct {
+		wordID    int
+		createdAt string
+	}{
+		{shortcuts[0].ID, "2024-01-10 00:00:00"},
+		{shortcuts[0].ID, "2024-03-10 00:00:00"},
+		{shortcuts[1].ID, "2024-03-10 00:00:00"},
+		{shortcuts[3].ID, "2024-01-10 00:00:00"},
+	}
+	for _, c := range clicks {
+		if _, err := db.Exec(`INSERT INTO queries (word_id, created_at) VALUES (?, ?)`, c.wordID, c.createdAt); err != nil {
+			t.Fatalf("Failed to create query: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  []string
+	}{
+		{"unused since February", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), []string{"old"}},
+		{"unused since April", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), []string{"wiki", "docs", "old"}},
+		{"unused since before any link", time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := queryRepo.GetStaleLinks(ctx, tt.since)
+			if err != nil {
+				t.Fatalf("QueryRepository.GetStaleLinks() error = %v", err)
+			}
+			var words []string
+			for _, link := range links {
+				words = append(words, link.Word)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("QueryRepository.GetStaleLinks() = %v, want %v", words, tt.want)
+			}
+			if len(links) > 0 && links[0].Word == "wiki" && (links[0].User != "alice" || links[0].Link != "https://wiki.example.org") {
+				t.Errorf("QueryRepository.GetStaleLinks() wiki = %+v, want alice's latest version", links[0])
+			}
+		})
+	}
+}
+
+func TestQueryRepository_PruneBefore(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	queryRepo := NewQueryRepository(db)
+	ctx := context.Background()
+
+	docs := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "user1"}
+	wiki := &domain.Shortcut{Word: "wiki", Link: "https://wiki.example.com", User: "user1"}
+	for _, shortcut := range []*domain.Shortcut{docs, wiki} {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if _, err := db.Exec(`UPDATE linktable SET created_at = '2023-01-01 00:00:00'`); err != nil {
+		t.Fatalf("Failed to age links: %v", err)
+	}
+
+	clicks := []struct {
+		wordID    int
+		createdAt string
+	}{
+		{docs.ID, "2024-01-01 09:00:00"},
+		{docs.ID, "2024-01-01 17:00:00"},
+		{docs.ID, "2024-01-02 09:00:00"},
+		{wiki.ID, "2024-01-02 10:00:00"},
+		{docs.ID, "2024-01-04 08:00:00"},
+	}
+	for _, c := range clicks {
+		if _, err := db.Exec(`INSERT INTO queries (word_id, referrer, created_at) VALUES (?, 'chat.example.com', ?)`,
+			c.wordID, c.createdAt); err != nil {
+			t.Fatalf("Failed to create query: %v", err)
+		}
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	pruned, err := queryRepo.PruneBefore(ctx, day(3))
+	if err != nil {
+		t.Fatalf("QueryRepository.PruneBefore() error = %v", err)
+	}
+	if pruned != 4 {
+		t.Errorf("QueryRepository.PruneBefore() = %d, want 4", pruned)
+	}
+
+	var remaining int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM queries`).Scan(&remaining); err != nil || remaining != 1 {
+		t.Errorf("%d queries remain (%v), want 1", remaining, err)
+	}
+
+	// The queries were logged behind the counters' back, which pruning corrects
+	var clickCount int
+	if err := db.QueryRow(`SELECT click_count FROM linktable WHERE id = ?`, docs.ID).Scan(&clickCount); err != nil || clickCount != 4 {
+		t.Errorf("docs click count = %d, %v, want 4 after recounting", clickCount, err)
+	}
+
+	// Daily clicks read pruned days back from their rollups
+	daily, err := queryRepo.GetDailyClicks(ctx, "docs", day(1))
+	if err != nil {
+		t.Fatalf("QueryRepository.GetDailyClicks() error = %v", err)
+	}
+	want := []domain.ClickCount{{Start: day(1), Count: 2}, {Start: day(2), Count: 1}, {Start: day(4), Count: 1}}
+	if !reflect.DeepEqual(daily, want) {
+		t.Errorf("QueryRepository.GetDailyClicks() = %+v, want %+v", daily, want)
+	}
+
+	// Referrers are only kept for queries within the retention period
+	referrers, err := queryRepo.GetTopReferrers(ctx, "docs", day(1), 10)
+	if err != nil {
+		t.Fatalf("QueryRepository.GetTopReferrers() error = %v", err)
+	}
+	if len(referrers) != 1 || referrers[0].Count != 1 {
+		t.Errorf("QueryRepository.GetTopReferrers() = %+v, want the one unpruned click", referrers)
+	}
+
+	// A rolled up click still counts as use
+	stale, err := queryRepo.GetStaleLinks(ctx, day(2))
+	if err != nil {
+		t.Fatalf("QueryRepository.GetStaleLinks() error = %v", err)
+	}
+	if len(stale) != 0 {
+		t.Errorf("QueryRepository.GetStaleLinks() = %+v, want none", stale)
+	}
+
+	// Pruning again finds nothing new and leaves the rollups alone
+	if pruned, err := queryRepo.PruneBefore(ctx, day(3)); err != nil || pruned != 0 {
+		t.Errorf("QueryRepository.PruneBefore() again = %d, %v, want 0", pruned, err)
+	}
+	if daily, _ := queryRepo.GetDailyClicks(ctx, "docs", day(1)); !reflect.DeepEqual(daily, want) {
+		t.Errorf("QueryRepository.GetDailyClicks() after a second prune = %+v, want %+v", daily, want)
+	}
+}
+
 func TestQueryRepository_DatabaseError(t *testing.T) {
 	// Test with closed database to simulate database errors
 	db := setupTestDB(t)
@@ -245,7 +730,7 @@ func TestQueryRepository_DatabaseError(t *testing.T) {
 	repo := NewQueryRepository(db)
 
 	// Test Create with closed DB
-	err := repo.Create(context.Background(), 1)
+	err := repo.Create(context.Background(), &domain.Query{WordID: 1})
 	if err == nil {
 		t.Error("Expected error with closed database, got nil")
 	}
@@ -255,6 +740,13 @@ func TestQueryRepository_DatabaseError(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error with closed database, got nil")
 	}
+
+	if _, err := repo.GetDailyClicks(context.Background(), "test", time.Now()); err == nil {
+		t.Error("Expected error with closed database, got nil")
+	}
+	if _, err := repo.GetTopReferrers(context.Background(), "test", time.Now(), 10); err == nil {
+		t.Error("Expected error with closed database, got nil")
+	}
 }
 
 func TestQueryRepository_EmptyResults(t *testing.T) {
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golinks/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisLinkPrefix prefixes the keys shortcuts are cached under, by word
+	redisLinkPrefix = "golinks:link:"
+
+	// redisInvalidateChannel carries the words servers drop from their caches, as a JSON
+	// array; an empty array drops every word
+	redisInvalidateChannel = "golinks:invalidate"
+)
+
+// RedisCache caches shortcuts in Redis for every server sharing a database, and broadcasts
+// invalidations to them over pub/sub
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache connects to the Redis server at url, such as redis://localhost:6379/0.
+// Connections are made lazily, so a server that is down only fails the calls made to it.
+func NewRedisCache(url string) (*RedisCache, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+	return &RedisCache{client: redis.NewClient(options)}, nil
+}
+
+// Ping checks that Redis is reachable
+func (c *RedisCache) Ping(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping Redis: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connections to Redis
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// GetShortcut returns the shortcut cached for word. found is false when nothing is cached,
+// and true with a nil shortcut when the word is cached as not existing.
+func (c *RedisCache) GetShortcut(ctx context.Context, word string) (*domain.Shortcut, bool, error) {
+	data, err := c.client.Get(ctx, redisLinkPrefix+word).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get cached shortcut: %w", err)
+	}
+
+	var shortcut *domain.Shortcut
+	if err := json.Unmarshal(data, &shortcut); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cached shortcut: %w", err)
+	}
+
+	return shortcut, true, nil
+}
+
+// SetShortcut caches shortcut, or that word doesn't exist if it is nil, for ttl
+func (c *RedisCache) SetShortcut(ctx context.Context, word string, shortcut *domain.Shortcut, ttl time.Duration) error {
+	data, err := json.Marshal(shortcut)
+	if err != nil {
+		return fmt.Errorf("failed to encode shortcut: %w", err)
+	}
+	if err := c.client.Set(ctx, redisLinkPrefix+word, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache shortcut: %w", err)
+	}
+	return nil
+}
+
+// Invalidate deletes words from the cache, or every word if none are given, and tells
+// every subscribed server to drop them
+func (c *RedisCache) Invalidate(ctx context.Context, words ...string) error {
+	keys := make([]string, len(words))
+	for i, word := range words {
+		keys[i] = redisLinkPrefix + word
+	}
+	if len(words) == 0 {
+		iter := c.client.Scan(ctx, 0, redisLinkPrefix+"*", 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("failed to list cached shortcuts: %w", err)
+		}
+	}
+
+	if len(keys) > 0 {
+		if err := c.client.Del(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("failed to delete cached shortcuts: %w", err)
+		}
+	}
+
+	if words == nil {
+		words = []string{}
+	}
+	message, err := json.Marshal(words)
+	if err != nil {
+		return fmt.Errorf("failed to encode invalidation: %w", err)
+	}
+	if err := c.client.Publish(ctx, redisInvalidateChannel, message).Err(); err != nil {
+		return fmt.Errorf("failed to publish invalidation: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe calls drop with the words any server invalidates until ctx is done,
+// resubscribing whenever the connection to Redis drops
+func (c *RedisCache) Subscribe(ctx context.Context, drop func(words ...string)) error {
+	subscription := c.client.Subscribe(ctx, redisInvalidateChannel)
+	defer subscription.Close()
+
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			var words []string
+			if err := json.Unmarshal([]byte(message.Payload), &words); err != nil {
+				continue
+			}
+			drop(words...)
+		}
+	}
+}
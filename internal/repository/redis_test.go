@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func setupTestRedis(t *testing.T) (*miniredis.Miniredis, *RedisCache) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	cache, err := NewRedisCache("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedisCache() error = %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return server, cache
+}
+
+func TestRedisCache_Shortcuts(t *testing.T) {
+	server, cache := setupTestRedis(t)
+	ctx := context.Background()
+
+	docs := &domain.Shortcut{ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := cache.SetShortcut(ctx, "docs", docs, time.Minute); err != nil {
+		t.Fatalf("RedisCache.SetShortcut() error = %v", err)
+	}
+	if err := cache.SetShortcut(ctx, "missing", nil, time.Minute); err != nil {
+		t.Fatalf("RedisCache.SetShortcut() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		word      string
+		want      *domain.Shortcut
+		wantFound bool
+	}{
+		{"cached shortcut", "docs", docs, true},
+		{"cached as missing", "missing", nil, true},
+		{"not cached", "github", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcut, found, err := cache.GetShortcut(ctx, tt.word)
+			if err != nil {
+				t.Fatalf("RedisCache.GetShortcut() error = %v", err)
+			}
+			if found != tt.wantFound || !reflect.DeepEqual(shortcut, tt.want) {
+				t.Errorf("RedisCache.GetShortcut(%q) = %+v, %v, want %+v, %v", tt.word, shortcut, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+
+	// Entries expire after their TTL
+	server.FastForward(time.Minute)
+	if _, found, _ := cache.GetShortcut(ctx, "docs"); found {
+		t.Error("RedisCache.GetShortcut() found docs after its TTL")
+	}
+
+	server.Close()
+	if _, _, err := cache.GetShortcut(ctx, "docs"); err == nil {
+		t.Error("RedisCache.GetShortcut() error = nil with Redis down")
+	}
+}
+
+func TestRedisCache_Invalidate(t *testing.T) {
+	_, cache := setupTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dropped := make(chan []string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- cache.Subscribe(ctx, func(words ...string) { dropped <- words })
+	}()
+
+	// Wait for the subscription before publishing
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		channels, err := cache.client.PubSubNumSub(ctx, redisInvalidateChannel).Result()
+		if err == nil && channels[redisInvalidateChannel] > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Subscribe() never subscribed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, word := range []string{"docs", "github", "wiki"} {
+		if err := cache.SetShortcut(ctx, word, &domain.Shortcut{Word: word}, time.Minute); err != nil {
+			t.Fatalf("RedisCache.SetShortcut() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		words       []string
+		wantDropped []string
+		wantCached  []string
+	}{
+		{"some words", []string{"docs", "github"}, []string{"docs", "github"}, []string{"wiki"}},
+		{"every word", nil, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cache.Invalidate(ctx, tt.words...); err != nil {
+				t.Fatalf("RedisCache.Invalidate() error = %v", err)
+			}
+
+			select {
+			case words := <-dropped:
+				if len(words) != len(tt.wantDropped) || (len(words) > 0 && !reflect.DeepEqual(words, tt.wantDropped)) {
+					t.Errorf("subscriber dropped %v, want %v", words, tt.wantDropped)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("subscriber was never told to drop any words")
+			}
+
+			var cached []string
+			for _, word := range []string{"docs", "github", "wiki"} {
+				if _, found, _ := cache.GetShortcut(ctx, word); found {
+					cached = append(cached, word)
+				}
+			}
+			sort.Strings(cached)
+			if !reflect.DeepEqual(cached, tt.wantCached) {
+				t.Errorf("still cached %v, want %v", cached, tt.wantCached)
+			}
+		})
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("RedisCache.Subscribe() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("RedisCache.Subscribe() kept running after its context was done")
+	}
+}
+
+func TestNewRedisCache_BadURL(t *testing.T) {
+	if _, err := NewRedisCache("http://localhost:6379"); err == nil {
+		t.Error("NewRedisCache() error = nil for a URL that isn't redis://")
+	}
+}
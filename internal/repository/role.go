@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// RoleRepository handles database operations for user roles
+type RoleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// Get retrieves the role assigned to user, or "" if none is
+func (r *RoleRepository) Get(ctx context.Context, user string) (domain.Role, error) {
+
+	query := `SELECT role FROM user_roles WHERE "user" = ?`
+
+	var role string
+	if err := r.db.QueryRowContext(ctx, query, user).Scan(&role); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return domain.Role(role), nil
+}
+
+// Set assigns a role to a user, replacing any role they had
+func (r *RoleRepository) Set(ctx context.Context, userRole *domain.UserRole) error {
+
+	query := `
+		INSERT INTO user_roles ("user", role, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT("user") DO UPDATE SET role = excluded.role, updated_at = excluded.updated_at
+	`
+
+	userRole.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	if _, err := r.db.ExecContext(ctx, query, userRole.User, string(userRole.Role), userRole.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to set role: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user's role, returning the number of roles removed
+func (r *RoleRepository) Delete(ctx context.Context, user string) (int64, error) {
+
+	query := `DELETE FROM user_roles WHERE "user" = ?`
+
+	result, err := r.db.ExecContext(ctx, query, user)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// List retrieves every assigned role, ordered by user
+func (r *RoleRepository) List(ctx context.Context) ([]domain.UserRole, error) {
+
+	query := `SELECT "user", role, updated_at FROM user_roles ORDER BY "user"`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []domain.UserRole
+	for rows.Next() {
+		var userRole domain.UserRole
+		if err := rows.Scan(&userRole.User, &userRole.Role, &userRole.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, userRole)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roles: %w", err)
+	}
+
+	return roles, nil
+}
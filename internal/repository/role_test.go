@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestRoleRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+	ctx := context.Background()
+
+	if role, err := repo.Get(ctx, "alice"); err != nil || role != "" {
+		t.Fatalf("RoleRepository.Get() = %q, %v, want no role", role, err)
+	}
+
+	alice := &domain.UserRole{User: "alice", Role: domain.RoleViewer}
+	if err := repo.Set(ctx, alice); err != nil {
+		t.Fatalf("RoleRepository.Set() error = %v", err)
+	}
+	if alice.UpdatedAt.IsZero() {
+		t.Error("RoleRepository.Set() did not fill in updated_at")
+	}
+	if err := repo.Set(ctx, &domain.UserRole{User: "bob", Role: domain.RoleEditor}); err != nil {
+		t.Fatalf("RoleRepository.Set() error = %v", err)
+	}
+
+	// Setting a role again replaces it
+	if err := repo.Set(ctx, &domain.UserRole{User: "alice", Role: domain.RoleAdmin}); err != nil {
+		t.Fatalf("RoleRepository.Set() error = %v", err)
+	}
+	if role, err := repo.Get(ctx, "alice"); err != nil || role != domain.RoleAdmin {
+		t.Errorf("RoleRepository.Get() = %q, %v, want admin", role, err)
+	}
+
+	roles, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("RoleRepository.List() error = %v", err)
+	}
+	if len(roles) != 2 || roles[0].User != "alice" || roles[0].Role != domain.RoleAdmin || roles[1].User != "bob" {
+		t.Errorf("RoleRepository.List() = %+v, want alice then bob", roles)
+	}
+
+	tests := []struct {
+		name string
+		user string
+		want int64
+	}{
+		{"assigned role", "bob", 1},
+		{"no role", "carol", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted, err := repo.Delete(ctx, tt.user)
+			if err != nil {
+				t.Fatalf("RoleRepository.Delete() error = %v", err)
+			}
+			if deleted != tt.want {
+				t.Errorf("RoleRepository.Delete() = %d, want %d", deleted, tt.want)
+			}
+		})
+	}
+}
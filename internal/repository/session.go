@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SessionRepository handles database operations for login sessions
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create stores a session for user under the hash of its token
+func (r *SessionRepository) Create(ctx context.Context, tokenHash, user string, expiresAt time.Time) error {
+
+	query := `
+		INSERT INTO sessions (token_hash, "user", created_at, expires_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, tokenHash, user, time.Now().UTC().Truncate(time.Second), expiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// GetUser retrieves the user of the session whose token has the given hash, or "" if
+// there is no such session or it expired before now
+func (r *SessionRepository) GetUser(ctx context.Context, tokenHash string, now time.Time) (string, error) {
+
+	query := `SELECT "user" FROM sessions WHERE token_hash = ? AND expires_at > ?`
+
+	var user string
+	if err := r.db.QueryRowContext(ctx, query, tokenHash, now.UTC()).Scan(&user); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return user, nil
+}
+
+// Delete removes the session whose token has the given hash
+func (r *SessionRepository) Delete(ctx context.Context, tokenHash string) error {
+
+	query := `DELETE FROM sessions WHERE token_hash = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes sessions that expired before now, returning how many were removed
+func (r *SessionRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+
+	query := `DELETE FROM sessions WHERE expires_at <= ?`
+
+	result, err := r.db.ExecContext(ctx, query, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
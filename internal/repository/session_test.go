@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := repo.Create(ctx, "hash-alice", "alice", now.Add(time.Hour)); err != nil {
+		t.Fatalf("SessionRepository.Create() error = %v", err)
+	}
+	if err := repo.Create(ctx, "hash-old", "bob", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("SessionRepository.Create() error = %v", err)
+	}
+
+	// Tokens are unique
+	if err := repo.Create(ctx, "hash-alice", "mallory", now.Add(time.Hour)); err == nil {
+		t.Error("SessionRepository.Create() with a duplicate hash should fail")
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want string
+	}{
+		{"live session", "hash-alice", "alice"},
+		{"expired session", "hash-old", ""},
+		{"unknown token", "hash-unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.GetUser(ctx, tt.hash, now)
+			if err != nil {
+				t.Fatalf("SessionRepository.GetUser() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SessionRepository.GetUser() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	deleted, err := repo.DeleteExpired(ctx, now)
+	if err != nil {
+		t.Fatalf("SessionRepository.DeleteExpired() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("SessionRepository.DeleteExpired() = %d, want 1", deleted)
+	}
+
+	if err := repo.Delete(ctx, "hash-alice"); err != nil {
+		t.Fatalf("SessionRepository.Delete() error = %v", err)
+	}
+	if got, _ := repo.GetUser(ctx, "hash-alice", now); got != "" {
+		t.Errorf("SessionRepository.GetUser() after Delete() = %q, want none", got)
+	}
+}
@@ -3,7 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"golinks/internal/domain"
 )
@@ -11,33 +16,117 @@ import (
 // ShortcutRepository handles database operations for shortcuts
 type ShortcutRepository struct {
 	db *sql.DB
+
+	// uniqueWords keeps one linktable row per word, updated in place, and every version
+	// of it in link_versions, rather than a linktable row per version
+	uniqueWords bool
+}
+
+// ShortcutOption configures optional ShortcutRepository behaviour
+type ShortcutOption func(*ShortcutRepository)
+
+// WithUniqueWords stores each word in a single row that edits update in place, moving
+// its history to a separate versions table so frequently edited links don't grow the
+// links table. Call ApplyWordMode before use when switching an existing database.
+func WithUniqueWords(enabled bool) ShortcutOption {
+	return func(r *ShortcutRepository) {
+		r.uniqueWords = enabled
+	}
 }
 
 // NewShortcutRepository creates a new shortcut repository
-func NewShortcutRepository(db *sql.DB) *ShortcutRepository {
-	return &ShortcutRepository{db: db}
+func NewShortcutRepository(db *sql.DB, opts ...ShortcutOption) *ShortcutRepository {
+	r := &ShortcutRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// GetByWord retrieves the most recent shortcut by word
-func (r *ShortcutRepository) GetByWord(ctx context.Context, word string) (*domain.Shortcut, error) {
+// shortcutColumns lists the linktable columns read by scanShortcut, in order
+const shortcutColumns = `id, word, link, "user", icon, description, updated_by, private, prefix, archived, metadata, created_at`
 
-	query := `
-		SELECT id, word, link, user, created_at 
-		FROM linktable 
-		WHERE word = ? 
-		ORDER BY id DESC 
-		LIMIT 1
-	`
+// versionColumns lists the link_versions columns read by scanShortcut, in order
+const versionColumns = `v.id, v.word, v.link, v."user", v.icon, v.description, v.updated_by, v.private, v.prefix, v.archived, v.metadata, v.created_at`
 
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanShortcut scans a row selected with shortcutColumns
+func scanShortcut(row rowScanner) (*domain.Shortcut, error) {
 	var shortcut domain.Shortcut
-	err := r.db.QueryRowContext(ctx, query, word).Scan(
+	err := row.Scan(
 		&shortcut.ID,
 		&shortcut.Word,
 		&shortcut.Link,
 		&shortcut.User,
+		&shortcut.Icon,
+		&shortcut.Description,
+		&shortcut.UpdatedBy,
+		&shortcut.Private,
+		&shortcut.Prefix,
+		&shortcut.Archived,
+		(*metadataColumn)(&shortcut.Metadata),
 		&shortcut.CreatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &shortcut, nil
+}
+
+// metadataColumn stores a link's metadata as a JSON object, or as "" when it has none
+type metadataColumn map[string]interface{}
+
+// Value implements driver.Valuer
+func (m metadataColumn) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(map[string]interface{}(m))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// Scan implements sql.Scanner
+func (m *metadataColumn) Scan(src interface{}) error {
+	var encoded []byte
+	switch v := src.(type) {
+	case nil:
+	case string:
+		encoded = []byte(v)
+	case []byte:
+		encoded = v
+	default:
+		return fmt.Errorf("unexpected metadata type %T", src)
+	}
+
+	*m = nil
+	if len(encoded) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(encoded, m); err != nil {
+		return fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return nil
+}
+
+// GetByWord retrieves the most recent shortcut by word, ignoring deleted ones
+func (r *ShortcutRepository) GetByWord(ctx context.Context, word string) (*domain.Shortcut, error) {
 
+	query := `
+		SELECT ` + shortcutColumns + `
+		FROM linktable 
+		WHERE word = ? AND deleted_at IS NULL
+		ORDER BY id DESC 
+		LIMIT 1
+	`
+
+	shortcut, err := scanShortcut(r.db.QueryRowContext(ctx, query, word))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -45,55 +134,496 @@ func (r *ShortcutRepository) GetByWord(ctx context.Context, word string) (*domai
 		return nil, fmt.Errorf("failed to get shortcut by word: %w", err)
 	}
 
-	return &shortcut, nil
+	return shortcut, nil
 }
 
-// Create creates a new shortcut
-func (r *ShortcutRepository) Create(ctx context.Context, shortcut *domain.Shortcut) error {
+// GetByID retrieves a single shortcut version by its ID, unless it has been deleted
+func (r *ShortcutRepository) GetByID(ctx context.Context, id int) (*domain.Shortcut, error) {
+
+	query := `SELECT ` + shortcutColumns + ` FROM linktable WHERE id = ? AND deleted_at IS NULL`
+	if r.uniqueWords {
+		query = `
+			SELECT ` + versionColumns + `
+			FROM link_versions v JOIN linktable l ON v.word_id = l.id
+			WHERE v.id = ? AND l.deleted_at IS NULL
+		`
+	}
+
+	shortcut, err := scanShortcut(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut by id: %w", err)
+	}
+
+	return shortcut, nil
+}
+
+// GetHistory retrieves every version of a word that hasn't been deleted, newest first
+func (r *ShortcutRepository) GetHistory(ctx context.Context, word string) ([]domain.Shortcut, error) {
 
 	query := `
-		INSERT INTO linktable (word, link, user, created_at) 
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		SELECT ` + shortcutColumns + `
+		FROM linktable
+		WHERE word = ? AND deleted_at IS NULL
+		ORDER BY id DESC
 	`
+	if r.uniqueWords {
+		query = `
+			SELECT ` + versionColumns + `
+			FROM link_versions v JOIN linktable l ON v.word_id = l.id
+			WHERE l.word = ? AND l.deleted_at IS NULL
+			ORDER BY v.id DESC
+		`
+	}
 
-	result, err := r.db.ExecContext(ctx, query, shortcut.Word, shortcut.Link, shortcut.User)
+	rows, err := r.db.QueryContext(ctx, query, word)
 	if err != nil {
-		return fmt.Errorf("failed to create shortcut: %w", err)
+		return nil, fmt.Errorf("failed to get shortcut history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []domain.Shortcut
+	for rows.Next() {
+		shortcut, err := scanShortcut(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shortcut: %w", err)
+		}
+		history = append(history, *shortcut)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shortcut history: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetAllVersions retrieves every version of every word that hasn't been deleted, private
+// ones included, by word and then oldest first
+func (r *ShortcutRepository) GetAllVersions(ctx context.Context) ([]domain.Shortcut, error) {
+
+	query := `
+		SELECT ` + shortcutColumns + `
+		FROM linktable
+		WHERE deleted_at IS NULL
+		ORDER BY word, id
+	`
+	if r.uniqueWords {
+		query = `
+			SELECT ` + versionColumns + `
+			FROM link_versions v JOIN linktable l ON v.word_id = l.id
+			WHERE l.deleted_at IS NULL
+			ORDER BY v.word, v.id
+		`
 	}
 
-	id, err := result.LastInsertId()
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %w", err)
+		return nil, fmt.Errorf("failed to get all shortcut versions: %w", err)
 	}
+	defer rows.Close()
 
-	shortcut.ID = int(id)
-	return nil
+	var versions []domain.Shortcut
+	for rows.Next() {
+		shortcut, err := scanShortcut(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shortcut: %w", err)
+		}
+		versions = append(versions, *shortcut)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shortcut versions: %w", err)
+	}
+
+	return versions, nil
 }
 
-// GetAllKeywords retrieves all keywords with their latest links
-func (r *ShortcutRepository) GetAllKeywords(ctx context.Context) ([]domain.KeywordInfo, error) {
+// GetVersionsSince retrieves up to limit versions stored after the version with ID
+// since, oldest first, including those of words in the trash. With unique words the IDs
+// are those of link_versions rather than linktable.
+func (r *ShortcutRepository) GetVersionsSince(ctx context.Context, since, limit int) ([]domain.Shortcut, error) {
 
 	query := `
-		SELECT word, link, created_at, MAX(id) as max_id
-		FROM linktable 
-		GROUP BY word 
-		ORDER BY max_id DESC
+		SELECT ` + shortcutColumns + `
+		FROM linktable
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?
 	`
+	if r.uniqueWords {
+		query = `
+			SELECT ` + versionColumns + `
+			FROM link_versions v
+			WHERE v.id > ?
+			ORDER BY v.id
+			LIMIT ?
+		`
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []domain.Shortcut
+	for rows.Next() {
+		shortcut, err := scanShortcut(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shortcut: %w", err)
+		}
+		versions = append(versions, *shortcut)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shortcut versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetRecentVersions retrieves the limit versions stored most recently, newest first,
+// including those of words in the trash
+func (r *ShortcutRepository) GetRecentVersions(ctx context.Context, limit int) ([]domain.Shortcut, error) {
+
+	query := `
+		SELECT ` + shortcutColumns + `
+		FROM linktable
+		ORDER BY id DESC
+		LIMIT ?
+	`
+	if r.uniqueWords {
+		query = `
+			SELECT ` + versionColumns + `
+			FROM link_versions v
+			ORDER BY v.id DESC
+			LIMIT ?
+		`
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent shortcut versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []domain.Shortcut
+	for rows.Next() {
+		shortcut, err := scanShortcut(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shortcut: %w", err)
+		}
+		versions = append(versions, *shortcut)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shortcut versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetCreated retrieves the version that created each word that hasn't been deleted, up
+// to limit of them, newest first. Only versions stored before the version with ID before
+// are included, unless before is 0, and words are left out unless both the version
+// creating them and their latest version are public or owned by viewer.
+func (r *ShortcutRepository) GetCreated(ctx context.Context, viewer string, before, limit int) ([]domain.Shortcut, error) {
+
+	query := `
+		SELECT f.id, f.word, f.link, f."user", f.icon, f.description, f.updated_by, f.private, f.prefix, f.archived, f.metadata, f.created_at
+		FROM linktable f
+		JOIN linktable l ON l.id = (SELECT MAX(id) FROM linktable WHERE word = f.word AND deleted_at IS NULL)
+		WHERE f.id IN (SELECT MIN(id) FROM linktable WHERE deleted_at IS NULL GROUP BY word)
+			AND (f.private = FALSE OR f."user" = ?) AND (l.private = FALSE OR l."user" = ?)
+			AND (? = 0 OR f.id < ?)
+		ORDER BY f.id DESC
+		LIMIT ?
+	`
+	if r.uniqueWords {
+		query = `
+			SELECT ` + versionColumns + `
+			FROM link_versions v JOIN linktable l ON v.word_id = l.id
+			WHERE v.id IN (SELECT MIN(id) FROM link_versions GROUP BY word_id) AND l.deleted_at IS NULL
+				AND (v.private = FALSE OR v."user" = ?) AND (l.private = FALSE OR l."user" = ?)
+				AND (? = 0 OR v.id < ?)
+			ORDER BY v.id DESC
+			LIMIT ?
+		`
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, viewer, viewer, before, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get created shortcuts: %w", err)
+	}
+	defer rows.Close()
+
+	var created []domain.Shortcut
+	for rows.Next() {
+		shortcut, err := scanShortcut(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shortcut: %w", err)
+		}
+		created = append(created, *shortcut)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating created shortcuts: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetWords retrieves every word that hasn't been deleted, private ones included, sorted
+func (r *ShortcutRepository) GetWords(ctx context.Context) ([]string, error) {
+
+	query := `SELECT DISTINCT word FROM linktable WHERE deleted_at IS NULL ORDER BY word`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all keywords: %w", err)
+		return nil, fmt.Errorf("failed to get words: %w", err)
 	}
 	defer rows.Close()
 
+	var words []string
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			return nil, fmt.Errorf("failed to scan word: %w", err)
+		}
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating words: %w", err)
+	}
+
+	return words, nil
+}
+
+// insertVersion adds a row for a new version of a word, carrying the word's click count
+// over to it as only the latest version keeps it. It takes the word again after the
+// columns.
+const insertVersion = `
+	INSERT INTO linktable (word, link, "user", icon, description, updated_by, private, prefix, archived, metadata, created_at, click_count)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		COALESCE((SELECT click_count FROM linktable WHERE id = (SELECT MAX(id) FROM linktable WHERE word = ?)), 0))
+	RETURNING id
+`
+
+// Create creates a new shortcut at its CreatedAt, or now when that is unset. With unique
+// words it updates the word's row instead, if it has one, and records the new version.
+func (r *ShortcutRepository) Create(ctx context.Context, shortcut *domain.Shortcut) error {
+	if r.uniqueWords {
+		return r.CreateBatch(ctx, []*domain.Shortcut{shortcut})
+	}
+
+	var id int
+	err := r.db.QueryRowContext(ctx, insertVersion,
+		shortcut.Word, shortcut.Link, shortcut.User, shortcut.Icon, shortcut.Description, shortcut.UpdatedBy, shortcut.Private, shortcut.Prefix, shortcut.Archived, metadataColumn(shortcut.Metadata), versionTime(shortcut, time.Now()),
+		shortcut.Word,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create shortcut: %w", err)
+	}
+
+	shortcut.ID = id
+	return nil
+}
+
+// versionTime is when a version of a shortcut is stored as created: its CreatedAt, or now
+// when that is unset, truncated to the second like CURRENT_TIMESTAMP
+func versionTime(shortcut *domain.Shortcut, now time.Time) time.Time {
+	createdAt := shortcut.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+	return createdAt.UTC().Truncate(time.Second)
+}
+
+// CreateBatch creates several shortcuts in a single transaction, each at its CreatedAt,
+// or now when that is unset
+func (r *ShortcutRepository) CreateBatch(ctx context.Context, shortcuts []*domain.Shortcut) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var stmt *sql.Stmt
+	if !r.uniqueWords {
+		stmt, err = tx.PrepareContext(ctx, insertVersion)
+		if err != nil {
+			return fmt.Errorf("failed to prepare insert: %w", err)
+		}
+		defer stmt.Close()
+	}
+
+	now := time.Now()
+	ids := make([]int, len(shortcuts))
+	for i, shortcut := range shortcuts {
+		var err error
+		createdAt := versionTime(shortcut, now)
+		if r.uniqueWords {
+			ids[i], err = upsertShortcut(ctx, tx, shortcut, createdAt)
+		} else {
+			err = stmt.QueryRowContext(ctx,
+				shortcut.Word, shortcut.Link, shortcut.User, shortcut.Icon, shortcut.Description, shortcut.UpdatedBy, shortcut.Private, shortcut.Prefix, shortcut.Archived, metadataColumn(shortcut.Metadata), createdAt,
+				shortcut.Word,
+			).Scan(&ids[i])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create shortcut %q: %w", shortcut.Word, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Only hand out IDs once they are committed
+	for i, shortcut := range shortcuts {
+		shortcut.ID = ids[i]
+	}
+
+	return nil
+}
+
+// upsertShortcut writes shortcut to its word's row, creating the row if the word has
+// none, and records it in link_versions as created at createdAt. A word in the trash
+// starts afresh.
+func upsertShortcut(ctx context.Context, tx *sql.Tx, shortcut *domain.Shortcut, createdAt time.Time) (int, error) {
+	var id int
+	var trashed bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT id, deleted_at IS NOT NULL FROM linktable WHERE word = ? ORDER BY id DESC LIMIT 1`, shortcut.Word,
+	).Scan(&id, &trashed)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	if err == nil && trashed {
+		if _, err := purgeTrashed(ctx, tx, shortcut.Word); err != nil {
+			return 0, err
+		}
+	}
+
+	if err == nil && !trashed {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE linktable SET link = ?, "user" = ?, icon = ?, description = ?, updated_by = ?, private = ?, prefix = ?, archived = ?, metadata = ?, created_at = ?
+			WHERE id = ?
+		`, shortcut.Link, shortcut.User, shortcut.Icon, shortcut.Description, shortcut.UpdatedBy, shortcut.Private, shortcut.Prefix, shortcut.Archived, metadataColumn(shortcut.Metadata), createdAt, id)
+	} else {
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO linktable (word, link, "user", icon, description, updated_by, private, prefix, archived, metadata, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`, shortcut.Word, shortcut.Link, shortcut.User, shortcut.Icon, shortcut.Description, shortcut.UpdatedBy, shortcut.Private, shortcut.Prefix, shortcut.Archived, metadataColumn(shortcut.Metadata), createdAt).Scan(&id)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO link_versions (word_id, word, link, "user", icon, description, updated_by, private, prefix, archived, metadata, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, shortcut.Word, shortcut.Link, shortcut.User, shortcut.Icon, shortcut.Description, shortcut.UpdatedBy, shortcut.Private, shortcut.Prefix, shortcut.Archived, metadataColumn(shortcut.Metadata), createdAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// keywordColumns selects a keyword from linktable l along with the tags of all its
+// versions and its aliases, its clicks, when its latest version was stored and by whom,
+// and when its first version, fv or f, was. Callers follow it with latestKeywordFrom or
+// keywordFrom and their filters.
+const keywordColumns = `
+		SELECT l.word, l.link, l.icon, l.description, l.private, l.prefix, l.archived, l.metadata, fv.created_at, f.created_at,
+			l.created_at, l.updated_by, l.id, l.click_count,
+			(SELECT GROUP_CONCAT(DISTINCT t.tag)
+			 FROM tags t JOIN linktable tl ON t.word_id = tl.id
+			 WHERE tl.word = l.word AND tl.deleted_at IS NULL) as tags,
+			(SELECT GROUP_CONCAT(a.word)
+			 FROM aliases a JOIN linktable al ON a.shortcut_id = al.id
+			 WHERE al.word = l.word AND al.deleted_at IS NULL) as aliases
+	`
+
+// keywordFrom reads keywords from linktable l joined to the first version of their word:
+// with unique words that is its oldest link_versions row fv, otherwise its oldest
+// linktable row f. The first versions are joined rather than selected with MIN so SQLite
+// still reads their times as times.
+const keywordFrom = `
+		FROM linktable l
+		LEFT JOIN link_versions fv ON fv.id = (SELECT MIN(id) FROM link_versions WHERE word_id = l.id)
+		LEFT JOIN linktable f ON f.id = (SELECT MIN(id) FROM linktable WHERE word = l.word AND deleted_at IS NULL)
+	`
+
+// latestKeywordFrom restricts linktable to the latest version of each word that hasn't
+// been deleted, so filters, counts and LIMIT/OFFSET apply to keywords rather than to
+// their versions
+const latestKeywordFrom = keywordFrom + `
+		WHERE l.id IN (SELECT MAX(id) FROM linktable WHERE deleted_at IS NULL GROUP BY word)
+	`
+
+// visibleFilter builds a condition matching keywords whose latest version is public or
+// owned by viewer, along with its arguments
+func visibleFilter(viewer string) (string, []interface{}) {
+	return `(l.private = FALSE OR l."user" = ?)`, []interface{}{viewer}
+}
+
+// keywordOrder returns the ORDER BY clause listing keywords in sort order, newest first
+// by default. Most used counts every click on any version of the word, including those
+// already rolled up, which the latest version keeps so ranking by popularity neither
+// scans the query log nor adds up versions.
+func keywordOrder(sort string) string {
+	switch sort {
+	case domain.KeywordSortAlphabetical:
+		return `l.word ASC`
+	case domain.KeywordSortMostUsed:
+		return `l.click_count DESC, l.word ASC`
+	default:
+		return `l.id DESC`
+	}
+}
+
+// scanKeywords reads rows selected with keywordColumns
+func scanKeywords(rows *sql.Rows) ([]domain.KeywordInfo, error) {
 	var keywords []domain.KeywordInfo
 	for rows.Next() {
 		var keyword domain.KeywordInfo
-		var maxID int
-		err := rows.Scan(&keyword.Word, &keyword.Link, &keyword.CreatedAt, &maxID)
+		var id int
+		var firstVersion, firstRow sql.NullTime
+		var clicks sql.NullInt64
+		var tags, aliases sql.NullString
+		err := rows.Scan(
+			&keyword.Word, &keyword.Link, &keyword.Icon, &keyword.Description, &keyword.Private, &keyword.Prefix, &keyword.Archived,
+			(*metadataColumn)(&keyword.Metadata),
+			&firstVersion, &firstRow, &keyword.UpdatedAt, &keyword.UpdatedBy, &id, &clicks, &tags, &aliases,
+		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan keyword: %w", err)
 		}
+		switch {
+		case firstVersion.Valid:
+			keyword.CreatedAt = firstVersion.Time
+		case firstRow.Valid:
+			keyword.CreatedAt = firstRow.Time
+		default:
+			keyword.CreatedAt = keyword.UpdatedAt
+		}
+		keyword.ClickCount = int(clicks.Int64)
+		if tags.Valid && tags.String != "" {
+			keyword.Tags = strings.Split(tags.String, ",")
+			sort.Strings(keyword.Tags)
+		}
+		if aliases.Valid && aliases.String != "" {
+			keyword.AlsoKnownAs = strings.Split(aliases.String, ",")
+			sort.Strings(keyword.AlsoKnownAs)
+		}
 		keywords = append(keywords, keyword)
 	}
 
@@ -103,3 +633,405 @@ func (r *ShortcutRepository) GetAllKeywords(ctx context.Context) ([]domain.Keywo
 
 	return keywords, nil
 }
+
+// GetAllKeywords retrieves all keywords visible to viewer with their latest links, newest first
+func (r *ShortcutRepository) GetAllKeywords(ctx context.Context, viewer string) ([]domain.KeywordInfo, error) {
+
+	visible, args := visibleFilter(viewer)
+	query := keywordColumns + latestKeywordFrom + ` AND ` + visible + ` ORDER BY l.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all keywords: %w", err)
+	}
+	defer rows.Close()
+
+	return scanKeywords(rows)
+}
+
+// GetKeywordsByOwner retrieves the keywords whose latest version belongs to owner, private
+// ones included, newest first
+func (r *ShortcutRepository) GetKeywordsByOwner(ctx context.Context, owner string) ([]domain.KeywordInfo, error) {
+	query := keywordColumns + latestKeywordFrom + ` AND l."user" = ? ORDER BY l.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keywords by owner: %w", err)
+	}
+	defer rows.Close()
+
+	return scanKeywords(rows)
+}
+
+// GetOwners counts the keywords whose latest version belongs to each user, and how many
+// of them are private or archived, the users owning most first
+func (r *ShortcutRepository) GetOwners(ctx context.Context) ([]domain.UserSummary, error) {
+
+	query := `
+		SELECT l."user", COUNT(*),
+			SUM(CASE WHEN l.private THEN 1 ELSE 0 END),
+			SUM(CASE WHEN l.archived THEN 1 ELSE 0 END)
+		FROM linktable l
+		WHERE l.id IN (SELECT MAX(id) FROM linktable WHERE deleted_at IS NULL GROUP BY word)
+		GROUP BY l."user"
+		ORDER BY COUNT(*) DESC, l."user" ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owners: %w", err)
+	}
+	defer rows.Close()
+
+	var owners []domain.UserSummary
+	for rows.Next() {
+		var owner domain.UserSummary
+		if err := rows.Scan(&owner.User, &owner.Links, &owner.Private, &owner.Archived); err != nil {
+			return nil, fmt.Errorf("failed to scan owner: %w", err)
+		}
+		owners = append(owners, owner)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating owners: %w", err)
+	}
+
+	return owners, nil
+}
+
+// targetFilter builds a condition matching links that start with one of the given
+// lowercase prefixes, along with its arguments
+func targetFilter(prefixes []string) (string, []interface{}) {
+	if len(prefixes) == 0 {
+		return "1 = 1", nil
+	}
+
+	conditions := make([]string, len(prefixes))
+	args := make([]interface{}, 0, len(prefixes)*2)
+	for i, prefix := range prefixes {
+		conditions[i] = "lower(substr(l.link, 1, ?)) = ?"
+		args = append(args, len(prefix), prefix)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// searchFilter builds a condition matching keywords whose word, description, link,
+// owner or metadata contains search, ignoring ASCII case as SQLite's LIKE does, along
+// with its arguments. Metadata is matched as its JSON text, so searching for a team name
+// finds the links it owns.
+func searchFilter(search string) (string, []interface{}) {
+	if search == "" {
+		return "1 = 1", nil
+	}
+
+	pattern := searchPattern(search)
+	return `(lower(l.word) LIKE ? ESCAPE '\' OR lower(l.description) LIKE ? ESCAPE '\' OR lower(l.link) LIKE ? ESCAPE '\' OR lower(l."user") LIKE ? ESCAPE '\' OR lower(l.metadata) LIKE ? ESCAPE '\')`,
+		[]interface{}{pattern, pattern, pattern, pattern, pattern}
+}
+
+// searchRank builds the leading ORDER BY terms ranking keywords whose word contains
+// search first and then those whose description does, along with their arguments
+func searchRank(search string) (string, []interface{}) {
+	if search == "" {
+		return "", nil
+	}
+
+	pattern := searchPattern(search)
+	return `CASE WHEN lower(l.word) LIKE ? ESCAPE '\' THEN 0 WHEN lower(l.description) LIKE ? ESCAPE '\' THEN 1 ELSE 2 END, `,
+		[]interface{}{pattern, pattern}
+}
+
+// searchPattern returns the LIKE pattern matching text that contains search
+func searchPattern(search string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + escaper.Replace(strings.ToLower(search)) + "%"
+}
+
+// GetKeywordsPage retrieves one page of keywords visible to viewer, in sort order, whose
+// latest link starts with one of targetPrefixes and, if search is set, whose word,
+// description, link, owner or metadata contains it. Searches list the keywords whose word matches
+// first, then those whose description does. It also returns the total number of matching
+// keywords.
+func (r *ShortcutRepository) GetKeywordsPage(
+	ctx context.Context, targetPrefixes []string, search, sort, viewer string, limit, offset int,
+) ([]domain.KeywordInfo, int, error) {
+
+	targets, args := targetFilter(targetPrefixes)
+	matches, searchArgs := searchFilter(search)
+	visible, visibleArgs := visibleFilter(viewer)
+	filter := targets + ` AND ` + matches + ` AND ` + visible
+	args = append(append(args, searchArgs...), visibleArgs...)
+
+	var total int
+	countQuery := `SELECT COUNT(*)` + latestKeywordFrom + ` AND ` + filter
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count keywords: %w", err)
+	}
+
+	rank, rankArgs := searchRank(search)
+	query := keywordColumns + latestKeywordFrom + ` AND ` + filter + `
+		ORDER BY ` + rank + keywordOrder(sort) + `
+		LIMIT ? OFFSET ?
+	`
+
+	args = append(args, rankArgs...)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get keywords page: %w", err)
+	}
+	defer rows.Close()
+
+	keywords, err := scanKeywords(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return keywords, total, nil
+}
+
+// GetKeywordsByPrefix retrieves up to limit keywords visible to viewer whose word starts
+// with prefix: an exact match first, then the most used. The prefix is matched as a range
+// of words so the word index serves it.
+func (r *ShortcutRepository) GetKeywordsByPrefix(
+	ctx context.Context, prefix, viewer string, limit int,
+) ([]domain.KeywordInfo, error) {
+
+	visible, visibleArgs := visibleFilter(viewer)
+	query := keywordColumns + keywordFrom + `
+		WHERE l.id IN (
+			SELECT MAX(id) FROM linktable
+			WHERE word >= ? AND word < ? AND deleted_at IS NULL
+			GROUP BY word
+		) AND ` + visible + `
+		ORDER BY CASE WHEN l.word = ? THEN 0 ELSE 1 END, ` + keywordOrder(domain.KeywordSortMostUsed) + `
+		LIMIT ?
+	`
+
+	// Every word starting with prefix sorts before prefix followed by the highest code point
+	args := append([]interface{}{prefix, prefix + "\U0010FFFF"}, visibleArgs...)
+	rows, err := r.db.QueryContext(ctx, query, append(args, prefix, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keywords by prefix: %w", err)
+	}
+	defer rows.Close()
+
+	return scanKeywords(rows)
+}
+
+// GetKeywordsVersion returns a fingerprint of the links, tags and aliases tables that
+// changes whenever a keyword list could. Rows are only ever appended, deleted or moved to
+// and from the trash, links updated in place with unique words also append a version and
+// renamed links gain an alias, so the highest id and row count of each table, the number
+// of links in the trash and the highest version id are enough and cheap to read from
+// their indexes.
+func (r *ShortcutRepository) GetKeywordsVersion(ctx context.Context) (string, error) {
+	query := `
+		SELECT (SELECT COALESCE(MAX(id), 0) FROM linktable),
+			(SELECT COUNT(*) FROM linktable),
+			(SELECT COUNT(*) FROM linktable WHERE deleted_at IS NOT NULL),
+			(SELECT COALESCE(MAX(id), 0) FROM link_versions),
+			(SELECT COALESCE(MAX(id), 0) FROM tags),
+			(SELECT COUNT(*) FROM tags),
+			(SELECT COALESCE(MAX(id), 0) FROM aliases),
+			(SELECT COUNT(*) FROM aliases)
+	`
+
+	var maxLinkID, links, trashed, maxVersionID, maxTagID, tags, maxAliasID, aliases int
+	err := r.db.QueryRowContext(ctx, query).Scan(&maxLinkID, &links, &trashed, &maxVersionID, &maxTagID, &tags, &maxAliasID, &aliases)
+	if err != nil {
+		return "", fmt.Errorf("failed to get keywords version: %w", err)
+	}
+
+	return fmt.Sprintf("%d-%d-%d-%d-%d-%d-%d-%d", maxLinkID, links, trashed, maxVersionID, maxTagID, tags, maxAliasID, aliases), nil
+}
+
+// DeleteByWord moves every version of a word to the trash, where it stays out of
+// resolution and listings until it is restored or purged
+func (r *ShortcutRepository) DeleteByWord(ctx context.Context, word string) (int64, error) {
+
+	query := `UPDATE linktable SET deleted_at = ? WHERE word = ? AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), word)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete shortcut: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// GetDeleted retrieves the latest deleted version of each word in the trash, most
+// recently deleted first
+func (r *ShortcutRepository) GetDeleted(ctx context.Context) ([]domain.TrashedLink, error) {
+
+	query := `
+		SELECT word, link, "user", private, deleted_at
+		FROM linktable
+		WHERE id IN (SELECT MAX(id) FROM linktable WHERE deleted_at IS NOT NULL GROUP BY word)
+		ORDER BY deleted_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted shortcuts: %w", err)
+	}
+	defer rows.Close()
+
+	var trash []domain.TrashedLink
+	for rows.Next() {
+		var link domain.TrashedLink
+		if err := rows.Scan(&link.Word, &link.Link, &link.User, &link.Private, &link.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted shortcut: %w", err)
+		}
+		trash = append(trash, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted shortcuts: %w", err)
+	}
+
+	return trash, nil
+}
+
+// RestoreByWord takes every deleted version of a word back out of the trash
+func (r *ShortcutRepository) RestoreByWord(ctx context.Context, word string) (int64, error) {
+
+	query := `UPDATE linktable SET deleted_at = NULL WHERE word = ? AND deleted_at IS NOT NULL`
+
+	result, err := r.db.ExecContext(ctx, query, word)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore shortcut: %w", err)
+	}
+
+	restored, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return restored, nil
+}
+
+// PurgeByWord permanently removes the deleted versions of a word along with their query
+// logs, tags and recorded versions
+func (r *ShortcutRepository) PurgeByWord(ctx context.Context, word string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	purged, err := purgeTrashed(ctx, tx, word)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return purged, nil
+}
+
+// purgeTrashed deletes the trashed rows of a word and everything referring to them
+func purgeTrashed(ctx context.Context, tx *sql.Tx, word string) (int64, error) {
+	dependents := []string{
+		`DELETE FROM queries WHERE word_id IN (SELECT id FROM linktable WHERE word = ? AND deleted_at IS NOT NULL)`,
+		`DELETE FROM query_rollups WHERE word_id IN (SELECT id FROM linktable WHERE word = ? AND deleted_at IS NOT NULL)`,
+		`DELETE FROM tags WHERE word_id IN (SELECT id FROM linktable WHERE word = ? AND deleted_at IS NOT NULL)`,
+		`DELETE FROM link_versions WHERE word_id IN (SELECT id FROM linktable WHERE word = ? AND deleted_at IS NOT NULL)`,
+		`DELETE FROM aliases WHERE shortcut_id IN (SELECT id FROM linktable WHERE word = ? AND deleted_at IS NOT NULL)`,
+		`DELETE FROM favorites WHERE word = ? AND NOT EXISTS (SELECT 1 FROM linktable WHERE word = favorites.word AND deleted_at IS NULL)`,
+	}
+	for _, query := range dependents {
+		if _, err := tx.ExecContext(ctx, query, word); err != nil {
+			return 0, fmt.Errorf("failed to purge shortcut references: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM linktable WHERE word = ? AND deleted_at IS NOT NULL`, word)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge shortcut: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return purged, nil
+}
+
+// shadowedTrash selects the trashed rows of words that are in use again
+const shadowedTrash = `SELECT id FROM linktable
+	WHERE deleted_at IS NOT NULL AND word IN (SELECT word FROM linktable WHERE deleted_at IS NULL)`
+
+// foldWords folds each word's rows into its newest row, keeping them all as versions in
+// link_versions, and then enforces one row per word. Trashed rows of a word that is in
+// use again are purged, as a word can't be both.
+var foldWords = []string{
+	`DELETE FROM queries WHERE word_id IN (` + shadowedTrash + `)`,
+	`DELETE FROM query_rollups WHERE word_id IN (` + shadowedTrash + `)`,
+	`DELETE FROM tags WHERE word_id IN (` + shadowedTrash + `)`,
+	`DELETE FROM link_versions WHERE word_id IN (` + shadowedTrash + `)`,
+	`DELETE FROM aliases WHERE shortcut_id IN (` + shadowedTrash + `)`,
+	`DELETE FROM linktable WHERE id IN (` + shadowedTrash + `)`,
+	// Rows written without unique words have no version recorded yet
+	`INSERT INTO link_versions (word_id, word, link, "user", icon, description, updated_by, private, prefix, archived, metadata, created_at)
+		SELECT id, word, link, "user", icon, description, updated_by, private, prefix, archived, metadata, created_at FROM linktable l
+		WHERE NOT EXISTS (SELECT 1 FROM link_versions v WHERE v.word_id = l.id)
+		ORDER BY id`,
+	`UPDATE link_versions SET word_id = (SELECT MAX(id) FROM linktable l WHERE l.word = link_versions.word)
+		WHERE word_id NOT IN (SELECT MAX(id) FROM linktable GROUP BY word)`,
+	`UPDATE tags SET word_id = (
+			SELECT MAX(l.id) FROM linktable l WHERE l.word = (SELECT word FROM linktable WHERE id = tags.word_id)
+		)
+		WHERE word_id NOT IN (SELECT MAX(id) FROM linktable GROUP BY word)`,
+	`UPDATE queries SET word_id = (
+			SELECT MAX(l.id) FROM linktable l WHERE l.word = (SELECT word FROM linktable WHERE id = queries.word_id)
+		)
+		WHERE word_id NOT IN (SELECT MAX(id) FROM linktable GROUP BY word)`,
+	`UPDATE query_rollups SET word_id = (
+			SELECT MAX(l.id) FROM linktable l WHERE l.word = (SELECT word FROM linktable WHERE id = query_rollups.word_id)
+		)
+		WHERE word_id NOT IN (SELECT MAX(id) FROM linktable GROUP BY word)`,
+	`UPDATE aliases SET shortcut_id = (
+			SELECT MAX(l.id) FROM linktable l WHERE l.word = (SELECT word FROM linktable WHERE id = aliases.shortcut_id)
+		)
+		WHERE shortcut_id NOT IN (SELECT MAX(id) FROM linktable GROUP BY word)`,
+	recountClicks,
+	`DELETE FROM linktable WHERE id NOT IN (SELECT MAX(id) FROM linktable GROUP BY word)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_linktable_word_unique ON linktable(word)`,
+}
+
+// ApplyWordMode brings the links table in line with the repository's word mode. With
+// unique words it folds each word's rows into one, keeping the rest as its history;
+// otherwise it lets edits add rows again. History recorded with unique words is not
+// carried back, so a word's history then starts from its current version.
+func (r *ShortcutRepository) ApplyWordMode(ctx context.Context) error {
+	steps := []string{`DROP INDEX IF EXISTS idx_linktable_word_unique`}
+	if r.uniqueWords {
+		steps = foldWords
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step); err != nil {
+			return fmt.Errorf("failed to apply word mode: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
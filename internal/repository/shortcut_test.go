@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -25,14 +27,113 @@ func setupTestDB(t *testing.T) *sql.DB {
 			word TEXT NOT NULL,
 			link TEXT NOT NULL,
 			user TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			icon TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			updated_by TEXT NOT NULL DEFAULT '',
+			private INTEGER NOT NULL DEFAULT 0,
+			prefix INTEGER NOT NULL DEFAULT 0,
+			archived INTEGER NOT NULL DEFAULT 0,
+			metadata TEXT NOT NULL DEFAULT '',
+			click_count INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME
 		)`,
 		`CREATE TABLE queries (
 			query_id INTEGER PRIMARY KEY AUTOINCREMENT,
 			word_id INTEGER NOT NULL,
+			referrer TEXT NOT NULL DEFAULT '',
+			user TEXT NOT NULL DEFAULT '',
+			client TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (word_id) REFERENCES linktable(id)
+		)`,
+		`CREATE TABLE query_rollups (
+			word_id INTEGER NOT NULL,
+			day TEXT NOT NULL,
+			count INTEGER NOT NULL,
+			FOREIGN KEY (word_id) REFERENCES linktable(id)
+		)`,
+		`CREATE TABLE tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			word_id INTEGER NOT NULL,
+			tag TEXT NOT NULL,
+			FOREIGN KEY (word_id) REFERENCES linktable(id)
+		)`,
+		`CREATE TABLE link_versions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			word_id INTEGER NOT NULL,
+			word TEXT NOT NULL,
+			link TEXT NOT NULL,
+			user TEXT NOT NULL,
+			icon TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			updated_by TEXT NOT NULL DEFAULT '',
+			private INTEGER NOT NULL DEFAULT 0,
+			prefix INTEGER NOT NULL DEFAULT 0,
+			archived INTEGER NOT NULL DEFAULT 0,
+			metadata TEXT NOT NULL DEFAULT '',
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (word_id) REFERENCES linktable(id)
 		)`,
+		`CREATE TABLE api_keys (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			user TEXT NOT NULL,
+			prefix TEXT NOT NULL,
+			key_hash TEXT NOT NULL UNIQUE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			revoked_at DATETIME
+		)`,
+		`CREATE TABLE sessions (
+			token_hash TEXT PRIMARY KEY,
+			user TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			expires_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE user_roles (
+			user TEXT PRIMARY KEY,
+			role TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE namespaces (
+			name TEXT PRIMARY KEY,
+			created_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE namespace_members (
+			namespace TEXT NOT NULL,
+			user TEXT NOT NULL,
+			role TEXT NOT NULL,
+			added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (namespace, user),
+			FOREIGN KEY (namespace) REFERENCES namespaces(name) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE favorites (
+			user TEXT NOT NULL,
+			word TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user, word)
+		)`,
+		`CREATE TABLE aliases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			word TEXT NOT NULL UNIQUE,
+			shortcut_id INTEGER NOT NULL REFERENCES linktable(id),
+			created_by TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE sync_state (
+			source TEXT PRIMARY KEY,
+			last_version INTEGER NOT NULL,
+			synced_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE link_health (
+			word TEXT PRIMARY KEY,
+			link TEXT NOT NULL,
+			owner TEXT NOT NULL,
+			problem TEXT NOT NULL DEFAULT '',
+			checked_at DATETIME NOT NULL,
+			broken_since DATETIME
+		)`,
 		`CREATE INDEX idx_linktable_word ON linktable(word)`,
 	}
 
@@ -210,7 +311,7 @@ func TestShortcutRepository_GetAllKeywords(t *testing.T) {
 		}
 	}
 
-	keywords, err := repo.GetAllKeywords(context.Background())
+	keywords, err := repo.GetAllKeywords(context.Background(), "")
 	if err != nil {
 		t.Errorf("ShortcutRepository.GetAllKeywords() error = %v", err)
 		return
@@ -240,6 +341,271 @@ func TestShortcutRepository_GetAllKeywords(t *testing.T) {
 	}
 }
 
+func TestShortcutRepository_GetKeywordsByOwner(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+	ctx := context.Background()
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "payroll", Link: "https://payroll.example.com", User: "user1", Private: true},
+		{Word: "github", Link: "https://github.com", User: "user1"},
+		{Word: "github", Link: "https://github.com/org", User: "user2"}, // handed over
+		{Word: "old", Link: "https://old.example.com", User: "user1"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if _, err := repo.DeleteByWord(ctx, "old"); err != nil {
+		t.Fatalf("DeleteByWord() error = %v", err)
+	}
+
+	tests := []struct {
+		owner string
+		want  []string
+	}{
+		{"user1", []string{"payroll", "docs"}},
+		{"user2", []string{"github"}},
+		{"user3", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			keywords, err := repo.GetKeywordsByOwner(ctx, tt.owner)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetKeywordsByOwner() error = %v", err)
+			}
+			var words []string
+			for _, keyword := range keywords {
+				words = append(words, keyword.Word)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("ShortcutRepository.GetKeywordsByOwner(%q) = %v, want %v", tt.owner, words, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_GetKeywordsPage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+	ctx := context.Background()
+
+	testShortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "github", Link: "https://github.com", User: "user2"},
+		{Word: "chat", Link: "slack://channel?id=1", User: "user2"},
+		{Word: "gh", Link: "github", User: "user2"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"},
+		{Word: "wiki", Link: "HTTP://wiki.example.com", User: "user1", Description: "Team notes, including our Git workflow"},
+	}
+	for _, shortcut := range testShortcuts {
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if err := NewTagRepository(db).AddTag(ctx, testShortcuts[0].ID, "engineering"); err != nil {
+		t.Fatalf("Failed to tag shortcut: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		prefixes  []string
+		search    string
+		limit     int
+		offset    int
+		wantWords []string
+		wantTotal int
+	}{
+		{
+			name:      "first page newest first",
+			prefixes:  []string{"http://", "https://"},
+			limit:     2,
+			wantWords: []string{"wiki", "docs"},
+			wantTotal: 3,
+		},
+		{
+			name:      "second page",
+			prefixes:  []string{"http://", "https://"},
+			limit:     2,
+			offset:    2,
+			wantWords: []string{"github"},
+			wantTotal: 3,
+		},
+		{
+			name:      "allowed scheme",
+			prefixes:  []string{"http://", "https://", "slack:"},
+			limit:     10,
+			wantWords: []string{"wiki", "docs", "chat", "github"},
+			wantTotal: 4,
+		},
+		{
+			name:      "search ranks words above descriptions",
+			prefixes:  []string{"http://", "https://"},
+			search:    "git",
+			limit:     10,
+			wantWords: []string{"github", "wiki"},
+			wantTotal: 2,
+		},
+		{
+			name:      "search by description",
+			prefixes:  []string{"http://", "https://"},
+			search:    "team notes",
+			limit:     10,
+			wantWords: []string{"wiki"},
+			wantTotal: 1,
+		},
+		{
+			name:      "search by link uses latest version",
+			prefixes:  []string{"http://", "https://"},
+			search:    "example.com/v2",
+			limit:     10,
+			wantWords: []string{"docs"},
+			wantTotal: 1,
+		},
+		{
+			name:      "search by owner",
+			prefixes:  []string{"http://", "https://"},
+			search:    "user1",
+			limit:     10,
+			wantWords: []string{"wiki", "docs"},
+			wantTotal: 2,
+		},
+		{
+			name:      "search wildcards are literal",
+			prefixes:  []string{"http://", "https://"},
+			search:    "%",
+			limit:     10,
+			wantWords: nil,
+			wantTotal: 0,
+		},
+		{
+			name:      "no filter includes aliases",
+			limit:     10,
+			wantWords: []string{"wiki", "docs", "gh", "chat", "github"},
+			wantTotal: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keywords, total, err := repo.GetKeywordsPage(ctx, tt.prefixes, tt.search, "", "", tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetKeywordsPage() error = %v", err)
+			}
+
+			var words []string
+			for _, keyword := range keywords {
+				words = append(words, keyword.Word)
+				if keyword.Word == "docs" && (keyword.Link != "https://docs.example.com/v2" || len(keyword.Tags) != 1) {
+					t.Errorf("ShortcutRepository.GetKeywordsPage() docs = %+v, want latest link with tags", keyword)
+				}
+			}
+			if !reflect.DeepEqual(words, tt.wantWords) {
+				t.Errorf("ShortcutRepository.GetKeywordsPage() words = %v, want %v", words, tt.wantWords)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("ShortcutRepository.GetKeywordsPage() total = %d, want %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_GetKeywordsPage_Sort(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+	queryRepo := NewQueryRepository(db)
+	ctx := context.Background()
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "github", Link: "https://github.com", User: "user2"},
+		{Word: "wiki", Link: "https://wiki.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "wiki", Link: "https://wiki.example.com/v2", User: "user1"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	// github is clicked three times, once on a day since rolled up, and wiki once through
+	// each version
+	clicks := []*domain.Query{
+		{WordID: shortcuts[0].ID, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{WordID: shortcuts[0].ID},
+		{WordID: shortcuts[0].ID},
+		{WordID: shortcuts[1].ID},
+		{WordID: shortcuts[3].ID},
+	}
+	if err := queryRepo.CreateBatch(ctx, clicks); err != nil {
+		t.Fatalf("Failed to log queries: %v", err)
+	}
+	if _, err := queryRepo.PruneBefore(ctx, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Failed to prune queries: %v", err)
+	}
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"wiki", "docs", "github"}},
+		{domain.KeywordSortNewest, []string{"wiki", "docs", "github"}},
+		{domain.KeywordSortAlphabetical, []string{"docs", "github", "wiki"}},
+		{domain.KeywordSortMostUsed, []string{"github", "wiki", "docs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			keywords, _, err := repo.GetKeywordsPage(ctx, nil, "", tt.sort, "", 10, 0)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetKeywordsPage() error = %v", err)
+			}
+			var words []string
+			for _, keyword := range keywords {
+				words = append(words, keyword.Word)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("ShortcutRepository.GetKeywordsPage() sorted by %q = %v, want %v", tt.sort, words, tt.want)
+			}
+		})
+	}
+
+	// Listings carry each keyword's clicks, rolled up ones included
+	keywords, _, err := repo.GetKeywordsPage(ctx, nil, "", domain.KeywordSortMostUsed, "", 10, 0)
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetKeywordsPage() error = %v", err)
+	}
+	var counts []int
+	for _, keyword := range keywords {
+		counts = append(counts, keyword.ClickCount)
+	}
+	if !reflect.DeepEqual(counts, []int{3, 2, 0}) {
+		t.Errorf("ShortcutRepository.GetKeywordsPage() click counts = %v, want [3 2 0]", counts)
+	}
+
+	// The latest version keeps the word's count and hands it on to the next one
+	wiki := &domain.Shortcut{Word: "wiki", Link: "https://wiki.example.com/v3", User: "user1"}
+	if err := repo.Create(ctx, wiki); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+	if err := queryRepo.Create(ctx, &domain.Query{WordID: shortcuts[1].ID}); err != nil {
+		t.Fatalf("Failed to log query: %v", err)
+	}
+	var clickCount int
+	if err := db.QueryRow(`SELECT click_count FROM linktable WHERE id = ?`, wiki.ID).Scan(&clickCount); err != nil || clickCount != 3 {
+		t.Errorf("wiki click count = %d, %v, want 3 on its latest version", clickCount, err)
+	}
+}
+
 func TestShortcutRepository_GetByWord_MostRecent(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -309,8 +675,1146 @@ func TestShortcutRepository_DatabaseError(t *testing.T) {
 	}
 
 	// Test GetAllKeywords with closed DB
-	_, err = repo.GetAllKeywords(context.Background())
+	_, err = repo.GetAllKeywords(context.Background(), "")
 	if err == nil {
 		t.Error("Expected error with closed database, got nil")
 	}
 }
+
+func TestShortcutRepository_Icon(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+
+	shortcut := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "user1", Icon: "📚"}
+	if err := repo.Create(context.Background(), shortcut); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+
+	got, err := repo.GetByWord(context.Background(), "docs")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetByWord() error = %v", err)
+	}
+	if got == nil || got.Icon != "📚" {
+		t.Errorf("ShortcutRepository.GetByWord() icon = %+v, want 📚", got)
+	}
+
+	keywords, err := repo.GetAllKeywords(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetAllKeywords() error = %v", err)
+	}
+	if len(keywords) != 1 || keywords[0].Icon != "📚" {
+		t.Errorf("ShortcutRepository.GetAllKeywords() = %+v, want icon 📚", keywords)
+	}
+}
+
+func TestShortcutRepository_Private(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+	ctx := context.Background()
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "payroll", Link: "https://payroll.example.com", User: "user1", Private: true},
+		// Making a word private hides its earlier public versions too
+		{Word: "wiki", Link: "https://wiki.example.com", User: "user2"},
+		{Word: "wiki", Link: "https://wiki.example.com/mine", User: "user2", Private: true},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	got, err := repo.GetByWord(ctx, "payroll")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetByWord() error = %v", err)
+	}
+	if got == nil || !got.Private {
+		t.Errorf("ShortcutRepository.GetByWord() = %+v, want a private shortcut", got)
+	}
+
+	tests := []struct {
+		name   string
+		viewer string
+		want   []string
+	}{
+		{"anonymous", "", []string{"docs"}},
+		{"owner of one", "user1", []string{"payroll", "docs"}},
+		{"owner of the other", "user2", []string{"wiki", "docs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keywords, err := repo.GetAllKeywords(ctx, tt.viewer)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetAllKeywords() error = %v", err)
+			}
+			var words []string
+			for _, keyword := range keywords {
+				words = append(words, keyword.Word)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("ShortcutRepository.GetAllKeywords() = %v, want %v", words, tt.want)
+			}
+
+			page, total, err := repo.GetKeywordsPage(ctx, nil, "", "", tt.viewer, 10, 0)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetKeywordsPage() error = %v", err)
+			}
+			if total != len(tt.want) || len(page) != len(tt.want) {
+				t.Errorf("ShortcutRepository.GetKeywordsPage() returned %d of %d, want %d", len(page), total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_Prefix(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			ctx := context.Background()
+
+			for _, shortcut := range []*domain.Shortcut{
+				{Word: "repo", Link: "https://github.com/org/repo", User: "user1"},
+				{Word: "repo", Link: "https://github.com/org/repo", User: "user1", Prefix: true},
+			} {
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+
+			got, err := repo.GetByWord(ctx, "repo")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetByWord() error = %v", err)
+			}
+			if got == nil || !got.Prefix {
+				t.Errorf("ShortcutRepository.GetByWord() = %+v, want a prefix link", got)
+			}
+
+			history, err := repo.GetHistory(ctx, "repo")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetHistory() error = %v", err)
+			}
+			if len(history) != 2 || history[0].Prefix == history[1].Prefix {
+				t.Errorf("ShortcutRepository.GetHistory() = %+v, want one prefix version and one not", history)
+			}
+
+			keywords, err := repo.GetAllKeywords(ctx, "")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetAllKeywords() error = %v", err)
+			}
+			if len(keywords) != 1 || !keywords[0].Prefix {
+				t.Errorf("ShortcutRepository.GetAllKeywords() = %+v, want repo as a prefix link", keywords)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_Archived(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			ctx := context.Background()
+
+			for _, shortcut := range []*domain.Shortcut{
+				{Word: "legacy", Link: "https://legacy.example.com", User: "user1"},
+				{Word: "legacy", Link: "https://legacy.example.com", User: "user1", UpdatedBy: "user2", Archived: true},
+			} {
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+
+			got, err := repo.GetByWord(ctx, "legacy")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetByWord() error = %v", err)
+			}
+			if got == nil || !got.Archived || got.UpdatedBy != "user2" {
+				t.Errorf("ShortcutRepository.GetByWord() = %+v, want legacy archived by user2", got)
+			}
+
+			history, err := repo.GetHistory(ctx, "legacy")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetHistory() error = %v", err)
+			}
+			if len(history) != 2 || !history[0].Archived || history[1].Archived {
+				t.Errorf("ShortcutRepository.GetHistory() = %+v, want the newest version archived", history)
+			}
+
+			keywords, err := repo.GetAllKeywords(ctx, "")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetAllKeywords() error = %v", err)
+			}
+			if len(keywords) != 1 || !keywords[0].Archived {
+				t.Errorf("ShortcutRepository.GetAllKeywords() = %+v, want legacy listed as archived", keywords)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_Metadata(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			ctx := context.Background()
+
+			metadata := map[string]interface{}{"team": "Payments", "tier": 1.0, "oncall": map[string]interface{}{"rotation": "pay-primary"}}
+			for _, shortcut := range []*domain.Shortcut{
+				{Word: "pay", Link: "https://pay.example.com", User: "user1"},
+				{Word: "pay", Link: "https://pay.example.com", User: "user1", Metadata: metadata},
+				{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+			} {
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+
+			got, err := repo.GetByWord(ctx, "pay")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetByWord() error = %v", err)
+			}
+			if got == nil || !reflect.DeepEqual(got.Metadata, metadata) {
+				t.Errorf("ShortcutRepository.GetByWord() = %+v, want metadata %v", got, metadata)
+			}
+
+			history, err := repo.GetHistory(ctx, "pay")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetHistory() error = %v", err)
+			}
+			if len(history) != 2 || history[0].Metadata == nil || history[1].Metadata != nil {
+				t.Errorf("ShortcutRepository.GetHistory() = %+v, want metadata on the newest version only", history)
+			}
+
+			// Searches match the metadata's JSON, ignoring case
+			keywords, total, err := repo.GetKeywordsPage(ctx, nil, "payments", "", "", 10, 0)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetKeywordsPage() error = %v", err)
+			}
+			if total != 1 || len(keywords) != 1 || !reflect.DeepEqual(keywords[0].Metadata, metadata) {
+				t.Errorf("ShortcutRepository.GetKeywordsPage() = %+v, %d, want pay with its metadata", keywords, total)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_Description(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			ctx := context.Background()
+
+			for _, shortcut := range []*domain.Shortcut{
+				{Word: "oncall", Link: "https://pager.example.com", User: "user1", Description: "Who is on call"},
+				{Word: "oncall", Link: "https://pager.example.com/schedule", User: "user1"},
+				{Word: "oncall", Link: "https://pager.example.com/schedule", User: "user1", Description: "This week's on-call schedule"},
+			} {
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+
+			got, err := repo.GetByWord(ctx, "oncall")
+			if err != nil || got == nil || got.Description != "This week's on-call schedule" {
+				t.Errorf("ShortcutRepository.GetByWord() = %+v, %v, want the latest description", got, err)
+			}
+
+			history, err := repo.GetHistory(ctx, "oncall")
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetHistory() error = %v", err)
+			}
+			var descriptions []string
+			for _, version := range history {
+				descriptions = append(descriptions, version.Description)
+			}
+			want := []string{"This week's on-call schedule", "", "Who is on call"}
+			if !reflect.DeepEqual(descriptions, want) {
+				t.Errorf("ShortcutRepository.GetHistory() descriptions = %q, want %q", descriptions, want)
+			}
+
+			keywords, err := repo.GetAllKeywords(ctx, "")
+			if err != nil || len(keywords) != 1 || keywords[0].Description != "This week's on-call schedule" {
+				t.Errorf("ShortcutRepository.GetAllKeywords() = %+v, %v, want oncall with its description", keywords, err)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_UpdatedBy(t *testing.T) {
+	created := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, time.March, 1, 17, 30, 0, 0, time.UTC)
+
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			ctx := context.Background()
+
+			for _, shortcut := range []*domain.Shortcut{
+				{Word: "oncall", Link: "https://pager.example.com", User: "user1", UpdatedBy: "user1", CreatedAt: created},
+				{Word: "oncall", Link: "https://pager.example.com/schedule", User: "user1", UpdatedBy: "admin", CreatedAt: updated},
+			} {
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+
+			history, err := repo.GetHistory(ctx, "oncall")
+			if err != nil || len(history) != 2 || history[0].UpdatedBy != "admin" || history[1].UpdatedBy != "user1" {
+				t.Errorf("ShortcutRepository.GetHistory() = %+v, %v, want versions by admin and user1", history, err)
+			}
+
+			keywords, err := repo.GetAllKeywords(ctx, "")
+			if err != nil || len(keywords) != 1 {
+				t.Fatalf("ShortcutRepository.GetAllKeywords() = %+v, %v, want oncall", keywords, err)
+			}
+			keyword := keywords[0]
+			if !keyword.CreatedAt.Equal(created) || !keyword.UpdatedAt.Equal(updated) || keyword.UpdatedBy != "admin" {
+				t.Errorf("ShortcutRepository.GetAllKeywords() = created %v, updated %v by %q, want created %v, updated %v by admin",
+					keyword.CreatedAt, keyword.UpdatedAt, keyword.UpdatedBy, created, updated)
+			}
+
+			page, _, err := repo.GetKeywordsPage(ctx, nil, "", domain.KeywordSortNewest, "", 10, 0)
+			if err != nil || len(page) != 1 || !page[0].CreatedAt.Equal(created) {
+				t.Errorf("ShortcutRepository.GetKeywordsPage() = %+v, %v, want oncall created %v", page, err, created)
+			}
+			byPrefix, err := repo.GetKeywordsByPrefix(ctx, "on", "", 10)
+			if err != nil || len(byPrefix) != 1 || !byPrefix[0].CreatedAt.Equal(created) {
+				t.Errorf("ShortcutRepository.GetKeywordsByPrefix() = %+v, %v, want oncall created %v", byPrefix, err, created)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_DeleteByWord(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+	queryRepo := NewQueryRepository(db)
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"},
+		{Word: "github", Link: "https://github.com", User: "user2"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(context.Background(), shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	// Query logs reference the rows being deleted
+	if err := queryRepo.Create(context.Background(), &domain.Query{WordID: shortcuts[0].ID}); err != nil {
+		t.Fatalf("Failed to create query log: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO tags (word_id, tag) VALUES (?, 'documentation')", shortcuts[1].ID); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	deleted, err := repo.DeleteByWord(context.Background(), "docs")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("ShortcutRepository.DeleteByWord() deleted %d rows, want 2", deleted)
+	}
+
+	got, err := repo.GetByWord(context.Background(), "docs")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetByWord() error = %v", err)
+	}
+	if got != nil {
+		t.Errorf("ShortcutRepository.GetByWord() = %+v, want nil after delete", got)
+	}
+
+	// Other words are untouched
+	if got, _ := repo.GetByWord(context.Background(), "github"); got == nil {
+		t.Error("github shortcut should still exist")
+	}
+
+	deleted, err = repo.DeleteByWord(context.Background(), "docs")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("ShortcutRepository.DeleteByWord() deleted %d rows on second call, want 0", deleted)
+	}
+}
+
+func TestShortcutRepository_Trash(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewShortcutRepository(db)
+	queryRepo := NewQueryRepository(db)
+	tagRepo := NewTagRepository(db)
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"},
+		{Word: "github", Link: "https://github.com", User: "user2"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if err := queryRepo.Create(ctx, &domain.Query{WordID: shortcuts[1].ID}); err != nil {
+		t.Fatalf("Failed to create query log: %v", err)
+	}
+	if err := tagRepo.AddTag(ctx, shortcuts[1].ID, "documentation"); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	if _, err := repo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+
+	// Trashed links are left out of everything but the trash
+	if got, _ := repo.GetByID(ctx, shortcuts[0].ID); got != nil {
+		t.Errorf("ShortcutRepository.GetByID() = %+v, want nil for a trashed version", got)
+	}
+	if history, _ := repo.GetHistory(ctx, "docs"); len(history) != 0 {
+		t.Errorf("ShortcutRepository.GetHistory() = %+v, want no trashed versions", history)
+	}
+	if keywords, _ := repo.GetAllKeywords(ctx, "user1"); len(keywords) != 1 || keywords[0].Word != "github" {
+		t.Errorf("ShortcutRepository.GetAllKeywords() = %+v, want only github", keywords)
+	}
+	if keywords, _ := tagRepo.GetKeywordsByTag(ctx, "documentation", "user1"); len(keywords) != 0 {
+		t.Errorf("TagRepository.GetKeywordsByTag() = %+v, want no trashed keywords", keywords)
+	}
+	if queries, _ := queryRepo.GetRecentQueries(ctx, 1, 10); len(queries) != 0 {
+		t.Errorf("QueryRepository.GetRecentQueries() = %+v, want no trashed keywords", queries)
+	}
+
+	trash, err := repo.GetDeleted(ctx)
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetDeleted() error = %v", err)
+	}
+	if len(trash) != 1 || trash[0].Word != "docs" || trash[0].Link != "https://docs.example.com/v2" || trash[0].DeletedAt.IsZero() {
+		t.Errorf("ShortcutRepository.GetDeleted() = %+v, want the latest docs version", trash)
+	}
+
+	// A word reused after deletion starts afresh
+	reused := &domain.Shortcut{Word: "docs", Link: "https://new.example.com", User: "user2"}
+	if err := repo.Create(ctx, reused); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+	if tags, _ := tagRepo.GetTagsByWord(ctx, "docs"); len(tags) != 0 {
+		t.Errorf("TagRepository.GetTagsByWord() = %v, want the trashed tags left behind", tags)
+	}
+	if _, err := repo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+
+	restored, err := repo.RestoreByWord(ctx, "docs")
+	if err != nil || restored != 3 {
+		t.Fatalf("ShortcutRepository.RestoreByWord() = %d, %v, want 3 versions", restored, err)
+	}
+	if got, _ := repo.GetByWord(ctx, "docs"); got == nil || got.ID != reused.ID {
+		t.Errorf("ShortcutRepository.GetByWord() = %+v after restore, want the newest version", got)
+	}
+	if tags, _ := tagRepo.GetTagsByWord(ctx, "docs"); len(tags) != 1 {
+		t.Errorf("TagRepository.GetTagsByWord() = %v after restore, want the tag back", tags)
+	}
+
+	if purged, err := repo.PurgeByWord(ctx, "docs"); err != nil || purged != 0 {
+		t.Errorf("ShortcutRepository.PurgeByWord() of a live word = %d, %v, want nothing purged", purged, err)
+	}
+	if _, err := repo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if purged, err := repo.PurgeByWord(ctx, "docs"); err != nil || purged != 3 {
+		t.Errorf("ShortcutRepository.PurgeByWord() = %d, %v, want 3 versions", purged, err)
+	}
+
+	var remaining int
+	err = db.QueryRow(`SELECT (SELECT COUNT(*) FROM linktable WHERE word = 'docs')
+		+ (SELECT COUNT(*) FROM tags) + (SELECT COUNT(*) FROM queries)`).Scan(&remaining)
+	if err != nil || remaining != 0 {
+		t.Errorf("purge left %d rows behind, %v", remaining, err)
+	}
+	if trash, _ := repo.GetDeleted(ctx); len(trash) != 0 {
+		t.Errorf("ShortcutRepository.GetDeleted() = %+v after purge, want an empty trash", trash)
+	}
+}
+
+func TestShortcutRepository_GetKeywordsVersion(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+	ctx := context.Background()
+
+	version := func() string {
+		t.Helper()
+		v, err := repo.GetKeywordsVersion(ctx)
+		if err != nil {
+			t.Fatalf("ShortcutRepository.GetKeywordsVersion() error = %v", err)
+		}
+		return v
+	}
+
+	empty := version()
+	if empty != "0-0-0-0-0-0-0-0" {
+		t.Errorf("ShortcutRepository.GetKeywordsVersion() = %q on an empty database, want 0-0-0-0-0-0-0-0", empty)
+	}
+
+	docs := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "user1"}
+	if err := repo.Create(ctx, docs); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+	created := version()
+	if created == empty {
+		t.Error("ShortcutRepository.GetKeywordsVersion() unchanged after create")
+	}
+	if again := version(); again != created {
+		t.Errorf("ShortcutRepository.GetKeywordsVersion() = %q then %q without changes", created, again)
+	}
+
+	if _, err := db.Exec("INSERT INTO tags (word_id, tag) VALUES (?, 'documentation')", docs.ID); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	tagged := version()
+	if tagged == created {
+		t.Error("ShortcutRepository.GetKeywordsVersion() unchanged after tagging")
+	}
+
+	if err := NewAliasRepository(db).Add(ctx, "documentation", "docs", "user1"); err != nil {
+		t.Fatalf("AliasRepository.Add() error = %v", err)
+	}
+	aliased := version()
+	if aliased == tagged {
+		t.Error("ShortcutRepository.GetKeywordsVersion() unchanged after adding an alias")
+	}
+
+	if _, err := repo.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	deleted := version()
+	if deleted == aliased {
+		t.Errorf("ShortcutRepository.GetKeywordsVersion() = %q after delete, want a new version", deleted)
+	}
+
+	if _, err := repo.RestoreByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.RestoreByWord() error = %v", err)
+	}
+	restored := version()
+	if restored == deleted {
+		t.Errorf("ShortcutRepository.GetKeywordsVersion() = %q after restore, want a new version", restored)
+	}
+
+	// Unique words edit links in place
+	unique := NewShortcutRepository(db, WithUniqueWords(true))
+	if err := unique.ApplyWordMode(ctx); err != nil {
+		t.Fatalf("ShortcutRepository.ApplyWordMode() error = %v", err)
+	}
+	folded := version()
+	if err := unique.Create(ctx, &domain.Shortcut{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"}); err != nil {
+		t.Fatalf("ShortcutRepository.Create() error = %v", err)
+	}
+	if edited := version(); edited == folded {
+		t.Errorf("ShortcutRepository.GetKeywordsVersion() = %q after an edit in place, want a new version", edited)
+	}
+}
+
+func TestShortcutRepository_GetHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "github", Link: "https://github.com", User: "user2"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user3"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(context.Background(), shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+
+	history, err := repo.GetHistory(context.Background(), "docs")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetHistory() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("ShortcutRepository.GetHistory() returned %d revisions, want 2", len(history))
+	}
+	if history[0].Link != "https://docs.example.com/v2" || history[1].Link != "https://docs.example.com" {
+		t.Errorf("ShortcutRepository.GetHistory() not ordered newest first: %+v", history)
+	}
+
+	got, err := repo.GetByID(context.Background(), history[1].ID)
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetByID() error = %v", err)
+	}
+	if got == nil || got.User != "user1" {
+		t.Errorf("ShortcutRepository.GetByID() = %+v, want first docs revision", got)
+	}
+
+	got, err = repo.GetByID(context.Background(), 999)
+	if err != nil || got != nil {
+		t.Errorf("ShortcutRepository.GetByID() for missing id = %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestShortcutRepository_GetAllVersions(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			ctx := context.Background()
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			shortcuts := []*domain.Shortcut{
+				{Word: "wiki", Link: "https://wiki.example.com", User: "user1"},
+				{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+				{Word: "secret", Link: "https://secret.example.com", User: "user2", Private: true},
+				{Word: "docs", Link: "https://docs.example.com/v2", User: "user2"},
+			}
+			for _, shortcut := range shortcuts {
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+			if _, err := repo.DeleteByWord(ctx, "wiki"); err != nil {
+				t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+			}
+
+			versions, err := repo.GetAllVersions(ctx)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetAllVersions() error = %v", err)
+			}
+			var got []string
+			for _, version := range versions {
+				got = append(got, version.Word+" "+version.Link+" "+version.User)
+			}
+			want := []string{
+				"docs https://docs.example.com user1",
+				"docs https://docs.example.com/v2 user2",
+				"secret https://secret.example.com user2",
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ShortcutRepository.GetAllVersions() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_GetVersionsSince(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			ctx := context.Background()
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			shortcuts := []*domain.Shortcut{
+				{Word: "wiki", Link: "https://wiki.example.com", User: "user1"},
+				{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+				{Word: "secret", Link: "https://secret.example.com", User: "user2", Private: true},
+				{Word: "docs", Link: "https://docs.example.com/v2", User: "user2"},
+			}
+			for _, shortcut := range shortcuts {
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+			if _, err := repo.DeleteByWord(ctx, "wiki"); err != nil {
+				t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+			}
+
+			all, err := repo.GetVersionsSince(ctx, 0, 10)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetVersionsSince() error = %v", err)
+			}
+			var got []string
+			for _, version := range all {
+				got = append(got, version.Word+" "+version.Link)
+			}
+			want := []string{
+				"wiki https://wiki.example.com",
+				"docs https://docs.example.com",
+				"secret https://secret.example.com",
+				"docs https://docs.example.com/v2",
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("ShortcutRepository.GetVersionsSince() = %v, want %v", got, want)
+			}
+
+			page, err := repo.GetVersionsSince(ctx, all[1].ID, 1)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetVersionsSince() error = %v", err)
+			}
+			if len(page) != 1 || page[0].ID != all[2].ID {
+				t.Errorf("ShortcutRepository.GetVersionsSince() = %v, want only %v", page, all[2])
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_GetRecentVersions(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			ctx := context.Background()
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			shortcuts := []*domain.Shortcut{
+				{Word: "wiki", Link: "https://wiki.example.com", User: "user1"},
+				{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+				{Word: "docs", Link: "https://docs.example.com/v2", User: "user2"},
+			}
+			for _, shortcut := range shortcuts {
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+			if _, err := repo.DeleteByWord(ctx, "wiki"); err != nil {
+				t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+			}
+
+			recent, err := repo.GetRecentVersions(ctx, 10)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetRecentVersions() error = %v", err)
+			}
+			var got []string
+			for _, version := range recent {
+				got = append(got, version.Word+" "+version.Link)
+			}
+			want := []string{
+				"docs https://docs.example.com/v2",
+				"docs https://docs.example.com",
+				"wiki https://wiki.example.com",
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ShortcutRepository.GetRecentVersions() = %v, want %v", got, want)
+			}
+
+			if recent, _ := repo.GetRecentVersions(ctx, 1); len(recent) != 1 || recent[0].Link != "https://docs.example.com/v2" {
+				t.Errorf("ShortcutRepository.GetRecentVersions(1) = %v, want the newest version", recent)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_GetOwners(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewShortcutRepository(db)
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user2"},
+		{Word: "wiki", Link: "https://wiki.example.com", User: "user2", Private: true},
+		{Word: "old", Link: "https://old.example.com", User: "user2", Archived: true},
+		{Word: "jira", Link: "https://jira.example.com", User: "user1"},
+		{Word: "gone", Link: "https://gone.example.com", User: "user3"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if _, err := repo.DeleteByWord(ctx, "gone"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+
+	owners, err := repo.GetOwners(ctx)
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetOwners() error = %v", err)
+	}
+	want := []domain.UserSummary{
+		{User: "user2", Links: 3, Private: 1, Archived: 1},
+		{User: "user1", Links: 1},
+	}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("ShortcutRepository.GetOwners() = %+v, want %+v", owners, want)
+	}
+}
+
+func TestShortcutRepository_GetCreated(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			ctx := context.Background()
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			create := func(shortcut *domain.Shortcut) {
+				t.Helper()
+				if err := repo.Create(ctx, shortcut); err != nil {
+					t.Fatalf("Failed to create test shortcut: %v", err)
+				}
+			}
+			create(&domain.Shortcut{Word: "wiki", Link: "https://wiki.example.com", User: "user1"})
+			create(&domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "user1"})
+			create(&domain.Shortcut{Word: "secret", Link: "https://secret.example.com", User: "user2", Private: true})
+			create(&domain.Shortcut{Word: "docs", Link: "https://docs.example.com/v2", User: "user2"})
+			if _, err := repo.DeleteByWord(ctx, "wiki"); err != nil {
+				t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+			}
+			create(&domain.Shortcut{Word: "wiki", Link: "https://new-wiki.example.com", User: "user2"})
+
+			tests := []struct {
+				name   string
+				viewer string
+				before string
+				limit  int
+				want   []string
+			}{
+				{
+					name:   "first version of each live word",
+					viewer: "user1",
+					limit:  10,
+					want:   []string{"wiki https://new-wiki.example.com", "docs https://docs.example.com"},
+				},
+				{
+					name:   "owner sees private words",
+					viewer: "user2",
+					limit:  10,
+					want:   []string{"wiki https://new-wiki.example.com", "secret https://secret.example.com", "docs https://docs.example.com"},
+				},
+				{name: "limit", viewer: "user2", limit: 1, want: []string{"wiki https://new-wiki.example.com"}},
+				{name: "before a version", viewer: "user2", before: "secret", limit: 10, want: []string{"docs https://docs.example.com"}},
+			}
+
+			ids := map[string]int{}
+			all, err := repo.GetCreated(ctx, "user2", 0, 10)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetCreated() error = %v", err)
+			}
+			for _, shortcut := range all {
+				ids[shortcut.Word] = shortcut.ID
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					created, err := repo.GetCreated(ctx, tt.viewer, ids[tt.before], tt.limit)
+					if err != nil {
+						t.Fatalf("ShortcutRepository.GetCreated() error = %v", err)
+					}
+					var got []string
+					for _, shortcut := range created {
+						got = append(got, shortcut.Word+" "+shortcut.Link)
+					}
+					if !reflect.DeepEqual(got, tt.want) {
+						t.Errorf("ShortcutRepository.GetCreated() = %v, want %v", got, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_GetWords(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewShortcutRepository(db)
+	shortcuts := []*domain.Shortcut{
+		{Word: "wiki", Link: "https://wiki.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "secret", Link: "https://secret.example.com", User: "user2", Private: true},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user2"},
+		{Word: "old", Link: "https://old.example.com", User: "user1"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if _, err := repo.DeleteByWord(ctx, "old"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+
+	got, err := repo.GetWords(ctx)
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetWords() error = %v", err)
+	}
+	want := []string{"docs", "secret", "wiki"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortcutRepository.GetWords() = %v, want %v", got, want)
+	}
+}
+
+func TestShortcutRepository_CreateKeepsCreatedAt(t *testing.T) {
+	for _, uniqueWords := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unique words %v", uniqueWords), func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			ctx := context.Background()
+			repo := NewShortcutRepository(db, WithUniqueWords(uniqueWords))
+			createdAt := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+			shortcut := &domain.Shortcut{Word: "wiki", Link: "https://wiki.example.com", User: "user1", CreatedAt: createdAt}
+			if err := repo.Create(ctx, shortcut); err != nil {
+				t.Fatalf("ShortcutRepository.Create() error = %v", err)
+			}
+
+			versions, err := repo.GetVersionsSince(ctx, 0, 10)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetVersionsSince() error = %v", err)
+			}
+			if len(versions) != 1 || !versions[0].CreatedAt.Equal(createdAt) {
+				t.Errorf("ShortcutRepository.GetVersionsSince() = %v, want one version created at %v", versions, createdAt)
+			}
+		})
+	}
+}
+
+func TestShortcutRepository_GetKeywordsByPrefix(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewShortcutRepository(db)
+	shortcuts := []*domain.Shortcut{
+		{Word: "wiki", Link: "https://wiki.example.com", User: "user1"},
+		{Word: "wikipedia", Link: "https://en.wikipedia.org", User: "user1"},
+		{Word: "wiki-hr", Link: "https://hr.example.com", User: "user2", Private: true},
+		{Word: "wiki-old", Link: "https://old.example.com", User: "user1"},
+		{Word: "wiki-eng", Link: "https://eng.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "wikipedia", Link: "https://de.wikipedia.org", User: "user1"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if _, err := repo.DeleteByWord(ctx, "wiki-old"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	// wiki-eng is followed more than the older wikipedia version, which still counts
+	queryRepo := NewQueryRepository(db)
+	for _, id := range []int{shortcuts[4].ID, shortcuts[4].ID, shortcuts[4].ID, shortcuts[1].ID, shortcuts[1].ID} {
+		if err := queryRepo.Create(ctx, &domain.Query{WordID: id}); err != nil {
+			t.Fatalf("Failed to log test query: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		viewer string
+		limit  int
+		want   []string
+	}{
+		{"exact match first, then most used", "wiki", "user1", 10, []string{"wiki", "wiki-eng", "wikipedia"}},
+		{"private for their owner", "wiki-", "user2", 10, []string{"wiki-eng", "wiki-hr"}},
+		{"limit", "wik", "user1", 2, []string{"wiki-eng", "wikipedia"}},
+		{"case sensitive", "Wiki", "user1", 10, nil},
+		{"like wildcards are literal", "wiki%", "user1", 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keywords, err := repo.GetKeywordsByPrefix(ctx, tt.prefix, tt.viewer, tt.limit)
+			if err != nil {
+				t.Fatalf("ShortcutRepository.GetKeywordsByPrefix() error = %v", err)
+			}
+			var got []string
+			for _, keyword := range keywords {
+				got = append(got, keyword.Word)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ShortcutRepository.GetKeywordsByPrefix(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+
+	keywords, err := repo.GetKeywordsByPrefix(ctx, "wikipedia", "user1", 1)
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetKeywordsByPrefix() error = %v", err)
+	}
+	if len(keywords) != 1 || keywords[0].Link != "https://de.wikipedia.org" {
+		t.Errorf("ShortcutRepository.GetKeywordsByPrefix() = %+v, want the latest wikipedia link", keywords)
+	}
+}
+
+func TestShortcutRepository_CreateBatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewShortcutRepository(db)
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "github", Link: "https://github.com", User: "user1", Icon: "💻"},
+	}
+	if err := repo.CreateBatch(context.Background(), shortcuts); err != nil {
+		t.Fatalf("ShortcutRepository.CreateBatch() error = %v", err)
+	}
+
+	for _, shortcut := range shortcuts {
+		if shortcut.ID == 0 {
+			t.Errorf("ShortcutRepository.CreateBatch() did not set ID for %s", shortcut.Word)
+		}
+		got, err := repo.GetByWord(context.Background(), shortcut.Word)
+		if err != nil || got == nil || got.Link != shortcut.Link || got.Icon != shortcut.Icon {
+			t.Errorf("ShortcutRepository.GetByWord(%s) = %+v, %v", shortcut.Word, got, err)
+		}
+	}
+
+	// A failing batch leaves nothing behind
+	trigger := `CREATE TRIGGER reject_bad BEFORE INSERT ON linktable WHEN NEW.word = 'bad'
+		BEGIN SELECT RAISE(ABORT, 'rejected'); END`
+	if _, err := db.Exec(trigger); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	failing := []*domain.Shortcut{
+		{Word: "good", Link: "https://good.example.com", User: "user1"},
+		{Word: "bad", Link: "https://bad.example.com", User: "user1"},
+	}
+	if err := repo.CreateBatch(context.Background(), failing); err == nil {
+		t.Fatal("ShortcutRepository.CreateBatch() expected error")
+	}
+	if got, _ := repo.GetByWord(context.Background(), "good"); got != nil {
+		t.Error("ShortcutRepository.CreateBatch() should roll back earlier inserts on failure")
+	}
+}
+
+func TestShortcutRepository_UniqueWords(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	rows := NewShortcutRepository(db)
+	unique := NewShortcutRepository(db, WithUniqueWords(true))
+	queryRepo := NewQueryRepository(db)
+	tagRepo := NewTagRepository(db)
+
+	create := func(repo *ShortcutRepository, word, link string) *domain.Shortcut {
+		t.Helper()
+		shortcut := &domain.Shortcut{Word: word, Link: link, User: "user1"}
+		if err := repo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("ShortcutRepository.Create(%s) error = %v", word, err)
+		}
+		return shortcut
+	}
+	countRows := func() int {
+		t.Helper()
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM linktable").Scan(&count); err != nil {
+			t.Fatalf("Failed to count links: %v", err)
+		}
+		return count
+	}
+	historyLinks := func(repo *ShortcutRepository, word string) []string {
+		t.Helper()
+		history, err := repo.GetHistory(ctx, word)
+		if err != nil {
+			t.Fatalf("ShortcutRepository.GetHistory(%s) error = %v", word, err)
+		}
+		var links []string
+		for _, shortcut := range history {
+			links = append(links, shortcut.Link)
+		}
+		return links
+	}
+
+	// A row per version, with a trashed docs shadowed by the live one and a word that
+	// is only in the trash
+	create(rows, "docs", "https://old.example.com")
+	if _, err := rows.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	first := create(rows, "docs", "https://docs.example.com/v1")
+	create(rows, "docs", "https://docs.example.com/v2")
+	create(rows, "github", "https://github.com")
+	create(rows, "gone", "https://gone.example.com")
+	if _, err := rows.DeleteByWord(ctx, "gone"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if err := tagRepo.AddTag(ctx, first.ID, "documentation"); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if err := queryRepo.Create(ctx, &domain.Query{WordID: first.ID}); err != nil {
+		t.Fatalf("Failed to create query log: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := unique.ApplyWordMode(ctx); err != nil {
+			t.Fatalf("ShortcutRepository.ApplyWordMode() error = %v", err)
+		}
+		if got := countRows(); got != 3 {
+			t.Errorf("ApplyWordMode() left %d rows, want one each for docs, github and gone", got)
+		}
+		if got, want := historyLinks(unique, "docs"), []string{"https://docs.example.com/v2", "https://docs.example.com/v1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("GetHistory(docs) = %v, want %v", got, want)
+		}
+	}
+	if tags, _ := tagRepo.GetTagsByWord(ctx, "docs"); !reflect.DeepEqual(tags, []string{"documentation"}) {
+		t.Errorf("TagRepository.GetTagsByWord(docs) = %v, want the tag kept", tags)
+	}
+	if queries, _ := queryRepo.GetRecentQueries(ctx, 1, 10); len(queries) != 1 || queries[0].Word != "docs" {
+		t.Errorf("QueryRepository.GetRecentQueries() = %+v, want the docs query kept", queries)
+	}
+	if keywords, _ := unique.GetKeywordsByPrefix(ctx, "docs", "", 1); len(keywords) != 1 || keywords[0].ClickCount != 1 {
+		t.Errorf("GetKeywordsByPrefix(docs) = %+v, want the click on an older version counted", keywords)
+	}
+
+	// Edits update the row in place and add a version
+	current, _ := unique.GetByWord(ctx, "docs")
+	edited := create(unique, "docs", "https://docs.example.com/v3")
+	if edited.ID != current.ID || countRows() != 3 {
+		t.Errorf("Create() of an existing word gave id %d and %d rows, want id %d and 3 rows", edited.ID, countRows(), current.ID)
+	}
+	if got, _ := unique.GetByWord(ctx, "docs"); got == nil || got.Link != "https://docs.example.com/v3" {
+		t.Errorf("GetByWord(docs) = %+v, want the edit", got)
+	}
+	history, _ := unique.GetHistory(ctx, "docs")
+	if len(history) != 3 {
+		t.Fatalf("GetHistory(docs) = %+v, want 3 versions", history)
+	}
+	if got, _ := unique.GetByID(ctx, history[1].ID); got == nil || got.Link != "https://docs.example.com/v2" {
+		t.Errorf("GetByID(%d) = %+v, want the v2 version", history[1].ID, got)
+	}
+
+	// Reusing a trashed word starts it afresh
+	create(unique, "gone", "https://back.example.com")
+	if got := historyLinks(unique, "gone"); !reflect.DeepEqual(got, []string{"https://back.example.com"}) {
+		t.Errorf("GetHistory(gone) = %v, want only the new version", got)
+	}
+	if trash, _ := unique.GetDeleted(ctx); len(trash) != 0 {
+		t.Errorf("GetDeleted() = %+v, want the reused word gone from the trash", trash)
+	}
+
+	if _, err := db.Exec(`INSERT INTO linktable (word, link, user) VALUES ('docs', 'https://dup.example.com', 'user1')`); err == nil {
+		t.Error("a second docs row was accepted with unique words")
+	}
+
+	// Without unique words, edits add rows again and history starts from the current version
+	if err := rows.ApplyWordMode(ctx); err != nil {
+		t.Fatalf("ShortcutRepository.ApplyWordMode() error = %v", err)
+	}
+	create(rows, "docs", "https://docs.example.com/v4")
+	if got, want := historyLinks(rows, "docs"), []string{"https://docs.example.com/v4", "https://docs.example.com/v3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetHistory(docs) = %v, want %v", got, want)
+	}
+}
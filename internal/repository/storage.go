@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golinks/internal/database"
+	"golinks/internal/domain"
+)
+
+// ShortcutStore stores golinks and every version of them
+type ShortcutStore interface {
+	GetByWord(ctx context.Context, word string) (*domain.Shortcut, error)
+	GetByID(ctx context.Context, id int) (*domain.Shortcut, error)
+	GetHistory(ctx context.Context, word string) ([]domain.Shortcut, error)
+	GetAllVersions(ctx context.Context) ([]domain.Shortcut, error)
+	GetVersionsSince(ctx context.Context, since, limit int) ([]domain.Shortcut, error)
+	GetCreated(ctx context.Context, viewer string, before, limit int) ([]domain.Shortcut, error)
+	GetWords(ctx context.Context) ([]string, error)
+	Create(ctx context.Context, shortcut *domain.Shortcut) error
+	CreateBatch(ctx context.Context, shortcuts []*domain.Shortcut) error
+	GetAllKeywords(ctx context.Context, viewer string) ([]domain.KeywordInfo, error)
+	GetKeywordsByOwner(ctx context.Context, owner string) ([]domain.KeywordInfo, error)
+	GetOwners(ctx context.Context) ([]domain.UserSummary, error)
+	GetRecentVersions(ctx context.Context, limit int) ([]domain.Shortcut, error)
+	GetKeywordsPage(
+		ctx context.Context, targetPrefixes []string, search, sort, viewer string, limit, offset int,
+	) ([]domain.KeywordInfo, int, error)
+	GetKeywordsVersion(ctx context.Context) (string, error)
+	GetKeywordsByPrefix(ctx context.Context, prefix, viewer string, limit int) ([]domain.KeywordInfo, error)
+	DeleteByWord(ctx context.Context, word string) (int64, error)
+	GetDeleted(ctx context.Context) ([]domain.TrashedLink, error)
+	RestoreByWord(ctx context.Context, word string) (int64, error)
+	PurgeByWord(ctx context.Context, word string) (int64, error)
+	ApplyWordMode(ctx context.Context) error
+}
+
+// QueryStore logs followed golinks and reports the popular ones
+type QueryStore interface {
+	Create(ctx context.Context, query *domain.Query) error
+	CreateBatch(ctx context.Context, queries []*domain.Query) error
+	GetRecentQueries(ctx context.Context, timeWindowDays, numResults int) ([]domain.PopularQuery, error)
+	GetUserTopLinks(ctx context.Context, user string, since time.Time, limit int) ([]domain.PopularQuery, error)
+	GetStaleLinks(ctx context.Context, since time.Time) ([]domain.StaleLink, error)
+	GetDailyClicks(ctx context.Context, word string, since time.Time) ([]domain.ClickCount, error)
+	GetTopReferrers(ctx context.Context, word string, since time.Time, limit int) ([]domain.ReferrerCount, error)
+	PruneBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TagStore stores the tags of golinks
+type TagStore interface {
+	AddTag(ctx context.Context, wordID int, tag string) error
+	RemoveTag(ctx context.Context, word, tag string) (int64, error)
+	GetTagsByWord(ctx context.Context, word string) ([]string, error)
+	GetAllTags(ctx context.Context) (map[string][]string, error)
+	GetKeywordsByTag(ctx context.Context, tag, viewer string) ([]domain.KeywordInfo, error)
+}
+
+// APIKeyStore stores API keys by the hash of their secret
+type APIKeyStore interface {
+	Create(ctx context.Context, key *domain.APIKey, keyHash string) error
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, id int) (int64, error)
+}
+
+// SessionStore stores sign-in sessions by the hash of their token
+type SessionStore interface {
+	Create(ctx context.Context, tokenHash, user string, expiresAt time.Time) error
+	GetUser(ctx context.Context, tokenHash string, now time.Time) (string, error)
+	Delete(ctx context.Context, tokenHash string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// RoleStore stores the roles assigned to users
+type RoleStore interface {
+	Get(ctx context.Context, user string) (domain.Role, error)
+	Set(ctx context.Context, userRole *domain.UserRole) error
+	Delete(ctx context.Context, user string) (int64, error)
+	List(ctx context.Context) ([]domain.UserRole, error)
+}
+
+// NamespaceStore stores team namespaces and their members
+type NamespaceStore interface {
+	Create(ctx context.Context, namespace *domain.Namespace) error
+	Get(ctx context.Context, name string) (*domain.Namespace, error)
+	List(ctx context.Context) ([]domain.Namespace, error)
+	Delete(ctx context.Context, name string) (int64, error)
+	MemberRole(ctx context.Context, name, user string) (domain.NamespaceRole, error)
+	SetMember(ctx context.Context, name string, member *domain.NamespaceMember) error
+	RemoveMember(ctx context.Context, name, user string) (int64, error)
+	CountLinks(ctx context.Context, name string) (int, error)
+}
+
+// FavoriteStore stores the links each user has pinned
+type FavoriteStore interface {
+	Add(ctx context.Context, user, word string) error
+	Remove(ctx context.Context, user, word string) (int64, error)
+	GetKeywords(ctx context.Context, user string) ([]domain.KeywordInfo, error)
+}
+
+// AliasStore stores the other words golinks answer to
+type AliasStore interface {
+	GetTarget(ctx context.Context, alias string) (string, error)
+	GetAliases(ctx context.Context, word string) ([]string, error)
+	GetAllAliases(ctx context.Context) (map[string]string, error)
+	Add(ctx context.Context, alias, word, user string) error
+	Remove(ctx context.Context, alias, word string) (int64, error)
+	Rename(ctx context.Context, word, newWord, user string) error
+}
+
+// SyncStateStore records how far a replica has synced from each primary
+type SyncStateStore interface {
+	GetCursor(ctx context.Context, source string) (int, error)
+	SetCursor(ctx context.Context, source string, cursor int) error
+}
+
+// LinkHealthStore records what the last check of each golink's target found
+type LinkHealthStore interface {
+	GetAll(ctx context.Context) ([]domain.LinkHealth, error)
+	Replace(ctx context.Context, health []domain.LinkHealth) error
+}
+
+// StorageStatsStore reports how much the backend holds
+type StorageStatsStore interface {
+	GetStorageStats(ctx context.Context) (*domain.StorageStats, error)
+}
+
+// SnapshotStore copies the whole database to and from snapshot files
+type SnapshotStore interface {
+	Snapshot(ctx context.Context, path string) error
+	Restore(ctx context.Context, path string) error
+}
+
+// Store is an open storage backend, holding a repository for each kind of data
+type Store struct {
+	Shortcuts  ShortcutStore
+	Queries    QueryStore
+	Tags       TagStore
+	APIKeys    APIKeyStore
+	Sessions   SessionStore
+	Roles      RoleStore
+	Namespaces NamespaceStore
+	Favorites  FavoriteStore
+	Aliases    AliasStore
+	SyncState  SyncStateStore
+
+	// LinkHealth records the dead link checker's findings. It is nil for backends that
+	// don't store them.
+	LinkHealth LinkHealthStore
+
+	// Stats reports how much the backend holds. It is nil for backends that can't tell.
+	Stats StorageStatsStore
+
+	// Closer releases the backend's resources, such as its database connections
+	Closer io.Closer
+
+	// Snapshots backs up and restores the backend. It is nil for backends that are
+	// backed up by other means, such as PostgreSQL's own tools.
+	Snapshots SnapshotStore
+
+	// Migrations versions the backend's schema. It is nil for backends that manage
+	// their own, and is left to the caller to run: Open does not migrate.
+	Migrations *database.Migrator
+
+	// Legacy copies links and queries from the legacy Python implementation's SQLite
+	// databases. It is nil for backends that aren't SQL databases.
+	Legacy *LegacyRepository
+}
+
+// NewSQLStore creates a store whose repositories share a SQL database
+func NewSQLStore(db *sql.DB, opts ...ShortcutOption) *Store {
+	return &Store{
+		Shortcuts:  NewShortcutRepository(db, opts...),
+		Queries:    NewQueryRepository(db),
+		Tags:       NewTagRepository(db),
+		APIKeys:    NewAPIKeyRepository(db),
+		Sessions:   NewSessionRepository(db),
+		Roles:      NewRoleRepository(db),
+		Namespaces: NewNamespaceRepository(db),
+		Favorites:  NewFavoriteRepository(db),
+		Aliases:    NewAliasRepository(db),
+		SyncState:  NewSyncStateRepository(db),
+		LinkHealth: NewLinkHealthRepository(db),
+		Closer:     db,
+	}
+}
+
+// Ping checks that the backend can be reached, for backends that can tell
+func (s *Store) Ping(ctx context.Context) error {
+	pinger, ok := s.Closer.(interface {
+		PingContext(ctx context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+	return pinger.PingContext(ctx)
+}
+
+// Close releases the store
+func (s *Store) Close() error {
+	if s.Closer == nil {
+		return nil
+	}
+	return s.Closer.Close()
+}
+
+// Options configures how a driver opens its backend
+type Options struct {
+	// DSN is the data source name, whose meaning is up to the driver
+	DSN string
+
+	// Pool sizes the backend's connection pool, for drivers that have one
+	Pool database.Pool
+
+	// UniqueWords keeps one row per word, updated in place, with its history in a
+	// separate versions table. See WithUniqueWords.
+	UniqueWords bool
+}
+
+// Driver opens a storage backend
+type Driver interface {
+	Open(opts Options) (*Store, error)
+}
+
+// DriverFunc adapts a function to a Driver
+type DriverFunc func(opts Options) (*Store, error)
+
+// Open calls f(opts)
+func (f DriverFunc) Open(opts Options) (*Store, error) {
+	return f(opts)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{}
+)
+
+// Register makes a storage driver available under name. It is meant to be called from
+// an init function, and panics if driver is nil or name is already taken.
+func Register(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if driver == nil {
+		panic("repository: Register driver is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("repository: Register called twice for driver " + name)
+	}
+	drivers[name] = driver
+}
+
+// Drivers returns the names of the registered storage drivers, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens a store with the named driver
+func Open(name string, opts Options) (*Store, error) {
+	driversMu.RLock()
+	driver, ok := drivers[name]
+	driversMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown storage driver %q (registered: %s)", name, strings.Join(Drivers(), ", "))
+	}
+
+	store, err := driver.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s storage: %w", name, err)
+	}
+	return store, nil
+}
+
+func init() {
+	Register("sqlite", DriverFunc(openSQLite))
+	Register("postgres", DriverFunc(openPostgres))
+	Register("memory", DriverFunc(openMemory))
+}
+
+// openSQLite opens the SQLite database file named by the DSN
+func openSQLite(opts Options) (*Store, error) {
+	db, err := database.NewSQLiteDB(opts.DSN, opts.Pool)
+	if err != nil {
+		return nil, err
+	}
+	return sqliteStore(db, opts), nil
+}
+
+// openPostgres opens a PostgreSQL database from a URL or key=value DSN
+func openPostgres(opts Options) (*Store, error) {
+	db, err := database.NewPostgresDB(opts.DSN, opts.Pool)
+	if err != nil {
+		return nil, err
+	}
+	store := migratable(db, database.PostgresMigrations, opts)
+	store.Legacy = NewLegacyRepository(db, true)
+	store.Stats = NewStorageStatsRepository(db, true)
+	return store, nil
+}
+
+// openMemory opens an empty SQLite database held in memory, which is lost when the
+// store is closed. The DSN is ignored.
+func openMemory(opts Options) (*Store, error) {
+	// Every connection to :memory: gets its own database, so keep to one
+	pool := opts.Pool
+	pool.MaxOpenConns = 1
+	db, err := database.NewSQLiteDB(":memory:", pool)
+	if err != nil {
+		return nil, err
+	}
+	return sqliteStore(db, opts), nil
+}
+
+// sqliteStore wraps a SQLite db in a store that can also be snapshotted
+func sqliteStore(db *sql.DB, opts Options) *Store {
+	store := migratable(db, database.SQLiteMigrations, opts)
+	snapshots := NewSQLiteSnapshots(db, store.Migrations)
+	snapshots.prepare = store.Shortcuts.ApplyWordMode
+	store.Snapshots = snapshots
+	store.Legacy = NewLegacyRepository(db, false)
+	store.Stats = NewStorageStatsRepository(db, false)
+	return store
+}
+
+// migratable wraps db in a store whose schema is versioned by migrations
+func migratable(db *sql.DB, migrations []database.Migration, opts Options) *Store {
+	store := NewSQLStore(db, WithUniqueWords(opts.UniqueWords))
+	store.Migrations = database.NewMigrator(db, migrations)
+	return store
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golinks/internal/database"
+	"golinks/internal/domain"
+)
+
+func TestRegister(t *testing.T) {
+	Register("test-storage", DriverFunc(func(opts Options) (*Store, error) {
+		if opts.DSN == "" {
+			return nil, errors.New("dsn required")
+		}
+		return &Store{}, nil
+	}))
+
+	found := false
+	for _, name := range Drivers() {
+		found = found || name == "test-storage"
+	}
+	if !found {
+		t.Errorf("Drivers() = %v, want test-storage listed", Drivers())
+	}
+
+	if _, err := Open("test-storage", Options{DSN: "anything"}); err != nil {
+		t.Errorf("Open() error = %v", err)
+	}
+	if _, err := Open("test-storage", Options{}); err == nil || !strings.Contains(err.Error(), "dsn required") {
+		t.Errorf("Open() error = %v, want the driver's error", err)
+	}
+	if _, err := Open("nosuchdriver", Options{}); err == nil || !strings.Contains(err.Error(), "sqlite") {
+		t.Errorf("Open() of an unknown driver error = %v, want the registered drivers listed", err)
+	}
+
+	panics := []struct {
+		name   string
+		driver Driver
+	}{
+		{"duplicate", DriverFunc(func(Options) (*Store, error) { return nil, nil })},
+		{"nil", nil},
+	}
+	for _, tt := range panics {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register() should panic")
+				}
+			}()
+			name := "test-storage"
+			if tt.driver == nil {
+				name = "test-nil"
+			}
+			Register(name, tt.driver)
+		})
+	}
+}
+
+func TestOpen_BuiltinDrivers(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+	}{
+		{"sqlite", filepath.Join(t.TempDir(), "golinks.db")},
+		{"memory", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := Open(tt.name, Options{DSN: tt.dsn, Pool: database.Pool{MaxOpenConns: 4}})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer store.Close()
+			ctx := context.Background()
+
+			if store.Migrations == nil {
+				t.Fatal("Open() returned a store without migrations")
+			}
+			if _, err := store.Migrations.Up(ctx); err != nil {
+				t.Fatalf("Migrations.Up() error = %v", err)
+			}
+
+			shortcut := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "alice"}
+			if err := store.Shortcuts.Create(ctx, shortcut); err != nil {
+				t.Fatalf("Shortcuts.Create() error = %v", err)
+			}
+			if err := store.Tags.AddTag(ctx, shortcut.ID, "engineering"); err != nil {
+				t.Fatalf("Tags.AddTag() error = %v", err)
+			}
+			if err := store.Queries.Create(ctx, &domain.Query{WordID: shortcut.ID}); err != nil {
+				t.Fatalf("Queries.Create() error = %v", err)
+			}
+
+			keywords, err := store.Shortcuts.GetAllKeywords(ctx, "alice")
+			if err != nil || len(keywords) != 1 || len(keywords[0].Tags) != 1 {
+				t.Errorf("Shortcuts.GetAllKeywords() = %+v, %v, want docs tagged engineering", keywords, err)
+			}
+			popular, err := store.Queries.GetRecentQueries(ctx, 1, 10)
+			if err != nil || len(popular) != 1 {
+				t.Errorf("Queries.GetRecentQueries() = %+v, %v, want docs", popular, err)
+			}
+		})
+	}
+}
+
+func TestOpen_MemoryKeepsOneConnection(t *testing.T) {
+	store, err := Open("memory", Options{Pool: database.Pool{MaxOpenConns: 10}})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	db, ok := store.Closer.(*sql.DB)
+	if !ok {
+		t.Fatalf("memory store Closer = %T, want *sql.DB", store.Closer)
+	}
+	if got := db.Stats().MaxOpenConnections; got != 1 {
+		t.Errorf("MaxOpenConnections = %d, want 1 whatever the pool asks for", got)
+	}
+}
+
+func TestStore_Ping(t *testing.T) {
+	store, err := Open("memory", Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+
+	store.Close()
+	if err := store.Ping(context.Background()); err == nil {
+		t.Error("Ping() of a closed store expected error")
+	}
+
+	if err := (&Store{}).Ping(context.Background()); err != nil {
+		t.Errorf("Ping() of a store that can't tell error = %v", err)
+	}
+}
+
+func TestOpen_UniqueWords(t *testing.T) {
+	store, err := Open("memory", Options{UniqueWords: true})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if _, err := store.Migrations.Up(ctx); err != nil {
+		t.Fatalf("Migrations.Up() error = %v", err)
+	}
+	if err := store.Shortcuts.ApplyWordMode(ctx); err != nil {
+		t.Fatalf("Shortcuts.ApplyWordMode() error = %v", err)
+	}
+
+	var ids []int
+	for _, link := range []string{"https://docs.example.com", "https://docs.example.com/v2"} {
+		shortcut := &domain.Shortcut{Word: "docs", Link: link, User: "alice"}
+		if err := store.Shortcuts.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Shortcuts.Create() error = %v", err)
+		}
+		ids = append(ids, shortcut.ID)
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("Shortcuts.Create() ids = %v, want the row updated in place", ids)
+	}
+	if history, err := store.Shortcuts.GetHistory(ctx, "docs"); err != nil || len(history) != 2 {
+		t.Errorf("Shortcuts.GetHistory() = %+v, %v, want both versions", history, err)
+	}
+}
+
+func TestOpen_MemoryIsPrivate(t *testing.T) {
+	first, err := Open("memory", Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer first.Close()
+	second, err := Open("memory", Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer second.Close()
+
+	ctx := context.Background()
+	for _, store := range []*Store{first, second} {
+		if _, err := store.Migrations.Up(ctx); err != nil {
+			t.Fatalf("Migrations.Up() error = %v", err)
+		}
+	}
+	if err := first.Shortcuts.Create(ctx, &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "alice"}); err != nil {
+		t.Fatalf("Shortcuts.Create() error = %v", err)
+	}
+	if shortcut, err := second.Shortcuts.GetByWord(ctx, "docs"); err != nil || shortcut != nil {
+		t.Errorf("second memory store GetByWord() = %+v, %v, want nothing", shortcut, err)
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"golinks/internal/domain"
+)
+
+// storageTables are the tables whose rows GetStorageStats counts, in the order reported
+var storageTables = []string{
+	"linktable", "link_versions", "tags", "aliases", "favorites", "queries", "query_rollups",
+	"link_health", "api_keys", "sessions", "user_roles", "namespaces", "namespace_members", "sync_state",
+}
+
+// Queries reporting the size of the database in bytes
+const (
+	sqliteSizeQuery   = `SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`
+	postgresSizeQuery = `SELECT pg_database_size(current_database())`
+)
+
+// StorageStatsRepository reports how much a SQL database holds
+type StorageStatsRepository struct {
+	db *sql.DB
+
+	// sizeQuery asks the database for its size, which each database reports its own way
+	sizeQuery string
+}
+
+// NewStorageStatsRepository creates a storage stats repository for db, which is a
+// PostgreSQL database if postgres is set and a SQLite one otherwise
+func NewStorageStatsRepository(db *sql.DB, postgres bool) *StorageStatsRepository {
+	sizeQuery := sqliteSizeQuery
+	if postgres {
+		sizeQuery = postgresSizeQuery
+	}
+	return &StorageStatsRepository{db: db, sizeQuery: sizeQuery}
+}
+
+// GetStorageStats counts the words in use and in the trash and the rows of each table,
+// and reports the size of the database
+func (r *StorageStatsRepository) GetStorageStats(ctx context.Context) (*domain.StorageStats, error) {
+	stats := &domain.StorageStats{Tables: make([]domain.TableStats, 0, len(storageTables))}
+
+	query := `
+		SELECT
+			(SELECT COUNT(DISTINCT word) FROM linktable WHERE deleted_at IS NULL),
+			(SELECT COUNT(DISTINCT word) FROM linktable WHERE deleted_at IS NOT NULL)
+	`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&stats.Links, &stats.Trashed); err != nil {
+		return nil, fmt.Errorf("failed to count links: %w", err)
+	}
+
+	for _, table := range storageTables {
+		var rows int
+		if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&rows); err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %w", table, err)
+		}
+		stats.Tables = append(stats.Tables, domain.TableStats{Name: table, Rows: rows})
+	}
+
+	if err := r.db.QueryRowContext(ctx, r.sizeQuery).Scan(&stats.Bytes); err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	return stats, nil
+}
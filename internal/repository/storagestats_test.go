@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestStorageStatsRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	shortcutRepo := NewShortcutRepository(db)
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"},
+		{Word: "wiki", Link: "https://wiki.example.com", User: "user2"},
+		{Word: "gone", Link: "https://gone.example.com", User: "user2"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if _, err := shortcutRepo.DeleteByWord(ctx, "gone"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+	if err := NewQueryRepository(db).Create(ctx, &domain.Query{WordID: shortcuts[1].ID}); err != nil {
+		t.Fatalf("QueryRepository.Create() error = %v", err)
+	}
+
+	stats, err := NewStorageStatsRepository(db, false).GetStorageStats(ctx)
+	if err != nil {
+		t.Fatalf("StorageStatsRepository.GetStorageStats() error = %v", err)
+	}
+	if stats.Links != 2 || stats.Trashed != 1 {
+		t.Errorf("GetStorageStats() links = %d and trashed = %d, want 2 and 1", stats.Links, stats.Trashed)
+	}
+	if stats.Bytes <= 0 {
+		t.Errorf("GetStorageStats() bytes = %d, want the database size", stats.Bytes)
+	}
+
+	rows := map[string]int{}
+	for _, table := range stats.Tables {
+		rows[table.Name] = table.Rows
+	}
+	if len(rows) != len(storageTables) {
+		t.Errorf("GetStorageStats() tables = %+v, want %d of them", stats.Tables, len(storageTables))
+	}
+	for table, want := range map[string]int{"linktable": 4, "queries": 1, "tags": 0} {
+		if rows[table] != want {
+			t.Errorf("GetStorageStats() rows of %s = %d, want %d", table, rows[table], want)
+		}
+	}
+}
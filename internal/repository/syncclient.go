@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// SyncClient reads the link changes of a primary golinks instance through its
+// /api/sync/changes endpoint, authenticating with an admin's API key
+type SyncClient struct {
+	client  *http.Client
+	baseURL *url.URL
+	apiKey  string
+}
+
+// NewSyncClient creates a client for the primary at baseURL, such as
+// https://go.example.com
+func NewSyncClient(baseURL, apiKey string) (*SyncClient, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("primary URL %q must be an http:// or https:// URL", baseURL)
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("syncing from %s needs an admin's API key", baseURL)
+	}
+
+	return &SyncClient{
+		client:  &http.Client{Timeout: time.Minute},
+		baseURL: u,
+		apiKey:  apiKey,
+	}, nil
+}
+
+// GetChanges retrieves up to limit link versions the primary stored after the version
+// with ID since
+func (c *SyncClient) GetChanges(ctx context.Context, since, limit int) (*domain.LinkChanges, error) {
+	u := *c.baseURL
+	u.Path += "/api/sync/changes"
+	u.RawQuery = url.Values{
+		"since": {strconv.Itoa(since)},
+		"limit": {strconv.Itoa(limit)},
+	}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Detail string `json:"detail"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err != nil || body.Detail == "" {
+			return nil, fmt.Errorf("primary answered %s", resp.Status)
+		}
+		return nil, fmt.Errorf("primary answered %s: %s", resp.Status, body.Detail)
+	}
+
+	var changes domain.LinkChanges
+	if err := json.NewDecoder(resp.Body).Decode(&changes); err != nil {
+		return nil, fmt.Errorf("failed to decode changes: %w", err)
+	}
+
+	return &changes, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestSyncClient_GetChanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"detail": "Invalid API key"})
+			return
+		}
+		if r.URL.Path != "/golinks/api/sync/changes" || r.URL.Query().Get("since") != "12" || r.URL.Query().Get("limit") != "50" {
+			t.Errorf("request = %s, want /golinks/api/sync/changes?since=12&limit=50", r.URL)
+		}
+		json.NewEncoder(w).Encode(domain.LinkChanges{
+			Versions: []domain.Shortcut{{ID: 13, Word: "wiki", Link: "https://wiki.example.com"}},
+			Cursor:   13,
+			Words:    []string{"wiki"},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewSyncClient(server.URL+"/golinks/", "secret-key")
+	if err != nil {
+		t.Fatalf("NewSyncClient() error = %v", err)
+	}
+	changes, err := client.GetChanges(context.Background(), 12, 50)
+	if err != nil {
+		t.Fatalf("SyncClient.GetChanges() error = %v", err)
+	}
+	if changes.Cursor != 13 || len(changes.Versions) != 1 || changes.Versions[0].Word != "wiki" {
+		t.Errorf("SyncClient.GetChanges() = %+v, want the wiki version at cursor 13", changes)
+	}
+
+	client, err = NewSyncClient(server.URL, "wrong-key")
+	if err != nil {
+		t.Fatalf("NewSyncClient() error = %v", err)
+	}
+	if _, err := client.GetChanges(context.Background(), 0, 50); err == nil || !strings.Contains(err.Error(), "Invalid API key") {
+		t.Errorf("SyncClient.GetChanges() with a wrong key error = %v, want the primary's detail", err)
+	}
+}
+
+func TestNewSyncClient_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		apiKey  string
+	}{
+		{"no scheme", "go.example.com", "key"},
+		{"unsupported scheme", "ftp://go.example.com", "key"},
+		{"no key", "https://go.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSyncClient(tt.baseURL, tt.apiKey); err == nil {
+				t.Errorf("NewSyncClient(%q, %q) succeeded, want an error", tt.baseURL, tt.apiKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SyncStateRepository records how far a replica has synced from each primary
+type SyncStateRepository struct {
+	db *sql.DB
+}
+
+// NewSyncStateRepository creates a new sync state repository
+func NewSyncStateRepository(db *sql.DB) *SyncStateRepository {
+	return &SyncStateRepository{db: db}
+}
+
+// GetCursor retrieves the last version synced from source, or 0 if none has been
+func (r *SyncStateRepository) GetCursor(ctx context.Context, source string) (int, error) {
+
+	query := `SELECT last_version FROM sync_state WHERE source = ?`
+
+	var cursor int
+	if err := r.db.QueryRowContext(ctx, query, source).Scan(&cursor); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get sync cursor: %w", err)
+	}
+
+	return cursor, nil
+}
+
+// SetCursor records the last version synced from source
+func (r *SyncStateRepository) SetCursor(ctx context.Context, source string, cursor int) error {
+
+	query := `
+		INSERT INTO sync_state (source, last_version, synced_at) VALUES (?, ?, ?)
+		ON CONFLICT(source) DO UPDATE SET last_version = excluded.last_version, synced_at = excluded.synced_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, source, cursor, time.Now().UTC().Truncate(time.Second)); err != nil {
+		return fmt.Errorf("failed to set sync cursor: %w", err)
+	}
+
+	return nil
+}
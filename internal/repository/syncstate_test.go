@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSyncStateRepository_Cursor(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewSyncStateRepository(db)
+
+	cursor, err := repo.GetCursor(ctx, "https://primary.example.com")
+	if err != nil {
+		t.Fatalf("SyncStateRepository.GetCursor() error = %v", err)
+	}
+	if cursor != 0 {
+		t.Errorf("SyncStateRepository.GetCursor() = %d before any sync, want 0", cursor)
+	}
+
+	for _, want := range []int{12, 30} {
+		if err := repo.SetCursor(ctx, "https://primary.example.com", want); err != nil {
+			t.Fatalf("SyncStateRepository.SetCursor() error = %v", err)
+		}
+		got, err := repo.GetCursor(ctx, "https://primary.example.com")
+		if err != nil {
+			t.Fatalf("SyncStateRepository.GetCursor() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("SyncStateRepository.GetCursor() = %d, want %d", got, want)
+		}
+	}
+
+	other, err := repo.GetCursor(ctx, "https://other.example.com")
+	if err != nil {
+		t.Fatalf("SyncStateRepository.GetCursor() error = %v", err)
+	}
+	if other != 0 {
+		t.Errorf("SyncStateRepository.GetCursor() = %d for another source, want 0", other)
+	}
+}
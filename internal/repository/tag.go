@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"golinks/internal/domain"
+)
+
+// TagRepository handles database operations for tags
+type TagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// AddTag tags a word. Tags apply to every version of the word, so the tag is only
+// stored if no version of the word already carries it.
+func (r *TagRepository) AddTag(ctx context.Context, wordID int, tag string) error {
+
+	query := `
+		INSERT INTO tags (word_id, tag)
+		SELECT CAST(? AS INTEGER), CAST(? AS TEXT)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM tags t JOIN linktable l ON t.word_id = l.id
+			WHERE t.tag = ? AND l.deleted_at IS NULL
+				AND l.word = (SELECT word FROM linktable WHERE id = ?)
+		)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, wordID, tag, tag, wordID)
+	if err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveTag removes a tag from every version of a word outside the trash
+func (r *TagRepository) RemoveTag(ctx context.Context, word, tag string) (int64, error) {
+
+	query := `
+		DELETE FROM tags
+		WHERE tag = ? AND word_id IN (SELECT id FROM linktable WHERE word = ? AND deleted_at IS NULL)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tag, word)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove tag: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return removed, nil
+}
+
+// GetTagsByWord retrieves the tags of a word, sorted alphabetically
+func (r *TagRepository) GetTagsByWord(ctx context.Context, word string) ([]string, error) {
+
+	query := `
+		SELECT DISTINCT t.tag
+		FROM tags t
+		JOIN linktable l ON t.word_id = l.id
+		WHERE l.word = ? AND l.deleted_at IS NULL
+		ORDER BY t.tag
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetAllTags retrieves the tags of every word that hasn't been deleted, each sorted
+// alphabetically
+func (r *TagRepository) GetAllTags(ctx context.Context) (map[string][]string, error) {
+
+	query := `
+		SELECT DISTINCT l.word, t.tag
+		FROM tags t
+		JOIN linktable l ON t.word_id = l.id
+		WHERE l.deleted_at IS NULL
+		ORDER BY l.word, t.tag
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := map[string][]string{}
+	for rows.Next() {
+		var word, tag string
+		if err := rows.Scan(&word, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags[word] = append(tags[word], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetKeywordsByTag retrieves the latest version of every word carrying a tag that is
+// visible to viewer, newest first
+func (r *TagRepository) GetKeywordsByTag(ctx context.Context, tag, viewer string) ([]domain.KeywordInfo, error) {
+
+	visible, args := visibleFilter(viewer)
+	query := keywordColumns + latestKeywordFrom + `
+		AND l.word IN (
+			SELECT tl.word FROM tags t JOIN linktable tl ON t.word_id = tl.id
+			WHERE t.tag = ? AND tl.deleted_at IS NULL
+		)
+		AND ` + visible + `
+		ORDER BY l.id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{tag}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keywords by tag: %w", err)
+	}
+	defer rows.Close()
+
+	return scanKeywords(rows)
+}
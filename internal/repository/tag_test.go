@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestTagRepository_AddAndGetTags(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	tagRepo := NewTagRepository(db)
+	ctx := context.Background()
+
+	first := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "user1"}
+	if err := shortcutRepo.Create(ctx, first); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+	if err := tagRepo.AddTag(ctx, first.ID, "engineering"); err != nil {
+		t.Fatalf("TagRepository.AddTag() error = %v", err)
+	}
+
+	// A newer version of the word keeps the tags of earlier versions
+	second := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"}
+	if err := shortcutRepo.Create(ctx, second); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+	if err := tagRepo.AddTag(ctx, second.ID, "docs"); err != nil {
+		t.Fatalf("TagRepository.AddTag() error = %v", err)
+	}
+	// Re-adding an existing tag is a no-op
+	if err := tagRepo.AddTag(ctx, second.ID, "engineering"); err != nil {
+		t.Fatalf("TagRepository.AddTag() error = %v", err)
+	}
+
+	tags, err := tagRepo.GetTagsByWord(ctx, "docs")
+	if err != nil {
+		t.Fatalf("TagRepository.GetTagsByWord() error = %v", err)
+	}
+	if want := []string{"docs", "engineering"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("TagRepository.GetTagsByWord() = %v, want %v", tags, want)
+	}
+
+	keywords, err := shortcutRepo.GetAllKeywords(ctx, "")
+	if err != nil {
+		t.Fatalf("ShortcutRepository.GetAllKeywords() error = %v", err)
+	}
+	if len(keywords) != 1 || !reflect.DeepEqual(keywords[0].Tags, []string{"docs", "engineering"}) {
+		t.Errorf("ShortcutRepository.GetAllKeywords() = %+v, want docs with both tags", keywords)
+	}
+}
+
+func TestTagRepository_GetAllTags(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	tagRepo := NewTagRepository(db)
+	ctx := context.Background()
+
+	tagged := map[string][]string{"docs": {"engineering", "docs"}, "wiki": {"team"}, "trashed": {"old"}}
+	for _, word := range []string{"docs", "wiki", "trashed", "docs"} {
+		shortcut := &domain.Shortcut{Word: word, Link: "https://" + word + ".example.com", User: "user1"}
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+		for _, tag := range tagged[word] {
+			if err := tagRepo.AddTag(ctx, shortcut.ID, tag); err != nil {
+				t.Fatalf("TagRepository.AddTag() error = %v", err)
+			}
+		}
+	}
+	if _, err := shortcutRepo.DeleteByWord(ctx, "trashed"); err != nil {
+		t.Fatalf("ShortcutRepository.DeleteByWord() error = %v", err)
+	}
+
+	tags, err := tagRepo.GetAllTags(ctx)
+	if err != nil {
+		t.Fatalf("TagRepository.GetAllTags() error = %v", err)
+	}
+	want := map[string][]string{"docs": {"docs", "engineering"}, "wiki": {"team"}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("TagRepository.GetAllTags() = %v, want %v", tags, want)
+	}
+}
+
+func TestTagRepository_GetKeywordsByTag(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	tagRepo := NewTagRepository(db)
+	ctx := context.Background()
+
+	shortcuts := []*domain.Shortcut{
+		{Word: "docs", Link: "https://docs.example.com", User: "user1"},
+		{Word: "github", Link: "https://github.com", User: "user2"},
+		{Word: "docs", Link: "https://docs.example.com/v2", User: "user1"},
+	}
+	for _, shortcut := range shortcuts {
+		if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	if err := tagRepo.AddTag(ctx, shortcuts[0].ID, "engineering"); err != nil {
+		t.Fatalf("TagRepository.AddTag() error = %v", err)
+	}
+
+	keywords, err := tagRepo.GetKeywordsByTag(ctx, "engineering", "")
+	if err != nil {
+		t.Fatalf("TagRepository.GetKeywordsByTag() error = %v", err)
+	}
+	if len(keywords) != 1 {
+		t.Fatalf("TagRepository.GetKeywordsByTag() returned %d keywords, want 1", len(keywords))
+	}
+	if keywords[0].Link != "https://docs.example.com/v2" {
+		t.Errorf("TagRepository.GetKeywordsByTag() link = %s, want latest version", keywords[0].Link)
+	}
+
+	secret := &domain.Shortcut{Word: "payroll", Link: "https://payroll.example.com", User: "user2", Private: true}
+	if err := shortcutRepo.Create(ctx, secret); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+	if err := tagRepo.AddTag(ctx, secret.ID, "engineering"); err != nil {
+		t.Fatalf("TagRepository.AddTag() error = %v", err)
+	}
+	if keywords, _ := tagRepo.GetKeywordsByTag(ctx, "engineering", "user1"); len(keywords) != 1 {
+		t.Errorf("TagRepository.GetKeywordsByTag() = %+v, want another user's private link hidden", keywords)
+	}
+	if keywords, _ := tagRepo.GetKeywordsByTag(ctx, "engineering", "user2"); len(keywords) != 2 {
+		t.Errorf("TagRepository.GetKeywordsByTag() = %+v, want the owner to see their private link", keywords)
+	}
+
+	keywords, err = tagRepo.GetKeywordsByTag(ctx, "unused", "")
+	if err != nil {
+		t.Fatalf("TagRepository.GetKeywordsByTag() error = %v", err)
+	}
+	if len(keywords) != 0 {
+		t.Errorf("TagRepository.GetKeywordsByTag() = %+v, want none", keywords)
+	}
+}
+
+func TestTagRepository_RemoveTag(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	shortcutRepo := NewShortcutRepository(db)
+	tagRepo := NewTagRepository(db)
+	ctx := context.Background()
+
+	shortcut := &domain.Shortcut{Word: "docs", Link: "https://docs.example.com", User: "user1"}
+	if err := shortcutRepo.Create(ctx, shortcut); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+	if err := tagRepo.AddTag(ctx, shortcut.ID, "engineering"); err != nil {
+		t.Fatalf("TagRepository.AddTag() error = %v", err)
+	}
+
+	removed, err := tagRepo.RemoveTag(ctx, "docs", "engineering")
+	if err != nil {
+		t.Fatalf("TagRepository.RemoveTag() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("TagRepository.RemoveTag() removed %d, want 1", removed)
+	}
+
+	tags, err := tagRepo.GetTagsByWord(ctx, "docs")
+	if err != nil {
+		t.Fatalf("TagRepository.GetTagsByWord() error = %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("TagRepository.GetTagsByWord() = %v, want none", tags)
+	}
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// Message formats a WebhookClient can post in
+const (
+	WebhookSlack = "slack"
+	WebhookTeams = "teams"
+	WebhookJSON  = "json"
+)
+
+// slackEscaper escapes the characters Slack's mrkdwn gives a meaning to
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// markup writes the text of a message: link links text to target, and escape makes text
+// show as it is
+type markup struct {
+	link   func(text, target string) string
+	escape func(text string) string
+}
+
+var (
+	slackMarkup = markup{
+		link: func(text, target string) string {
+			return "<" + target + "|" + slackEscaper.Replace(text) + ">"
+		},
+		escape: slackEscaper.Replace,
+	}
+	teamsMarkup = markup{
+		link: func(text, target string) string {
+			return "[" + text + "](" + target + ")"
+		},
+		escape: func(text string) string { return text },
+	}
+)
+
+// WebhookClient posts link changes to a channel's incoming webhook: a Slack message, a
+// Microsoft Teams Adaptive Card, or the changes themselves as JSON
+type WebhookClient struct {
+	client  *http.Client
+	url     string
+	format  string
+	baseURL string
+}
+
+// NewWebhookClient creates a client posting to webhookURL in format, linking each golink
+// to its page under baseURL
+func NewWebhookClient(webhookURL, format, baseURL string) (*WebhookClient, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook URL must be an http:// or https:// URL")
+	}
+	switch format {
+	case WebhookSlack, WebhookTeams, WebhookJSON:
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q; use %s, %s or %s", format, WebhookSlack, WebhookTeams, WebhookJSON)
+	}
+
+	return &WebhookClient{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		url:     webhookURL,
+		format:  format,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// Send posts one message announcing events
+func (c *WebhookClient) Send(ctx context.Context, events []domain.LinkEvent) error {
+	// Keep the <, > and & of Slack's markup readable
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(c.payload(events)); err != nil {
+		return fmt.Errorf("failed to encode webhook message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Leave out the URL, which holds the webhook's secret
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// payload builds the message body for the client's format
+func (c *WebhookClient) payload(events []domain.LinkEvent) interface{} {
+	switch c.format {
+	case WebhookJSON:
+		return struct {
+			Events []domain.LinkEvent `json:"events"`
+		}{events}
+	case WebhookTeams:
+		blocks := make([]map[string]interface{}, len(events))
+		for i, event := range events {
+			blocks[i] = map[string]interface{}{"type": "TextBlock", "text": c.describe(event, teamsMarkup), "wrap": true}
+		}
+		return map[string]interface{}{
+			"type": "message",
+			"attachments": []map[string]interface{}{{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    blocks,
+				},
+			}},
+		}
+	default:
+		lines := make([]string, len(events))
+		for i, event := range events {
+			lines[i] = c.describe(event, slackMarkup)
+		}
+		return map[string]string{"text": strings.Join(lines, "\n")}
+	}
+}
+
+// describe sums up a change in a line, such as "alice changed go/docs from
+// https://old.example.com to https://docs.example.com"
+func (c *WebhookClient) describe(event domain.LinkEvent, m markup) string {
+	golink := m.link("go/"+event.Word, c.baseURL+(&url.URL{Path: "/stats/" + event.Word}).EscapedPath())
+	user := m.escape(event.User)
+	if user == "" {
+		user = "Someone"
+	}
+
+	switch event.Action {
+	case domain.LinkCreated:
+		return fmt.Sprintf("%s created %s, going to %s", user, golink, m.escape(event.After.Link))
+	case domain.LinkDeleted:
+		return fmt.Sprintf("%s deleted %s, which went to %s", user, golink, m.escape(event.Before.Link))
+	}
+
+	before, after := m.escape(event.Before.Link), m.escape(event.After.Link)
+	line := fmt.Sprintf("%s changed %s from %s to %s", user, golink, before, after)
+	switch {
+	case before == after && event.After.Archived && !event.Before.Archived:
+		line = fmt.Sprintf("%s archived %s, which went to %s", user, golink, after)
+	case before == after && event.Before.Archived && !event.After.Archived:
+		line = fmt.Sprintf("%s brought %s back from the archive, going to %s", user, golink, after)
+	case before == after:
+		line = fmt.Sprintf("%s changed %s, still going to %s", user, golink, after)
+	case event.After.Archived && !event.Before.Archived:
+		line += ", and archived it"
+	case event.Before.Archived && !event.After.Archived:
+		line += ", and brought it back from the archive"
+	}
+	if event.Before.User != event.After.User {
+		line += fmt.Sprintf(", and handed it from %s to %s", m.escape(event.Before.User), m.escape(event.After.User))
+	}
+	return line
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+func TestWebhookClient_Send(t *testing.T) {
+	events := []domain.LinkEvent{
+		{
+			Action: domain.LinkCreated, Word: "docs", User: "alice",
+			After: &domain.Shortcut{Word: "docs", Link: "https://docs.example.com/?a=1&b=2", User: "alice"},
+		},
+		{
+			Action: domain.LinkUpdated, Word: "payments/runbook", User: "bob",
+			Before: &domain.Shortcut{Word: "payments/runbook", Link: "https://old.example.com", User: "alice"},
+			After:  &domain.Shortcut{Word: "payments/runbook", Link: "https://new.example.com", User: "bob"},
+		},
+		{
+			Action: domain.LinkUpdated, Word: "wiki", User: "alice",
+			Before: &domain.Shortcut{Word: "wiki", Link: "https://wiki.example.com", User: "alice"},
+			After:  &domain.Shortcut{Word: "wiki", Link: "https://wiki.example.com", User: "alice"},
+		},
+		{
+			Action: domain.LinkUpdated, Word: "legacy", User: "carol",
+			Before: &domain.Shortcut{Word: "legacy", Link: "https://legacy.example.com", User: "carol"},
+			After:  &domain.Shortcut{Word: "legacy", Link: "https://legacy.example.com", User: "carol", Archived: true},
+		},
+		{
+			Action: domain.LinkDeleted, Word: "old", User: "carol",
+			Before: &domain.Shortcut{Word: "old", Link: "https://old.example.com", User: "carol"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+	}{
+		{
+			name:   "slack",
+			format: WebhookSlack,
+			want: []string{
+				`{"text":"alice created <https://go.example.com/stats/docs|go/docs>, going to https://docs.example.com/?a=1&amp;b=2\n`,
+				`bob changed <https://go.example.com/stats/payments/runbook|go/payments/runbook> from https://old.example.com to https://new.example.com, and handed it from alice to bob\n`,
+				`alice changed <https://go.example.com/stats/wiki|go/wiki>, still going to https://wiki.example.com\n`,
+				`carol archived <https://go.example.com/stats/legacy|go/legacy>, which went to https://legacy.example.com\n`,
+				`carol deleted <https://go.example.com/stats/old|go/old>, which went to https://old.example.com"}`,
+			},
+		},
+		{
+			name:   "teams",
+			format: WebhookTeams,
+			want: []string{
+				`"contentType":"application/vnd.microsoft.card.adaptive"`,
+				`"text":"alice created [go/docs](https://go.example.com/stats/docs), going to https://docs.example.com/?a=1&b=2"`,
+				`"text":"carol deleted [go/old](https://go.example.com/stats/old), which went to https://old.example.com"`,
+			},
+		},
+		{
+			name:   "json",
+			format: WebhookJSON,
+			want: []string{
+				`{"events":[{"action":"created","word":"docs","user":"alice","after":{`,
+				`"before":{"id":0,"word":"old","link":"https://old.example.com"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("request = %s with %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+				}
+				data, _ := io.ReadAll(r.Body)
+				body = string(data)
+			}))
+			defer server.Close()
+
+			client, err := NewWebhookClient(server.URL+"/hook", tt.format, "https://go.example.com/")
+			if err != nil {
+				t.Fatalf("NewWebhookClient() error = %v", err)
+			}
+			if err := client.Send(context.Background(), events); err != nil {
+				t.Fatalf("WebhookClient.Send() error = %v", err)
+			}
+			if !json.Valid([]byte(body)) {
+				t.Fatalf("WebhookClient.Send() posted invalid JSON: %s", body)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("WebhookClient.Send() posted %s, want it to contain %s", body, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookClient_SendFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client, err := NewWebhookClient(server.URL+"/secret-token", WebhookSlack, "https://go.example.com")
+	if err != nil {
+		t.Fatalf("NewWebhookClient() error = %v", err)
+	}
+	event := domain.LinkEvent{Action: domain.LinkCreated, Word: "docs", After: &domain.Shortcut{Link: "https://docs.example.com"}, Time: time.Now()}
+	if err := client.Send(context.Background(), []domain.LinkEvent{event}); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("WebhookClient.Send() error = %v, want the 403 status", err)
+	}
+
+	server.Close()
+	err = client.Send(context.Background(), []domain.LinkEvent{event})
+	if err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("WebhookClient.Send() to a closed server error = %v, want an error without the URL", err)
+	}
+}
+
+func TestNewWebhookClient_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		format string
+	}{
+		{"no scheme", "hooks.slack.com/services/T0/B0/x", WebhookSlack},
+		{"unsupported scheme", "ftp://hooks.example.com", WebhookSlack},
+		{"unknown format", "https://hooks.example.com", "discord"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWebhookClient(tt.url, tt.format, "https://go.example.com"); err == nil {
+				t.Errorf("NewWebhookClient(%q, %q) succeeded, want an error", tt.url, tt.format)
+			}
+		})
+	}
+}
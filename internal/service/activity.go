@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// Activity page sizes used by CreatedLinks and DeletedLinks
+const (
+	DefaultActivityLimit = 50
+	MaxActivityLimit     = 100
+)
+
+// activityLimit checks the page size asked for, defaulting to DefaultActivityLimit
+func activityLimit(limit int) (int, error) {
+	if limit == 0 {
+		return DefaultActivityLimit, nil
+	}
+	if limit < 0 || limit > MaxActivityLimit {
+		return 0, InvalidQueryError{Message: fmt.Sprintf("limit must be between 1 and %d", MaxActivityLimit)}
+	}
+	return limit, nil
+}
+
+// CreatedLinks returns up to limit of the golinks userID can see, newest first, as they
+// were when created. A word deleted and created again counts as created again. Given
+// the ID of the last link of a page as cursor, it returns the page after it.
+func (s *LinkService) CreatedLinks(ctx context.Context, userID, cursor string, limit int) ([]domain.LinkActivity, error) {
+	limit, err := activityLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	before := 0
+	if cursor != "" {
+		if before, err = strconv.Atoi(cursor); err != nil || before <= 0 {
+			return nil, InvalidQueryError{Message: "cursor must be the id of a created link"}
+		}
+	}
+
+	created, err := s.shortcutRepo.GetCreated(ctx, userID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get created links: %w", err)
+	}
+
+	activity := make([]domain.LinkActivity, len(created))
+	for i, shortcut := range created {
+		activity[i] = domain.LinkActivity{
+			ID:     strconv.Itoa(shortcut.ID),
+			Action: domain.LinkCreated,
+			Word:   shortcut.Word,
+			Link:   shortcut.Link,
+			Owner:  shortcut.User,
+			Time:   shortcut.CreatedAt,
+		}
+	}
+	return activity, nil
+}
+
+// DeletedLinks returns up to limit of the golinks in the trash that userID can see, most
+// recently deleted first. Given the ID of the last link of a page as cursor, it returns
+// the page after it. Links purged from the trash are no longer listed.
+func (s *LinkService) DeletedLinks(ctx context.Context, userID, cursor string, limit int) ([]domain.LinkActivity, error) {
+	limit, err := activityLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	// Deleted links are told apart by when they were deleted and their word
+	var after int64
+	var afterWord string
+	if cursor != "" {
+		nanos, word, ok := strings.Cut(cursor, "-")
+		after, err = strconv.ParseInt(nanos, 10, 64)
+		if !ok || err != nil || word == "" {
+			return nil, InvalidQueryError{Message: "cursor must be the id of a deleted link"}
+		}
+		afterWord = word
+	}
+
+	trash, err := s.shortcutRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+	// Order links deleted at the same moment by word, so the cursor can tell them apart
+	sort.SliceStable(trash, func(i, j int) bool {
+		if !trash[i].DeletedAt.Equal(trash[j].DeletedAt) {
+			return trash[i].DeletedAt.After(trash[j].DeletedAt)
+		}
+		return trash[i].Word < trash[j].Word
+	})
+
+	activity := []domain.LinkActivity{}
+	for _, link := range trash {
+		if len(activity) == limit {
+			break
+		}
+		if link.Private && link.User != userID {
+			continue
+		}
+		deletedAt := link.DeletedAt.UnixNano()
+		if cursor != "" && (deletedAt > after || (deletedAt == after && link.Word <= afterWord)) {
+			continue
+		}
+		activity = append(activity, domain.LinkActivity{
+			ID:     fmt.Sprintf("%d-%s", deletedAt, link.Word),
+			Action: domain.LinkDeleted,
+			Word:   link.Word,
+			Link:   link.Link,
+			Owner:  link.User,
+			Time:   link.DeletedAt,
+		})
+	}
+	return activity, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_CreatedLinks(t *testing.T) {
+	repo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+	service := NewLinkService(repo, &mockQueryRepository{})
+	ctx := context.Background()
+	for _, req := range []struct {
+		domain.LinkRequest
+		user string
+	}{
+		{domain.LinkRequest{Word: "docs", Link: "https://docs.example.com"}, "alice"},
+		{domain.LinkRequest{Word: "wiki", Link: "https://wiki.example.com"}, "alice"},
+		{domain.LinkRequest{Word: "secret", Link: "https://secret.example.com", Private: true}, "bob"},
+		{domain.LinkRequest{Word: "docs", Link: "https://docs.example.com/v2"}, "alice"},
+	} {
+		if err := service.UpdateLink(ctx, req.LinkRequest, req.user); err != nil {
+			t.Fatalf("UpdateLink(%s) error = %v", req.Word, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		cursor  string
+		limit   int
+		want    []string
+		wantErr bool
+	}{
+		{name: "newest first as created", userID: "alice", want: []string{"wiki https://wiki.example.com", "docs https://docs.example.com"}},
+		{name: "owner sees private links", userID: "bob", want: []string{"secret https://secret.example.com", "wiki https://wiki.example.com", "docs https://docs.example.com"}},
+		{name: "limit", userID: "bob", limit: 1, want: []string{"secret https://secret.example.com"}},
+		{name: "after cursor", userID: "bob", cursor: "secret", want: []string{"wiki https://wiki.example.com", "docs https://docs.example.com"}},
+		{name: "invalid cursor", userID: "bob", cursor: "0", wantErr: true},
+		{name: "limit too large", userID: "bob", limit: MaxActivityLimit + 1, wantErr: true},
+	}
+
+	// Cursors name the word whose ID to page after
+	ids := map[string]string{}
+	all, err := service.CreatedLinks(ctx, "bob", "", 0)
+	if err != nil {
+		t.Fatalf("CreatedLinks() error = %v", err)
+	}
+	for _, item := range all {
+		ids[item.Word] = item.ID
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := tt.cursor
+			if id, ok := ids[cursor]; ok {
+				cursor = id
+			}
+			activity, err := service.CreatedLinks(ctx, tt.userID, cursor, tt.limit)
+			if tt.wantErr {
+				if _, ok := err.(InvalidQueryError); !ok {
+					t.Fatalf("CreatedLinks() error = %v, want InvalidQueryError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatedLinks() error = %v", err)
+			}
+
+			got := []string{}
+			for _, item := range activity {
+				if item.Action != domain.LinkCreated || item.ID == "" {
+					t.Errorf("CreatedLinks() item = %+v, want a created link with an ID", item)
+				}
+				got = append(got, item.Word+" "+item.Link)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreatedLinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkService_DeletedLinks(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockShortcutRepository{
+		shortcuts: map[string]*domain.Shortcut{},
+		trash: map[string]*domain.Shortcut{
+			"old":    {Word: "old", Link: "https://old.example.com", User: "alice", CreatedAt: deletedAt.Add(-time.Hour)},
+			"wiki":   {Word: "wiki", Link: "https://wiki.example.com", User: "alice", CreatedAt: deletedAt},
+			"docs":   {Word: "docs", Link: "https://docs.example.com", User: "alice", CreatedAt: deletedAt},
+			"secret": {Word: "secret", Link: "https://secret.example.com", User: "bob", Private: true, CreatedAt: deletedAt.Add(time.Hour)},
+		},
+	}
+	service := NewLinkService(repo, &mockQueryRepository{})
+	docsID := "1714564800000000000-docs"
+
+	tests := []struct {
+		name    string
+		userID  string
+		cursor  string
+		limit   int
+		want    []string
+		wantErr bool
+	}{
+		{name: "most recently deleted first", userID: "alice", want: []string{"docs", "wiki", "old"}},
+		{name: "owner sees private links", userID: "bob", limit: 2, want: []string{"secret", "docs"}},
+		{name: "after cursor deleted at the same time", userID: "alice", cursor: docsID, want: []string{"wiki", "old"}},
+		{name: "after the last link", userID: "alice", cursor: "1714561200000000000-old", want: []string{}},
+		{name: "invalid cursor", userID: "alice", cursor: "docs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity, err := service.DeletedLinks(context.Background(), tt.userID, tt.cursor, tt.limit)
+			if tt.wantErr {
+				if _, ok := err.(InvalidQueryError); !ok {
+					t.Fatalf("DeletedLinks() error = %v, want InvalidQueryError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeletedLinks() error = %v", err)
+			}
+
+			got := []string{}
+			for _, item := range activity {
+				if item.Action != domain.LinkDeleted {
+					t.Errorf("DeletedLinks() item = %+v, want a deleted link", item)
+				}
+				got = append(got, item.Word)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeletedLinks() = %v, want %v", got, tt.want)
+			}
+			if len(activity) > 0 && activity[0].Word == "docs" && activity[0].ID != docsID {
+				t.Errorf("DeletedLinks() docs ID = %q, want %q", activity[0].ID, docsID)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"golinks/internal/domain"
+)
+
+// Recent change list sizes used by RecentChanges
+const (
+	DefaultRecentChanges = 50
+	MaxRecentChanges     = 500
+)
+
+// OwnerRepository reports who owns golinks and how they changed, for admins
+type OwnerRepository interface {
+	GetOwners(ctx context.Context) ([]domain.UserSummary, error)
+	GetRecentVersions(ctx context.Context, limit int) ([]domain.Shortcut, error)
+}
+
+// StorageStatsRepository reports how much the database holds
+type StorageStatsRepository interface {
+	GetStorageStats(ctx context.Context) (*domain.StorageStats, error)
+}
+
+// RoleLister tells which role each user holds and lists the assigned ones
+type RoleLister interface {
+	RoleOf(ctx context.Context, user string) (domain.Role, error)
+	ListRoles(ctx context.Context, requester string) ([]domain.UserRole, error)
+}
+
+// AdminService reports on users, changes and storage for the admin dashboard
+type AdminService struct {
+	owners OwnerRepository
+	roles  RoleLister
+
+	// storage reports the size of the database, or is nil if the backend can't tell
+	storage StorageStatsRepository
+}
+
+// NewAdminService creates a new admin service. storage may be nil.
+func NewAdminService(owners OwnerRepository, roles RoleLister, storage StorageStatsRepository) *AdminService {
+	return &AdminService{owners: owners, roles: roles, storage: storage}
+}
+
+// ListUsers lists every user who owns golinks or was assigned a role, with their role
+// and how many golinks they own, the users owning most first. Only admins may list them.
+func (s *AdminService) ListUsers(ctx context.Context, requester string) ([]domain.UserSummary, error) {
+	assigned, err := s.roles.ListRoles(ctx, requester)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := s.owners.GetOwners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owners: %w", err)
+	}
+
+	users := make([]domain.UserSummary, 0, len(owners)+len(assigned))
+	listed := map[string]bool{}
+	for _, owner := range owners {
+		users = append(users, owner)
+		listed[owner.User] = true
+	}
+	for _, role := range assigned {
+		if !listed[role.User] {
+			users = append(users, domain.UserSummary{User: role.User})
+			listed[role.User] = true
+		}
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		if users[i].Links != users[j].Links {
+			return users[i].Links > users[j].Links
+		}
+		return users[i].User < users[j].User
+	})
+
+	for i := range users {
+		role, err := s.roles.RoleOf(ctx, users[i].User)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get role of %s: %w", users[i].User, err)
+		}
+		users[i].Role = role
+	}
+
+	return users, nil
+}
+
+// RecentChanges returns the limit golink versions stored most recently, newest first,
+// private ones and those of words in the trash included. A zero limit uses
+// DefaultRecentChanges.
+func (s *AdminService) RecentChanges(ctx context.Context, limit int) ([]domain.Shortcut, error) {
+	if limit == 0 {
+		limit = DefaultRecentChanges
+	}
+	if limit < 1 || limit > MaxRecentChanges {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("limit must be between 1 and %d", MaxRecentChanges)}
+	}
+
+	changes, err := s.owners.GetRecentVersions(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent changes: %w", err)
+	}
+	if changes == nil {
+		changes = []domain.Shortcut{}
+	}
+
+	return changes, nil
+}
+
+// StorageStats reports how much the database holds
+func (s *AdminService) StorageStats(ctx context.Context) (*domain.StorageStats, error) {
+	if s.storage == nil {
+		return nil, NotFoundError{Message: "This storage backend doesn't report its size"}
+	}
+
+	stats, err := s.storage.GetStorageStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage stats: %w", err)
+	}
+
+	return stats, nil
+}
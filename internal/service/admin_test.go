@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+// mockOwnerRepository reports canned owners and the last limit of its versions, newest first
+type mockOwnerRepository struct {
+	owners   []domain.UserSummary
+	versions []domain.Shortcut
+}
+
+func (m *mockOwnerRepository) GetOwners(ctx context.Context) ([]domain.UserSummary, error) {
+	return m.owners, nil
+}
+
+func (m *mockOwnerRepository) GetRecentVersions(ctx context.Context, limit int) ([]domain.Shortcut, error) {
+	var recent []domain.Shortcut
+	for i := len(m.versions) - 1; i >= 0 && len(recent) < limit; i-- {
+		recent = append(recent, m.versions[i])
+	}
+	return recent, nil
+}
+
+// mockStorageStatsRepository reports canned storage stats
+type mockStorageStatsRepository struct {
+	stats *domain.StorageStats
+}
+
+func (m *mockStorageStatsRepository) GetStorageStats(ctx context.Context) (*domain.StorageStats, error) {
+	return m.stats, nil
+}
+
+func TestAdminService_ListUsers(t *testing.T) {
+	ctx := context.Background()
+	owners := &mockOwnerRepository{owners: []domain.UserSummary{
+		{User: "bob", Links: 3, Private: 1},
+		{User: "alice", Links: 1, Archived: 1},
+	}}
+	roles := NewRoleService(newMockRoleRepository(map[string]domain.Role{
+		"bob":   domain.RoleViewer,
+		"carol": domain.RoleEditor,
+	}), []string{"root"}, domain.RoleEditor)
+	service := NewAdminService(owners, roles, nil)
+
+	users, err := service.ListUsers(ctx, "root")
+	if err != nil {
+		t.Fatalf("AdminService.ListUsers() error = %v", err)
+	}
+	want := []domain.UserSummary{
+		{User: "bob", Role: domain.RoleViewer, Links: 3, Private: 1},
+		{User: "alice", Role: domain.RoleEditor, Links: 1, Archived: 1},
+		{User: "carol", Role: domain.RoleEditor},
+	}
+	if !reflect.DeepEqual(users, want) {
+		t.Errorf("AdminService.ListUsers() = %+v, want %+v", users, want)
+	}
+
+	if _, err := service.ListUsers(ctx, "alice"); !sameErrorType(err, ForbiddenError{}) {
+		t.Errorf("AdminService.ListUsers() by a non-admin error = %v, want ForbiddenError", err)
+	}
+}
+
+func TestAdminService_RecentChanges(t *testing.T) {
+	ctx := context.Background()
+	owners := &mockOwnerRepository{}
+	for i := 1; i <= DefaultRecentChanges+10; i++ {
+		owners.versions = append(owners.versions, domain.Shortcut{ID: i, Word: "docs"})
+	}
+	service := NewAdminService(owners, nil, nil)
+
+	tests := []struct {
+		name    string
+		limit   int
+		wantLen int
+		wantErr error
+	}{
+		{name: "default limit", limit: 0, wantLen: DefaultRecentChanges},
+		{name: "explicit limit", limit: 5, wantLen: 5},
+		{name: "negative limit", limit: -1, wantErr: InvalidQueryError{}},
+		{name: "limit too large", limit: MaxRecentChanges + 1, wantErr: InvalidQueryError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := service.RecentChanges(ctx, tt.limit)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("AdminService.RecentChanges() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AdminService.RecentChanges() error = %v", err)
+			}
+			if len(changes) != tt.wantLen || changes[0].ID != DefaultRecentChanges+10 {
+				t.Errorf("AdminService.RecentChanges() = %d changes from %d, want %d from the newest",
+					len(changes), changes[0].ID, tt.wantLen)
+			}
+		})
+	}
+
+	if changes, err := NewAdminService(&mockOwnerRepository{}, nil, nil).RecentChanges(ctx, 0); err != nil || changes == nil {
+		t.Errorf("AdminService.RecentChanges() without changes = %v, %v, want an empty list", changes, err)
+	}
+}
+
+func TestAdminService_StorageStats(t *testing.T) {
+	ctx := context.Background()
+	want := &domain.StorageStats{Links: 2, Tables: []domain.TableStats{{Name: "linktable", Rows: 3}}, Bytes: 4096}
+
+	stats, err := NewAdminService(nil, nil, &mockStorageStatsRepository{stats: want}).StorageStats(ctx)
+	if err != nil || !reflect.DeepEqual(stats, want) {
+		t.Errorf("AdminService.StorageStats() = %+v, %v, want %+v", stats, err, want)
+	}
+
+	if _, err := NewAdminService(nil, nil, nil).StorageStats(ctx); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("AdminService.StorageStats() without storage stats error = %v, want NotFoundError", err)
+	}
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// maxAliasHops is the most aliases a query may pass through on its way to a URL
+const maxAliasHops = 10
+
+// followAlias records that resolution passed through the alias word, failing if it has
+// been there before or has passed through too many aliases. Links stored before aliases
+// were checked on write can still loop.
+func followAlias(res *domain.Resolution, seen map[string]int, word string) error {
+	if _, ok := seen[word]; ok {
+		return AliasLoopError{
+			Message: fmt.Sprintf("The aliases of %s loop back through %s", res.Word, word),
+			Chain:   seenChain(seen, word),
+		}
+	}
+	res.Hops++
+	seen[word] = res.Hops
+
+	if res.Hops > maxAliasHops {
+		return AliasLoopError{
+			Message: fmt.Sprintf("%s passes through more than %d aliases", res.Word, maxAliasHops),
+			Chain:   seenChain(seen, ""),
+		}
+	}
+	return nil
+}
+
+// seenChain lists the aliases in seen in the order they were passed through, followed by
+// next unless it is empty
+func seenChain(seen map[string]int, next string) []string {
+	chain := make([]string, len(seen))
+	for word, hop := range seen {
+		chain[hop-1] = word
+	}
+	if next != "" {
+		chain = append(chain, next)
+	}
+	return chain
+}
+
+// checkAlias rejects making word an alias to target if following target as userID sees it
+// leads back to word, or through so many aliases that word would pass through more than
+// maxAliasHops. batchLinks holds the links of words created earlier in the same bulk
+// request, which are followed in place of the stored ones.
+func (s *LinkService) checkAlias(
+	ctx context.Context, word, target, userID string, batchLinks map[string]string,
+) error {
+
+	seen := map[string]bool{word: true}
+	next := target
+	for hops := 1; ; hops++ {
+		link, ok := batchLinks[next]
+		if !ok {
+			shortcut, err := s.lookupAlias(ctx, next, userID)
+			if err != nil || shortcut == nil {
+				// Targets that don't resolve are left for the caller to reject
+				return err
+			}
+			next, link = shortcut.Word, shortcut.Link
+		}
+		if seen[next] {
+			return InvalidQueryError{Message: fmt.Sprintf("Pointing %s at %s would make its aliases loop", word, target)}
+		}
+		if hops > maxAliasHops {
+			return InvalidQueryError{
+				Message: fmt.Sprintf("%s would pass through more than %d aliases", word, maxAliasHops),
+			}
+		}
+		seen[next] = true
+
+		if s.isTarget(link) {
+			return nil
+		}
+		aliased, err := s.aliasTarget(ctx, next, link, userID)
+		if err != nil {
+			return err
+		}
+		next = strings.TrimSpace(aliased)
+	}
+}
+
+// aliasResolves reports whether target, the keyword an alias points at, resolves as userID
+// sees it. Checking doesn't count as a click on target. Archived keywords can't be pointed at.
+func (s *LinkService) aliasResolves(ctx context.Context, target, userID string) (bool, error) {
+	_, err := s.ResolveDetail(ctx, target, false, userID)
+	var invalid InvalidQueryError
+	if errors.As(err, &invalid) {
+		return false, nil
+	}
+	var archived ArchivedError
+	if errors.As(err, &archived) {
+		return false, InvalidQueryError{Message: fmt.Sprintf("%s has been archived, so no keyword can point to it", archived.Shortcut.Word)}
+	}
+	return err == nil, err
+}
+
+// lookupAlias finds the link a query resolves through as userID sees it, falling back to
+// shorter words the way resolution does, or nil if there is none
+func (s *LinkService) lookupAlias(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	for {
+		word = NormalizeWord(word)
+		shortcut, err := s.shortcutRepo.GetByWord(ctx, word)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get shortcut: %w", err)
+		}
+		if shortcut == nil {
+			if shortcut, err = s.aliasedShortcut(ctx, word); err != nil {
+				return nil, err
+			}
+		}
+		if visibleTo(shortcut, userID) {
+			return shortcut, nil
+		}
+
+		shorter := word
+		if strings.Contains(word, " ") {
+			shorter, _ = moveLastWord(word, "")
+		} else if shorter, _, err = s.moveLastSegment(ctx, word, ""); err != nil {
+			return nil, err
+		}
+		if shorter == word {
+			return nil, nil
+		}
+		word = shorter
+	}
+}
+
+// AliasRepository interface for the other words golinks answer to
+type AliasRepository interface {
+	GetTarget(ctx context.Context, alias string) (string, error)
+	GetAliases(ctx context.Context, word string) ([]string, error)
+	GetAllAliases(ctx context.Context) (map[string]string, error)
+	Add(ctx context.Context, alias, word, user string) error
+	Remove(ctx context.Context, alias, word string) (int64, error)
+	Rename(ctx context.Context, word, newWord, user string) error
+}
+
+// WithAliases lets golinks answer to other words, stored as aliases of them rather than as
+// keywords pointing at them, and be renamed with the old word kept as an alias
+func WithAliases(aliases AliasRepository) Option {
+	return func(s *LinkService) {
+		s.aliases = aliases
+	}
+}
+
+// aliasedShortcut returns the current version of the golink word is an alias of, or nil
+// if it is none
+func (s *LinkService) aliasedShortcut(ctx context.Context, word string) (*domain.Shortcut, error) {
+	if s.aliases == nil {
+		return nil, nil
+	}
+
+	target, err := s.aliases.GetTarget(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alias: %w", err)
+	}
+	if target == "" {
+		return nil, nil
+	}
+
+	shortcut, err := s.shortcutRepo.GetByWord(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	return shortcut, nil
+}
+
+// checkFreeWord returns an error unless userID may give a golink, or an alias of one, the
+// name word, which must not be in use as either
+func (s *LinkService) checkFreeWord(ctx context.Context, word, userID string) error {
+	if word == "" {
+		return InvalidQueryError{Message: "No word given"}
+	}
+	if strings.HasSuffix(word, "/") {
+		return InvalidQueryError{Message: "Words ending in a '/' are not supported"}
+	}
+	if err := validateWord(word); err != nil {
+		return err
+	}
+
+	namespace, member, err := s.namespaceMember(ctx, word, userID)
+	if err != nil {
+		return err
+	}
+	if namespace != "" {
+		if err := s.checkNamespacedWord(ctx, word, namespace, member, userID); err != nil {
+			return err
+		}
+	}
+	if err := s.wordRules.check(word); err != nil {
+		return err
+	}
+
+	existing, err := s.shortcutRepo.GetByWord(ctx, word)
+	if err != nil {
+		return fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	if existing == nil {
+		existing, err = s.aliasedShortcut(ctx, word)
+		if err != nil {
+			return err
+		}
+	}
+	if existing != nil {
+		return InvalidQueryError{Message: fmt.Sprintf("%s is already taken", word)}
+	}
+	return nil
+}
+
+// modifiableShortcut returns the current version of a golink userID may change, failing
+// with action, such as "rename", in the error when they may not
+func (s *LinkService) modifiableShortcut(ctx context.Context, word, userID, action string) (*domain.Shortcut, error) {
+	current, err := s.shortcutRepo.GetByWord(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	if !visibleTo(current, userID) {
+		return nil, NotFoundError{Message: fmt.Sprintf("No golink found for %s", word)}
+	}
+
+	allowed, err := s.canModify(ctx, current, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ForbiddenError{Message: fmt.Sprintf("Only %s or an admin can %s %s", current.User, action, word)}
+	}
+	return current, nil
+}
+
+// ListAliases returns the aliases of a golink userID can see, in alphabetical order
+func (s *LinkService) ListAliases(ctx context.Context, word, userID string) ([]string, error) {
+	word = NormalizeWord(word)
+
+	if _, err := s.GetShortcut(ctx, word, userID); err != nil {
+		return nil, err
+	}
+	if s.aliases == nil {
+		return []string{}, nil
+	}
+
+	aliases, err := s.aliases.GetAliases(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aliases: %w", err)
+	}
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return aliases, nil
+}
+
+// AddAlias makes alias another word for a golink, resolving to it and counting towards
+// its stats. Only those who may change the golink can add aliases to it; adding an alias
+// twice is not an error.
+func (s *LinkService) AddAlias(ctx context.Context, word, alias, userID string) error {
+	word, alias = NormalizeWord(word), NormalizeWord(alias)
+	if s.aliases == nil {
+		return InvalidQueryError{Message: "Aliases are not supported on this server"}
+	}
+
+	if _, err := s.modifiableShortcut(ctx, word, userID, "add aliases to"); err != nil {
+		return err
+	}
+
+	target, err := s.aliases.GetTarget(ctx, alias)
+	if err != nil {
+		return fmt.Errorf("failed to get alias: %w", err)
+	}
+	if target == word {
+		return nil
+	}
+	if err := s.checkFreeWord(ctx, alias, userID); err != nil {
+		return err
+	}
+
+	if err := s.aliases.Add(ctx, alias, word, userID); err != nil {
+		return fmt.Errorf("failed to add alias: %w", err)
+	}
+	s.keywords.Invalidate()
+
+	return nil
+}
+
+// RemoveAlias stops alias from resolving to a golink
+func (s *LinkService) RemoveAlias(ctx context.Context, word, alias, userID string) error {
+	word, alias = NormalizeWord(word), NormalizeWord(alias)
+	if s.aliases == nil {
+		return NotFoundError{Message: fmt.Sprintf("%s is not an alias of %s", alias, word)}
+	}
+
+	if _, err := s.modifiableShortcut(ctx, word, userID, "remove aliases of"); err != nil {
+		return err
+	}
+
+	removed, err := s.aliases.Remove(ctx, alias, word)
+	if err != nil {
+		return fmt.Errorf("failed to remove alias: %w", err)
+	}
+	if removed == 0 {
+		return NotFoundError{Message: fmt.Sprintf("%s is not an alias of %s", alias, word)}
+	}
+	s.keywords.Invalidate()
+
+	return nil
+}
+
+// RenameLink moves a golink to a new word, bringing its history, stats, tags, aliases and
+// pins along, and keeps the old word as an alias so existing links to it still work. The
+// rename is stored as a new version by userID. A golink can be renamed to one of its own
+// aliases, but not to a word in the trash.
+func (s *LinkService) RenameLink(ctx context.Context, word string, req domain.RenameRequest, userID string) (*domain.Shortcut, error) {
+	word, newWord := NormalizeWord(word), NormalizeWord(req.Word)
+	if s.aliases == nil {
+		return nil, InvalidQueryError{Message: "Renaming links is not supported on this server"}
+	}
+
+	current, err := s.modifiableShortcut(ctx, word, userID, "rename")
+	if err != nil {
+		return nil, err
+	}
+	if newWord == word {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("%s already has that name", word)}
+	}
+
+	target, err := s.aliases.GetTarget(ctx, newWord)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alias: %w", err)
+	}
+	if target != word {
+		if err := s.checkFreeWord(ctx, newWord, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	trash, err := s.shortcutRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+	for _, link := range trash {
+		if link.Word == newWord {
+			return nil, InvalidQueryError{Message: fmt.Sprintf("%s is in the trash; purge it before reusing the word", newWord)}
+		}
+	}
+
+	if err := s.aliases.Rename(ctx, word, newWord, userID); err != nil {
+		return nil, fmt.Errorf("failed to rename shortcut: %w", err)
+	}
+	// The rename bypasses the shortcut cache, which may still hold the golink under its
+	// old word and nothing under the new one
+	s.invalidateWords(ctx, word, newWord)
+	s.keywords.Invalidate()
+
+	renamed, err := s.shortcutRepo.GetByWord(ctx, newWord)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	if renamed == nil {
+		return nil, NotFoundError{Message: fmt.Sprintf("%s can't be found after renaming it to %s", word, newWord)}
+	}
+
+	// Store the rename as a new version too, so it shows in the history and replicas,
+	// which copy versions, pick it up
+	shortcut := &domain.Shortcut{
+		Word:        newWord,
+		Link:        renamed.Link,
+		User:        renamed.User,
+		Icon:        renamed.Icon,
+		Description: renamed.Description,
+		UpdatedBy:   userID,
+		Private:     renamed.Private,
+		Prefix:      renamed.Prefix,
+		Archived:    renamed.Archived,
+		Metadata:    renamed.Metadata,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.shortcutRepo.Create(ctx, shortcut); err != nil {
+		return nil, fmt.Errorf("failed to create shortcut: %w", err)
+	}
+	s.notify(userID, current, shortcut)
+
+	return shortcut, nil
+}
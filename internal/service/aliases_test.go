@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// aliasChain stores words chain0 to chain<n-1>, each an alias to the next, with the last
+// pointing at a URL
+func aliasChain(shortcuts map[string]*domain.Shortcut, n int) {
+	for i := 0; i < n; i++ {
+		link := fmt.Sprintf("chain%d", i+1)
+		if i == n-1 {
+			link = "https://example.com"
+		}
+		word := fmt.Sprintf("chain%d", i)
+		shortcuts[word] = &domain.Shortcut{ID: 100 + i, Word: word, Link: link, User: "alice"}
+	}
+}
+
+func TestLinkService_GetLink_AliasLimits(t *testing.T) {
+	shortcuts := map[string]*domain.Shortcut{
+		// Stored before aliases were checked on write
+		"ping": {ID: 1, Word: "ping", Link: "pong", User: "alice"},
+		"pong": {ID: 2, Word: "pong", Link: "ping", User: "alice"},
+		"self": {ID: 3, Word: "self", Link: "self extra", User: "alice"},
+	}
+	aliasChain(shortcuts, maxAliasHops+2)
+	service := NewLinkService(&mockShortcutRepository{shortcuts: shortcuts}, &mockQueryRepository{})
+
+	tests := []struct {
+		name      string
+		query     string
+		hops      int
+		wantErr   string
+		wantChain string
+	}{
+		{"longest chain", "chain1", maxAliasHops, "", ""},
+		{"loop", "ping", 0, "loop back through ping", "ping pong ping"},
+		{"loop through a search term", "self", 0, "loop back through self", "self self"},
+		{
+			"too deep", "chain0", 0, fmt.Sprintf("more than %d aliases", maxAliasHops),
+			"chain0 chain1 chain2 chain3 chain4 chain5 chain6 chain7 chain8 chain9 chain10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := service.ResolveDetail(context.Background(), tt.query, true, "alice")
+			if tt.wantErr != "" {
+				loop, ok := err.(AliasLoopError)
+				if !ok || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LinkService.ResolveDetail(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+				} else if chain := strings.Join(loop.Chain, " "); chain != tt.wantChain {
+					t.Errorf("LinkService.ResolveDetail(%q) chain = %q, want %q", tt.query, chain, tt.wantChain)
+				}
+				return
+			}
+			if err != nil || res.Hops != tt.hops || res.URL != "https://example.com" {
+				t.Errorf("LinkService.ResolveDetail(%q) = %+v, %v, want %d hops", tt.query, res, err, tt.hops)
+			}
+		})
+	}
+}
+
+func TestLinkService_UpdateLink_AliasLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		word    string
+		link    string
+		wantErr string
+	}{
+		{"to itself", "docs", "docs", "points to itself"},
+		{"back through another alias", "docs", "d", "would make its aliases loop"},
+		{"back through a search term", "docs", "d extra", "would make its aliases loop"},
+		{"onto a loop", "new", "ping", "would make its aliases loop"},
+		{"too deep", "new", "chain0", fmt.Sprintf("more than %d aliases", maxAliasHops)},
+		{"longest chain", "new", "chain1", ""},
+		{"to an alias", "new", "d", ""},
+		{"to a missing keyword", "new", "missing", "missing is neither a URL nor a keyword new can point to"},
+		{"to someone else's private link", "new", "secret", "secret is neither a URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcuts := map[string]*domain.Shortcut{
+				"docs":   {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+				"d":      {ID: 2, Word: "d", Link: "docs", User: "alice"},
+				"ping":   {ID: 3, Word: "ping", Link: "pong", User: "alice"},
+				"pong":   {ID: 4, Word: "pong", Link: "ping", User: "alice"},
+				"secret": {ID: 5, Word: "secret", Link: "https://hr.example.com", User: "bob", Private: true},
+			}
+			aliasChain(shortcuts, maxAliasHops+1)
+			repo := &mockShortcutRepository{shortcuts: shortcuts}
+			queryRepo := &mockQueryRepository{}
+			service := NewLinkService(repo, queryRepo)
+
+			err := service.UpdateLink(context.Background(), domain.LinkRequest{Word: tt.word, Link: tt.link}, "alice")
+			if len(queryRepo.queries) != 0 {
+				t.Errorf("LinkService.UpdateLink(%s -> %s) logged %d clicks", tt.word, tt.link, len(queryRepo.queries))
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LinkService.UpdateLink(%s -> %s) error = %v", tt.word, tt.link, err)
+				}
+				return
+			}
+			if _, ok := err.(InvalidQueryError); !ok || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LinkService.UpdateLink(%s -> %s) error = %v, want %q", tt.word, tt.link, err, tt.wantErr)
+			}
+			if len(repo.history) != 0 {
+				t.Errorf("LinkService.UpdateLink(%s -> %s) stored %d links", tt.word, tt.link, len(repo.history))
+			}
+		})
+	}
+}
+
+func TestLinkService_BulkUpdateLinks_AliasLoop(t *testing.T) {
+	repo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}
+	service := NewLinkService(repo, &mockQueryRepository{})
+
+	// wiki resolves through docs as stored, but docs then points back at wiki in the batch
+	results, err := service.BulkUpdateLinks(context.Background(), []domain.LinkRequest{
+		{Word: "wiki", Link: "docs"},
+		{Word: "docs", Link: "wiki"},
+		{Word: "w", Link: "wiki"},
+	}, "alice")
+	if err != nil {
+		t.Fatalf("LinkService.BulkUpdateLinks() error = %v", err)
+	}
+
+	want := []string{domain.BulkStatusCreated, domain.BulkStatusFailed, domain.BulkStatusCreated}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("result %d = %+v, want %s", i, result, want[i])
+		}
+	}
+	if !strings.Contains(results[1].Error, "loop") {
+		t.Errorf("result 1 error = %q, want a loop", results[1].Error)
+	}
+}
+
+func TestLinkService_RollbackLink_AliasLoop(t *testing.T) {
+	shortcuts := map[string]*domain.Shortcut{
+		"docs": {ID: 2, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"d":    {ID: 3, Word: "d", Link: "docs", User: "alice"},
+	}
+	repo := &mockShortcutRepository{
+		shortcuts: shortcuts,
+		history:   []*domain.Shortcut{{ID: 1, Word: "docs", Link: "d", User: "alice"}, shortcuts["docs"], shortcuts["d"]},
+	}
+	service := NewLinkService(repo, &mockQueryRepository{})
+
+	_, err := service.RollbackLink(context.Background(), "docs", 1, "alice")
+	if _, ok := err.(InvalidQueryError); !ok || !strings.Contains(err.Error(), "loop") {
+		t.Errorf("LinkService.RollbackLink() to an alias of d error = %v, want a loop", err)
+	}
+}
+
+// mockAliasRepository maps each alias to the word of its golink in the shortcut mock
+type mockAliasRepository struct {
+	repo    *mockShortcutRepository
+	aliases map[string]string
+}
+
+func (m *mockAliasRepository) GetTarget(ctx context.Context, alias string) (string, error) {
+	word := m.aliases[alias]
+	if _, ok := m.repo.shortcuts[word]; !ok {
+		return "", nil
+	}
+	return word, nil
+}
+
+func (m *mockAliasRepository) GetAliases(ctx context.Context, word string) ([]string, error) {
+	var aliases []string
+	for alias, target := range m.aliases {
+		if target == word {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+func (m *mockAliasRepository) GetAllAliases(ctx context.Context) (map[string]string, error) {
+	aliases := map[string]string{}
+	for alias, word := range m.aliases {
+		if _, ok := m.repo.shortcuts[word]; ok {
+			aliases[alias] = word
+		}
+	}
+	return aliases, nil
+}
+
+func (m *mockAliasRepository) Add(ctx context.Context, alias, word, user string) error {
+	m.aliases[alias] = word
+	return nil
+}
+
+func (m *mockAliasRepository) Remove(ctx context.Context, alias, word string) (int64, error) {
+	if m.aliases[alias] != word {
+		return 0, nil
+	}
+	delete(m.aliases, alias)
+	return 1, nil
+}
+
+func (m *mockAliasRepository) Rename(ctx context.Context, word, newWord, user string) error {
+	renamed := *m.repo.shortcuts[word]
+	delete(m.repo.shortcuts, word)
+	renamed.Word = newWord
+	m.repo.shortcuts[newWord] = &renamed
+
+	delete(m.aliases, newWord)
+	for alias, target := range m.aliases {
+		if target == word {
+			m.aliases[alias] = newWord
+		}
+	}
+	m.aliases[word] = newWord
+	return nil
+}
+
+// newAliasTestService returns a service over alice's docs, known as documentation, and
+// wiki, bob's private secret, known as hidden, and a trashed old link. root is an admin.
+func newAliasTestService() (*LinkService, *mockShortcutRepository, *mockQueryRepository) {
+	repo := &mockShortcutRepository{
+		shortcuts: map[string]*domain.Shortcut{
+			"docs":   {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+			"wiki":   {ID: 2, Word: "wiki", Link: "https://wiki.example.com", User: "alice"},
+			"secret": {ID: 3, Word: "secret", Link: "https://secret.example.com", User: "bob", Private: true},
+		},
+		trash: map[string]*domain.Shortcut{
+			"old": {ID: 4, Word: "old", Link: "https://old.example.com", User: "alice"},
+		},
+	}
+	aliases := &mockAliasRepository{repo: repo, aliases: map[string]string{"documentation": "docs", "hidden": "secret"}}
+	queries := &mockQueryRepository{}
+	return NewLinkService(repo, queries, WithAliases(aliases), WithAdmins([]string{"root"})), repo, queries
+}
+
+func TestLinkService_ResolveAlias(t *testing.T) {
+	service, _, queries := newAliasTestService()
+
+	res, err := service.ResolveDetail(context.Background(), "documentation", true, "alice")
+	if err != nil {
+		t.Fatalf("LinkService.ResolveDetail() error = %v", err)
+	}
+	if res.URL != "https://docs.example.com" || res.Word != "docs" || res.Alias != "documentation" {
+		t.Errorf("LinkService.ResolveDetail() = %+v, want docs through the alias documentation", res)
+	}
+	// Clicks through an alias count towards its golink
+	if len(queries.queries) != 1 || queries.queries[0].WordID != 1 {
+		t.Errorf("LinkService.ResolveDetail() logged %+v, want one click on docs", queries.queries)
+	}
+
+	// An alias of a private link behaves as if it didn't exist
+	if res, err := service.ResolveDetail(context.Background(), "hidden", false, "alice"); err == nil {
+		t.Errorf("LinkService.ResolveDetail() = %+v, want the alias of someone else's private link hidden", res)
+	}
+	if res, err := service.ResolveDetail(context.Background(), "hidden", false, "bob"); err != nil || res.URL != "https://secret.example.com" {
+		t.Errorf("LinkService.ResolveDetail() = %+v, %v, want the owner to follow the alias", res, err)
+	}
+}
+
+func TestLinkService_Aliases(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newAliasTestService()
+
+	tests := []struct {
+		name    string
+		word    string
+		alias   string
+		user    string
+		wantErr error
+	}{
+		{name: "adds an alias", word: "docs", alias: "manual", user: "alice"},
+		{name: "adding twice is fine", word: "docs", alias: "manual", user: "alice"},
+		{name: "trims the alias", word: "docs", alias: " handbook ", user: "alice"},
+		{name: "a word that is taken", word: "docs", alias: "wiki", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "an alias of another link", word: "wiki", alias: "documentation", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "an alias of a hidden link", word: "docs", alias: "hidden", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "a word in the trash", word: "docs", alias: "old", user: "alice"},
+		{name: "an invalid word", word: "docs", alias: "manual/", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "someone else's link", word: "docs", alias: "guide", user: "carol", wantErr: ForbiddenError{}},
+		{name: "someone else's private link", word: "secret", alias: "guide", user: "alice", wantErr: NotFoundError{}},
+		{name: "missing link", word: "missing", alias: "guide", user: "alice", wantErr: NotFoundError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.AddAlias(ctx, tt.word, tt.alias, tt.user)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.AddAlias() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("LinkService.AddAlias() error = %v", err)
+			}
+		})
+	}
+
+	aliases, err := service.ListAliases(ctx, "docs", "carol")
+	if want := "documentation handbook manual old"; err != nil || strings.Join(aliases, " ") != want {
+		t.Errorf("LinkService.ListAliases() = %v, %v, want %s", aliases, err, want)
+	}
+	if aliases, err := service.ListAliases(ctx, "wiki", "alice"); err != nil || aliases == nil || len(aliases) != 0 {
+		t.Errorf("LinkService.ListAliases() = %v, %v, want no aliases", aliases, err)
+	}
+	if _, err := service.ListAliases(ctx, "secret", "alice"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("LinkService.ListAliases() of someone else's private link error = %v, want NotFoundError", err)
+	}
+
+	// An alias is a word taken like any other
+	err = service.UpdateLink(ctx, domain.LinkRequest{Word: "manual", Link: "https://manual.example.com"}, "alice")
+	if err == nil || !strings.Contains(err.Error(), "alias of docs") {
+		t.Errorf("LinkService.UpdateLink() of an alias error = %v, want it refused", err)
+	}
+	if _, err := service.RestoreLink(ctx, "old", "alice"); err == nil || !strings.Contains(err.Error(), "alias of docs") {
+		t.Errorf("LinkService.RestoreLink() over an alias error = %v, want it refused", err)
+	}
+
+	if err := service.RemoveAlias(ctx, "docs", "manual", "carol"); !sameErrorType(err, ForbiddenError{}) {
+		t.Errorf("LinkService.RemoveAlias() by someone else error = %v, want ForbiddenError", err)
+	}
+	if err := service.RemoveAlias(ctx, "docs", "manual", "alice"); err != nil {
+		t.Errorf("LinkService.RemoveAlias() error = %v", err)
+	}
+	if err := service.RemoveAlias(ctx, "wiki", "handbook", "alice"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("LinkService.RemoveAlias() of another link's alias error = %v, want NotFoundError", err)
+	}
+	if err := service.UpdateLink(ctx, domain.LinkRequest{Word: "manual", Link: "https://manual.example.com"}, "alice"); err != nil {
+		t.Errorf("LinkService.UpdateLink() of a removed alias error = %v", err)
+	}
+}
+
+func TestLinkService_RenameLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		word    string
+		newWord string
+		user    string
+		wantErr error
+	}{
+		{name: "renames", word: "docs", newWord: "guide", user: "alice"},
+		{name: "by an admin", word: "docs", newWord: "guide", user: "root"},
+		{name: "to its own alias", word: "docs", newWord: "documentation", user: "alice"},
+		{name: "to its own word", word: "docs", newWord: "docs", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "to a taken word", word: "docs", newWord: "wiki", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "to another link's alias", word: "wiki", newWord: "documentation", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "to a word in the trash", word: "docs", newWord: "old", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "to no word", word: "docs", newWord: " ", user: "alice", wantErr: InvalidQueryError{}},
+		{name: "someone else's link", word: "docs", newWord: "guide", user: "carol", wantErr: ForbiddenError{}},
+		{name: "missing link", word: "missing", newWord: "guide", user: "alice", wantErr: NotFoundError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, _ := newAliasTestService()
+
+			shortcut, err := service.RenameLink(context.Background(), tt.word, domain.RenameRequest{Word: tt.newWord}, tt.user)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.RenameLink() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.RenameLink() error = %v", err)
+			}
+			if shortcut.Word != tt.newWord || shortcut.Link != "https://docs.example.com" || repo.shortcuts[tt.word] != nil {
+				t.Errorf("LinkService.RenameLink() = %+v, want docs moved to %s", shortcut, tt.newWord)
+			}
+			// The rename is stored as a new version by whoever renamed it
+			if latest := repo.history[len(repo.history)-1]; latest.Word != tt.newWord || latest.UpdatedBy != tt.user {
+				t.Errorf("latest version = %+v, want %s renamed by %s", latest, tt.newWord, tt.user)
+			}
+
+			// The old word keeps working as an alias
+			res, err := service.ResolveDetail(context.Background(), tt.word, false, tt.user)
+			if err != nil || res.Word != tt.newWord || res.Alias != tt.word {
+				t.Errorf("LinkService.ResolveDetail() of the old word = %+v, %v, want the alias of %s", res, err, tt.newWord)
+			}
+			aliases, _ := service.ListAliases(context.Background(), tt.newWord, tt.user)
+			for _, alias := range aliases {
+				if alias == tt.newWord {
+					t.Errorf("LinkService.ListAliases() = %v, want the new word dropped", aliases)
+				}
+			}
+		})
+	}
+}
+
+func TestLinkService_RenameLink_Cache(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}
+	shared := &mockSharedCache{shortcuts: map[string]*domain.Shortcut{}}
+	cache := NewShortcutCache(repo, 10, time.Hour)
+	cache.Share(shared)
+	aliases := &mockAliasRepository{repo: repo, aliases: map[string]string{}}
+	service := NewLinkService(cache, &mockQueryRepository{}, WithAliases(aliases))
+
+	// Cache docs, and guide as missing
+	for _, word := range []string{"docs", "guide"} {
+		if _, err := cache.GetByWord(ctx, word); err != nil {
+			t.Fatalf("ShortcutCache.GetByWord() error = %v", err)
+		}
+	}
+
+	shortcut, err := service.RenameLink(ctx, "docs", domain.RenameRequest{Word: "guide"}, "alice")
+	if err != nil || shortcut == nil || shortcut.Word != "guide" {
+		t.Fatalf("LinkService.RenameLink() = %+v, %v, want docs moved to guide", shortcut, err)
+	}
+	if len(shared.invalidated) == 0 || strings.Join(shared.invalidated[0], ",") != "docs,guide" {
+		t.Errorf("shared cache invalidations = %v, want docs and guide", shared.invalidated)
+	}
+
+	// The old word is only an alias now, so it can't be edited as a golink of its own
+	res, err := service.ResolveDetail(ctx, "docs", false, "alice")
+	if err != nil || res.Word != "guide" || res.Alias != "docs" {
+		t.Errorf("LinkService.ResolveDetail() of the old word = %+v, %v, want the alias of guide", res, err)
+	}
+	if _, err := service.ArchiveLink(ctx, "docs", "alice"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("LinkService.ArchiveLink() of the old word error = %v, want NotFoundError", err)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+type mockAPIKeyRepository struct {
+	keys   []*domain.APIKey
+	hashes map[string]*domain.APIKey
+}
+
+func newMockAPIKeyRepository() *mockAPIKeyRepository {
+	return &mockAPIKeyRepository{hashes: map[string]*domain.APIKey{}}
+}
+
+func (m *mockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey, keyHash string) error {
+	key.ID = len(m.keys) + 1
+	stored := *key
+	m.keys = append(m.keys, &stored)
+	m.hashes[keyHash] = &stored
+	return nil
+}
+
+func (m *mockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	return m.hashes[keyHash], nil
+}
+
+func (m *mockAPIKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		keys = append(keys, *m.keys[i])
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepository) Revoke(ctx context.Context, id int) (int64, error) {
+	for _, key := range m.keys {
+		if key.ID == id && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func TestAPIKeyService_CreateKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       domain.APIKeyRequest
+		requester string
+		wantUser  string
+		wantErr   error
+	}{
+		{name: "for requester", req: domain.APIKeyRequest{Name: "ci"}, requester: "admin", wantUser: "admin"},
+		{name: "for another user", req: domain.APIKeyRequest{Name: " ci ", User: " alice "}, requester: "admin", wantUser: "alice"},
+		{name: "not an admin", req: domain.APIKeyRequest{Name: "ci"}, requester: "alice", wantErr: ForbiddenError{}},
+		{name: "missing name", req: domain.APIKeyRequest{Name: " "}, requester: "admin", wantErr: InvalidQueryError{}},
+		{name: "long name", req: domain.APIKeyRequest{Name: strings.Repeat("x", 65)}, requester: "admin", wantErr: InvalidQueryError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockAPIKeyRepository()
+			service := NewAPIKeyService(repo, adminSet([]string{"admin"}))
+
+			created, err := service.CreateKey(context.Background(), tt.req, tt.requester)
+			if tt.wantErr != nil {
+				if err == nil || !sameErrorType(err, tt.wantErr) {
+					t.Fatalf("APIKeyService.CreateKey() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("APIKeyService.CreateKey() error = %v", err)
+			}
+
+			if created.User != tt.wantUser || created.Name != "ci" {
+				t.Errorf("APIKeyService.CreateKey() = %+v, want user %s", created.APIKey, tt.wantUser)
+			}
+			if !strings.HasPrefix(created.Key, apiKeyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) {
+				t.Errorf("APIKeyService.CreateKey() key = %q, prefix = %q", created.Key, created.Prefix)
+			}
+			if _, stored := repo.hashes[created.Key]; stored {
+				t.Error("APIKeyService.CreateKey() stored the plaintext key")
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	repo := newMockAPIKeyRepository()
+	service := NewAPIKeyService(repo, adminSet([]string{"admin"}))
+	ctx := context.Background()
+
+	created, err := service.CreateKey(ctx, domain.APIKeyRequest{Name: "ci", User: "alice"}, "admin")
+	if err != nil {
+		t.Fatalf("APIKeyService.CreateKey() error = %v", err)
+	}
+	other, err := service.CreateKey(ctx, domain.APIKeyRequest{Name: "bot", User: "bob"}, "admin")
+	if err != nil {
+		t.Fatalf("APIKeyService.CreateKey() error = %v", err)
+	}
+	if created.Key == other.Key {
+		t.Fatal("APIKeyService.CreateKey() minted the same key twice")
+	}
+
+	key, err := service.Authenticate(ctx, created.Key)
+	if err != nil || key.User != "alice" {
+		t.Fatalf("APIKeyService.Authenticate() = %+v, %v, want alice", key, err)
+	}
+
+	for _, token := range []string{"", "glk_unknown", "not-a-key", created.Key + "x"} {
+		if _, err := service.Authenticate(ctx, token); !sameErrorType(err, UnauthorizedError{}) {
+			t.Errorf("APIKeyService.Authenticate(%q) error = %v, want UnauthorizedError", token, err)
+		}
+	}
+
+	if err := service.RevokeKey(ctx, created.ID, "alice"); !sameErrorType(err, ForbiddenError{}) {
+		t.Errorf("APIKeyService.RevokeKey() by non-admin error = %v, want ForbiddenError", err)
+	}
+	if err := service.RevokeKey(ctx, created.ID, "admin"); err != nil {
+		t.Fatalf("APIKeyService.RevokeKey() error = %v", err)
+	}
+	if err := service.RevokeKey(ctx, created.ID, "admin"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("APIKeyService.RevokeKey() twice error = %v, want NotFoundError", err)
+	}
+	if _, err := service.Authenticate(ctx, created.Key); !sameErrorType(err, UnauthorizedError{}) {
+		t.Errorf("APIKeyService.Authenticate() after revoke error = %v, want UnauthorizedError", err)
+	}
+	if _, err := service.Authenticate(ctx, other.Key); err != nil {
+		t.Errorf("APIKeyService.Authenticate() of unrevoked key error = %v", err)
+	}
+}
+
+func TestAPIKeyService_ListKeys(t *testing.T) {
+	repo := newMockAPIKeyRepository()
+	service := NewAPIKeyService(repo, adminSet([]string{"admin"}))
+	ctx := context.Background()
+
+	keys, err := service.ListKeys(ctx, "admin")
+	if err != nil || keys == nil || len(keys) != 0 {
+		t.Errorf("APIKeyService.ListKeys() on no keys = %v, %v, want empty list", keys, err)
+	}
+
+	if _, err := service.CreateKey(ctx, domain.APIKeyRequest{Name: "ci"}, "admin"); err != nil {
+		t.Fatalf("APIKeyService.CreateKey() error = %v", err)
+	}
+	if keys, err := service.ListKeys(ctx, "admin"); err != nil || len(keys) != 1 {
+		t.Errorf("APIKeyService.ListKeys() = %v, %v, want one key", keys, err)
+	}
+	if _, err := service.ListKeys(ctx, "alice"); !sameErrorType(err, ForbiddenError{}) {
+		t.Errorf("APIKeyService.ListKeys() by non-admin error = %v, want ForbiddenError", err)
+	}
+}
+
+// sameErrorType reports whether err has the same concrete type as want
+func sameErrorType(err, want error) bool {
+	return err != nil && reflect.TypeOf(err) == reflect.TypeOf(want)
+}
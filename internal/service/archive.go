@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// ArchiveLink retires a golink without deleting it: it stays listed with its history and
+// stats, but following it explains that it was archived instead of redirecting. Archiving
+// is stored as a new version, so the link's history records who archived it and when.
+func (s *LinkService) ArchiveLink(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	return s.setArchived(ctx, word, true, userID)
+}
+
+// UnarchiveLink makes an archived golink resolve again
+func (s *LinkService) UnarchiveLink(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	return s.setArchived(ctx, word, false, userID)
+}
+
+// setArchived stores a new version of a golink userID may change, archived or not
+func (s *LinkService) setArchived(ctx context.Context, word string, archived bool, userID string) (*domain.Shortcut, error) {
+	word = NormalizeWord(word)
+
+	action := "unarchive"
+	if archived {
+		action = "archive"
+	}
+	current, err := s.modifiableShortcut(ctx, word, userID, action)
+	if err != nil {
+		return nil, err
+	}
+	if current.Archived == archived {
+		if archived {
+			return nil, InvalidQueryError{Message: fmt.Sprintf("%s is already archived", word)}
+		}
+		return nil, InvalidQueryError{Message: fmt.Sprintf("%s is not archived", word)}
+	}
+
+	shortcut := &domain.Shortcut{
+		Word:        current.Word,
+		Link:        current.Link,
+		User:        current.User,
+		Icon:        current.Icon,
+		Description: current.Description,
+		UpdatedBy:   userID,
+		Private:     current.Private,
+		Prefix:      current.Prefix,
+		Archived:    archived,
+		Metadata:    current.Metadata,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.shortcutRepo.Create(ctx, shortcut); err != nil {
+		return nil, fmt.Errorf("failed to create shortcut: %w", err)
+	}
+	s.keywords.Invalidate()
+	s.notify(userID, current, shortcut)
+
+	return shortcut, nil
+}
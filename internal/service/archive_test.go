@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_ArchiveLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		word    string
+		userID  string
+		wantErr error
+	}{
+		{name: "owner archives", word: "legacy", userID: "alice"},
+		{name: "admin archives anyone's link", word: "legacy", userID: "root"},
+		{name: "other user", word: "legacy", userID: "bob", wantErr: ForbiddenError{}},
+		{name: "someone else's private link", word: "payroll", userID: "bob", wantErr: NotFoundError{}},
+		{name: "missing link", word: "missing", userID: "alice", wantErr: NotFoundError{}},
+		{name: "already archived", word: "old", userID: "alice", wantErr: InvalidQueryError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"legacy": {
+					ID: 1, Word: "legacy", Link: "https://legacy.example.com", User: "alice", Description: "Old wiki",
+					Metadata: map[string]interface{}{"team": "docs"},
+				},
+				"payroll": {ID: 2, Word: "payroll", Link: "https://payroll.example.com", User: "alice", Private: true},
+				"old":     {ID: 3, Word: "old", Link: "https://old.example.com", User: "alice", Archived: true},
+			}}
+			service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAdmins([]string{"root"}))
+
+			shortcut, err := service.ArchiveLink(context.Background(), tt.word, tt.userID)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.ArchiveLink() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.ArchiveLink() error = %v", err)
+			}
+
+			// Archiving is a new version keeping everything but the flag
+			want := domain.Shortcut{
+				Word: "legacy", Link: "https://legacy.example.com", User: "alice", Description: "Old wiki",
+				UpdatedBy: tt.userID, Archived: true, Metadata: map[string]interface{}{"team": "docs"},
+			}
+			got := *shortcut
+			got.ID, got.CreatedAt = 0, want.CreatedAt
+			if !reflect.DeepEqual(got, want) || len(shortcutRepo.history) != 1 {
+				t.Errorf("LinkService.ArchiveLink() = %+v, want %+v stored as the newest version", got, want)
+			}
+		})
+	}
+}
+
+func TestLinkService_ResolveArchived(t *testing.T) {
+	ctx := context.Background()
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"legacy": {ID: 1, Word: "legacy", Link: "https://legacy.example.com", User: "alice"},
+		"old":    {ID: 2, Word: "old", Link: "legacy", User: "alice"},
+		"repo":   {ID: 3, Word: "repo", Link: "https://github.com/org/repo", User: "alice", Prefix: true},
+	}}
+	queries := &mockQueryRepository{}
+	service := NewLinkService(shortcutRepo, queries)
+
+	for _, word := range []string{"legacy", "repo"} {
+		if _, err := service.ArchiveLink(ctx, word, "alice"); err != nil {
+			t.Fatalf("LinkService.ArchiveLink() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantWord string
+	}{
+		{"archived link", "legacy", "legacy"},
+		{"with a search term", "legacy onboarding", "legacy"},
+		{"alias to an archived link", "old", "legacy"},
+		{"path below an archived prefix link", "repo/issues/123", "repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := service.ResolveDetail(ctx, tt.query, true, "bob")
+			var archived ArchivedError
+			if !errors.As(err, &archived) || archived.Shortcut.Word != tt.wantWord || archived.Shortcut.UpdatedBy != "alice" {
+				t.Errorf("LinkService.ResolveDetail(%q) = %+v, %v, want %s archived", tt.query, res, err, tt.wantWord)
+			}
+		})
+	}
+
+	// Following an archived link goes nowhere, so it isn't counted as a click
+	if len(queries.queries) != 1 || queries.queries[0].WordID != 2 {
+		t.Errorf("LinkService.ResolveDetail() logged %+v, want only the click on the alias", queries.queries)
+	}
+
+	// Archived links stay listed with their history
+	detail, err := service.GetLinkDetail(ctx, "legacy", "bob")
+	if err != nil || !detail.Archived || detail.Link != "https://legacy.example.com" {
+		t.Errorf("LinkService.GetLinkDetail() = %+v, %v, want legacy archived", detail, err)
+	}
+
+	// New aliases can't point at an archived link
+	err = service.UpdateLink(ctx, domain.LinkRequest{Word: "older", Link: "legacy"}, "alice")
+	if !sameErrorType(err, InvalidQueryError{}) {
+		t.Errorf("LinkService.UpdateLink() of an alias to an archived link error = %v, want InvalidQueryError", err)
+	}
+
+	if _, err := service.UnarchiveLink(ctx, "legacy", "alice"); err != nil {
+		t.Fatalf("LinkService.UnarchiveLink() error = %v", err)
+	}
+	if res, err := service.ResolveDetail(ctx, "old", false, "bob"); err != nil || res.URL != "https://legacy.example.com" {
+		t.Errorf("LinkService.ResolveDetail() after unarchiving = %+v, %v, want legacy", res, err)
+	}
+	if _, err := service.UnarchiveLink(ctx, "legacy", "alice"); !sameErrorType(err, InvalidQueryError{}) {
+		t.Errorf("LinkService.UnarchiveLink() of a link that isn't archived error = %v, want InvalidQueryError", err)
+	}
+}
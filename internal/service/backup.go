@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// Snapshotter copies the whole database to and from snapshot files
+type Snapshotter interface {
+	Snapshot(ctx context.Context, path string) error
+	Restore(ctx context.Context, path string) error
+}
+
+// BackupStorage keeps database snapshots somewhere safe, such as a directory or an
+// object store. Open returns nil for a backup that doesn't exist.
+type BackupStorage interface {
+	Save(ctx context.Context, name string, r io.Reader) (*domain.Backup, error)
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	List(ctx context.Context) ([]domain.Backup, error)
+}
+
+// backupNamePattern is what backup names may look like, keeping them inside the storage
+var backupNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*\.db$`)
+
+// BackupService snapshots the database into backup storage and restores it from there
+type BackupService struct {
+	snapshots Snapshotter
+	storage   BackupStorage
+	now       func() time.Time
+}
+
+// NewBackupService creates a new backup service. Either argument may be nil when the
+// storage driver can't be snapshotted or no backup storage is configured, in which case
+// every call fails with an InvalidQueryError saying so.
+func NewBackupService(snapshots Snapshotter, storage BackupStorage) *BackupService {
+	return &BackupService{snapshots: snapshots, storage: storage, now: time.Now}
+}
+
+// ListBackups returns the stored backups, newest first
+func (s *BackupService) ListBackups(ctx context.Context) ([]domain.Backup, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+
+	backups, err := s.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if backups == nil {
+		backups = []domain.Backup{}
+	}
+
+	return backups, nil
+}
+
+// CreateBackup snapshots the database and saves it under a name from the current time
+func (s *BackupService) CreateBackup(ctx context.Context) (*domain.Backup, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+
+	tmp, err := tempBackupPath()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+
+	if err := s.snapshots.Snapshot(ctx, tmp); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer f.Close()
+
+	name := "golinks-" + s.now().UTC().Format("20060102-150405.000") + ".db"
+	return s.storage.Save(ctx, name, f)
+}
+
+// RestoreBackup replaces the database with the named backup
+func (s *BackupService) RestoreBackup(ctx context.Context, req domain.RestoreRequest) (*domain.Backup, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+
+	if !backupNamePattern.MatchString(req.Name) || strings.Contains(req.Name, "..") {
+		return nil, InvalidQueryError{Message: "Backup name must be a file name ending in .db"}
+	}
+
+	backup, err := s.findBackup(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.storage.Open(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, NotFoundError{Message: "Backup " + req.Name + " not found"}
+	}
+	defer r.Close()
+
+	// Snapshots are restored from a local file, wherever the backup is stored
+	tmp, err := tempBackupPath()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if err := s.snapshots.Restore(ctx, tmp); err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// findBackup returns the stored backup with the given name
+func (s *BackupService) findBackup(ctx context.Context, name string) (*domain.Backup, error) {
+	backups, err := s.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range backups {
+		if backups[i].Name == name {
+			return &backups[i], nil
+		}
+	}
+
+	return nil, NotFoundError{Message: "Backup " + name + " not found"}
+}
+
+// check reports why backups can't be taken, if they can't
+func (s *BackupService) check() error {
+	if s.snapshots == nil {
+		return InvalidQueryError{Message: "This storage driver doesn't support backups"}
+	}
+	if s.storage == nil {
+		return InvalidQueryError{Message: "Backups are not configured; set BACKUP_DIR"}
+	}
+	return nil
+}
+
+// tempBackupPath returns a path for a snapshot file that doesn't exist yet
+func tempBackupPath() (string, error) {
+	f, err := os.CreateTemp("", "golinks-snapshot-*.db")
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	name := f.Name()
+	f.Close()
+
+	// SQLite's backup writes a fresh database, so leave it nothing to overwrite
+	if err := os.Remove(name); err != nil {
+		return "", fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+
+	return name, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// mockSnapshotter treats the database as a string written to and read from snapshot files
+type mockSnapshotter struct {
+	data string
+}
+
+func (m *mockSnapshotter) Snapshot(ctx context.Context, path string) error {
+	return os.WriteFile(path, []byte(m.data), 0o600)
+}
+
+func (m *mockSnapshotter) Restore(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	m.data = string(data)
+	return nil
+}
+
+// mockBackupStorage keeps backups in memory
+type mockBackupStorage struct {
+	files map[string][]byte
+	times map[string]time.Time
+}
+
+func newMockBackupStorage() *mockBackupStorage {
+	return &mockBackupStorage{files: map[string][]byte{}, times: map[string]time.Time{}}
+}
+
+func (m *mockBackupStorage) Save(ctx context.Context, name string, r io.Reader) (*domain.Backup, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m.files[name] = data
+	m.times[name] = time.Now()
+	return &domain.Backup{Name: name, Size: int64(len(data)), CreatedAt: m.times[name]}, nil
+}
+
+func (m *mockBackupStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	data, ok := m.files[name]
+	if !ok {
+		return nil, nil
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *mockBackupStorage) List(ctx context.Context) ([]domain.Backup, error) {
+	var backups []domain.Backup
+	for name, data := range m.files {
+		backups = append(backups, domain.Backup{Name: name, Size: int64(len(data)), CreatedAt: m.times[name]})
+	}
+	return backups, nil
+}
+
+func (m *mockBackupStorage) Delete(ctx context.Context, name string) error {
+	delete(m.files, name)
+	delete(m.times, name)
+	return nil
+}
+
+func TestBackupService_CreateAndRestore(t *testing.T) {
+	snapshots := &mockSnapshotter{data: "monday"}
+	storage := newMockBackupStorage()
+	s := NewBackupService(snapshots, storage)
+	s.now = func() time.Time { return time.Date(2024, 3, 4, 5, 6, 7, 8000000, time.UTC) }
+	ctx := context.Background()
+
+	backup, err := s.CreateBackup(ctx)
+	if err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+	if backup.Name != "golinks-20240304-050607.008.db" || backup.Size != int64(len("monday")) {
+		t.Errorf("CreateBackup() = %+v, want golinks-20240304-050607.008.db of 6 bytes", backup)
+	}
+
+	snapshots.data = "tuesday"
+	restored, err := s.RestoreBackup(ctx, domain.RestoreRequest{Name: backup.Name})
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if restored.Name != backup.Name {
+		t.Errorf("RestoreBackup() = %+v, want %s", restored, backup.Name)
+	}
+	if snapshots.data != "monday" {
+		t.Errorf("database after RestoreBackup() = %q, want monday", snapshots.data)
+	}
+
+	backups, err := s.ListBackups(ctx)
+	if err != nil || len(backups) != 1 {
+		t.Errorf("ListBackups() = %+v, %v, want the one backup", backups, err)
+	}
+}
+
+func TestBackupService_RestoreBackup_Errors(t *testing.T) {
+	storage := newMockBackupStorage()
+	storage.files["golinks-20240101-000000.000.db"] = []byte("january")
+
+	tests := []struct {
+		name    string
+		backup  string
+		wantErr error
+	}{
+		{"parent directory", "../golinks.db", InvalidQueryError{}},
+		{"absolute path", "/etc/passwd.db", InvalidQueryError{}},
+		{"not a database file", "golinks.txt", InvalidQueryError{}},
+		{"empty", "", InvalidQueryError{}},
+		{"missing", "golinks-20990101-000000.000.db", NotFoundError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshots := &mockSnapshotter{data: "now"}
+			s := NewBackupService(snapshots, storage)
+			_, err := s.RestoreBackup(context.Background(), domain.RestoreRequest{Name: tt.backup})
+			if !sameErrorType(err, tt.wantErr) {
+				t.Errorf("RestoreBackup(%q) error = %v, want %T", tt.backup, err, tt.wantErr)
+			}
+			if snapshots.data != "now" {
+				t.Errorf("RestoreBackup(%q) changed the database to %q", tt.backup, snapshots.data)
+			}
+		})
+	}
+}
+
+func TestBackupService_NotConfigured(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshots Snapshotter
+		storage   BackupStorage
+	}{
+		{"driver without snapshots", nil, newMockBackupStorage()},
+		{"no backup storage", &mockSnapshotter{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewBackupService(tt.snapshots, tt.storage)
+			ctx := context.Background()
+
+			if _, err := s.CreateBackup(ctx); !sameErrorType(err, InvalidQueryError{}) {
+				t.Errorf("CreateBackup() error = %v, want InvalidQueryError", err)
+			}
+			if _, err := s.ListBackups(ctx); !sameErrorType(err, InvalidQueryError{}) {
+				t.Errorf("ListBackups() error = %v, want InvalidQueryError", err)
+			}
+			if _, err := s.RestoreBackup(ctx, domain.RestoreRequest{Name: "golinks.db"}); !sameErrorType(err, InvalidQueryError{}) {
+				t.Errorf("RestoreBackup() error = %v, want InvalidQueryError", err)
+			}
+		})
+	}
+}
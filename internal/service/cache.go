@@ -0,0 +1,293 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// SharedCache is a cache of shortcuts by word shared by every server using the same
+// database, such as Redis. Invalidate deletes words from it, or every word if none are
+// given, and tells every server subscribed to it to drop them from their own caches.
+type SharedCache interface {
+	GetShortcut(ctx context.Context, word string) (shortcut *domain.Shortcut, found bool, err error)
+	SetShortcut(ctx context.Context, word string, shortcut *domain.Shortcut, ttl time.Duration) error
+	Invalidate(ctx context.Context, words ...string) error
+	Subscribe(ctx context.Context, drop func(words ...string)) error
+}
+
+// ShortcutCache is a ShortcutRepository that keeps the most recently resolved words in
+// memory, so hot golinks redirect without a database query. Words written through it are
+// dropped from the cache; entries also expire after a TTL, which bounds how stale they can
+// get when other servers write to the same database. With a SharedCache, lookups that miss
+// memory try it before the database, and writes are broadcast to the other servers.
+type ShortcutCache struct {
+	ShortcutRepository
+
+	size   int
+	ttl    time.Duration
+	now    func() time.Time
+	shared SharedCache
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // most recently used first
+
+	// generation counts invalidations, so a lookup that raced one doesn't cache what it read
+	generation uint64
+
+	hits   int64
+	misses int64
+
+	// sharedHits counts the misses the shared cache answered, and sharedErrors the calls
+	// to it that failed and fell back to the database
+	sharedHits   int64
+	sharedErrors int64
+}
+
+// cacheEntry is a cached GetByWord result; shortcut is nil for a word that doesn't exist
+type cacheEntry struct {
+	word     string
+	shortcut *domain.Shortcut
+	expires  time.Time
+}
+
+// NewShortcutCache caches up to size words looked up through repo for ttl each
+func NewShortcutCache(repo ShortcutRepository, size int, ttl time.Duration) *ShortcutCache {
+	return &ShortcutCache{
+		ShortcutRepository: repo,
+		size:               size,
+		ttl:                ttl,
+		now:                time.Now,
+		entries:            map[string]*list.Element{},
+		order:              list.New(),
+	}
+}
+
+// Share puts shared in front of the repository, behind the in-memory cache. Call Listen
+// to drop the words other servers change.
+func (c *ShortcutCache) Share(shared SharedCache) {
+	c.shared = shared
+}
+
+// Listen drops the words other servers invalidate in the shared cache from memory until
+// ctx is done
+func (c *ShortcutCache) Listen(ctx context.Context) error {
+	if c.shared == nil {
+		return nil
+	}
+	return c.shared.Subscribe(ctx, c.drop)
+}
+
+// GetByWord returns the cached shortcut for word, reading it through from the shared cache
+// or the repository when it isn't cached or has expired
+func (c *ShortcutCache) GetByWord(ctx context.Context, word string) (*domain.Shortcut, error) {
+	c.mu.Lock()
+	if element, ok := c.entries[word]; ok {
+		entry := element.Value.(*cacheEntry)
+		if c.now().Before(entry.expires) {
+			c.order.MoveToFront(element)
+			c.hits++
+			c.mu.Unlock()
+			return copyShortcut(entry.shortcut), nil
+		}
+		c.remove(element)
+	}
+	c.misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	if c.shared != nil {
+		shortcut, found, err := c.shared.GetShortcut(ctx, word)
+		if err == nil && found {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.sharedHits++
+			if generation == c.generation {
+				c.add(word, copyShortcut(shortcut))
+			}
+			return shortcut, nil
+		}
+		if err != nil {
+			c.countSharedError()
+		}
+	}
+
+	shortcut, err := c.ShortcutRepository.GetByWord(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	current := generation == c.generation
+	if current {
+		c.add(word, copyShortcut(shortcut))
+	}
+	c.mu.Unlock()
+
+	if current && c.shared != nil {
+		if err := c.shared.SetShortcut(ctx, word, shortcut, c.ttl); err != nil {
+			c.countSharedError()
+		}
+	}
+
+	return shortcut, nil
+}
+
+// Create creates the shortcut and drops its word from the cache
+func (c *ShortcutCache) Create(ctx context.Context, shortcut *domain.Shortcut) error {
+	defer c.Invalidate(ctx, shortcut.Word)
+	return c.ShortcutRepository.Create(ctx, shortcut)
+}
+
+// CreateBatch creates the shortcuts and drops their words from the cache
+func (c *ShortcutCache) CreateBatch(ctx context.Context, shortcuts []*domain.Shortcut) error {
+	words := make([]string, len(shortcuts))
+	for i, shortcut := range shortcuts {
+		words[i] = shortcut.Word
+	}
+	defer c.Invalidate(ctx, words...)
+	return c.ShortcutRepository.CreateBatch(ctx, shortcuts)
+}
+
+// DeleteByWord trashes word and drops it from the cache
+func (c *ShortcutCache) DeleteByWord(ctx context.Context, word string) (int64, error) {
+	defer c.Invalidate(ctx, word)
+	return c.ShortcutRepository.DeleteByWord(ctx, word)
+}
+
+// RestoreByWord restores word from the trash and drops it from the cache
+func (c *ShortcutCache) RestoreByWord(ctx context.Context, word string) (int64, error) {
+	defer c.Invalidate(ctx, word)
+	return c.ShortcutRepository.RestoreByWord(ctx, word)
+}
+
+// PurgeByWord purges word from the trash and drops it from the cache
+func (c *ShortcutCache) PurgeByWord(ctx context.Context, word string) (int64, error) {
+	defer c.Invalidate(ctx, word)
+	return c.ShortcutRepository.PurgeByWord(ctx, word)
+}
+
+// Invalidate drops words from the cache, or every word if none are given, on this server
+// and any others sharing its shared cache
+func (c *ShortcutCache) Invalidate(ctx context.Context, words ...string) {
+	c.drop(words...)
+	if c.shared != nil {
+		if err := c.shared.Invalidate(ctx, words...); err != nil {
+			c.countSharedError()
+		}
+	}
+}
+
+// drop removes words from memory, or every word if none are given
+func (c *ShortcutCache) drop(words ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if len(words) == 0 {
+		c.entries = map[string]*list.Element{}
+		c.order.Init()
+		return
+	}
+	for _, word := range words {
+		if element, ok := c.entries[word]; ok {
+			c.remove(element)
+		}
+	}
+}
+
+// Stats reports how full the cache is and how often lookups hit it
+func (c *ShortcutCache) Stats() domain.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return domain.CacheStats{
+		Name:         "links",
+		Entries:      c.order.Len(),
+		Capacity:     c.size,
+		Hits:         c.hits,
+		Misses:       c.misses,
+		Shared:       c.shared != nil,
+		SharedHits:   c.sharedHits,
+		SharedErrors: c.sharedErrors,
+	}
+}
+
+func (c *ShortcutCache) countSharedError() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sharedErrors++
+}
+
+// FlushOnRestore wraps snapshots so that restoring a backup empties the cache, as it
+// replaces every link. A nil snapshots stays nil.
+func (c *ShortcutCache) FlushOnRestore(snapshots Snapshotter) Snapshotter {
+	if snapshots == nil {
+		return nil
+	}
+	return flushingSnapshotter{Snapshotter: snapshots, cache: c}
+}
+
+// add caches shortcut under word, evicting the least recently used word when full
+func (c *ShortcutCache) add(word string, shortcut *domain.Shortcut) {
+	entry := &cacheEntry{word: word, shortcut: shortcut, expires: c.now().Add(c.ttl)}
+	if element, ok := c.entries[word]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[word] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *ShortcutCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).word)
+}
+
+// copyShortcut keeps callers from changing a cached shortcut
+func copyShortcut(shortcut *domain.Shortcut) *domain.Shortcut {
+	if shortcut == nil {
+		return nil
+	}
+	copied := *shortcut
+	return &copied
+}
+
+// flushingSnapshotter empties a ShortcutCache after every restore
+type flushingSnapshotter struct {
+	Snapshotter
+	cache *ShortcutCache
+}
+
+func (s flushingSnapshotter) Restore(ctx context.Context, path string) error {
+	defer s.cache.Invalidate(ctx)
+	return s.Snapshotter.Restore(ctx, path)
+}
+
+// invalidateWords drops words from the shortcut cache LinkService reads links through, if
+// there is one, after they were changed without going through it
+func (s *LinkService) invalidateWords(ctx context.Context, words ...string) {
+	if cache, ok := s.shortcutRepo.(*ShortcutCache); ok {
+		cache.Invalidate(ctx, words...)
+	}
+}
+
+// CacheStats reports the caches LinkService reads links through, if there are any
+func (s *LinkService) CacheStats() []domain.CacheStats {
+	stats := []domain.CacheStats{}
+	if cache, ok := s.shortcutRepo.(*ShortcutCache); ok {
+		stats = append(stats, cache.Stats())
+	}
+	if s.keywords != nil {
+		stats = append(stats, s.keywords.Stats())
+	}
+	return stats
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// countingShortcutRepository counts the lookups that reach the repository
+type countingShortcutRepository struct {
+	*mockShortcutRepository
+	lookups int
+}
+
+func (r *countingShortcutRepository) GetByWord(ctx context.Context, word string) (*domain.Shortcut, error) {
+	r.lookups++
+	return r.mockShortcutRepository.GetByWord(ctx, word)
+}
+
+func TestShortcutCache_GetByWord(t *testing.T) {
+	repo := &countingShortcutRepository{mockShortcutRepository: &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs":   {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"github": {ID: 2, Word: "github", Link: "https://github.com", User: "alice"},
+		"wiki":   {ID: 3, Word: "wiki", Link: "https://wiki.example.com", User: "alice"},
+	}}}
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewShortcutCache(repo, 2, time.Minute)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		word        string
+		advance     time.Duration
+		wantLink    string
+		wantLookups int
+	}{
+		{name: "miss", word: "docs", wantLink: "https://docs.example.com", wantLookups: 1},
+		{name: "hit", word: "docs", wantLink: "https://docs.example.com", wantLookups: 1},
+		{name: "missing word", word: "nope", wantLookups: 2},
+		{name: "missing word cached", word: "nope", wantLookups: 2},
+		{name: "evicts the least recently used", word: "github", wantLink: "https://github.com", wantLookups: 3},
+		{name: "evicted word", word: "docs", wantLink: "https://docs.example.com", wantLookups: 4},
+		{name: "recently used word kept", word: "github", wantLink: "https://github.com", wantLookups: 4},
+		{name: "expired", word: "github", advance: time.Minute, wantLink: "https://github.com", wantLookups: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			shortcut, err := cache.GetByWord(ctx, tt.word)
+			if err != nil {
+				t.Fatalf("ShortcutCache.GetByWord() error = %v", err)
+			}
+			link := ""
+			if shortcut != nil {
+				link = shortcut.Link
+			}
+			if link != tt.wantLink || repo.lookups != tt.wantLookups {
+				t.Errorf("ShortcutCache.GetByWord(%q) = %q after %d lookups, want %q after %d",
+					tt.word, link, repo.lookups, tt.wantLink, tt.wantLookups)
+			}
+		})
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Capacity != 2 || stats.Hits != 3 || stats.Misses != 5 {
+		t.Errorf("ShortcutCache.Stats() = %+v, want 2 of 2 entries, 3 hits and 5 misses", stats)
+	}
+
+	// Changing a cached shortcut doesn't change the cache
+	shortcut, _ := cache.GetByWord(ctx, "github")
+	shortcut.Link = "https://changed.example.com"
+	if shortcut, _ := cache.GetByWord(ctx, "github"); shortcut.Link != "https://github.com" {
+		t.Errorf("ShortcutCache.GetByWord() = %q, want the cached link unchanged", shortcut.Link)
+	}
+}
+
+func TestShortcutCache_Invalidate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		write func(t *testing.T, cache *ShortcutCache) error
+	}{
+		{"create", func(t *testing.T, cache *ShortcutCache) error {
+			return cache.Create(ctx, &domain.Shortcut{Word: "docs", Link: "https://docs.example.org", User: "alice"})
+		}},
+		{"create batch", func(t *testing.T, cache *ShortcutCache) error {
+			return cache.CreateBatch(ctx, []*domain.Shortcut{{Word: "docs", Link: "https://docs.example.org", User: "alice"}})
+		}},
+		{"delete", func(t *testing.T, cache *ShortcutCache) error {
+			_, err := cache.DeleteByWord(ctx, "docs")
+			return err
+		}},
+		{"flush", func(t *testing.T, cache *ShortcutCache) error {
+			cache.Invalidate(ctx)
+			return nil
+		}},
+		{"restore a backup", func(t *testing.T, cache *ShortcutCache) error {
+			path := filepath.Join(t.TempDir(), "backup.db")
+			if err := os.WriteFile(path, []byte("links"), 0o600); err != nil {
+				return err
+			}
+			return cache.FlushOnRestore(&mockSnapshotter{}).Restore(ctx, path)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &countingShortcutRepository{mockShortcutRepository: &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+			}}}
+			cache := NewShortcutCache(repo, 10, time.Hour)
+
+			if _, err := cache.GetByWord(ctx, "docs"); err != nil {
+				t.Fatalf("ShortcutCache.GetByWord() error = %v", err)
+			}
+			if err := tt.write(t, cache); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			if _, err := cache.GetByWord(ctx, "docs"); err != nil {
+				t.Fatalf("ShortcutCache.GetByWord() error = %v", err)
+			}
+			if repo.lookups != 2 {
+				t.Errorf("%d lookups reached the repository, want the word read again after the write", repo.lookups)
+			}
+		})
+	}
+
+	if cache := NewShortcutCache(&mockShortcutRepository{}, 10, time.Hour); cache.FlushOnRestore(nil) != nil {
+		t.Error("ShortcutCache.FlushOnRestore(nil) is not nil")
+	}
+}
+
+func TestLinkService_CacheStats(t *testing.T) {
+	repo := &mockShortcutRepository{}
+	if stats := NewLinkService(repo, &mockQueryRepository{}).CacheStats(); len(stats) != 0 {
+		t.Errorf("LinkService.CacheStats() = %+v without a cache, want none", stats)
+	}
+
+	cache := NewShortcutCache(repo, 10, time.Minute)
+	stats := NewLinkService(cache, &mockQueryRepository{}).CacheStats()
+	if len(stats) != 1 || stats[0].Name != "links" || stats[0].Capacity != 10 {
+		t.Errorf("LinkService.CacheStats() = %+v, want the links cache", stats)
+	}
+}
+
+// mockSharedCache keeps shared shortcuts in a map and records invalidations
+type mockSharedCache struct {
+	shortcuts   map[string]*domain.Shortcut
+	invalidated [][]string
+	err         error
+
+	// drop is the function Subscribe was called with
+	drop func(words ...string)
+}
+
+func (m *mockSharedCache) GetShortcut(ctx context.Context, word string) (*domain.Shortcut, bool, error) {
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	shortcut, found := m.shortcuts[word]
+	return shortcut, found, nil
+}
+
+func (m *mockSharedCache) SetShortcut(ctx context.Context, word string, shortcut *domain.Shortcut, ttl time.Duration) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.shortcuts[word] = shortcut
+	return nil
+}
+
+func (m *mockSharedCache) Invalidate(ctx context.Context, words ...string) error {
+	m.invalidated = append(m.invalidated, words)
+	for _, word := range words {
+		delete(m.shortcuts, word)
+	}
+	return m.err
+}
+
+func (m *mockSharedCache) Subscribe(ctx context.Context, drop func(words ...string)) error {
+	m.drop = drop
+	return nil
+}
+
+func TestShortcutCache_Shared(t *testing.T) {
+	repo := &countingShortcutRepository{mockShortcutRepository: &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs":   {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"github": {ID: 2, Word: "github", Link: "https://github.com", User: "alice"},
+	}}}
+	shared := &mockSharedCache{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.org", User: "alice"},
+	}}
+	cache := NewShortcutCache(repo, 10, time.Minute)
+	cache.Share(shared)
+	ctx := context.Background()
+	if err := cache.Listen(ctx); err != nil {
+		t.Fatalf("ShortcutCache.Listen() error = %v", err)
+	}
+
+	// A word in the shared cache is read from there, and others are shared once read
+	if shortcut, _ := cache.GetByWord(ctx, "docs"); shortcut.Link != "https://docs.example.org" || repo.lookups != 0 {
+		t.Errorf("ShortcutCache.GetByWord() = %q after %d lookups, want the shared copy", shortcut.Link, repo.lookups)
+	}
+	if _, err := cache.GetByWord(ctx, "github"); err != nil || repo.lookups != 1 || shared.shortcuts["github"] == nil {
+		t.Errorf("ShortcutCache.GetByWord() error = %v after %d lookups, want github read and shared", err, repo.lookups)
+	}
+
+	// Another server changing a word drops it here too
+	delete(shared.shortcuts, "github")
+	shared.drop("github")
+	if _, err := cache.GetByWord(ctx, "github"); err != nil || repo.lookups != 2 {
+		t.Errorf("ShortcutCache.GetByWord() error = %v after %d lookups, want github read again", err, repo.lookups)
+	}
+
+	// Writes are broadcast
+	if _, err := cache.DeleteByWord(ctx, "docs"); err != nil {
+		t.Fatalf("ShortcutCache.DeleteByWord() error = %v", err)
+	}
+	if len(shared.invalidated) != 1 || len(shared.invalidated[0]) != 1 || shared.invalidated[0][0] != "docs" {
+		t.Errorf("invalidated %v in the shared cache, want docs", shared.invalidated)
+	}
+
+	// The database answers while the shared cache is down
+	shared.err = errors.New("connection refused")
+	cache.drop()
+	if shortcut, err := cache.GetByWord(ctx, "github"); err != nil || shortcut == nil || repo.lookups != 3 {
+		t.Errorf("ShortcutCache.GetByWord() = %+v, %v after %d lookups, want github from the database", shortcut, err, repo.lookups)
+	}
+
+	stats := cache.Stats()
+	if !stats.Shared || stats.SharedHits != 1 || stats.SharedErrors != 2 {
+		t.Errorf("ShortcutCache.Stats() = %+v, want 1 shared hit and 2 shared errors", stats)
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+const (
+	// clickWindow is the longest rolling window clicks are counted over, in seconds
+	clickWindow = 3600
+
+	// clickBuffer is how many clicks a subscriber may fall behind by before it misses some
+	clickBuffer = 64
+)
+
+// clickFeed fans followed golinks out to live stats subscribers and keeps rolling counts
+// of them. It only sees the clicks served by this process.
+type clickFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan domain.ClickEvent]struct{}
+
+	// counts holds the clicks in each second of the last hour at index second % clickWindow,
+	// and seconds the Unix second each slot was last counted for
+	counts  [clickWindow]int
+	seconds [clickWindow]int64
+
+	total int64
+	since time.Time
+	now   func() time.Time
+}
+
+func newClickFeed(now func() time.Time) *clickFeed {
+	return &clickFeed{
+		subscribers: map[chan domain.ClickEvent]struct{}{},
+		since:       now().UTC().Truncate(time.Second),
+		now:         now,
+	}
+}
+
+// publish counts a click and sends it to every subscriber with room for it
+func (f *clickFeed) publish(event domain.ClickEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	second := event.Time.Unix()
+	slot := second % clickWindow
+	switch {
+	case second > f.seconds[slot]:
+		f.seconds[slot] = second
+		f.counts[slot] = 1
+	case second == f.seconds[slot]:
+		f.counts[slot]++
+	}
+	// A click older than the slot's second is out of the window already
+	f.total++
+
+	for subscriber := range f.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel of clicks published from now on, and a function that
+// unsubscribes and closes it
+func (f *clickFeed) subscribe() (<-chan domain.ClickEvent, func()) {
+	subscriber := make(chan domain.ClickEvent, clickBuffer)
+
+	f.mu.Lock()
+	f.subscribers[subscriber] = struct{}{}
+	f.mu.Unlock()
+
+	return subscriber, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subscribers[subscriber]; ok {
+			delete(f.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// counters sums the clicks over the last minute and hour
+func (f *clickFeed) counters() domain.ClickCounters {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now().Unix()
+	counters := domain.ClickCounters{Total: f.total, Since: f.since}
+	for slot, second := range f.seconds {
+		age := now - second
+		if age < 0 || age >= clickWindow {
+			continue
+		}
+		if age < 60 {
+			counters.LastMinute += f.counts[slot]
+		}
+		counters.LastHour += f.counts[slot]
+	}
+	return counters
+}
+
+// SubscribeClicks streams the public golinks followed through this service from now on,
+// until the returned function is called
+func (s *LinkService) SubscribeClicks() (<-chan domain.ClickEvent, func()) {
+	return s.clicks.subscribe()
+}
+
+// ClickCounters counts the public golinks followed through this service over the last
+// minute and hour, and since it started
+func (s *LinkService) ClickCounters() domain.ClickCounters {
+	return s.clicks.counters()
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+func TestClickFeed_Counters(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	feed := newClickFeed(func() time.Time { return now })
+
+	for _, ago := range []time.Duration{0, 30 * time.Second, 30 * time.Second, 5 * time.Minute, 59 * time.Minute, 2 * time.Hour} {
+		feed.publish(domain.ClickEvent{Word: "docs", Time: start.Add(-ago)})
+	}
+
+	tests := []struct {
+		name           string
+		at             time.Time
+		wantLastMinute int
+		wantLastHour   int
+	}{
+		{"now", start, 3, 5},
+		{"a minute later", start.Add(time.Minute), 0, 4},
+		{"half an hour later", start.Add(30 * time.Minute), 0, 4},
+		{"an hour later", start.Add(time.Hour), 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = tt.at
+			counters := feed.counters()
+			if counters.LastMinute != tt.wantLastMinute || counters.LastHour != tt.wantLastHour {
+				t.Errorf("counters() = %d in the last minute and %d in the last hour, want %d and %d",
+					counters.LastMinute, counters.LastHour, tt.wantLastMinute, tt.wantLastHour)
+			}
+			if counters.Total != 6 || !counters.Since.Equal(start) {
+				t.Errorf("counters() = %d since %v, want 6 since %v", counters.Total, counters.Since, start)
+			}
+		})
+	}
+
+	// A slot reused an hour later starts counting again
+	now = start.Add(time.Hour)
+	feed.publish(domain.ClickEvent{Word: "docs", Time: now})
+	if counters := feed.counters(); counters.LastMinute != 1 || counters.LastHour != 1 {
+		t.Errorf("counters() = %+v, want the one new click", counters)
+	}
+}
+
+func TestLinkService_SubscribeClicks(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs":    {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"d":       {ID: 2, Word: "d", Link: "docs", User: "alice"},
+		"payroll": {ID: 3, Word: "payroll", Link: "https://payroll.example.com", User: "alice", Private: true},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+
+	clicks, unsubscribe := service.SubscribeClicks()
+
+	ctx := WithClient(WithReferrer(context.Background(), "wiki.example.com"), domain.ClientBrowser)
+	for _, word := range []string{"d", "payroll"} {
+		if _, err := service.ResolveDetail(ctx, word, true, "alice"); err != nil {
+			t.Fatalf("LinkService.ResolveDetail(%q) error = %v", word, err)
+		}
+	}
+	if _, err := service.ResolveDetail(ctx, "docs", false, "alice"); err != nil {
+		t.Fatalf("LinkService.ResolveDetail() error = %v", err)
+	}
+	unsubscribe()
+	unsubscribe()
+
+	// Every logged hop of an alias is published; private and unlogged lookups are not
+	var words []string
+	for click := range clicks {
+		if click.Referrer != "wiki.example.com" || click.Client != domain.ClientBrowser {
+			t.Errorf("click on %s from %q via %q, want wiki.example.com via browser", click.Word, click.Referrer, click.Client)
+		}
+		words = append(words, click.Word)
+	}
+	if len(words) != 2 || words[0] != "d" || words[1] != "docs" {
+		t.Errorf("published clicks on %v, want d and docs", words)
+	}
+	if counters := service.ClickCounters(); counters.Total != 2 || counters.LastMinute != 2 {
+		t.Errorf("LinkService.ClickCounters() = %+v, want 2 clicks", counters)
+	}
+}
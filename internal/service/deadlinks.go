@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// deadLinkWorkers is how many targets the dead link checker requests at once
+const deadLinkWorkers = 4
+
+// LinkHealthRepository records what the last check of each golink's target found
+type LinkHealthRepository interface {
+	GetAll(ctx context.Context) ([]domain.LinkHealth, error)
+	Replace(ctx context.Context, health []domain.LinkHealth) error
+}
+
+// BrokenLinkMailer emails an owner the golinks of theirs whose targets stopped working
+type BrokenLinkMailer interface {
+	SendBrokenLinks(ctx context.Context, owner string, links []domain.LinkHealth) error
+}
+
+// DeadLinkService checks the target of every golink now and then, so links to pages that
+// have gone can be found and fixed
+type DeadLinkService struct {
+	links   LinkExporter
+	health  LinkHealthRepository
+	checker LinkChecker
+
+	// mailer tells owners when their links break, or is nil to leave it to the report
+	mailer BrokenLinkMailer
+
+	now func() time.Time
+}
+
+// NewDeadLinkService creates a service checking the targets of links with checker and
+// recording the findings in health. A nil mailer emails nobody.
+func NewDeadLinkService(
+	links LinkExporter, health LinkHealthRepository, checker LinkChecker, mailer BrokenLinkMailer,
+) *DeadLinkService {
+	return &DeadLinkService{links: links, health: health, checker: checker, mailer: mailer, now: time.Now}
+}
+
+// CheckAll requests the target of every golink that leads to a web page, records what it
+// found and emails the owners of links that broke since the last check. Links with
+// placeholders are skipped, as they only become a URL once filled in.
+func (s *DeadLinkService) CheckAll(ctx context.Context) (*domain.LinkCheckRun, error) {
+	export, err := s.links.ExportLinks(ctx, domain.ExportRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+	previous, err := s.health.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link health: %w", err)
+	}
+	last := make(map[string]domain.LinkHealth, len(previous))
+	for _, h := range previous {
+		last[h.Word] = h
+	}
+
+	health := []domain.LinkHealth{}
+	for _, link := range export.Links {
+		if !IsWebURL(link.Link) || strings.Contains(link.Link, "{") {
+			continue
+		}
+		health = append(health, domain.LinkHealth{Word: link.Word, Link: link.Link, Owner: link.Owner})
+	}
+	s.checkTargets(ctx, health)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	run := &domain.LinkCheckRun{Checked: len(health)}
+	newlyBroken := map[string][]domain.LinkHealth{}
+	for i := range health {
+		h := &health[i]
+		if h.Problem == "" {
+			continue
+		}
+		run.Broken++
+
+		// A link keeps the time it broke until it works or points somewhere else
+		if before, ok := last[h.Word]; ok && before.Link == h.Link && before.BrokenSince != nil {
+			h.BrokenSince = before.BrokenSince
+			continue
+		}
+		h.BrokenSince = &h.CheckedAt
+		run.NewlyBroken++
+		newlyBroken[h.Owner] = append(newlyBroken[h.Owner], *h)
+	}
+
+	if err := s.health.Replace(ctx, health); err != nil {
+		return nil, fmt.Errorf("failed to record link health: %w", err)
+	}
+
+	if s.mailer != nil {
+		for owner, links := range newlyBroken {
+			// Only owners signed in by email address can be written to
+			if !strings.Contains(owner, "@") {
+				continue
+			}
+			if err := s.mailer.SendBrokenLinks(ctx, owner, links); err != nil {
+				run.EmailsFailed++
+				continue
+			}
+			run.Emailed++
+		}
+	}
+
+	return run, nil
+}
+
+// checkTargets requests each link's target a few at a time, filling in when it was checked
+// and any problem found
+func (s *DeadLinkService) checkTargets(ctx context.Context, health []domain.LinkHealth) {
+	next := make(chan *domain.LinkHealth)
+	var wg sync.WaitGroup
+	for i := 0; i < deadLinkWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h := range next {
+				if err := s.checker.Check(ctx, h.Link); err != nil {
+					h.Problem = err.Error()
+				}
+				h.CheckedAt = s.now().UTC().Truncate(time.Second)
+			}
+		}()
+	}
+
+	for i := range health {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- &health[i]
+	}
+	close(next)
+	wg.Wait()
+}
+
+// BrokenLinks reports the golinks whose targets looked broken when last checked, longest
+// broken first
+func (s *DeadLinkService) BrokenLinks(ctx context.Context) (*domain.BrokenLinkReport, error) {
+	health, err := s.health.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link health: %w", err)
+	}
+
+	report := &domain.BrokenLinkReport{Links: []domain.LinkHealth{}}
+	for _, h := range health {
+		if h.Problem != "" {
+			report.Links = append(report.Links, h)
+		}
+	}
+	sort.SliceStable(report.Links, func(i, j int) bool {
+		a, b := report.Links[i].BrokenSince, report.Links[j].BrokenSince
+		return a != nil && (b == nil || a.Before(*b))
+	})
+	return report, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// stubExporter lists links as an export would
+type stubExporter struct {
+	links []domain.ExportedLink
+}
+
+func (e *stubExporter) ExportLinks(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error) {
+	return &domain.LinkExport{Links: e.links}, nil
+}
+
+// memoryLinkHealth keeps the findings of the last check
+type memoryLinkHealth struct {
+	health []domain.LinkHealth
+}
+
+func (r *memoryLinkHealth) GetAll(ctx context.Context) ([]domain.LinkHealth, error) {
+	return append([]domain.LinkHealth{}, r.health...), nil
+}
+
+func (r *memoryLinkHealth) Replace(ctx context.Context, health []domain.LinkHealth) error {
+	r.health = append([]domain.LinkHealth{}, health...)
+	return nil
+}
+
+// stubMailer records the links each owner is emailed about, failing for owners in fail
+type stubMailer struct {
+	sent map[string][]string
+	fail map[string]bool
+}
+
+func (m *stubMailer) SendBrokenLinks(ctx context.Context, owner string, links []domain.LinkHealth) error {
+	if m.fail[owner] {
+		return errors.New("mailbox unavailable")
+	}
+	for _, link := range links {
+		m.sent[owner] = append(m.sent[owner], link.Word)
+	}
+	return nil
+}
+
+func TestDeadLinkService_CheckAll(t *testing.T) {
+	exporter := &stubExporter{links: []domain.ExportedLink{
+		{Word: "docs", Link: "https://docs.example.com", Owner: "alice@example.com"},
+		{Word: "old", Link: "https://old.example.com", Owner: "alice@example.com"},
+		{Word: "gone", Link: "https://gone.example.com", Owner: "bob@example.com"},
+		{Word: "legacy", Link: "https://legacy.example.com", Owner: "DefaultUser"},
+		{Word: "search", Link: "https://gone.example.com/?q={*}", Owner: "alice@example.com"},
+		{Word: "d", Link: "docs", Owner: "alice@example.com"},
+	}}
+	checker := &stubLinkChecker{broken: map[string]bool{
+		"https://old.example.com":    true,
+		"https://gone.example.com":   true,
+		"https://legacy.example.com": true,
+	}}
+	health := &memoryLinkHealth{}
+	mailer := &stubMailer{sent: map[string][]string{}, fail: map[string]bool{"bob@example.com": true}}
+	service := NewDeadLinkService(exporter, health, checker, mailer)
+	first := time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return first }
+
+	run, err := service.CheckAll(context.Background())
+	if err != nil {
+		t.Fatalf("DeadLinkService.CheckAll() error = %v", err)
+	}
+	want := &domain.LinkCheckRun{Checked: 4, Broken: 3, NewlyBroken: 3, Emailed: 1, EmailsFailed: 1}
+	if !reflect.DeepEqual(run, want) {
+		t.Errorf("DeadLinkService.CheckAll() = %+v, want %+v", run, want)
+	}
+	if want := map[string][]string{"alice@example.com": {"old"}}; !reflect.DeepEqual(mailer.sent, want) {
+		t.Errorf("DeadLinkService.CheckAll() emailed %v, want %v", mailer.sent, want)
+	}
+
+	// The next day old still fails and gone has been fixed
+	delete(checker.broken, "https://gone.example.com")
+	mailer.sent = map[string][]string{}
+	service.now = func() time.Time { return first.Add(24 * time.Hour) }
+
+	run, err = service.CheckAll(context.Background())
+	if err != nil {
+		t.Fatalf("DeadLinkService.CheckAll() error = %v", err)
+	}
+	want = &domain.LinkCheckRun{Checked: 4, Broken: 2}
+	if !reflect.DeepEqual(run, want) || len(mailer.sent) != 0 {
+		t.Errorf("DeadLinkService.CheckAll() = %+v emailing %v, want %+v emailing nobody", run, mailer.sent, want)
+	}
+
+	report, err := service.BrokenLinks(context.Background())
+	if err != nil {
+		t.Fatalf("DeadLinkService.BrokenLinks() error = %v", err)
+	}
+	var words []string
+	for _, link := range report.Links {
+		words = append(words, link.Word)
+		if !link.BrokenSince.Equal(first) || !link.CheckedAt.Equal(first.Add(24*time.Hour)) {
+			t.Errorf("DeadLinkService.BrokenLinks() %s broken since %v, checked %v, want since %v", link.Word, link.BrokenSince, link.CheckedAt, first)
+		}
+	}
+	if want := []string{"old", "legacy"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("DeadLinkService.BrokenLinks() = %v, want %v", words, want)
+	}
+}
+
+func TestDeadLinkService_BrokenLinks_Order(t *testing.T) {
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	health := &memoryLinkHealth{health: []domain.LinkHealth{
+		{Word: "a", Problem: "it answered 404 Not Found", BrokenSince: day(4)},
+		{Word: "b"},
+		{Word: "c", Problem: "it answered 500 Internal Server Error", BrokenSince: day(1)},
+		{Word: "d", Problem: "d.example.com doesn't exist", BrokenSince: day(3)},
+	}}
+	service := NewDeadLinkService(&stubExporter{}, health, &stubLinkChecker{}, nil)
+
+	report, err := service.BrokenLinks(context.Background())
+	if err != nil {
+		t.Fatalf("DeadLinkService.BrokenLinks() error = %v", err)
+	}
+	var words []string
+	for _, link := range report.Links {
+		words = append(words, link.Word)
+	}
+	if want := []string{"c", "d", "a"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("DeadLinkService.BrokenLinks() = %v, want %v", words, want)
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxDescriptionRunes bounds a link's description to a line's worth of text
+const maxDescriptionRunes = 200
+
+// validateDescription checks a description is a single line of at most
+// maxDescriptionRunes characters
+func validateDescription(description string) error {
+	if utf8.RuneCountInString(description) > maxDescriptionRunes {
+		return InvalidQueryError{
+			Message: fmt.Sprintf("Descriptions are limited to %d characters", maxDescriptionRunes),
+		}
+	}
+	if strings.IndexFunc(description, unicode.IsControl) >= 0 {
+		return InvalidQueryError{Message: "Descriptions must be a single line of text"}
+	}
+	return nil
+}
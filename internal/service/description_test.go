@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func Test_validateDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		wantErr     bool
+	}{
+		{"empty", "", false},
+		{"one line", "Where the on-call schedule lives", false},
+		{"non-ASCII", "Planning de l'équipe 📅", false},
+		{"longest", strings.Repeat("é", maxDescriptionRunes), false},
+		{"too long", strings.Repeat("a", maxDescriptionRunes+1), true},
+		{"several lines", "Runbooks\nand dashboards", true},
+		{"control character", "Runbooks\x1b[31m", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDescription(tt.description)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateDescription(%q) error = %v, wantErr %v", tt.description, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLinkService_UpdateLink_Description(t *testing.T) {
+	repo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+	service := NewLinkService(repo, &mockQueryRepository{})
+	ctx := context.Background()
+
+	req := domain.LinkRequest{Word: "oncall", Link: "https://pager.example.com", Description: "  Who is on call  "}
+	if err := service.UpdateLink(ctx, req, "alice"); err != nil {
+		t.Fatalf("LinkService.UpdateLink() error = %v", err)
+	}
+	if got := repo.shortcuts["oncall"].Description; got != "Who is on call" {
+		t.Errorf("LinkService.UpdateLink() stored description %q, want it trimmed", got)
+	}
+
+	req.Description = "Who is\non call"
+	if err := service.UpdateLink(ctx, req, "alice"); err == nil {
+		t.Error("LinkService.UpdateLink() accepted a description of several lines")
+	}
+}
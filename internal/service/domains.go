@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golinks/internal/domain"
+)
+
+// DomainPolicy holds the domains new links may and may not point to. Admins can replace
+// the lists while the server runs; links already stored are left alone.
+type DomainPolicy struct {
+	mu    sync.RWMutex
+	lists domain.DomainLists
+}
+
+// NewDomainPolicy creates a policy from the configured lists, failing if an entry isn't a
+// domain
+func NewDomainPolicy(lists domain.DomainLists) (*DomainPolicy, error) {
+	p := &DomainPolicy{}
+	if _, err := p.SetLists(lists); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WithDomainPolicy checks the targets of new and updated links against policy
+func WithDomainPolicy(policy *DomainPolicy) Option {
+	return func(s *LinkService) {
+		s.domains = policy
+	}
+}
+
+// Lists returns the current lists
+func (p *DomainPolicy) Lists() domain.DomainLists {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return domain.DomainLists{
+		Denied:  append([]string{}, p.lists.Denied...),
+		Allowed: append([]string{}, p.lists.Allowed...),
+	}
+}
+
+// SetLists replaces both lists, returning them as they are stored: lowercased, without
+// leading or trailing dots and without duplicates
+func (p *DomainPolicy) SetLists(lists domain.DomainLists) (domain.DomainLists, error) {
+	denied, err := normalizeDomains(lists.Denied)
+	if err != nil {
+		return domain.DomainLists{}, err
+	}
+	allowed, err := normalizeDomains(lists.Allowed)
+	if err != nil {
+		return domain.DomainLists{}, err
+	}
+
+	p.mu.Lock()
+	p.lists = domain.DomainLists{Denied: denied, Allowed: allowed}
+	p.mu.Unlock()
+	return p.Lists(), nil
+}
+
+// check rejects web links to a denied domain, or to one that isn't allowed when only some
+// are. Aliases and other schemes have no domain and pass.
+func (p *DomainPolicy) check(link string) error {
+	if p == nil || !isURL(link) {
+		return nil
+	}
+	target, err := url.Parse(link)
+	if err != nil {
+		return InvalidQueryError{Message: "The link is not a valid URL"}
+	}
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if denied := matchDomain(host, p.lists.Denied); denied != "" {
+		return InvalidQueryError{Message: fmt.Sprintf("Links to %s are not allowed", denied)}
+	}
+	if len(p.lists.Allowed) > 0 && matchDomain(host, p.lists.Allowed) == "" {
+		return InvalidQueryError{Message: fmt.Sprintf("%s is not one of the domains links may point to", host)}
+	}
+	return nil
+}
+
+// matchDomain returns the entry of domains that host is or is a subdomain of, or "" if none
+func matchDomain(host string, domains []string) string {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return d
+		}
+	}
+	return ""
+}
+
+// normalizeDomains lowercases domains and drops surrounding dots, blanks and duplicates,
+// failing on entries such as URLs that aren't plain domains
+func normalizeDomains(domains []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, d := range domains {
+		d = strings.ToLower(strings.Trim(strings.TrimSpace(d), "."))
+		if d == "" || seen[d] {
+			continue
+		}
+		if strings.ContainsAny(d, "/:@?# ") {
+			return nil, InvalidQueryError{Message: fmt.Sprintf("%q is not a domain", d)}
+		}
+		seen[d] = true
+		normalized = append(normalized, d)
+	}
+	return normalized, nil
+}
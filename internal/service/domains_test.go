@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_UpdateLink_Domains(t *testing.T) {
+	tests := []struct {
+		name    string
+		lists   domain.DomainLists
+		link    string
+		wantErr string
+	}{
+		{"no lists", domain.DomainLists{}, "https://bit.ly/abc", ""},
+		{"denied", domain.DomainLists{Denied: []string{"bit.ly"}}, "https://bit.ly/abc", "Links to bit.ly are not allowed"},
+		{"denied subdomain", domain.DomainLists{Denied: []string{"bit.ly"}}, "https://WWW.bit.ly./abc", "Links to bit.ly are not allowed"},
+		{"lookalike of a denied domain", domain.DomainLists{Denied: []string{"bit.ly"}}, "https://notbit.ly/abc", ""},
+		{"allowed", domain.DomainLists{Allowed: []string{"example.com"}}, "https://docs.example.com/x", ""},
+		{"not allowed", domain.DomainLists{Allowed: []string{"example.com"}}, "https://example.org", "example.org is not one of the domains"},
+		{
+			"denied within allowed", domain.DomainLists{Denied: []string{"old.example.com"}, Allowed: []string{"example.com"}},
+			"https://old.example.com", "Links to old.example.com are not allowed",
+		},
+		{"alias", domain.DomainLists{Allowed: []string{"example.com"}}, "docs", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewDomainPolicy(tt.lists)
+			if err != nil {
+				t.Fatalf("NewDomainPolicy(%+v) error = %v", tt.lists, err)
+			}
+			repo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "testuser"},
+			}}
+			service := NewLinkService(repo, &mockQueryRepository{}, WithDomainPolicy(policy))
+
+			err = service.UpdateLink(context.Background(), domain.LinkRequest{Word: "short", Link: tt.link}, "testuser")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LinkService.UpdateLink(%s) error = %v", tt.link, err)
+				}
+				return
+			}
+			if _, ok := err.(InvalidQueryError); !ok || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LinkService.UpdateLink(%s) error = %v, want %q", tt.link, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDomainPolicy_SetLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		lists   domain.DomainLists
+		want    domain.DomainLists
+		wantErr bool
+	}{
+		{
+			name:  "normalized",
+			lists: domain.DomainLists{Denied: []string{" Bit.ly ", ".tinyurl.com.", "bit.ly", ""}, Allowed: nil},
+			want:  domain.DomainLists{Denied: []string{"bit.ly", "tinyurl.com"}, Allowed: []string{}},
+		},
+		{name: "url", lists: domain.DomainLists{Allowed: []string{"https://example.com"}}, wantErr: true},
+		{name: "path", lists: domain.DomainLists{Denied: []string{"example.com/x"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewDomainPolicy(domain.DomainLists{Denied: []string{"kept.example.com"}})
+			if err != nil {
+				t.Fatalf("NewDomainPolicy() error = %v", err)
+			}
+
+			got, err := policy.SetLists(tt.lists)
+			if tt.wantErr {
+				if _, ok := err.(InvalidQueryError); !ok {
+					t.Errorf("DomainPolicy.SetLists(%+v) error = %v, want an InvalidQueryError", tt.lists, err)
+				}
+				if lists := policy.Lists(); !reflect.DeepEqual(lists.Denied, []string{"kept.example.com"}) {
+					t.Errorf("DomainPolicy.SetLists(%+v) changed the lists to %+v", tt.lists, lists)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(policy.Lists(), tt.want) {
+				t.Errorf("DomainPolicy.SetLists(%+v) = %+v, %v, want %+v", tt.lists, got, err, tt.want)
+			}
+		})
+	}
+}
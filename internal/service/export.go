@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// ExportLinks dumps every link the request asks for, private ones included, with its tags
+// and, if History is set, every version of it. Links are filtered by their current owner.
+func (s *LinkService) ExportLinks(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error) {
+	if req.Tag != "" {
+		tag, err := normalizeTag(req.Tag)
+		if err != nil {
+			return nil, err
+		}
+		req.Tag = tag
+	}
+
+	versions, err := s.shortcutRepo.GetAllVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcuts: %w", err)
+	}
+
+	tags := map[string][]string{}
+	if s.tagRepo != nil {
+		if tags, err = s.tagRepo.GetAllTags(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get tags: %w", err)
+		}
+	}
+
+	export := &domain.LinkExport{ExportedAt: s.now().UTC(), Links: []domain.ExportedLink{}}
+	for i, version := range versions {
+		// Versions come by word, so a word's first version starts a new link
+		if i == 0 || versions[i-1].Word != version.Word {
+			wordTags := tags[version.Word]
+			if wordTags == nil {
+				wordTags = []string{}
+			}
+			export.Links = append(export.Links, domain.ExportedLink{
+				Word:      version.Word,
+				Tags:      wordTags,
+				CreatedAt: version.CreatedAt,
+			})
+		}
+
+		link := &export.Links[len(export.Links)-1]
+		link.Link = version.Link
+		link.Owner = version.User
+		link.Icon = version.Icon
+		link.Description = version.Description
+		link.Private = version.Private
+		link.Prefix = version.Prefix
+		link.Archived = version.Archived
+		link.Metadata = version.Metadata
+		link.UpdatedAt = version.CreatedAt
+		link.UpdatedBy = version.UpdatedBy
+		if req.History {
+			link.History = append(link.History, version)
+		}
+	}
+
+	export.Links = slices.DeleteFunc(export.Links, func(link domain.ExportedLink) bool {
+		return !exportMatches(link, req)
+	})
+	return export, nil
+}
+
+// exportMatches reports whether a link passes an export's filters
+func exportMatches(link domain.ExportedLink, req domain.ExportRequest) bool {
+	if req.User != "" && link.Owner != req.User {
+		return false
+	}
+	if req.Tag != "" && !slices.Contains(link.Tags, req.Tag) {
+		return false
+	}
+	if req.Namespace != "" && !strings.HasPrefix(link.Word, req.Namespace+"/") {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_ExportLinks(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	now := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	setup := func(tags bool) *LinkService {
+		shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+		for _, shortcut := range []*domain.Shortcut{
+			{Word: "docs", Link: "https://docs.example.com", User: "alice", CreatedAt: day(1)},
+			{Word: "secret", Link: "https://secret.example.com", User: "bob", Private: true, CreatedAt: day(2)},
+			{Word: "docs", Link: "https://docs.example.com/v2", User: "carol", Icon: "📄", CreatedAt: day(3)},
+			{Word: "team/wiki", Link: "https://wiki.example.com", User: "alice", CreatedAt: day(4)},
+		} {
+			_ = shortcutRepo.Create(context.Background(), shortcut)
+		}
+
+		opts := []Option{}
+		if tags {
+			tagRepo := &mockTagRepository{shortcuts: shortcutRepo, tags: map[string]map[string]bool{
+				"docs": {"engineering": true, "docs": true},
+			}}
+			opts = append(opts, WithTags(tagRepo))
+		}
+		s := NewLinkService(shortcutRepo, &mockQueryRepository{}, opts...)
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	docs := domain.ExportedLink{
+		Word: "docs", Link: "https://docs.example.com/v2", Owner: "carol", Icon: "📄",
+		Tags: []string{"docs", "engineering"}, CreatedAt: day(1), UpdatedAt: day(3),
+	}
+	secret := domain.ExportedLink{
+		Word: "secret", Link: "https://secret.example.com", Owner: "bob", Private: true,
+		Tags: []string{}, CreatedAt: day(2), UpdatedAt: day(2),
+	}
+	wiki := domain.ExportedLink{
+		Word: "team/wiki", Link: "https://wiki.example.com", Owner: "alice",
+		Tags: []string{}, CreatedAt: day(4), UpdatedAt: day(4),
+	}
+
+	tests := []struct {
+		name    string
+		tags    bool
+		req     domain.ExportRequest
+		want    []domain.ExportedLink
+		wantErr bool
+	}{
+		{name: "latest versions", tags: true, want: []domain.ExportedLink{docs, secret, wiki}},
+		{
+			name: "without tags",
+			want: []domain.ExportedLink{
+				func() domain.ExportedLink { link := docs; link.Tags = []string{}; return link }(),
+				secret,
+				wiki,
+			},
+		},
+		{name: "by current owner", tags: true, req: domain.ExportRequest{User: "alice"}, want: []domain.ExportedLink{wiki}},
+		{name: "by tag", tags: true, req: domain.ExportRequest{Tag: " Engineering"}, want: []domain.ExportedLink{docs}},
+		{name: "by namespace", tags: true, req: domain.ExportRequest{Namespace: "team"}, want: []domain.ExportedLink{wiki}},
+		{name: "matching nothing", tags: true, req: domain.ExportRequest{User: "carol", Namespace: "team"}, want: []domain.ExportedLink{}},
+		{name: "invalid tag", tags: true, req: domain.ExportRequest{Tag: "not a tag"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export, err := setup(tt.tags).ExportLinks(context.Background(), tt.req)
+			if tt.wantErr {
+				if _, ok := err.(InvalidQueryError); !ok {
+					t.Errorf("LinkService.ExportLinks() error = %v, want InvalidQueryError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.ExportLinks() error = %v", err)
+			}
+			if !export.ExportedAt.Equal(now) {
+				t.Errorf("LinkService.ExportLinks() exported at %v, want %v", export.ExportedAt, now)
+			}
+			if !reflect.DeepEqual(export.Links, tt.want) {
+				t.Errorf("LinkService.ExportLinks() = %+v, want %+v", export.Links, tt.want)
+			}
+		})
+	}
+
+	// With history, every version comes along oldest first
+	export, err := setup(true).ExportLinks(context.Background(), domain.ExportRequest{History: true})
+	if err != nil {
+		t.Fatalf("LinkService.ExportLinks() error = %v", err)
+	}
+	var links []string
+	for _, version := range export.Links[0].History {
+		links = append(links, version.Link)
+	}
+	if want := []string{"https://docs.example.com", "https://docs.example.com/v2"}; !reflect.DeepEqual(links, want) {
+		t.Errorf("LinkService.ExportLinks() docs history = %v, want %v", links, want)
+	}
+	if len(export.Links[1].History) != 1 {
+		t.Errorf("LinkService.ExportLinks() secret history = %+v, want its one version", export.Links[1].History)
+	}
+}
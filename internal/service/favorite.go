@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"golinks/internal/domain"
+)
+
+// MaxFavorites bounds how many links each user can pin
+const MaxFavorites = 50
+
+// FavoriteRepository interface for favorite operations
+type FavoriteRepository interface {
+	Add(ctx context.Context, user, word string) error
+	Remove(ctx context.Context, user, word string) (int64, error)
+	GetKeywords(ctx context.Context, user string) ([]domain.KeywordInfo, error)
+}
+
+// FavoriteService lets each user pin the links they use most, which their homepage lists
+// first
+type FavoriteService struct {
+	repo         FavoriteRepository
+	shortcutRepo ShortcutRepository
+}
+
+// NewFavoriteService creates a new favorite service
+func NewFavoriteService(repo FavoriteRepository, shortcutRepo ShortcutRepository) *FavoriteService {
+	return &FavoriteService{repo: repo, shortcutRepo: shortcutRepo}
+}
+
+// AddFavorite pins a golink for userID, who must be able to see it. Pinning a link twice
+// is not an error.
+func (s *FavoriteService) AddFavorite(ctx context.Context, word, userID string) error {
+	word = NormalizeWord(word)
+
+	shortcut, err := s.shortcutRepo.GetByWord(ctx, word)
+	if err != nil {
+		return fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	if !visibleTo(shortcut, userID) {
+		return NotFoundError{Message: fmt.Sprintf("No golink found for %s", word)}
+	}
+
+	favorites, err := s.repo.GetKeywords(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get favorites: %w", err)
+	}
+	for _, favorite := range favorites {
+		if favorite.Word == word {
+			return nil
+		}
+	}
+	if len(favorites) >= MaxFavorites {
+		return InvalidQueryError{Message: fmt.Sprintf("At most %d links can be pinned; unpin one first", MaxFavorites)}
+	}
+
+	if err := s.repo.Add(ctx, userID, word); err != nil {
+		return fmt.Errorf("failed to add favorite: %w", err)
+	}
+	return nil
+}
+
+// RemoveFavorite unpins a golink for userID
+func (s *FavoriteService) RemoveFavorite(ctx context.Context, word, userID string) error {
+	word = NormalizeWord(word)
+
+	removed, err := s.repo.Remove(ctx, userID, word)
+	if err != nil {
+		return fmt.Errorf("failed to remove favorite: %w", err)
+	}
+	if removed == 0 {
+		return NotFoundError{Message: fmt.Sprintf("%s is not pinned", word)}
+	}
+	return nil
+}
+
+// ListFavorites returns the golinks userID has pinned that they can still see, in
+// alphabetical order
+func (s *FavoriteService) ListFavorites(ctx context.Context, userID string) ([]domain.KeywordInfo, error) {
+	favorites, err := s.repo.GetKeywords(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get favorites: %w", err)
+	}
+	if favorites == nil {
+		favorites = []domain.KeywordInfo{}
+	}
+	return favorites, nil
+}
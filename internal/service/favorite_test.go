@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+// mockFavoriteRepository keeps each user's pinned words, listing the ones still in links
+type mockFavoriteRepository struct {
+	shortcuts map[string]*domain.Shortcut
+	favorites map[string]map[string]bool
+}
+
+func (m *mockFavoriteRepository) Add(ctx context.Context, user, word string) error {
+	if m.favorites[user] == nil {
+		m.favorites[user] = map[string]bool{}
+	}
+	m.favorites[user][word] = true
+	return nil
+}
+
+func (m *mockFavoriteRepository) Remove(ctx context.Context, user, word string) (int64, error) {
+	if !m.favorites[user][word] {
+		return 0, nil
+	}
+	delete(m.favorites[user], word)
+	return 1, nil
+}
+
+func (m *mockFavoriteRepository) GetKeywords(ctx context.Context, user string) ([]domain.KeywordInfo, error) {
+	keywords := []domain.KeywordInfo{}
+	for word := range m.favorites[user] {
+		if shortcut, ok := m.shortcuts[word]; ok && visibleTo(shortcut, user) {
+			keywords = append(keywords, domain.KeywordInfo{Word: word, Link: shortcut.Link})
+		}
+	}
+	sort.Slice(keywords, func(i, j int) bool { return keywords[i].Word < keywords[j].Word })
+	return keywords, nil
+}
+
+func TestFavoriteService(t *testing.T) {
+	ctx := context.Background()
+	shortcuts := map[string]*domain.Shortcut{
+		"docs":   {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "owner"},
+		"wiki":   {ID: 2, Word: "wiki", Link: "https://wiki.example.com", User: "owner"},
+		"secret": {ID: 3, Word: "secret", Link: "https://secret.example.com", User: "owner", Private: true},
+	}
+	repo := &mockFavoriteRepository{shortcuts: shortcuts, favorites: map[string]map[string]bool{}}
+	service := NewFavoriteService(repo, &mockShortcutRepository{shortcuts: shortcuts})
+
+	favorites, err := service.ListFavorites(ctx, "alice")
+	if err != nil || favorites == nil || len(favorites) != 0 {
+		t.Fatalf("FavoriteService.ListFavorites() = %v, %v, want no favorites", favorites, err)
+	}
+
+	tests := []struct {
+		name    string
+		word    string
+		user    string
+		wantErr error
+	}{
+		{name: "pins a link", word: "docs", user: "alice"},
+		{name: "trims the word", word: " wiki ", user: "alice"},
+		{name: "pinning twice is fine", word: "docs", user: "alice"},
+		{name: "missing link", word: "missing", user: "alice", wantErr: NotFoundError{}},
+		{name: "someone else's private link", word: "secret", user: "alice", wantErr: NotFoundError{}},
+		{name: "own private link", word: "secret", user: "owner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.AddFavorite(ctx, tt.word, tt.user)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("FavoriteService.AddFavorite() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("FavoriteService.AddFavorite() error = %v", err)
+			}
+		})
+	}
+
+	favorites, _ = service.ListFavorites(ctx, "alice")
+	if len(favorites) != 2 || favorites[0].Word != "docs" || favorites[1].Word != "wiki" {
+		t.Errorf("FavoriteService.ListFavorites() = %+v, want docs and wiki", favorites)
+	}
+
+	if err := service.RemoveFavorite(ctx, "docs", "alice"); err != nil {
+		t.Errorf("FavoriteService.RemoveFavorite() error = %v", err)
+	}
+	if err := service.RemoveFavorite(ctx, "docs", "alice"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("FavoriteService.RemoveFavorite() of an unpinned link error = %v, want NotFoundError", err)
+	}
+}
+
+func TestFavoriteService_Limit(t *testing.T) {
+	ctx := context.Background()
+	shortcuts := map[string]*domain.Shortcut{}
+	for i := 0; i <= MaxFavorites; i++ {
+		word := fmt.Sprintf("link%d", i)
+		shortcuts[word] = &domain.Shortcut{ID: i + 1, Word: word, Link: "https://example.com", User: "owner"}
+	}
+	repo := &mockFavoriteRepository{shortcuts: shortcuts, favorites: map[string]map[string]bool{}}
+	service := NewFavoriteService(repo, &mockShortcutRepository{shortcuts: shortcuts})
+
+	for i := 0; i < MaxFavorites; i++ {
+		if err := service.AddFavorite(ctx, fmt.Sprintf("link%d", i), "alice"); err != nil {
+			t.Fatalf("FavoriteService.AddFavorite() error = %v", err)
+		}
+	}
+
+	if err := service.AddFavorite(ctx, fmt.Sprintf("link%d", MaxFavorites), "alice"); !sameErrorType(err, InvalidQueryError{}) {
+		t.Errorf("FavoriteService.AddFavorite() past the limit error = %v, want InvalidQueryError", err)
+	}
+	if err := service.AddFavorite(ctx, "link0", "alice"); err != nil {
+		t.Errorf("FavoriteService.AddFavorite() of a pinned link at the limit error = %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxIconRunes bounds emoji icons; enough for ZWJ sequences like 👩‍💻
+const maxIconRunes = 8
+
+// namedIcons is the allowlist of icon identifiers and the glyph each one renders as
+var namedIcons = map[string]string{
+	"book":     "📚",
+	"bug":      "🐛",
+	"calendar": "📅",
+	"chart":    "📈",
+	"chat":     "💬",
+	"cloud":    "☁️",
+	"code":     "💻",
+	"doc":      "📄",
+	"folder":   "📁",
+	"home":     "🏠",
+	"key":      "🔑",
+	"link":     "🔗",
+	"lock":     "🔒",
+	"mail":     "✉️",
+	"rocket":   "🚀",
+	"search":   "🔍",
+	"star":     "⭐",
+	"tool":     "🔧",
+	"video":    "🎥",
+	"wiki":     "📖",
+}
+
+// IconGlyph returns the glyph to display for a stored icon value
+func IconGlyph(icon string) string {
+	if glyph, ok := namedIcons[icon]; ok {
+		return glyph
+	}
+	return icon
+}
+
+// validateIcon checks an icon is either an allowlisted identifier or a short emoji
+func validateIcon(icon string) error {
+	if icon == "" {
+		return nil
+	}
+
+	if _, ok := namedIcons[icon]; ok {
+		return nil
+	}
+
+	if !isEmoji(icon) {
+		names := make([]string, 0, len(namedIcons))
+		for name := range namedIcons {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return InvalidQueryError{
+			Message: fmt.Sprintf("Icons must be a single emoji or one of: %s", strings.Join(names, ", ")),
+		}
+	}
+
+	return nil
+}
+
+// isEmoji reports whether s looks like a short emoji sequence
+func isEmoji(s string) bool {
+	if utf8.RuneCountInString(s) > maxIconRunes || strings.TrimSpace(s) != s {
+		return false
+	}
+
+	hasSymbol := false
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			// Plain ASCII could smuggle markup or text into the icon slot
+			return false
+		case r == '\u200d':
+			// Zero-width joiner used in combined emoji
+		case unicode.Is(unicode.So, r):
+			hasSymbol = true
+		case !unicode.IsGraphic(r) || unicode.IsSpace(r):
+			return false
+		}
+	}
+
+	return hasSymbol
+}
@@ -0,0 +1,50 @@
+package service
+
+import "testing"
+
+func Test_validateIcon(t *testing.T) {
+	tests := []struct {
+		name    string
+		icon    string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"named icon", "book", false},
+		{"single emoji", "🚀", false},
+		{"emoji with variation selector", "☁️", false},
+		{"zwj sequence", "👩‍💻", false},
+		{"unknown name", "banana", true},
+		{"markup", "<script>", true},
+		{"letters", "é", true},
+		{"emoji with text", "🚀 go", true},
+		{"too long", "🚀🚀🚀🚀🚀🚀🚀🚀🚀", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIcon(tt.icon)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateIcon(%q) error = %v, wantErr %v", tt.icon, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIconGlyph(t *testing.T) {
+	tests := []struct {
+		icon string
+		want string
+	}{
+		{"rocket", "🚀"},
+		{"📚", "📚"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.icon, func(t *testing.T) {
+			if got := IconGlyph(tt.icon); got != tt.want {
+				t.Errorf("IconGlyph(%q) = %q, want %q", tt.icon, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"golinks/internal/domain"
+)
+
+// MaxImportLinks is the most links accepted by one ImportLinks call
+const MaxImportLinks = 10000
+
+// WithTags lets imports tag the links they create. Without it, importing links with tags
+// fails.
+func WithTags(tags TagRepository) Option {
+	return func(s *LinkService) {
+		s.tagRepo = tags
+	}
+}
+
+// ImportLinks validates links and stores the valid ones in a single transaction, then tags
+// them. Words that already exist are skipped, or with the overwrite policy replaced as an
+// update would, letting admins overwrite links they don't own. A word may appear only once
+// per import, and aliases may point at words imported earlier in it. A dry run validates
+// every link the same way but writes nothing, reporting where each word would point.
+func (s *LinkService) ImportLinks(
+	ctx context.Context, links []domain.ImportLink, policy, userID string, dryRun bool,
+) (*domain.ImportReport, error) {
+
+	switch policy {
+	case domain.ImportSkip, domain.ImportOverwrite:
+	default:
+		return nil, InvalidQueryError{
+			Message: fmt.Sprintf("The conflict policy must be %s or %s", domain.ImportSkip, domain.ImportOverwrite),
+		}
+	}
+	if len(links) == 0 {
+		return nil, InvalidQueryError{Message: "No links given"}
+	}
+	if len(links) > MaxImportLinks {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("At most %d links can be imported at once", MaxImportLinks)}
+	}
+
+	report := &domain.ImportReport{Results: make([]domain.BulkLinkResult, len(links)), DryRun: dryRun}
+	shortcuts := make([]*domain.Shortcut, 0, len(links))
+	replaced := make([]*domain.Shortcut, 0, len(links))
+	stored := make([]int, 0, len(links))
+	tags := make([][]string, len(links))
+	rows := map[string]int{}
+	batchLinks := map[string]string{}
+
+	for i, link := range links {
+		word := NormalizeWord(link.Word)
+		result := &report.Results[i]
+		*result = domain.BulkLinkResult{Index: i, Word: word}
+
+		fail := func(err error) {
+			result.Status = domain.BulkStatusFailed
+			result.Error = err.Error()
+		}
+		if first, ok := rows[word]; ok {
+			fail(InvalidQueryError{Message: fmt.Sprintf("%s is also imported by item %d", word, first)})
+			continue
+		}
+		rows[word] = i
+
+		var err error
+		if tags[i], err = s.importTags(link.Tags); err != nil {
+			fail(err)
+			continue
+		}
+
+		existing, err := s.shortcutRepo.GetByWord(ctx, word)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get shortcut: %w", err)
+		}
+		if existing != nil && policy == domain.ImportSkip {
+			result.Status = domain.BulkStatusSkipped
+			continue
+		}
+
+		req := link.LinkRequest
+		req.Word, req.Force = word, true
+		shortcut, _, err := s.newShortcut(ctx, req, userID, batchLinks)
+		if err != nil {
+			switch err.(type) {
+			case InvalidQueryError, ForbiddenError:
+			default:
+				return nil, err
+			}
+			fail(err)
+			continue
+		}
+
+		result.Status = domain.BulkStatusCreated
+		if existing != nil {
+			result.Status = domain.BulkStatusUpdated
+		}
+		if dryRun {
+			result.Link = shortcut.Link
+		}
+		batchLinks[shortcut.Word] = shortcut.Link
+		shortcuts = append(shortcuts, shortcut)
+		replaced = append(replaced, existing)
+		stored = append(stored, i)
+	}
+
+	if dryRun {
+		shortcuts, stored = nil, nil
+	}
+	if len(shortcuts) > 0 {
+		if err := s.shortcutRepo.CreateBatch(ctx, shortcuts); err != nil {
+			return nil, fmt.Errorf("failed to create shortcuts: %w", err)
+		}
+		s.keywords.Invalidate()
+	}
+
+	for n, i := range stored {
+		for _, tag := range tags[i] {
+			if err := s.tagRepo.AddTag(ctx, shortcuts[n].ID, tag); err != nil {
+				return nil, fmt.Errorf("failed to add tag: %w", err)
+			}
+		}
+		s.notify(userID, replaced[n], shortcuts[n])
+	}
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case domain.BulkStatusCreated:
+			report.Created++
+		case domain.BulkStatusUpdated:
+			report.Updated++
+		case domain.BulkStatusSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// importTags normalizes the tags of an imported link
+func (s *LinkService) importTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	if s.tagRepo == nil {
+		return nil, InvalidQueryError{Message: "Tags cannot be imported on this server"}
+	}
+	if len(tags) > maxTagsPerRequest {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("At most %d tags can be added at once", maxTagsPerRequest)}
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
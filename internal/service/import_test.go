@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_ImportLinks(t *testing.T) {
+	links := []domain.ImportLink{
+		{LinkRequest: domain.LinkRequest{Word: "github", Link: "https://github.com"}, Tags: []string{"Engineering", "code"}},
+		{LinkRequest: domain.LinkRequest{Word: "gh", Link: "github"}},
+		{LinkRequest: domain.LinkRequest{Word: "docs", Link: "https://docs.example.com/v2"}, Tags: []string{"docs"}},
+		{LinkRequest: domain.LinkRequest{Word: "wiki", Link: "https://wiki.example.com"}},
+		{LinkRequest: domain.LinkRequest{Word: "bad", Link: "missing"}},
+		{LinkRequest: domain.LinkRequest{Word: "gh", Link: "https://example.com"}},
+		{LinkRequest: domain.LinkRequest{Word: "tagged", Link: "https://example.com"}, Tags: []string{"not a tag"}},
+		{LinkRequest: domain.LinkRequest{Word: "handed", Link: "https://example.com", Owner: "carol"}},
+	}
+
+	tests := []struct {
+		name       string
+		policy     string
+		user       string
+		dryRun     bool
+		wantStatus []string
+		wantReport domain.ImportReport
+		wantDocs   string
+	}{
+		{
+			name:   "skip",
+			policy: domain.ImportSkip,
+			user:   "alice",
+			wantStatus: []string{
+				domain.BulkStatusCreated, domain.BulkStatusCreated, domain.BulkStatusSkipped, domain.BulkStatusSkipped,
+				domain.BulkStatusFailed, domain.BulkStatusFailed, domain.BulkStatusFailed, domain.BulkStatusFailed,
+			},
+			wantReport: domain.ImportReport{Created: 2, Skipped: 2, Failed: 4},
+			wantDocs:   "https://docs.example.com",
+		},
+		{
+			name:   "overwrite as the owner",
+			policy: domain.ImportOverwrite,
+			user:   "alice",
+			wantStatus: []string{
+				domain.BulkStatusCreated, domain.BulkStatusCreated, domain.BulkStatusUpdated, domain.BulkStatusFailed,
+				domain.BulkStatusFailed, domain.BulkStatusFailed, domain.BulkStatusFailed, domain.BulkStatusFailed,
+			},
+			wantReport: domain.ImportReport{Created: 2, Updated: 1, Failed: 5},
+			wantDocs:   "https://docs.example.com/v2",
+		},
+		{
+			name:   "overwrite as an admin",
+			policy: domain.ImportOverwrite,
+			user:   "admin",
+			wantStatus: []string{
+				domain.BulkStatusCreated, domain.BulkStatusCreated, domain.BulkStatusUpdated, domain.BulkStatusUpdated,
+				domain.BulkStatusFailed, domain.BulkStatusFailed, domain.BulkStatusFailed, domain.BulkStatusCreated,
+			},
+			wantReport: domain.ImportReport{Created: 3, Updated: 2, Failed: 3},
+			wantDocs:   "https://docs.example.com/v2",
+		},
+		{
+			name:   "dry run",
+			policy: domain.ImportOverwrite,
+			user:   "admin",
+			dryRun: true,
+			wantStatus: []string{
+				domain.BulkStatusCreated, domain.BulkStatusCreated, domain.BulkStatusUpdated, domain.BulkStatusUpdated,
+				domain.BulkStatusFailed, domain.BulkStatusFailed, domain.BulkStatusFailed, domain.BulkStatusCreated,
+			},
+			wantReport: domain.ImportReport{Created: 3, Updated: 2, Failed: 3, DryRun: true},
+			wantDocs:   "https://docs.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+				"wiki": {ID: 2, Word: "wiki", Link: "https://old-wiki.example.com", User: "bob"},
+			}}
+			tagRepo := &mockTagRepository{shortcuts: shortcutRepo, tags: map[string]map[string]bool{}}
+			service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAdmins([]string{"admin"}), WithTags(tagRepo))
+
+			report, err := service.ImportLinks(context.Background(), links, tt.policy, tt.user, tt.dryRun)
+			if err != nil {
+				t.Fatalf("LinkService.ImportLinks() error = %v", err)
+			}
+
+			for i, result := range report.Results {
+				if result.Index != i || result.Status != tt.wantStatus[i] {
+					t.Errorf("result %d = %+v, want status %s", i, result, tt.wantStatus[i])
+				}
+				if (result.Status == domain.BulkStatusFailed) != (result.Error != "") {
+					t.Errorf("result %d = %+v, want an error only when it failed", i, result)
+				}
+				if wantLink := tt.dryRun && result.Error == ""; wantLink != (result.Link != "") {
+					t.Errorf("result %d = %+v, want a link only when a dry run would store it", i, result)
+				}
+			}
+			counts := *report
+			counts.Results = nil
+			if !reflect.DeepEqual(counts, tt.wantReport) {
+				t.Errorf("LinkService.ImportLinks() counts = %+v, want %+v", counts, tt.wantReport)
+			}
+
+			if got := shortcutRepo.shortcuts["docs"].Link; got != tt.wantDocs {
+				t.Errorf("docs links to %s, want %s", got, tt.wantDocs)
+			}
+			if tt.dryRun {
+				if len(shortcutRepo.shortcuts) != 2 || len(tagRepo.tags) != 0 {
+					t.Errorf("dry run stored %d links and %d tags, want none", len(shortcutRepo.shortcuts)-2, len(tagRepo.tags))
+				}
+				if got := report.Results[0].Link; got != "https://github.com" {
+					t.Errorf("dry run links github to %s, want https://github.com", got)
+				}
+				return
+			}
+			if got, _ := tagRepo.GetTagsByWord(context.Background(), "github"); !reflect.DeepEqual(got, []string{"code", "engineering"}) {
+				t.Errorf("github tags = %v, want code and engineering", got)
+			}
+			if handed := shortcutRepo.shortcuts["handed"]; handed != nil && handed.User != "carol" {
+				t.Errorf("handed is owned by %s, want carol", handed.User)
+			}
+		})
+	}
+}
+
+func TestLinkService_ImportLinks_Errors(t *testing.T) {
+	link := domain.ImportLink{LinkRequest: domain.LinkRequest{Word: "docs", Link: "https://docs.example.com"}}
+
+	tests := []struct {
+		name    string
+		links   []domain.ImportLink
+		policy  string
+		wantErr string
+	}{
+		{name: "unknown policy", links: []domain.ImportLink{link}, policy: "merge", wantErr: "conflict policy"},
+		{name: "no links", policy: domain.ImportSkip, wantErr: "No links given"},
+		{name: "too many", links: make([]domain.ImportLink, MaxImportLinks+1), policy: domain.ImportSkip, wantErr: "At most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewLinkService(&mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}, &mockQueryRepository{})
+			_, err := service.ImportLinks(context.Background(), tt.links, tt.policy, "alice", false)
+			if _, ok := err.(InvalidQueryError); !ok || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LinkService.ImportLinks() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Without a tag repository, links with tags fail and the rest are imported
+	service := NewLinkService(&mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}, &mockQueryRepository{})
+	tagged := link
+	tagged.Word, tagged.Tags = "tagged", []string{"docs"}
+	report, err := service.ImportLinks(context.Background(), []domain.ImportLink{link, tagged}, domain.ImportSkip, "alice", false)
+	if err != nil {
+		t.Fatalf("LinkService.ImportLinks() error = %v", err)
+	}
+	if report.Created != 1 || report.Failed != 1 || !strings.Contains(report.Results[1].Error, "Tags cannot be imported") {
+		t.Errorf("LinkService.ImportLinks() without tags = %+v, want the tagged link to fail", report)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// keywordCacheEntries bounds how many keyword lists a KeywordCache holds at once
+const keywordCacheEntries = 1000
+
+// KeywordCache keeps the keyword lists the homepage and API serve in memory, so rendering
+// them doesn't query every link. Lists are cached against the keywords version, which is
+// itself read again once its TTL passes; services that change links or tags invalidate
+// the cache straight away. Clicks change no version, so lists are also read again once
+// their TTL passes, keeping their click counts at most that far behind. A nil
+// KeywordCache caches nothing.
+type KeywordCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu             sync.Mutex
+	version        string
+	versionExpires time.Time
+	entries        map[string]keywordEntry
+
+	// generation counts invalidations, so a load that raced one doesn't cache what it read
+	generation uint64
+
+	hits   int64
+	misses int64
+}
+
+// keywordEntry is a cached keyword list and when it is read again
+type keywordEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewKeywordCache caches keyword lists, reading them and the keywords version again after ttl
+func NewKeywordCache(ttl time.Duration) *KeywordCache {
+	return &KeywordCache{ttl: ttl, now: time.Now, entries: map[string]keywordEntry{}}
+}
+
+// WithKeywordCache caches the keyword lists LinkService serves, invalidating them when it
+// changes a link
+func WithKeywordCache(cache *KeywordCache) Option {
+	return func(s *LinkService) {
+		s.keywords = cache
+	}
+}
+
+// Invalidate drops every cached keyword list
+func (c *KeywordCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.version = ""
+	c.entries = map[string]keywordEntry{}
+}
+
+// Stats reports how full the cache is and how often lookups hit it
+func (c *KeywordCache) Stats() domain.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return domain.CacheStats{
+		Name:     "keywords",
+		Entries:  len(c.entries),
+		Capacity: keywordCacheEntries,
+		Hits:     c.hits,
+		Misses:   c.misses,
+	}
+}
+
+// currentVersion returns the cached keywords version, reading it with load once it expires.
+// Lists cached against an older version are dropped.
+func (c *KeywordCache) currentVersion(ctx context.Context, load func(context.Context) (string, error)) (string, error) {
+	c.mu.Lock()
+	if c.version != "" && c.now().Before(c.versionExpires) {
+		version := c.version
+		c.mu.Unlock()
+		return version, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	version, err := load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		if version != c.version {
+			c.entries = map[string]keywordEntry{}
+		}
+		c.version = version
+		c.versionExpires = c.now().Add(c.ttl)
+	}
+
+	return version, nil
+}
+
+// lookup returns the list cached under key, loading and caching it when it isn't or its
+// TTL has passed
+func (c *KeywordCache) lookup(key string, load func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && c.now().Before(entry.expires) {
+		c.hits++
+		c.mu.Unlock()
+		return entry.value, nil
+	}
+	c.misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		if len(c.entries) >= keywordCacheEntries {
+			c.entries = map[string]keywordEntry{}
+		}
+		c.entries[key] = keywordEntry{value: value, expires: c.now().Add(c.ttl)}
+	}
+
+	return value, nil
+}
+
+// keywordsVersion returns the version of the keyword lists, from the cache if there is one
+func (s *LinkService) keywordsVersion(ctx context.Context) (string, error) {
+	if s.keywords == nil {
+		return s.shortcutRepo.GetKeywordsVersion(ctx)
+	}
+	return s.keywords.currentVersion(ctx, s.shortcutRepo.GetKeywordsVersion)
+}
+
+// cachedKeywords returns the keyword list load builds, from the cache if there is one
+func (s *LinkService) cachedKeywords(
+	ctx context.Context, key string, load func() (interface{}, error),
+) (interface{}, error) {
+
+	if s.keywords == nil {
+		return load()
+	}
+	// Reading the version drops lists it has outdated
+	if _, err := s.keywordsVersion(ctx); err != nil {
+		return nil, err
+	}
+	return s.keywords.lookup(key, load)
+}
+
+// keywordPageKey identifies a page of ListKeywords in the cache
+func keywordPageKey(search, sort string, limit, offset int, userID string) string {
+	return "page\x00" + userID + "\x00" + search + "\x00" + sort + "\x00" + strconv.Itoa(limit) + "\x00" + strconv.Itoa(offset)
+}
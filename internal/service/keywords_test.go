@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// keywordCountingRepository counts the keyword list queries that reach the repository
+type keywordCountingRepository struct {
+	*mockShortcutRepository
+	pages    int
+	all      int
+	versions int
+}
+
+func (r *keywordCountingRepository) GetKeywordsPage(
+	ctx context.Context, targetPrefixes []string, search, sort, viewer string, limit, offset int,
+) ([]domain.KeywordInfo, int, error) {
+	r.pages++
+	return r.mockShortcutRepository.GetKeywordsPage(ctx, targetPrefixes, search, sort, viewer, limit, offset)
+}
+
+func (r *keywordCountingRepository) GetAllKeywords(ctx context.Context, viewer string) ([]domain.KeywordInfo, error) {
+	r.all++
+	return r.mockShortcutRepository.GetAllKeywords(ctx, viewer)
+}
+
+func (r *keywordCountingRepository) GetKeywordsVersion(ctx context.Context) (string, error) {
+	r.versions++
+	return r.mockShortcutRepository.GetKeywordsVersion(ctx)
+}
+
+func TestLinkService_KeywordCache(t *testing.T) {
+	repo := &keywordCountingRepository{mockShortcutRepository: &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}}
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewKeywordCache(5 * time.Second)
+	cache.now = func() time.Time { return now }
+	service := NewLinkService(repo, &mockQueryRepository{}, WithKeywordCache(cache))
+	ctx := context.Background()
+
+	list := func(userID string) *domain.KeywordPage {
+		t.Helper()
+		page, err := service.ListKeywords(ctx, "", "", 0, 0, userID)
+		if err != nil {
+			t.Fatalf("LinkService.ListKeywords() error = %v", err)
+		}
+		return page
+	}
+
+	tests := []struct {
+		name         string
+		change       func()
+		userID       string
+		wantTotal    int
+		wantPages    int
+		wantVersions int
+	}{
+		{name: "first render", userID: "alice", wantTotal: 1, wantPages: 1, wantVersions: 1},
+		{name: "cached", userID: "alice", wantTotal: 1, wantPages: 1, wantVersions: 1},
+		{name: "another viewer", userID: "bob", wantTotal: 1, wantPages: 2, wantVersions: 1},
+		{
+			name: "a link created through the service",
+			change: func() {
+				if err := service.UpdateLink(ctx, domain.LinkRequest{Word: "github", Link: "https://github.com"}, "alice"); err != nil {
+					t.Fatalf("LinkService.UpdateLink() error = %v", err)
+				}
+			},
+			userID: "alice", wantTotal: 2, wantPages: 3, wantVersions: 2,
+		},
+		{
+			name: "a link created elsewhere, within the TTL",
+			change: func() {
+				repo.shortcuts["wiki"] = &domain.Shortcut{ID: 3, Word: "wiki", Link: "https://wiki.example.com", User: "bob"}
+			},
+			userID: "alice", wantTotal: 2, wantPages: 3, wantVersions: 2,
+		},
+		{
+			name:   "a link created elsewhere, after the TTL",
+			change: func() { now = now.Add(5 * time.Second) },
+			userID: "alice", wantTotal: 3, wantPages: 4, wantVersions: 3,
+		},
+		{
+			name:   "unchanged within the TTL",
+			change: func() { now = now.Add(time.Second) },
+			userID: "alice", wantTotal: 3, wantPages: 4, wantVersions: 3,
+		},
+		{
+			name:   "unchanged after the TTL, for fresh click counts",
+			change: func() { now = now.Add(5 * time.Second) },
+			userID: "alice", wantTotal: 3, wantPages: 5, wantVersions: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.change != nil {
+				tt.change()
+			}
+			page := list(tt.userID)
+			if page.Total != tt.wantTotal || repo.pages != tt.wantPages || repo.versions != tt.wantVersions {
+				t.Errorf("LinkService.ListKeywords() total = %d after %d page and %d version queries, want %d after %d and %d",
+					page.Total, repo.pages, repo.versions, tt.wantTotal, tt.wantPages, tt.wantVersions)
+			}
+		})
+	}
+
+	// Callers can't change the cached page
+	page := list("alice")
+	page.Keywords[0].Word = "changed"
+	if page := list("alice"); page.Keywords[0].Word == "changed" {
+		t.Error("LinkService.ListKeywords() returned a page changed by an earlier caller")
+	}
+
+	// The ETag comes from the cached version, so it matches the cached lists
+	if _, err := service.KeywordsETag(ctx, "alice"); err != nil || repo.versions != 4 {
+		t.Errorf("LinkService.KeywordsETag() error = %v after %d version queries, want the cached version", err, repo.versions)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.GetAllKeywords(ctx, "alice"); err != nil {
+			t.Fatalf("LinkService.GetAllKeywords() error = %v", err)
+		}
+	}
+	if repo.all != 1 {
+		t.Errorf("LinkService.GetAllKeywords() queried the repository %d times, want 1", repo.all)
+	}
+
+	// Clicks reorder the most used keywords, so those pages aren't cached
+	for i := 0; i < 2; i++ {
+		if _, err := service.ListKeywords(ctx, "", domain.KeywordSortMostUsed, 0, 0, "alice"); err != nil {
+			t.Fatalf("LinkService.ListKeywords() error = %v", err)
+		}
+	}
+	if repo.pages != 7 {
+		t.Errorf("LinkService.ListKeywords() by most used queried the repository %d times, want 2", repo.pages-5)
+	}
+
+	stats := service.CacheStats()
+	if len(stats) != 1 || stats[0].Name != "keywords" || stats[0].Entries != 2 || stats[0].Hits != 6 {
+		t.Errorf("LinkService.CacheStats() = %+v, want the keywords cache with 2 entries and 6 hits", stats)
+	}
+}
+
+func TestTagService_KeywordCache(t *testing.T) {
+	repo := &keywordCountingRepository{mockShortcutRepository: &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}}
+	cache := NewKeywordCache(time.Hour)
+	links := NewLinkService(repo, &mockQueryRepository{}, WithKeywordCache(cache))
+	tags := NewTagService(&mockTagRepository{shortcuts: repo.mockShortcutRepository, tags: map[string]map[string]bool{}}, links,
+		WithTagKeywordCache(cache))
+	ctx := context.Background()
+
+	steps := []struct {
+		name      string
+		change    func() error
+		wantPages int
+	}{
+		{"first render", nil, 1},
+		{"tag added", func() error { _, err := tags.AddTags(ctx, "docs", []string{"how-to"}, "alice"); return err }, 2},
+		{"tag removed", func() error { return tags.RemoveTag(ctx, "docs", "how-to", "alice") }, 3},
+		{"missing tag", func() error { _ = tags.RemoveTag(ctx, "docs", "how-to", "alice"); return nil }, 3},
+	}
+
+	for _, step := range steps {
+		if step.change != nil {
+			if err := step.change(); err != nil {
+				t.Fatalf("%s: error = %v", step.name, err)
+			}
+		}
+		if _, err := links.ListKeywords(ctx, "", "", 0, 0, "alice"); err != nil {
+			t.Fatalf("%s: LinkService.ListKeywords() error = %v", step.name, err)
+		}
+		if repo.pages != step.wantPages {
+			t.Errorf("%s: %d page queries, want %d", step.name, repo.pages, step.wantPages)
+		}
+	}
+}
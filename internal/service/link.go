@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,27 +18,131 @@ import (
 type ShortcutRepository interface {
 	GetByWord(ctx context.Context, word string) (*domain.Shortcut, error)
 	Create(ctx context.Context, shortcut *domain.Shortcut) error
-	GetAllKeywords(ctx context.Context) ([]domain.KeywordInfo, error)
+	GetAllKeywords(ctx context.Context, viewer string) ([]domain.KeywordInfo, error)
+	GetKeywordsByOwner(ctx context.Context, owner string) ([]domain.KeywordInfo, error)
+	GetKeywordsPage(
+		ctx context.Context, targetPrefixes []string, search, sort, viewer string, limit, offset int,
+	) ([]domain.KeywordInfo, int, error)
+	GetKeywordsVersion(ctx context.Context) (string, error)
+	GetKeywordsByPrefix(ctx context.Context, prefix, viewer string, limit int) ([]domain.KeywordInfo, error)
+	DeleteByWord(ctx context.Context, word string) (int64, error)
+	GetDeleted(ctx context.Context) ([]domain.TrashedLink, error)
+	RestoreByWord(ctx context.Context, word string) (int64, error)
+	PurgeByWord(ctx context.Context, word string) (int64, error)
+	GetByID(ctx context.Context, id int) (*domain.Shortcut, error)
+	GetHistory(ctx context.Context, word string) ([]domain.Shortcut, error)
+	GetAllVersions(ctx context.Context) ([]domain.Shortcut, error)
+	GetVersionsSince(ctx context.Context, since, limit int) ([]domain.Shortcut, error)
+	GetCreated(ctx context.Context, viewer string, before, limit int) ([]domain.Shortcut, error)
+	GetWords(ctx context.Context) ([]string, error)
+	CreateBatch(ctx context.Context, shortcuts []*domain.Shortcut) error
 }
 
 // QueryRepository interface for query operations
 type QueryRepository interface {
-	Create(ctx context.Context, wordID int) error
+	Create(ctx context.Context, query *domain.Query) error
+	CreateBatch(ctx context.Context, queries []*domain.Query) error
 	GetRecentQueries(ctx context.Context, timeWindowDays, numResults int) ([]domain.PopularQuery, error)
+	GetUserTopLinks(ctx context.Context, user string, since time.Time, limit int) ([]domain.PopularQuery, error)
+	GetStaleLinks(ctx context.Context, since time.Time) ([]domain.StaleLink, error)
+	GetDailyClicks(ctx context.Context, word string, since time.Time) ([]domain.ClickCount, error)
+	GetTopReferrers(ctx context.Context, word string, since time.Time, limit int) ([]domain.ReferrerCount, error)
+	PruneBefore(ctx context.Context, before time.Time) (int64, error)
 }
 
+// Keyword list page sizes used by ListKeywords
+const (
+	DefaultKeywordPageSize = 100
+	MaxKeywordPageSize     = 1000
+
+	// maxSearchLength bounds the search term accepted by ListKeywords
+	maxSearchLength = 200
+)
+
+// MaxBulkLinks is the largest batch accepted by BulkUpdateLinks
+const MaxBulkLinks = 1000
+
+// Popular query windows and list sizes used by GetRecentQueries
+const (
+	DefaultPopularDays  = 3
+	DefaultPopularLimit = 20
+	MaxPopularDays      = 365
+	MaxPopularLimit     = 100
+)
+
 // LinkService handles business logic for golinks
 type LinkService struct {
 	shortcutRepo ShortcutRepository
 	queryRepo    QueryRepository
+	iconsEnabled bool
+
+	// allowedSchemes holds non-HTTP schemes permitted as link targets
+	allowedSchemes map[string]bool
+
+	// admins may modify golinks owned by other users
+	admins AdminChecker
+
+	// namespaces limits who may edit words under a team namespace, like payments/runbook
+	namespaces NamespaceChecker
+
+	// now is the clock click stats are bucketed against and {date} and {week} are read from
+	now func() time.Time
+
+	// tagRepo tags imported links and reads the tags of exported ones, or is nil if imports
+	// can't tag them and exports leave tags out
+	tagRepo TagRepository
+
+	// keywords caches the keyword lists, and is invalidated whenever a link changes
+	keywords *KeywordCache
+
+	// queryLog writes followed golinks in the background, or is nil to write them before
+	// redirecting
+	queryLog *QueryLog
+
+	// clicks feeds followed golinks to the live stats stream
+	clicks *clickFeed
+
+	// queryRetentionDays is how many days of the query log PruneQueries keeps, or 0 to keep it all
+	queryRetentionDays int
+
+	// notifier announces changes to public golinks to a channel, or is nil to announce none
+	notifier *Notifier
+
+	// domains limits the domains links may point to, or is nil to allow any
+	domains *DomainPolicy
+
+	// linkChecker requests the targets of links as they are saved, or is nil to trust them
+	linkChecker LinkChecker
+
+	// wordRules are the syntax new keywords must follow, or nil to allow any
+	wordRules *WordRules
+
+	// aliases stores the other words golinks answer to, or is nil if they have none
+	aliases AliasRepository
+}
+
+// Option configures optional LinkService behaviour
+type Option func(*LinkService)
+
+// WithIcons enables storing an icon or emoji alongside each shortcut
+func WithIcons(enabled bool) Option {
+	return func(s *LinkService) {
+		s.iconsEnabled = enabled
+	}
 }
 
 // NewLinkService creates a new link service
-func NewLinkService(shortcutRepo ShortcutRepository, queryRepo QueryRepository) *LinkService {
-	return &LinkService{
+func NewLinkService(shortcutRepo ShortcutRepository, queryRepo QueryRepository, opts ...Option) *LinkService {
+	s := &LinkService{
 		shortcutRepo: shortcutRepo,
 		queryRepo:    queryRepo,
+		now:          time.Now,
+		clicks:       newClickFeed(time.Now),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // InvalidQueryError represents an error when a query cannot be resolved
@@ -46,93 +154,641 @@ func (e InvalidQueryError) Error() string {
 	return e.Message
 }
 
-// GetLink resolves a golink query to a URL
-func (s *LinkService) GetLink(ctx context.Context, word string, searchTerm string) (string, error) {
+// AliasLoopError represents an error when a query's aliases loop or chain through too
+// many aliases to be followed. Chain lists the words passed through, in order.
+type AliasLoopError struct {
+	Message string
+	Chain   []string
+}
+
+func (e AliasLoopError) Error() string {
+	return e.Message
+}
+
+// ArchivedError represents an error when a query leads to a golink that has been archived.
+// Shortcut is the archived version, saying who archived it and when.
+type ArchivedError struct {
+	Message  string
+	Shortcut *domain.Shortcut
+}
+
+func (e ArchivedError) Error() string {
+	return e.Message
+}
+
+// NotFoundError represents an error when a golink does not exist
+type NotFoundError struct {
+	Message string
+}
 
-	word = strings.TrimSpace(word)
+func (e NotFoundError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError represents an error when a user may not modify a golink
+type ForbiddenError struct {
+	Message string
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Message
+}
+
+// UnauthorizedError represents an error when a request's credentials are missing or invalid
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e UnauthorizedError) Error() string {
+	return e.Message
+}
+
+// GetLink resolves a golink query to a URL for userID, who alone can resolve their private links
+func (s *LinkService) GetLink(ctx context.Context, word, searchTerm, userID string) (string, error) {
+	res := &domain.Resolution{Query: strings.TrimSpace(strings.Join([]string{word, searchTerm}, " "))}
+	if err := s.resolve(ctx, word, searchTerm, userID, true, res, map[string]int{}); err != nil {
+		return "", err
+	}
+	return res.URL, nil
+}
+
+// ResolveDetail resolves a query and reports how the target was reached
+func (s *LinkService) ResolveDetail(
+	ctx context.Context, query string, logQuery bool, userID string,
+) (*domain.Resolution, error) {
+
+	res := &domain.Resolution{Query: strings.TrimSpace(query)}
+	if err := s.resolve(ctx, query, "", userID, logQuery, res, map[string]int{}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// resolve follows a query through aliases as seen by userID, filling res as it goes. seen
+// holds the aliases passed through so far, numbered in the order they were.
+func (s *LinkService) resolve(
+	ctx context.Context, word, searchTerm, userID string, logQuery bool, res *domain.Resolution,
+	seen map[string]int,
+) error {
+
+	word = NormalizeWord(word)
 
 	shortcut, err := s.shortcutRepo.GetByWord(ctx, word)
 	if err != nil {
-		return "", fmt.Errorf("failed to get shortcut: %w", err)
+		return fmt.Errorf("failed to get shortcut: %w", err)
 	}
 
+	// An alias resolves as the golink it stands for
 	if shortcut == nil {
+		if shortcut, err = s.aliasedShortcut(ctx, word); err != nil {
+			return err
+		}
+		if visibleTo(shortcut, userID) && res.Word == "" {
+			res.Alias = word
+		}
+	}
+
+	// Other users' private links behave as if they don't exist
+	if !visibleTo(shortcut, userID) {
 		// Try splitting the word if it contains spaces
 		if strings.Contains(word, " ") {
 			newWord, newSearchTerm := moveLastWord(word, searchTerm)
-			return s.GetLink(ctx, newWord, newSearchTerm)
+			return s.resolve(ctx, newWord, newSearchTerm, userID, logQuery, res, seen)
 		}
 
-		return "", InvalidQueryError{
-			Message: fmt.Sprintf("Unable to find link for query %s", strings.Join([]string{word, searchTerm}, " ")),
+		// Paths below a prefix link are appended to its target, like repo/issues/123
+		prefix, path, err := s.prefixLink(ctx, word, userID)
+		if err != nil {
+			return err
+		}
+		if prefix == nil {
+			// Extra path segments after a namespaced word are search terms, like payments/runbook/2024
+			newWord, newSearchTerm, err := s.moveLastSegment(ctx, word, searchTerm)
+			if err != nil {
+				return err
+			}
+			if newWord != word {
+				return s.resolve(ctx, newWord, newSearchTerm, userID, logQuery, res, seen)
+			}
+
+			return InvalidQueryError{
+				Message: fmt.Sprintf("Unable to find link for query %s", strings.Join([]string{word, searchTerm}, " ")),
+			}
 		}
+		shortcut, res.Path = prefix, path
+	}
+
+	if shortcut.Archived {
+		return ArchivedError{Message: fmt.Sprintf("%s has been archived", shortcut.Word), Shortcut: shortcut}
+	}
+
+	if res.Word == "" {
+		res.Word = shortcut.Word
+		res.SearchTerm = searchTerm
+		res.Owner = shortcut.User
 	}
 
 	// Log the query
-	if err := s.queryRepo.Create(ctx, shortcut.ID); err != nil {
-		// Log error but don't fail the request
-		// In a production system, you might want to log this error
-		_ = err
+	if logQuery {
+		query := &domain.Query{
+			WordID:    shortcut.ID,
+			User:      userID,
+			Referrer:  referrerFrom(ctx),
+			Client:    clientFrom(ctx),
+			CreatedAt: s.now().UTC(),
+		}
+		if s.queryLog != nil {
+			s.queryLog.Log(query)
+		} else if err := s.queryRepo.Create(ctx, query); err != nil {
+			// Log error but don't fail the request
+			// In a production system, you might want to log this error
+			_ = err
+		}
+		if !shortcut.Private {
+			s.clicks.publish(domain.ClickEvent{
+				Word:     shortcut.Word,
+				Referrer: query.Referrer,
+				Client:   query.Client,
+				Time:     query.CreatedAt,
+			})
+		}
 	}
 
 	// Handle different types of links
-	if !isURL(shortcut.Link) {
+	if !s.isTarget(shortcut.Link) {
 		// This is an alias, recurse
-		return s.GetLink(ctx, shortcut.Link, searchTerm)
+		if err := followAlias(res, seen, shortcut.Word); err != nil {
+			return err
+		}
+		target, err := s.aliasTarget(ctx, shortcut.Word, shortcut.Link, userID)
+		if err != nil {
+			return err
+		}
+		return s.resolve(ctx, target, searchTerm, userID, logQuery, res, seen)
 	}
 
 	// Process URL with search term substitution
-	resultLink := processResultLink(shortcut.Link, searchTerm)
-	return resultLink, nil
+	res.ResolvedWord = shortcut.Word
+	res.Substituted = hasPlaceholders(shortcut.Link)
+	res.URL = processResultLink(expandVariables(shortcut.Link, userID, s.now()), searchTerm)
+	if res.Path != "" {
+		res.URL = appendPath(res.URL, res.Path)
+	}
+	return nil
 }
 
 // UpdateLink creates or updates a golink
 func (s *LinkService) UpdateLink(ctx context.Context, req domain.LinkRequest, userID string) error {
 
+	shortcut, existing, err := s.newShortcut(ctx, req, userID, nil)
+	if err != nil {
+		return err
+	}
+	if existing == nil || existing.Link != shortcut.Link {
+		if err := s.checkTarget(ctx, shortcut.Link); err != nil {
+			return err
+		}
+	}
+
+	if err := s.shortcutRepo.Create(ctx, shortcut); err != nil {
+		return fmt.Errorf("failed to create shortcut: %w", err)
+	}
+	s.keywords.Invalidate()
+	s.notify(userID, existing, shortcut)
+
+	return nil
+}
+
+// BulkUpdateLinks validates a batch of link requests and stores the valid ones in a single
+// transaction. Aliases may point at words created earlier in the same batch.
+func (s *LinkService) BulkUpdateLinks(
+	ctx context.Context, reqs []domain.LinkRequest, userID string,
+) ([]domain.BulkLinkResult, error) {
+
+	if len(reqs) == 0 {
+		return nil, InvalidQueryError{Message: "No links given"}
+	}
+	if len(reqs) > MaxBulkLinks {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("At most %d links can be created at once", MaxBulkLinks)}
+	}
+
+	results := make([]domain.BulkLinkResult, len(reqs))
+	shortcuts := make([]*domain.Shortcut, 0, len(reqs))
+	replaced := make([]*domain.Shortcut, 0, len(reqs))
+	created := make([]int, 0, len(reqs))
+	batchLinks := map[string]string{}
+
+	for i, req := range reqs {
+		results[i] = domain.BulkLinkResult{Index: i, Word: req.Word}
+
+		shortcut, existing, err := s.newShortcut(ctx, req, userID, batchLinks)
+		if err != nil {
+			switch err.(type) {
+			case InvalidQueryError, ForbiddenError:
+			default:
+				return nil, err
+			}
+			results[i].Status = domain.BulkStatusFailed
+			results[i].Error = err.Error()
+			continue
+		}
+
+		batchLinks[shortcut.Word] = shortcut.Link
+		shortcuts = append(shortcuts, shortcut)
+		replaced = append(replaced, existing)
+		created = append(created, i)
+	}
+
+	if len(shortcuts) > 0 {
+		if err := s.shortcutRepo.CreateBatch(ctx, shortcuts); err != nil {
+			return nil, fmt.Errorf("failed to create shortcuts: %w", err)
+		}
+		s.keywords.Invalidate()
+	}
+	for n, shortcut := range shortcuts {
+		s.notify(userID, replaced[n], shortcut)
+	}
+
+	for _, i := range created {
+		results[i].Status = domain.BulkStatusCreated
+	}
+
+	return results, nil
+}
+
+// newShortcut validates a link request and builds the shortcut to store, returning it with
+// the current version it replaces, if any. batchLinks holds the links of words created
+// earlier in the same bulk request, which aliases may point at.
+func (s *LinkService) newShortcut(
+	ctx context.Context, req domain.LinkRequest, userID string, batchLinks map[string]string,
+) (*domain.Shortcut, *domain.Shortcut, error) {
+
 	// Validate the request
+	req.Word = NormalizeWord(req.Word)
 	if err := s.validateLinkRequest(ctx, req); err != nil {
+		return nil, nil, err
+	}
+
+	// Only members can add to a namespace
+	word := strings.TrimSpace(req.Word)
+	namespace, member, err := s.namespaceMember(ctx, word, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if namespace != "" {
+		if err := s.checkNamespacedWord(ctx, word, namespace, member, userID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// If the link is not a URL, validate it's a valid alias that doesn't loop
+	if !s.isTarget(req.Link) {
+		target := req.Link
+		_, inBatch := batchLinks[target]
+		if _, ok := batchLinks[namespace+"/"+target]; namespace != "" && ok {
+			target, inBatch = namespace+"/"+target, true
+		}
+		if !inBatch {
+			if target, err = s.aliasTarget(ctx, word, req.Link, userID); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := s.checkAlias(ctx, word, target, userID, batchLinks); err != nil {
+			return nil, nil, err
+		}
+		if !inBatch {
+			found, err := s.aliasResolves(ctx, target, userID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !found {
+				return nil, nil, InvalidQueryError{
+					Message: fmt.Sprintf("%s is neither a URL nor a keyword %s can point to", req.Link, word),
+				}
+			}
+		}
+	}
+
+	existing, err := s.shortcutRepo.GetByWord(ctx, req.Word)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	if existing == nil {
+		aliased, err := s.aliasedShortcut(ctx, req.Word)
+		if err != nil {
+			return nil, nil, err
+		}
+		if visibleTo(aliased, userID) {
+			return nil, nil, InvalidQueryError{
+				Message: fmt.Sprintf("%s is an alias of %s; remove the alias before reusing the word", req.Word, aliased.Word),
+			}
+		}
+		if aliased != nil {
+			return nil, nil, InvalidQueryError{Message: fmt.Sprintf("%s is already taken", req.Word)}
+		}
+	}
+	// Keywords from before the rules changed can still be edited
+	if existing == nil {
+		if err := s.wordRules.check(req.Word); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	owner, err := s.ownerFor(ctx, existing, req, userID, member)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shortcut := &domain.Shortcut{
+		Word:        req.Word,
+		Link:        req.Link,
+		User:        owner,
+		Description: strings.TrimSpace(req.Description),
+		UpdatedBy:   userID,
+		Private:     req.Private,
+		Prefix:      req.Prefix,
+		Archived:    req.Archived,
+		Metadata:    req.Metadata,
+		CreatedAt:   time.Now(),
+	}
+	if s.iconsEnabled {
+		shortcut.Icon = strings.TrimSpace(req.Icon)
+	}
+	// Metadata is usually kept up by other tools, so saving a link without it leaves it be;
+	// sending an empty object clears it
+	if req.Metadata == nil && existing != nil {
+		shortcut.Metadata = existing.Metadata
+	} else if len(req.Metadata) == 0 {
+		shortcut.Metadata = nil
+	}
+
+	return shortcut, existing, nil
+}
+
+// DeleteLink moves a golink and all of its versions to the trash; only the owner may
+// delete it
+func (s *LinkService) DeleteLink(ctx context.Context, word string, userID string) error {
+	word = NormalizeWord(word)
+
+	shortcut, err := s.modifiableShortcut(ctx, word, userID, "delete")
+	if err != nil {
 		return err
 	}
 
-	// If the link is not a URL, validate it's a valid alias
-	if !isURL(req.Link) {
-		_, err := s.GetLink(ctx, req.Link, "")
+	if _, err := s.shortcutRepo.DeleteByWord(ctx, word); err != nil {
+		return fmt.Errorf("failed to delete shortcut: %w", err)
+	}
+	s.keywords.Invalidate()
+	s.notify(userID, shortcut, nil)
+
+	return nil
+}
+
+// ListTrash returns the deleted golinks that can still be restored, most recently
+// deleted first
+func (s *LinkService) ListTrash(ctx context.Context) ([]domain.TrashedLink, error) {
+	trash, err := s.shortcutRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+	if trash == nil {
+		trash = []domain.TrashedLink{}
+	}
+
+	return trash, nil
+}
+
+// RestoreLink takes a deleted golink back out of the trash for userID, unless the word has
+// been reused since it was deleted
+func (s *LinkService) RestoreLink(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	word = NormalizeWord(word)
+
+	current, err := s.shortcutRepo.GetByWord(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	if current != nil {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("%s already exists; delete it before restoring the trashed one", word)}
+	}
+	aliased, err := s.aliasedShortcut(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+	if aliased != nil {
+		return nil, InvalidQueryError{
+			Message: fmt.Sprintf("%s is now an alias of %s; remove the alias before restoring the trashed one", word, aliased.Word),
+		}
+	}
+
+	restored, err := s.shortcutRepo.RestoreByWord(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore shortcut: %w", err)
+	}
+	if restored == 0 {
+		return nil, NotFoundError{Message: fmt.Sprintf("No deleted golink found for %s", word)}
+	}
+	s.keywords.Invalidate()
+
+	shortcut, err := s.shortcutRepo.GetByWord(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	s.notify(userID, nil, shortcut)
+
+	return shortcut, nil
+}
+
+// PurgeLink permanently removes a deleted golink from the trash
+func (s *LinkService) PurgeLink(ctx context.Context, word string) error {
+	word = NormalizeWord(word)
+
+	purged, err := s.shortcutRepo.PurgeByWord(ctx, word)
+	if err != nil {
+		return fmt.Errorf("failed to purge shortcut: %w", err)
+	}
+	if purged == 0 {
+		return NotFoundError{Message: fmt.Sprintf("No deleted golink found for %s", word)}
+	}
+	s.keywords.Invalidate()
+
+	return nil
+}
+
+// GetShortcut returns the current version of a golink as seen by userID
+func (s *LinkService) GetShortcut(ctx context.Context, word, userID string) (*domain.Shortcut, error) {
+	word = NormalizeWord(word)
+
+	shortcut, err := s.shortcutRepo.GetByWord(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	if !visibleTo(shortcut, userID) {
+		return nil, NotFoundError{Message: fmt.Sprintf("No golink found for %s", word)}
+	}
+
+	return shortcut, nil
+}
+
+// GetLinkDetail returns the current version of a golink as seen by userID, with when its
+// word was first created and when and by whom it was last changed
+func (s *LinkService) GetLinkDetail(ctx context.Context, word, userID string) (*domain.LinkDetail, error) {
+	history, err := s.GetHistory(ctx, word, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, first := history[0], history[len(history)-1]
+	var aliases []string
+	if s.aliases != nil {
+		if aliases, err = s.aliases.GetAliases(ctx, current.Word); err != nil {
+			return nil, fmt.Errorf("failed to get aliases: %w", err)
+		}
+	}
+
+	return &domain.LinkDetail{
+		ID:          current.ID,
+		Word:        current.Word,
+		Link:        current.Link,
+		User:        current.User,
+		Icon:        current.Icon,
+		Description: current.Description,
+		Private:     current.Private,
+		Prefix:      current.Prefix,
+		Archived:    current.Archived,
+		Metadata:    current.Metadata,
+		AlsoKnownAs: aliases,
+		CreatedAt:   first.CreatedAt,
+		UpdatedAt:   current.CreatedAt,
+		UpdatedBy:   current.UpdatedBy,
+	}, nil
+}
+
+// GetHistory returns every revision of a golink, newest first, as seen by userID
+func (s *LinkService) GetHistory(ctx context.Context, word, userID string) ([]domain.Shortcut, error) {
+	word = NormalizeWord(word)
+
+	history, err := s.shortcutRepo.GetHistory(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	if len(history) == 0 || !visibleTo(&history[0], userID) {
+		return nil, NotFoundError{Message: fmt.Sprintf("No golink found for %s", word)}
+	}
+
+	return history, nil
+}
+
+// RollbackLink restores a previous revision of a golink as its newest version
+func (s *LinkService) RollbackLink(
+	ctx context.Context, word string, revisionID int, userID string,
+) (*domain.Shortcut, error) {
+
+	word = NormalizeWord(word)
+
+	current, err := s.modifiableShortcut(ctx, word, userID, "roll back")
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.shortcutRepo.GetByID(ctx, revisionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	if revision == nil || revision.Word != word {
+		return nil, NotFoundError{Message: fmt.Sprintf("Revision %d not found for %s", revisionID, word)}
+	}
+
+	// Aliases may point at keywords that have since been removed
+	if !s.isTarget(revision.Link) {
+		target, err := s.aliasTarget(ctx, word, revision.Link, userID)
 		if err != nil {
-			return InvalidQueryError{
-				Message: "The link target appears to neither be a URL, or a valid alias.",
+			return nil, err
+		}
+		if err := s.checkAlias(ctx, word, target, userID, nil); err != nil {
+			return nil, err
+		}
+		found, err := s.aliasResolves(ctx, target, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, InvalidQueryError{
+				Message: fmt.Sprintf("Revision %d points to %s, which no longer resolves", revisionID, revision.Link),
 			}
 		}
 	}
 
+	// The link keeps its current owner and visibility, whoever performs the rollback
+	owner, private := userID, revision.Private
+	if current != nil {
+		owner, private = current.User, current.Private
+	}
+
 	shortcut := &domain.Shortcut{
-		Word:      req.Word,
-		Link:      req.Link,
-		User:      userID,
-		CreatedAt: time.Now(),
+		Word:        revision.Word,
+		Link:        revision.Link,
+		User:        owner,
+		Icon:        revision.Icon,
+		Description: revision.Description,
+		UpdatedBy:   userID,
+		Private:     private,
+		Prefix:      revision.Prefix,
+		Archived:    revision.Archived,
+		Metadata:    revision.Metadata,
+		CreatedAt:   time.Now(),
 	}
 
 	if err := s.shortcutRepo.Create(ctx, shortcut); err != nil {
-		return fmt.Errorf("failed to create shortcut: %w", err)
+		return nil, fmt.Errorf("failed to create shortcut: %w", err)
 	}
+	s.keywords.Invalidate()
+	s.notify(userID, current, shortcut)
 
-	return nil
+	return shortcut, nil
 }
 
-// GetRecentQueries retrieves popular queries
-func (s *LinkService) GetRecentQueries(ctx context.Context) ([]domain.PopularQuery, error) {
-	return s.queryRepo.GetRecentQueries(ctx, 3, 20)
+// GetRecentQueries retrieves the limit most popular queries over the last days days. Zero
+// values use DefaultPopularDays and DefaultPopularLimit.
+func (s *LinkService) GetRecentQueries(ctx context.Context, days, limit int) ([]domain.PopularQuery, error) {
+	if days == 0 {
+		days = DefaultPopularDays
+	}
+	if limit == 0 {
+		limit = DefaultPopularLimit
+	}
+	if days < 1 || days > MaxPopularDays {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("days must be between 1 and %d", MaxPopularDays)}
+	}
+	if limit < 1 || limit > MaxPopularLimit {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("limit must be between 1 and %d", MaxPopularLimit)}
+	}
+
+	return s.queryRepo.GetRecentQueries(ctx, days, limit)
+}
+
+// GetAllKeywords retrieves all keywords visible to userID with aliases
+func (s *LinkService) GetAllKeywords(ctx context.Context, userID string) ([]domain.KeywordInfo, error) {
+	cached, err := s.cachedKeywords(ctx, "all\x00"+userID, func() (interface{}, error) {
+		return s.getAllKeywords(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	keywords := cached.([]domain.KeywordInfo)
+
+	return append([]domain.KeywordInfo(nil), keywords...), nil
 }
 
-// GetAllKeywords retrieves all keywords with aliases
-func (s *LinkService) GetAllKeywords(ctx context.Context) ([]domain.KeywordInfo, error) {
-	keywords, err := s.shortcutRepo.GetAllKeywords(ctx)
+// getAllKeywords reads every keyword visible to userID from the repository
+func (s *LinkService) getAllKeywords(ctx context.Context, userID string) ([]domain.KeywordInfo, error) {
+	keywords, err := s.shortcutRepo.GetAllKeywords(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Process aliases (simplified version - not implementing full recursive alias resolution for now)
 	for i := range keywords {
-		if !isURL(keywords[i].Link) {
+		if !s.isTarget(keywords[i].Link) {
 			keywords[i].Aliases = keywords[i].Link
 		}
 	}
@@ -140,7 +796,7 @@ func (s *LinkService) GetAllKeywords(ctx context.Context) ([]domain.KeywordInfo,
 	// Filter to only return URLs (not aliases)
 	var result []domain.KeywordInfo
 	for _, keyword := range keywords {
-		if isURL(keyword.Link) {
+		if s.isTarget(keyword.Link) {
 			result = append(result, keyword)
 		}
 	}
@@ -148,6 +804,79 @@ func (s *LinkService) GetAllKeywords(ctx context.Context) ([]domain.KeywordInfo,
 	return result, nil
 }
 
+// ListKeywords returns one page of the keywords visible to userID in sort order, newest
+// first when sort is empty, optionally limited to those whose word, description, link,
+// owner or metadata contains search. A zero limit uses DefaultKeywordPageSize and larger
+// limits are capped at MaxKeywordPageSize.
+func (s *LinkService) ListKeywords(
+	ctx context.Context, search, sort string, limit, offset int, userID string,
+) (*domain.KeywordPage, error) {
+
+	if limit < 0 || offset < 0 {
+		return nil, InvalidQueryError{Message: "limit and offset must not be negative"}
+	}
+	switch sort {
+	case "":
+		sort = domain.KeywordSortNewest
+	case domain.KeywordSortNewest, domain.KeywordSortAlphabetical, domain.KeywordSortMostUsed:
+	default:
+		return nil, InvalidQueryError{Message: fmt.Sprintf("Unknown sort %q", sort)}
+	}
+	search = strings.TrimSpace(search)
+	if len(search) > maxSearchLength {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("Search terms are limited to %d characters", maxSearchLength)}
+	}
+	if limit == 0 {
+		limit = DefaultKeywordPageSize
+	}
+	if limit > MaxKeywordPageSize {
+		limit = MaxKeywordPageSize
+	}
+
+	load := func() (interface{}, error) {
+		keywords, total, err := s.shortcutRepo.GetKeywordsPage(ctx, s.targetPrefixes(), search, sort, userID, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		if keywords == nil {
+			keywords = []domain.KeywordInfo{}
+		}
+		return &domain.KeywordPage{
+			Keywords: keywords, Query: search, Sort: sort, Total: total, Limit: limit, Offset: offset,
+		}, nil
+	}
+
+	// Clicks reorder the most used keywords without changing the keywords version, so
+	// those pages are always read afresh
+	var cached interface{}
+	var err error
+	if sort == domain.KeywordSortMostUsed {
+		cached, err = load()
+	} else {
+		cached, err = s.cachedKeywords(ctx, keywordPageKey(search, sort, limit, offset, userID), load)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers get their own copy of the cached page
+	page := *cached.(*domain.KeywordPage)
+	page.Keywords = append([]domain.KeywordInfo{}, page.Keywords...)
+	return &page, nil
+}
+
+// KeywordsETag returns an entity tag for userID's keyword list that changes whenever a link
+// or tag is added or removed, letting clients revalidate without fetching the list again.
+// The list includes the user's private links, so each user gets a different tag.
+func (s *LinkService) KeywordsETag(ctx context.Context, userID string) (string, error) {
+	version, err := s.keywordsVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	viewer := sha256.Sum256([]byte(userID))
+	return `"k` + version + `-` + hex.EncodeToString(viewer[:4]) + `"`, nil
+}
+
 // validateLinkRequest validates a link request
 func (s *LinkService) validateLinkRequest(ctx context.Context, req domain.LinkRequest) error {
 	req.Word = strings.TrimSpace(req.Word)
@@ -161,6 +890,10 @@ func (s *LinkService) validateLinkRequest(ctx context.Context, req domain.LinkRe
 		return InvalidQueryError{Message: "Words ending in a '/' are not supported"}
 	}
 
+	if err := validateWord(req.Word); err != nil {
+		return err
+	}
+
 	if req.Link == "" {
 		return InvalidQueryError{Message: "No link given, cannot setup a golink"}
 	}
@@ -169,6 +902,36 @@ func (s *LinkService) validateLinkRequest(ctx context.Context, req domain.LinkRe
 		return InvalidQueryError{Message: "Word points to itself, will cause a recursive lookup"}
 	}
 
+	if err := s.validateScheme(req.Link); err != nil {
+		return err
+	}
+
+	if err := validatePlaceholders(req.Link); err != nil {
+		return err
+	}
+
+	if err := s.domains.check(req.Link); err != nil {
+		return err
+	}
+
+	if req.Prefix && !IsWebURL(req.Link) {
+		return InvalidQueryError{Message: "Only links to web pages can be prefix links"}
+	}
+
+	if err := validateDescription(strings.TrimSpace(req.Description)); err != nil {
+		return err
+	}
+
+	if err := validateMetadata(req.Metadata); err != nil {
+		return err
+	}
+
+	if s.iconsEnabled {
+		if err := validateIcon(strings.TrimSpace(req.Icon)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -177,18 +940,78 @@ func isURL(link string) bool {
 	return strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://")
 }
 
-// processResultLink processes a URL with search term substitution
+// maxPositionalPlaceholder is the highest {n} placeholder a link may use
+const maxPositionalPlaceholder = 9
+
+// positionalPattern matches numbered placeholders such as {1}
+var positionalPattern = regexp.MustCompile(`\{([0-9]+)\}`)
+
+// processResultLink processes a URL with search term substitution, escaping terms as
+// path segments before the link's query and as query values within it
 func processResultLink(link, searchTerm string) string {
 	// Remove wildcard markers and encode spaces
 	searchTerm = strings.ReplaceAll(searchTerm, "{*}", "")
 	searchTerm = strings.TrimSpace(searchTerm)
-	searchTerm = url.QueryEscape(searchTerm)
 
-	// Replace wildcards in the link
-	resultLink := strings.ReplaceAll(link, "{*}", searchTerm)
+	path, query, hasQuery := strings.Cut(link, "?")
+	resultLink := substituteTerms(path, searchTerm, url.PathEscape)
+	if hasQuery {
+		resultLink += "?" + substituteTerms(query, searchTerm, url.QueryEscape)
+	}
 	return strings.TrimSpace(resultLink)
 }
 
+// substituteTerms replaces the placeholders in part of a link with searchTerm, escaped
+// with escape
+func substituteTerms(link, searchTerm string, escape func(string) string) string {
+	// Replace numbered placeholders with the matching search term word
+	args := strings.Fields(searchTerm)
+	link = positionalPattern.ReplaceAllStringFunc(link, func(placeholder string) string {
+		n, _ := strconv.Atoi(placeholder[1 : len(placeholder)-1])
+		if n < 1 || n > len(args) {
+			return ""
+		}
+		return escape(args[n-1])
+	})
+
+	// Replace wildcards in the link
+	return strings.ReplaceAll(link, "{*}", escape(searchTerm))
+}
+
+// hasPlaceholders reports whether a link contains {*} or numbered placeholders
+func hasPlaceholders(link string) bool {
+	return strings.Contains(link, "{*}") || positionalPattern.MatchString(link)
+}
+
+// validatePlaceholders checks numbered placeholders start at {1}, have no gaps
+// and stay within maxPositionalPlaceholder
+func validatePlaceholders(link string) error {
+	used := map[int]bool{}
+	highest := 0
+	for _, match := range positionalPattern.FindAllStringSubmatch(link, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n < 1 || n > maxPositionalPlaceholder {
+			return InvalidQueryError{
+				Message: fmt.Sprintf("Placeholder %s is invalid, use {1} to {%d}", match[0], maxPositionalPlaceholder),
+			}
+		}
+		used[n] = true
+		if n > highest {
+			highest = n
+		}
+	}
+
+	for n := 1; n < highest; n++ {
+		if !used[n] {
+			return InvalidQueryError{
+				Message: fmt.Sprintf("Placeholder {%d} is used but {%d} is missing", highest, n),
+			}
+		}
+	}
+
+	return nil
+}
+
 // moveLastWord moves the last word from the first string to the beginning of the second string
 func moveLastWord(moveFrom, moveTo string) (string, string) {
 	moveFromWords := strings.Fields(moveFrom)
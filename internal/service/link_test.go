@@ -2,6 +2,11 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,7 +16,13 @@ import (
 // Mock repositories for testing
 type mockShortcutRepository struct {
 	shortcuts map[string]*domain.Shortcut
+	history   []*domain.Shortcut
+	trash     map[string]*domain.Shortcut
 	createErr error
+
+	lastPrefixes []string
+	lastSearch   string
+	lastSort     string
 }
 
 func (m *mockShortcutRepository) GetByWord(ctx context.Context, word string) (*domain.Shortcut, error) {
@@ -25,18 +36,21 @@ func (m *mockShortcutRepository) Create(ctx context.Context, shortcut *domain.Sh
 	if m.createErr != nil {
 		return m.createErr
 	}
-	shortcut.ID = len(m.shortcuts) + 1
+	shortcut.ID = len(m.shortcuts) + len(m.history) + 1
 	m.shortcuts[shortcut.Word] = shortcut
+	m.history = append(m.history, shortcut)
 	return nil
 }
 
-func (m *mockShortcutRepository) GetAllKeywords(ctx context.Context) ([]domain.KeywordInfo, error) {
+func (m *mockShortcutRepository) GetAllKeywords(ctx context.Context, viewer string) ([]domain.KeywordInfo, error) {
 	var keywords []domain.KeywordInfo
 	for word, shortcut := range m.shortcuts {
-		if isURL(shortcut.Link) {
+		if isURL(shortcut.Link) && visibleTo(shortcut, viewer) {
 			keywords = append(keywords, domain.KeywordInfo{
 				Word:      word,
 				Link:      shortcut.Link,
+				Icon:      shortcut.Icon,
+				Private:   shortcut.Private,
 				CreatedAt: shortcut.CreatedAt,
 			})
 		}
@@ -44,24 +58,323 @@ func (m *mockShortcutRepository) GetAllKeywords(ctx context.Context) ([]domain.K
 	return keywords, nil
 }
 
+func (m *mockShortcutRepository) GetKeywordsByOwner(ctx context.Context, owner string) ([]domain.KeywordInfo, error) {
+	var keywords []domain.KeywordInfo
+	for word, shortcut := range m.shortcuts {
+		if shortcut.User == owner {
+			keywords = append(keywords, domain.KeywordInfo{Word: word, Link: shortcut.Link, Private: shortcut.Private})
+		}
+	}
+	return keywords, nil
+}
+
+func (m *mockShortcutRepository) GetKeywordsPage(
+	ctx context.Context, targetPrefixes []string, search, order, viewer string, limit, offset int,
+) ([]domain.KeywordInfo, int, error) {
+	m.lastPrefixes = targetPrefixes
+	m.lastSearch = search
+	m.lastSort = order
+
+	var words []string
+	for word, shortcut := range m.shortcuts {
+		if !visibleTo(shortcut, viewer) || !strings.Contains(word+" "+shortcut.Link+" "+shortcut.User, search) {
+			continue
+		}
+		for _, prefix := range targetPrefixes {
+			if strings.HasPrefix(strings.ToLower(shortcut.Link), prefix) {
+				words = append(words, word)
+				break
+			}
+		}
+	}
+	sort.Strings(words)
+
+	var keywords []domain.KeywordInfo
+	for i := offset; i < len(words) && i < offset+limit; i++ {
+		shortcut := m.shortcuts[words[i]]
+		keywords = append(keywords, domain.KeywordInfo{Word: shortcut.Word, Link: shortcut.Link})
+	}
+	return keywords, len(words), nil
+}
+
+func (m *mockShortcutRepository) GetKeywordsByPrefix(
+	ctx context.Context, prefix, viewer string, limit int,
+) ([]domain.KeywordInfo, error) {
+	var words []string
+	for word, shortcut := range m.shortcuts {
+		if strings.HasPrefix(word, prefix) && visibleTo(shortcut, viewer) {
+			words = append(words, word)
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		return words[i] == prefix || (words[j] != prefix && words[i] < words[j])
+	})
+	if len(words) > limit {
+		words = words[:limit]
+	}
+
+	var keywords []domain.KeywordInfo
+	for _, word := range words {
+		shortcut := m.shortcuts[word]
+		keywords = append(keywords, domain.KeywordInfo{
+			Word: word, Link: shortcut.Link, Icon: shortcut.Icon, Description: shortcut.Description,
+		})
+	}
+	return keywords, nil
+}
+
+func (m *mockShortcutRepository) GetKeywordsVersion(ctx context.Context) (string, error) {
+	return fmt.Sprintf("%d-%d", len(m.history), len(m.shortcuts)), nil
+}
+
+func (m *mockShortcutRepository) DeleteByWord(ctx context.Context, word string) (int64, error) {
+	if _, exists := m.shortcuts[word]; !exists {
+		return 0, nil
+	}
+	if m.trash == nil {
+		m.trash = map[string]*domain.Shortcut{}
+	}
+	m.trash[word] = m.shortcuts[word]
+	delete(m.shortcuts, word)
+	return 1, nil
+}
+
+func (m *mockShortcutRepository) GetDeleted(ctx context.Context) ([]domain.TrashedLink, error) {
+	var trash []domain.TrashedLink
+	for word, shortcut := range m.trash {
+		trash = append(trash, domain.TrashedLink{
+			Word: word, Link: shortcut.Link, User: shortcut.User, Private: shortcut.Private, DeletedAt: shortcut.CreatedAt,
+		})
+	}
+	return trash, nil
+}
+
+func (m *mockShortcutRepository) RestoreByWord(ctx context.Context, word string) (int64, error) {
+	shortcut, exists := m.trash[word]
+	if !exists {
+		return 0, nil
+	}
+	m.shortcuts[word] = shortcut
+	delete(m.trash, word)
+	return 1, nil
+}
+
+func (m *mockShortcutRepository) PurgeByWord(ctx context.Context, word string) (int64, error) {
+	if _, exists := m.trash[word]; !exists {
+		return 0, nil
+	}
+	delete(m.trash, word)
+	return 1, nil
+}
+
+func (m *mockShortcutRepository) GetByID(ctx context.Context, id int) (*domain.Shortcut, error) {
+	for _, shortcut := range m.history {
+		if shortcut.ID == id {
+			return shortcut, nil
+		}
+	}
+	for _, shortcut := range m.shortcuts {
+		if shortcut.ID == id {
+			return shortcut, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockShortcutRepository) GetHistory(ctx context.Context, word string) ([]domain.Shortcut, error) {
+	var history []domain.Shortcut
+	for i := len(m.history) - 1; i >= 0; i-- {
+		if m.history[i].Word == word {
+			history = append(history, *m.history[i])
+		}
+	}
+	return history, nil
+}
+
+func (m *mockShortcutRepository) GetAllVersions(ctx context.Context) ([]domain.Shortcut, error) {
+	// Seeded shortcuts have no history, so they are their only version
+	var versions []domain.Shortcut
+	for _, shortcut := range m.shortcuts {
+		if !slices.Contains(m.history, shortcut) {
+			versions = append(versions, *shortcut)
+		}
+	}
+	for _, shortcut := range m.history {
+		if _, live := m.shortcuts[shortcut.Word]; live {
+			versions = append(versions, *shortcut)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Word != versions[j].Word {
+			return versions[i].Word < versions[j].Word
+		}
+		return versions[i].ID < versions[j].ID
+	})
+	return versions, nil
+}
+
+func (m *mockShortcutRepository) GetVersionsSince(ctx context.Context, since, limit int) ([]domain.Shortcut, error) {
+	versions := map[int]domain.Shortcut{}
+	for _, shortcuts := range []map[string]*domain.Shortcut{m.shortcuts, m.trash} {
+		for _, shortcut := range shortcuts {
+			versions[shortcut.ID] = *shortcut
+		}
+	}
+	for _, shortcut := range m.history {
+		versions[shortcut.ID] = *shortcut
+	}
+
+	var after []domain.Shortcut
+	for id, version := range versions {
+		if id > since {
+			after = append(after, version)
+		}
+	}
+	sort.Slice(after, func(i, j int) bool { return after[i].ID < after[j].ID })
+	if len(after) > limit {
+		after = after[:limit]
+	}
+	return after, nil
+}
+
+func (m *mockShortcutRepository) GetCreated(ctx context.Context, viewer string, before, limit int) ([]domain.Shortcut, error) {
+	var created []domain.Shortcut
+	for word, latest := range m.shortcuts {
+		// Seeded shortcuts have no history, so they created their word
+		first := latest
+		for _, shortcut := range m.history {
+			if shortcut.Word == word && shortcut.ID < first.ID {
+				first = shortcut
+			}
+		}
+		if visibleTo(first, viewer) && visibleTo(latest, viewer) && (before == 0 || first.ID < before) {
+			created = append(created, *first)
+		}
+	}
+	sort.Slice(created, func(i, j int) bool { return created[i].ID > created[j].ID })
+	if len(created) > limit {
+		created = created[:limit]
+	}
+	return created, nil
+}
+
+func (m *mockShortcutRepository) GetWords(ctx context.Context) ([]string, error) {
+	var words []string
+	for word := range m.shortcuts {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words, nil
+}
+
+func (m *mockShortcutRepository) CreateBatch(ctx context.Context, shortcuts []*domain.Shortcut) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	for _, shortcut := range shortcuts {
+		if err := m.Create(ctx, shortcut); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type mockQueryRepository struct {
 	queries   []domain.Query
 	createErr error
+
+	// daily and referrers are the click stats reported for every word
+	daily     []domain.ClickCount
+	referrers []domain.ReferrerCount
+
+	// stale is reported by GetStaleLinks, which records the cutoff it was asked for
+	stale      []domain.StaleLink
+	staleSince time.Time
+
+	// popularDays and popularLimit record the last popular queries window asked for
+	popularDays  int
+	popularLimit int
+
+	// pruneBefore records the cutoff PruneBefore was asked for, which reports pruned
+	pruneBefore time.Time
+	pruned      int64
 }
 
-func (m *mockQueryRepository) Create(ctx context.Context, wordID int) error {
+func (m *mockQueryRepository) PruneBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.pruneBefore = before
+	return m.pruned, nil
+}
+
+func (m *mockQueryRepository) GetStaleLinks(ctx context.Context, since time.Time) ([]domain.StaleLink, error) {
+	m.staleSince = since
+	return m.stale, nil
+}
+
+func (m *mockQueryRepository) Create(ctx context.Context, query *domain.Query) error {
 	if m.createErr != nil {
 		return m.createErr
 	}
-	m.queries = append(m.queries, domain.Query{
-		ID:        len(m.queries) + 1,
-		WordID:    wordID,
-		CreatedAt: time.Now(),
-	})
+	logged := *query
+	logged.ID = len(m.queries) + 1
+	logged.CreatedAt = time.Now()
+	m.queries = append(m.queries, logged)
 	return nil
 }
 
+func (m *mockQueryRepository) CreateBatch(ctx context.Context, queries []*domain.Query) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	for _, query := range queries {
+		logged := *query
+		logged.ID = len(m.queries) + 1
+		m.queries = append(m.queries, logged)
+	}
+	return nil
+}
+
+// GetUserTopLinks counts the logged queries of user by word ID, most used first
+func (m *mockQueryRepository) GetUserTopLinks(
+	ctx context.Context, user string, since time.Time, limit int,
+) ([]domain.PopularQuery, error) {
+	counts := map[int]int{}
+	for _, query := range m.queries {
+		if query.User == user {
+			counts[query.WordID]++
+		}
+	}
+	var links []domain.PopularQuery
+	for wordID, count := range counts {
+		links = append(links, domain.PopularQuery{Count: count, Word: fmt.Sprint(wordID)})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Count > links[j].Count })
+	if len(links) > limit {
+		links = links[:limit]
+	}
+	return links, nil
+}
+
+func (m *mockQueryRepository) GetDailyClicks(ctx context.Context, word string, since time.Time) ([]domain.ClickCount, error) {
+	var clicks []domain.ClickCount
+	for _, click := range m.daily {
+		if !click.Start.Before(since) {
+			clicks = append(clicks, click)
+		}
+	}
+	return clicks, nil
+}
+
+func (m *mockQueryRepository) GetTopReferrers(
+	ctx context.Context, word string, since time.Time, limit int,
+) ([]domain.ReferrerCount, error) {
+	if len(m.referrers) > limit {
+		return m.referrers[:limit], nil
+	}
+	return m.referrers, nil
+}
+
 func (m *mockQueryRepository) GetRecentQueries(ctx context.Context, timeWindowDays, numResults int) ([]domain.PopularQuery, error) {
+	m.popularDays, m.popularLimit = timeWindowDays, numResults
 	// Simple mock implementation
 	return []domain.PopularQuery{
 		{Count: 5, Word: "docs", Link: "https://docs.example.com"},
@@ -137,6 +450,21 @@ func TestLinkService_GetLink(t *testing.T) {
 			want:       "",
 			wantErr:    true,
 		},
+		{
+			name: "positional placeholders",
+			shortcuts: map[string]*domain.Shortcut{
+				"pr": {
+					ID:   1,
+					Word: "pr",
+					Link: "https://github.com/org/{2}/pull/{1}",
+					User: "testuser",
+				},
+			},
+			word:       "pr 1234 my-repo",
+			searchTerm: "",
+			want:       "https://github.com/org/my-repo/pull/1234",
+			wantErr:    false,
+		},
 		{
 			name: "word with spaces - should split",
 			shortcuts: map[string]*domain.Shortcut{
@@ -160,7 +488,7 @@ func TestLinkService_GetLink(t *testing.T) {
 			queryRepo := &mockQueryRepository{}
 			service := NewLinkService(shortcutRepo, queryRepo)
 
-			got, err := service.GetLink(context.Background(), tt.word, tt.searchTerm)
+			got, err := service.GetLink(context.Background(), tt.word, tt.searchTerm, "testuser")
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("LinkService.GetLink() error = %v, wantErr %v", err, tt.wantErr)
@@ -174,6 +502,78 @@ func TestLinkService_GetLink(t *testing.T) {
 	}
 }
 
+func TestLinkService_ResolveDetail(t *testing.T) {
+	shortcuts := map[string]*domain.Shortcut{
+		"search": {ID: 1, Word: "search", Link: "https://google.com/search?q={*}", User: "alice"},
+		"s":      {ID: 2, Word: "s", Link: "search", User: "bob"},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		logQuery   bool
+		want       domain.Resolution
+		wantLogged int
+		wantErr    bool
+	}{
+		{
+			name:       "alias with substitution",
+			query:      "s cats",
+			logQuery:   true,
+			wantLogged: 2,
+			want: domain.Resolution{
+				Query:        "s cats",
+				URL:          "https://google.com/search?q=cats",
+				Word:         "s",
+				SearchTerm:   "cats",
+				ResolvedWord: "search",
+				Substituted:  true,
+				Hops:         1,
+				Owner:        "bob",
+			},
+		},
+		{
+			name:  "without logging",
+			query: "search",
+			want: domain.Resolution{
+				Query:        "search",
+				URL:          "https://google.com/search?q=",
+				Word:         "search",
+				ResolvedWord: "search",
+				Substituted:  true,
+				Owner:        "alice",
+			},
+		},
+		{
+			name:    "not found",
+			query:   "missing",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: shortcuts}
+			queryRepo := &mockQueryRepository{}
+			service := NewLinkService(shortcutRepo, queryRepo)
+
+			got, err := service.ResolveDetail(context.Background(), tt.query, tt.logQuery, "testuser")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LinkService.ResolveDetail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(queryRepo.queries) != tt.wantLogged {
+				t.Errorf("LinkService.ResolveDetail() logged %d queries, want %d", len(queryRepo.queries), tt.wantLogged)
+			}
+			if tt.wantErr {
+				return
+			}
+			if *got != tt.want {
+				t.Errorf("LinkService.ResolveDetail() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
 func TestLinkService_UpdateLink(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -239,6 +639,16 @@ func TestLinkService_UpdateLink(t *testing.T) {
 			userID:  "testuser",
 			wantErr: true,
 		},
+		{
+			name:      "positional placeholder gap",
+			shortcuts: map[string]*domain.Shortcut{},
+			request: domain.LinkRequest{
+				Word: "pr",
+				Link: "https://github.com/org/{3}/pull/{1}",
+			},
+			userID:  "testuser",
+			wantErr: true,
+		},
 		{
 			name:      "invalid alias target",
 			shortcuts: map[string]*domain.Shortcut{},
@@ -266,12 +676,251 @@ func TestLinkService_UpdateLink(t *testing.T) {
 	}
 }
 
+func TestLinkService_BulkUpdateLinks(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "testuser"},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+
+	reqs := []domain.LinkRequest{
+		{Word: "github", Link: "https://github.com"},
+		{Word: "gh", Link: "github"},   // alias to an earlier item
+		{Word: "d", Link: "docs"},      // alias to an existing word
+		{Word: "", Link: "https://x"},  // invalid
+		{Word: "bad", Link: "missing"}, // dangling alias
+	}
+
+	results, err := service.BulkUpdateLinks(context.Background(), reqs, "bulkuser")
+	if err != nil {
+		t.Fatalf("LinkService.BulkUpdateLinks() error = %v", err)
+	}
+
+	wantStatus := []string{
+		domain.BulkStatusCreated,
+		domain.BulkStatusCreated,
+		domain.BulkStatusCreated,
+		domain.BulkStatusFailed,
+		domain.BulkStatusFailed,
+	}
+	for i, result := range results {
+		if result.Index != i || result.Status != wantStatus[i] {
+			t.Errorf("result %d = %+v, want status %s", i, result, wantStatus[i])
+		}
+		if result.Status == domain.BulkStatusFailed && result.Error == "" {
+			t.Errorf("result %d failed without an error message", i)
+		}
+	}
+
+	for _, word := range []string{"github", "gh", "d"} {
+		if shortcut, exists := shortcutRepo.shortcuts[word]; !exists || shortcut.User != "bulkuser" {
+			t.Errorf("shortcut %s not created for bulkuser", word)
+		}
+	}
+
+	if _, err := service.BulkUpdateLinks(context.Background(), nil, "bulkuser"); err == nil {
+		t.Error("LinkService.BulkUpdateLinks() expected error for empty batch")
+	}
+
+	tooMany := make([]domain.LinkRequest, MaxBulkLinks+1)
+	if _, err := service.BulkUpdateLinks(context.Background(), tooMany, "bulkuser"); err == nil {
+		t.Error("LinkService.BulkUpdateLinks() expected error for oversized batch")
+	}
+}
+
+func TestLinkService_DeleteLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		word    string
+		userID  string
+		wantErr error
+	}{
+		{
+			name:   "owner deletes",
+			word:   "docs",
+			userID: "owner",
+		},
+		{
+			name:    "other user forbidden",
+			word:    "docs",
+			userID:  "intruder",
+			wantErr: ForbiddenError{},
+		},
+		{
+			name:    "missing word",
+			word:    "nonexistent",
+			userID:  "owner",
+			wantErr: NotFoundError{},
+		},
+		{
+			name:    "someone else's private link hidden",
+			word:    "payroll",
+			userID:  "intruder",
+			wantErr: NotFoundError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"docs":    {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "owner"},
+				"payroll": {ID: 2, Word: "payroll", Link: "https://payroll.example.com", User: "owner", Private: true},
+			}}
+			service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+
+			err := service.DeleteLink(context.Background(), tt.word, tt.userID)
+
+			switch tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("LinkService.DeleteLink() error = %v", err)
+				}
+				if _, exists := shortcutRepo.shortcuts[tt.word]; exists {
+					t.Error("LinkService.DeleteLink() did not delete the shortcut")
+				}
+			case ForbiddenError:
+				if _, ok := err.(ForbiddenError); !ok {
+					t.Errorf("LinkService.DeleteLink() error = %v, want ForbiddenError", err)
+				}
+			case NotFoundError:
+				if _, ok := err.(NotFoundError); !ok {
+					t.Errorf("LinkService.DeleteLink() error = %v, want NotFoundError", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLinkService_Trash(t *testing.T) {
+	ctx := context.Background()
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "owner"},
+		"wiki": {ID: 2, Word: "wiki", Link: "https://wiki.example.com", User: "owner"},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+
+	trash, err := service.ListTrash(ctx)
+	if err != nil || trash == nil || len(trash) != 0 {
+		t.Fatalf("LinkService.ListTrash() = %v, %v, want an empty trash", trash, err)
+	}
+
+	for _, word := range []string{"docs", "wiki"} {
+		if err := service.DeleteLink(ctx, word, "owner"); err != nil {
+			t.Fatalf("LinkService.DeleteLink() error = %v", err)
+		}
+	}
+	if trash, _ := service.ListTrash(ctx); len(trash) != 2 {
+		t.Errorf("LinkService.ListTrash() = %v, want both deleted links", trash)
+	}
+
+	// The word has been reused since it was deleted
+	if err := service.UpdateLink(ctx, domain.LinkRequest{Word: "wiki", Link: "https://new.example.com"}, "other"); err != nil {
+		t.Fatalf("LinkService.UpdateLink() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		word     string
+		wantLink string
+		wantErr  error
+	}{
+		{name: "restores a deleted link", word: "docs", wantLink: "https://docs.example.com"},
+		{name: "word reused since", word: "wiki", wantErr: InvalidQueryError{}},
+		{name: "not in the trash", word: "missing", wantErr: NotFoundError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcut, err := service.RestoreLink(ctx, tt.word, "admin")
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.RestoreLink() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.RestoreLink() error = %v", err)
+			}
+			if shortcut == nil || shortcut.Link != tt.wantLink {
+				t.Errorf("LinkService.RestoreLink() = %+v, want link %s", shortcut, tt.wantLink)
+			}
+		})
+	}
+
+	if err := service.PurgeLink(ctx, "wiki"); err != nil {
+		t.Errorf("LinkService.PurgeLink() error = %v", err)
+	}
+	if err := service.PurgeLink(ctx, "wiki"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("LinkService.PurgeLink() of a purged link error = %v, want NotFoundError", err)
+	}
+	if trash, _ := service.ListTrash(ctx); len(trash) != 0 {
+		t.Errorf("LinkService.ListTrash() = %v, want an empty trash", trash)
+	}
+}
+
+func TestLinkService_HistoryAndRollback(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+	ctx := context.Background()
+
+	for _, link := range []string{"https://v1.example.com", "https://v2.example.com"} {
+		if err := service.UpdateLink(ctx, domain.LinkRequest{Word: "docs", Link: link}, "alice"); err != nil {
+			t.Fatalf("LinkService.UpdateLink() error = %v", err)
+		}
+	}
+
+	history, err := service.GetHistory(ctx, "docs", "alice")
+	if err != nil {
+		t.Fatalf("LinkService.GetHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].Link != "https://v2.example.com" {
+		t.Fatalf("LinkService.GetHistory() = %+v, want 2 revisions newest first", history)
+	}
+
+	if _, err := service.RollbackLink(ctx, "docs", history[1].ID, "bob"); err == nil {
+		t.Error("LinkService.RollbackLink() expected ForbiddenError for non-owner")
+	} else if _, ok := err.(ForbiddenError); !ok {
+		t.Errorf("LinkService.RollbackLink() error = %v, want ForbiddenError", err)
+	}
+
+	restored, err := service.RollbackLink(ctx, "docs", history[1].ID, "alice")
+	if err != nil {
+		t.Fatalf("LinkService.RollbackLink() error = %v", err)
+	}
+	if restored.Link != "https://v1.example.com" || restored.User != "alice" {
+		t.Errorf("LinkService.RollbackLink() = %+v, want v1 link restored for alice", restored)
+	}
+
+	got, err := service.GetLink(ctx, "docs", "", "alice")
+	if err != nil || got != "https://v1.example.com" {
+		t.Errorf("LinkService.GetLink() after rollback = %v, %v", got, err)
+	}
+
+	if _, err := service.GetHistory(ctx, "missing", "testuser"); err == nil {
+		t.Error("LinkService.GetHistory() expected NotFoundError for unknown word")
+	}
+
+	if _, err := service.RollbackLink(ctx, "other", history[1].ID, "bob"); err == nil {
+		t.Error("LinkService.RollbackLink() expected error for revision of a different word")
+	} else if _, ok := err.(NotFoundError); !ok {
+		t.Errorf("LinkService.RollbackLink() error = %v, want NotFoundError", err)
+	}
+
+	// Someone else's private link must not give away that it exists or who owns it
+	private := domain.LinkRequest{Word: "docs", Link: "https://v3.example.com", Private: true}
+	if err := service.UpdateLink(ctx, private, "alice"); err != nil {
+		t.Fatalf("LinkService.UpdateLink() error = %v", err)
+	}
+	if _, err := service.RollbackLink(ctx, "docs", history[1].ID, "bob"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("LinkService.RollbackLink() of a private link error = %v, want NotFoundError", err)
+	}
+}
+
 func TestLinkService_GetRecentQueries(t *testing.T) {
 	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
 	queryRepo := &mockQueryRepository{}
 	service := NewLinkService(shortcutRepo, queryRepo)
 
-	queries, err := service.GetRecentQueries(context.Background())
+	queries, err := service.GetRecentQueries(context.Background(), 0, 0)
 
 	if err != nil {
 		t.Errorf("LinkService.GetRecentQueries() error = %v", err)
@@ -287,6 +936,46 @@ func TestLinkService_GetRecentQueries(t *testing.T) {
 	}
 }
 
+func TestLinkService_GetRecentQueries_Window(t *testing.T) {
+	tests := []struct {
+		name      string
+		days      int
+		limit     int
+		wantDays  int
+		wantLimit int
+		wantErr   bool
+	}{
+		{name: "defaults", wantDays: DefaultPopularDays, wantLimit: DefaultPopularLimit},
+		{name: "weekly top 10", days: 7, limit: 10, wantDays: 7, wantLimit: 10},
+		{name: "largest window", days: MaxPopularDays, limit: MaxPopularLimit, wantDays: MaxPopularDays, wantLimit: MaxPopularLimit},
+		{name: "negative days", days: -1, wantErr: true},
+		{name: "window too long", days: MaxPopularDays + 1, wantErr: true},
+		{name: "too many results", limit: MaxPopularLimit + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryRepo := &mockQueryRepository{}
+			service := NewLinkService(&mockShortcutRepository{}, queryRepo)
+
+			_, err := service.GetRecentQueries(context.Background(), tt.days, tt.limit)
+			if tt.wantErr {
+				if !sameErrorType(err, InvalidQueryError{}) {
+					t.Errorf("LinkService.GetRecentQueries() error = %v, want InvalidQueryError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.GetRecentQueries() error = %v", err)
+			}
+			if queryRepo.popularDays != tt.wantDays || queryRepo.popularLimit != tt.wantLimit {
+				t.Errorf("LinkService.GetRecentQueries() asked for %d days, %d results, want %d, %d",
+					queryRepo.popularDays, queryRepo.popularLimit, tt.wantDays, tt.wantLimit)
+			}
+		})
+	}
+}
+
 func TestLinkService_GetAllKeywords(t *testing.T) {
 	shortcuts := map[string]*domain.Shortcut{
 		"docs": {
@@ -309,7 +998,7 @@ func TestLinkService_GetAllKeywords(t *testing.T) {
 	queryRepo := &mockQueryRepository{}
 	service := NewLinkService(shortcutRepo, queryRepo)
 
-	keywords, err := service.GetAllKeywords(context.Background())
+	keywords, err := service.GetAllKeywords(context.Background(), "testuser")
 
 	if err != nil {
 		t.Errorf("LinkService.GetAllKeywords() error = %v", err)
@@ -325,6 +1014,46 @@ func TestLinkService_GetAllKeywords(t *testing.T) {
 	}
 }
 
+func TestLinkService_UpdateLink_Icon(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		icon     string
+		wantIcon string
+		wantErr  bool
+	}{
+		{"emoji icon", true, "📚", "📚", false},
+		{"named icon", true, "rocket", "rocket", false},
+		{"invalid icon", true, "<b>", "", true},
+		{"icons disabled ignores value", false, "<b>", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+			queryRepo := &mockQueryRepository{}
+			service := NewLinkService(shortcutRepo, queryRepo, WithIcons(tt.enabled))
+
+			req := domain.LinkRequest{Word: "docs", Link: "https://docs.example.com", Icon: tt.icon}
+			err := service.UpdateLink(context.Background(), req, "testuser")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LinkService.UpdateLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			keywords, err := service.GetAllKeywords(context.Background(), "testuser")
+			if err != nil {
+				t.Fatalf("LinkService.GetAllKeywords() error = %v", err)
+			}
+			if len(keywords) != 1 || keywords[0].Icon != tt.wantIcon {
+				t.Errorf("LinkService.GetAllKeywords() = %+v, want icon %q", keywords, tt.wantIcon)
+			}
+		})
+	}
+}
+
 // Test utility functions
 func Test_isURL(t *testing.T) {
 	tests := []struct {
@@ -385,6 +1114,42 @@ func Test_processResultLink(t *testing.T) {
 			searchTerm: "",
 			want:       "https://example.com/",
 		},
+		{
+			name:       "positional placeholders",
+			link:       "https://github.com/org/{2}/pull/{1}",
+			searchTerm: "1234 my-repo",
+			want:       "https://github.com/org/my-repo/pull/1234",
+		},
+		{
+			name:       "missing positional argument",
+			link:       "https://github.com/org/{2}/pull/{1}",
+			searchTerm: "1234",
+			want:       "https://github.com/org//pull/1234",
+		},
+		{
+			name:       "positional and wildcard",
+			link:       "https://example.com/{1}?q={*}",
+			searchTerm: "team hello world",
+			want:       "https://example.com/team?q=team+hello+world",
+		},
+		{
+			name:       "positional argument is escaped",
+			link:       "https://example.com/search?q={1}",
+			searchTerm: "a&b",
+			want:       "https://example.com/search?q=a%26b",
+		},
+		{
+			name:       "spaces in a path are percent-encoded",
+			link:       "https://wiki.example.com/pages/{*}?q={*}",
+			searchTerm: "release notes",
+			want:       "https://wiki.example.com/pages/release%20notes?q=release+notes",
+		},
+		{
+			name:       "positional argument in a path",
+			link:       "https://example.com/lang/{1}?tab={2}",
+			searchTerm: "c++ a+b",
+			want:       "https://example.com/lang/c++?tab=a%2Bb",
+		},
 	}
 
 	for _, tt := range tests {
@@ -396,6 +1161,32 @@ func Test_processResultLink(t *testing.T) {
 	}
 }
 
+func Test_validatePlaceholders(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    string
+		wantErr bool
+	}{
+		{"no placeholders", "https://example.com", false},
+		{"wildcard only", "https://example.com/{*}", false},
+		{"contiguous", "https://github.com/org/{2}/pull/{1}", false},
+		{"repeated", "https://example.com/{1}/{1}", false},
+		{"zero", "https://example.com/{0}", true},
+		{"gap", "https://example.com/{1}/{3}", true},
+		{"starts above one", "https://example.com/{2}", true},
+		{"too high", "https://example.com/{1}/{2}/{3}/{4}/{5}/{6}/{7}/{8}/{9}/{10}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePlaceholders(tt.link)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePlaceholders(%q) error = %v, wantErr %v", tt.link, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func Test_moveLastWord(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -446,3 +1237,242 @@ func Test_moveLastWord(t *testing.T) {
 		})
 	}
 }
+
+func TestLinkService_GetShortcut(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+
+	shortcut, err := service.GetShortcut(context.Background(), " docs ", "testuser")
+	if err != nil || shortcut.Link != "https://docs.example.com" {
+		t.Errorf("LinkService.GetShortcut() = %+v, %v", shortcut, err)
+	}
+
+	if _, err := service.GetShortcut(context.Background(), "missing", "testuser"); err == nil {
+		t.Error("LinkService.GetShortcut() expected NotFoundError")
+	} else if _, ok := err.(NotFoundError); !ok {
+		t.Errorf("LinkService.GetShortcut() error = %v, want NotFoundError", err)
+	}
+}
+
+func TestLinkService_GetLinkDetail(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+	ctx := context.Background()
+
+	if err := service.UpdateLink(ctx, domain.LinkRequest{Word: "docs", Link: "https://v1.example.com"}, "alice"); err != nil {
+		t.Fatalf("LinkService.UpdateLink() error = %v", err)
+	}
+	if got := shortcutRepo.shortcuts["docs"].UpdatedBy; got != "alice" {
+		t.Errorf("LinkService.UpdateLink() stored UpdatedBy = %q, want alice", got)
+	}
+
+	created := shortcutRepo.shortcuts["docs"].CreatedAt
+	updated := created.Add(time.Hour)
+	edit := &domain.Shortcut{Word: "docs", Link: "https://v2.example.com", User: "alice", UpdatedBy: "admin", CreatedAt: updated}
+	if err := shortcutRepo.Create(ctx, edit); err != nil {
+		t.Fatalf("Failed to create test shortcut: %v", err)
+	}
+
+	detail, err := service.GetLinkDetail(ctx, " docs ", "testuser")
+	if err != nil {
+		t.Fatalf("LinkService.GetLinkDetail() error = %v", err)
+	}
+	want := domain.LinkDetail{
+		ID:        edit.ID,
+		Word:      "docs",
+		Link:      "https://v2.example.com",
+		User:      "alice",
+		CreatedAt: created,
+		UpdatedAt: updated,
+		UpdatedBy: "admin",
+	}
+	if !reflect.DeepEqual(*detail, want) {
+		t.Errorf("LinkService.GetLinkDetail() = %+v, want %+v", *detail, want)
+	}
+
+	if _, err := service.GetLinkDetail(ctx, "missing", "testuser"); err == nil {
+		t.Error("LinkService.GetLinkDetail() expected NotFoundError")
+	} else if _, ok := err.(NotFoundError); !ok {
+		t.Errorf("LinkService.GetLinkDetail() error = %v, want NotFoundError", err)
+	}
+}
+
+func TestLinkService_PrivateLinks(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"payroll": {ID: 1, Word: "payroll", Link: "https://payroll.example.com", User: "alice", Private: true},
+		"pay":     {ID: 2, Word: "pay", Link: "payroll", User: "alice"},
+		"docs":    {ID: 3, Word: "docs", Link: "https://docs.example.com", User: "bob"},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		word    string
+		userID  string
+		want    string
+		wantErr bool
+	}{
+		{"owner resolves", "payroll", "alice", "https://payroll.example.com", false},
+		{"owner resolves through alias", "pay", "alice", "https://payroll.example.com", false},
+		{"others can't resolve", "payroll", "bob", "", true},
+		{"others can't resolve through alias", "pay", "bob", "", true},
+		{"public links still resolve", "docs", "alice", "https://docs.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.GetLink(ctx, tt.word, "", tt.userID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LinkService.GetLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LinkService.GetLink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := service.GetShortcut(ctx, "payroll", "bob"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("LinkService.GetShortcut() by another user error = %v, want NotFoundError", err)
+	}
+	if _, err := service.GetHistory(ctx, "payroll", "bob"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("LinkService.GetHistory() by another user error = %v, want NotFoundError", err)
+	}
+
+	keywords, err := service.GetAllKeywords(ctx, "bob")
+	if err != nil {
+		t.Fatalf("LinkService.GetAllKeywords() error = %v", err)
+	}
+	if len(keywords) != 1 || keywords[0].Word != "docs" {
+		t.Errorf("LinkService.GetAllKeywords() = %+v, want only docs", keywords)
+	}
+
+	// Words stay unique, so others can't claim a private word
+	err = service.UpdateLink(ctx, domain.LinkRequest{Word: "payroll", Link: "https://example.com"}, "bob")
+	if !sameErrorType(err, ForbiddenError{}) || strings.Contains(err.Error(), "alice") {
+		t.Errorf("LinkService.UpdateLink() on another user's private word error = %v, want ForbiddenError without the owner", err)
+	}
+
+	err = service.UpdateLink(ctx, domain.LinkRequest{Word: "notes", Link: "https://notes.example.com", Private: true}, "bob")
+	if err != nil {
+		t.Fatalf("LinkService.UpdateLink() error = %v", err)
+	}
+	if !shortcutRepo.shortcuts["notes"].Private {
+		t.Error("LinkService.UpdateLink() should store the link as private")
+	}
+}
+
+func TestLinkService_ListKeywords(t *testing.T) {
+	shortcuts := map[string]*domain.Shortcut{
+		"a":     {Word: "a", Link: "https://a.example.com"},
+		"b":     {Word: "b", Link: "https://b.example.com"},
+		"c":     {Word: "c", Link: "http://c.example.com"},
+		"chat":  {Word: "chat", Link: "Slack://channel?id=1"},
+		"alias": {Word: "alias", Link: "a"},
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		offset    int
+		search    string
+		sort      string
+		wantWords []string
+		wantTotal int
+		wantLimit int
+		wantErr   bool
+	}{
+		{name: "first page", limit: 2, wantWords: []string{"a", "b"}, wantTotal: 4, wantLimit: 2},
+		{name: "last page", limit: 2, offset: 2, wantWords: []string{"c", "chat"}, wantTotal: 4, wantLimit: 2},
+		{name: "past the end", limit: 2, offset: 10, wantWords: nil, wantTotal: 4, wantLimit: 2},
+		{name: "default limit", wantWords: []string{"a", "b", "c", "chat"}, wantTotal: 4, wantLimit: DefaultKeywordPageSize},
+		{name: "limit capped", limit: MaxKeywordPageSize + 1, wantWords: []string{"a", "b", "c", "chat"}, wantTotal: 4, wantLimit: MaxKeywordPageSize},
+		{name: "negative limit", limit: -1, wantErr: true},
+		{name: "negative offset", offset: -1, wantErr: true},
+		{name: "search", search: " example.com ", wantWords: []string{"a", "b", "c"}, wantTotal: 3, wantLimit: DefaultKeywordPageSize},
+		{name: "search too long", search: strings.Repeat("x", 201), wantErr: true},
+		{name: "sorted", sort: domain.KeywordSortMostUsed, wantWords: []string{"a", "b", "c", "chat"}, wantTotal: 4, wantLimit: DefaultKeywordPageSize},
+		{name: "unknown sort", sort: "oldest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: shortcuts}
+			service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAllowedSchemes([]string{"slack"}))
+
+			page, err := service.ListKeywords(context.Background(), tt.search, tt.sort, tt.limit, tt.offset, "testuser")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LinkService.ListKeywords() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, ok := err.(InvalidQueryError); !ok {
+					t.Errorf("LinkService.ListKeywords() error = %v, want InvalidQueryError", err)
+				}
+				return
+			}
+
+			var words []string
+			for _, keyword := range page.Keywords {
+				words = append(words, keyword.Word)
+			}
+			if !reflect.DeepEqual(words, tt.wantWords) {
+				t.Errorf("LinkService.ListKeywords() words = %v, want %v", words, tt.wantWords)
+			}
+			if page.Keywords == nil {
+				t.Error("LinkService.ListKeywords() keywords should not be nil")
+			}
+			if page.Query != strings.TrimSpace(tt.search) || shortcutRepo.lastSearch != page.Query {
+				t.Errorf("LinkService.ListKeywords() query = %q, repository search = %q", page.Query, shortcutRepo.lastSearch)
+			}
+			if page.Total != tt.wantTotal || page.Limit != tt.wantLimit || page.Offset != tt.offset {
+				t.Errorf("LinkService.ListKeywords() = total %d limit %d offset %d", page.Total, page.Limit, page.Offset)
+			}
+			wantSort := tt.sort
+			if wantSort == "" {
+				wantSort = domain.KeywordSortNewest
+			}
+			if page.Sort != wantSort || shortcutRepo.lastSort != wantSort {
+				t.Errorf("LinkService.ListKeywords() sort = %q, repository sort = %q, want %q", page.Sort, shortcutRepo.lastSort, wantSort)
+			}
+			if want := []string{"http://", "https://", "slack:"}; !reflect.DeepEqual(shortcutRepo.lastPrefixes, want) {
+				t.Errorf("LinkService.ListKeywords() prefixes = %v, want %v", shortcutRepo.lastPrefixes, want)
+			}
+		})
+	}
+}
+
+func TestLinkService_KeywordsETag(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: make(map[string]*domain.Shortcut)}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+	ctx := context.Background()
+
+	before, err := service.KeywordsETag(ctx, "user1")
+	if err != nil {
+		t.Fatalf("LinkService.KeywordsETag() error = %v", err)
+	}
+	if !strings.HasPrefix(before, `"`) || !strings.HasSuffix(before, `"`) {
+		t.Errorf("LinkService.KeywordsETag() = %s, want a quoted entity tag", before)
+	}
+
+	if err := service.UpdateLink(ctx, domain.LinkRequest{Word: "docs", Link: "https://docs.example.com"}, "user1"); err != nil {
+		t.Fatalf("LinkService.UpdateLink() error = %v", err)
+	}
+
+	after, err := service.KeywordsETag(ctx, "user1")
+	if err != nil {
+		t.Fatalf("LinkService.KeywordsETag() error = %v", err)
+	}
+	if after == before {
+		t.Errorf("LinkService.KeywordsETag() = %s before and after an update", after)
+	}
+
+	other, err := service.KeywordsETag(ctx, "user2")
+	if err != nil {
+		t.Fatalf("LinkService.KeywordsETag() error = %v", err)
+	}
+	if other == after {
+		t.Errorf("LinkService.KeywordsETag() = %s for two users, want tags per user", other)
+	}
+}
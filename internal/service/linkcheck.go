@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LinkChecker requests link targets to find broken ones before they go live
+type LinkChecker interface {
+	// Check returns why link looks broken, or nil if it works or can't be checked
+	Check(ctx context.Context, link string) error
+}
+
+// WithLinkChecker rejects new and changed links to web pages checker finds broken
+func WithLinkChecker(checker LinkChecker) Option {
+	return func(s *LinkService) {
+		s.linkChecker = checker
+	}
+}
+
+// checkTarget rejects link if it is a web page that looks broken. Links with placeholders
+// aren't checked, as they only become a URL once filled in.
+func (s *LinkService) checkTarget(ctx context.Context, link string) error {
+	if s.linkChecker == nil || !IsWebURL(link) || strings.Contains(link, "{") {
+		return nil
+	}
+	if err := s.linkChecker.Check(ctx, link); err != nil {
+		return InvalidQueryError{Message: fmt.Sprintf("%s looks broken, %v; check it for typos", link, err)}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+// stubLinkChecker finds the links in broken broken, and records every link it checks
+type stubLinkChecker struct {
+	broken map[string]bool
+
+	mu      sync.Mutex
+	checked []string
+}
+
+func (c *stubLinkChecker) Check(ctx context.Context, link string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked = append(c.checked, link)
+	if c.broken[link] {
+		return errors.New("it answered 404 Not Found")
+	}
+	return nil
+}
+
+func TestLinkService_UpdateLink_LinkCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		link        string
+		wantErr     string
+		wantChecked bool
+	}{
+		{name: "works", link: "https://docs.example.com/new", wantChecked: true},
+		{
+			name: "broken", link: "https://docs.example.com/nwe", wantChecked: true,
+			wantErr: "https://docs.example.com/nwe looks broken, it answered 404 Not Found",
+		},
+		{name: "unchanged", link: "https://docs.example.com/old"},
+		{name: "placeholders", link: "https://docs.example.com/nwe?q={*}"},
+		{name: "alias", link: "wiki"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &stubLinkChecker{broken: map[string]bool{
+				"https://docs.example.com/nwe":       true,
+				"https://docs.example.com/old":       true,
+				"https://docs.example.com/nwe?q={*}": true,
+			}}
+			repo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com/old", User: "alice"},
+				"wiki": {ID: 2, Word: "wiki", Link: "https://wiki.example.com", User: "alice"},
+			}}
+			service := NewLinkService(repo, &mockQueryRepository{}, WithLinkChecker(checker))
+
+			err := service.UpdateLink(context.Background(), domain.LinkRequest{Word: "docs", Link: tt.link}, "alice")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("LinkService.UpdateLink(%s) error = %v", tt.link, err)
+			}
+			if tt.wantErr != "" {
+				if _, ok := err.(InvalidQueryError); !ok || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LinkService.UpdateLink(%s) error = %v, want %q", tt.link, err, tt.wantErr)
+				}
+				if len(repo.history) != 0 {
+					t.Errorf("LinkService.UpdateLink(%s) stored %d links", tt.link, len(repo.history))
+				}
+			}
+			if checked := len(checker.checked) > 0; checked != tt.wantChecked {
+				t.Errorf("LinkService.UpdateLink(%s) checked %v, want checked %v", tt.link, checker.checked, tt.wantChecked)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// MaxMetadataBytes bounds a link's metadata, encoded as JSON, so it stays a handful of
+// fields rather than a document store
+const MaxMetadataBytes = 4096
+
+// validateMetadata checks metadata has no blank keys and encodes to at most
+// MaxMetadataBytes of JSON
+func validateMetadata(metadata map[string]interface{}) error {
+	for key := range metadata {
+		if strings.TrimSpace(key) == "" {
+			return InvalidQueryError{Message: "Metadata keys can't be blank"}
+		}
+	}
+
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return InvalidQueryError{Message: fmt.Sprintf("Metadata must be a JSON object: %v", err)}
+	}
+	if len(encoded) > MaxMetadataBytes {
+		return InvalidQueryError{Message: fmt.Sprintf("Metadata is limited to %d bytes of JSON", MaxMetadataBytes)}
+	}
+	return nil
+}
+
+// SetMetadata replaces the metadata of a golink userID may change, storing a new version
+// so its history records who changed it. Empty metadata clears it.
+func (s *LinkService) SetMetadata(ctx context.Context, word string, metadata map[string]interface{}, userID string) (*domain.Shortcut, error) {
+	word = NormalizeWord(word)
+
+	if err := validateMetadata(metadata); err != nil {
+		return nil, err
+	}
+	current, err := s.modifiableShortcut(ctx, word, userID, "change the metadata of")
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+
+	shortcut := &domain.Shortcut{
+		Word:        current.Word,
+		Link:        current.Link,
+		User:        current.User,
+		Icon:        current.Icon,
+		Description: current.Description,
+		UpdatedBy:   userID,
+		Private:     current.Private,
+		Prefix:      current.Prefix,
+		Archived:    current.Archived,
+		Metadata:    metadata,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.shortcutRepo.Create(ctx, shortcut); err != nil {
+		return nil, fmt.Errorf("failed to create shortcut: %w", err)
+	}
+	s.keywords.Invalidate()
+	s.notify(userID, current, shortcut)
+
+	return shortcut, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		wantErr  bool
+	}{
+		{name: "none", metadata: nil},
+		{name: "flat fields", metadata: map[string]interface{}{"team": "payments", "tier": 1.0, "jira": "PAY"}},
+		{name: "nested values", metadata: map[string]interface{}{"oncall": map[string]interface{}{"rotation": "payments-primary"}}},
+		{name: "blank key", metadata: map[string]interface{}{" ": "payments"}, wantErr: true},
+		{name: "too large", metadata: map[string]interface{}{"notes": strings.Repeat("x", MaxMetadataBytes)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMetadata(tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !sameErrorType(err, InvalidQueryError{}) {
+				t.Errorf("validateMetadata() error = %v, want InvalidQueryError", err)
+			}
+		})
+	}
+}
+
+func TestLinkService_SetMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		word     string
+		userID   string
+		metadata map[string]interface{}
+		want     map[string]interface{}
+		wantErr  error
+	}{
+		{name: "owner sets", word: "pay", userID: "alice", metadata: map[string]interface{}{"tier": 1.0}, want: map[string]interface{}{"tier": 1.0}},
+		{name: "admin sets anyone's", word: "pay", userID: "root", metadata: map[string]interface{}{"tier": 2.0}, want: map[string]interface{}{"tier": 2.0}},
+		{name: "empty clears", word: "pay", userID: "alice", metadata: map[string]interface{}{}},
+		{name: "other user", word: "pay", userID: "bob", metadata: map[string]interface{}{"tier": 1.0}, wantErr: ForbiddenError{}},
+		{name: "someone else's private link", word: "payroll", userID: "bob", wantErr: NotFoundError{}},
+		{name: "missing link", word: "missing", userID: "alice", wantErr: NotFoundError{}},
+		{name: "invalid metadata", word: "pay", userID: "alice", metadata: map[string]interface{}{"": 1.0}, wantErr: InvalidQueryError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"pay": {
+					ID: 1, Word: "pay", Link: "https://pay.example.com", User: "alice", Description: "Payments",
+					Metadata: map[string]interface{}{"team": "payments"},
+				},
+				"payroll": {ID: 2, Word: "payroll", Link: "https://payroll.example.com", User: "alice", Private: true},
+			}}
+			service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAdmins([]string{"root"}))
+
+			shortcut, err := service.SetMetadata(context.Background(), tt.word, tt.metadata, tt.userID)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.SetMetadata() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.SetMetadata() error = %v", err)
+			}
+
+			// The metadata replaces the old one in a new version keeping everything else
+			if !reflect.DeepEqual(shortcut.Metadata, tt.want) || shortcut.Description != "Payments" ||
+				shortcut.UpdatedBy != tt.userID || len(shortcutRepo.history) != 1 {
+				t.Errorf("LinkService.SetMetadata() = %+v, want metadata %v stored as the newest version", shortcut, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkService_UpdateLinkKeepsMetadata(t *testing.T) {
+	ctx := context.Background()
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"pay": {ID: 1, Word: "pay", Link: "https://pay.example.com", User: "alice", Metadata: map[string]interface{}{"team": "payments"}},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		want     map[string]interface{}
+	}{
+		{name: "left out keeps it", want: map[string]interface{}{"team": "payments"}},
+		{name: "given replaces it", metadata: map[string]interface{}{"team": "billing"}, want: map[string]interface{}{"team": "billing"}},
+		{name: "empty clears it", metadata: map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := domain.LinkRequest{Word: "pay", Link: "https://pay.example.com/v2", Metadata: tt.metadata}
+			if err := service.UpdateLink(ctx, req, "alice"); err != nil {
+				t.Fatalf("LinkService.UpdateLink() error = %v", err)
+			}
+			if got := shortcutRepo.shortcuts["pay"].Metadata; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LinkService.UpdateLink() stored metadata %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
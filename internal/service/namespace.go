@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// NamespaceRepository interface for namespace operations
+type NamespaceRepository interface {
+	Create(ctx context.Context, namespace *domain.Namespace) error
+	Get(ctx context.Context, name string) (*domain.Namespace, error)
+	List(ctx context.Context) ([]domain.Namespace, error)
+	Delete(ctx context.Context, name string) (int64, error)
+	MemberRole(ctx context.Context, name, user string) (domain.NamespaceRole, error)
+	SetMember(ctx context.Context, name string, member *domain.NamespaceMember) error
+	RemoveMember(ctx context.Context, name, user string) (int64, error)
+	CountLinks(ctx context.Context, name string) (int, error)
+}
+
+// NamespaceChecker tells which words belong to a team namespace and who may edit them
+type NamespaceChecker interface {
+	NamespaceOf(ctx context.Context, word string) (string, error)
+	IsMember(ctx context.Context, namespace, userID string) (bool, error)
+}
+
+// namespacePattern is what namespace names may look like; they are the part of a word
+// before its first '/'
+var namespacePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// WithNamespaces restricts words under a team namespace to the namespace's members
+func WithNamespaces(namespaces NamespaceChecker) Option {
+	return func(s *LinkService) {
+		s.namespaces = namespaces
+	}
+}
+
+// NamespaceService manages team namespaces. Anyone may create a namespace and becomes its
+// owner; owners and admins manage its members, who alone can edit the namespace's links.
+type NamespaceService struct {
+	repo   NamespaceRepository
+	admins AdminChecker
+}
+
+// NewNamespaceService creates a new namespace service
+func NewNamespaceService(repo NamespaceRepository, admins AdminChecker) *NamespaceService {
+	return &NamespaceService{repo: repo, admins: admins}
+}
+
+// NamespaceOf returns the namespace a word belongs to, or "" if it isn't in one
+func (s *NamespaceService) NamespaceOf(ctx context.Context, word string) (string, error) {
+	name, _, found := strings.Cut(word, "/")
+	if !found || !namespacePattern.MatchString(name) {
+		return "", nil
+	}
+
+	namespace, err := s.repo.Get(ctx, name)
+	if err != nil || namespace == nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// IsMember reports whether a user belongs to a namespace
+func (s *NamespaceService) IsMember(ctx context.Context, namespace, userID string) (bool, error) {
+	role, err := s.repo.MemberRole(ctx, namespace, userID)
+	if err != nil {
+		return false, err
+	}
+	return role.Valid(), nil
+}
+
+// ListNamespaces returns every namespace, ordered by name
+func (s *NamespaceService) ListNamespaces(ctx context.Context) ([]domain.Namespace, error) {
+	namespaces, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if namespaces == nil {
+		namespaces = []domain.Namespace{}
+	}
+	return namespaces, nil
+}
+
+// GetNamespace returns a namespace with its members
+func (s *NamespaceService) GetNamespace(ctx context.Context, name string) (*domain.Namespace, error) {
+	namespace, err := s.repo.Get(ctx, strings.TrimSpace(name))
+	if err != nil {
+		return nil, err
+	}
+	if namespace == nil {
+		return nil, NotFoundError{Message: fmt.Sprintf("No namespace named %s", name)}
+	}
+	return namespace, nil
+}
+
+// CreateNamespace creates a namespace owned by requester
+func (s *NamespaceService) CreateNamespace(
+	ctx context.Context, req domain.NamespaceRequest, requester string,
+) (*domain.Namespace, error) {
+
+	name := strings.TrimSpace(req.Name)
+	if !namespacePattern.MatchString(name) {
+		return nil, InvalidQueryError{
+			Message: "Namespace names are 1 to 32 lowercase letters, digits and '-', starting with a letter or digit",
+		}
+	}
+
+	existing, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("Namespace %s already exists", name)}
+	}
+
+	namespace := &domain.Namespace{Name: name, CreatedBy: requester}
+	if err := s.repo.Create(ctx, namespace); err != nil {
+		return nil, err
+	}
+	return namespace, nil
+}
+
+// DeleteNamespace removes an empty namespace
+func (s *NamespaceService) DeleteNamespace(ctx context.Context, name, requester string) error {
+	namespace, err := s.manageable(ctx, name, requester, "delete")
+	if err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountLinks(ctx, namespace.Name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return InvalidQueryError{
+			Message: fmt.Sprintf("Namespace %s still has %d links; delete them first", namespace.Name, count),
+		}
+	}
+
+	_, err = s.repo.Delete(ctx, namespace.Name)
+	return err
+}
+
+// SetMember adds a user to a namespace or changes their role in it
+func (s *NamespaceService) SetMember(
+	ctx context.Context, name, user string, req domain.NamespaceMemberRequest, requester string,
+) (*domain.NamespaceMember, error) {
+
+	namespace, err := s.manageable(ctx, name, requester, "manage members of")
+	if err != nil {
+		return nil, err
+	}
+
+	user = strings.TrimSpace(user)
+	if user == "" {
+		return nil, InvalidQueryError{Message: "A user is required"}
+	}
+	role := req.Role
+	if role == "" {
+		role = domain.NamespaceMemberRole
+	}
+	if !role.Valid() {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("Unknown namespace role %q; use member or owner", role)}
+	}
+	if role != domain.NamespaceOwnerRole && isLastOwner(namespace, user) {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("%s is the last owner of %s", user, namespace.Name)}
+	}
+
+	member := &domain.NamespaceMember{User: user, Role: role}
+	if err := s.repo.SetMember(ctx, namespace.Name, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember takes a user out of a namespace. Members may also leave on their own.
+func (s *NamespaceService) RemoveMember(ctx context.Context, name, user, requester string) error {
+	user = strings.TrimSpace(user)
+
+	var namespace *domain.Namespace
+	var err error
+	if user == requester {
+		namespace, err = s.GetNamespace(ctx, name)
+	} else {
+		namespace, err = s.manageable(ctx, name, requester, "manage members of")
+	}
+	if err != nil {
+		return err
+	}
+
+	if isLastOwner(namespace, user) {
+		return InvalidQueryError{Message: fmt.Sprintf("%s is the last owner of %s", user, namespace.Name)}
+	}
+
+	removed, err := s.repo.RemoveMember(ctx, namespace.Name, user)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return NotFoundError{Message: fmt.Sprintf("%s is not a member of %s", user, namespace.Name)}
+	}
+	return nil
+}
+
+// manageable fetches a namespace, returning a ForbiddenError unless requester owns it or is an admin
+func (s *NamespaceService) manageable(
+	ctx context.Context, name, requester, action string,
+) (*domain.Namespace, error) {
+
+	namespace, err := s.GetNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.repo.MemberRole(ctx, namespace.Name, requester)
+	if err != nil {
+		return nil, err
+	}
+	if role == domain.NamespaceOwnerRole {
+		return namespace, nil
+	}
+
+	isAdmin, err := s.admins.IsAdmin(ctx, requester)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ForbiddenError{Message: fmt.Sprintf("Only owners of %s or an admin can %s it", namespace.Name, action)}
+	}
+	return namespace, nil
+}
+
+// isLastOwner reports whether user is the only owner of a namespace
+func isLastOwner(namespace *domain.Namespace, user string) bool {
+	owners := 0
+	isOwner := false
+	for _, member := range namespace.Members {
+		if member.Role == domain.NamespaceOwnerRole {
+			owners++
+			isOwner = isOwner || member.User == user
+		}
+	}
+	return isOwner && owners == 1
+}
+
+// namespaceMember reports the namespace a word is in, if any, and whether userID belongs to it
+func (s *LinkService) namespaceMember(ctx context.Context, word, userID string) (string, bool, error) {
+	if s.namespaces == nil {
+		return "", false, nil
+	}
+
+	namespace, err := s.namespaces.NamespaceOf(ctx, word)
+	if err != nil || namespace == "" {
+		return "", false, err
+	}
+
+	member, err := s.namespaces.IsMember(ctx, namespace, userID)
+	if err != nil {
+		return "", false, err
+	}
+	return namespace, member, nil
+}
+
+// checkNamespacedWord returns an error unless userID may write word, which is in namespace
+func (s *LinkService) checkNamespacedWord(ctx context.Context, word, namespace string, member bool, userID string) error {
+	if strings.Contains(strings.TrimPrefix(word, namespace+"/"), "/") {
+		return InvalidQueryError{Message: fmt.Sprintf("Words in the %s namespace cannot contain another '/'", namespace)}
+	}
+	if member {
+		return nil
+	}
+
+	isAdmin, err := s.isAdmin(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return ForbiddenError{Message: fmt.Sprintf("Only members of the %s namespace can add links to it", namespace)}
+	}
+	return nil
+}
+
+// aliasTarget returns the word an alias stored under word points at. Inside a namespace,
+// a target without a '/' means the namespace's own word of that name if there is one, so
+// payments/rb -> runbook leads to payments/runbook.
+func (s *LinkService) aliasTarget(ctx context.Context, word, link, userID string) (string, error) {
+	if s.namespaces == nil || strings.Contains(link, "/") {
+		return link, nil
+	}
+
+	namespace, err := s.namespaces.NamespaceOf(ctx, word)
+	if err != nil || namespace == "" {
+		return link, err
+	}
+
+	local, err := s.shortcutRepo.GetByWord(ctx, namespace+"/"+strings.TrimSpace(link))
+	if err != nil {
+		return "", fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	if visibleTo(local, userID) {
+		return local.Word, nil
+	}
+	return link, nil
+}
+
+// moveLastSegment moves the last path segment of a namespaced query to the front of the
+// search term, leaving other queries as they are
+func (s *LinkService) moveLastSegment(ctx context.Context, word, searchTerm string) (string, string, error) {
+	i := strings.LastIndex(word, "/")
+	if s.namespaces == nil || strings.Count(word, "/") < 2 || i == len(word)-1 {
+		return word, searchTerm, nil
+	}
+
+	namespace, err := s.namespaces.NamespaceOf(ctx, word)
+	if err != nil || namespace == "" {
+		return word, searchTerm, err
+	}
+
+	return word[:i], strings.TrimSpace(word[i+1:] + " " + searchTerm), nil
+}
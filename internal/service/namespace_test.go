@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+type mockNamespaceRepository struct {
+	namespaces map[string]*domain.Namespace
+	links      map[string]int
+}
+
+func newMockNamespaceRepository(namespaces ...*domain.Namespace) *mockNamespaceRepository {
+	m := &mockNamespaceRepository{namespaces: map[string]*domain.Namespace{}, links: map[string]int{}}
+	for _, namespace := range namespaces {
+		m.namespaces[namespace.Name] = namespace
+	}
+	return m
+}
+
+func (m *mockNamespaceRepository) Create(ctx context.Context, namespace *domain.Namespace) error {
+	namespace.Members = []domain.NamespaceMember{{User: namespace.CreatedBy, Role: domain.NamespaceOwnerRole}}
+	m.namespaces[namespace.Name] = namespace
+	return nil
+}
+
+func (m *mockNamespaceRepository) Get(ctx context.Context, name string) (*domain.Namespace, error) {
+	return m.namespaces[name], nil
+}
+
+func (m *mockNamespaceRepository) List(ctx context.Context) ([]domain.Namespace, error) {
+	var namespaces []domain.Namespace
+	for _, namespace := range m.namespaces {
+		namespaces = append(namespaces, *namespace)
+	}
+	return namespaces, nil
+}
+
+func (m *mockNamespaceRepository) Delete(ctx context.Context, name string) (int64, error) {
+	if _, ok := m.namespaces[name]; !ok {
+		return 0, nil
+	}
+	delete(m.namespaces, name)
+	return 1, nil
+}
+
+func (m *mockNamespaceRepository) MemberRole(ctx context.Context, name, user string) (domain.NamespaceRole, error) {
+	if namespace, ok := m.namespaces[name]; ok {
+		for _, member := range namespace.Members {
+			if member.User == user {
+				return member.Role, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (m *mockNamespaceRepository) SetMember(ctx context.Context, name string, member *domain.NamespaceMember) error {
+	namespace := m.namespaces[name]
+	for i := range namespace.Members {
+		if namespace.Members[i].User == member.User {
+			namespace.Members[i].Role = member.Role
+			return nil
+		}
+	}
+	namespace.Members = append(namespace.Members, *member)
+	return nil
+}
+
+func (m *mockNamespaceRepository) RemoveMember(ctx context.Context, name, user string) (int64, error) {
+	namespace := m.namespaces[name]
+	for i, member := range namespace.Members {
+		if member.User == user {
+			namespace.Members = append(namespace.Members[:i], namespace.Members[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *mockNamespaceRepository) CountLinks(ctx context.Context, name string) (int, error) {
+	return m.links[name], nil
+}
+
+// paymentsNamespace is owned by alice, with bob as a member
+func paymentsNamespace() *domain.Namespace {
+	return &domain.Namespace{Name: "payments", CreatedBy: "alice", Members: []domain.NamespaceMember{
+		{User: "alice", Role: domain.NamespaceOwnerRole},
+		{User: "bob", Role: domain.NamespaceMemberRole},
+	}}
+}
+
+func TestNamespaceService_CreateNamespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     domain.NamespaceRequest
+		wantErr error
+	}{
+		{"valid", domain.NamespaceRequest{Name: "infra"}, nil},
+		{"trimmed", domain.NamespaceRequest{Name: " data-eng "}, nil},
+		{"empty", domain.NamespaceRequest{Name: ""}, InvalidQueryError{}},
+		{"uppercase", domain.NamespaceRequest{Name: "Infra"}, InvalidQueryError{}},
+		{"slash", domain.NamespaceRequest{Name: "infra/team"}, InvalidQueryError{}},
+		{"leading dash", domain.NamespaceRequest{Name: "-infra"}, InvalidQueryError{}},
+		{"too long", domain.NamespaceRequest{Name: strings.Repeat("a", 33)}, InvalidQueryError{}},
+		{"exists", domain.NamespaceRequest{Name: "payments"}, InvalidQueryError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewNamespaceService(newMockNamespaceRepository(paymentsNamespace()), adminSet(nil))
+
+			namespace, err := service.CreateNamespace(context.Background(), tt.req, "carol")
+			if (tt.wantErr == nil) != (err == nil) || (err != nil && !sameErrorType(err, tt.wantErr)) {
+				t.Fatalf("NamespaceService.CreateNamespace() error = %v, want %T", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if namespace.Name != strings.TrimSpace(tt.req.Name) || namespace.CreatedBy != "carol" {
+				t.Errorf("NamespaceService.CreateNamespace() = %+v", namespace)
+			}
+			if member, _ := service.IsMember(context.Background(), namespace.Name, "carol"); !member {
+				t.Error("NamespaceService.CreateNamespace() should make the creator a member")
+			}
+		})
+	}
+}
+
+func TestNamespaceService_NamespaceOf(t *testing.T) {
+	service := NewNamespaceService(newMockNamespaceRepository(paymentsNamespace()), adminSet(nil))
+
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"payments/runbook", "payments"},
+		{"payments/runbook/2024", "payments"},
+		{"payments", ""},
+		{"infra/runbook", ""},
+		{"Payments/runbook", ""},
+		{"docs", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got, err := service.NamespaceOf(context.Background(), tt.word)
+			if err != nil || got != tt.want {
+				t.Errorf("NamespaceService.NamespaceOf() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceService_Members(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    func(s *NamespaceService) error
+		wantErr   error
+		wantRoles map[string]domain.NamespaceRole
+	}{
+		{
+			name: "owner adds member",
+			action: func(s *NamespaceService) error {
+				_, err := s.SetMember(context.Background(), "payments", "carol", domain.NamespaceMemberRequest{}, "alice")
+				return err
+			},
+			wantRoles: map[string]domain.NamespaceRole{"carol": domain.NamespaceMemberRole},
+		},
+		{
+			name: "owner promotes member",
+			action: func(s *NamespaceService) error {
+				_, err := s.SetMember(context.Background(), "payments", "bob", domain.NamespaceMemberRequest{Role: domain.NamespaceOwnerRole}, "alice")
+				return err
+			},
+			wantRoles: map[string]domain.NamespaceRole{"bob": domain.NamespaceOwnerRole},
+		},
+		{
+			name: "admin adds member",
+			action: func(s *NamespaceService) error {
+				_, err := s.SetMember(context.Background(), "payments", "carol", domain.NamespaceMemberRequest{}, "root")
+				return err
+			},
+			wantRoles: map[string]domain.NamespaceRole{"carol": domain.NamespaceMemberRole},
+		},
+		{
+			name: "member cannot add member",
+			action: func(s *NamespaceService) error {
+				_, err := s.SetMember(context.Background(), "payments", "carol", domain.NamespaceMemberRequest{}, "bob")
+				return err
+			},
+			wantErr:   ForbiddenError{},
+			wantRoles: map[string]domain.NamespaceRole{"carol": ""},
+		},
+		{
+			name: "unknown role",
+			action: func(s *NamespaceService) error {
+				_, err := s.SetMember(context.Background(), "payments", "carol", domain.NamespaceMemberRequest{Role: "admin"}, "alice")
+				return err
+			},
+			wantErr: InvalidQueryError{},
+		},
+		{
+			name: "last owner cannot step down",
+			action: func(s *NamespaceService) error {
+				_, err := s.SetMember(context.Background(), "payments", "alice", domain.NamespaceMemberRequest{Role: domain.NamespaceMemberRole}, "alice")
+				return err
+			},
+			wantErr:   InvalidQueryError{},
+			wantRoles: map[string]domain.NamespaceRole{"alice": domain.NamespaceOwnerRole},
+		},
+		{
+			name: "unknown namespace",
+			action: func(s *NamespaceService) error {
+				_, err := s.SetMember(context.Background(), "infra", "carol", domain.NamespaceMemberRequest{}, "root")
+				return err
+			},
+			wantErr: NotFoundError{},
+		},
+		{
+			name: "owner removes member",
+			action: func(s *NamespaceService) error {
+				return s.RemoveMember(context.Background(), "payments", "bob", "alice")
+			},
+			wantRoles: map[string]domain.NamespaceRole{"bob": ""},
+		},
+		{
+			name: "member leaves",
+			action: func(s *NamespaceService) error {
+				return s.RemoveMember(context.Background(), "payments", "bob", "bob")
+			},
+			wantRoles: map[string]domain.NamespaceRole{"bob": ""},
+		},
+		{
+			name: "member cannot remove owner",
+			action: func(s *NamespaceService) error {
+				return s.RemoveMember(context.Background(), "payments", "alice", "bob")
+			},
+			wantErr:   ForbiddenError{},
+			wantRoles: map[string]domain.NamespaceRole{"alice": domain.NamespaceOwnerRole},
+		},
+		{
+			name: "last owner cannot leave",
+			action: func(s *NamespaceService) error {
+				return s.RemoveMember(context.Background(), "payments", "alice", "alice")
+			},
+			wantErr: InvalidQueryError{},
+		},
+		{
+			name: "removing a non-member",
+			action: func(s *NamespaceService) error {
+				return s.RemoveMember(context.Background(), "payments", "carol", "alice")
+			},
+			wantErr: NotFoundError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockNamespaceRepository(paymentsNamespace())
+			service := NewNamespaceService(repo, adminSet([]string{"root"}))
+
+			err := tt.action(service)
+			if (tt.wantErr == nil) != (err == nil) || (err != nil && !sameErrorType(err, tt.wantErr)) {
+				t.Fatalf("error = %v, want %T", err, tt.wantErr)
+			}
+			for user, want := range tt.wantRoles {
+				if role, _ := repo.MemberRole(context.Background(), "payments", user); role != want {
+					t.Errorf("role of %s = %q, want %q", user, role, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNamespaceService_DeleteNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		links     int
+		requester string
+		wantErr   error
+	}{
+		{"owner deletes empty namespace", 0, "alice", nil},
+		{"admin deletes empty namespace", 0, "root", nil},
+		{"member cannot delete", 0, "bob", ForbiddenError{}},
+		{"namespace with links", 2, "alice", InvalidQueryError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockNamespaceRepository(paymentsNamespace())
+			repo.links["payments"] = tt.links
+			service := NewNamespaceService(repo, adminSet([]string{"root"}))
+
+			err := service.DeleteNamespace(context.Background(), "payments", tt.requester)
+			if (tt.wantErr == nil) != (err == nil) || (err != nil && !sameErrorType(err, tt.wantErr)) {
+				t.Fatalf("NamespaceService.DeleteNamespace() error = %v, want %T", err, tt.wantErr)
+			}
+			if _, exists := repo.namespaces["payments"]; exists != (tt.wantErr != nil) {
+				t.Errorf("namespace exists = %v after delete", exists)
+			}
+		})
+	}
+}
+
+func TestLinkService_Namespaces(t *testing.T) {
+	newService := func() (*LinkService, *mockShortcutRepository) {
+		shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+			"payments/runbook": {ID: 1, Word: "payments/runbook", Link: "https://runbook.example.com/{*}", User: "alice"},
+			"payments/rb":      {ID: 2, Word: "payments/rb", Link: "runbook", User: "alice"},
+			"runbook":          {ID: 3, Word: "runbook", Link: "https://global.example.com", User: "dave"},
+			"payments/wiki":    {ID: 4, Word: "payments/wiki", Link: "https://wiki.example.com", User: "alice", Private: true},
+		}}
+		namespaces := NewNamespaceService(newMockNamespaceRepository(paymentsNamespace()), adminSet(nil))
+		service := NewLinkService(shortcutRepo, &mockQueryRepository{},
+			WithAdmins([]string{"root"}), WithNamespaces(namespaces))
+		return service, shortcutRepo
+	}
+
+	updateTests := []struct {
+		name      string
+		req       domain.LinkRequest
+		userID    string
+		wantErr   error
+		wantOwner string
+	}{
+		{
+			name:      "member edits another member's link",
+			req:       domain.LinkRequest{Word: "payments/runbook", Link: "https://new.example.com"},
+			userID:    "bob",
+			wantOwner: "alice",
+		},
+		{
+			name:      "member adds a link",
+			req:       domain.LinkRequest{Word: "payments/oncall", Link: "https://oncall.example.com"},
+			userID:    "bob",
+			wantOwner: "bob",
+		},
+		{
+			name:    "non-member cannot add a link",
+			req:     domain.LinkRequest{Word: "payments/oncall", Link: "https://oncall.example.com"},
+			userID:  "carol",
+			wantErr: ForbiddenError{},
+		},
+		{
+			name:    "non-member cannot edit a link",
+			req:     domain.LinkRequest{Word: "payments/runbook", Link: "https://evil.example.com", Force: true},
+			userID:  "carol",
+			wantErr: ForbiddenError{},
+		},
+		{
+			name:      "admin adds a link",
+			req:       domain.LinkRequest{Word: "payments/oncall", Link: "https://oncall.example.com"},
+			userID:    "root",
+			wantOwner: "root",
+		},
+		{
+			name:    "member cannot edit another member's private link",
+			req:     domain.LinkRequest{Word: "payments/wiki", Link: "https://new.example.com"},
+			userID:  "bob",
+			wantErr: ForbiddenError{},
+		},
+		{
+			name:    "nested words are rejected",
+			req:     domain.LinkRequest{Word: "payments/runbook/old", Link: "https://old.example.com"},
+			userID:  "alice",
+			wantErr: InvalidQueryError{},
+		},
+		{
+			name:      "words in unknown namespaces are ordinary words",
+			req:       domain.LinkRequest{Word: "infra/runbook", Link: "https://infra.example.com"},
+			userID:    "carol",
+			wantOwner: "carol",
+		},
+	}
+
+	for _, tt := range updateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, shortcutRepo := newService()
+
+			err := service.UpdateLink(context.Background(), tt.req, tt.userID)
+			if (tt.wantErr == nil) != (err == nil) || (err != nil && !sameErrorType(err, tt.wantErr)) {
+				t.Fatalf("LinkService.UpdateLink() error = %v, want %T", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && shortcutRepo.shortcuts[tt.req.Word].User != tt.wantOwner {
+				t.Errorf("owner = %q, want %q", shortcutRepo.shortcuts[tt.req.Word].User, tt.wantOwner)
+			}
+		})
+	}
+
+	resolveTests := []struct {
+		name  string
+		word  string
+		query string
+		want  string
+	}{
+		{"namespaced word", "payments/runbook", "", "https://runbook.example.com/"},
+		{"alias resolves inside its namespace", "payments/rb", "", "https://runbook.example.com/"},
+		{"extra segments are search terms", "payments/runbook/2024", "", "https://runbook.example.com/2024"},
+		{"extra segments come before the search term", "payments/runbook/2024", "q3", "https://runbook.example.com/2024%20q3"},
+	}
+
+	for _, tt := range resolveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newService()
+
+			got, err := service.GetLink(context.Background(), tt.word, tt.query, "carol")
+			if err != nil || got != tt.want {
+				t.Errorf("LinkService.GetLink() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
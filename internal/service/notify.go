@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+const (
+	// notifyBatch is the most changes announced in one message
+	notifyBatch = 20
+
+	// notifySendTimeout bounds each message, so a stuck webhook cannot hold the sender forever
+	notifySendTimeout = 10 * time.Second
+)
+
+// NotificationSender posts a message announcing link changes to a channel, as the webhook
+// client does
+type NotificationSender interface {
+	Send(ctx context.Context, events []domain.LinkEvent) error
+}
+
+// Notifier announces link changes to a channel in the background, so saving a link doesn't
+// wait on the webhook. Changes queue in a buffer and are announced in messages of whatever
+// has queued up since the last one, so an import makes a few messages rather than one per
+// link. When the buffer is full, changes are dropped rather than slowing edits down.
+type Notifier struct {
+	sender NotificationSender
+	queue  chan domain.LinkEvent
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	sent    atomic.Int64
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+// NewNotifier starts a background sender announcing changes through sender, queueing up
+// to buffer of them while it sends. Close stops it.
+func NewNotifier(sender NotificationSender, buffer int) *Notifier {
+	n := &Notifier{
+		sender: sender,
+		queue:  make(chan domain.LinkEvent, buffer),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+// WithNotifier announces changes to public golinks through notifier. A nil notifier
+// announces nothing.
+func WithNotifier(notifier *Notifier) Option {
+	return func(s *LinkService) {
+		s.notifier = notifier
+	}
+}
+
+// Notify queues event to be announced, reporting false if it was dropped because the
+// buffer is full or the notifier is closed
+func (n *Notifier) Notify(event domain.LinkEvent) bool {
+	select {
+	case <-n.stop:
+		n.dropped.Add(1)
+		return false
+	default:
+	}
+
+	select {
+	case n.queue <- event:
+		return true
+	default:
+		n.dropped.Add(1)
+		return false
+	}
+}
+
+// Stats reports how many changes are waiting to be announced, and how many were sent,
+// dropped because the buffer was full, or lost because the webhook failed
+func (n *Notifier) Stats() domain.NotifierStats {
+	return domain.NotifierStats{
+		Queued:  len(n.queue),
+		Sent:    n.sent.Load(),
+		Dropped: n.dropped.Load(),
+		Failed:  n.failed.Load(),
+	}
+}
+
+// Close announces the changes still queued and stops the sender, giving up waiting when
+// ctx is done. Changes made after Close are dropped.
+func (n *Notifier) Close(ctx context.Context) error {
+	n.stopOnce.Do(func() { close(n.stop) })
+	select {
+	case <-n.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *Notifier) run() {
+	defer close(n.done)
+
+	batch := make([]domain.LinkEvent, 0, notifyBatch)
+	for {
+		select {
+		case event := <-n.queue:
+			batch = append(batch[:0], event)
+		case <-n.stop:
+			n.drain(batch[:0])
+			return
+		}
+		n.send(n.fill(batch))
+	}
+}
+
+// fill adds changes already queued to batch, up to notifyBatch, without waiting for more
+func (n *Notifier) fill(batch []domain.LinkEvent) []domain.LinkEvent {
+	for len(batch) < notifyBatch {
+		select {
+		case event := <-n.queue:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// drain announces every change left in the queue
+func (n *Notifier) drain(batch []domain.LinkEvent) {
+	for {
+		batch = n.fill(batch[:0])
+		if len(batch) == 0 {
+			return
+		}
+		n.send(batch)
+	}
+}
+
+// send announces batch in one message
+func (n *Notifier) send(batch []domain.LinkEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifySendTimeout)
+	defer cancel()
+
+	if err := n.sender.Send(ctx, batch); err != nil {
+		n.failed.Add(int64(len(batch)))
+		return
+	}
+	n.sent.Add(int64(len(batch)))
+}
+
+// notify announces that userID changed word from before to after, either of which is nil
+// if the link was created or deleted. Changes to private links are kept to their owner.
+func (s *LinkService) notify(userID string, before, after *domain.Shortcut) {
+	if s.notifier == nil {
+		return
+	}
+	if (before != nil && before.Private) || (after != nil && after.Private) {
+		return
+	}
+
+	event := domain.LinkEvent{Action: domain.LinkUpdated, User: userID, Before: before, After: after, Time: s.now()}
+	switch {
+	case before == nil && after == nil:
+		return
+	case before == nil:
+		event.Action, event.Word = domain.LinkCreated, after.Word
+	case after == nil:
+		event.Action, event.Word = domain.LinkDeleted, before.Word
+	default:
+		event.Word = after.Word
+	}
+	s.notifier.Notify(event)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// recordingSender records the messages a Notifier sends. Messages holding the reject
+// word fail, and when release is set each send waits for it to close after announcing
+// itself on started.
+type recordingSender struct {
+	mu       sync.Mutex
+	messages [][]domain.LinkEvent
+	reject   string
+
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *recordingSender) Send(ctx context.Context, events []domain.LinkEvent) error {
+	if s.release != nil {
+		s.started <- struct{}{}
+		<-s.release
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		if event.Word == s.reject {
+			return errors.New("webhook answered 500 Internal Server Error")
+		}
+	}
+	s.messages = append(s.messages, append([]domain.LinkEvent(nil), events...))
+	return nil
+}
+
+// announced returns the changes sent, each as action and word, in order
+func (s *recordingSender) announced() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var changes []string
+	for _, message := range s.messages {
+		for _, event := range message {
+			changes = append(changes, event.Action+" "+event.Word)
+		}
+	}
+	return changes
+}
+
+func TestNotifier(t *testing.T) {
+	sender := &recordingSender{
+		reject:  "broken",
+		started: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+	notifier := NewNotifier(sender, 2)
+
+	// The sender takes the first change and holds it while two more fill the queue
+	if !notifier.Notify(domain.LinkEvent{Action: domain.LinkCreated, Word: "docs"}) {
+		t.Fatal("Notifier.Notify() dropped the first change")
+	}
+	<-sender.started
+	for _, word := range []string{"wiki", "broken"} {
+		if !notifier.Notify(domain.LinkEvent{Action: domain.LinkUpdated, Word: word}) {
+			t.Fatalf("Notifier.Notify(%s) dropped a change with room in the queue", word)
+		}
+	}
+	if notifier.Notify(domain.LinkEvent{Action: domain.LinkDeleted, Word: "extra"}) {
+		t.Error("Notifier.Notify() queued a change beyond the buffer")
+	}
+	if stats := notifier.Stats(); stats.Queued != 2 || stats.Dropped != 1 {
+		t.Errorf("Notifier.Stats() = %+v, want 2 queued and 1 dropped", stats)
+	}
+
+	close(sender.release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := notifier.Close(ctx); err != nil {
+		t.Fatalf("Notifier.Close() error = %v", err)
+	}
+
+	// wiki and broken went out in one message, which the webhook rejected
+	if changes := sender.announced(); len(changes) != 1 || changes[0] != "created docs" {
+		t.Errorf("announced %v, want only created docs", changes)
+	}
+	if stats := notifier.Stats(); stats != (domain.NotifierStats{Sent: 1, Dropped: 1, Failed: 2}) {
+		t.Errorf("Notifier.Stats() = %+v, want 1 sent, 1 dropped and 2 failed", stats)
+	}
+
+	if notifier.Notify(domain.LinkEvent{Action: domain.LinkCreated, Word: "late"}) {
+		t.Error("Notifier.Notify() queued a change after Close")
+	}
+}
+
+func TestLinkService_Notify(t *testing.T) {
+	ctx := context.Background()
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs":   {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"secret": {ID: 2, Word: "secret", Link: "https://secret.example.com", User: "alice", Private: true},
+	}}
+	sender := &recordingSender{}
+	notifier := NewNotifier(sender, 100)
+	aliases := &mockAliasRepository{repo: shortcutRepo, aliases: map[string]string{}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithNotifier(notifier), WithAliases(aliases))
+	changed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return changed }
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"update", func() error {
+			return service.UpdateLink(ctx, domain.LinkRequest{Word: "docs", Link: "https://new.example.com"}, "alice")
+		}},
+		{"create", func() error {
+			return service.UpdateLink(ctx, domain.LinkRequest{Word: "wiki", Link: "https://wiki.example.com"}, "bob")
+		}},
+		{"update private", func() error {
+			return service.UpdateLink(ctx, domain.LinkRequest{Word: "secret", Link: "https://other.example.com", Private: true}, "alice")
+		}},
+		{"create private", func() error {
+			return service.UpdateLink(ctx, domain.LinkRequest{Word: "mine", Link: "https://mine.example.com", Private: true}, "bob")
+		}},
+		{"delete", func() error { return service.DeleteLink(ctx, "wiki", "bob") }},
+		{"restore", func() error { _, err := service.RestoreLink(ctx, "wiki", "admin"); return err }},
+		{"bulk", func() error {
+			_, err := service.BulkUpdateLinks(ctx, []domain.LinkRequest{{Word: "jira", Link: "https://jira.example.com"}}, "carol")
+			return err
+		}},
+		{"rename", func() error {
+			_, err := service.RenameLink(ctx, "jira", domain.RenameRequest{Word: "tracker"}, "carol")
+			return err
+		}},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s error = %v", step.name, err)
+		}
+	}
+	if err := notifier.Close(ctx); err != nil {
+		t.Fatalf("Notifier.Close() error = %v", err)
+	}
+
+	want := []string{"updated docs", "created wiki", "deleted wiki", "created wiki", "created jira", "updated tracker"}
+	changes := sender.announced()
+	if len(changes) != len(want) {
+		t.Fatalf("announced %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("announced %v, want %v", changes, want)
+			break
+		}
+	}
+
+	update := sender.messages[0][0]
+	if update.User != "alice" || !update.Time.Equal(changed) || update.Before == nil || update.After == nil ||
+		update.Before.Link != "https://docs.example.com" || update.After.Link != "https://new.example.com" {
+		t.Errorf("update event = %+v, want alice changing docs from its old link to its new one", update)
+	}
+
+	rename := sender.messages[len(sender.messages)-1]
+	if event := rename[len(rename)-1]; event.Before == nil || event.Before.Word != "jira" || event.After.Word != "tracker" {
+		t.Errorf("rename event = %+v, want jira renamed to tracker", event)
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// AdminChecker reports whether a user has admin rights
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID string) (bool, error)
+}
+
+// WithAdmins sets the users allowed to modify golinks owned by someone else
+func WithAdmins(users []string) Option {
+	return func(s *LinkService) {
+		s.admins = adminSet(users)
+	}
+}
+
+// WithRoles decides who may modify golinks owned by someone else using assigned roles
+func WithRoles(roles AdminChecker) Option {
+	return func(s *LinkService) {
+		s.admins = roles
+	}
+}
+
+// adminList is a fixed set of admin users
+type adminList map[string]bool
+
+// IsAdmin reports whether userID is in the list
+func (a adminList) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	return a[userID], nil
+}
+
+// adminSet builds a lookup of admin users, ignoring blank names
+func adminSet(users []string) adminList {
+	admins := adminList{}
+	for _, user := range users {
+		if user = strings.TrimSpace(user); user != "" {
+			admins[user] = true
+		}
+	}
+	return admins
+}
+
+// isAdmin reports whether a user has admin rights over all golinks
+func (s *LinkService) isAdmin(ctx context.Context, userID string) (bool, error) {
+	if s.admins == nil {
+		return false, nil
+	}
+	return s.admins.IsAdmin(ctx, userID)
+}
+
+// canModify reports whether userID may change or remove an existing golink
+func (s *LinkService) canModify(ctx context.Context, existing *domain.Shortcut, userID string) (bool, error) {
+	if existing == nil || existing.User == userID {
+		return true, nil
+	}
+
+	// Members of a namespace share its links, apart from each other's private ones
+	if !existing.Private {
+		_, member, err := s.namespaceMember(ctx, existing.Word, userID)
+		if err != nil || member {
+			return member, err
+		}
+	}
+
+	return s.isAdmin(ctx, userID)
+}
+
+// visibleTo reports whether userID may see a golink; private links are only visible to their owner
+func visibleTo(shortcut *domain.Shortcut, userID string) bool {
+	return shortcut != nil && (!shortcut.Private || shortcut.User == userID)
+}
+
+// ownerFor decides who owns the version of a word that userID is about to write.
+// Owners may update or hand over their own links, as may members of the namespace the
+// word is in; admins must set Force to overwrite someone else's link. Either way the link
+// keeps its current owner unless Owner names a new one.
+func (s *LinkService) ownerFor(
+	ctx context.Context, existing *domain.Shortcut, req domain.LinkRequest, userID string, member bool,
+) (string, error) {
+
+	newOwner := strings.TrimSpace(req.Owner)
+	isAdmin, err := s.isAdmin(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case existing == nil:
+		if newOwner != "" && newOwner != userID && !isAdmin {
+			return "", ForbiddenError{Message: "Only admins can create links on behalf of other users"}
+		}
+	case existing.User == userID:
+		// Owners can edit and transfer their own links
+	case !isAdmin && existing.Private:
+		// Don't reveal who owns a private link
+		return "", ForbiddenError{Message: fmt.Sprintf("%s is already taken", existing.Word)}
+	case member && !existing.Private:
+		if newOwner == "" {
+			newOwner = existing.User
+		}
+	case !isAdmin:
+		return "", ForbiddenError{
+			Message: fmt.Sprintf("%s is owned by %s; only the owner or an admin can change it", existing.Word, existing.User),
+		}
+	case !req.Force:
+		return "", ForbiddenError{
+			Message: fmt.Sprintf("%s is owned by %s; set force to overwrite it as an admin", existing.Word, existing.User),
+		}
+	default:
+		if newOwner == "" {
+			newOwner = existing.User
+		}
+	}
+
+	if newOwner == "" {
+		newOwner = userID
+	}
+	return newOwner, nil
+}
+
+// TransferLink hands a golink over to another user, such as a teammate of someone leaving.
+// Owners may transfer their own links and admins anyone's. The transfer is stored as a new
+// version, so the link's history records who handed it to whom and when.
+func (s *LinkService) TransferLink(
+	ctx context.Context, word string, req domain.TransferRequest, userID string,
+) (*domain.Shortcut, error) {
+
+	word = NormalizeWord(word)
+	newOwner := strings.TrimSpace(req.Owner)
+	if newOwner == "" {
+		return nil, InvalidQueryError{Message: "A new owner is required"}
+	}
+
+	current, err := s.shortcutRepo.GetByWord(ctx, word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shortcut: %w", err)
+	}
+	isAdmin, err := s.isAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Don't reveal that someone else's private link exists
+	if current == nil || (!visibleTo(current, userID) && !isAdmin) {
+		return nil, NotFoundError{Message: fmt.Sprintf("No golink found for %s", word)}
+	}
+	if current.User != userID && !isAdmin {
+		return nil, ForbiddenError{Message: fmt.Sprintf("Only %s or an admin can transfer %s", current.User, word)}
+	}
+	if current.User == newOwner {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("%s already belongs to %s", word, newOwner)}
+	}
+
+	shortcut := &domain.Shortcut{
+		Word:        current.Word,
+		Link:        current.Link,
+		User:        newOwner,
+		Icon:        current.Icon,
+		Description: current.Description,
+		UpdatedBy:   userID,
+		Private:     current.Private,
+		Prefix:      current.Prefix,
+		Archived:    current.Archived,
+		Metadata:    current.Metadata,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.shortcutRepo.Create(ctx, shortcut); err != nil {
+		return nil, fmt.Errorf("failed to create shortcut: %w", err)
+	}
+	s.keywords.Invalidate()
+	s.notify(userID, current, shortcut)
+
+	return shortcut, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_UpdateLink_Ownership(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       domain.LinkRequest
+		userID    string
+		wantErr   bool
+		wantOwner string
+	}{
+		{
+			name:      "owner updates own link",
+			req:       domain.LinkRequest{Word: "docs", Link: "https://new.example.com"},
+			userID:    "alice",
+			wantOwner: "alice",
+		},
+		{
+			name:      "owner transfers link",
+			req:       domain.LinkRequest{Word: "docs", Link: "https://new.example.com", Owner: "carol"},
+			userID:    "alice",
+			wantOwner: "carol",
+		},
+		{
+			name:    "other user cannot shadow link",
+			req:     domain.LinkRequest{Word: "docs", Link: "https://evil.example.com"},
+			userID:  "bob",
+			wantErr: true,
+		},
+		{
+			name:    "other user cannot force",
+			req:     domain.LinkRequest{Word: "docs", Link: "https://evil.example.com", Force: true},
+			userID:  "bob",
+			wantErr: true,
+		},
+		{
+			name:    "admin must force",
+			req:     domain.LinkRequest{Word: "docs", Link: "https://new.example.com"},
+			userID:  "root",
+			wantErr: true,
+		},
+		{
+			name:      "admin force keeps owner",
+			req:       domain.LinkRequest{Word: "docs", Link: "https://new.example.com", Force: true},
+			userID:    "root",
+			wantOwner: "alice",
+		},
+		{
+			name:      "admin force transfers owner",
+			req:       domain.LinkRequest{Word: "docs", Link: "https://new.example.com", Force: true, Owner: "carol"},
+			userID:    "root",
+			wantOwner: "carol",
+		},
+		{
+			name:      "anyone can create new word",
+			req:       domain.LinkRequest{Word: "wiki", Link: "https://wiki.example.com"},
+			userID:    "bob",
+			wantOwner: "bob",
+		},
+		{
+			name:    "non-admin cannot create on behalf of others",
+			req:     domain.LinkRequest{Word: "wiki", Link: "https://wiki.example.com", Owner: "carol"},
+			userID:  "bob",
+			wantErr: true,
+		},
+		{
+			name:      "admin creates on behalf of others",
+			req:       domain.LinkRequest{Word: "wiki", Link: "https://wiki.example.com", Owner: "carol"},
+			userID:    "root",
+			wantOwner: "carol",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+			}}
+			service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAdmins([]string{"root"}))
+
+			err := service.UpdateLink(context.Background(), tt.req, tt.userID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LinkService.UpdateLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, ok := err.(ForbiddenError); !ok {
+					t.Errorf("LinkService.UpdateLink() error = %v, want ForbiddenError", err)
+				}
+				return
+			}
+			if got := shortcutRepo.shortcuts[tt.req.Word].User; got != tt.wantOwner {
+				t.Errorf("LinkService.UpdateLink() owner = %q, want %q", got, tt.wantOwner)
+			}
+		})
+	}
+}
+
+func TestLinkService_DeleteLink_Admin(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAdmins([]string{" root ", ""}))
+
+	if err := service.DeleteLink(context.Background(), "docs", "root"); err != nil {
+		t.Errorf("LinkService.DeleteLink() admin error = %v", err)
+	}
+}
+
+func TestLinkService_BulkUpdateLinks_Ownership(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+
+	results, err := service.BulkUpdateLinks(context.Background(), []domain.LinkRequest{
+		{Word: "docs", Link: "https://evil.example.com"},
+		{Word: "wiki", Link: "https://wiki.example.com"},
+	}, "bob")
+	if err != nil {
+		t.Fatalf("LinkService.BulkUpdateLinks() error = %v", err)
+	}
+	if results[0].Status != domain.BulkStatusFailed || results[1].Status != domain.BulkStatusCreated {
+		t.Errorf("LinkService.BulkUpdateLinks() = %+v, want docs failed and wiki created", results)
+	}
+}
+
+func TestLinkService_TransferLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		word      string
+		owner     string
+		userID    string
+		wantErr   error
+		wantOwner string
+	}{
+		{name: "owner hands over own link", word: "docs", owner: "carol", userID: "alice", wantOwner: "carol"},
+		{name: "admin hands over anyone's link", word: "docs", owner: " carol ", userID: "root", wantOwner: "carol"},
+		{name: "admin hands over a private link", word: "payroll", owner: "carol", userID: "root", wantOwner: "carol"},
+		{name: "other user", word: "docs", owner: "bob", userID: "bob", wantErr: ForbiddenError{}},
+		{name: "someone else's private link", word: "payroll", owner: "bob", userID: "bob", wantErr: NotFoundError{}},
+		{name: "missing link", word: "wiki", owner: "carol", userID: "alice", wantErr: NotFoundError{}},
+		{name: "no new owner", word: "docs", owner: " ", userID: "alice", wantErr: InvalidQueryError{}},
+		{name: "same owner", word: "docs", owner: "alice", userID: "alice", wantErr: InvalidQueryError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"docs":    {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice", Description: "Team docs"},
+				"payroll": {ID: 2, Word: "payroll", Link: "https://payroll.example.com", User: "alice", Private: true},
+			}}
+			service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAdmins([]string{"root"}))
+			before := shortcutRepo.shortcuts[tt.word]
+
+			shortcut, err := service.TransferLink(context.Background(), tt.word, domain.TransferRequest{Owner: tt.owner}, tt.userID)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.TransferLink() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.TransferLink() error = %v", err)
+			}
+
+			// The transfer is a new version keeping everything but the owner
+			if len(shortcutRepo.history) != 1 || shortcutRepo.shortcuts[tt.word] != shortcut {
+				t.Errorf("LinkService.TransferLink() = %+v, want it stored as the newest version", shortcut)
+			}
+			if shortcut.User != tt.wantOwner || shortcut.UpdatedBy != tt.userID {
+				t.Errorf("LinkService.TransferLink() owner = %q by %q, want %q by %q", shortcut.User, shortcut.UpdatedBy, tt.wantOwner, tt.userID)
+			}
+			if shortcut.Link != before.Link || shortcut.Description != before.Description || shortcut.Private != before.Private {
+				t.Errorf("LinkService.TransferLink() = %+v, want the link otherwise unchanged", shortcut)
+			}
+		})
+	}
+}
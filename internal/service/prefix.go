@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// prefixLink finds the longest prefix link userID can see that word is a path below, like
+// repo for repo/issues/123, and returns it with the rest of the path. It returns nil if
+// there is none.
+func (s *LinkService) prefixLink(ctx context.Context, word, userID string) (*domain.Shortcut, string, error) {
+	for i := strings.LastIndex(word, "/"); i > 0; i = strings.LastIndex(word[:i], "/") {
+		path := strings.Trim(word[i+1:], "/")
+		if path == "" {
+			continue
+		}
+
+		shortcut, err := s.shortcutRepo.GetByWord(ctx, word[:i])
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get shortcut: %w", err)
+		}
+		if visibleTo(shortcut, userID) && shortcut.Prefix {
+			return shortcut, path, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// appendPath adds path below the path of target, keeping its query string and fragment
+func appendPath(target, path string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return strings.TrimSuffix(target, "/") + "/" + path
+	}
+	return u.JoinPath(strings.Split(path, "/")...).String()
+}
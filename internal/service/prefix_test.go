@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_PrefixLinks(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"repo":        {ID: 1, Word: "repo", Link: "https://github.com/org/repo", User: "alice", Prefix: true},
+		"repo/wiki":   {ID: 2, Word: "repo/wiki", Link: "https://wiki.example.com/repo?lang=en", User: "alice", Prefix: true},
+		"docs":        {ID: 3, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"secret":      {ID: 4, Word: "secret", Link: "https://secret.example.com", User: "alice", Private: true, Prefix: true},
+		"repo/issues": {ID: 5, Word: "repo/issues", Link: "https://jira.example.com", User: "alice"},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    string
+		userID   string
+		want     string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "word itself", query: "repo", userID: "bob", want: "https://github.com/org/repo"},
+		{name: "path appended", query: "repo/issues/123", userID: "bob", want: "https://github.com/org/repo/issues/123", wantPath: "issues/123"},
+		{name: "exact word wins", query: "repo/issues", userID: "bob", want: "https://jira.example.com"},
+		{name: "longest prefix wins", query: "repo/wiki/Home", userID: "bob", want: "https://wiki.example.com/repo/Home?lang=en", wantPath: "Home"},
+		{name: "not a prefix link", query: "docs/page", userID: "bob", wantErr: true},
+		{name: "private prefix link for its owner", query: "secret/page", userID: "alice", want: "https://secret.example.com/page", wantPath: "page"},
+		{name: "private prefix link for others", query: "secret/page", userID: "bob", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := service.ResolveDetail(ctx, tt.query, false, tt.userID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LinkService.ResolveDetail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if res.URL != tt.want || res.Path != tt.wantPath {
+				t.Errorf("LinkService.ResolveDetail() = %s with path %q, want %s with path %q", res.URL, res.Path, tt.want, tt.wantPath)
+			}
+		})
+	}
+
+	err := service.UpdateLink(ctx, domain.LinkRequest{Word: "code", Link: "repo", Prefix: true}, "alice")
+	if !sameErrorType(err, InvalidQueryError{}) {
+		t.Errorf("LinkService.UpdateLink() of a prefix alias error = %v, want InvalidQueryError", err)
+	}
+	err = service.UpdateLink(ctx, domain.LinkRequest{Word: "pr", Link: "https://github.com/org/repo/pull", Prefix: true}, "alice")
+	if err != nil {
+		t.Fatalf("LinkService.UpdateLink() error = %v", err)
+	}
+	if !shortcutRepo.shortcuts["pr"].Prefix {
+		t.Error("LinkService.UpdateLink() should store the link as a prefix link")
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+const (
+	// queryLogBatch is the most queries written in one transaction
+	queryLogBatch = 500
+
+	// queryLogWriteTimeout bounds each batch, so a stuck database cannot hold the writer forever
+	queryLogWriteTimeout = 10 * time.Second
+)
+
+// QueryLog writes followed golinks to the query log in the background, so redirects don't
+// wait on the database. Queries queue in a buffer and are written in batches of whatever
+// has queued up since the last write. When the buffer is full, queries are dropped rather
+// than slowing redirects down.
+type QueryLog struct {
+	repo  QueryRepository
+	queue chan *domain.Query
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	written atomic.Int64
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+// NewQueryLog starts a background writer logging queries to repo, queueing up to buffer
+// of them while it writes. Close stops it.
+func NewQueryLog(repo QueryRepository, buffer int) *QueryLog {
+	l := &QueryLog{
+		repo:  repo,
+		queue: make(chan *domain.Query, buffer),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// WithQueryLog logs followed golinks through log instead of writing each one before
+// redirecting. A nil log writes them directly.
+func WithQueryLog(log *QueryLog) Option {
+	return func(s *LinkService) {
+		s.queryLog = log
+	}
+}
+
+// Log queues q to be written, reporting false if q was dropped because the buffer is full
+// or the log is closed
+func (l *QueryLog) Log(q *domain.Query) bool {
+	select {
+	case <-l.stop:
+		l.dropped.Add(1)
+		return false
+	default:
+	}
+
+	select {
+	case l.queue <- q:
+		return true
+	default:
+		l.dropped.Add(1)
+		return false
+	}
+}
+
+// Stats reports how many queries are waiting to be written, and how many were written,
+// dropped because the buffer was full, or lost because the database failed
+func (l *QueryLog) Stats() domain.QueryLogStats {
+	return domain.QueryLogStats{
+		Queued:  len(l.queue),
+		Written: l.written.Load(),
+		Dropped: l.dropped.Load(),
+		Failed:  l.failed.Load(),
+	}
+}
+
+// Close writes the queries still queued and stops the writer, giving up waiting when ctx
+// is done. Queries logged after Close are dropped.
+func (l *QueryLog) Close(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *QueryLog) run() {
+	defer close(l.done)
+
+	batch := make([]*domain.Query, 0, queryLogBatch)
+	for {
+		select {
+		case q := <-l.queue:
+			batch = append(batch[:0], q)
+		case <-l.stop:
+			l.drain(batch[:0])
+			return
+		}
+		l.write(l.fill(batch))
+	}
+}
+
+// fill adds queries already queued to batch, up to queryLogBatch, without waiting for more
+func (l *QueryLog) fill(batch []*domain.Query) []*domain.Query {
+	for len(batch) < queryLogBatch {
+		select {
+		case q := <-l.queue:
+			batch = append(batch, q)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// drain writes every query left in the queue
+func (l *QueryLog) drain(batch []*domain.Query) {
+	for {
+		batch = l.fill(batch[:0])
+		if len(batch) == 0 {
+			return
+		}
+		l.write(batch)
+	}
+}
+
+// write logs batch in one transaction. If that fails, each query is tried on its own, so
+// one query for a link purged meanwhile doesn't lose the rest of the batch.
+func (l *QueryLog) write(batch []*domain.Query) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryLogWriteTimeout)
+	defer cancel()
+
+	if err := l.repo.CreateBatch(ctx, batch); err == nil {
+		l.written.Add(int64(len(batch)))
+		return
+	}
+	if len(batch) == 1 {
+		l.failed.Add(1)
+		return
+	}
+	for i := range batch {
+		if err := l.repo.CreateBatch(ctx, batch[i:i+1]); err != nil {
+			l.failed.Add(1)
+			continue
+		}
+		l.written.Add(1)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// batchQueryRepository records the batches a QueryLog writes. Writes of a batch holding
+// the reject word ID fail, and when release is set each write waits for it to close after
+// announcing itself on started.
+type batchQueryRepository struct {
+	mockQueryRepository
+
+	mu      sync.Mutex
+	batches [][]domain.Query
+	reject  int
+
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *batchQueryRepository) CreateBatch(ctx context.Context, queries []*domain.Query) error {
+	if r.release != nil {
+		r.started <- struct{}{}
+		<-r.release
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	batch := make([]domain.Query, 0, len(queries))
+	for _, query := range queries {
+		if query.WordID == r.reject {
+			return errors.New("FOREIGN KEY constraint failed")
+		}
+		batch = append(batch, *query)
+	}
+	r.batches = append(r.batches, batch)
+	return nil
+}
+
+// logged returns the word IDs written, in order
+func (r *batchQueryRepository) logged() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []int
+	for _, batch := range r.batches {
+		for _, query := range batch {
+			ids = append(ids, query.WordID)
+		}
+	}
+	return ids
+}
+
+func TestQueryLog(t *testing.T) {
+	repo := &batchQueryRepository{
+		reject:  99,
+		started: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+	log := NewQueryLog(repo, 2)
+
+	// The writer takes the first query and holds it while two more fill the queue
+	if !log.Log(&domain.Query{WordID: 1}) {
+		t.Fatal("QueryLog.Log() dropped the first query")
+	}
+	<-repo.started
+	for _, id := range []int{99, 3} {
+		if !log.Log(&domain.Query{WordID: id}) {
+			t.Fatalf("QueryLog.Log(%d) dropped a query with room in the queue", id)
+		}
+	}
+	if log.Log(&domain.Query{WordID: 4}) {
+		t.Error("QueryLog.Log() queued a query beyond the buffer")
+	}
+	if stats := log.Stats(); stats.Queued != 2 || stats.Dropped != 1 {
+		t.Errorf("QueryLog.Stats() = %+v, want 2 queued and 1 dropped", stats)
+	}
+
+	close(repo.release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := log.Close(ctx); err != nil {
+		t.Fatalf("QueryLog.Close() error = %v", err)
+	}
+
+	// The failed batch of 99 and 3 was retried query by query, keeping 3
+	if ids := repo.logged(); len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("logged word IDs %v, want 1 and 3", ids)
+	}
+	if stats := log.Stats(); stats != (domain.QueryLogStats{Written: 2, Dropped: 1, Failed: 1}) {
+		t.Errorf("QueryLog.Stats() = %+v, want 2 written, 1 dropped and 1 failed", stats)
+	}
+
+	if log.Log(&domain.Query{WordID: 5}) {
+		t.Error("QueryLog.Log() queued a query after Close")
+	}
+	if err := log.Close(ctx); err != nil {
+		t.Errorf("second QueryLog.Close() error = %v", err)
+	}
+}
+
+func TestQueryLog_CloseTimeout(t *testing.T) {
+	repo := &batchQueryRepository{started: make(chan struct{}, 1), release: make(chan struct{})}
+	defer close(repo.release)
+	log := NewQueryLog(repo, 1)
+
+	log.Log(&domain.Query{WordID: 1})
+	<-repo.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := log.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("QueryLog.Close() with a stuck write error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestLinkService_QueryLog(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}
+	queryRepo := &batchQueryRepository{}
+	log := NewQueryLog(queryRepo, 10)
+	service := NewLinkService(shortcutRepo, queryRepo, WithQueryLog(log))
+	clicked := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return clicked }
+
+	ctx := WithReferrer(context.Background(), "wiki.example.com")
+	if _, err := service.GetLink(ctx, "docs", "", "bob"); err != nil {
+		t.Fatalf("LinkService.GetLink() error = %v", err)
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("QueryLog.Close() error = %v", err)
+	}
+
+	// The query went through the log, stamped with the time of the click
+	if len(queryRepo.queries) != 0 {
+		t.Errorf("LinkService.GetLink() wrote %d queries directly", len(queryRepo.queries))
+	}
+	want := domain.Query{WordID: 1, User: "bob", Referrer: "wiki.example.com", CreatedAt: clicked}
+	if len(queryRepo.batches) != 1 || len(queryRepo.batches[0]) != 1 || queryRepo.batches[0][0] != want {
+		t.Errorf("logged batches %+v, want %+v", queryRepo.batches, want)
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// WithQueryRetention keeps days days of the query log, counting today, when PruneQueries
+// runs. Older queries are kept only as per-link daily counts. Zero keeps every query.
+func WithQueryRetention(days int) Option {
+	return func(s *LinkService) {
+		s.queryRetentionDays = days
+	}
+}
+
+// PruneQueries rolls the queries logged before the retention period up into daily counts
+// and deletes them. The cutoff falls on a UTC midnight so no day is split between the log
+// and its rollup.
+func (s *LinkService) PruneQueries(ctx context.Context) (*domain.QueryPrune, error) {
+	if s.queryRetentionDays < 1 {
+		return nil, InvalidQueryError{Message: "query retention is not configured"}
+	}
+
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	before := today.AddDate(0, 0, 1-s.queryRetentionDays)
+
+	pruned, err := s.queryRepo.PruneBefore(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune queries: %w", err)
+	}
+
+	return &domain.QueryPrune{Before: before, Pruned: pruned}, nil
+}
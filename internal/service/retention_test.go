@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLinkService_PruneQueries(t *testing.T) {
+	now := time.Date(2024, time.June, 10, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		days       int
+		wantBefore time.Time
+		wantErr    error
+	}{
+		{name: "not configured", days: 0, wantErr: InvalidQueryError{}},
+		{name: "today only", days: 1, wantBefore: time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC)},
+		{name: "a month", days: 30, wantBefore: time.Date(2024, time.May, 12, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryRepo := &mockQueryRepository{pruned: 42}
+			service := NewLinkService(&mockShortcutRepository{}, queryRepo, WithQueryRetention(tt.days))
+			service.now = func() time.Time { return now }
+
+			prune, err := service.PruneQueries(context.Background())
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.PruneQueries() error = %v, want %T", err, tt.wantErr)
+				}
+				if !queryRepo.pruneBefore.IsZero() {
+					t.Errorf("LinkService.PruneQueries() pruned before %v without a retention period", queryRepo.pruneBefore)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.PruneQueries() error = %v", err)
+			}
+			if !prune.Before.Equal(tt.wantBefore) || !queryRepo.pruneBefore.Equal(tt.wantBefore) {
+				t.Errorf("LinkService.PruneQueries() before = %v (asked %v), want %v", prune.Before, queryRepo.pruneBefore, tt.wantBefore)
+			}
+			if prune.Pruned != 42 {
+				t.Errorf("LinkService.PruneQueries() pruned = %d, want 42", prune.Pruned)
+			}
+		})
+	}
+}
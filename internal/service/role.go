@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// RoleRepository interface for user role operations
+type RoleRepository interface {
+	Get(ctx context.Context, user string) (domain.Role, error)
+	Set(ctx context.Context, userRole *domain.UserRole) error
+	Delete(ctx context.Context, user string) (int64, error)
+	List(ctx context.Context) ([]domain.UserRole, error)
+}
+
+// RoleService decides what each user may do. Users listed as admins in the
+// configuration are always admins; everyone else has the role assigned to them, or the
+// default role if none is.
+type RoleService struct {
+	repo        RoleRepository
+	admins      adminList
+	defaultRole domain.Role
+}
+
+// NewRoleService creates a new role service. An invalid default role falls back to editor.
+func NewRoleService(repo RoleRepository, admins []string, defaultRole domain.Role) *RoleService {
+	if !defaultRole.Valid() {
+		defaultRole = domain.RoleEditor
+	}
+
+	return &RoleService{
+		repo:        repo,
+		admins:      adminSet(admins),
+		defaultRole: defaultRole,
+	}
+}
+
+// RoleOf returns the role a user has
+func (s *RoleService) RoleOf(ctx context.Context, user string) (domain.Role, error) {
+	if s.admins[user] {
+		return domain.RoleAdmin, nil
+	}
+
+	role, err := s.repo.Get(ctx, user)
+	if err != nil {
+		return "", err
+	}
+	// Ignore roles this version does not know rather than granting anything
+	if !role.Valid() {
+		return s.defaultRole, nil
+	}
+	return role, nil
+}
+
+// IsAdmin reports whether a user has the admin role
+func (s *RoleService) IsAdmin(ctx context.Context, user string) (bool, error) {
+	role, err := s.RoleOf(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return role == domain.RoleAdmin, nil
+}
+
+// ListRoles returns every assigned role, ordered by user. Configured admins are not listed.
+func (s *RoleService) ListRoles(ctx context.Context, requester string) ([]domain.UserRole, error) {
+	if err := s.requireAdmin(ctx, requester, "list roles"); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if roles == nil {
+		roles = []domain.UserRole{}
+	}
+	return roles, nil
+}
+
+// SetRole assigns a role to a user
+func (s *RoleService) SetRole(
+	ctx context.Context, user string, req domain.RoleRequest, requester string,
+) (*domain.UserRole, error) {
+
+	if err := s.requireAdmin(ctx, requester, "assign roles"); err != nil {
+		return nil, err
+	}
+
+	user, err := s.assignable(user, requester)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Role.Valid() {
+		return nil, InvalidQueryError{
+			Message: fmt.Sprintf("Unknown role %q; use viewer, editor or admin", req.Role),
+		}
+	}
+
+	userRole := &domain.UserRole{User: user, Role: req.Role}
+	if err := s.repo.Set(ctx, userRole); err != nil {
+		return nil, err
+	}
+	return userRole, nil
+}
+
+// ResetRole removes a user's assigned role, returning them to the default role
+func (s *RoleService) ResetRole(ctx context.Context, user string, requester string) error {
+	if err := s.requireAdmin(ctx, requester, "reset roles"); err != nil {
+		return err
+	}
+
+	user, err := s.assignable(user, requester)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, user)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return NotFoundError{Message: fmt.Sprintf("%s has no assigned role", user)}
+	}
+	return nil
+}
+
+// assignable checks that user's role may be changed by requester, returning the trimmed user
+func (s *RoleService) assignable(user, requester string) (string, error) {
+	user = strings.TrimSpace(user)
+
+	switch {
+	case user == "":
+		return "", InvalidQueryError{Message: "A user is required"}
+	case s.admins[user]:
+		return "", InvalidQueryError{Message: fmt.Sprintf("%s is an admin through ADMIN_USERS", user)}
+	case user == requester:
+		// Keeps the last assigned admin from locking everyone out
+		return "", ForbiddenError{Message: "Admins cannot change their own role"}
+	}
+	return user, nil
+}
+
+// requireAdmin returns a ForbiddenError unless requester is an admin
+func (s *RoleService) requireAdmin(ctx context.Context, requester, action string) error {
+	isAdmin, err := s.IsAdmin(ctx, requester)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return ForbiddenError{Message: "Only admins can " + action}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+type mockRoleRepository struct {
+	roles map[string]domain.Role
+}
+
+func newMockRoleRepository(roles map[string]domain.Role) *mockRoleRepository {
+	if roles == nil {
+		roles = map[string]domain.Role{}
+	}
+	return &mockRoleRepository{roles: roles}
+}
+
+func (m *mockRoleRepository) Get(ctx context.Context, user string) (domain.Role, error) {
+	return m.roles[user], nil
+}
+
+func (m *mockRoleRepository) Set(ctx context.Context, userRole *domain.UserRole) error {
+	m.roles[userRole.User] = userRole.Role
+	return nil
+}
+
+func (m *mockRoleRepository) Delete(ctx context.Context, user string) (int64, error) {
+	if _, ok := m.roles[user]; !ok {
+		return 0, nil
+	}
+	delete(m.roles, user)
+	return 1, nil
+}
+
+func (m *mockRoleRepository) List(ctx context.Context) ([]domain.UserRole, error) {
+	var roles []domain.UserRole
+	for user, role := range m.roles {
+		roles = append(roles, domain.UserRole{User: user, Role: role})
+	}
+	return roles, nil
+}
+
+func TestRoleService_RoleOf(t *testing.T) {
+	repo := newMockRoleRepository(map[string]domain.Role{
+		"alice": domain.RoleViewer,
+		"bob":   domain.RoleAdmin,
+		"root":  domain.RoleViewer,
+		"eve":   "superuser",
+	})
+
+	tests := []struct {
+		name        string
+		defaultRole domain.Role
+		user        string
+		want        domain.Role
+	}{
+		{"assigned role", domain.RoleEditor, "alice", domain.RoleViewer},
+		{"assigned admin", domain.RoleEditor, "bob", domain.RoleAdmin},
+		{"configured admin wins", domain.RoleEditor, "root", domain.RoleAdmin},
+		{"default role", domain.RoleViewer, "carol", domain.RoleViewer},
+		{"unknown stored role", domain.RoleViewer, "eve", domain.RoleViewer},
+		{"invalid default", "owner", "carol", domain.RoleEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRoleService(repo, []string{"root"}, tt.defaultRole)
+			got, err := service.RoleOf(context.Background(), tt.user)
+			if err != nil {
+				t.Fatalf("RoleService.RoleOf() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RoleService.RoleOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleService_SetRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      string
+		role      domain.Role
+		requester string
+		wantErr   error
+	}{
+		{name: "configured admin assigns", user: " alice ", role: domain.RoleViewer, requester: "root"},
+		{name: "assigned admin assigns", user: "alice", role: domain.RoleEditor, requester: "bob"},
+		{name: "not an admin", user: "carol", role: domain.RoleAdmin, requester: "alice", wantErr: ForbiddenError{}},
+		{name: "unknown role", user: "alice", role: "owner", requester: "root", wantErr: InvalidQueryError{}},
+		{name: "missing user", user: " ", role: domain.RoleViewer, requester: "root", wantErr: InvalidQueryError{}},
+		{name: "configured admin", user: "root", role: domain.RoleViewer, requester: "bob", wantErr: InvalidQueryError{}},
+		{name: "own role", user: "bob", role: domain.RoleViewer, requester: "bob", wantErr: ForbiddenError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRoleRepository(map[string]domain.Role{"bob": domain.RoleAdmin})
+			service := NewRoleService(repo, []string{"root"}, domain.RoleEditor)
+
+			got, err := service.SetRole(context.Background(), tt.user, domain.RoleRequest{Role: tt.role}, tt.requester)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Fatalf("RoleService.SetRole() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RoleService.SetRole() error = %v", err)
+			}
+			if got.User != "alice" || got.Role != tt.role || repo.roles["alice"] != tt.role {
+				t.Errorf("RoleService.SetRole() = %+v, stored %q", got, repo.roles["alice"])
+			}
+		})
+	}
+}
+
+func TestRoleService_ResetRole(t *testing.T) {
+	repo := newMockRoleRepository(map[string]domain.Role{"alice": domain.RoleViewer})
+	service := NewRoleService(repo, []string{"root"}, domain.RoleEditor)
+	ctx := context.Background()
+
+	if err := service.ResetRole(ctx, "alice", "alice"); !sameErrorType(err, ForbiddenError{}) {
+		t.Errorf("RoleService.ResetRole() by a viewer error = %v, want ForbiddenError", err)
+	}
+	if err := service.ResetRole(ctx, "alice", "root"); err != nil {
+		t.Fatalf("RoleService.ResetRole() error = %v", err)
+	}
+	if role, _ := service.RoleOf(ctx, "alice"); role != domain.RoleEditor {
+		t.Errorf("RoleService.RoleOf() after reset = %q, want the default", role)
+	}
+	if err := service.ResetRole(ctx, "alice", "root"); !sameErrorType(err, NotFoundError{}) {
+		t.Errorf("RoleService.ResetRole() twice error = %v, want NotFoundError", err)
+	}
+}
+
+func TestRoleService_ListRoles(t *testing.T) {
+	service := NewRoleService(newMockRoleRepository(nil), []string{"root"}, domain.RoleEditor)
+
+	roles, err := service.ListRoles(context.Background(), "root")
+	if err != nil {
+		t.Fatalf("RoleService.ListRoles() error = %v", err)
+	}
+	if roles == nil || len(roles) != 0 {
+		t.Errorf("RoleService.ListRoles() = %#v, want an empty list", roles)
+	}
+
+	if _, err := service.ListRoles(context.Background(), "alice"); !sameErrorType(err, ForbiddenError{}) {
+		t.Errorf("RoleService.ListRoles() by an editor error = %v, want ForbiddenError", err)
+	}
+}
+
+func TestLinkService_WithRoles(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+	}}
+	roles := NewRoleService(newMockRoleRepository(map[string]domain.Role{"bob": domain.RoleAdmin}), nil, domain.RoleEditor)
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithRoles(roles))
+
+	if err := service.DeleteLink(context.Background(), "docs", "carol"); !sameErrorType(err, ForbiddenError{}) {
+		t.Errorf("LinkService.DeleteLink() by an editor error = %v, want ForbiddenError", err)
+	}
+	if err := service.DeleteLink(context.Background(), "docs", "bob"); err != nil {
+		t.Errorf("LinkService.DeleteLink() by an assigned admin error = %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// blockedSchemes can never be allowed because browsers execute or read them locally
+var blockedSchemes = map[string]bool{
+	"about":      true,
+	"blob":       true,
+	"data":       true,
+	"file":       true,
+	"javascript": true,
+	"vbscript":   true,
+}
+
+// WithAllowedSchemes permits link targets using non-HTTP schemes such as slack or zoommtg.
+// http and https are always allowed; schemes in blockedSchemes are ignored.
+func WithAllowedSchemes(schemes []string) Option {
+	return func(s *LinkService) {
+		s.allowedSchemes = map[string]bool{}
+		for _, scheme := range schemes {
+			scheme = strings.ToLower(strings.TrimSpace(scheme))
+			if scheme == "" || blockedSchemes[scheme] {
+				continue
+			}
+			s.allowedSchemes[scheme] = true
+		}
+	}
+}
+
+// isTarget reports whether a link is a URL this service may redirect to, as opposed to an alias
+func (s *LinkService) isTarget(link string) bool {
+	if isURL(link) {
+		return true
+	}
+
+	scheme := linkScheme(link)
+	return scheme != "" && s.allowedSchemes[scheme]
+}
+
+// targetPrefixes lists the lowercase link prefixes isTarget accepts, so that repositories
+// can tell targets from aliases in SQL
+func (s *LinkService) targetPrefixes() []string {
+	prefixes := []string{"http://", "https://"}
+	for scheme := range s.allowedSchemes {
+		prefixes = append(prefixes, scheme+":")
+	}
+	sort.Strings(prefixes[2:])
+	return prefixes
+}
+
+// validateScheme rejects links that look like URLs but use a scheme that is not allowed
+func (s *LinkService) validateScheme(link string) error {
+	if s.isTarget(link) {
+		return nil
+	}
+
+	scheme := linkScheme(link)
+	if strings.Contains(link, "://") || blockedSchemes[scheme] {
+		return InvalidQueryError{
+			Message: fmt.Sprintf("Links using the %s scheme are not allowed", scheme),
+		}
+	}
+
+	// Anything else is treated as an alias
+	return nil
+}
+
+// linkScheme returns the lowercased scheme of a link, or "" if it has none
+func linkScheme(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Scheme)
+}
+
+// IsBlockedScheme reports whether links using scheme are never allowed, because browsers
+// execute or read them locally
+func IsBlockedScheme(scheme string) bool {
+	return blockedSchemes[strings.ToLower(scheme)]
+}
+
+// IsWebURL reports whether a target can be served with a plain HTTP redirect
+func IsWebURL(link string) bool {
+	return isURL(link)
+}
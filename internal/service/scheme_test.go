@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_isTarget(t *testing.T) {
+	service := NewLinkService(nil, nil, WithAllowedSchemes([]string{"Slack", " zoommtg ", "javascript"}))
+
+	tests := []struct {
+		name string
+		link string
+		want bool
+	}{
+		{"https", "https://example.com", true},
+		{"allowed scheme", "slack://channel?id=C123", true},
+		{"allowed scheme is case-insensitive", "ZOOMMTG://zoom.us/join?confno=1", true},
+		{"blocked scheme stays blocked", "javascript:alert(1)", false},
+		{"unlisted scheme", "ftp://example.com", false},
+		{"alias", "docs", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.isTarget(tt.link); got != tt.want {
+				t.Errorf("isTarget(%q) = %v, want %v", tt.link, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkService_UpdateLink_Schemes(t *testing.T) {
+	tests := []struct {
+		name    string
+		schemes []string
+		link    string
+		wantErr bool
+	}{
+		{"allowed scheme", []string{"slack"}, "slack://channel?id=C123", false},
+		{"scheme not allowed", nil, "slack://channel?id=C123", true},
+		{"javascript rejected", []string{"javascript"}, "javascript:alert(1)", true},
+		{"data rejected", nil, "data:text/html,hi", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+			service := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAllowedSchemes(tt.schemes))
+
+			err := service.UpdateLink(context.Background(), domain.LinkRequest{Word: "chat", Link: tt.link}, "testuser")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LinkService.UpdateLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := service.GetLink(context.Background(), "chat", "", "testuser")
+			if err != nil || got != tt.link {
+				t.Errorf("LinkService.GetLink() = %v, %v; want %v", got, err, tt.link)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// SnapshotStorage keeps snapshots off the server, such as in an object store
+type SnapshotStorage interface {
+	BackupStorage
+	Delete(ctx context.Context, name string) error
+}
+
+// LinkExporter exports every link, as LinkService does
+type LinkExporter interface {
+	ExportLinks(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error)
+}
+
+// snapshotNamePattern matches the objects of a snapshot, capturing the time it was taken
+var snapshotNamePattern = regexp.MustCompile(`^golinks-(\d{8}-\d{6}\.\d{3})\.(json|db)$`)
+
+// snapshotTimeFormat is the layout of the time in snapshot names, which sorts by time
+const snapshotTimeFormat = "20060102-150405.000"
+
+// SnapshotService saves every link, with its history, to off-box storage on a schedule,
+// keeping a limited number of snapshots
+type SnapshotService struct {
+	links    LinkExporter
+	database Snapshotter
+	storage  SnapshotStorage
+	interval time.Duration
+	keep     int
+	now      func() time.Time
+
+	// lastTaken is when this server last took a snapshot, in case the storage doesn't
+	// list it yet
+	lastTaken time.Time
+}
+
+// NewSnapshotService creates a service taking a snapshot every interval into storage and
+// keeping the newest keep of them, or all of them if keep is 0. Each snapshot includes a
+// copy of the database if database is not nil.
+func NewSnapshotService(
+	links LinkExporter, database Snapshotter, storage SnapshotStorage, interval time.Duration, keep int,
+) *SnapshotService {
+	return &SnapshotService{
+		links:    links,
+		database: database,
+		storage:  storage,
+		interval: interval,
+		keep:     keep,
+		now:      time.Now,
+	}
+}
+
+// Due returns how long until the next snapshot should be taken: an interval after the
+// newest one in storage, or right away if there is none
+func (s *SnapshotService) Due(ctx context.Context) (time.Duration, error) {
+	backups, err := s.storage.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	stamps := snapshotStamps(backups)
+
+	last := s.lastTaken
+	if len(stamps) > 0 {
+		if taken, err := time.Parse(snapshotTimeFormat, stamps[0]); err == nil && taken.After(last) {
+			last = taken
+		}
+	}
+	if last.IsZero() {
+		return 0, nil
+	}
+
+	return max(last.Add(s.interval).Sub(s.now()), 0), nil
+}
+
+// TakeSnapshot saves every link, and the database if configured, then deletes the
+// snapshots beyond the newest keep
+func (s *SnapshotService) TakeSnapshot(ctx context.Context) (*domain.Snapshot, error) {
+	taken := s.now().UTC()
+	stamp := taken.Format(snapshotTimeFormat)
+	snapshot := &domain.Snapshot{}
+
+	export, err := s.links.ExportLinks(ctx, domain.ExportRequest{History: true})
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode links: %w", err)
+	}
+	saved, err := s.storage.Save(ctx, "golinks-"+stamp+".json", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Saved = append(snapshot.Saved, *saved)
+
+	if s.database != nil {
+		saved, err := s.saveDatabase(ctx, "golinks-"+stamp+".db")
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Saved = append(snapshot.Saved, *saved)
+	}
+	s.lastTaken = taken
+
+	if snapshot.Deleted, err = s.prune(ctx); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// saveDatabase snapshots the database and saves it under name
+func (s *SnapshotService) saveDatabase(ctx context.Context, name string) (*domain.Backup, error) {
+	tmp, err := tempBackupPath()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+
+	if err := s.database.Snapshot(ctx, tmp); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer f.Close()
+
+	return s.storage.Save(ctx, name, f)
+}
+
+// prune deletes every snapshot beyond the newest keep, returning the names deleted
+func (s *SnapshotService) prune(ctx context.Context) ([]string, error) {
+	if s.keep <= 0 {
+		return nil, nil
+	}
+
+	backups, err := s.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stamps := snapshotStamps(backups)
+	if len(stamps) <= s.keep {
+		return nil, nil
+	}
+
+	expired := map[string]bool{}
+	for _, stamp := range stamps[s.keep:] {
+		expired[stamp] = true
+	}
+
+	var deleted []string
+	for _, backup := range backups {
+		match := snapshotNamePattern.FindStringSubmatch(backup.Name)
+		if match == nil || !expired[match[1]] {
+			continue
+		}
+		if err := s.storage.Delete(ctx, backup.Name); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, backup.Name)
+	}
+
+	return deleted, nil
+}
+
+// snapshotStamps returns the times of the snapshots among backups, newest first
+func snapshotStamps(backups []domain.Backup) []string {
+	seen := map[string]bool{}
+	var stamps []string
+	for _, backup := range backups {
+		match := snapshotNamePattern.FindStringSubmatch(backup.Name)
+		if match == nil || seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		stamps = append(stamps, match[1])
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(stamps)))
+
+	return stamps
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// exportFunc exports links with a function
+type exportFunc func(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error)
+
+func (f exportFunc) ExportLinks(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error) {
+	return f(ctx, req)
+}
+
+func TestSnapshotService_TakeSnapshot(t *testing.T) {
+	links := exportFunc(func(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error) {
+		if req != (domain.ExportRequest{History: true}) {
+			t.Errorf("SnapshotService.TakeSnapshot() exported %+v, want every link with its history", req)
+		}
+		return &domain.LinkExport{Links: []domain.ExportedLink{{Word: "docs", Link: "https://docs.example.com"}}}, nil
+	})
+
+	tests := []struct {
+		name        string
+		database    Snapshotter
+		keep        int
+		wantSaved   []string
+		wantDeleted []string
+		wantStored  []string
+	}{
+		{
+			name:       "links",
+			wantSaved:  []string{"golinks-20240301-120000.000.json"},
+			wantStored: []string{"golinks-20240101-000000.000.db", "golinks-20240101-000000.000.json", "golinks-20240201-000000.000.json", "golinks-20240301-120000.000.json", "notes.txt"},
+		},
+		{
+			name:        "links and database, keeping two",
+			database:    &mockSnapshotter{data: "database"},
+			keep:        2,
+			wantSaved:   []string{"golinks-20240301-120000.000.json", "golinks-20240301-120000.000.db"},
+			wantDeleted: []string{"golinks-20240101-000000.000.db", "golinks-20240101-000000.000.json"},
+			wantStored:  []string{"golinks-20240201-000000.000.json", "golinks-20240301-120000.000.db", "golinks-20240301-120000.000.json", "notes.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMockBackupStorage()
+			for _, name := range []string{"golinks-20240101-000000.000.json", "golinks-20240101-000000.000.db", "golinks-20240201-000000.000.json", "notes.txt"} {
+				storage.files[name] = []byte(name)
+			}
+			s := NewSnapshotService(links, tt.database, storage, 24*time.Hour, tt.keep)
+			s.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+			snapshot, err := s.TakeSnapshot(context.Background())
+			if err != nil {
+				t.Fatalf("SnapshotService.TakeSnapshot() error = %v", err)
+			}
+
+			var saved []string
+			for _, backup := range snapshot.Saved {
+				saved = append(saved, backup.Name)
+			}
+			deleted := snapshot.Deleted
+			sort.Strings(deleted)
+			if !reflect.DeepEqual(saved, tt.wantSaved) || !reflect.DeepEqual(deleted, tt.wantDeleted) {
+				t.Errorf("SnapshotService.TakeSnapshot() saved %v and deleted %v, want %v and %v", saved, deleted, tt.wantSaved, tt.wantDeleted)
+			}
+
+			var stored []string
+			for name := range storage.files {
+				stored = append(stored, name)
+			}
+			sort.Strings(stored)
+			if !reflect.DeepEqual(stored, tt.wantStored) {
+				t.Errorf("storage holds %v, want %v", stored, tt.wantStored)
+			}
+
+			var export domain.LinkExport
+			if err := json.Unmarshal(storage.files["golinks-20240301-120000.000.json"], &export); err != nil || len(export.Links) != 1 {
+				t.Errorf("saved links = %+v, %v, want the export", export, err)
+			}
+			if tt.database != nil && string(storage.files["golinks-20240301-120000.000.db"]) != "database" {
+				t.Errorf("saved database = %q, want the snapshot", storage.files["golinks-20240301-120000.000.db"])
+			}
+		})
+	}
+}
+
+func TestSnapshotService_Due(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	links := exportFunc(func(ctx context.Context, req domain.ExportRequest) (*domain.LinkExport, error) {
+		return &domain.LinkExport{}, nil
+	})
+
+	tests := []struct {
+		name   string
+		stored []string
+		want   time.Duration
+	}{
+		{name: "no snapshots", stored: []string{"notes.txt"}, want: 0},
+		{name: "recent snapshot", stored: []string{"golinks-20240301-060000.000.json", "golinks-20240201-000000.000.json"}, want: 18 * time.Hour},
+		{name: "overdue", stored: []string{"golinks-20240228-060000.000.json"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMockBackupStorage()
+			for _, name := range tt.stored {
+				storage.files[name] = nil
+			}
+			s := NewSnapshotService(links, nil, storage, 24*time.Hour, 0)
+			s.now = func() time.Time { return now }
+
+			got, err := s.Due(context.Background())
+			if err != nil || got != tt.want {
+				t.Errorf("SnapshotService.Due() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	// A snapshot this server took counts even before the storage lists it
+	s := NewSnapshotService(links, nil, &unlistedStorage{newMockBackupStorage()}, 24*time.Hour, 0)
+	s.now = func() time.Time { return now }
+	if _, err := s.TakeSnapshot(context.Background()); err != nil {
+		t.Fatalf("SnapshotService.TakeSnapshot() error = %v", err)
+	}
+	if got, err := s.Due(context.Background()); err != nil || got != 24*time.Hour {
+		t.Errorf("SnapshotService.Due() after a snapshot = %v, %v, want 24h", got, err)
+	}
+}
+
+// unlistedStorage saves backups without listing them yet
+type unlistedStorage struct {
+	*mockBackupStorage
+}
+
+func (u *unlistedStorage) List(ctx context.Context) ([]domain.Backup, error) {
+	return nil, nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+// Click stats windows accepted by GetLinkStats, in days
+const (
+	DefaultStatsDays       = 30
+	DefaultWeeklyStatsDays = 84
+	MaxStatsDays           = 366
+
+	// topReferrers bounds the referrers reported by GetLinkStats
+	topReferrers = 10
+
+	// userLinksDays and userLinksLimit bound the most used links reported by GetUserLinks
+	userLinksDays  = 90
+	userLinksLimit = 10
+
+	// DefaultStaleDays is how long a link must go unused to be reported by StaleLinks
+	DefaultStaleDays = 90
+	MaxStaleDays     = 3650
+)
+
+type referrerKey struct{}
+
+type clientKey struct{}
+
+// WithReferrer returns a context under which followed golinks are logged as clicked from
+// referrer, the host of the page that linked to them
+func WithReferrer(ctx context.Context, referrer string) context.Context {
+	return context.WithValue(ctx, referrerKey{}, referrer)
+}
+
+// referrerFrom returns the referrer set by WithReferrer, or "" if there is none
+func referrerFrom(ctx context.Context) string {
+	referrer, _ := ctx.Value(referrerKey{}).(string)
+	return referrer
+}
+
+// WithClient returns a context under which followed golinks are logged as coming through
+// client, one of the domain.Client kinds
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientFrom returns the client set by WithClient, or "" if there is none
+func clientFrom(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// GetLinkStats counts how often a golink visible to userID was followed over the last days
+// days, bucketed by day or by week, along with the hosts the clicks came from most. A zero
+// days uses the interval's default window.
+func (s *LinkService) GetLinkStats(
+	ctx context.Context, word, interval string, days int, userID string,
+) (*domain.LinkStats, error) {
+
+	step := 1
+	switch interval {
+	case "", domain.IntervalDay:
+		interval = domain.IntervalDay
+		if days == 0 {
+			days = DefaultStatsDays
+		}
+	case domain.IntervalWeek:
+		step = 7
+		if days == 0 {
+			days = DefaultWeeklyStatsDays
+		}
+	default:
+		return nil, InvalidQueryError{Message: fmt.Sprintf("interval must be %s or %s", domain.IntervalDay, domain.IntervalWeek)}
+	}
+	if days < 1 || days > MaxStatsDays {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("days must be between 1 and %d", MaxStatsDays)}
+	}
+
+	shortcut, err := s.GetShortcut(ctx, word, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, 1-days)
+	if interval == domain.IntervalWeek {
+		since = startOfWeek(since)
+	}
+
+	daily, err := s.queryRepo.GetDailyClicks(ctx, shortcut.Word, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clicks: %w", err)
+	}
+	referrers, err := s.queryRepo.GetTopReferrers(ctx, shortcut.Word, since, topReferrers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referrers: %w", err)
+	}
+	if referrers == nil {
+		referrers = []domain.ReferrerCount{}
+	}
+
+	stats := &domain.LinkStats{
+		Word:      shortcut.Word,
+		Interval:  interval,
+		Since:     since,
+		Clicks:    bucketClicks(daily, since, today, step),
+		Referrers: referrers,
+	}
+	for _, click := range stats.Clicks {
+		stats.Total += click.Count
+	}
+
+	return stats, nil
+}
+
+// GetUserLinks returns the links userID followed most over the last 90 days along with
+// every link they own
+func (s *LinkService) GetUserLinks(ctx context.Context, userID string) (*domain.UserLinks, error) {
+	since := s.now().UTC().AddDate(0, 0, -userLinksDays)
+	mostUsed, err := s.queryRepo.GetUserTopLinks(ctx, userID, since, userLinksLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most used links: %w", err)
+	}
+	created, err := s.shortcutRepo.GetKeywordsByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get created links: %w", err)
+	}
+
+	links := &domain.UserLinks{User: userID, MostUsed: mostUsed, Created: created}
+	if links.MostUsed == nil {
+		links.MostUsed = []domain.PopularQuery{}
+	}
+	if links.Created == nil {
+		links.Created = []domain.KeywordInfo{}
+	}
+
+	return links, nil
+}
+
+// StaleLinks reports the golinks nobody has followed or changed in the last days days,
+// grouped by owner, for cleanup. A zero days uses DefaultStaleDays.
+func (s *LinkService) StaleLinks(ctx context.Context, days int) (*domain.StaleLinkReport, error) {
+	if days == 0 {
+		days = DefaultStaleDays
+	}
+	if days < 1 || days > MaxStaleDays {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("days must be between 1 and %d", MaxStaleDays)}
+	}
+
+	since := s.now().UTC().Truncate(time.Second).AddDate(0, 0, -days)
+	links, err := s.queryRepo.GetStaleLinks(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale links: %w", err)
+	}
+	if links == nil {
+		links = []domain.StaleLink{}
+	}
+
+	return &domain.StaleLinkReport{Days: days, Since: since, Links: links}, nil
+}
+
+// bucketClicks sums daily click counts into buckets of step days from since through until,
+// including the buckets nobody clicked in
+func bucketClicks(daily []domain.ClickCount, since, until time.Time, step int) []domain.ClickCount {
+	var buckets []domain.ClickCount
+	for start := since; !start.After(until); start = start.AddDate(0, 0, step) {
+		buckets = append(buckets, domain.ClickCount{Start: start})
+	}
+
+	for _, click := range daily {
+		i := int(click.Start.Sub(since).Hours()/24) / step
+		if click.Start.Before(since) || i >= len(buckets) {
+			continue
+		}
+		buckets[i].Count += click.Count
+	}
+
+	return buckets
+}
+
+// startOfWeek returns the Monday on or before day
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
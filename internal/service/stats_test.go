@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_GetLinkStats(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs":    {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"payroll": {ID: 2, Word: "payroll", Link: "https://payroll.example.com", User: "alice", Private: true},
+	}}
+	queryRepo := &mockQueryRepository{
+		daily: []domain.ClickCount{
+			{Start: time.Date(2023, time.December, 20, 0, 0, 0, 0, time.UTC), Count: 5},
+			{Start: day(time.January, 1), Count: 2},
+			{Start: day(time.January, 9), Count: 3},
+			{Start: day(time.January, 11), Count: 1},
+		},
+		referrers: []domain.ReferrerCount{{Referrer: "wiki.example.com", Count: 4}},
+	}
+	service := NewLinkService(shortcutRepo, queryRepo)
+	// A Thursday afternoon
+	service.now = func() time.Time { return time.Date(2024, time.January, 11, 15, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		word       string
+		interval   string
+		days       int
+		userID     string
+		wantErr    error
+		wantSince  time.Time
+		wantTotal  int
+		wantClicks []domain.ClickCount
+	}{
+		{
+			name:      "daily",
+			word:      "docs",
+			interval:  "day",
+			days:      3,
+			wantSince: day(time.January, 9),
+			wantTotal: 4,
+			wantClicks: []domain.ClickCount{
+				{Start: day(time.January, 9), Count: 3},
+				{Start: day(time.January, 10)},
+				{Start: day(time.January, 11), Count: 1},
+			},
+		},
+		{
+			name:      "weekly buckets start on Monday",
+			word:      "docs",
+			interval:  "week",
+			days:      14,
+			wantSince: time.Date(2023, time.December, 25, 0, 0, 0, 0, time.UTC),
+			wantTotal: 6,
+			wantClicks: []domain.ClickCount{
+				{Start: time.Date(2023, time.December, 25, 0, 0, 0, 0, time.UTC)},
+				{Start: day(time.January, 1), Count: 2},
+				{Start: day(time.January, 8), Count: 4},
+			},
+		},
+		{
+			name:      "default window",
+			word:      "docs",
+			wantSince: time.Date(2023, time.December, 13, 0, 0, 0, 0, time.UTC),
+			wantTotal: 11,
+		},
+		{
+			name:      "owner sees a private link",
+			word:      "payroll",
+			userID:    "alice",
+			wantSince: time.Date(2023, time.December, 13, 0, 0, 0, 0, time.UTC),
+			wantTotal: 11,
+		},
+		{name: "unknown interval", word: "docs", interval: "month", wantErr: InvalidQueryError{}},
+		{name: "too many days", word: "docs", days: MaxStatsDays + 1, wantErr: InvalidQueryError{}},
+		{name: "negative days", word: "docs", days: -1, wantErr: InvalidQueryError{}},
+		{name: "missing link", word: "nope", wantErr: NotFoundError{}},
+		{name: "someone else's private link", word: "payroll", userID: "bob", wantErr: NotFoundError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := service.GetLinkStats(context.Background(), tt.word, tt.interval, tt.days, tt.userID)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.GetLinkStats() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.GetLinkStats() error = %v", err)
+			}
+
+			if !stats.Since.Equal(tt.wantSince) || stats.Total != tt.wantTotal {
+				t.Errorf("LinkService.GetLinkStats() since %v total %d, want since %v total %d",
+					stats.Since, stats.Total, tt.wantSince, tt.wantTotal)
+			}
+			if tt.wantClicks != nil && !reflect.DeepEqual(stats.Clicks, tt.wantClicks) {
+				t.Errorf("LinkService.GetLinkStats() clicks = %+v, want %+v", stats.Clicks, tt.wantClicks)
+			}
+			if tt.interval == "" && (stats.Interval != domain.IntervalDay || len(stats.Clicks) != DefaultStatsDays) {
+				t.Errorf("LinkService.GetLinkStats() = %s with %d buckets, want %d daily buckets",
+					stats.Interval, len(stats.Clicks), DefaultStatsDays)
+			}
+			if len(stats.Referrers) != 1 || stats.Referrers[0].Referrer != "wiki.example.com" {
+				t.Errorf("LinkService.GetLinkStats() referrers = %+v, want wiki.example.com", stats.Referrers)
+			}
+		})
+	}
+}
+
+func TestLinkService_ResolveDetail_Referrer(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs": {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"d":    {ID: 2, Word: "d", Link: "docs", User: "alice"},
+	}}
+	queryRepo := &mockQueryRepository{}
+	service := NewLinkService(shortcutRepo, queryRepo)
+
+	ctx := WithClient(WithReferrer(context.Background(), "wiki.example.com"), domain.ClientBrowser)
+	if _, err := service.ResolveDetail(ctx, "d", true, "alice"); err != nil {
+		t.Fatalf("LinkService.ResolveDetail() error = %v", err)
+	}
+	if _, err := service.ResolveDetail(context.Background(), "docs", true, "alice"); err != nil {
+		t.Fatalf("LinkService.ResolveDetail() error = %v", err)
+	}
+
+	// Every hop of an alias is logged with the referrer and client
+	want := []domain.Query{
+		{Referrer: "wiki.example.com", Client: domain.ClientBrowser},
+		{Referrer: "wiki.example.com", Client: domain.ClientBrowser},
+		{},
+	}
+	if len(queryRepo.queries) != len(want) {
+		t.Fatalf("logged %d queries, want %d", len(queryRepo.queries), len(want))
+	}
+	for i, query := range queryRepo.queries {
+		if query.Referrer != want[i].Referrer || query.Client != want[i].Client {
+			t.Errorf("query %d from %q via %q, want %q via %q", i, query.Referrer, query.Client, want[i].Referrer, want[i].Client)
+		}
+	}
+}
+
+func TestLinkService_GetUserLinks(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs":    {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "alice"},
+		"payroll": {ID: 2, Word: "payroll", Link: "https://payroll.example.com", User: "alice", Private: true},
+		"github":  {ID: 3, Word: "github", Link: "https://github.com", User: "bob"},
+	}}
+	queryRepo := &mockQueryRepository{}
+	service := NewLinkService(shortcutRepo, queryRepo)
+	ctx := context.Background()
+
+	// Followed links are logged against the user who followed them
+	for _, follow := range []struct{ word, user string }{
+		{"github", "alice"}, {"github", "alice"}, {"docs", "alice"}, {"docs", "bob"},
+	} {
+		if _, err := service.ResolveDetail(ctx, follow.word, true, follow.user); err != nil {
+			t.Fatalf("LinkService.ResolveDetail() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		userID       string
+		wantMostUsed []string
+		wantCreated  int
+	}{
+		{"alice", "alice", []string{"3", "1"}, 2},
+		{"bob", "bob", []string{"1"}, 1},
+		{"nobody", "carol", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := service.GetUserLinks(ctx, tt.userID)
+			if err != nil {
+				t.Fatalf("LinkService.GetUserLinks() error = %v", err)
+			}
+			if links.User != tt.userID || links.MostUsed == nil || links.Created == nil {
+				t.Fatalf("LinkService.GetUserLinks() = %+v, want non-nil lists for %s", links, tt.userID)
+			}
+
+			var mostUsed []string
+			for _, link := range links.MostUsed {
+				mostUsed = append(mostUsed, link.Word)
+			}
+			if !reflect.DeepEqual(mostUsed, tt.wantMostUsed) {
+				t.Errorf("LinkService.GetUserLinks() most used = %v, want %v", mostUsed, tt.wantMostUsed)
+			}
+			if len(links.Created) != tt.wantCreated {
+				t.Errorf("LinkService.GetUserLinks() created %d links, want %d", len(links.Created), tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestLinkService_StaleLinks(t *testing.T) {
+	queryRepo := &mockQueryRepository{stale: []domain.StaleLink{{Word: "old", Link: "https://old.example.com", User: "alice"}}}
+	service := NewLinkService(&mockShortcutRepository{}, queryRepo)
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		days      int
+		wantDays  int
+		wantSince time.Time
+		wantErr   error
+	}{
+		{name: "default", days: 0, wantDays: DefaultStaleDays, wantSince: now.AddDate(0, 0, -DefaultStaleDays)},
+		{name: "a week", days: 7, wantDays: 7, wantSince: time.Date(2024, time.May, 25, 12, 0, 0, 0, time.UTC)},
+		{name: "negative", days: -1, wantErr: InvalidQueryError{}},
+		{name: "too long", days: MaxStaleDays + 1, wantErr: InvalidQueryError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := service.StaleLinks(context.Background(), tt.days)
+			if tt.wantErr != nil {
+				if !sameErrorType(err, tt.wantErr) {
+					t.Errorf("LinkService.StaleLinks() error = %v, want %T", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkService.StaleLinks() error = %v", err)
+			}
+			if report.Days != tt.wantDays || !report.Since.Equal(tt.wantSince) || !queryRepo.staleSince.Equal(tt.wantSince) {
+				t.Errorf("LinkService.StaleLinks() = %d days since %v (asked %v), want %d days since %v",
+					report.Days, report.Since, queryRepo.staleSince, tt.wantDays, tt.wantSince)
+			}
+			if len(report.Links) != 1 || report.Links[0].Word != "old" {
+				t.Errorf("LinkService.StaleLinks() links = %+v, want old", report.Links)
+			}
+		})
+	}
+}
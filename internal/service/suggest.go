@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// Suggestion list sizes used by SuggestLinks
+const (
+	DefaultSuggestLimit = 8
+	MaxSuggestLimit     = 20
+)
+
+// SuggestLinks returns up to limit keywords visible to userID starting with the first word
+// of query, an exact match first and then the most used. Terms after the first word are
+// the search a link is followed with, so they don't narrow the suggestions. A zero limit
+// uses DefaultSuggestLimit and larger limits are capped at MaxSuggestLimit.
+func (s *LinkService) SuggestLinks(ctx context.Context, query, userID string, limit int) (*domain.Suggestions, error) {
+	if limit < 0 {
+		return nil, InvalidQueryError{Message: "limit must not be negative"}
+	}
+	if limit == 0 {
+		limit = DefaultSuggestLimit
+	}
+	if limit > MaxSuggestLimit {
+		limit = MaxSuggestLimit
+	}
+
+	query = strings.TrimSpace(query)
+	suggestions := &domain.Suggestions{Query: query, Suggestions: []domain.Suggestion{}}
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return suggestions, nil
+	}
+	prefix := fields[0]
+	if len(prefix) > maxSearchLength {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("Search terms are limited to %d characters", maxSearchLength)}
+	}
+
+	keywords, err := s.shortcutRepo.GetKeywordsByPrefix(ctx, prefix, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suggestions: %w", err)
+	}
+	for _, keyword := range keywords {
+		suggestions.Suggestions = append(suggestions.Suggestions, domain.Suggestion{
+			Word:        keyword.Word,
+			Link:        keyword.Link,
+			Description: s.describe(keyword),
+		})
+	}
+
+	return suggestions, nil
+}
+
+// describe sums up a keyword for a suggestion: its description, or else its target or
+// the keyword it is an alias of, followed by its tags
+func (s *LinkService) describe(keyword domain.KeywordInfo) string {
+	description := keyword.Link
+	switch {
+	case keyword.Description != "":
+		description = keyword.Description
+	case !s.isTarget(keyword.Link):
+		description = "Alias of " + keyword.Link
+	}
+	if len(keyword.Tags) > 0 {
+		description += " (" + strings.Join(keyword.Tags, ", ") + ")"
+	}
+	return description
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestLinkService_SuggestLinks(t *testing.T) {
+	shortcuts := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"wiki":      {ID: 1, Word: "wiki", Link: "https://wiki.example.com", User: "user1"},
+		"wikipedia": {ID: 2, Word: "wikipedia", Link: "https://en.wikipedia.org", User: "user1"},
+		"wk":        {ID: 3, Word: "wk", Link: "wiki", User: "user1"},
+		"wiki-hr":   {ID: 4, Word: "wiki-hr", Link: "https://hr.example.com", User: "user2", Private: true},
+		"docs":      {ID: 5, Word: "docs", Link: "https://docs.example.com", User: "user1"},
+	}}
+	service := NewLinkService(shortcuts, &mockQueryRepository{})
+
+	tests := []struct {
+		name    string
+		query   string
+		userID  string
+		limit   int
+		want    []domain.Suggestion
+		wantErr bool
+	}{
+		{
+			name:   "exact match first",
+			query:  "wiki",
+			userID: "user1",
+			want: []domain.Suggestion{
+				{Word: "wiki", Link: "https://wiki.example.com", Description: "https://wiki.example.com"},
+				{Word: "wikipedia", Link: "https://en.wikipedia.org", Description: "https://en.wikipedia.org"},
+			},
+		},
+		{
+			name:   "private links for their owner",
+			query:  "wiki-",
+			userID: "user2",
+			want: []domain.Suggestion{
+				{Word: "wiki-hr", Link: "https://hr.example.com", Description: "https://hr.example.com"},
+			},
+		},
+		{
+			name:   "alias",
+			query:  "wk search terms",
+			userID: "user1",
+			want:   []domain.Suggestion{{Word: "wk", Link: "wiki", Description: "Alias of wiki"}},
+		},
+		{
+			name:   "limit",
+			query:  "w",
+			userID: "user1",
+			limit:  1,
+			want:   []domain.Suggestion{{Word: "wiki", Link: "https://wiki.example.com", Description: "https://wiki.example.com"}},
+		},
+		{name: "empty query", query: "  ", userID: "user1", want: []domain.Suggestion{}},
+		{name: "no match", query: "zz", userID: "user1", want: []domain.Suggestion{}},
+		{name: "negative limit", query: "w", userID: "user1", limit: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.SuggestLinks(context.Background(), tt.query, tt.userID, tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LinkService.SuggestLinks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.Suggestions, tt.want) {
+				t.Errorf("LinkService.SuggestLinks() = %+v, want %+v", got.Suggestions, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkService_Describe(t *testing.T) {
+	service := NewLinkService(&mockShortcutRepository{}, &mockQueryRepository{})
+
+	tests := []struct {
+		name    string
+		keyword domain.KeywordInfo
+		want    string
+	}{
+		{
+			name:    "target and tags",
+			keyword: domain.KeywordInfo{Word: "wiki", Link: "https://wiki.example.com", Tags: []string{"docs", "eng"}},
+			want:    "https://wiki.example.com (docs, eng)",
+		},
+		{
+			name:    "alias",
+			keyword: domain.KeywordInfo{Word: "wk", Link: "wiki"},
+			want:    "Alias of wiki",
+		},
+		{
+			name: "description in place of the target",
+			keyword: domain.KeywordInfo{
+				Word: "wiki", Link: "https://wiki.example.com", Description: "Team handbook", Tags: []string{"docs"},
+			},
+			want: "Team handbook (docs)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.describe(tt.keyword); got != tt.want {
+				t.Errorf("LinkService.describe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"golinks/internal/domain"
+)
+
+// Change page sizes used by GetChanges
+const (
+	DefaultChangesLimit = 500
+	MaxChangesLimit     = 5000
+)
+
+// ChangeSource serves the link changes of a primary, as LinkService does
+type ChangeSource interface {
+	GetChanges(ctx context.Context, since, limit int) (*domain.LinkChanges, error)
+}
+
+// ChangeApplier copies a primary's link changes into this instance, as LinkService does
+type ChangeApplier interface {
+	ApplyChanges(ctx context.Context, changes *domain.LinkChanges) (*domain.SyncResult, error)
+}
+
+// SyncStateRepository interface for recording how far a replica has synced
+type SyncStateRepository interface {
+	GetCursor(ctx context.Context, source string) (int, error)
+	SetCursor(ctx context.Context, source string, cursor int) error
+}
+
+// GetChanges returns up to limit link versions stored after the version with ID since,
+// oldest first, private and trashed ones included. The page's Cursor is the ID to ask
+// for changes since next. The last page also lists the live and trashed words, tags and
+// aliases.
+func (s *LinkService) GetChanges(ctx context.Context, since, limit int) (*domain.LinkChanges, error) {
+	if since < 0 {
+		return nil, InvalidQueryError{Message: "since must not be negative"}
+	}
+	if limit == 0 {
+		limit = DefaultChangesLimit
+	}
+	if limit < 0 || limit > MaxChangesLimit {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("limit must be between 1 and %d", MaxChangesLimit)}
+	}
+
+	// Ask for one more than the page holds to tell whether another page follows
+	versions, err := s.shortcutRepo.GetVersionsSince(ctx, since, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes: %w", err)
+	}
+
+	changes := &domain.LinkChanges{Versions: versions, Cursor: since, More: len(versions) > limit}
+	if changes.More {
+		changes.Versions = versions[:limit]
+	}
+	if changes.Versions == nil {
+		changes.Versions = []domain.Shortcut{}
+	}
+	if len(changes.Versions) > 0 {
+		changes.Cursor = changes.Versions[len(changes.Versions)-1].ID
+	}
+	if changes.More {
+		return changes, nil
+	}
+
+	if changes.Words, err = s.shortcutRepo.GetWords(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get words: %w", err)
+	}
+	trash, err := s.shortcutRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+	for _, link := range trash {
+		changes.Trashed = append(changes.Trashed, link.Word)
+	}
+	if s.tagRepo != nil {
+		if changes.Tags, err = s.tagRepo.GetAllTags(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get tags: %w", err)
+		}
+	}
+	if s.aliases != nil {
+		if changes.Aliases, err = s.aliases.GetAllAliases(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get aliases: %w", err)
+		}
+	}
+
+	return changes, nil
+}
+
+// ApplyChanges stores a page of a primary's link versions as they were, keeping when
+// each was created. On the last page it then trashes, restores and purges words until
+// they match the primary's, and sets each link's tags and aliases to the primary's.
+func (s *LinkService) ApplyChanges(ctx context.Context, changes *domain.LinkChanges) (*domain.SyncResult, error) {
+	result := &domain.SyncResult{Versions: len(changes.Versions), Cursor: changes.Cursor}
+	defer func() {
+		if result.Versions+result.Deleted+result.Restored+result.Purged+result.Tags+result.Aliases > 0 {
+			s.keywords.Invalidate()
+		}
+	}()
+
+	if len(changes.Versions) > 0 {
+		shortcuts := make([]*domain.Shortcut, len(changes.Versions))
+		for i, version := range changes.Versions {
+			shortcuts[i] = &domain.Shortcut{
+				Word:        version.Word,
+				Link:        version.Link,
+				User:        version.User,
+				Icon:        version.Icon,
+				Description: version.Description,
+				UpdatedBy:   version.UpdatedBy,
+				Private:     version.Private,
+				Prefix:      version.Prefix,
+				Archived:    version.Archived,
+				Metadata:    version.Metadata,
+				CreatedAt:   version.CreatedAt,
+			}
+		}
+		if err := s.shortcutRepo.CreateBatch(ctx, shortcuts); err != nil {
+			return nil, fmt.Errorf("failed to store changes: %w", err)
+		}
+	}
+	if changes.More {
+		return result, nil
+	}
+
+	live, err := s.shortcutRepo.GetWords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get words: %w", err)
+	}
+	trash, err := s.shortcutRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	for _, word := range live {
+		if slices.Contains(changes.Words, word) {
+			continue
+		}
+		if _, err := s.shortcutRepo.DeleteByWord(ctx, word); err != nil {
+			return nil, fmt.Errorf("failed to delete shortcut: %w", err)
+		}
+		result.Deleted++
+		if !slices.Contains(changes.Trashed, word) {
+			if _, err := s.shortcutRepo.PurgeByWord(ctx, word); err != nil {
+				return nil, fmt.Errorf("failed to purge shortcut: %w", err)
+			}
+			result.Purged++
+		}
+	}
+	for _, link := range trash {
+		switch {
+		case slices.Contains(changes.Words, link.Word):
+			if slices.Contains(live, link.Word) {
+				continue
+			}
+			if _, err := s.shortcutRepo.RestoreByWord(ctx, link.Word); err != nil {
+				return nil, fmt.Errorf("failed to restore shortcut: %w", err)
+			}
+			result.Restored++
+		case !slices.Contains(changes.Trashed, link.Word) && !slices.Contains(live, link.Word):
+			if _, err := s.shortcutRepo.PurgeByWord(ctx, link.Word); err != nil {
+				return nil, fmt.Errorf("failed to purge shortcut: %w", err)
+			}
+			result.Purged++
+		}
+	}
+
+	if s.tagRepo != nil {
+		if result.Tags, err = s.syncTags(ctx, changes); err != nil {
+			return nil, err
+		}
+	}
+	if s.aliases != nil {
+		if result.Aliases, err = s.syncAliases(ctx, changes); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// syncAliases points every alias at the same word as on the primary, removing those the
+// primary doesn't have, and returns how many it added or removed. Words that were renamed
+// on the primary arrive as a new version of the new word, so this keeps the old word
+// resolving through its alias.
+func (s *LinkService) syncAliases(ctx context.Context, changes *domain.LinkChanges) (int, error) {
+	current, err := s.aliases.GetAllAliases(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get aliases: %w", err)
+	}
+
+	changed := 0
+	for alias, word := range current {
+		if changes.Aliases[alias] == word {
+			continue
+		}
+		if _, err := s.aliases.Remove(ctx, alias, word); err != nil {
+			return changed, fmt.Errorf("failed to remove alias: %w", err)
+		}
+		changed++
+	}
+	for alias, word := range changes.Aliases {
+		if current[alias] == word {
+			continue
+		}
+		if err := s.aliases.Add(ctx, alias, word, ""); err != nil {
+			return changed, fmt.Errorf("failed to add alias: %w", err)
+		}
+		changed++
+	}
+
+	return changed, nil
+}
+
+// syncTags sets the tags of every live link to the primary's, returning how many tags it
+// added or removed
+func (s *LinkService) syncTags(ctx context.Context, changes *domain.LinkChanges) (int, error) {
+	current, err := s.tagRepo.GetAllTags(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	changed := 0
+	for _, word := range changes.Words {
+		want, have := changes.Tags[word], current[word]
+		for _, tag := range have {
+			if slices.Contains(want, tag) {
+				continue
+			}
+			if _, err := s.tagRepo.RemoveTag(ctx, word, tag); err != nil {
+				return changed, fmt.Errorf("failed to remove tag: %w", err)
+			}
+			changed++
+		}
+
+		var shortcut *domain.Shortcut
+		for _, tag := range want {
+			if slices.Contains(have, tag) {
+				continue
+			}
+			if shortcut == nil {
+				if shortcut, err = s.shortcutRepo.GetByWord(ctx, word); err != nil {
+					return changed, fmt.Errorf("failed to get shortcut: %w", err)
+				}
+				if shortcut == nil {
+					break
+				}
+			}
+			if err := s.tagRepo.AddTag(ctx, shortcut.ID, tag); err != nil {
+				return changed, fmt.Errorf("failed to add tag: %w", err)
+			}
+			changed++
+		}
+	}
+
+	return changed, nil
+}
+
+// SyncService keeps this instance a replica of a primary, pulling the changes made there
+type SyncService struct {
+	source ChangeSource
+	links  ChangeApplier
+	state  SyncStateRepository
+
+	// primary names the primary the cursor is recorded for, such as its URL
+	primary string
+
+	pageSize int
+}
+
+// NewSyncService creates a service pulling changes from source, the primary named
+// primary, into links
+func NewSyncService(source ChangeSource, primary string, links ChangeApplier, state SyncStateRepository) *SyncService {
+	return &SyncService{
+		source:   source,
+		links:    links,
+		state:    state,
+		primary:  primary,
+		pageSize: DefaultChangesLimit,
+	}
+}
+
+// Pull copies every change made on the primary since the last pull, a page at a time,
+// recording the cursor after each page so an interrupted pull resumes where it stopped
+func (s *SyncService) Pull(ctx context.Context) (*domain.SyncResult, error) {
+	cursor, err := s.state.GetCursor(ctx, s.primary)
+	if err != nil {
+		return nil, err
+	}
+
+	total := &domain.SyncResult{Cursor: cursor}
+	for {
+		changes, err := s.source.GetChanges(ctx, cursor, s.pageSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to get changes from %s: %w", s.primary, err)
+		}
+		if changes.More && changes.Cursor <= cursor {
+			return total, fmt.Errorf("%s sent more changes without moving past %d", s.primary, cursor)
+		}
+
+		result, err := s.links.ApplyChanges(ctx, changes)
+		if err != nil {
+			return total, err
+		}
+		total.Versions += result.Versions
+		total.Deleted += result.Deleted
+		total.Restored += result.Restored
+		total.Purged += result.Purged
+		total.Tags += result.Tags
+		total.Aliases += result.Aliases
+
+		if changes.Cursor != cursor {
+			if err := s.state.SetCursor(ctx, s.primary, changes.Cursor); err != nil {
+				return total, err
+			}
+			cursor = changes.Cursor
+			total.Cursor = cursor
+		}
+		if !changes.More {
+			return total, nil
+		}
+	}
+}
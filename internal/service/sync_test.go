@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+type mockSyncStateRepository struct {
+	cursors map[string]int
+}
+
+func (m *mockSyncStateRepository) GetCursor(ctx context.Context, source string) (int, error) {
+	return m.cursors[source], nil
+}
+
+func (m *mockSyncStateRepository) SetCursor(ctx context.Context, source string, cursor int) error {
+	m.cursors[source] = cursor
+	return nil
+}
+
+// setupSyncLinkService creates a link service over words, each live, with tags
+func setupSyncLinkService(t *testing.T, words ...string) (*LinkService, *mockShortcutRepository, *mockTagRepository) {
+	t.Helper()
+	shortcuts := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+	tags := &mockTagRepository{shortcuts: shortcuts, tags: map[string]map[string]bool{}}
+	for _, word := range words {
+		shortcut := &domain.Shortcut{Word: word, Link: "https://" + word + ".example.com", User: "user1"}
+		if err := shortcuts.Create(context.Background(), shortcut); err != nil {
+			t.Fatalf("Failed to create test shortcut: %v", err)
+		}
+	}
+	return NewLinkService(shortcuts, &mockQueryRepository{}, WithTags(tags)), shortcuts, tags
+}
+
+func TestLinkService_GetChanges(t *testing.T) {
+	ctx := context.Background()
+	service, shortcuts, tags := setupSyncLinkService(t, "docs", "wiki", "old")
+	if _, err := shortcuts.DeleteByWord(ctx, "old"); err != nil {
+		t.Fatalf("Failed to delete test shortcut: %v", err)
+	}
+	if err := tags.AddTag(ctx, shortcuts.shortcuts["docs"].ID, "eng"); err != nil {
+		t.Fatalf("Failed to tag test shortcut: %v", err)
+	}
+
+	first, err := service.GetChanges(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("LinkService.GetChanges() error = %v", err)
+	}
+	if len(first.Versions) != 2 || !first.More || first.Cursor != first.Versions[1].ID || first.Words != nil {
+		t.Errorf("LinkService.GetChanges() first page = %+v, want 2 versions, more and no word lists", first)
+	}
+
+	last, err := service.GetChanges(ctx, first.Cursor, 2)
+	if err != nil {
+		t.Fatalf("LinkService.GetChanges() error = %v", err)
+	}
+	if len(last.Versions) != 1 || last.More || last.Versions[0].Word != "old" {
+		t.Errorf("LinkService.GetChanges() last page = %+v, want only the trashed version", last)
+	}
+	if want := []string{"docs", "wiki"}; !reflect.DeepEqual(last.Words, want) {
+		t.Errorf("LinkService.GetChanges() words = %v, want %v", last.Words, want)
+	}
+	if want := []string{"old"}; !reflect.DeepEqual(last.Trashed, want) {
+		t.Errorf("LinkService.GetChanges() trashed = %v, want %v", last.Trashed, want)
+	}
+	if want := []string{"eng"}; !reflect.DeepEqual(last.Tags["docs"], want) {
+		t.Errorf("LinkService.GetChanges() docs tags = %v, want %v", last.Tags["docs"], want)
+	}
+
+	caughtUp, err := service.GetChanges(ctx, last.Cursor, 0)
+	if err != nil {
+		t.Fatalf("LinkService.GetChanges() error = %v", err)
+	}
+	if len(caughtUp.Versions) != 0 || caughtUp.Cursor != last.Cursor || caughtUp.Versions == nil {
+		t.Errorf("LinkService.GetChanges() when caught up = %+v, want no versions at cursor %d", caughtUp, last.Cursor)
+	}
+
+	for _, tt := range []struct{ since, limit int }{{-1, 10}, {0, -1}, {0, MaxChangesLimit + 1}} {
+		if _, err := service.GetChanges(ctx, tt.since, tt.limit); !isInvalidQueryError(err) {
+			t.Errorf("LinkService.GetChanges(%d, %d) error = %v, want InvalidQueryError", tt.since, tt.limit, err)
+		}
+	}
+}
+
+func isInvalidQueryError(err error) bool {
+	_, ok := err.(InvalidQueryError)
+	return ok
+}
+
+func TestLinkService_ApplyChanges(t *testing.T) {
+	ctx := context.Background()
+	service, shortcuts, tags := setupSyncLinkService(t, "docs", "old", "gone", "back", "dead")
+	for _, word := range []string{"back", "dead"} {
+		if _, err := shortcuts.DeleteByWord(ctx, word); err != nil {
+			t.Fatalf("Failed to delete test shortcut: %v", err)
+		}
+	}
+	if err := tags.AddTag(ctx, shortcuts.shortcuts["docs"].ID, "stale"); err != nil {
+		t.Fatalf("Failed to tag test shortcut: %v", err)
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	result, err := service.ApplyChanges(ctx, &domain.LinkChanges{
+		Versions: []domain.Shortcut{
+			{ID: 40, Word: "wiki", Link: "https://wiki.example.com", User: "user2", CreatedAt: createdAt},
+		},
+		Cursor:  40,
+		Words:   []string{"back", "docs", "wiki"},
+		Trashed: []string{"old"},
+		Tags:    map[string][]string{"docs": {"eng"}, "wiki": {"team"}},
+	})
+	if err != nil {
+		t.Fatalf("LinkService.ApplyChanges() error = %v", err)
+	}
+
+	want := &domain.SyncResult{Versions: 1, Deleted: 2, Restored: 1, Purged: 2, Tags: 3, Cursor: 40}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("LinkService.ApplyChanges() = %+v, want %+v", result, want)
+	}
+
+	var live, trashed []string
+	for word := range shortcuts.shortcuts {
+		live = append(live, word)
+	}
+	for word := range shortcuts.trash {
+		trashed = append(trashed, word)
+	}
+	sort.Strings(live)
+	if !reflect.DeepEqual(live, []string{"back", "docs", "wiki"}) || !reflect.DeepEqual(trashed, []string{"old"}) {
+		t.Errorf("after ApplyChanges live = %v, trashed = %v, want [back docs wiki] and [old]", live, trashed)
+	}
+	if wiki := shortcuts.shortcuts["wiki"]; wiki.User != "user2" || !wiki.CreatedAt.Equal(createdAt) {
+		t.Errorf("wiki = %+v, want it owned by user2 and created at %v", wiki, createdAt)
+	}
+
+	allTags, _ := tags.GetAllTags(ctx)
+	if !reflect.DeepEqual(allTags["docs"], []string{"eng"}) || !reflect.DeepEqual(allTags["wiki"], []string{"team"}) {
+		t.Errorf("after ApplyChanges tags = %v, want docs tagged eng and wiki team", allTags)
+	}
+}
+
+func TestLinkService_ApplyChanges_MorePages(t *testing.T) {
+	ctx := context.Background()
+	service, shortcuts, _ := setupSyncLinkService(t, "docs")
+
+	result, err := service.ApplyChanges(ctx, &domain.LinkChanges{
+		Versions: []domain.Shortcut{{ID: 7, Word: "wiki", Link: "https://wiki.example.com", User: "user1"}},
+		Cursor:   7,
+		More:     true,
+	})
+	if err != nil {
+		t.Fatalf("LinkService.ApplyChanges() error = %v", err)
+	}
+	if result.Versions != 1 || result.Deleted != 0 {
+		t.Errorf("LinkService.ApplyChanges() = %+v, want one version and nothing deleted", result)
+	}
+	if _, live := shortcuts.shortcuts["docs"]; !live {
+		t.Error("ApplyChanges deleted docs before the last page")
+	}
+}
+
+func TestSyncService_Pull(t *testing.T) {
+	ctx := context.Background()
+	primary, primaryShortcuts, _ := setupSyncLinkService(t, "docs", "wiki", "jira")
+	replica, replicaShortcuts, _ := setupSyncLinkService(t)
+	state := &mockSyncStateRepository{cursors: map[string]int{}}
+
+	sync := NewSyncService(primary, "https://primary.example.com", replica, state)
+	sync.pageSize = 2
+
+	latest := primaryShortcuts.history[len(primaryShortcuts.history)-1].ID
+
+	result, err := sync.Pull(ctx)
+	if err != nil {
+		t.Fatalf("SyncService.Pull() error = %v", err)
+	}
+	if result.Versions != 3 || result.Cursor != latest || state.cursors["https://primary.example.com"] != latest {
+		t.Errorf("SyncService.Pull() = %+v, cursor saved %d, want 3 versions up to cursor %d",
+			result, state.cursors["https://primary.example.com"], latest)
+	}
+	if len(replicaShortcuts.shortcuts) != 3 {
+		t.Errorf("replica has %d links after pulling, want 3", len(replicaShortcuts.shortcuts))
+	}
+
+	if _, err := primaryShortcuts.DeleteByWord(ctx, "jira"); err != nil {
+		t.Fatalf("Failed to delete test shortcut: %v", err)
+	}
+	result, err = sync.Pull(ctx)
+	if err != nil {
+		t.Fatalf("SyncService.Pull() error = %v", err)
+	}
+	if result.Versions != 0 || result.Deleted != 1 || result.Cursor != latest {
+		t.Errorf("SyncService.Pull() = %+v, want only jira deleted", result)
+	}
+	if _, trashed := replicaShortcuts.trash["jira"]; !trashed {
+		t.Error("replica kept jira live after the primary deleted it")
+	}
+}
+
+func TestSyncService_Pull_Rename(t *testing.T) {
+	ctx := context.Background()
+	newService := func(words ...string) (*LinkService, *mockShortcutRepository) {
+		service, shortcuts, _ := setupSyncLinkService(t, words...)
+		WithAliases(&mockAliasRepository{repo: shortcuts, aliases: map[string]string{}})(service)
+		return service, shortcuts
+	}
+	primary, _ := newService("docs", "wiki")
+	replica, replicaShortcuts := newService()
+	sync := NewSyncService(primary, "https://primary.example.com", replica, &mockSyncStateRepository{cursors: map[string]int{}})
+
+	if _, err := sync.Pull(ctx); err != nil {
+		t.Fatalf("SyncService.Pull() error = %v", err)
+	}
+	if _, err := primary.RenameLink(ctx, "docs", domain.RenameRequest{Word: "guide"}, "user1"); err != nil {
+		t.Fatalf("LinkService.RenameLink() error = %v", err)
+	}
+	if err := primary.AddAlias(ctx, "wiki", "kb", "user1"); err != nil {
+		t.Fatalf("LinkService.AddAlias() error = %v", err)
+	}
+
+	result, err := sync.Pull(ctx)
+	if err != nil {
+		t.Fatalf("SyncService.Pull() error = %v", err)
+	}
+	if result.Versions != 1 || result.Aliases != 2 {
+		t.Errorf("SyncService.Pull() = %+v, want the rename's version and two aliases", result)
+	}
+	if _, live := replicaShortcuts.shortcuts["docs"]; live {
+		t.Error("replica kept docs as a golink after the primary renamed it")
+	}
+
+	for word, want := range map[string]string{"guide": "guide", "docs": "guide", "kb": "wiki"} {
+		res, err := replica.ResolveDetail(ctx, word, false, "user1")
+		if err != nil || res.Word != want {
+			t.Errorf("replica LinkService.ResolveDetail(%q) = %+v, %v, want %s", word, res, err, want)
+		}
+	}
+
+	// Aliases removed on the primary go from the replica too
+	if err := primary.RemoveAlias(ctx, "wiki", "kb", "user1"); err != nil {
+		t.Fatalf("LinkService.RemoveAlias() error = %v", err)
+	}
+	if result, err := sync.Pull(ctx); err != nil || result.Aliases != 1 {
+		t.Errorf("SyncService.Pull() = %+v, %v, want one alias removed", result, err)
+	}
+	if _, err := replica.ResolveDetail(ctx, "kb", false, "user1"); err == nil {
+		t.Error("replica still resolves kb after the primary removed it")
+	}
+}
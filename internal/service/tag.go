@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golinks/internal/domain"
+)
+
+// maxTagsPerRequest bounds how many tags can be added in one call
+const maxTagsPerRequest = 20
+
+// tagPattern restricts tags to short lowercase slugs
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// TagRepository interface for tag operations
+type TagRepository interface {
+	AddTag(ctx context.Context, wordID int, tag string) error
+	RemoveTag(ctx context.Context, word, tag string) (int64, error)
+	GetTagsByWord(ctx context.Context, word string) ([]string, error)
+	GetAllTags(ctx context.Context) (map[string][]string, error)
+	GetKeywordsByTag(ctx context.Context, tag, viewer string) ([]domain.KeywordInfo, error)
+}
+
+// TagService handles business logic for tagging golinks
+type TagService struct {
+	tagRepo TagRepository
+
+	// links decides who may see and change the golinks tagged
+	links *LinkService
+
+	// keywords caches the keyword lists, which show each link's tags
+	keywords *KeywordCache
+}
+
+// TagOption configures a TagService
+type TagOption func(*TagService)
+
+// WithTagKeywordCache invalidates the cached keyword lists whenever a tag changes
+func WithTagKeywordCache(cache *KeywordCache) TagOption {
+	return func(s *TagService) {
+		s.keywords = cache
+	}
+}
+
+// NewTagService creates a new tag service over the golinks of links
+func NewTagService(tagRepo TagRepository, links *LinkService, opts ...TagOption) *TagService {
+	s := &TagService{
+		tagRepo: tagRepo,
+		links:   links,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddTags tags a golink userID may change and returns its full set of tags
+func (s *TagService) AddTags(ctx context.Context, word string, tags []string, userID string) ([]string, error) {
+	word = NormalizeWord(word)
+
+	if len(tags) == 0 {
+		return nil, InvalidQueryError{Message: "No tags given"}
+	}
+	if len(tags) > maxTagsPerRequest {
+		return nil, InvalidQueryError{Message: fmt.Sprintf("At most %d tags can be added at once", maxTagsPerRequest)}
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+
+	shortcut, err := s.links.modifiableShortcut(ctx, word, userID, "tag")
+	if err != nil {
+		return nil, err
+	}
+
+	defer s.keywords.Invalidate()
+	for _, tag := range normalized {
+		if err := s.tagRepo.AddTag(ctx, shortcut.ID, tag); err != nil {
+			return nil, fmt.Errorf("failed to add tag: %w", err)
+		}
+	}
+
+	return s.GetTags(ctx, word, userID)
+}
+
+// RemoveTag removes a tag from a golink userID may change
+func (s *TagService) RemoveTag(ctx context.Context, word, tag, userID string) error {
+	word = NormalizeWord(word)
+
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	if _, err := s.links.modifiableShortcut(ctx, word, userID, "untag"); err != nil {
+		return err
+	}
+
+	removed, err := s.tagRepo.RemoveTag(ctx, word, tag)
+	if err != nil {
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+	if removed == 0 {
+		return NotFoundError{Message: fmt.Sprintf("%s is not tagged %s", word, tag)}
+	}
+	s.keywords.Invalidate()
+
+	return nil
+}
+
+// GetTags lists the tags of a golink userID can see
+func (s *TagService) GetTags(ctx context.Context, word, userID string) ([]string, error) {
+	shortcut, err := s.links.GetShortcut(ctx, word, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.GetTagsByWord(ctx, shortcut.Word)
+}
+
+// GetKeywordsByTag lists the golinks carrying a tag that are visible to userID
+func (s *TagService) GetKeywordsByTag(ctx context.Context, tag, userID string) ([]domain.KeywordInfo, error) {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.GetKeywordsByTag(ctx, tag, userID)
+}
+
+// normalizeTag lowercases a tag and checks it is a valid slug
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(tag) {
+		return "", InvalidQueryError{
+			Message: fmt.Sprintf("Invalid tag %q: use up to 32 lowercase letters, digits, '-' or '_'", tag),
+		}
+	}
+	return tag, nil
+}
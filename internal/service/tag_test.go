@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+type mockTagRepository struct {
+	shortcuts *mockShortcutRepository
+	tags      map[string]map[string]bool
+}
+
+func (m *mockTagRepository) wordByID(id int) string {
+	for word, shortcut := range m.shortcuts.shortcuts {
+		if shortcut.ID == id {
+			return word
+		}
+	}
+	return ""
+}
+
+func (m *mockTagRepository) AddTag(ctx context.Context, wordID int, tag string) error {
+	word := m.wordByID(wordID)
+	if m.tags[word] == nil {
+		m.tags[word] = map[string]bool{}
+	}
+	m.tags[word][tag] = true
+	return nil
+}
+
+func (m *mockTagRepository) RemoveTag(ctx context.Context, word, tag string) (int64, error) {
+	if !m.tags[word][tag] {
+		return 0, nil
+	}
+	delete(m.tags[word], tag)
+	return 1, nil
+}
+
+func (m *mockTagRepository) GetTagsByWord(ctx context.Context, word string) ([]string, error) {
+	tags := []string{}
+	for tag := range m.tags[word] {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (m *mockTagRepository) GetAllTags(ctx context.Context) (map[string][]string, error) {
+	all := map[string][]string{}
+	for word := range m.tags {
+		all[word], _ = m.GetTagsByWord(ctx, word)
+	}
+	return all, nil
+}
+
+func (m *mockTagRepository) GetKeywordsByTag(ctx context.Context, tag, viewer string) ([]domain.KeywordInfo, error) {
+	var keywords []domain.KeywordInfo
+	for word, tags := range m.tags {
+		if tags[tag] && visibleTo(m.shortcuts.shortcuts[word], viewer) {
+			keywords = append(keywords, domain.KeywordInfo{Word: word, Link: m.shortcuts.shortcuts[word].Link})
+		}
+	}
+	return keywords, nil
+}
+
+func setupTagService() (*TagService, *mockTagRepository) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"docs":   {ID: 1, Word: "docs", Link: "https://docs.example.com", User: "testuser"},
+		"github": {ID: 2, Word: "github", Link: "https://github.com", User: "testuser"},
+		"secret": {ID: 3, Word: "secret", Link: "https://secret.example.com", User: "alice", Private: true},
+	}}
+	tagRepo := &mockTagRepository{shortcuts: shortcutRepo, tags: map[string]map[string]bool{}}
+	links := NewLinkService(shortcutRepo, &mockQueryRepository{}, WithAdmins([]string{"admin"}))
+	return NewTagService(tagRepo, links), tagRepo
+}
+
+func TestTagService_AddTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		word    string
+		tags    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "normalizes tags",
+			word: "docs",
+			tags: []string{" Engineering ", "how-to"},
+			want: []string{"engineering", "how-to"},
+		},
+		{
+			name:    "invalid tag",
+			word:    "docs",
+			tags:    []string{"no spaces"},
+			wantErr: true,
+		},
+		{
+			name:    "no tags",
+			word:    "docs",
+			tags:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown word",
+			word:    "missing",
+			tags:    []string{"engineering"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupTagService()
+
+			got, err := service.AddTags(context.Background(), tt.word, tt.tags, "testuser")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TagService.AddTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TagService.AddTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagService_RemoveTagAndList(t *testing.T) {
+	service, _ := setupTagService()
+	ctx := context.Background()
+
+	if _, err := service.AddTags(ctx, "docs", []string{"engineering"}, "testuser"); err != nil {
+		t.Fatalf("TagService.AddTags() error = %v", err)
+	}
+	if _, err := service.AddTags(ctx, "github", []string{"engineering", "code"}, "testuser"); err != nil {
+		t.Fatalf("TagService.AddTags() error = %v", err)
+	}
+
+	keywords, err := service.GetKeywordsByTag(ctx, "Engineering", "testuser")
+	if err != nil {
+		t.Fatalf("TagService.GetKeywordsByTag() error = %v", err)
+	}
+	if len(keywords) != 2 {
+		t.Errorf("TagService.GetKeywordsByTag() returned %d keywords, want 2", len(keywords))
+	}
+
+	if err := service.RemoveTag(ctx, "docs", "engineering", "testuser"); err != nil {
+		t.Fatalf("TagService.RemoveTag() error = %v", err)
+	}
+	if err := service.RemoveTag(ctx, "docs", "engineering", "testuser"); err == nil {
+		t.Error("TagService.RemoveTag() expected NotFoundError for missing tag")
+	} else if _, ok := err.(NotFoundError); !ok {
+		t.Errorf("TagService.RemoveTag() error = %v, want NotFoundError", err)
+	}
+
+	tags, err := service.GetTags(ctx, "github", "testuser")
+	if err != nil {
+		t.Fatalf("TagService.GetTags() error = %v", err)
+	}
+	if want := []string{"code", "engineering"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("TagService.GetTags() = %v, want %v", tags, want)
+	}
+}
+
+func TestTagService_Permissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		call    func(s *TagService) error
+		wantErr error
+	}{
+		{
+			name: "admin tags someone else's link",
+			call: func(s *TagService) error {
+				_, err := s.AddTags(context.Background(), "docs", []string{"how-to"}, "admin")
+				return err
+			},
+		},
+		{
+			name: "other user cannot tag",
+			call: func(s *TagService) error {
+				_, err := s.AddTags(context.Background(), "docs", []string{"how-to"}, "bob")
+				return err
+			},
+			wantErr: ForbiddenError{},
+		},
+		{
+			name: "other user cannot untag",
+			call: func(s *TagService) error {
+				return s.RemoveTag(context.Background(), "docs", "engineering", "bob")
+			},
+			wantErr: ForbiddenError{},
+		},
+		{
+			name: "other user can list tags of a public link",
+			call: func(s *TagService) error {
+				_, err := s.GetTags(context.Background(), "docs", "bob")
+				return err
+			},
+		},
+		{
+			name: "private link hidden from listing",
+			call: func(s *TagService) error {
+				_, err := s.GetTags(context.Background(), "secret", "bob")
+				return err
+			},
+			wantErr: NotFoundError{},
+		},
+		{
+			name: "private link hidden from tagging",
+			call: func(s *TagService) error {
+				_, err := s.AddTags(context.Background(), "secret", []string{"how-to"}, "admin")
+				return err
+			},
+			wantErr: NotFoundError{},
+		},
+		{
+			name: "owner tags private link",
+			call: func(s *TagService) error {
+				_, err := s.AddTags(context.Background(), "secret", []string{"how-to"}, "alice")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, tagRepo := setupTagService()
+			tagRepo.tags["docs"] = map[string]bool{"engineering": true}
+
+			err := tt.call(service)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !sameErrorType(err, tt.wantErr) {
+				t.Errorf("error = %v, want %T", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// expandVariables fills in the named placeholders a link may use, as of now for userID:
+// {user} with the user following the link, {date} with today's date as 2006-01-02 and
+// {week} with the ISO week, as 2006-W01
+func expandVariables(link, userID string, now time.Time) string {
+	if !strings.Contains(link, "{") {
+		return link
+	}
+
+	year, week := now.ISOWeek()
+	return strings.NewReplacer(
+		"{user}", url.QueryEscape(userID),
+		"{date}", now.Format(time.DateOnly),
+		"{week}", fmt.Sprintf("%d-W%02d", year, week),
+	).Replace(link)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golinks/internal/domain"
+)
+
+func TestExpandVariables(t *testing.T) {
+	now := time.Date(2024, time.December, 30, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		link   string
+		userID string
+		want   string
+	}{
+		{name: "no placeholders", link: "https://example.com", userID: "alice", want: "https://example.com"},
+		{name: "user", link: "https://hr.example.com/people/{user}", userID: "alice", want: "https://hr.example.com/people/alice"},
+		{name: "user escaped", link: "https://hr.example.com/?who={user}", userID: "a&b c", want: "https://hr.example.com/?who=a%26b+c"},
+		{name: "date", link: "https://cal.example.com/{date}", want: "https://cal.example.com/2024-12-30"},
+		{name: "ISO week of the next year", link: "https://time.example.com/{user}/{week}", userID: "bob", want: "https://time.example.com/bob/2025-W01"},
+		{name: "search placeholders untouched", link: "https://example.com/{user}?q={*}&n={1}", userID: "bob", want: "https://example.com/bob?q={*}&n={1}"},
+		{name: "unknown names untouched", link: "https://example.com/{team}", userID: "bob", want: "https://example.com/{team}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandVariables(tt.link, tt.userID, now); got != tt.want {
+				t.Errorf("expandVariables(%q) = %q, want %q", tt.link, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkService_GetLink_Variables(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+		"timesheet": {ID: 1, Word: "timesheet", Link: "https://time.example.com/{user}/{week}", User: "alice"},
+		"standup":   {ID: 2, Word: "standup", Link: "https://notes.example.com/{date}?topic={*}", User: "alice"},
+		"mine":      {ID: 3, Word: "mine", Link: "timesheet", User: "alice"},
+	}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+	service.now = func() time.Time { return time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	tests := []struct {
+		word       string
+		searchTerm string
+		userID     string
+		want       string
+	}{
+		{word: "timesheet", userID: "bob", want: "https://time.example.com/bob/2024-W10"},
+		{word: "mine", userID: "carol", want: "https://time.example.com/carol/2024-W10"},
+		{word: "standup", searchTerm: "release", userID: "bob", want: "https://notes.example.com/2024-03-05?topic=release"},
+	}
+
+	for _, tt := range tests {
+		got, err := service.GetLink(ctx, tt.word, tt.searchTerm, tt.userID)
+		if err != nil {
+			t.Fatalf("LinkService.GetLink(%s) error = %v", tt.word, err)
+		}
+		if got != tt.want {
+			t.Errorf("LinkService.GetLink(%s) = %s, want %s", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// zeroWidthJoiner joins emoji into sequences such as 👩‍💻
+const zeroWidthJoiner = '\u200d'
+
+// scriptTables are the scripts letters in a word are told apart by. Characters in none
+// of them, like digits, punctuation, emoji and combining marks, go with any script.
+var scriptTables = map[string]*unicode.RangeTable{
+	"Arabic":     unicode.Arabic,
+	"Armenian":   unicode.Armenian,
+	"Bengali":    unicode.Bengali,
+	"Bopomofo":   unicode.Bopomofo,
+	"Cyrillic":   unicode.Cyrillic,
+	"Devanagari": unicode.Devanagari,
+	"Georgian":   unicode.Georgian,
+	"Greek":      unicode.Greek,
+	"Gujarati":   unicode.Gujarati,
+	"Gurmukhi":   unicode.Gurmukhi,
+	"Han":        unicode.Han,
+	"Hangul":     unicode.Hangul,
+	"Hebrew":     unicode.Hebrew,
+	"Hiragana":   unicode.Hiragana,
+	"Kannada":    unicode.Kannada,
+	"Katakana":   unicode.Katakana,
+	"Khmer":      unicode.Khmer,
+	"Lao":        unicode.Lao,
+	"Latin":      unicode.Latin,
+	"Malayalam":  unicode.Malayalam,
+	"Myanmar":    unicode.Myanmar,
+	"Sinhala":    unicode.Sinhala,
+	"Tamil":      unicode.Tamil,
+	"Telugu":     unicode.Telugu,
+	"Thai":       unicode.Thai,
+	"Tibetan":    unicode.Tibetan,
+}
+
+// mixedScripts are the combinations of scripts commonly written together, which one part
+// of a word may mix. Any other mix, like Latin with Cyrillic, is how lookalike words such
+// as a Cyrillic "раypal" are made.
+var mixedScripts = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// NormalizeWord puts a keyword in the form it is stored and looked up in: trimmed and
+// in Unicode normalization form C, so é typed as one character or as e and an accent
+// is the same word
+func NormalizeWord(word string) string {
+	return norm.NFC.String(strings.TrimSpace(word))
+}
+
+// validateWord checks a normalized keyword only holds printable characters, without %,
+// which would be ambiguous in URLs, and that each part of it between slashes keeps to
+// one script or a common mix of them
+func validateWord(word string) error {
+	if !utf8.ValidString(word) {
+		return InvalidQueryError{Message: "Words must be valid UTF-8"}
+	}
+	for _, r := range word {
+		if r == '%' {
+			return InvalidQueryError{Message: "Words can't contain '%'"}
+		}
+		if !unicode.IsGraphic(r) && r != zeroWidthJoiner {
+			return InvalidQueryError{Message: "Words can only contain printable characters"}
+		}
+	}
+
+	for _, part := range strings.Split(word, "/") {
+		if scripts := wordScripts(part); !commonScripts(scripts) {
+			return InvalidQueryError{
+				Message: "Words can't mix letters of " + strings.Join(scripts, " and ") + ", which makes them look like other words",
+			}
+		}
+	}
+	return nil
+}
+
+// wordScripts lists the scripts of the letters in part, in the order they appear
+func wordScripts(part string) []string {
+	var scripts []string
+	seen := map[string]bool{}
+	for _, r := range part {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for script, table := range scriptTables {
+			if unicode.Is(table, r) && !seen[script] {
+				seen[script] = true
+				scripts = append(scripts, script)
+			}
+		}
+	}
+	return scripts
+}
+
+// commonScripts reports whether scripts are a single script or fit in one of mixedScripts
+func commonScripts(scripts []string) bool {
+	if len(scripts) <= 1 {
+		return true
+	}
+	for _, mix := range mixedScripts {
+		fits := true
+		for _, script := range scripts {
+			if !slices.Contains(mix, script) {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return true
+		}
+	}
+	return false
+}
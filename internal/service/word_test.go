@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestNormalizeWord(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+		want string
+	}{
+		{name: "ascii", word: " docs ", want: "docs"},
+		{name: "composed", word: "\u00e9quipe", want: "\u00e9quipe"},
+		{name: "decomposed", word: "e\u0301quipe", want: "\u00e9quipe"},
+		{name: "hangul jamo", word: "\u1112\u1161\u11ab", want: "\ud55c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeWord(tt.word); got != tt.want {
+				t.Errorf("NormalizeWord(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWord(t *testing.T) {
+	tests := []struct {
+		name    string
+		word    string
+		wantErr bool
+	}{
+		{name: "ascii", word: "docs"},
+		{name: "accented latin", word: "équipe-données"},
+		{name: "cyrillic", word: "команда"},
+		{name: "greek", word: "ομάδα"},
+		{name: "japanese", word: "チーム日本語"},
+		{name: "latin with japanese", word: "team-チーム"},
+		{name: "korean with han", word: "팀漢字"},
+		{name: "emoji", word: "🚀launch"},
+		{name: "emoji sequence", word: "\U0001f469\u200d\U0001f4bb"},
+		{name: "scripts in separate parts", word: "команда/docs"},
+		{name: "latin with cyrillic lookalike", word: "p\u0430ypal", wantErr: true},
+		{name: "latin with greek", word: "Ωmega", wantErr: true},
+		{name: "cyrillic with han", word: "команда漢字", wantErr: true},
+		{name: "korean with japanese kana", word: "팀チーム", wantErr: true},
+		{name: "percent", word: "50%off", wantErr: true},
+		{name: "control character", word: "do\tcs", wantErr: true},
+		{name: "invisible formatting", word: "do\u200bcs", wantErr: true},
+		{name: "invalid utf-8", word: "do\xffcs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWord(tt.word)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateWord(%q) error = %v, wantErr %v", tt.word, err, tt.wantErr)
+			}
+			if err != nil && !sameErrorType(err, InvalidQueryError{}) {
+				t.Errorf("validateWord(%q) error = %v, want InvalidQueryError", tt.word, err)
+			}
+		})
+	}
+}
+
+func TestLinkService_UnicodeWords(t *testing.T) {
+	shortcutRepo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{}}
+	service := NewLinkService(shortcutRepo, &mockQueryRepository{})
+	ctx := context.Background()
+
+	// Stored composed however it was typed
+	err := service.UpdateLink(ctx, domain.LinkRequest{Word: "e\u0301quipe", Link: "https://team.example.com"}, "alice")
+	if err != nil {
+		t.Fatalf("LinkService.UpdateLink() error = %v", err)
+	}
+	if _, ok := shortcutRepo.shortcuts["\u00e9quipe"]; !ok {
+		t.Errorf("LinkService.UpdateLink() stored %v, want the composed word", shortcutRepo.shortcuts)
+	}
+
+	for _, word := range []string{"\u00e9quipe", "e\u0301quipe"} {
+		got, err := service.GetLink(ctx, word, "", "bob")
+		if err != nil || got != "https://team.example.com" {
+			t.Errorf("LinkService.GetLink(%q) = %q, %v, want https://team.example.com", word, got, err)
+		}
+	}
+
+	err = service.UpdateLink(ctx, domain.LinkRequest{Word: "p\u0430ypal", Link: "https://evil.example.com"}, "alice")
+	if !sameErrorType(err, InvalidQueryError{}) {
+		t.Errorf("LinkService.UpdateLink() of a mixed-script word error = %v, want InvalidQueryError", err)
+	}
+}
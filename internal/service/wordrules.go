@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordRules are the keyword syntax an organization enforces on new keywords, on top of
+// the checks every keyword passes. The zero value allows any keyword.
+type WordRules struct {
+	// MinLength and MaxLength bound the characters in a keyword, namespace included; a
+	// MaxLength of 0 sets no limit
+	MinLength int
+	MaxLength int
+
+	// Separators, when not empty, are the only characters other than letters, digits and
+	// the / of namespaces keywords may contain
+	Separators string
+
+	// Pattern, when set, must match the whole keyword, and Hint explains it to people
+	// whose keyword doesn't
+	Pattern *regexp.Regexp
+	Hint    string
+}
+
+// NewWordRules creates rules from their configuration, compiling pattern to match whole
+// keywords
+func NewWordRules(minLength, maxLength int, separators, pattern, hint string) (*WordRules, error) {
+	if minLength < 0 || maxLength < 0 || (maxLength > 0 && maxLength < minLength) {
+		return nil, fmt.Errorf("keyword length limits %d to %d are out of order", minLength, maxLength)
+	}
+	rules := &WordRules{MinLength: minLength, MaxLength: maxLength, Separators: separators, Hint: hint}
+	if pattern != "" {
+		compiled, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyword pattern: %w", err)
+		}
+		rules.Pattern = compiled
+		if hint == "" {
+			rules.Hint = fmt.Sprintf("Keywords must match %s", pattern)
+		}
+	}
+	return rules, nil
+}
+
+// WithWordRules enforces rules on keywords as they are created
+func WithWordRules(rules *WordRules) Option {
+	return func(s *LinkService) {
+		s.wordRules = rules
+	}
+}
+
+// check rejects a normalized word that breaks the rules, saying which rule it breaks
+func (r *WordRules) check(word string) error {
+	if r == nil {
+		return nil
+	}
+
+	length := utf8.RuneCountInString(word)
+	if length < r.MinLength {
+		return InvalidQueryError{Message: fmt.Sprintf("Keywords must be at least %d characters long", r.MinLength)}
+	}
+	if r.MaxLength > 0 && length > r.MaxLength {
+		return InvalidQueryError{Message: fmt.Sprintf("Keywords can be at most %d characters long", r.MaxLength)}
+	}
+
+	if r.Separators != "" {
+		for _, c := range word {
+			if c == '/' || unicode.IsLetter(c) || unicode.IsNumber(c) || unicode.IsMark(c) || strings.ContainsRune(r.Separators, c) {
+				continue
+			}
+			return InvalidQueryError{
+				Message: fmt.Sprintf("Keywords can't contain %q; besides letters and digits they may only use %s", c, r.separatorList()),
+			}
+		}
+	}
+
+	if r.Pattern != nil && !r.Pattern.MatchString(word) {
+		return InvalidQueryError{Message: r.Hint}
+	}
+	return nil
+}
+
+// separatorList names the characters other than letters and digits keywords may use
+func (r *WordRules) separatorList() string {
+	chars := []string{"/"}
+	for _, c := range r.Separators {
+		if c == ' ' {
+			chars = append(chars, "spaces")
+		} else {
+			chars = append(chars, string(c))
+		}
+	}
+	return strings.Join(chars[:len(chars)-1], " ") + " and " + chars[len(chars)-1]
+}
+
+// Describe summarizes the rules for people choosing a keyword, or returns "" if there
+// are none
+func (r *WordRules) Describe() string {
+	if r == nil {
+		return ""
+	}
+
+	var sentences []string
+	switch {
+	case r.MaxLength > 0 && r.MinLength > 1:
+		sentences = append(sentences, fmt.Sprintf("Keywords are %d to %d characters long.", r.MinLength, r.MaxLength))
+	case r.MaxLength > 0:
+		sentences = append(sentences, fmt.Sprintf("Keywords are at most %d characters long.", r.MaxLength))
+	case r.MinLength > 1:
+		sentences = append(sentences, fmt.Sprintf("Keywords are at least %d characters long.", r.MinLength))
+	}
+	if r.Separators != "" {
+		sentences = append(sentences, "Besides letters and digits they may only use "+r.separatorList()+".")
+	}
+	if r.Pattern != nil {
+		sentences = append(sentences, r.Hint)
+	}
+	return strings.Join(sentences, " ")
+}
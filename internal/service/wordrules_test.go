@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"golinks/internal/domain"
+)
+
+func TestWordRules_check(t *testing.T) {
+	rules, err := NewWordRules(2, 12, "-_", `[\p{Ll}\d/_-]+`, "Keywords are lowercase")
+	if err != nil {
+		t.Fatalf("NewWordRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rules   *WordRules
+		word    string
+		wantErr string
+	}{
+		{name: "no rules", word: "Any thing!"},
+		{name: "valid", rules: rules, word: "team/on-call"},
+		{name: "too short", rules: rules, word: "d", wantErr: "Keywords must be at least 2 characters long"},
+		{name: "too long", rules: rules, word: "documentation", wantErr: "Keywords can be at most 12 characters long"},
+		{name: "length counts characters", rules: rules, word: "équipe-été"},
+		{name: "separator", rules: rules, word: "on.call", wantErr: `Keywords can't contain '.'; besides letters and digits they may only use / - and _`},
+		{name: "space", rules: rules, word: "on call", wantErr: `Keywords can't contain ' '; besides letters and digits they may only use / - and _`},
+		{name: "pattern", rules: rules, word: "OnCall", wantErr: "Keywords are lowercase"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.check(tt.word)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("WordRules.check(%q) error = %v", tt.word, err)
+				}
+				return
+			}
+			if _, ok := err.(InvalidQueryError); !ok || err.Error() != tt.wantErr {
+				t.Errorf("WordRules.check(%q) error = %v, want %q", tt.word, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewWordRules(t *testing.T) {
+	tests := []struct {
+		name         string
+		min, max     int
+		separators   string
+		pattern      string
+		hint         string
+		wantErr      bool
+		wantDescribe string
+	}{
+		{name: "defaults", min: 1},
+		{
+			name: "all rules", min: 2, max: 32, separators: "-_ ", pattern: "[a-z].*", hint: "Keywords start with a lowercase letter.",
+			wantDescribe: "Keywords are 2 to 32 characters long. Besides letters and digits they may only use / - _ and spaces. Keywords start with a lowercase letter.",
+		},
+		{name: "pattern without hint", min: 1, max: 20, pattern: "[a-z]+", wantDescribe: "Keywords are at most 20 characters long. Keywords must match [a-z]+"},
+		{name: "minimum only", min: 3, wantDescribe: "Keywords are at least 3 characters long."},
+		{name: "limits out of order", min: 10, max: 5, wantErr: true},
+		{name: "invalid pattern", min: 1, pattern: "[a-z", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewWordRules(tt.min, tt.max, tt.separators, tt.pattern, tt.hint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewWordRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := rules.Describe(); got != tt.wantDescribe {
+				t.Errorf("WordRules.Describe() = %q, want %q", got, tt.wantDescribe)
+			}
+		})
+	}
+}
+
+func TestLinkService_UpdateLink_WordRules(t *testing.T) {
+	rules, err := NewWordRules(1, 8, "-", "", "")
+	if err != nil {
+		t.Fatalf("NewWordRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		word    string
+		wantErr bool
+	}{
+		{name: "new keyword", word: "on-call"},
+		{name: "new keyword breaking the rules", word: "on_call_rotation", wantErr: true},
+		{name: "existing keyword breaking the rules", word: "old_style_keyword"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockShortcutRepository{shortcuts: map[string]*domain.Shortcut{
+				"old_style_keyword": {ID: 1, Word: "old_style_keyword", Link: "https://old.example.com", User: "alice"},
+			}}
+			service := NewLinkService(repo, &mockQueryRepository{}, WithWordRules(rules))
+
+			err := service.UpdateLink(context.Background(), domain.LinkRequest{Word: tt.word, Link: "https://example.com"}, "alice")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LinkService.UpdateLink(%s) error = %v, wantErr %v", tt.word, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+// Package client calls a golinks server's JSON API, so Go programs can resolve, create,
+// list, delete and count golinks without writing the HTTP requests themselves.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retry defaults used by New
+const (
+	DefaultRetries = 2
+	DefaultBackoff = 200 * time.Millisecond
+
+	// maxBackoff caps the wait between retries, including waits servers ask for
+	maxBackoff = 10 * time.Second
+)
+
+// Client calls the API of one golinks server. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	apiKey     string
+	userAgent  string
+
+	retries int
+	backoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithAPIKey authenticates every request with an API key minted on the server, so
+// changes are made as the key's user
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithHTTPClient sends requests through httpClient instead of a client with a 30 second
+// timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries retries a request up to retries times when the server can't be reached or
+// is unavailable, waiting backoff before the first retry and twice as long before each
+// one after. Zero retries sends each request once.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries, c.backoff = retries, backoff
+	}
+}
+
+// WithUserAgent names the program making the requests in their User-Agent header
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New creates a client for the golinks server at baseURL, such as
+// https://go.example.com
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("golinks URL %q must be an http:// or https:// URL", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "golinks-go-client",
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Link is the current version of a golink, with when it was first created and when and
+// by whom it was last changed
+type Link struct {
+	ID          int                    `json:"id"`
+	Word        string                 `json:"word"`
+	Link        string                 `json:"link"`
+	Description string                 `json:"description,omitempty"`
+	User        string                 `json:"user"`
+	Icon        string                 `json:"icon,omitempty"`
+	Private     bool                   `json:"private,omitempty"`
+	Prefix      bool                   `json:"prefix,omitempty"`
+	Archived    bool                   `json:"archived,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	UpdatedBy   string                 `json:"updated_by,omitempty"`
+}
+
+// LinkRequest creates or changes a golink. Link is a URL, which may hold {*} or {1}
+// placeholders for search terms, or another keyword to make an alias of. Prefix links
+// also resolve paths below their word by appending the rest of the path to Link, and
+// archived links stay listed but no longer resolve. Leaving Metadata empty keeps the
+// link's current metadata; SetMetadata replaces or clears it.
+type LinkRequest struct {
+	Word        string                 `json:"word,omitempty"`
+	Link        string                 `json:"link"`
+	Description string                 `json:"description,omitempty"`
+	Icon        string                 `json:"icon,omitempty"`
+	Private     bool                   `json:"private,omitempty"`
+	Prefix      bool                   `json:"prefix,omitempty"`
+	Archived    bool                   `json:"archived,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	// Owner hands the link to another user; Force lets admins overwrite links they don't own
+	Owner string `json:"owner,omitempty"`
+	Force bool   `json:"force,omitempty"`
+}
+
+// Resolution is where a query such as "docs" or "jira ABC-123" leads, and how it got there
+type Resolution struct {
+	Query        string `json:"query"`
+	URL          string `json:"url"`
+	Word         string `json:"word"`
+	SearchTerm   string `json:"search_term"`
+	ResolvedWord string `json:"resolved_word"`
+	Substituted  bool   `json:"substituted"`
+	Hops         int    `json:"hops"`
+	Owner        string `json:"owner"`
+	Path         string `json:"path,omitempty"`
+}
+
+// Keyword is a golink as listed, with its tags and the aliases pointing at it
+type Keyword struct {
+	Word        string                 `json:"word"`
+	Aliases     string                 `json:"aliases"`
+	Link        string                 `json:"link"`
+	Description string                 `json:"description,omitempty"`
+	Icon        string                 `json:"icon,omitempty"`
+	Private     bool                   `json:"private,omitempty"`
+	Prefix      bool                   `json:"prefix,omitempty"`
+	Archived    bool                   `json:"archived,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Tags        []string               `json:"tags"`
+	ClickCount  int                    `json:"click_count"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	UpdatedBy   string                 `json:"updated_by,omitempty"`
+}
+
+// KeywordPage is one page of the keyword list, with how many keywords match in all
+type KeywordPage struct {
+	Keywords []Keyword `json:"keywords"`
+	Query    string    `json:"query,omitempty"`
+	Sort     string    `json:"sort"`
+	Total    int       `json:"total"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
+
+// ListOptions narrows and pages the keyword list. Query keeps the keywords whose word,
+// description, link, owner or metadata contains it; a zero Limit uses the server's page
+// size.
+type ListOptions struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// Stats counts how often a golink was followed since Since, per day or week
+type Stats struct {
+	Word      string          `json:"word"`
+	Interval  string          `json:"interval"`
+	Since     time.Time       `json:"since"`
+	Total     int             `json:"total"`
+	Clicks    []ClickCount    `json:"clicks"`
+	Referrers []ReferrerCount `json:"referrers"`
+}
+
+// ClickCount is how many times a golink was followed in the day or week starting at Start
+type ClickCount struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// ReferrerCount is how many clicks on a golink came from one referring host
+type ReferrerCount struct {
+	Referrer string `json:"referrer"`
+	Count    int    `json:"count"`
+}
+
+// StatsOptions picks the buckets of Stats: Interval is "day" or "week", and Days how far
+// back to count; zero values use the server's defaults
+type StatsOptions struct {
+	Interval string
+	Days     int
+}
+
+// Error is an error answered by the server, with the HTTP status and the server's detail
+type Error struct {
+	StatusCode int
+	Detail     string
+}
+
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("golinks answered %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("golinks answered %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Detail)
+}
+
+// IsNotFound reports whether err is the server saying a golink doesn't exist
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether err is the server refusing to create a golink that exists
+func IsConflict(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// Resolve finds where a query leads, as following go/<query> would, without redirecting
+// or counting a click
+func (c *Client) Resolve(ctx context.Context, query string) (*Resolution, error) {
+	var resolution Resolution
+	err := c.do(ctx, http.MethodGet, "/api/resolve/detail", url.Values{"q": {query}}, nil, &resolution)
+	if err != nil {
+		return nil, err
+	}
+	return &resolution, nil
+}
+
+// Get returns the current version of a golink
+func (c *Client) Get(ctx context.Context, word string) (*Link, error) {
+	var link Link
+	if err := c.do(ctx, http.MethodGet, linkPath(word), nil, nil, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// Create creates a golink, failing with an error IsConflict reports if the word exists
+func (c *Client) Create(ctx context.Context, req LinkRequest) (*Link, error) {
+	var link Link
+	if err := c.do(ctx, http.MethodPost, "/api/v1/links", nil, req, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// Update points word at req.Link, creating the golink if it doesn't exist
+func (c *Client) Update(ctx context.Context, word string, req LinkRequest) (*Link, error) {
+	req.Word = word
+	var link Link
+	if err := c.do(ctx, http.MethodPut, linkPath(word), nil, req, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// SetMetadata replaces the metadata of a golink, clearing it when metadata is empty
+func (c *Client) SetMetadata(ctx context.Context, word string, metadata map[string]interface{}) (*Link, error) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	var link Link
+	if err := c.do(ctx, http.MethodPut, "/api/links"+escapeWord(word)+"/metadata", nil, metadata, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// List returns one page of the golinks visible to the client, newest first
+func (c *Client) List(ctx context.Context, opts ListOptions) (*KeywordPage, error) {
+	query := url.Values{}
+	if opts.Query != "" {
+		query.Set("q", opts.Query)
+	}
+	if opts.Limit != 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset != 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+
+	var page KeywordPage
+	if err := c.do(ctx, http.MethodGet, "/api/v1/links", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Delete moves a golink and all of its versions to the server's trash
+func (c *Client) Delete(ctx context.Context, word string) error {
+	return c.do(ctx, http.MethodDelete, linkPath(word), nil, nil, nil)
+}
+
+// Stats counts the clicks on a golink
+func (c *Client) Stats(ctx context.Context, word string, opts StatsOptions) (*Stats, error) {
+	query := url.Values{}
+	if opts.Interval != "" {
+		query.Set("interval", opts.Interval)
+	}
+	if opts.Days != 0 {
+		query.Set("days", strconv.Itoa(opts.Days))
+	}
+
+	var stats Stats
+	if err := c.do(ctx, http.MethodGet, "/api/links"+escapeWord(word)+"/stats", query, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// linkPath is the v1 API path of a golink
+func linkPath(word string) string {
+	return "/api/v1/links" + escapeWord(word)
+}
+
+// escapeWord escapes a word as a path suffix, keeping the slash of namespaced words like
+// payments/runbook
+func escapeWord(word string) string {
+	return (&url.URL{Path: "/" + strings.TrimSpace(word)}).EscapedPath()
+}
+
+// do sends a request with body, if any, as JSON, retrying it while it may be retried,
+// and decodes a successful answer into out, if any
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	// path is escaped already
+	target := c.baseURL.String() + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, target, payload)
+		retry, wait := c.shouldRetry(method, resp, err), backoff
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				wait = after
+			}
+		}
+		if !retry || attempt >= c.retries {
+			if err != nil {
+				return err
+			}
+			return decodeResponse(resp, out)
+		}
+		if resp != nil {
+			drain(resp)
+		}
+
+		timer := time.NewTimer(min(wait, maxBackoff))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// send makes one attempt at a request
+func (c *Client) send(ctx context.Context, method, target string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// shouldRetry reports whether a request that got resp or err may be tried again. Requests
+// are retried when the server is unavailable, and also when it couldn't be reached unless
+// they create a link, which may have been created before the connection failed.
+func (c *Client) shouldRetry(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		return method != http.MethodPost && !errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method != http.MethodPost
+	}
+	return false
+}
+
+// retryAfter reads how long a server asked to be left alone for, in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// decodeResponse decodes a successful answer into out, or turns a failed one into an
+// *Error with the server's detail
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer drain(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body struct {
+			Detail string `json:"detail"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+		return &Error{StatusCode: resp.StatusCode, Detail: body.Detail}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// drain reads what is left of a response and closes it, so its connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Requests(t *testing.T) {
+	type request struct {
+		method, uri, auth, body string
+	}
+	var got request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = request{r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"), string(body)}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasPrefix(r.URL.Path, "/golinks/api/resolve/"):
+			w.Write([]byte(`{"query": "jira ABC-1", "url": "https://jira.example.com/ABC-1", "word": "jira", "search_term": "ABC-1"}`))
+		case strings.HasSuffix(r.URL.Path, "/stats"):
+			w.Write([]byte(`{"word": "docs", "interval": "week", "total": 3, "clicks": [{"count": 3}]}`))
+		case r.URL.Path == "/golinks/api/v1/links" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"keywords": [{"word": "docs", "link": "https://docs.example.com", "tags": ["eng"]}], "total": 1}`))
+		default:
+			w.Write([]byte(`{"id": 7, "word": "docs", "link": "https://docs.example.com", "user": "alice"}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL+"/golinks/", WithAPIKey("glk_secret"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		call  func() (interface{}, error)
+		want  request
+		check func(result interface{}) bool
+	}{
+		{
+			name: "resolve",
+			call: func() (interface{}, error) { return c.Resolve(ctx, "jira ABC-1") },
+			want: request{method: "GET", uri: "/golinks/api/resolve/detail?q=jira+ABC-1"},
+			check: func(result interface{}) bool {
+				return result.(*Resolution).URL == "https://jira.example.com/ABC-1"
+			},
+		},
+		{
+			name:  "get namespaced word",
+			call:  func() (interface{}, error) { return c.Get(ctx, "payments/run book") },
+			want:  request{method: "GET", uri: "/golinks/api/v1/links/payments/run%20book"},
+			check: func(result interface{}) bool { return result.(*Link).ID == 7 },
+		},
+		{
+			name: "create",
+			call: func() (interface{}, error) {
+				return c.Create(ctx, LinkRequest{Word: "docs", Link: "https://docs.example.com"})
+			},
+			want:  request{method: "POST", uri: "/golinks/api/v1/links", body: `{"word":"docs","link":"https://docs.example.com"}`},
+			check: func(result interface{}) bool { return result.(*Link).User == "alice" },
+		},
+		{
+			name: "update",
+			call: func() (interface{}, error) {
+				return c.Update(ctx, "docs", LinkRequest{Link: "https://docs.example.com", Private: true})
+			},
+			want:  request{method: "PUT", uri: "/golinks/api/v1/links/docs", body: `{"word":"docs","link":"https://docs.example.com","private":true}`},
+			check: func(result interface{}) bool { return result.(*Link).Word == "docs" },
+		},
+		{
+			name: "set metadata",
+			call: func() (interface{}, error) {
+				return c.SetMetadata(ctx, "docs", map[string]interface{}{"team": "docs"})
+			},
+			want:  request{method: "PUT", uri: "/golinks/api/links/docs/metadata", body: `{"team":"docs"}`},
+			check: func(result interface{}) bool { return result.(*Link).Word == "docs" },
+		},
+		{
+			name: "list",
+			call: func() (interface{}, error) { return c.List(ctx, ListOptions{Query: "doc", Limit: 10, Offset: 20}) },
+			want: request{method: "GET", uri: "/golinks/api/v1/links?limit=10&offset=20&q=doc"},
+			check: func(result interface{}) bool {
+				page := result.(*KeywordPage)
+				return page.Total == 1 && page.Keywords[0].Tags[0] == "eng"
+			},
+		},
+		{
+			name:  "delete",
+			call:  func() (interface{}, error) { return nil, c.Delete(ctx, "docs") },
+			want:  request{method: "DELETE", uri: "/golinks/api/v1/links/docs"},
+			check: func(result interface{}) bool { return true },
+		},
+		{
+			name: "stats",
+			call: func() (interface{}, error) {
+				return c.Stats(ctx, "docs", StatsOptions{Interval: "week", Days: 28})
+			},
+			want:  request{method: "GET", uri: "/golinks/api/links/docs/stats?days=28&interval=week"},
+			check: func(result interface{}) bool { return result.(*Stats).Total == 3 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.call()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			tt.want.auth = "Bearer glk_secret"
+			if got != tt.want {
+				t.Errorf("request = %+v, want %+v", got, tt.want)
+			}
+			if !tt.check(result) {
+				t.Errorf("result = %+v, not decoded as expected", result)
+			}
+		})
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusNotFound
+		if r.Method == http.MethodPost {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"detail": "No golink found for docs"})
+	}))
+	defer server.Close()
+
+	c, _ := New(server.URL)
+	_, err := c.Get(context.Background(), "docs")
+	if !IsNotFound(err) || !strings.Contains(err.Error(), "No golink found for docs") {
+		t.Errorf("Get() error = %v, want a not found error with the server's detail", err)
+	}
+	_, err = c.Create(context.Background(), LinkRequest{Word: "docs", Link: "https://docs.example.com"})
+	if !IsConflict(err) || IsNotFound(err) {
+		t.Errorf("Create() error = %v, want a conflict", err)
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		failures     int
+		status       int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{"retries unavailable server", http.MethodGet, 2, http.StatusServiceUnavailable, 3, false},
+		{"gives up after retries", http.MethodGet, 5, http.StatusServiceUnavailable, 3, true},
+		{"retries bad gateway", http.MethodDelete, 1, http.StatusBadGateway, 2, false},
+		{"creates only retried when not processed", http.MethodPost, 1, http.StatusBadGateway, 1, true},
+		{"creates retried when rate limited", http.MethodPost, 1, http.StatusTooManyRequests, 2, false},
+		{"client errors are not retried", http.MethodGet, 1, http.StatusBadRequest, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(attempts.Add(1)) <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				if r.Method == http.MethodDelete {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				w.Write([]byte(`{"word": "docs", "link": "https://docs.example.com"}`))
+			}))
+			defer server.Close()
+
+			c, _ := New(server.URL, WithRetries(2, time.Millisecond))
+			var err error
+			switch tt.method {
+			case http.MethodGet:
+				_, err = c.Get(context.Background(), "docs")
+			case http.MethodPost:
+				_, err = c.Create(context.Background(), LinkRequest{Word: "docs", Link: "https://docs.example.com"})
+			case http.MethodDelete:
+				err = c.Delete(context.Background(), "docs")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestClient_RetriesUnreachableServer(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	c, _ := New(server.URL, WithRetries(1, time.Millisecond))
+	if _, err := c.Get(context.Background(), "docs"); err == nil {
+		t.Error("Get() from a closed server succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c, _ = New(server.URL, WithRetries(5, time.Hour))
+	if _, err := c.Get(ctx, "docs"); err == nil {
+		t.Error("Get() with a canceled context succeeded")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, baseURL := range []string{"go.example.com", "ftp://go.example.com", "https://"} {
+		if _, err := New(baseURL); err == nil {
+			t.Errorf("New(%q) succeeded, want an error", baseURL)
+		}
+	}
+}
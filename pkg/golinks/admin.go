@@ -0,0 +1,235 @@
+package golinks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golinks/internal/domain"
+	"golinks/internal/handlers"
+	"golinks/internal/repository"
+	"golinks/internal/service"
+)
+
+const adminUsage = `usage: golinks [serve]
+       golinks export [-history] [-user name] [-tag tag] [-namespace name] [-o file]
+       golinks import [-format csv|trotto|golinksio] [-on-conflict skip|overwrite] [-user name] [-dry-run] file|-
+       golinks backup
+       golinks prune
+       golinks user add [-role viewer|editor|admin] name
+       golinks migrate up | down [steps] | status | legacy <path>`
+
+// adminServices are what the administration commands manage the instance through
+type adminServices struct {
+	links              *service.LinkService
+	backups            *service.BackupService
+	roles              repository.RoleStore
+	sessions           repository.SessionStore
+	queryRetentionDays int
+
+	// importUser is who links are imported as unless -user says otherwise
+	importUser string
+}
+
+// runAdmin runs an administration command, reading files named - from stdin and writing
+// what it did to out
+func runAdmin(ctx context.Context, services adminServices, args []string, stdin io.Reader, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(adminUsage)
+	}
+
+	switch args[0] {
+	case "export":
+		return runExport(ctx, services, args[1:], out)
+	case "import":
+		return runImport(ctx, services, args[1:], stdin, out)
+	case "backup":
+		if len(args) > 1 {
+			return errors.New(adminUsage)
+		}
+		backup, err := services.backups.CreateBackup(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "saved %s (%d bytes)\n", backup.Name, backup.Size)
+	case "prune":
+		if len(args) > 1 {
+			return errors.New(adminUsage)
+		}
+		return runPrune(ctx, services, out)
+	case "help":
+		fmt.Fprintln(out, adminUsage)
+	case "user":
+		if len(args) < 2 || args[1] != "add" {
+			return errors.New(adminUsage)
+		}
+		return runUserAdd(ctx, services, args[2:], out)
+	default:
+		return errors.New(adminUsage)
+	}
+
+	return nil
+}
+
+// parseCommandFlags parses a command's flags, leaving usage errors to the caller
+func parseCommandFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%s: %v\n%s", flags.Name(), err, adminUsage)
+	}
+	return nil
+}
+
+// runExport writes every link as JSON, like GET /api/links/export, to stdout or a file
+func runExport(ctx context.Context, services adminServices, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	var req domain.ExportRequest
+	flags.BoolVar(&req.History, "history", false, "include every version of each link")
+	flags.StringVar(&req.User, "user", "", "export only the links this user owns")
+	flags.StringVar(&req.Tag, "tag", "", "export only the links with this tag")
+	flags.StringVar(&req.Namespace, "namespace", "", "export only the links in this namespace")
+	path := flags.String("o", "", "file to write the export to instead of stdout")
+	if err := parseCommandFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return errors.New(adminUsage)
+	}
+
+	export, err := services.links.ExportLinks(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export: %w", err)
+	}
+	data = append(data, '\n')
+	if *path == "" {
+		_, err := out.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	fmt.Fprintf(out, "exported %d links to %s\n", len(export.Links), *path)
+	return nil
+}
+
+// runImport imports links from a file, like POST /api/links/import, as the given user.
+// It fails if any link could not be imported, after importing the rest, or with -dry-run
+// if any would fail.
+func runImport(ctx context.Context, services adminServices, args []string, stdin io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "csv", "format of the file: csv, trotto or golinksio")
+	policy := flags.String("on-conflict", domain.ImportSkip, "skip or overwrite words that already exist")
+	user := flags.String("user", services.importUser, "user to import as, who owns links without an owner")
+	dryRun := flags.Bool("dry-run", false, "report what the import would do without changing anything")
+	if err := parseCommandFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(adminUsage)
+	}
+
+	body := stdin
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open import: %w", err)
+		}
+		defer file.Close()
+		body = file
+	}
+
+	links, lines, err := handlers.ParseImport(*format, body)
+	if err != nil {
+		return err
+	}
+	report, err := services.links.ImportLinks(ctx, links, *policy, *user, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	for i, result := range report.Results {
+		if result.Status != domain.BulkStatusFailed {
+			continue
+		}
+		if lines[i] > 0 {
+			fmt.Fprintf(out, "line %d: ", lines[i])
+		}
+		fmt.Fprintf(out, "%s: %s\n", result.Word, result.Error)
+	}
+	if report.DryRun {
+		for i, result := range report.Results {
+			if result.Link == "" {
+				continue
+			}
+			if lines[i] > 0 {
+				fmt.Fprintf(out, "line %d: ", lines[i])
+			}
+			fmt.Fprintf(out, "%s: would be %s to %s\n", result.Word, result.Status, result.Link)
+		}
+		fmt.Fprint(out, "dry run, nothing changed: ")
+	}
+	fmt.Fprintf(out, "created %d, updated %d, skipped %d, failed %d\n",
+		report.Created, report.Updated, report.Skipped, report.Failed)
+	if report.Failed > 0 && report.DryRun {
+		return fmt.Errorf("%d links would fail to import", report.Failed)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d links failed to import", report.Failed)
+	}
+	return nil
+}
+
+// runPrune rolls up queries older than QUERY_RETENTION_DAYS, when it is set, and deletes
+// expired sessions
+func runPrune(ctx context.Context, services adminServices, out io.Writer) error {
+	if services.queryRetentionDays > 0 {
+		prune, err := services.links.PruneQueries(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "rolled up %d queries from before %s\n", prune.Pruned, prune.Before.Format(time.DateOnly))
+	} else {
+		fmt.Fprintln(out, "kept every query, as QUERY_RETENTION_DAYS is not set")
+	}
+
+	expired, err := services.sessions.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "deleted %d expired sessions\n", expired)
+	return nil
+}
+
+// runUserAdd assigns a role to a user, editor unless given. Unlike the API it needs no
+// admin to do so, as whoever runs it already controls the instance.
+func runUserAdd(ctx context.Context, services adminServices, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("user add", flag.ContinueOnError)
+	role := flags.String("role", string(domain.RoleEditor), "role to assign: viewer, editor or admin")
+	if err := parseCommandFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || strings.TrimSpace(flags.Arg(0)) == "" {
+		return errors.New(adminUsage)
+	}
+
+	userRole := &domain.UserRole{User: strings.TrimSpace(flags.Arg(0)), Role: domain.Role(*role)}
+	if !userRole.Role.Valid() {
+		return fmt.Errorf("unknown role %q; use viewer, editor or admin", *role)
+	}
+	if err := services.roles.Set(ctx, userRole); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s is now %s\n", userRole.User, userRole.Role)
+	return nil
+}
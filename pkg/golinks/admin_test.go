@@ -0,0 +1,104 @@
+package golinks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golinks/internal/domain"
+	"golinks/internal/repository"
+	"golinks/internal/service"
+)
+
+func TestRunAdmin(t *testing.T) {
+	ctx := context.Background()
+	store, err := repository.Open("memory", repository.Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+	if _, err := store.Migrations.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "links.csv")
+	if err := os.WriteFile(csvPath, []byte("docs,https://docs.example.com,alice,team\nbad,not a link\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write import: %v", err)
+	}
+	exportPath := filepath.Join(dir, "export.json")
+
+	services := adminServices{
+		links: service.NewLinkService(store.Shortcuts, store.Queries,
+			service.WithTags(store.Tags),
+			service.WithQueryRetention(30),
+			service.WithRoles(service.NewRoleService(store.Roles, []string{"ops"}, domain.RoleEditor)),
+		),
+		backups:            service.NewBackupService(store.Snapshots, repository.NewBackupDirectory(filepath.Join(dir, "backups"))),
+		roles:              store.Roles,
+		sessions:           store.Sessions,
+		queryRetentionDays: 30,
+		importUser:         "ops",
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    []string
+		wantErr string
+	}{
+		{name: "dry run", args: []string{"import", "-dry-run", csvPath}, want: []string{
+			"line 1: docs: would be created to https://docs.example.com", "line 2: bad: ",
+			"dry run, nothing changed: created 1, updated 0, skipped 0, failed 1",
+		}, wantErr: "1 links would fail"},
+		{name: "import with a failure", args: []string{"import", csvPath}, want: []string{
+			"line 2: bad: ", "created 1, updated 0, skipped 0, failed 1",
+		}, wantErr: "1 links failed"},
+		{name: "import from stdin", args: []string{"import", "-format", "trotto", "-on-conflict", "overwrite", "-user", "erin", "-"},
+			stdin: `[{"shortpath": "gh/%s", "destination_url": "https://github.com/%s"}]`,
+			want:  []string{"created 1, updated 0, skipped 0, failed 0"}},
+		{name: "import in an unknown format", args: []string{"import", "-format", "xlsx", csvPath}, wantErr: "Unknown import format"},
+		{name: "export to stdout", args: []string{"export"}, want: []string{`"word": "docs"`, `"owner": "alice"`, `"word": "gh"`, `"owner": "erin"`}},
+		{name: "export by owner", args: []string{"export", "-user", "alice"}, want: []string{`"word": "docs"`}},
+		{name: "export to a file", args: []string{"export", "-history", "-o", exportPath}, want: []string{"exported 2 links to " + exportPath}},
+		{name: "backup", args: []string{"backup"}, want: []string{"saved golinks-"}},
+		{name: "prune", args: []string{"prune"}, want: []string{"rolled up 0 queries from before", "deleted 0 expired sessions"}},
+		{name: "add a user", args: []string{"user", "add", "-role", "admin", "bob"}, want: []string{"bob is now admin"}},
+		{name: "add a user as an editor", args: []string{"user", "add", "carol"}, want: []string{"carol is now editor"}},
+		{name: "unknown role", args: []string{"user", "add", "-role", "owner", "dave"}, wantErr: "unknown role"},
+		{name: "unknown flag", args: []string{"export", "-csv"}, wantErr: "flag provided but not defined"},
+		{name: "help", args: []string{"help"}, want: []string{"golinks user add"}},
+		{name: "unknown command", args: []string{"launch"}, wantErr: "usage"},
+		{name: "no user to add", args: []string{"user", "add"}, wantErr: "usage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runAdmin(ctx, services, tt.args, strings.NewReader(tt.stdin), &out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("runAdmin() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("runAdmin() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("runAdmin() output = %q, want %q", out.String(), want)
+				}
+			}
+		})
+	}
+
+	if _, err := os.Stat(exportPath); err != nil {
+		t.Errorf("export file: %v", err)
+	}
+	if role, err := store.Roles.Get(ctx, "bob"); err != nil || role != domain.RoleAdmin {
+		t.Errorf("role of bob = %q, %v, want admin", role, err)
+	}
+}
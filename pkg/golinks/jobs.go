@@ -0,0 +1,159 @@
+package golinks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"golinks/internal/domain"
+	"golinks/internal/service"
+)
+
+// queryPruneInterval is how often old queries are rolled up when QUERY_RETENTION_DAYS is set
+const queryPruneInterval = time.Hour
+
+// pruneQueries runs the query log retention job at startup and then every
+// queryPruneInterval until stop is closed
+func pruneQueries(logger *slog.Logger, linkService *service.LinkService, stop <-chan struct{}) {
+	ticker := time.NewTicker(queryPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		prune, err := linkService.PruneQueries(context.Background())
+		if err != nil {
+			logger.Error("Failed to prune queries", "err", err)
+		} else if prune.Pruned > 0 {
+			logger.Info("Rolled up queries", "pruned", prune.Pruned, "before", prune.Before.Format(time.DateOnly))
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshotRetryDelay is how long a failed snapshot waits before it is tried again
+const snapshotRetryDelay = 5 * time.Minute
+
+// takeSnapshots saves a snapshot whenever one is due until stop is closed, retrying
+// failures after snapshotRetryDelay
+func takeSnapshots(logger *slog.Logger, snapshots *service.SnapshotService, stop <-chan struct{}) {
+	for {
+		wait, err := snapshots.Due(context.Background())
+		if err != nil {
+			logger.Error("Failed to list snapshots", "err", err)
+			wait = snapshotRetryDelay
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err != nil {
+			continue
+		}
+
+		snapshot, err := snapshots.TakeSnapshot(context.Background())
+		if err != nil {
+			logger.Error("Failed to take snapshot", "err", err)
+			timer := time.NewTimer(snapshotRetryDelay)
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		for _, saved := range snapshot.Saved {
+			logger.Info("Saved snapshot", "name", saved.Name, "size", saved.Size)
+		}
+		if len(snapshot.Deleted) > 0 {
+			logger.Info("Deleted old snapshots", "names", snapshot.Deleted)
+		}
+	}
+}
+
+// syncFromPrimary pulls the changes made on the primary at startup and then every
+// interval until stop is closed
+func syncFromPrimary(logger *slog.Logger, sync *service.SyncService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := sync.Pull(context.Background())
+		if err != nil {
+			logger.Error("Failed to sync from primary", "err", err)
+		}
+		if result != nil && result.Versions+result.Deleted+result.Restored+result.Purged+result.Tags+result.Aliases > 0 {
+			logger.Info("Synced from primary", "versions", result.Versions, "deleted", result.Deleted,
+				"restored", result.Restored, "purged", result.Purged, "tags", result.Tags, "aliases", result.Aliases,
+				"cursor", result.Cursor)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkLinks checks the target of every link at startup and then every interval until
+// stop is closed
+func checkLinks(logger *slog.Logger, deadLinks *service.DeadLinkService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := deadLinks.CheckAll(context.Background())
+		if err != nil {
+			logger.Error("Failed to check links", "err", err)
+		} else {
+			logger.Info("Checked links", "checked", run.Checked, "broken", run.Broken, "newly_broken", run.NewlyBroken,
+				"emailed", run.Emailed, "emails_failed", run.EmailsFailed)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifierBuffer is how many link changes may wait to be announced before more are dropped
+const notifierBuffer = 1000
+
+// loggedSender logs the messages a channel webhook rejects, which the notifier only counts
+type loggedSender struct {
+	service.NotificationSender
+	logger *slog.Logger
+}
+
+func (s loggedSender) Send(ctx context.Context, events []domain.LinkEvent) error {
+	err := s.NotificationSender.Send(ctx, events)
+	if err != nil {
+		s.logger.Warn("Failed to announce link changes", "changes", len(events), "err", err)
+	}
+	return err
+}
+
+// warningChecker logs the links the checker finds broken but lets them be saved, for
+// LINK_CHECK=warn
+type warningChecker struct {
+	service.LinkChecker
+	logger *slog.Logger
+}
+
+func (c warningChecker) Check(ctx context.Context, link string) error {
+	if err := c.LinkChecker.Check(ctx, link); err != nil {
+		c.logger.Warn("Saved a link that looks broken", "link", link, "err", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package golinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golinks/internal/database"
+	"golinks/internal/repository"
+)
+
+// migrateLegacy copies the links and queries of the legacy Python implementation's
+// SQLite database at path into the store, bringing its schema up to date first
+func migrateLegacy(ctx context.Context, store *repository.Store, path string, out io.Writer) error {
+	if store.Legacy == nil {
+		return errors.New("this storage driver can't copy legacy databases")
+	}
+	// Opening a missing file would create an empty database instead
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open legacy database: %w", err)
+	}
+	legacy, err := database.NewSQLiteDB(path, database.Pool{})
+	if err != nil {
+		return fmt.Errorf("failed to open legacy database: %w", err)
+	}
+	defer legacy.Close()
+
+	applied, err := store.Migrations.Up(ctx)
+	for _, migration := range applied {
+		fmt.Fprintf(out, "applied %d %s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	migration, err := store.Legacy.Import(ctx, legacy)
+	if err != nil {
+		return err
+	}
+	// With unique words, each word's copied versions become its history
+	if err := store.Shortcuts.ApplyWordMode(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "copied %d links and %d queries\n", migration.Links, migration.Queries)
+	if migration.SkippedQueries > 0 {
+		fmt.Fprintf(out, "skipped %d queries of links that no longer exist\n", migration.SkippedQueries)
+	}
+	return nil
+}
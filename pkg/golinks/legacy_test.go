@@ -0,0 +1,84 @@
+package golinks
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golinks/internal/database"
+	"golinks/internal/repository"
+)
+
+func TestMigrateLegacy(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, "legacy.db")
+	legacy, err := database.NewSQLiteDB(legacyPath, database.Pool{})
+	if err != nil {
+		t.Fatalf("Failed to create legacy database: %v", err)
+	}
+	for _, statement := range []string{
+		`CREATE TABLE linktable (id INTEGER PRIMARY KEY, word TEXT, link TEXT, user TEXT)`,
+		`CREATE TABLE queries (query_id INTEGER PRIMARY KEY, word_id INTEGER)`,
+		`INSERT INTO linktable VALUES (1, 'docs', 'https://docs.example.com', 'alice')`,
+		`INSERT INTO linktable VALUES (2, 'docs', 'https://docs.example.com/v2', 'alice')`,
+		`INSERT INTO queries VALUES (1, 1), (2, 2), (3, 5)`,
+	} {
+		if _, err := legacy.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up legacy database: %v", err)
+		}
+	}
+	legacy.Close()
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "copies links and queries", args: []string{"legacy", legacyPath}, want: []string{
+			"applied 1 links, queries and tags", "copied 2 links and 2 queries", "skipped 1 queries",
+		}},
+		{name: "refuses to copy twice", args: []string{"legacy", legacyPath}, wantErr: "already holds 1 links"},
+		{name: "missing file", args: []string{"legacy", filepath.Join(dir, "missing.db")}, wantErr: "no such file"},
+		{name: "no path", args: []string{"legacy"}, wantErr: "usage"},
+	}
+
+	// With unique words the older version of docs becomes its history
+	store, err := repository.Open("sqlite", repository.Options{DSN: filepath.Join(dir, "golinks.db"), UniqueWords: true})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runMigrate(context.Background(), store, tt.args, &out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("runMigrate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runMigrate() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("runMigrate() output = %q, want %q", out.String(), want)
+				}
+			}
+		})
+	}
+
+	history, err := store.Shortcuts.GetHistory(context.Background(), "docs")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("GetHistory(docs) = %+v, %v, want both versions", history, err)
+	}
+	docs, err := store.Shortcuts.GetByWord(context.Background(), "docs")
+	if err != nil || docs.Link != "https://docs.example.com/v2" {
+		t.Errorf("GetByWord(docs) = %+v, %v, want the newest version", docs, err)
+	}
+}
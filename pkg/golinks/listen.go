@@ -0,0 +1,85 @@
+package golinks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// unixPrefix starts a LISTEN_ADDR naming a unix domain socket
+	unixPrefix = "unix:"
+
+	// systemdAddr is the LISTEN_ADDR that takes the socket passed by systemd socket activation
+	systemdAddr = "systemd"
+
+	// systemdFirstFD is the first file descriptor systemd passes sockets on
+	systemdFirstFD = 3
+)
+
+// listen opens the listener the server accepts connections on: the TCP address addr, a
+// unix domain socket for unix:/path, the socket systemd passes for systemd, or port on
+// every interface when addr is empty
+func listen(addr string, port int) (net.Listener, error) {
+	switch {
+	case addr == "":
+		return net.Listen("tcp", fmt.Sprintf(":%d", port))
+	case addr == systemdAddr:
+		return systemdListener()
+	case strings.HasPrefix(addr, unixPrefix):
+		return unixListener(strings.TrimPrefix(addr, unixPrefix))
+	default:
+		return net.Listen("tcp", addr)
+	}
+}
+
+// unixListener listens on the socket at path, replacing a socket left behind by a server
+// that did not shut down cleanly
+func unixListener(path string) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("LISTEN_ADDR unix: needs a socket path")
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		// A socket that still answers belongs to a running server
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another server", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// systemdListener takes the one socket passed by systemd socket activation, following
+// the sd_listen_fds protocol
+func systemdListener() (net.Listener, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, errors.New("LISTEN_ADDR=systemd needs a socket passed by systemd, but LISTEN_PID is not this process")
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds != 1 {
+		return nil, fmt.Errorf("LISTEN_ADDR=systemd needs exactly one socket from systemd, got LISTEN_FDS=%q", os.Getenv("LISTEN_FDS"))
+	}
+
+	// Child processes must not take the socket again
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(systemdFirstFD, "systemd")
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use the socket from systemd: %w", err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,86 @@
+package golinks
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListen(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A socket left behind by a server that did not shut down cleanly
+	stale := filepath.Join(dir, "stale.sock")
+	staleListener, err := net.ListenUnix("unix", &net.UnixAddr{Name: stale, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleListener.SetUnlinkOnClose(false)
+	staleListener.Close()
+
+	// A socket another server is still accepting on
+	inUse := filepath.Join(dir, "in-use.sock")
+	inUseListener, err := net.Listen("unix", inUse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inUseListener.Close()
+
+	tests := []struct {
+		name     string
+		addr     string
+		wantNet  string
+		wantAddr string
+		wantErr  string
+	}{
+		{name: "port", addr: "", wantNet: "tcp"},
+		{name: "TCP address", addr: "127.0.0.1:0", wantNet: "tcp", wantAddr: "127.0.0.1:"},
+		{name: "unix socket", addr: "unix:" + filepath.Join(dir, "golinks.sock"), wantNet: "unix", wantAddr: filepath.Join(dir, "golinks.sock")},
+		{name: "stale unix socket", addr: "unix:" + stale, wantNet: "unix", wantAddr: stale},
+		{name: "unix socket in use", addr: "unix:" + inUse, wantErr: "in use"},
+		{name: "not a socket", addr: "unix:" + file, wantErr: "is not a socket"},
+		{name: "no socket path", addr: "unix:", wantErr: "needs a socket path"},
+		{name: "systemd without a socket", addr: "systemd", wantErr: "LISTEN_PID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := listen(tt.addr, 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("listen(%q) error = %v, want %q", tt.addr, err, tt.wantErr)
+				}
+				if listener != nil {
+					listener.Close()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("listen(%q) error = %v", tt.addr, err)
+			}
+			defer listener.Close()
+
+			addr := listener.Addr()
+			if addr.Network() != tt.wantNet || !strings.HasPrefix(addr.String(), tt.wantAddr) {
+				t.Errorf("listen(%q) on %s %s, want %s %s", tt.addr, addr.Network(), addr, tt.wantNet, tt.wantAddr)
+			}
+		})
+	}
+
+	// The socket is removed when the server closes it
+	socket := filepath.Join(dir, "closed.sock")
+	listener, err := listen("unix:"+socket, 0)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+	listener.Close()
+	if _, err := os.Lstat(socket); !os.IsNotExist(err) {
+		t.Errorf("socket after Close: %v, want it removed", err)
+	}
+}
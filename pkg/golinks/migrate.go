@@ -0,0 +1,122 @@
+package golinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"text/tabwriter"
+
+	"golinks/internal/repository"
+)
+
+const migrateUsage = "usage: golinks migrate up | down [steps] | status | legacy <path>"
+
+// runMigrate runs the migrate command: up applies every pending migration, down reverts
+// the newest one (or the given number of them), status lists them all and legacy copies
+// a legacy Python golinks database in
+func runMigrate(ctx context.Context, store *repository.Store, args []string, out io.Writer) error {
+	if store.Migrations == nil {
+		return errors.New("this storage driver does not support migrations")
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		if len(args) > 1 {
+			return errors.New(migrateUsage)
+		}
+		applied, err := store.Migrations.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("steps must be a positive number, not %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := store.Migrations.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(out, "reverted %d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(out, "no applied migrations")
+		}
+
+	case "status":
+		if len(args) > 1 {
+			return errors.New(migrateUsage)
+		}
+		statuses, err := store.Migrations.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied {
+				applied = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+		}
+		return w.Flush()
+
+	case "legacy":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		return migrateLegacy(ctx, store, args[1], out)
+
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
+
+// migrateOnStart brings the schema up to date before serving, or with autoMigrate off
+// refuses to serve an out-of-date schema
+func migrateOnStart(ctx context.Context, logger *slog.Logger, store *repository.Store, autoMigrate bool) error {
+	if store.Migrations == nil {
+		return nil
+	}
+
+	if !autoMigrate {
+		pending, err := store.Migrations.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d schema migrations are pending; run `golinks migrate up` or set AUTO_MIGRATE=true", len(pending))
+		}
+		return nil
+	}
+
+	applied, err := store.Migrations.Up(ctx)
+	for _, migration := range applied {
+		logger.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return err
+}
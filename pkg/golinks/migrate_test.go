@@ -0,0 +1,98 @@
+package golinks
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golinks/internal/database"
+	"golinks/internal/repository"
+)
+
+func TestRunMigrate(t *testing.T) {
+	store, err := repository.Open("sqlite", repository.Options{DSN: filepath.Join(t.TempDir(), "golinks.db")})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "status before migrating", args: []string{"status"}, want: []string{"VERSION NAME APPLIED", "1 links, queries and tags pending"}},
+		{name: "up", args: []string{"up"}, want: []string{"applied 1 links, queries and tags", "applied 5 namespaces"}},
+		{name: "up again", args: []string{"up"}, want: []string{"no pending migrations"}},
+		{name: "revert to namespaces", args: []string{"down", strconv.Itoa(len(database.SQLiteMigrations) - 5)}},
+		{name: "down one", args: []string{"down"}, want: []string{"reverted 5 namespaces"}},
+		{name: "down two", args: []string{"down", "2"}, want: []string{"reverted 4 user roles", "reverted 3 sessions"}},
+		{name: "status after reverting", args: []string{"status"}, want: []string{"2 api keys 20", "3 sessions pending"}},
+		{name: "bad steps", args: []string{"down", "zero"}, wantErr: "positive number"},
+		{name: "no command", args: nil, wantErr: "usage"},
+		{name: "unknown command", args: []string{"sideways"}, wantErr: "usage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runMigrate(context.Background(), store, tt.args, &out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("runMigrate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runMigrate() error = %v", err)
+			}
+			// Collapse the status table's padding
+			got := strings.Join(strings.Fields(out.String()), " ")
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("runMigrate() output = %q, want %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateOnStart(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoMigrate bool
+		wantErr     bool
+	}{
+		{name: "applies pending migrations", autoMigrate: true},
+		{name: "refuses pending migrations when disabled", autoMigrate: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := repository.Open("memory", repository.Options{})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer store.Close()
+
+			err = migrateOnStart(context.Background(), slog.Default(), store, tt.autoMigrate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("migrateOnStart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if err := migrateOnStart(context.Background(), slog.Default(), store, false); err != nil {
+					t.Errorf("migrateOnStart() on an up-to-date schema error = %v", err)
+				}
+			}
+		})
+	}
+
+	if err := migrateOnStart(context.Background(), slog.Default(), &repository.Store{}, false); err != nil {
+		t.Errorf("migrateOnStart() without migrations error = %v", err)
+	}
+}
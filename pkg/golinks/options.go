@@ -0,0 +1,100 @@
+package golinks
+
+import (
+	"log/slog"
+	"net/http"
+
+	"golinks/internal/config"
+)
+
+// Option configures a Server. Options override the settings read from the environment,
+// the .env file and the config file, which still supply everything they leave unset.
+type Option func(*options)
+
+type options struct {
+	args     []string
+	logger   *slog.Logger
+	identify func(r *http.Request) (string, bool)
+	settings []func(cfg *config.Config)
+}
+
+// WithArgs reads settings from command-line flags such as --port=9000 as the golinks
+// binary does. Whatever follows the flags is the command the server runs instead of
+// serving; see Command.
+func WithArgs(args []string) Option {
+	return func(o *options) {
+		o.args = args
+	}
+}
+
+// WithStorage keeps golinks' data with the storage driver named driver (sqlite, postgres
+// or memory) at dsn: the database file for sqlite, or the connection URL for postgres.
+// An empty dsn keeps DATABASE_PATH or DATABASE_URL.
+func WithStorage(driver, dsn string) Option {
+	return setting(func(cfg *config.Config) {
+		cfg.StorageDriver = driver
+		switch {
+		case dsn == "":
+		case driver == "sqlite":
+			cfg.DatabasePath, cfg.DatabaseURL = dsn, ""
+		default:
+			cfg.DatabaseURL = dsn
+		}
+	})
+}
+
+// WithLogger logs through logger in place of one built from LOG_LEVEL and LOG_FORMAT.
+// The log level can then no longer be changed through the admin API.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithAuthenticator leaves signing in to the application embedding golinks: identify
+// returns the user a request was signed in as, and false if it wasn't. Requests that
+// are neither signed in nor carry an API key are turned away with a 401. Google and
+// LDAP sign-in still apply to requests identify doesn't recognize.
+func WithAuthenticator(identify func(r *http.Request) (user string, ok bool)) Option {
+	return func(o *options) {
+		o.identify = identify
+	}
+}
+
+// WithAdminUsers makes users admins, in place of ADMIN_USERS
+func WithAdminUsers(users ...string) Option {
+	return setting(func(cfg *config.Config) {
+		cfg.AdminUsers = users
+	})
+}
+
+// WithBaseURL sets the URL golinks is reached at, which links and redirects are built
+// from, in place of BASE_URL
+func WithBaseURL(baseURL string) Option {
+	return setting(func(cfg *config.Config) {
+		cfg.BaseURL = baseURL
+	})
+}
+
+// WithListenAddr sets where ListenAndServe accepts connections, in place of LISTEN_ADDR
+// and PORT: a TCP address, unix:/path/to/socket, or systemd
+func WithListenAddr(addr string) Option {
+	return setting(func(cfg *config.Config) {
+		cfg.ListenAddr = addr
+	})
+}
+
+// WithWebDir serves the web interface from the templates and static files in dir, in
+// place of WEB_DIR
+func WithWebDir(dir string) Option {
+	return setting(func(cfg *config.Config) {
+		cfg.WebDir = dir
+	})
+}
+
+// setting is an Option changing the loaded configuration
+func setting(apply func(cfg *config.Config)) Option {
+	return func(o *options) {
+		o.settings = append(o.settings, apply)
+	}
+}
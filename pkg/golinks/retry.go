@@ -0,0 +1,23 @@
+package golinks
+
+import (
+	"log/slog"
+	"time"
+)
+
+// maxRetryBackoff caps the wait between startup retries
+const maxRetryBackoff = 30 * time.Second
+
+// withRetry calls fn until it succeeds or has been retried retries times, waiting
+// backoff before the first retry and twice as long before each one after, up to
+// maxRetryBackoff. It returns the last error.
+func withRetry(logger *slog.Logger, retries int, backoff time.Duration, step string, fn func() error) error {
+	err := fn()
+	for attempt := 1; err != nil && attempt <= retries; attempt++ {
+		logger.Warn("Database not ready, retrying", "step", step, "attempt", attempt, "of", retries, "wait", backoff, "err", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxRetryBackoff)
+		err = fn()
+	}
+	return err
+}
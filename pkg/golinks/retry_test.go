@@ -0,0 +1,42 @@
+package golinks
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	notReady := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		retries   int
+		failures  int
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "first try", retries: 3, failures: 0, wantCalls: 1},
+		{name: "ready on a retry", retries: 3, failures: 2, wantCalls: 3},
+		{name: "ready on the last retry", retries: 3, failures: 3, wantCalls: 4},
+		{name: "never ready", retries: 3, failures: 10, wantCalls: 4, wantErr: notReady},
+		{name: "no retries", retries: 0, failures: 1, wantCalls: 1, wantErr: notReady},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := withRetry(slog.Default(), tt.retries, time.Millisecond, "connect", func() error {
+				calls++
+				if calls <= tt.failures {
+					return notReady
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) || calls != tt.wantCalls {
+				t.Errorf("withRetry() = %v after %d calls, want %v after %d", err, calls, tt.wantErr, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,546 @@
+// Package golinks runs a golinks server: the web interface, the JSON, GraphQL and gRPC
+// APIs, and the background jobs behind them. The golinks binary is a thin wrapper around
+// it, and a larger program can embed the whole application, either mounting Handler in
+// its own server or letting ListenAndServe run it.
+package golinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golinks/internal/config"
+	"golinks/internal/database"
+	"golinks/internal/domain"
+	"golinks/internal/grpcapi"
+	"golinks/internal/handlers"
+	"golinks/internal/logger"
+	"golinks/internal/repository"
+	"golinks/internal/service"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout bounds how long ListenAndServe waits for requests in flight to finish
+const shutdownTimeout = 30 * time.Second
+
+// Server is a golinks instance: its storage, services and HTTP handler. Close releases
+// it.
+type Server struct {
+	cfg      *config.Config
+	command  []string
+	logger   *slog.Logger
+	logLevel *slog.LevelVar
+
+	store     *repository.Store
+	redis     *repository.RedisCache
+	cache     *service.ShortcutCache
+	links     *service.LinkService
+	backups   *service.BackupService
+	queryLog  *service.QueryLog
+	notifier  *service.Notifier
+	snapshots *service.SnapshotService
+	sync      *service.SyncService
+	deadLinks *service.DeadLinkService
+	handler   *handlers.Handler
+	grpcAPI   *grpcapi.Server
+	router    *mux.Router
+	identify  handlers.IdentityFunc
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopJobs  chan struct{}
+	listenCtx context.Context
+	stopCache context.CancelFunc
+}
+
+// New opens storage, applies pending migrations and sets up a server from the
+// environment, the .env file and the config file, overridden by opts. It starts
+// nothing in the background until Start or ListenAndServe.
+func New(opts ...Option) (*Server, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg, args, err := config.Load(o.args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	for _, apply := range o.settings {
+		apply(cfg)
+	}
+
+	s := &Server{cfg: cfg, identify: o.identify, stopJobs: make(chan struct{})}
+	s.listenCtx, s.stopCache = context.WithCancel(context.Background())
+	// `golinks serve` is the same as no command at all
+	if len(args) > 0 && (args[0] != "serve" || len(args) > 1) {
+		s.command = args
+	}
+
+	s.logger = o.logger
+	if s.logger == nil {
+		s.logLevel = new(slog.LevelVar)
+		s.logger, err = logger.New(os.Stderr, logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat, LevelVar: s.logLevel})
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure logging: %w", err)
+		}
+	}
+
+	if err := s.open(); err != nil {
+		_ = s.Close(context.Background())
+		return nil, err
+	}
+	return s, nil
+}
+
+// open connects to storage and sets up the services and, unless the server runs a
+// command, the handler
+func (s *Server) open() error {
+	cfg := s.cfg
+
+	// Wait for a database that is still starting up
+	err := withRetry(s.logger, cfg.DBConnectRetries, cfg.DBConnectBackoff, "connect", func() (err error) {
+		s.store, err = repository.Open(cfg.StorageDriver, repository.Options{
+			DSN: cfg.StorageDSN(),
+			Pool: database.Pool{
+				MaxOpenConns:    cfg.DBMaxOpenConns,
+				MaxIdleConns:    cfg.DBMaxIdleConns,
+				ConnMaxLifetime: cfg.DBConnMaxLifetime,
+			},
+			UniqueWords: cfg.UniqueWords,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	// The migrate command manages the schema itself
+	if len(s.command) > 0 && s.command[0] == "migrate" {
+		return nil
+	}
+
+	err = withRetry(s.logger, cfg.DBConnectRetries, cfg.DBConnectBackoff, "migrate", func() error {
+		return migrateOnStart(context.Background(), s.logger, s.store, cfg.AutoMigrate)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	if err := s.store.Shortcuts.ApplyWordMode(context.Background()); err != nil {
+		return fmt.Errorf("failed to apply UNIQUE_WORDS: %w", err)
+	}
+
+	defaultRole := domain.Role(cfg.DefaultRole)
+	if !defaultRole.Valid() {
+		return fmt.Errorf("DEFAULT_ROLE must be viewer, editor or admin, not %q", cfg.DefaultRole)
+	}
+	switch cfg.RedirectStatus {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return fmt.Errorf("REDIRECT_STATUS must be 301, 302, 307 or 308, not %d", cfg.RedirectStatus)
+	}
+	if cfg.FallbackSearchURL != "" && (!strings.Contains(cfg.FallbackSearchURL, "{*}") || !service.IsWebURL(cfg.FallbackSearchURL)) {
+		return fmt.Errorf("FALLBACK_SEARCH_URL must be a web URL with {*} where the query goes, not %q", cfg.FallbackSearchURL)
+	}
+	domains, err := service.NewDomainPolicy(domain.DomainLists{Denied: cfg.DeniedLinkDomains, Allowed: cfg.AllowedLinkDomains})
+	if err != nil {
+		return fmt.Errorf("DENIED_LINK_DOMAINS and ALLOWED_LINK_DOMAINS must list domains: %w", err)
+	}
+	wordRules, err := service.NewWordRules(cfg.WordMinLength, cfg.WordMaxLength, cfg.WordSeparators, cfg.WordPattern, cfg.WordPatternHint)
+	if err != nil {
+		return fmt.Errorf("WORD_MIN_LENGTH, WORD_MAX_LENGTH and WORD_PATTERN must describe valid keywords: %w", err)
+	}
+	var linkChecker service.LinkChecker
+	switch cfg.LinkCheck {
+	case "off":
+	case "warn":
+		linkChecker = warningChecker{repository.NewLinkChecker(cfg.LinkCheckTimeout), s.logger}
+	case "reject":
+		linkChecker = repository.NewLinkChecker(cfg.LinkCheckTimeout)
+	default:
+		return fmt.Errorf("LINK_CHECK must be off, warn or reject, not %q", cfg.LinkCheck)
+	}
+	if (linkChecker != nil || cfg.DeadLinkCheckInterval > 0) && cfg.LinkCheckTimeout <= 0 {
+		return fmt.Errorf("LINK_CHECK_TIMEOUT must be positive, not %s", cfg.LinkCheckTimeout)
+	}
+	if cfg.DeadLinkCheckInterval < 0 {
+		return fmt.Errorf("DEAD_LINK_CHECK_INTERVAL must not be negative, not %s", cfg.DeadLinkCheckInterval)
+	}
+	roleService := service.NewRoleService(s.store.Roles, cfg.AdminUsers, defaultRole)
+	namespaceService := service.NewNamespaceService(s.store.Namespaces, roleService)
+	var shortcuts service.ShortcutRepository = s.store.Shortcuts
+	var snapshots service.Snapshotter = s.store.Snapshots
+	if cfg.LinkCacheSize > 0 || cfg.RedisURL != "" {
+		s.cache = service.NewShortcutCache(s.store.Shortcuts, cfg.LinkCacheSize, cfg.LinkCacheTTL)
+		shortcuts = s.cache
+		snapshots = s.cache.FlushOnRestore(snapshots)
+
+		if cfg.RedisURL != "" {
+			s.redis, err = repository.NewRedisCache(cfg.RedisURL)
+			if err != nil {
+				return fmt.Errorf("failed to configure Redis: %w", err)
+			}
+			if err := s.redis.Ping(context.Background()); err != nil {
+				s.logger.Warn("Redis is unavailable, caching on this server alone until it is back", "err", err)
+			}
+			s.cache.Share(s.redis)
+		}
+	}
+	var keywords *service.KeywordCache
+	if cfg.KeywordCacheTTL > 0 {
+		keywords = service.NewKeywordCache(cfg.KeywordCacheTTL)
+	}
+	if cfg.QueryLogBuffer > 0 {
+		s.queryLog = service.NewQueryLog(s.store.Queries, cfg.QueryLogBuffer)
+	}
+	if cfg.NotifyWebhookURL != "" {
+		webhook, err := repository.NewWebhookClient(cfg.NotifyWebhookURL, cfg.NotifyWebhookFormat, cfg.BaseURL)
+		if err != nil {
+			return fmt.Errorf("failed to configure change notifications: %w", err)
+		}
+		s.notifier = service.NewNotifier(loggedSender{webhook, s.logger}, notifierBuffer)
+	}
+	s.links = service.NewLinkService(
+		shortcuts,
+		s.store.Queries,
+		service.WithIcons(cfg.LinkIcons),
+		service.WithAllowedSchemes(cfg.AllowedSchemes),
+		service.WithRoles(roleService),
+		service.WithNamespaces(namespaceService),
+		service.WithQueryRetention(cfg.QueryRetentionDays),
+		service.WithKeywordCache(keywords),
+		service.WithQueryLog(s.queryLog),
+		service.WithTags(s.store.Tags),
+		service.WithNotifier(s.notifier),
+		service.WithDomainPolicy(domains),
+		service.WithLinkChecker(linkChecker),
+		service.WithWordRules(wordRules),
+		service.WithAliases(s.store.Aliases),
+	)
+	if cfg.DeadLinkCheckInterval > 0 {
+		if s.store.LinkHealth == nil {
+			return fmt.Errorf("DEAD_LINK_CHECK_INTERVAL is set but the %s storage driver can't record link health", cfg.StorageDriver)
+		}
+		var mailer service.BrokenLinkMailer
+		if cfg.SMTPAddr != "" {
+			if mailer, err = repository.NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword, cfg.BaseURL); err != nil {
+				return fmt.Errorf("failed to configure email: %w", err)
+			}
+		}
+		s.deadLinks = service.NewDeadLinkService(s.links, s.store.LinkHealth, repository.NewLinkChecker(cfg.LinkCheckTimeout), mailer)
+	}
+	tagService := service.NewTagService(s.store.Tags, s.links, service.WithTagKeywordCache(keywords))
+	apiKeyService := service.NewAPIKeyService(s.store.APIKeys, roleService)
+	var backupStorage service.BackupStorage
+	if cfg.BackupDir != "" {
+		backupStorage = repository.NewBackupDirectory(cfg.BackupDir)
+	}
+	s.backups = service.NewBackupService(snapshots, backupStorage)
+
+	// Commands run from the shell need neither the web interface nor the jobs
+	if s.command != nil {
+		return nil
+	}
+
+	if cfg.SnapshotURL != "" {
+		snapshotStorage, err := repository.NewObjectStore(cfg.SnapshotURL, repository.ObjectStoreOptions{
+			Endpoint:        cfg.SnapshotEndpoint,
+			Region:          cfg.SnapshotRegion,
+			AccessKeyID:     cfg.SnapshotAccessKeyID,
+			SecretAccessKey: cfg.SnapshotSecretAccessKey,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure snapshots: %w", err)
+		}
+		if cfg.SnapshotInterval <= 0 {
+			return fmt.Errorf("SNAPSHOT_INTERVAL must be positive, not %s", cfg.SnapshotInterval)
+		}
+		var database service.Snapshotter
+		if cfg.SnapshotDatabase {
+			if snapshots == nil {
+				return fmt.Errorf("SNAPSHOT_DATABASE needs a storage driver that can be backed up, like sqlite, not %s", cfg.StorageDriver)
+			}
+			database = snapshots
+		}
+		s.snapshots = service.NewSnapshotService(s.links, database, snapshotStorage, cfg.SnapshotInterval, cfg.SnapshotKeep)
+	}
+
+	if cfg.SyncPrimaryURL != "" {
+		primary, err := repository.NewSyncClient(cfg.SyncPrimaryURL, cfg.SyncAPIKey)
+		if err != nil {
+			return fmt.Errorf("failed to configure syncing: %w", err)
+		}
+		if cfg.SyncInterval <= 0 {
+			return fmt.Errorf("SYNC_INTERVAL must be positive, not %s", cfg.SyncInterval)
+		}
+		s.sync = service.NewSyncService(primary, cfg.SyncPrimaryURL, s.links, s.store.SyncState)
+	}
+
+	// Loading the templates panics rather than failing when there are none
+	if matches, _ := filepath.Glob(filepath.Join(cfg.WebDir, "templates", "*.html")); len(matches) == 0 {
+		return fmt.Errorf("no templates found in %s; set WEB_DIR to the directory holding the web interface", filepath.Join(cfg.WebDir, "templates"))
+	}
+	favoriteService := service.NewFavoriteService(s.store.Favorites, s.store.Shortcuts)
+	adminService := service.NewAdminService(s.store.Shortcuts, roleService, s.store.Stats)
+	s.handler = handlers.NewHandler(
+		s.links, tagService, apiKeyService, roleService, namespaceService, s.backups, favoriteService, adminService,
+		s.store.Sessions, cfg, s.logger,
+	)
+	s.handler.AddReadinessCheck("database", s.store.Ping)
+	s.handler.SetLogLevel(s.logLevel)
+	s.handler.SetDomainPolicy(domains)
+	s.handler.SetWordRules(wordRules)
+	if s.deadLinks != nil {
+		s.handler.SetBrokenLinkReporter(s.deadLinks)
+	}
+	if s.identify != nil {
+		s.handler.SetIdentityFunc(s.identify)
+	}
+	// gRPC callers can only authenticate with API keys, which they need whenever the web
+	// interface needs signing in
+	requireLogin := cfg.GoogleClientID != "" || cfg.LDAPURL != "" || s.identify != nil
+	s.grpcAPI = grpcapi.NewServer(s.links, apiKeyService, roleService, requireLogin, s.logger)
+
+	s.router = mux.NewRouter()
+	s.handler.RegisterRoutes(s.router)
+	return nil
+}
+
+// Logger returns the logger the server logs to: the one given to WithLogger, or the one
+// built from LOG_LEVEL and LOG_FORMAT
+func (s *Server) Logger() *slog.Logger {
+	return s.logger
+}
+
+// Command returns the command given after the flags of WithArgs, such as
+// ["export", "links.json"], or nil when the server is to serve
+func (s *Server) Command() []string {
+	return s.command
+}
+
+// RunCommand runs the server's command, reading files named - from stdin and writing
+// what it did to out. The commands are export, import, backup, prune, user add and
+// migrate.
+func (s *Server) RunCommand(ctx context.Context, stdin io.Reader, out io.Writer) error {
+	if len(s.command) == 0 {
+		return errors.New("no command to run")
+	}
+	if s.command[0] == "migrate" {
+		return runMigrate(ctx, s.store, s.command[1:], out)
+	}
+
+	// Imports act as the first configured admin, who may set any owner
+	importUser := "DefaultUser"
+	if len(s.cfg.AdminUsers) > 0 {
+		importUser = s.cfg.AdminUsers[0]
+	}
+	return runAdmin(ctx, adminServices{
+		links:              s.links,
+		backups:            s.backups,
+		roles:              s.store.Roles,
+		sessions:           s.store.Sessions,
+		queryRetentionDays: s.cfg.QueryRetentionDays,
+		importUser:         importUser,
+	}, s.command, stdin, out)
+}
+
+// Handler returns the handler serving golinks, for mounting in another server. golinks
+// expects to be served at the root of BaseURL. Start runs the jobs that ListenAndServe
+// would otherwise start.
+func (s *Server) Handler() http.Handler {
+	if s.router == nil {
+		return http.NotFoundHandler()
+	}
+	return s.router
+}
+
+// Start runs the background jobs: listening for cache invalidations from other servers,
+// rolling up old queries, saving snapshots and pulling changes from the primary, as
+// configured. Close stops them.
+func (s *Server) Start() {
+	s.startOnce.Do(func() {
+		if s.redis != nil {
+			go func() {
+				if err := s.cache.Listen(s.listenCtx); err != nil {
+					s.logger.Warn("Stopped listening for cache invalidations", "err", err)
+				}
+			}()
+		}
+		if s.command != nil {
+			return
+		}
+		if s.cfg.QueryRetentionDays > 0 {
+			go pruneQueries(s.logger, s.links, s.stopJobs)
+		}
+		if s.snapshots != nil {
+			go takeSnapshots(s.logger, s.snapshots, s.stopJobs)
+		}
+		if s.sync != nil {
+			go syncFromPrimary(s.logger, s.sync, s.cfg.SyncInterval, s.stopJobs)
+		}
+		if s.deadLinks != nil {
+			go checkLinks(s.logger, s.deadLinks, s.cfg.DeadLinkCheckInterval, s.stopJobs)
+		}
+	})
+}
+
+// ListenAndServe starts the jobs and serves golinks on the configured address, over
+// HTTPS if a certificate or ACME hosts are configured, and the gRPC API on GRPC_PORT if
+// set, until ctx is done. It then waits for requests in flight to finish before
+// returning. Close releases the server afterwards.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if s.router == nil {
+		return errors.New("a server running a command can't serve")
+	}
+	cfg := s.cfg
+
+	server := &http.Server{
+		Handler:      s.router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+	server.RegisterOnShutdown(s.handler.CloseStreams)
+	challengeServer, err := configureTLS(server, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+
+	listener, err := listen(cfg.ListenAddr, cfg.Port)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	var grpcListener net.Listener
+	if cfg.GRPCPort != 0 {
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("failed to listen on gRPC port %d: %w", cfg.GRPCPort, err)
+		}
+	}
+
+	s.Start()
+
+	// The first server to fail stops the others
+	failed := make(chan error, 3)
+	go func() {
+		var err error
+		if server.TLSConfig == nil {
+			s.logger.Info("Starting server", "addr", listener.Addr().String())
+			err = server.Serve(listener)
+		} else {
+			s.logger.Info("Starting HTTPS server", "addr", listener.Addr().String())
+			err = server.ServeTLS(listener, "", "")
+		}
+		if err != nil && err != http.ErrServerClosed {
+			failed <- fmt.Errorf("server failed: %w", err)
+		}
+	}()
+
+	// Answer ACME HTTP challenges and redirect plain HTTP to HTTPS if enabled
+	if challengeServer != nil {
+		go func() {
+			s.logger.Info("Starting ACME HTTP challenge server", "port", cfg.ACMEHTTPPort)
+			if err := challengeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				failed <- fmt.Errorf("ACME HTTP challenge server failed: %w", err)
+			}
+		}()
+	}
+
+	var grpcServer *grpc.Server
+	if grpcListener != nil {
+		grpcServer = grpc.NewServer(grpc.UnaryInterceptor(s.grpcAPI.UnaryInterceptor()))
+		s.grpcAPI.Register(grpcServer)
+
+		go func() {
+			s.logger.Info("Starting gRPC server", "port", cfg.GRPCPort)
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				failed <- fmt.Errorf("gRPC server failed: %w", err)
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+	case err = <-failed:
+	}
+	s.logger.Info("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
+	if challengeServer != nil {
+		if err := challengeServer.Shutdown(shutdownCtx); err != nil {
+			s.logger.Warn("ACME HTTP challenge server forced to shutdown", "err", err)
+		}
+	}
+
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil && err == nil {
+		err = fmt.Errorf("server forced to shutdown: %w", shutdownErr)
+	}
+	return err
+}
+
+// Close stops the background jobs and long-lived streams, writes the queries and
+// announces the link changes still queued, giving up when ctx is done, and closes
+// storage
+func (s *Server) Close(ctx context.Context) error {
+	var errs []error
+	s.stopOnce.Do(func() {
+		close(s.stopJobs)
+		s.stopCache()
+		if s.handler != nil {
+			s.handler.CloseStreams()
+		}
+
+		// Write the queries still queued before the database is closed
+		if s.queryLog != nil {
+			if err := s.queryLog.Close(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to write the query log: %w", err))
+			}
+			if stats := s.queryLog.Stats(); stats.Dropped > 0 || stats.Failed > 0 {
+				s.logger.Warn("Query log lost queries", "dropped", stats.Dropped, "failed", stats.Failed,
+					"total", stats.Written+stats.Dropped+stats.Failed)
+			}
+		}
+
+		// Announce the changes still queued
+		if s.notifier != nil {
+			if err := s.notifier.Close(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to announce link changes: %w", err))
+			}
+			if stats := s.notifier.Stats(); stats.Dropped > 0 || stats.Failed > 0 {
+				s.logger.Warn("Change notifications were lost", "dropped", stats.Dropped, "failed", stats.Failed,
+					"total", stats.Sent+stats.Dropped+stats.Failed)
+			}
+		}
+
+		if s.redis != nil {
+			if err := s.redis.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close Redis: %w", err))
+			}
+		}
+		if s.store != nil {
+			if err := s.store.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close storage: %w", err))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
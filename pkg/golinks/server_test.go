@@ -0,0 +1,193 @@
+package golinks
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// webDir is the web interface of the repository the tests run in
+const webDir = "../../web"
+
+func newTestServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	server, err := New(append([]Option{WithStorage("memory", ""), WithWebDir(webDir)}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { server.Close(context.Background()) })
+	return server
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		opts    []Option
+		wantErr string
+	}{
+		{name: "memory storage", opts: []Option{WithStorage("memory", ""), WithWebDir(webDir)}},
+		{name: "unknown storage driver", opts: []Option{WithStorage("floppy", ""), WithWebDir(webDir)}, wantErr: "unknown storage driver"},
+		{name: "missing web interface", opts: []Option{WithStorage("memory", ""), WithWebDir(t.TempDir())}, wantErr: "WEB_DIR"},
+		{name: "invalid default role", env: map[string]string{"DEFAULT_ROLE": "owner"}, opts: []Option{WithStorage("memory", ""), WithWebDir(webDir)}, wantErr: "DEFAULT_ROLE"},
+		{name: "invalid redirect status", env: map[string]string{"REDIRECT_STATUS": "303"}, opts: []Option{WithStorage("memory", ""), WithWebDir(webDir)}, wantErr: "REDIRECT_STATUS"},
+		{name: "fallback search without query", env: map[string]string{"FALLBACK_SEARCH_URL": "https://wiki.example.com/search"}, opts: []Option{WithStorage("memory", ""), WithWebDir(webDir)}, wantErr: "FALLBACK_SEARCH_URL"},
+		{name: "denied link domain that is a URL", env: map[string]string{"DENIED_LINK_DOMAINS": "https://bit.ly"}, opts: []Option{WithStorage("memory", ""), WithWebDir(webDir)}, wantErr: "DENIED_LINK_DOMAINS"},
+		{name: "unknown link check", env: map[string]string{"LINK_CHECK": "strict"}, opts: []Option{WithStorage("memory", ""), WithWebDir(webDir)}, wantErr: "LINK_CHECK"},
+		{name: "email without a port", env: map[string]string{"DEAD_LINK_CHECK_INTERVAL": "24h", "SMTP_ADDR": "mail.example.com", "SMTP_FROM": "golinks@example.com"}, opts: []Option{WithStorage("memory", ""), WithWebDir(webDir)}, wantErr: "email"},
+		{name: "invalid keyword pattern", env: map[string]string{"WORD_PATTERN": "[a-z"}, opts: []Option{WithStorage("memory", ""), WithWebDir(webDir)}, wantErr: "WORD_PATTERN"},
+		{name: "commands need no web interface", opts: []Option{WithStorage("memory", ""), WithWebDir(t.TempDir()), WithArgs([]string{"prune"})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			server, err := New(tt.opts...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := server.Close(context.Background()); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNew_Logger(t *testing.T) {
+	before := slog.Default()
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	server := newTestServer(t, WithStorage("sqlite", filepath.Join(t.TempDir(), "golinks.db")), WithLogger(logger))
+	if slog.Default() != before {
+		t.Error("New() replaced the default logger")
+	}
+	if server.Logger() != logger {
+		t.Error("Server.Logger() is not the logger given to WithLogger")
+	}
+	if !strings.Contains(logs.String(), "Applied migration") {
+		t.Errorf("New() logged %q, want the migrations it applied", logs.String())
+	}
+}
+
+func TestServer_Handler(t *testing.T) {
+	server := newTestServer(t, WithAuthenticator(func(r *http.Request) (string, bool) {
+		user := r.Header.Get("X-Portal-User")
+		return user, user != ""
+	}))
+	server.Start()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		user       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "probes are public", method: "GET", path: "/healthz", wantStatus: http.StatusOK},
+		{name: "pages need a user", method: "GET", path: "/homepage/", wantStatus: http.StatusUnauthorized},
+		{name: "create as the portal user", method: "POST", path: "/api/v1/links", body: `{"word":"docs","link":"https://docs.example.com"}`, user: "carol", wantStatus: http.StatusCreated},
+		{name: "owned by the portal user", method: "GET", path: "/api/me/links", user: "carol", wantStatus: http.StatusOK, wantBody: `"docs"`},
+		{name: "redirect", method: "GET", path: "/query/docs", user: "dave", wantStatus: http.StatusFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.user != "" {
+				req.Header.Set("X-Portal-User", tt.user)
+			}
+			w := httptest.NewRecorder()
+			server.Handler().ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d: %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s body = %s, want it to contain %s", tt.method, tt.path, w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_RunCommand(t *testing.T) {
+	server := newTestServer(t, WithArgs([]string{"user", "add", "-role", "admin", "alice"}))
+	if got := server.Command(); len(got) != 5 || got[0] != "user" {
+		t.Fatalf("Command() = %v, want the user add command", got)
+	}
+
+	var out bytes.Buffer
+	if err := server.RunCommand(context.Background(), strings.NewReader(""), &out); err != nil {
+		t.Fatalf("RunCommand() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "alice") {
+		t.Errorf("RunCommand() output = %q, want it to name alice", out.String())
+	}
+
+	serving := newTestServer(t, WithArgs([]string{"serve"}))
+	if got := serving.Command(); got != nil {
+		t.Errorf("Command() = %v for serve, want nil", got)
+	}
+	if err := serving.RunCommand(context.Background(), strings.NewReader(""), &out); err == nil {
+		t.Error("RunCommand() without a command succeeded")
+	}
+}
+
+func TestServer_ListenAndServe(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "golinks.sock")
+	server := newTestServer(t, WithListenAddr("unix:"+socket))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.ListenAndServe(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	var resp *http.Response
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if resp, err = client.Get("http://golinks/healthz"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("GET /healthz error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz status = %d, want 200", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("ListenAndServe() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListenAndServe() did not return after ctx was done")
+	}
+}
@@ -0,0 +1,61 @@
+package golinks
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golinks/internal/config"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// configureTLS sets server up to serve HTTPS when cfg has a certificate or ACME hosts,
+// leaving it on plain HTTP otherwise. In ACME mode it also returns the server answering
+// HTTP challenges, or nil if ACMEHTTPPort is zero.
+func configureTLS(server *http.Server, cfg *config.Config) (*http.Server, error) {
+	hasCert := cfg.TLSCertFile != "" || cfg.TLSKeyFile != ""
+	switch {
+	case hasCert && (cfg.TLSCertFile == "" || cfg.TLSKeyFile == ""):
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	case hasCert && len(cfg.ACMEHosts) > 0:
+		return nil, errors.New("set TLS_CERT_FILE and TLS_KEY_FILE or ACME_HOSTS, not both")
+	case hasCert:
+		// Load the pair now, so a bad certificate fails at startup rather than on the first request
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		return nil, nil
+	case len(cfg.ACMEHosts) > 0:
+		if cfg.ACMECacheDir == "" {
+			return nil, errors.New("ACME_CACHE_DIR must be set with ACME_HOSTS")
+		}
+	default:
+		return nil, nil
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.ACMEHosts...),
+		Cache:      autocert.DirCache(cfg.ACMECacheDir),
+		Email:      cfg.ACMEEmail,
+	}
+	server.TLSConfig = manager.TLSConfig()
+	server.TLSConfig.MinVersion = tls.VersionTLS12
+	if cfg.ACMEHTTPPort == 0 {
+		return nil, nil
+	}
+
+	// A nil fallback redirects everything but the challenges to HTTPS
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.ACMEHTTPPort),
+		Handler:      manager.HTTPHandler(nil),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}, nil
+}
@@ -0,0 +1,141 @@
+package golinks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golinks/internal/config"
+)
+
+// writeCertificate writes a self-signed certificate and its key to dir
+func writeCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go.example.com"},
+		DNSNames:     []string{"go.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestConfigureTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+	acmeCache := filepath.Join(dir, "acme")
+
+	tests := []struct {
+		name          string
+		cfg           config.Config
+		wantTLS       bool
+		wantChallenge string
+		wantErr       string
+	}{
+		{name: "plain HTTP", cfg: config.Config{ACMECacheDir: acmeCache, ACMEHTTPPort: 80}},
+		{name: "certificate", cfg: config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile}, wantTLS: true},
+		{name: "certificate without key", cfg: config.Config{TLSCertFile: certFile}, wantErr: "set together"},
+		{name: "key without certificate", cfg: config.Config{TLSKeyFile: keyFile}, wantErr: "set together"},
+		{name: "missing certificate", cfg: config.Config{TLSCertFile: filepath.Join(dir, "missing.pem"), TLSKeyFile: keyFile}, wantErr: "failed to load TLS certificate"},
+		{name: "key for the certificate", cfg: config.Config{TLSCertFile: keyFile, TLSKeyFile: certFile}, wantErr: "failed to load TLS certificate"},
+		{
+			name:    "certificate and ACME",
+			cfg:     config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile, ACMEHosts: []string{"go.example.com"}},
+			wantErr: "not both",
+		},
+		{
+			name:          "ACME",
+			cfg:           config.Config{ACMEHosts: []string{"go.example.com"}, ACMECacheDir: acmeCache, ACMEHTTPPort: 8081},
+			wantTLS:       true,
+			wantChallenge: ":8081",
+		},
+		{
+			name:    "ACME with the TLS challenge alone",
+			cfg:     config.Config{ACMEHosts: []string{"go.example.com"}, ACMECacheDir: acmeCache},
+			wantTLS: true,
+		},
+		{name: "ACME without a cache", cfg: config.Config{ACMEHosts: []string{"go.example.com"}}, wantErr: "ACME_CACHE_DIR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &http.Server{}
+			challenge, err := configureTLS(server, &tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("configureTLS() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("configureTLS() error = %v", err)
+			}
+
+			if (server.TLSConfig != nil) != tt.wantTLS {
+				t.Errorf("configureTLS() TLSConfig = %v, want TLS %v", server.TLSConfig, tt.wantTLS)
+			}
+			switch {
+			case tt.wantChallenge == "" && challenge != nil:
+				t.Errorf("configureTLS() challenge server on %s, want none", challenge.Addr)
+			case tt.wantChallenge != "" && (challenge == nil || challenge.Addr != tt.wantChallenge):
+				t.Errorf("configureTLS() challenge server = %v, want one on %s", challenge, tt.wantChallenge)
+			}
+		})
+	}
+}
+
+func TestConfigureTLS_ACMEHosts(t *testing.T) {
+	server := &http.Server{}
+	cfg := &config.Config{ACMEHosts: []string{"go.example.com"}, ACMECacheDir: t.TempDir(), ACMEHTTPPort: 80}
+	challenge, err := configureTLS(server, cfg)
+	if err != nil {
+		t.Fatalf("configureTLS() error = %v", err)
+	}
+
+	// Plain HTTP to an allowed host is sent to HTTPS
+	req := httptest.NewRequest(http.MethodGet, "http://go.example.com/query/docs", nil)
+	rec := httptest.NewRecorder()
+	challenge.Handler.ServeHTTP(rec, req)
+	if location := rec.Header().Get("Location"); rec.Code != http.StatusFound || location != "https://go.example.com/query/docs" {
+		t.Errorf("challenge server GET = %d %q, want a redirect to HTTPS", rec.Code, location)
+	}
+
+	// Certificates are only requested for allowed hosts
+	_, err = server.TLSConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "evil.example.com"})
+	if err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("GetCertificate() for another host error = %v, want it refused", err)
+	}
+}